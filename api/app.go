@@ -21,6 +21,7 @@ import (
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/auth"
 	tsuruEnvs "github.com/tsuru/tsuru/envs"
+	"github.com/tsuru/tsuru/envs/secretref"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
@@ -140,6 +141,45 @@ func appVersionDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 	return app.DeleteVersion(ctx, a, evt, versionString)
 }
 
+// title: app version log
+// path: /apps/{app}/versions/{version}/log
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	400: Invalid filters
+//	401: Unauthorized
+//	404: App or version not found
+func appVersionLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	urlValues := r.URL.Query()
+	a, err := getAppFromContext(urlValues.Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppReadDeploy,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	var args appTypes.ListLogArgs
+	err = parseLogFilters(urlValues, &args)
+	if err != nil {
+		return err
+	}
+	versionLog, err := app.VersionLog(ctx, a, urlValues.Get(":version"), urlValues["phase"], args)
+	if err != nil {
+		if appTypes.IsInvalidVersionError(err) {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(versionLog)
+}
+
 // title: remove app
 // path: /apps/{name}
 // method: DELETE
@@ -622,6 +662,14 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		}
 	}
 
+	if isDryRun(r) {
+		result, dryRunErr := app.UpdateDryRun(ctx, a, app.UpdateAppArgs{
+			UpdateData:    updateData,
+			ShouldRestart: !noRestart,
+		})
+		return writeDryRunResult(w, result, updateAppError(dryRunErr))
+	}
+
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppUpdate,
@@ -648,6 +696,10 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		Writer:        evt,
 		ShouldRestart: !noRestart,
 	})
+	return updateAppError(err)
+}
+
+func updateAppError(err error) error {
 	if pkgErrors.Cause(err) == appTypes.ErrPlanNotFound {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
 	}
@@ -705,6 +757,10 @@ func addUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	if isDryRun(r) {
+		result, dryRunErr := app.AddUnitsDryRun(ctx, a, n, processName, version)
+		return writeDryRunResult(w, result, dryRunErr)
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppUpdateUnitAdd,
@@ -759,6 +815,10 @@ func removeUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	if isDryRun(r) {
+		result, dryRunErr := app.RemoveUnitsDryRun(ctx, a, n, processName, version)
+		return writeDryRunResult(w, result, dryRunErr)
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppUpdateUnitRemove,
@@ -1094,25 +1154,10 @@ func setAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		return permission.ErrUnauthorized
 	}
 
-	var toExclude []string
-	for i := 0; i < len(e.Envs); i++ {
-		if (e.Envs[i].Private != nil && *e.Envs[i].Private) || e.Private {
-			toExclude = append(toExclude, fmt.Sprintf("Envs.%d.Value", i))
-		}
+	if err = validateEnvSecretRefs(ctx, a, e.Envs); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("There were errors validating secret references: %s", err)}
 	}
 
-	evt, err := event.New(ctx, &event.Opts{
-		Target:     appTarget(appName),
-		Kind:       permission.PermAppUpdateEnvSet,
-		Owner:      t,
-		RemoteAddr: r.RemoteAddr,
-		CustomData: event.FormToCustomData(InputFields(r, toExclude...)),
-		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
-	})
-	if err != nil {
-		return err
-	}
-	defer func() { evt.Done(ctx, err) }()
 	envs := map[string]string{}
 	variables := []bindTypes.EnvVar{}
 	for _, v := range e.Envs {
@@ -1122,7 +1167,7 @@ func setAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 			private = *v.Private
 		}
 		// Global private override individual private definitions
-		if e.Private {
+		if e.Private || v.SecretRef != "" {
 			private = true
 		}
 		variables = append(variables, bindTypes.EnvVar{
@@ -1131,21 +1176,47 @@ func setAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 			Public:    !private,
 			Alias:     v.Alias,
 			ManagedBy: e.ManagedBy,
+			SecretRef: v.SecretRef,
 		})
 	}
+	setEnvArgs := bindTypes.SetEnvArgs{
+		Envs:          variables,
+		ManagedBy:     e.ManagedBy,
+		PruneUnused:   e.PruneUnused,
+		ShouldRestart: !e.NoRestart,
+	}
+	if isDryRun(r) {
+		result, dryRunErr := app.SetEnvsDryRun(ctx, a, setEnvArgs)
+		return writeDryRunResult(w, result, dryRunErr)
+	}
+
+	var toExclude []string
+	for i := 0; i < len(e.Envs); i++ {
+		if (e.Envs[i].Private != nil && *e.Envs[i].Private) || e.Private {
+			toExclude = append(toExclude, fmt.Sprintf("Envs.%d.Value", i))
+		}
+	}
+
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateEnvSet,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r, toExclude...)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
 	w.Header().Set("Content-Type", "application/x-json-stream")
 	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	evt.SetLogWriter(writer)
 
-	err = app.SetEnvs(ctx, a, bindTypes.SetEnvArgs{
-		Envs:          variables,
-		ManagedBy:     e.ManagedBy,
-		PruneUnused:   e.PruneUnused,
-		ShouldRestart: !e.NoRestart,
-		Writer:        evt,
-	})
+	setEnvArgs.Writer = evt
+	err = app.SetEnvs(ctx, a, setEnvArgs)
 	if v, ok := err.(*errors.ValidationError); ok {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
 	}
@@ -1170,6 +1241,29 @@ func validateApiEnvVars(envs []apiTypes.Env) error {
 	return errs.ToError()
 }
 
+// validateEnvSecretRefs checks that every secret reference points to an
+// existing secret, which is never read back to the client.
+func validateEnvSecretRefs(ctx stdContext.Context, a *appTypes.App, envs []apiTypes.Env) error {
+	var errs errors.MultiError
+
+	for _, e := range envs {
+		if e.SecretRef == "" {
+			continue
+		}
+
+		if e.Value != "" || e.Alias != "" {
+			errs.Add(fmt.Errorf("%q: a secret reference cannot be combined with a value or an alias", e.Name))
+			continue
+		}
+
+		if err := secretref.Validate(ctx, a, e.SecretRef); err != nil {
+			errs.Add(fmt.Errorf("%q: %w", e.Name, err))
+		}
+	}
+
+	return errs.ToError()
+}
+
 func isInternalEnv(envKey string) bool {
 	for _, internalEnv := range internalEnvs() {
 		if internalEnv == envKey {
@@ -1227,6 +1321,15 @@ func unsetAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	noRestart, _ := strconv.ParseBool(InputValue(r, "noRestart"))
+	unsetEnvArgs := bindTypes.UnsetEnvArgs{
+		VariableNames: variables,
+		ShouldRestart: !noRestart,
+	}
+	if isDryRun(r) {
+		result, dryRunErr := app.UnsetEnvsDryRun(ctx, a, unsetEnvArgs)
+		return writeDryRunResult(w, result, dryRunErr)
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     appTarget(appName),
 		Kind:       permission.PermAppUpdateEnvUnset,
@@ -1244,13 +1347,8 @@ func unsetAppEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	evt.SetLogWriter(writer)
-	noRestart, _ := strconv.ParseBool(InputValue(r, "noRestart"))
-
-	return app.UnsetEnvs(ctx, a, bindTypes.UnsetEnvArgs{
-		VariableNames: variables,
-		ShouldRestart: !noRestart,
-		Writer:        evt,
-	})
+	unsetEnvArgs.Writer = evt
+	return app.UnsetEnvs(ctx, a, unsetEnvArgs)
 }
 
 // title: set cname
@@ -1405,6 +1503,10 @@ func appLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		InvertSource: invert,
 		Units:        units,
 	}
+	err = parseLogFilters(urlValues, &listArgs)
+	if err != nil {
+		return err
+	}
 	logs, err := app.LastLogs(ctx, a, logService, listArgs)
 	if err != nil {
 		return err
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,7 +27,9 @@ import (
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruEnvs "github.com/tsuru/tsuru/envs"
+	"github.com/tsuru/tsuru/envs/secretref"
 	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/permission/permissiontest"
@@ -788,6 +791,87 @@ func (s *S) TestDeleteVersion(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestAppVersionLog(c *check.C) {
+	ctx := context.TODO()
+	myApp := &appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, myApp, s.user)
+	c.Assert(err, check.IsNil)
+	version := newAppVersion(c, myApp)
+	evt, err := event.New(ctx, &event.Opts{
+		Target:  appTarget(myApp.Name),
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	start := time.Now().UTC()
+	time.Sleep(5 * time.Millisecond)
+	fmt.Fprintln(evt, "fetching source")
+	fmt.Fprintln(evt, "level=error msg=timeout")
+	time.Sleep(5 * time.Millisecond)
+	buildEnd := time.Now().UTC()
+	time.Sleep(5 * time.Millisecond)
+	fmt.Fprintln(evt, "rolling out")
+	time.Sleep(5 * time.Millisecond)
+	rolloutEnd := time.Now().UTC()
+	err = evt.Done(ctx, nil)
+	c.Assert(err, check.IsNil)
+	err = version.SetBuildPhase(appTypes.BuildPhase{Name: "build", Status: appTypes.BuildPhaseSucceeded, StartTime: start, EndTime: buildEnd, EventID: evt.UniqueID.Hex()})
+	c.Assert(err, check.IsNil)
+	err = version.SetBuildPhase(appTypes.BuildPhase{Name: "rollout", Status: appTypes.BuildPhaseFailed, StartTime: buildEnd, EndTime: rolloutEnd, EventID: evt.UniqueID.Hex()})
+	c.Assert(err, check.IsNil)
+
+	request, err := http.NewRequest("GET", "/apps/myapp/versions/1/log", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var versionLog appTypes.AppVersionLog
+	err = json.NewDecoder(recorder.Body).Decode(&versionLog)
+	c.Assert(err, check.IsNil)
+	c.Assert(versionLog.Version, check.Equals, 1)
+	c.Assert(versionLog.Phases, check.HasLen, 2)
+	c.Assert(versionLog.Phases[0].Name, check.Equals, "build")
+	c.Assert(versionLog.Phases[0].Status, check.Equals, appTypes.BuildPhaseSucceeded)
+	c.Assert(versionLog.Phases[0].DurationSeconds > 0, check.Equals, true)
+	c.Assert(versionLog.Phases[0].Log, check.HasLen, 2)
+	c.Assert(versionLog.Phases[0].Log[0].Message, check.Equals, "fetching source\n")
+	c.Assert(versionLog.Phases[1].Name, check.Equals, "rollout")
+	c.Assert(versionLog.Phases[1].Status, check.Equals, appTypes.BuildPhaseFailed)
+	c.Assert(versionLog.Phases[1].Log, check.HasLen, 1)
+	c.Assert(versionLog.Phases[1].Log[0].Message, check.Equals, "rolling out\n")
+
+	request, err = http.NewRequest("GET", "/apps/myapp/versions/1/log?phase=build&level=error", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	versionLog = appTypes.AppVersionLog{}
+	err = json.NewDecoder(recorder.Body).Decode(&versionLog)
+	c.Assert(err, check.IsNil)
+	c.Assert(versionLog.Phases, check.HasLen, 1)
+	c.Assert(versionLog.Phases[0].Log, check.HasLen, 1)
+	c.Assert(versionLog.Phases[0].Log[0].Message, check.Equals, "level=error msg=timeout\n")
+}
+
+func (s *S) TestAppVersionLogVersionNotFound(c *check.C) {
+	ctx := context.TODO()
+	myApp := &appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(ctx, myApp, s.user)
+	c.Assert(err, check.IsNil)
+	newAppVersion(c, myApp)
+	request, err := http.NewRequest("GET", "/apps/myapp/versions/9/log", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "Invalid version: 9\n")
+}
+
 func (s *S) TestDeleteShouldReturnForbiddenIfTheGivenUserDoesNotHaveAccessToTheApp(c *check.C) {
 	myApp := appTypes.App{Name: "app-to-delete", Platform: "zend"}
 	appsCollection, err := storagev2.AppsCollection()
@@ -4122,6 +4206,81 @@ func (s *S) TestSetEnvInvalidEnvName(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
 }
 
+type fakeSecretResolver struct{}
+
+func (fakeSecretResolver) Validate(ctx context.Context, app *appTypes.App, ref secretref.Ref) error {
+	if ref.Path != "db" {
+		return stderrors.New("secret not found")
+	}
+	return nil
+}
+
+func (fakeSecretResolver) Resolve(ctx context.Context, app *appTypes.App, ref secretref.Ref) (string, error) {
+	return "s3cr3t", fakeSecretResolver{}.Validate(ctx, app, ref)
+}
+
+func (s *S) TestSetEnvSecretRef(c *check.C) {
+	secretref.Register("fake", fakeSecretResolver{})
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	d := apiTypes.Envs{
+		Envs: []apiTypes.Env{
+			{Name: "DATABASE_PASSWORD", SecretRef: "fake://db#password"},
+		},
+	}
+	v, err := json.Marshal(d)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/env", a.Name)
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(v))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DATABASE_PASSWORD"], check.DeepEquals, bindTypes.EnvVar{Name: "DATABASE_PASSWORD", SecretRef: "fake://db#password"})
+	request, err = http.NewRequest(http.MethodGet, url+"?env=DATABASE_PASSWORD", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var envs []bindTypes.EnvVar
+	err = json.NewDecoder(recorder.Body).Decode(&envs)
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []bindTypes.EnvVar{{Name: "DATABASE_PASSWORD", SecretRef: "fake://db#password"}})
+}
+
+func (s *S) TestSetEnvSecretRefInvalid(c *check.C) {
+	secretref.Register("fake", fakeSecretResolver{})
+	a := appTypes.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []apiTypes.Env{
+		{Name: "DATABASE_PASSWORD", SecretRef: "fake://other#password"},
+		{Name: "DATABASE_PASSWORD", SecretRef: "fake://db#password", Value: "value"},
+		{Name: "DATABASE_PASSWORD", SecretRef: "unknown://db#password"},
+		{Name: "DATABASE_PASSWORD", SecretRef: "not-a-ref"},
+	}
+	for _, env := range tests {
+		v, err := json.Marshal(apiTypes.Envs{Envs: []apiTypes.Env{env}})
+		c.Assert(err, check.IsNil)
+		request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/apps/%s/env", a.Name), bytes.NewReader(v))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("env %#v", env))
+	}
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.HasLen, 0)
+}
+
 func (s *S) TestIsEnvVarValid(c *check.C) {
 	tests := []struct {
 		envs          []apiTypes.Env
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"reflect"
 	"runtime"
+	"time"
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
@@ -40,6 +41,9 @@ func handleAuthError(err error) error {
 	if err == authTypes.ErrUserNotFound {
 		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
 	}
+	if err == auth.ErrUserDisabled {
+		return &errors.HTTP{Code: http.StatusUnauthorized, Message: err.Error()}
+	}
 	switch err.(type) {
 	case *errors.ValidationError:
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
@@ -616,6 +620,10 @@ func removeUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	if err != nil {
 		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
 	}
+	err = servicemanager.PersonalToken.DeleteByUser(ctx, u.Email)
+	if err != nil {
+		return err
+	}
 
 	if userScheme, ok := app.AuthScheme.(auth.UserScheme); ok {
 		return userScheme.Remove(ctx, u)
@@ -755,10 +763,12 @@ func showAPIToken(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 }
 
 type rolePermissionData struct {
-	Name         string
-	ContextType  string
-	ContextValue string
-	Group        string `json:",omitempty"`
+	Name          string
+	ContextType   string
+	ContextValue  string
+	Group         string     `json:",omitempty"`
+	ExpiresAt     *time.Time `json:",omitempty"`
+	Justification string     `json:",omitempty"`
 }
 
 type apiUser struct {
@@ -813,6 +823,9 @@ func createAPIUser(ctx context.Context, perms []permTypes.Permission, user *auth
 }
 
 func expandRoleData(ctx context.Context, perms []permTypes.Permission, userRole authTypes.RoleInstance, user *apiUser, roleMap map[string]*permission.Role, includeAll bool, group string) (bool, error) {
+	if userRole.Expired(time.Now()) {
+		return true, nil
+	}
 	role := roleMap[userRole.Name]
 	if role == nil {
 		r, err := permission.FindRole(ctx, userRole.Name)
@@ -844,10 +857,12 @@ func expandRoleData(ctx context.Context, perms []permTypes.Permission, userRole
 		return true, nil
 	}
 	user.Roles = append(user.Roles, rolePermissionData{
-		Name:         userRole.Name,
-		ContextType:  string(role.ContextType),
-		ContextValue: userRole.ContextValue,
-		Group:        group,
+		Name:          userRole.Name,
+		ContextType:   string(role.ContextType),
+		ContextValue:  userRole.ContextValue,
+		Group:         group,
+		ExpiresAt:     userRole.ExpiresAt,
+		Justification: userRole.Justification,
 	})
 	user.Permissions = append(user.Permissions, rolePerms...)
 	return role.ContextType == permTypes.CtxGlobal, nil
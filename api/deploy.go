@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -19,6 +20,7 @@ import (
 	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	provisionTypes "github.com/tsuru/tsuru/types/provision"
@@ -111,6 +113,10 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	opts.Message = message
 	opts.NewVersion, _ = strconv.ParseBool(InputValue(r, "new-version"))
 	opts.OverrideVersions, _ = strconv.ParseBool(InputValue(r, "override-versions"))
+	opts.Canary, err = canaryOptions(r)
+	if err != nil {
+		return err
+	}
 	opts.GetKind()
 	canDeploy := permission.Check(ctx, t, permSchemeForDeploy(opts), contextsForApp(instance)...)
 	if !canDeploy {
@@ -120,6 +126,7 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	var imageID string
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
+		ExtraTargets:  deployExtraTargets(instance),
 		Kind:          permission.PermAppDeploy,
 		RawOwner:      eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: userName},
 		RemoteAddr:    r.RemoteAddr,
@@ -151,6 +158,43 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	return err
 }
 
+func canaryOptions(r *http.Request) (*app.CanaryOptions, error) {
+	switch mode := InputValue(r, "mode"); mode {
+	case "":
+		return nil, nil
+	case "canary":
+	default:
+		return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid deploy mode %q", mode)}
+	}
+	opts := &app.CanaryOptions{
+		Query: InputValue(r, "canary-query"),
+	}
+	if steps := InputValue(r, "canary-steps"); steps != "" {
+		for _, step := range strings.Split(steps, ",") {
+			weight, err := strconv.Atoi(strings.TrimSpace(step))
+			if err != nil {
+				return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid canary step %q", step)}
+			}
+			opts.Steps = append(opts.Steps, weight)
+		}
+	}
+	if interval := InputValue(r, "canary-interval"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid canary interval: %v", err)}
+		}
+		opts.Interval = d
+	}
+	if threshold := InputValue(r, "canary-threshold"); threshold != "" {
+		value, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid canary threshold: %v", err)}
+		}
+		opts.Threshold = value
+	}
+	return opts, nil
+}
+
 // path: /jobs/{name}/deploy
 // method: POST
 // consume: application/x-www-form-urlencoded
@@ -236,6 +280,14 @@ func jobDeploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	return nil
 }
 
+// deployExtraTargets adds the app pool to deploy events, so they can be
+// filtered and require approval by pool.
+func deployExtraTargets(a *appTypes.App) []eventTypes.ExtraTarget {
+	return []eventTypes.ExtraTarget{
+		{Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: a.Pool}},
+	}
+}
+
 func deployStatus(evt *event.Event) string {
 	if evt == nil {
 		return "unknown"
@@ -337,6 +389,7 @@ func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) (err e
 	var imageID string
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
+		ExtraTargets:  deployExtraTargets(instance),
 		Kind:          permission.PermAppDeploy,
 		Owner:         t,
 		RemoteAddr:    r.RemoteAddr,
@@ -359,6 +412,68 @@ func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) (err e
 	return nil
 }
 
+// title: update auto rollback
+// path: /apps/{app}/deploy/auto-rollback
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	403: Forbidden
+//	404: Not found
+func deployAutoRollbackUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	instance, err := app.GetByName(ctx, appName)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	canUpdateRollback := permission.Check(ctx, t, permission.PermAppUpdateDeployRollback, contextsForApp(instance)...)
+	if !canUpdateRollback {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusForbidden,
+			Message: "User does not have permission to do this action in this app",
+		}
+	}
+	var cfg appTypes.AutoRollback
+	cfg.Enabled, err = strconv.ParseBool(InputValue(r, "enabled"))
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("Cannot set 'enabled' status to: '%s', instead of 'true' or 'false'", InputValue(r, "enabled"))}
+	}
+	if window := InputValue(r, "window"); window != "" {
+		cfg.Window, err = time.ParseDuration(window)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid window: %v", err)}
+		}
+	}
+	if maxRestarts := InputValue(r, "max-restarts"); maxRestarts != "" {
+		cfg.MaxRestarts, err = strconv.Atoi(maxRestarts)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid max-restarts: %v", err)}
+		}
+	}
+	if maxFailedChecks := InputValue(r, "max-failed-checks"); maxFailedChecks != "" {
+		cfg.MaxFailedChecks, err = strconv.Atoi(maxFailedChecks)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid max-failed-checks: %v", err)}
+		}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateDeployRollback,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return app.SetAutoRollback(ctx, instance, cfg)
+}
+
 // title: deploy list
 // path: /deploys
 // method: GET
@@ -468,6 +583,7 @@ func deployRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 	var imageID string
 	evt, err := event.New(ctx, &event.Opts{
 		Target:        appTarget(appName),
+		ExtraTargets:  deployExtraTargets(instance),
 		Kind:          permission.PermAppDeploy,
 		Owner:         t,
 		RemoteAddr:    r.RemoteAddr,
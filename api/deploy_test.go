@@ -273,6 +273,38 @@ func (s *DeploySuite) TestDeployInvalidOrigin(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Equals, "Invalid deployment origin\n")
 }
 
+func (s *DeploySuite) TestDeployInvalidMode(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/deploy?:appname=%s", a.Name, a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("archive-url=http://something.tar.gz&mode=blue-green"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid deploy mode \"blue-green\"\n")
+}
+
+func (s *DeploySuite) TestDeployInvalidCanarySteps(c *check.C) {
+	a := appTypes.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/deploy?:appname=%s", a.Name, a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("archive-url=http://something.tar.gz&mode=canary&canary-steps=10,abc"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid canary step \"abc\"\n")
+}
+
 func (s *DeploySuite) TestDeployOriginImage(c *check.C) {
 	s.builder.OnBuild = func(app *appTypes.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
 		return newAppVersion(c, app), nil
@@ -1363,6 +1395,65 @@ func (s *DeploySuite) TestRollbackUpdate(c *check.C) {
 	c.Assert(disabledVersion.DisabledReason, check.Equals, "because of reasons")
 }
 
+func (s *DeploySuite) TestDeployAutoRollbackUpdate(c *check.C) {
+	fakeApp := appTypes.App{Name: "otherapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &fakeApp, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("enabled", "true")
+	v.Set("window", "10m")
+	v.Set("max-restarts", "5")
+	url := fmt.Sprintf("/apps/%s/deploy/auto-rollback", fakeApp.Name)
+	request, err := http.NewRequest(http.MethodPut, url, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myadmin", permTypes.Permission{
+		Scheme:  permission.PermAppUpdateDeployRollback,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	server := RunServer(true)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(context.TODO(), fakeApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AutoRollback, check.DeepEquals, &appTypes.AutoRollback{
+		Enabled:     true,
+		Window:      10 * time.Minute,
+		MaxRestarts: 5,
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(fakeApp.Name),
+		Owner:  token.GetUserName(),
+		Kind:   "app.update.deploy.rollback",
+		StartCustomData: []map[string]interface{}{
+			{"name": "enabled", "value": "true"},
+			{"name": "window", "value": "10m"},
+			{"name": "max-restarts", "value": "5"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *DeploySuite) TestDeployAutoRollbackUpdateInvalidWindow(c *check.C) {
+	fakeApp := appTypes.App{Name: "otherapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &fakeApp, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("enabled", "true")
+	v.Set("window", "48h")
+	url := fmt.Sprintf("/apps/%s/deploy/auto-rollback", fakeApp.Name)
+	request, err := http.NewRequest(http.MethodPut, url, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "auto rollback window must be between 0 and 1h0m0s\n")
+}
+
 func (s *DeploySuite) TestRollbackUpdateInvalidImage(c *check.C) {
 	fakeApp := appTypes.App{Name: "otherapp", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &fakeApp, s.user)
@@ -0,0 +1,39 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/types/quota"
+)
+
+// isDryRun returns whether the request asks for the changes of a mutating
+// operation to be computed and returned instead of applied.
+func isDryRun(r *http.Request) bool {
+	dryRun, _ := strconv.ParseBool(InputValue(r, "dryRun"))
+	return dryRun
+}
+
+// writeDryRunResult encodes the result of a dry run, validation and quota
+// errors found by it are reported as bad requests.
+func writeDryRunResult(w http.ResponseWriter, result *app.DryRunResult, err error) error {
+	if err != nil {
+		switch e := pkgErrors.Cause(err).(type) {
+		case *errors.ValidationError:
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: e.Message}
+		case *quota.QuotaExceededError:
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: e.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
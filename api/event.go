@@ -170,6 +170,74 @@ func eventCancel(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return nil
 }
 
+// title: event approve
+// path: /events/{uuid}/approve
+// method: POST
+// responses:
+//
+//	204: OK
+//	400: Invalid uuid or event not pending approval
+//	401: Unauthorized
+//	403: Event owner can't approve it
+//	404: Not found
+func eventApprove(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return eventDecideApproval(w, r, t, true)
+}
+
+// title: event reject
+// path: /events/{uuid}/reject
+// method: POST
+// responses:
+//
+//	204: OK
+//	400: Invalid uuid, empty reason or event not pending approval
+//	401: Unauthorized
+//	403: Event owner can't reject it
+//	404: Not found
+func eventReject(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return eventDecideApproval(w, r, t, false)
+}
+
+func eventDecideApproval(w http.ResponseWriter, r *http.Request, t auth.Token, approve bool) error {
+	ctx := r.Context()
+	uuid := r.URL.Query().Get(":uuid")
+	if _, err := primitive.ObjectIDFromHex(uuid); err != nil {
+		msg := fmt.Sprintf("uuid parameter is not ObjectId: %s", uuid)
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	e, err := event.GetByHexID(ctx, uuid)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	reason := InputValue(r, "reason")
+	if !approve && reason == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "reason is mandatory"}
+	}
+	scheme := permission.PermEventApprovalApprove
+	if !approve {
+		scheme = permission.PermEventApprovalReject
+	}
+	if !permission.Check(ctx, t, scheme, e.Allowed.Contexts...) {
+		return permission.ErrUnauthorized
+	}
+	if approve {
+		err = e.Approve(ctx, t.GetUserName(), reason)
+	} else {
+		err = e.Reject(ctx, t.GetUserName(), reason)
+	}
+	switch err {
+	case nil:
+	case event.ErrNotPendingApproval:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case event.ErrSelfApproval:
+		return &errors.HTTP{Code: http.StatusForbidden, Message: err.Error()}
+	default:
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 // title: event block list
 // path: /events/blocks
 // method: GET
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/cezarsa/form"
 	"github.com/tsuru/config"
@@ -580,6 +581,135 @@ func (s *EventSuite) TestEventCancelWithoutPermission(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
 }
 
+func (s *EventSuite) setPendingApproval(c *check.C, evt *event.Event) {
+	collection, err := storagev2.EventsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(context.TODO(), mongoBSON.M{"_id": evt.ID}, mongoBSON.M{"$set": mongoBSON.M{
+		"approvalinfo.required": true,
+		"approvalinfo.pending":  true,
+		"approvalinfo.deadline": time.Now().Add(time.Hour).UTC(),
+	}})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *EventSuite) TestEventApprove(c *check.C) {
+	events, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	s.setPendingApproval(c, events[0])
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permTypes.Permission{
+		Scheme:  permission.PermEventApprovalApprove,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("reason=lgtm")
+	u := fmt.Sprintf("/events/%s/approve", events[0].UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	evt, err := event.GetByID(context.TODO(), events[0].ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.ApprovalInfo.Pending, check.Equals, false)
+	c.Assert(evt.ApprovalInfo.Approved, check.Equals, true)
+	c.Assert(evt.ApprovalInfo.Owner, check.Equals, token.GetUserName())
+	c.Assert(evt.ApprovalInfo.Reason, check.Equals, "lgtm")
+}
+
+func (s *EventSuite) TestEventApproveByOwner(c *check.C) {
+	events, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	s.setPendingApproval(c, events[0])
+	u := fmt.Sprintf("/events/%s/approve", events[0].UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, event.ErrSelfApproval.Error()+"\n")
+}
+
+func (s *EventSuite) TestEventApproveNotPending(c *check.C) {
+	events, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permTypes.Permission{
+		Scheme:  permission.PermEventApproval,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	u := fmt.Sprintf("/events/%s/approve", events[0].UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, event.ErrNotPendingApproval.Error()+"\n")
+}
+
+func (s *EventSuite) TestEventApproveWithoutPermission(c *check.C) {
+	events, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	s.setPendingApproval(c, events[0])
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permTypes.Permission{
+		Scheme:  permission.PermEventApprovalReject,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	u := fmt.Sprintf("/events/%s/approve", events[0].UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *EventSuite) TestEventReject(c *check.C) {
+	events, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	s.setPendingApproval(c, events[0])
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permTypes.Permission{
+		Scheme:  permission.PermEventApprovalReject,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("reason=not during the freeze")
+	u := fmt.Sprintf("/events/%s/reject", events[0].UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	evt, err := event.GetByID(context.TODO(), events[0].ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.ApprovalInfo.Rejected, check.Equals, true)
+	c.Assert(evt.ApprovalInfo.Reason, check.Equals, "not during the freeze")
+	c.Assert(evt.Running, check.Equals, false)
+	c.Assert(evt.Error, check.Matches, ".*rejected by .*: not during the freeze")
+}
+
+func (s *EventSuite) TestEventRejectNoReason(c *check.C) {
+	events, err := s.insertEvents("app", nil, c)
+	c.Assert(err, check.IsNil)
+	s.setPendingApproval(c, events[0])
+	u := fmt.Sprintf("/events/%s/reject", events[0].UniqueID.Hex())
+	request, err := http.NewRequest("POST", u, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "reason is mandatory\n")
+}
+
 func (s *EventSuite) TestEventBlockListAllBlocks(c *check.C) {
 	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
 		Scheme:  permission.PermEventBlockRead,
@@ -673,6 +803,60 @@ func (s *EventSuite) TestEventBlockAdd(c *check.C) {
 	c.Assert(blocks[0].Reason, check.Equals, "block reason")
 }
 
+func (s *EventSuite) TestEventBlockAddScheduled(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermEventBlockAdd,
+		Context: permTypes.PermissionContext{CtxType: permTypes.CtxGlobal},
+	})
+	endTime := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	block := &event.Block{
+		KindName:     "app.deploy",
+		Reason:       "deploy freeze",
+		EndTime:      endTime,
+		Window:       &event.BlockWindow{Start: "0 18 * * 5", End: "0 8 * * 1", Timezone: "America/Sao_Paulo"},
+		AllowedTeams: []string{"sre"},
+	}
+	values, err := form.EncodeToValues(block)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/events/blocks", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	blocks, err := event.ListBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(len(blocks), check.Equals, 1)
+	c.Assert(blocks[0].Active, check.Equals, true)
+	c.Assert(blocks[0].EndTime.Equal(endTime), check.Equals, true)
+	c.Assert(blocks[0].Window, check.DeepEquals, block.Window)
+	c.Assert(blocks[0].AllowedTeams, check.DeepEquals, []string{"sre"})
+}
+
+func (s *EventSuite) TestEventBlockAddInvalidWindow(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permTypes.Permission{
+		Scheme:  permission.PermEventBlockAdd,
+		Context: permTypes.PermissionContext{CtxType: permTypes.CtxGlobal},
+	})
+	block := &event.Block{KindName: "app.deploy", Reason: "deploy freeze", Window: &event.BlockWindow{Start: "0 18 * * 5"}}
+	values, err := form.EncodeToValues(block)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/events/blocks", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "block window requires both start and end\n")
+	blocks, err := event.ListBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(len(blocks), check.Equals, 0)
+}
+
 func (s *EventSuite) TestEventBlockAddWithoutPermission(c *check.C) {
 	block := &event.Block{KindName: "app.deploy", Reason: "block reason"}
 	values, err := form.EncodeToValues(block)
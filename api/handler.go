@@ -5,12 +5,18 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	pkgErrors "github.com/pkg/errors"
 	"github.com/tsuru/tsuru/api/context"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
@@ -26,6 +32,10 @@ func (fn Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	context.AddRequestError(r, fn(w, r))
 }
 
+// approvedEventHeader carries the ID of an approved event when the operation
+// that required the approval is requested again.
+const approvedEventHeader = "X-Tsuru-Approved-Event"
+
 type AuthorizationRequiredHandler func(http.ResponseWriter, *http.Request, auth.Token) error
 
 func (fn AuthorizationRequiredHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -33,9 +43,65 @@ func (fn AuthorizationRequiredHandler) ServeHTTP(w http.ResponseWriter, r *http.
 	if t == nil {
 		w.Header().Set("WWW-Authenticate", "Bearer realm=\"tsuru\" scope=\"tsuru\"")
 		context.AddRequestError(r, tokenRequiredErr)
+		return
+	}
+	var err error
+	if approvedID := r.Header.Get(approvedEventHeader); approvedID != "" {
+		err = runApproved(r, approvedID, t, func() error {
+			return fn(w, r, t)
+		})
 	} else {
-		context.AddRequestError(r, fn(w, r, t))
+		err = fn(w, r, t)
+	}
+	if pending, ok := pkgErrors.Cause(err).(event.ErrApprovalPending); ok {
+		err = writeApprovalPending(w, pending.Event)
+	}
+	context.AddRequestError(r, err)
+}
+
+// writeApprovalPending answers requests whose event is pending approval with
+// the event ID, ending the request. Once someone approves the event through
+// /events/{uuid}/approve, the same request must be sent again with the event
+// ID in the X-Tsuru-Approved-Event header to run the operation.
+func writeApprovalPending(w http.ResponseWriter, evt *event.Event) error {
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	w.Header().Set(eventIDHeader, evt.UniqueID.Hex())
+	w.WriteHeader(http.StatusAccepted)
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(w)}
+	_, err := fmt.Fprintf(writer, "Event %s is pending approval until %s. Once approved, send the request again with the %s: %s header.\n",
+		evt.UniqueID.Hex(), evt.ApprovalInfo.Deadline.Format(time.RFC3339), approvedEventHeader, evt.UniqueID.Hex())
+	return err
+}
+
+// runApproved runs handler resuming the approved event with the given ID
+// instead of creating a new event.
+func runApproved(r *http.Request, approvedID string, t auth.Token, handler func() error) error {
+	if _, err := primitive.ObjectIDFromHex(approvedID); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s is not a valid event ID: %s", approvedEventHeader, approvedID)}
+	}
+	evt, err := event.ResumeApproved(r.Context(), approvedID, t)
+	if err != nil {
+		switch err.(type) {
+		case event.ErrApprovalPending:
+			return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+		case event.ErrNotApproved:
+			return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+		}
+		switch err {
+		case event.ErrEventNotFound:
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		case event.ErrApprovalNotOwner:
+			return &errors.HTTP{Code: http.StatusForbidden, Message: err.Error()}
+		case event.ErrApprovalResumed:
+			return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+		}
+		return err
 	}
+	ctx, finish := event.WithApprovedEvent(r.Context(), evt)
+	*r = *r.WithContext(ctx)
+	err = handler()
+	finish(err)
+	return err
 }
 
 func deprecateFormContentType(r *http.Request) error {
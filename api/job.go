@@ -38,6 +38,11 @@ import (
 	tagTypes "github.com/tsuru/tsuru/types/tag"
 )
 
+const (
+	defaultJobRunLimit = 100
+	maxJobRunLimit     = 1000
+)
+
 type inputJob struct {
 	TeamOwner   string            `json:"teamOwner"`
 	Plan        string            `json:"plan"`
@@ -173,7 +178,10 @@ func jobTrigger(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 		return err
 	}
 	defer func() { evt.Done(ctx, err) }()
-	err = servicemanager.Job.Trigger(ctx, j)
+	err = servicemanager.Job.Trigger(ctx, j, jobTypes.TriggerArgs{
+		Trigger:     jobRunTrigger(t),
+		TriggeredBy: t.GetUserName(),
+	})
 	if err != nil {
 		return err
 	}
@@ -190,6 +198,17 @@ func jobTrigger(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	return nil
 }
 
+// jobRunTrigger tells apart the runs triggered by users from the ones
+// triggered by automation, through team, personal, API key or workload
+// tokens.
+func jobRunTrigger(t auth.Token) jobTypes.RunTrigger {
+	switch t.Engine() {
+	case "native", "oauth", "oidc":
+		return jobTypes.RunTriggerManual
+	}
+	return jobTypes.RunTriggerAPI
+}
+
 // title: job info
 // path: /jobs
 // method: GET
@@ -974,6 +993,80 @@ func unsetJobEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	})
 }
 
+// title: job run list
+// path: /jobs/{name}/runs
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List job runs
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Job not found
+func jobRunList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	j, err := getJob(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	filter := jobTypes.RunFilter{
+		Status: jobTypes.RunStatus(r.URL.Query().Get("status")),
+		Limit:  defaultJobRunLimit,
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "limit must be a positive integer"}
+		}
+		if filter.Limit > maxJobRunLimit {
+			filter.Limit = maxJobRunLimit
+		}
+	}
+	runs, err := servicemanager.Job.ListRuns(ctx, j, filter)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(runs)
+}
+
+// title: job run info
+// path: /jobs/{name}/runs/{id}
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Job or run not found
+func jobRunInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	j, err := getJob(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	run, err := servicemanager.Job.GetRun(ctx, j, r.URL.Query().Get(":id"))
+	if err == jobTypes.ErrJobRunNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(run)
+}
+
 // title: job log
 // path: /jobs/{job}/log
 // method: GET
@@ -1014,6 +1107,10 @@ func jobLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		Type:  log.LogTypeJob,
 		Limit: lines,
 	}
+	err = parseLogFilters(urlValues, &listArgs)
+	if err != nil {
+		return err
+	}
 	logService := servicemanager.LogService
 	logs, err := logService.List(ctx, listArgs)
 	if err != nil {
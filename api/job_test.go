@@ -830,6 +830,153 @@ func (s *S) TestTriggerCronjob(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
 }
 
+func (s *S) createRunsJob(c *check.C) *jobTypes.Job {
+	provision.DefaultProvisioner = "jobProv"
+	provision.Register("jobProv", func() (provision.Provisioner, error) {
+		return &provisiontest.JobProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}, nil
+	})
+	j := jobTypes.Job{
+		TeamOwner: s.team.Name,
+		Pool:      "test1",
+		Name:      "nightly-job",
+		Spec: jobTypes.JobSpec{
+			Schedule: "0 3 * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+				Command:          []string{"echo", "hello world"},
+			},
+		},
+	}
+	user, _ := auth.ConvertOldUser(s.user, nil)
+	err := servicemanager.Job.CreateJob(context.TODO(), &j, user)
+	c.Assert(err, check.IsNil)
+	return &j
+}
+
+func (s *S) TestJobRunList(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	j := s.createRunsJob(c)
+	now := time.Now().UTC()
+	for _, run := range []jobTypes.Run{
+		{ID: "r1", JobName: j.Name, Trigger: jobTypes.RunTriggerCron, Status: jobTypes.RunStatusFailed, StartTime: now.Add(-2 * time.Hour)},
+		{ID: "r2", JobName: j.Name, Trigger: jobTypes.RunTriggerManual, Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-time.Hour)},
+	} {
+		err := servicemanager.Job.RecordRun(context.TODO(), run)
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest("GET", "/jobs/nightly-job/runs", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var runs []jobTypes.Run
+	err = json.NewDecoder(recorder.Body).Decode(&runs)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 2)
+	c.Assert(runs[0].ID, check.Equals, "r2")
+	c.Assert(runs[0].Trigger, check.Equals, jobTypes.RunTriggerManual)
+	c.Assert(runs[1].ID, check.Equals, "r1")
+
+	request, err = http.NewRequest("GET", "/jobs/nightly-job/runs?status=failed&limit=1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	runs = nil
+	err = json.NewDecoder(recorder.Body).Decode(&runs)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+	c.Assert(runs[0].ID, check.Equals, "r1")
+}
+
+func (s *S) TestJobRunListNoContent(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createRunsJob(c)
+	request, err := http.NewRequest("GET", "/jobs/nightly-job/runs", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestJobRunListInvalidLimit(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createRunsJob(c)
+	for _, limit := range []string{"abc", "0", "-1"} {
+		request, err := http.NewRequest("GET", "/jobs/nightly-job/runs?limit="+limit, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "b "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+		c.Assert(recorder.Body.String(), check.Equals, "limit must be a positive integer\n")
+	}
+}
+
+func (s *S) TestJobRunInfo(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	j := s.createRunsJob(c)
+	exitCode := int32(1)
+	now := time.Now().UTC()
+	err := servicemanager.Job.RecordRun(context.TODO(), jobTypes.Run{
+		ID:        "r1",
+		JobName:   j.Name,
+		Trigger:   jobTypes.RunTriggerCron,
+		Status:    jobTypes.RunStatusFailed,
+		Reason:    "BackoffLimitExceeded",
+		ExitCode:  &exitCode,
+		Retries:   3,
+		StartTime: now.Add(-time.Minute),
+		EndTime:   now,
+		Units:     []string{"nightly-job-28000000-abcde"},
+		Log: &jobTypes.RunLog{Entries: []jobTypes.RunLogEntry{
+			{Date: now, Unit: "nightly-job-28000000-abcde", Message: "connection refused"},
+		}},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/jobs/nightly-job/runs/r1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var run jobTypes.Run
+	err = json.NewDecoder(recorder.Body).Decode(&run)
+	c.Assert(err, check.IsNil)
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(run.Reason, check.Equals, "BackoffLimitExceeded")
+	c.Assert(*run.ExitCode, check.Equals, int32(1))
+	c.Assert(run.Retries, check.Equals, 3)
+	c.Assert(run.Log.Entries, check.HasLen, 1)
+	c.Assert(run.Log.Entries[0].Message, check.Equals, "connection refused")
+}
+
+func (s *S) TestJobRunInfoNotFound(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createRunsJob(c)
+	request, err := http.NewRequest("GET", "/jobs/nightly-job/runs/unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "Job run not found\n")
+}
+
 func (s *S) TestJobList(c *check.C) {
 	oldProvisioner := provision.DefaultProvisioner
 	defer func() { provision.DefaultProvisioner = oldProvisioner }()
@@ -0,0 +1,390 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	defaultWorkflowRunLimit = 100
+	maxWorkflowRunLimit     = 1000
+)
+
+func getWorkflow(ctx stdContext.Context, name string) (*jobTypes.Workflow, error) {
+	w, err := servicemanager.JobWorkflow.Find(ctx, name)
+	if err == jobTypes.ErrWorkflowNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return w, err
+}
+
+func workflowTarget(name string) eventTypes.Target {
+	return eventTypes.Target{Type: eventTypes.TargetTypeJobWorkflow, Value: name}
+}
+
+// checkWorkflowJobs checks the token is allowed to run the jobs of the
+// workflow steps, as the workflow runs them on its behalf. Unknown jobs are
+// left for the workflow validation.
+func checkWorkflowJobs(ctx stdContext.Context, t auth.Token, w *jobTypes.Workflow) error {
+	for _, step := range w.Steps {
+		j, err := servicemanager.Job.GetByName(ctx, step.Job)
+		if err == jobTypes.ErrJobNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !permission.Check(ctx, t, permission.PermJobRun, contextsForJob(j)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	return nil
+}
+
+// title: job workflow list
+// path: /job-workflows
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List workflows
+//	204: No content
+func workflowList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	ctxs := permission.ContextsForPermission(ctx, t, permission.PermJobRead, permTypes.CtxTeam)
+	var teams []string
+	for _, c := range ctxs {
+		if c.CtxType == permTypes.CtxGlobal {
+			teams = nil
+			break
+		}
+		teams = append(teams, c.Value)
+	}
+	if teams == nil && len(ctxs) == 0 {
+		teams = []string{}
+	}
+	workflows, err := servicemanager.JobWorkflow.List(ctx, teams)
+	if err != nil {
+		return err
+	}
+	if len(workflows) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(workflows)
+}
+
+// title: job workflow info
+// path: /job-workflows/{name}
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Get workflow
+//	401: Unauthorized
+//	404: Not found
+func workflowInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	wf, err := getWorkflow(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, permission.Context(permTypes.CtxTeam, wf.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(wf)
+}
+
+// title: job workflow create
+// path: /job-workflows
+// method: POST
+// consume: application/json
+// responses:
+//
+//	201: Workflow created
+//	400: Invalid workflow
+//	401: Unauthorized
+//	409: Workflow already exists
+func workflowCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var wf jobTypes.Workflow
+	err = ParseInput(r, &wf)
+	if err != nil {
+		return err
+	}
+	if wf.TeamOwner == "" {
+		wf.TeamOwner, err = autoTeamOwner(ctx, t, permission.PermJobCreate)
+		if err != nil {
+			return err
+		}
+	}
+	permCtx := permission.Context(permTypes.CtxTeam, wf.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermJobCreate, permCtx) {
+		return permission.ErrUnauthorized
+	}
+	err = checkWorkflowJobs(ctx, t, &wf)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     workflowTarget(wf.Name),
+		Kind:       permission.PermJobCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermJobReadEvents, permCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.JobWorkflow.Create(ctx, wf)
+	if err == jobTypes.ErrWorkflowAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// title: job workflow update
+// path: /job-workflows/{name}
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: Workflow updated
+//	400: Invalid workflow
+//	401: Unauthorized
+//	404: Workflow not found
+func workflowUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	old, err := getWorkflow(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	var wf jobTypes.Workflow
+	err = ParseInput(r, &wf)
+	if err != nil {
+		return err
+	}
+	wf.Name = old.Name
+	if wf.TeamOwner == "" {
+		wf.TeamOwner = old.TeamOwner
+	}
+	for _, team := range []string{old.TeamOwner, wf.TeamOwner} {
+		if !permission.Check(ctx, t, permission.PermJobUpdate, permission.Context(permTypes.CtxTeam, team)) {
+			return permission.ErrUnauthorized
+		}
+	}
+	err = checkWorkflowJobs(ctx, t, &wf)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     workflowTarget(wf.Name),
+		Kind:       permission.PermJobUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed: event.Allowed(permission.PermJobReadEvents,
+			permission.Context(permTypes.CtxTeam, old.TeamOwner),
+			permission.Context(permTypes.CtxTeam, wf.TeamOwner),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return servicemanager.JobWorkflow.Update(ctx, wf)
+}
+
+// title: job workflow delete
+// path: /job-workflows/{name}
+// method: DELETE
+// responses:
+//
+//	200: Workflow deleted
+//	401: Unauthorized
+//	404: Workflow not found
+func workflowDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	wf, err := getWorkflow(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	permCtx := permission.Context(permTypes.CtxTeam, wf.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermJobDelete, permCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     workflowTarget(wf.Name),
+		Kind:       permission.PermJobDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermJobReadEvents, permCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return servicemanager.JobWorkflow.Delete(ctx, wf.Name)
+}
+
+type inputWorkflowTrigger struct {
+	Envs []bindTypes.EnvVar `json:"envs"`
+}
+
+// title: job workflow trigger
+// path: /job-workflows/{name}/trigger
+// method: POST
+// consume: application/json
+// produce: application/json
+// responses:
+//
+//	200: Workflow triggered
+//	401: Unauthorized
+//	404: Workflow not found
+func workflowTrigger(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	wf, err := getWorkflow(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	var input inputWorkflowTrigger
+	err = ParseInput(r, &input)
+	if err != nil {
+		return err
+	}
+	permCtx := permission.Context(permTypes.CtxTeam, wf.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermJobRun, permCtx) {
+		return permission.ErrUnauthorized
+	}
+	// the envs of the trigger are set in the jobs of every step, which may
+	// be owned by other teams
+	err = checkWorkflowJobs(ctx, t, wf)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     workflowTarget(wf.Name),
+		Kind:       permission.PermJobTrigger,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermJobReadEvents, permCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	run, err := servicemanager.JobWorkflow.Trigger(ctx, wf, jobTypes.WorkflowTriggerArgs{
+		Trigger:     jobRunTrigger(t),
+		TriggeredBy: t.GetUserName(),
+		Envs:        input.Envs,
+	})
+	if stderrors.Is(err, jobTypes.ErrWorkflowJobTeamChanged) {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(run)
+}
+
+// title: job workflow run list
+// path: /job-workflows/{name}/runs
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Workflow not found
+func workflowRunList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	wf, err := getWorkflow(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, permission.Context(permTypes.CtxTeam, wf.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	filter := jobTypes.WorkflowRunFilter{
+		Status: jobTypes.RunStatus(r.URL.Query().Get("status")),
+		Limit:  defaultWorkflowRunLimit,
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "limit must be a positive integer"}
+		}
+		if filter.Limit > maxWorkflowRunLimit {
+			filter.Limit = maxWorkflowRunLimit
+		}
+	}
+	runs, err := servicemanager.JobWorkflow.ListRuns(ctx, wf, filter)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(runs)
+}
+
+// title: job workflow run info
+// path: /job-workflows/{name}/runs/{id}
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Workflow or run not found
+func workflowRunInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	wf, err := getWorkflow(ctx, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, permission.Context(permTypes.CtxTeam, wf.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	run, err := servicemanager.JobWorkflow.GetRun(ctx, wf, r.URL.Query().Get(":id"))
+	if err == jobTypes.ErrWorkflowRunNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(run)
+}
@@ -0,0 +1,314 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/servicemanager"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createWorkflow(c *check.C) *jobTypes.Workflow {
+	j := s.createRunsJob(c)
+	w := jobTypes.Workflow{
+		Name:      "etl",
+		TeamOwner: s.team.Name,
+		Envs:      []bindTypes.EnvVar{{Name: "REGION", Value: "us-east-1"}},
+		Steps: []jobTypes.WorkflowStep{
+			{Name: "extract", Job: j.Name},
+			{Name: "load", Job: j.Name, DependsOn: []string{"extract"}},
+		},
+	}
+	err := servicemanager.JobWorkflow.Create(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	return &w
+}
+
+func (s *S) TestWorkflowCreate(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createRunsJob(c)
+	body := `{"name":"etl","teamOwner":"` + s.team.Name + `","schedule":"0 4 * * *","steps":[
+		{"name":"extract","job":"nightly-job"},
+		{"name":"notify","job":"nightly-job","dependsOn":["extract"],"condition":"failure"}
+	]}`
+	request, err := http.NewRequest("POST", "/job-workflows", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+	w, err := servicemanager.JobWorkflow.Find(context.TODO(), "etl")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Schedule, check.Equals, "0 4 * * *")
+	c.Assert(w.Steps, check.DeepEquals, []jobTypes.WorkflowStep{
+		{Name: "extract", Job: "nightly-job", Condition: jobTypes.WorkflowConditionSuccess},
+		{Name: "notify", Job: "nightly-job", DependsOn: []string{"extract"}, Condition: jobTypes.WorkflowConditionFailure},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: workflowTarget("etl"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "job.create",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("POST", "/job-workflows", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestWorkflowCreateWithCycle(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createRunsJob(c)
+	body := `{"name":"etl","teamOwner":"` + s.team.Name + `","steps":[
+		{"name":"extract","job":"nightly-job","dependsOn":["load"]},
+		{"name":"load","job":"nightly-job","dependsOn":["extract"]}
+	]}`
+	request, err := http.NewRequest("POST", "/job-workflows", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "steps have a dependency cycle: extract -> load -> extract\n")
+}
+
+func (s *S) TestWorkflowCreateRequiresJobRunPermission(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createRunsJob(c)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermJobCreate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := `{"name":"etl","teamOwner":"` + s.team.Name + `","steps":[{"name":"extract","job":"nightly-job"}]}`
+	request, err := http.NewRequest("POST", "/job-workflows", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	_, err = servicemanager.JobWorkflow.Find(context.TODO(), "etl")
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowNotFound)
+}
+
+func (s *S) TestWorkflowListAndInfo(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createWorkflow(c)
+	request, err := http.NewRequest("GET", "/job-workflows", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var workflows []jobTypes.Workflow
+	err = json.NewDecoder(recorder.Body).Decode(&workflows)
+	c.Assert(err, check.IsNil)
+	c.Assert(workflows, check.HasLen, 1)
+	c.Assert(workflows[0].Name, check.Equals, "etl")
+	request, err = http.NewRequest("GET", "/job-workflows/etl", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var w jobTypes.Workflow
+	err = json.NewDecoder(recorder.Body).Decode(&w)
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Steps, check.HasLen, 2)
+	request, err = http.NewRequest("GET", "/job-workflows/unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestWorkflowListWithoutPermission(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createWorkflow(c)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermJobRead,
+		Context: permission.Context(permTypes.CtxTeam, "other-team"),
+	})
+	request, err := http.NewRequest("GET", "/job-workflows", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestWorkflowUpdate(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createWorkflow(c)
+	body := `{"description":"nightly etl","steps":[{"name":"extract","job":"nightly-job"}]}`
+	request, err := http.NewRequest("PUT", "/job-workflows/etl", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	w, err := servicemanager.JobWorkflow.Find(context.TODO(), "etl")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(w.Description, check.Equals, "nightly etl")
+	c.Assert(w.Steps, check.HasLen, 1)
+	c.Assert(eventtest.EventDesc{
+		Target: workflowTarget("etl"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "job.update",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestWorkflowDelete(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createWorkflow(c)
+	request, err := http.NewRequest("DELETE", "/job-workflows/etl", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = servicemanager.JobWorkflow.Find(context.TODO(), "etl")
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowNotFound)
+	c.Assert(eventtest.EventDesc{
+		Target: workflowTarget("etl"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "job.delete",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestWorkflowTrigger(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	s.createWorkflow(c)
+	body := `{"envs":[{"name":"DATE","value":"2026-10-16"}]}`
+	request, err := http.NewRequest("POST", "/job-workflows/etl/trigger", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var run jobTypes.WorkflowRun
+	err = json.NewDecoder(recorder.Body).Decode(&run)
+	c.Assert(err, check.IsNil)
+	c.Assert(run.WorkflowName, check.Equals, "etl")
+	c.Assert(run.Trigger, check.Equals, jobRunTrigger(s.token))
+	c.Assert(run.TriggeredBy, check.Equals, s.token.GetUserName())
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusRunning)
+	c.Assert(run.Envs, check.DeepEquals, []bindTypes.EnvVar{{Name: "REGION", Value: "us-east-1"}, {Name: "DATE", Value: "2026-10-16"}})
+	c.Assert(run.Steps, check.HasLen, 2)
+	c.Assert(run.Steps[0].Status, check.Equals, jobTypes.WorkflowStepRunning)
+	c.Assert(run.Steps[1].Status, check.Equals, jobTypes.WorkflowStepPending)
+	triggers := provisiontest.ProvisionerInstance.JobTriggers("nightly-job")
+	c.Assert(triggers, check.HasLen, 1)
+	c.Assert(triggers[0].Workflow, check.DeepEquals, &jobTypes.WorkflowRef{Workflow: "etl", Run: run.ID, Step: "extract"})
+	c.Assert(eventtest.EventDesc{
+		Target: workflowTarget("etl"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "job.trigger",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestWorkflowTriggerNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/job-workflows/unknown/trigger", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestWorkflowRunListAndInfo(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	defer provision.Unregister("jobProv")
+	w := s.createWorkflow(c)
+	run, err := servicemanager.JobWorkflow.Trigger(context.TODO(), w, jobTypes.WorkflowTriggerArgs{Trigger: jobTypes.RunTriggerCron})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/job-workflows/etl/runs?status=running", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var runs []jobTypes.WorkflowRun
+	err = json.NewDecoder(recorder.Body).Decode(&runs)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+	c.Assert(runs[0].ID, check.Equals, run.ID)
+	request, err = http.NewRequest("GET", "/job-workflows/etl/runs?status=failed", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	for _, limit := range []string{"abc", "0", "-1"} {
+		request, err = http.NewRequest("GET", "/job-workflows/etl/runs?limit="+limit, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "b "+s.token.GetValue())
+		recorder = httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+		c.Assert(recorder.Body.String(), check.Equals, "limit must be a positive integer\n")
+	}
+	request, err = http.NewRequest("GET", "/job-workflows/etl/runs?limit=1000000", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	request, err = http.NewRequest("GET", "/job-workflows/etl/runs/"+run.ID, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result jobTypes.WorkflowRun
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.ID, check.Equals, run.ID)
+	c.Assert(result.Step("extract").Status, check.Equals, jobTypes.WorkflowStepRunning)
+	request, err = http.NewRequest("GET", "/job-workflows/etl/runs/unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
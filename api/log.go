@@ -6,10 +6,16 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/api/context"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
@@ -64,3 +70,34 @@ func scanLogs(stream io.Reader) error {
 type errMsg struct {
 	Error string `json:"error"`
 }
+
+// parseLogFilters fills the time, message and level filters of args from
+// the since, until, filter, filter-regex and level query parameters.
+func parseLogFilters(values url.Values, args *appTypes.ListLogArgs) error {
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{name: "since", value: &args.Since},
+		{name: "until", value: &args.Until},
+	} {
+		v := values.Get(param.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			msg := fmt.Sprintf("Parameter %q must be a RFC 3339 timestamp.", param.name)
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: msg}
+		}
+		*param.value = t
+	}
+	args.Filter = values.Get("filter")
+	args.FilterRegex, _ = strconv.ParseBool(values.Get("filter-regex"))
+	args.Levels = values["level"]
+	_, err := appTypes.NewLogMatcher(*args)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+)
+
+var errManifestAppChanged = stderrors.New("the app was changed while the manifest was being applied, review the changes and try again")
+
+// title: app manifest
+// path: /apps/{app}/manifest
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App not found
+func appManifest(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	for _, perm := range []*permTypes.PermissionScheme{permission.PermAppReadInfo, permission.PermAppReadEnv} {
+		if !permission.Check(ctx, t, perm, contextsForApp(a)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	m, err := manifest.Export(ctx, a)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(m)
+}
+
+// title: apply app manifest
+// path: /apps/{app}/manifest
+// method: PUT
+// consume: application/json
+// produce: application/x-json-stream
+// responses:
+//
+//	200: OK
+//	400: Invalid manifest
+//	401: Unauthorized
+//	404: App not found
+//	409: App changed
+func appManifestApply(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	noRestart, _ := strconv.ParseBool(r.URL.Query().Get("noRestart"))
+	var desired manifest.Manifest
+	err = ParseJSON(r, &desired)
+	if err != nil {
+		return err
+	}
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	current, err := manifest.Export(ctx, a)
+	if err != nil {
+		return err
+	}
+	changes, err := manifest.Diff(current, &desired)
+	if err != nil {
+		return err
+	}
+	extraTargets, err := checkManifestChanges(ctx, t, a, changes)
+	if err != nil {
+		return err
+	}
+	if dryRun || len(changes) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(manifest.Redacted(changes))
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:        appTarget(appName),
+		ExtraTargets:  extraTargets,
+		Kind:          permission.PermAppUpdate,
+		Owner:         t,
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    manifest.Redacted(changes),
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(a)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	ctx, cancel := evt.CancelableContext(ctx)
+	defer cancel()
+
+	// NOTE: the app may be modified while the event lock is being acquired,
+	// so it's fetched again and the changes are computed again before
+	// applying them. The request fails when they differ from the changes
+	// that were checked, which are also the ones recorded in the event.
+	a, err = app.GetByName(ctx, appName)
+	if err != nil {
+		return err
+	}
+	current, err = manifest.Export(ctx, a)
+	if err != nil {
+		return err
+	}
+	lockedChanges, err := manifest.Diff(current, &desired)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(changes, lockedChanges) {
+		return &errors.HTTP{Code: http.StatusConflict, Message: errManifestAppChanged.Error()}
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return manifest.Apply(ctx, a, changes, manifest.ApplyOptions{
+		Event:     evt,
+		RequestID: requestIDHeader(r),
+		NoRestart: noRestart,
+	})
+}
+
+var manifestPermissions = map[string]map[manifest.Action]*permTypes.PermissionScheme{
+	manifest.SectionDescription: {manifest.ActionUpdate: permission.PermAppUpdateDescription},
+	manifest.SectionTeamOwner:   {manifest.ActionUpdate: permission.PermAppUpdateTeamowner},
+	manifest.SectionPlan:        {manifest.ActionUpdate: permission.PermAppUpdatePlan},
+	manifest.SectionTags:        {manifest.ActionUpdate: permission.PermAppUpdateTags},
+	manifest.SectionMetadata: {
+		manifest.ActionAdd:    permission.PermAppUpdateMetadata,
+		manifest.ActionUpdate: permission.PermAppUpdateMetadata,
+		manifest.ActionRemove: permission.PermAppUpdateMetadata,
+	},
+	manifest.SectionEnv: {
+		manifest.ActionAdd:    permission.PermAppUpdateEnvSet,
+		manifest.ActionUpdate: permission.PermAppUpdateEnvSet,
+		manifest.ActionRemove: permission.PermAppUpdateEnvUnset,
+	},
+	manifest.SectionRouter: {
+		manifest.ActionAdd:    permission.PermAppUpdateRouterAdd,
+		manifest.ActionUpdate: permission.PermAppUpdateRouterUpdate,
+		manifest.ActionRemove: permission.PermAppUpdateRouterRemove,
+	},
+	manifest.SectionCName: {
+		manifest.ActionAdd:    permission.PermAppUpdateCnameAdd,
+		manifest.ActionRemove: permission.PermAppUpdateCnameRemove,
+	},
+	manifest.SectionCertIssuer: {
+		manifest.ActionAdd:    permission.PermCertissuerSet,
+		manifest.ActionUpdate: permission.PermCertissuerSet,
+		manifest.ActionRemove: permission.PermCertissuerUnset,
+	},
+	manifest.SectionAutoscale: {
+		manifest.ActionAdd:    permission.PermAppUpdateUnitAutoscaleAdd,
+		manifest.ActionUpdate: permission.PermAppUpdateUnitAutoscaleAdd,
+		manifest.ActionRemove: permission.PermAppUpdateUnitAutoscaleRemove,
+	},
+}
+
+// checkManifestChanges ensures the token is allowed to apply every change,
+// checking the same permissions as the endpoints dedicated to each of them,
+// and returns the service instances and volumes touched by the changes as
+// event extra targets.
+func checkManifestChanges(ctx stdContext.Context, t auth.Token, a *appTypes.App, changes []manifest.Change) ([]eventTypes.ExtraTarget, error) {
+	var extraTargets []eventTypes.ExtraTarget
+	for _, c := range changes {
+		switch c.Section {
+		case manifest.SectionVolume:
+			b, _ := c.New.(manifest.VolumeBind)
+			if c.Action == manifest.ActionRemove {
+				b = c.Old.(manifest.VolumeBind)
+			}
+			v, err := servicemanager.Volume.Get(ctx, b.Volume)
+			if err != nil {
+				if err == volumeTypes.ErrVolumeNotFound {
+					return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+				}
+				return nil, err
+			}
+			perms := [][2]*permTypes.PermissionScheme{}
+			if c.Action != manifest.ActionAdd {
+				perms = append(perms, [2]*permTypes.PermissionScheme{permission.PermVolumeUpdateUnbind, permission.PermAppUpdateUnbindVolume})
+			}
+			if c.Action != manifest.ActionRemove {
+				perms = append(perms, [2]*permTypes.PermissionScheme{permission.PermVolumeUpdateBind, permission.PermAppUpdateBindVolume})
+			}
+			for _, p := range perms {
+				if !permission.Check(ctx, t, p[0], contextsForVolume(v)...) || !permission.Check(ctx, t, p[1], contextsForApp(a)...) {
+					return nil, permission.ErrUnauthorized
+				}
+			}
+			extraTargets = append(extraTargets, eventTypes.ExtraTarget{
+				Target: eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: v.Name},
+			})
+		case manifest.SectionService:
+			b, _ := c.New.(manifest.ServiceBind)
+			instancePerm, appPerm := permission.PermServiceInstanceUpdateBind, permission.PermAppUpdateBind
+			if c.Action == manifest.ActionRemove {
+				b = c.Old.(manifest.ServiceBind)
+				instancePerm, appPerm = permission.PermServiceInstanceUpdateUnbind, permission.PermAppUpdateUnbind
+			}
+			instance, err := getServiceInstanceOrError(ctx, b.Service, b.Instance)
+			if err != nil {
+				return nil, err
+			}
+			allowed := permission.Check(ctx, t, instancePerm,
+				append(permission.Contexts(permTypes.CtxTeam, instance.Teams),
+					permission.Context(permTypes.CtxTeam, instance.TeamOwner),
+					permission.Context(permTypes.CtxServiceInstance, instance.Name),
+				)...,
+			)
+			if !allowed || !permission.Check(ctx, t, appPerm, contextsForApp(a)...) {
+				return nil, permission.ErrUnauthorized
+			}
+			extraTargets = append(extraTargets, eventTypes.ExtraTarget{
+				Target: serviceInstanceTarget(b.Service, b.Instance),
+				Lock:   true,
+			})
+		case manifest.SectionPlatform:
+			repo, _ := image.SplitImageName(c.New.(string))
+			platform, err := servicemanager.Platform.FindByName(ctx, repo)
+			if err != nil {
+				return nil, err
+			}
+			if platform.Disabled && !permission.Check(ctx, t, permission.PermPlatformUpdate) && !permission.Check(ctx, t, permission.PermPlatformCreate) {
+				return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: appTypes.ErrInvalidPlatform.Error()}
+			}
+			for _, perm := range []*permTypes.PermissionScheme{permission.PermAppUpdatePlatform, permission.PermAppUpdateImageReset} {
+				if !permission.Check(ctx, t, perm, contextsForApp(a)...) {
+					return nil, permission.ErrUnauthorized
+				}
+			}
+		default:
+			perm := manifestPermissions[c.Section][c.Action]
+			if perm == nil || !permission.Check(ctx, t, perm, contextsForApp(a)...) {
+				return nil, permission.ErrUnauthorized
+			}
+		}
+	}
+	return extraTargets, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppManifest(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Description: "my app"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "PUBLIC", Value: "1", Public: true},
+			{Name: "SECRET", Value: "s3cr3t"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/1.32/apps/myapp/manifest", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var m manifest.Manifest
+	err = json.Unmarshal(recorder.Body.Bytes(), &m)
+	c.Assert(err, check.IsNil)
+	c.Assert(m.Version, check.Equals, manifest.Version)
+	c.Assert(m.Name, check.Equals, "myapp")
+	c.Assert(m.Description, check.Equals, "my app")
+	c.Assert(m.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(m.Env, check.DeepEquals, []manifest.EnvVar{
+		{Name: "PUBLIC", Value: "1"},
+		{Name: "SECRET", Value: app.SuppressedEnv, Private: true},
+	})
+}
+
+func (s *S) TestAppManifestUnauthorized(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppReadInfo,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest(http.MethodGet, "/1.32/apps/myapp/manifest", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppManifestApplyDryRun(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Description: "my app"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"version":"v1","description":"new description","env":[{"name":"NEW","value":"1"}]}`)
+	request, err := http.NewRequest(http.MethodPut, "/1.32/apps/myapp/manifest?dryRun=true", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	var changes []map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &changes)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []map[string]interface{}{
+		{"section": "description", "action": "update", "old": "my app", "new": "new description"},
+		{"section": "env", "action": "add", "key": "NEW", "new": map[string]interface{}{"name": "NEW", "value": "1"}},
+	})
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "my app")
+	c.Assert(dbApp.Env, check.HasLen, 0)
+}
+
+func (s *S) TestAppManifestApply(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Description: "my app"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"version":"v1","description":"new description","tags":["t1"]}`)
+	request, err := http.NewRequest(http.MethodPut, "/1.32/apps/myapp/manifest", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "new description")
+	c.Assert(dbApp.Tags, check.DeepEquals, []string{"t1"})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update",
+		StartCustomData: []map[string]interface{}{
+			{"section": "description", "action": "update", "old": "my app", "new": "new description"},
+			{"section": "tags", "action": "update", "new": []interface{}{"t1"}},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppManifestApplyAppChangedWhileLocked(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  appTarget(a.Name),
+		Kind:    permission.PermAppUpdate,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"version":"v1","env":[{"name":"NEW","value":"1"}]}`)
+	request, err := http.NewRequest(http.MethodPut, "/1.32/apps/myapp/manifest", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.testServer.ServeHTTP(recorder, request)
+	}()
+	time.Sleep(500 * time.Millisecond)
+	err = app.SetEnvs(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "NEW", Value: "2", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	<-done
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["NEW"].Value, check.Equals, "2")
+}
+
+func (s *S) TestAppManifestApplyWithoutPermission(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Description: "my app"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppUpdateDescription,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	body := strings.NewReader(`{"version":"v1","description":"new description","env":[{"name":"NEW","value":"1"}]}`)
+	request, err := http.NewRequest(http.MethodPut, "/1.32/apps/myapp/manifest", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "my app")
+}
+
+func (s *S) TestAppManifestApplyInvalidVersion(c *check.C) {
+	a := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPut, "/1.32/apps/myapp/manifest", strings.NewReader(`{"version":"v9"}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "unsupported manifest version \"v9\", expected \"v1\"\n")
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	stdIO "io"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -34,6 +35,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 	negroni "github.com/urfave/negroni/v3"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -68,7 +70,7 @@ var (
 
 func validate(token string, r *http.Request) (auth.Token, error) {
 	var t auth.Token
-	t, err := tokenByAllAuthEngines(r.Context(), token)
+	t, err := tokenByAllAuthEngines(auth.WithSourceAddr(r.Context(), clientAddr(r)), token)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +107,14 @@ func tokenByAllAuthEngines(ctx stdContext.Context, token string) (auth.Token, er
 	if err == nil {
 		return t, nil
 	}
+	if _, ok := err.(*authTypes.ErrTeamTokenRejected); ok {
+		return nil, err
+	}
+
+	t, err = servicemanager.PersonalToken.Authenticate(ctx, token)
+	if err == nil {
+		return t, nil
+	}
 
 	t, err = peer.Auth(ctx, token)
 	if err == nil {
@@ -170,6 +180,67 @@ func setRequestIDHeaderMiddleware(w http.ResponseWriter, r *http.Request, next h
 	next(w, r)
 }
 
+// clientAddr returns the address of the client making the request. The
+// X-Forwarded-For header is only trusted when the request comes from one of
+// the proxies listed in the server:trusted-proxies config, in which case the
+// rightmost address not belonging to a trusted proxy is used.
+func clientAddr(r *http.Request) string {
+	trusted, _ := config.GetList("server:trusted-proxies")
+	if len(trusted) == 0 {
+		return r.RemoteAddr
+	}
+	var proxies []*net.IPNet
+	for _, value := range trusted {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil {
+				bits := 8 * len(ip)
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 8*net.IPv4len
+				}
+				proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			}
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(value); err == nil {
+			proxies = append(proxies, ipNet)
+		}
+	}
+	isTrusted := func(addr string) bool {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return false
+		}
+		for _, p := range proxies {
+			if p.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrusted(host) {
+		return r.RemoteAddr
+	}
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if addr == "" {
+			continue
+		}
+		if !isTrusted(addr) {
+			return addr
+		}
+		host = addr
+	}
+	return host
+}
+
 func setVersionHeadersMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	w.Header().Set("Supported-Tsuru", tsuruMin)
 	next(w, r)
@@ -190,6 +261,9 @@ func errorHandlingMiddleware(w http.ResponseWriter, r *http.Request, next http.H
 		if errors.Cause(err) == appTypes.ErrAppNotFound {
 			code = http.StatusNotFound
 		}
+		if code == http.StatusForbidden {
+			recordScopedTokenForbidden(r)
+		}
 		if verbosity == 0 {
 			err = fmt.Errorf("%s", err)
 		} else {
@@ -217,6 +291,15 @@ func authTokenMiddleware(w http.ResponseWriter, r *http.Request, next http.Handl
 	if token != "" {
 		t, err := validate(token, r)
 		if err != nil {
+			if rejected, ok := err.(*authTypes.ErrTeamTokenRejected); ok {
+				recordTeamTokenRejection(r, rejected)
+				code := http.StatusForbidden
+				if rejected.Reason == authTypes.TeamTokenRejectedRateLimit {
+					code = http.StatusTooManyRequests
+				}
+				context.AddRequestError(r, &tsuruErrors.HTTP{Code: code, Message: err.Error()})
+				return
+			}
 			if err != auth.ErrInvalidToken {
 				context.AddRequestError(r, err)
 				return
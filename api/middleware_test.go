@@ -119,6 +119,22 @@ func (s *S) TestSetRequestIDHeaderMiddleware(c *check.C) {
 	c.Assert(reqID, check.Not(check.Equals), "")
 }
 
+func (s *S) TestClientAddr(c *check.C) {
+	req, err := http.NewRequest("GET", "/", nil)
+	c.Assert(err, check.IsNil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Add("X-Forwarded-For", "203.0.113.9, 198.51.100.7")
+	req.Header.Add("X-Forwarded-For", "10.0.0.2")
+	c.Assert(clientAddr(req), check.Equals, "10.0.0.1:1234")
+	config.Set("server:trusted-proxies", []string{"10.0.0.0/8"})
+	defer config.Unset("server:trusted-proxies")
+	c.Assert(clientAddr(req), check.Equals, "198.51.100.7")
+	config.Set("server:trusted-proxies", []string{"10.0.0.0/8", "198.51.100.7"})
+	c.Assert(clientAddr(req), check.Equals, "203.0.113.9")
+	req.RemoteAddr = "192.168.0.1:1234"
+	c.Assert(clientAddr(req), check.Equals, "192.168.0.1:1234")
+}
+
 func (s *S) TestSetRequestIDHeaderAlreadySet(c *check.C) {
 	config.Set("request-id-header", "Request-ID")
 	defer config.Unset("request-id-header")
@@ -11,7 +11,10 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
+	"time"
 
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
@@ -344,6 +347,7 @@ func canUseRole(ctx context.Context, t auth.Token, role permission.Role, context
 //	400: Invalid data
 //	401: Unauthorized
 //	404: Role not found
+//	409: Role already permanently assigned
 func assignRole(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	if !permission.Check(ctx, t, permission.PermRoleUpdateAssign) {
@@ -364,6 +368,22 @@ func assignRole(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	defer func() { evt.Done(ctx, err) }()
 	email := InputValue(r, "email")
 	contextValue := InputValue(r, "context")
+	var expiresIn time.Duration
+	if expires := InputValue(r, "expires"); expires != "" {
+		expiresIn, err = time.ParseDuration(expires)
+		if err != nil || expiresIn <= 0 {
+			return &errors.HTTP{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("invalid expires value %q, it must be a positive duration, e.g. 2h", expires),
+			}
+		}
+		if maxDuration, _ := config.GetDuration("roles:max-temporary-assignment"); maxDuration > 0 && expiresIn > maxDuration {
+			return &errors.HTTP{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("temporary role assignments cannot last longer than %v", maxDuration),
+			}
+		}
+	}
 	user, err := auth.GetUserByEmail(ctx, email)
 	if err != nil {
 		return err
@@ -382,7 +402,17 @@ func assignRole(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 		return err
 	}
 
-	return user.AddRole(ctx, roleName, contextValue)
+	if expiresIn == 0 {
+		return user.AddRole(ctx, roleName, contextValue)
+	}
+	err = user.AddTemporaryRole(ctx, roleName, contextValue, time.Now().Add(expiresIn), InputValue(r, "justification"))
+	if err == auth.ErrRoleAlreadyAssigned {
+		return &errors.HTTP{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		}
+	}
+	return err
 }
 
 // title: dissociate role from user
@@ -926,3 +956,118 @@ func dissociateRoleFromGroup(w http.ResponseWriter, r *http.Request, t auth.Toke
 	}
 	return servicemanager.AuthGroup.RemoveRole(ctx, groupName, roleName, contextValue)
 }
+
+// simulationContexts parses the context query values, each one in the
+// type:value format. App and job contexts are expanded into every context
+// checked by the API for them.
+func simulationContexts(ctx context.Context, values []string) ([]permTypes.PermissionContext, error) {
+	var contexts []permTypes.PermissionContext
+	for _, value := range values {
+		ctxTypeName, ctxValue, _ := strings.Cut(value, ":")
+		ctxType, err := permission.ParseContext(ctxTypeName)
+		if err != nil {
+			return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		switch ctxType {
+		case permTypes.CtxApp:
+			a, err := getApp(ctx, ctxValue)
+			if err != nil {
+				return nil, err
+			}
+			contexts = append(contexts, contextsForApp(a)...)
+		case permTypes.CtxJob:
+			j, err := getJob(ctx, ctxValue)
+			if err != nil {
+				return nil, err
+			}
+			contexts = append(contexts, contextsForJob(j)...)
+		default:
+			contexts = append(contexts, permission.Context(ctxType, ctxValue))
+		}
+	}
+	return contexts, nil
+}
+
+func mapPermissionSimulationError(err error) error {
+	switch err {
+	case authTypes.ErrUserNotFound, authTypes.ErrTeamTokenNotFound, authTypes.ErrGroupNotFound:
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case auth.ErrInvalidSubjectType:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if perr, ok := err.(*permTypes.ErrPermissionNotFound); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: perr.Error()}
+	}
+	return err
+}
+
+type permissionCheckResult struct {
+	Allowed bool                   `json:"allowed"`
+	Grants  []auth.PermissionGrant `json:"grants"`
+}
+
+// title: check permission
+// path: /permissions/check
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Subject not found
+func checkPermission(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermRoleUpdate) {
+		return permission.ErrUnauthorized
+	}
+	query := r.URL.Query()
+	contexts, err := simulationContexts(ctx, query["context"])
+	if err != nil {
+		return err
+	}
+	grants, err := auth.ExplainPermission(ctx, query.Get("subject_type"), query.Get("subject"), query.Get("permission"), contexts...)
+	if err != nil {
+		return mapPermissionSimulationError(err)
+	}
+	if grants == nil {
+		grants = []auth.PermissionGrant{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(permissionCheckResult{
+		Allowed: len(grants) > 0,
+		Grants:  grants,
+	})
+}
+
+// title: list permission holders
+// path: /permissions/subjects
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+func listPermissionHolders(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermRoleUpdate) {
+		return permission.ErrUnauthorized
+	}
+	query := r.URL.Query()
+	contexts, err := simulationContexts(ctx, query["context"])
+	if err != nil {
+		return err
+	}
+	subjects, err := auth.PermissionHolders(ctx, query.Get("permission"), contexts...)
+	if err != nil {
+		return mapPermissionSimulationError(err)
+	}
+	if len(subjects) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(subjects)
+}
@@ -13,10 +13,13 @@ import (
 	"net/http/httptest"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/roleexpiry"
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
@@ -519,6 +522,96 @@ func (s *S) TestAssignRole(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestAssignRoleTemporary(c *check.C) {
+	ctx := context.TODO()
+	role, err := permission.NewRole(ctx, "test", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(ctx, "app.create")
+	c.Assert(err, check.IsNil)
+	_, emptyToken := permissiontest.CustomUserWithPermission(c, nativeScheme, "user2")
+	roleBody := bytes.NewBufferString(fmt.Sprintf("email=%s&context=myteam&expires=2h&justification=incident+42", emptyToken.GetUserName()))
+	req, err := http.NewRequest(http.MethodPost, "/roles/test/user", roleBody)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "user1", permTypes.Permission{
+		Scheme:  permission.PermRoleUpdateAssign,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	}, permTypes.Permission{
+		Scheme:  permission.PermAppCreate,
+		Context: permission.Context(permTypes.CtxTeam, "myteam"),
+	})
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	emptyUser, err := emptyToken.User(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(emptyUser.Roles, check.HasLen, 1)
+	c.Assert(emptyUser.Roles[0].Justification, check.Equals, "incident 42")
+	c.Assert(emptyUser.Roles[0].ExpiresAt, check.NotNil)
+	c.Assert(time.Until(*emptyUser.Roles[0].ExpiresAt) > time.Hour, check.Equals, true)
+
+	req, err = http.NewRequest(http.MethodGet, "/users/info", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+emptyToken.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var info apiUser
+	err = json.NewDecoder(recorder.Body).Decode(&info)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Roles, check.HasLen, 1)
+	c.Assert(info.Roles[0].Name, check.Equals, "test")
+	c.Assert(info.Roles[0].Justification, check.Equals, "incident 42")
+	c.Assert(info.Roles[0].ExpiresAt.Equal(*emptyUser.Roles[0].ExpiresAt), check.Equals, true)
+}
+
+func (s *S) TestAssignRoleTemporaryInvalidExpires(c *check.C) {
+	_, err := permission.NewRole(context.TODO(), "test", "team", "")
+	c.Assert(err, check.IsNil)
+	config.Set("roles:max-temporary-assignment", "8h")
+	defer config.Unset("roles:max-temporary-assignment")
+	_, emptyToken := permissiontest.CustomUserWithPermission(c, nativeScheme, "user2")
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "user1", permTypes.Permission{
+		Scheme:  permission.PermRoleUpdateAssign,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	for _, expires := range []string{"tomorrow", "-1h", "9h"} {
+		roleBody := bytes.NewBufferString(fmt.Sprintf("email=%s&context=myteam&expires=%s", emptyToken.GetUserName(), expires))
+		req, err := http.NewRequest(http.MethodPost, "/roles/test/user", roleBody)
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "bearer "+token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, req)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("expires %q", expires))
+	}
+}
+
+func (s *S) TestRevokeExpiredRoles(c *check.C) {
+	ctx := context.TODO()
+	_, err := permission.NewRole(ctx, "test", "team", "")
+	c.Assert(err, check.IsNil)
+	user, _ := permissiontest.CustomUserWithPermission(c, nativeScheme, "user2")
+	err = user.AddTemporaryRole(ctx, "test", "myteam", time.Now().Add(-time.Second), "incident 42")
+	c.Assert(err, check.IsNil)
+	err = roleexpiry.RevokeExpired(ctx)
+	c.Assert(err, check.IsNil)
+	user, err = auth.GetUserByEmail(ctx, user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(user.Roles, check.HasLen, 0)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: "test"},
+		Kind:   roleexpiry.EventKind,
+		StartCustomData: map[string]interface{}{
+			"email":         user.Email,
+			"context":       "myteam",
+			"justification": "incident 42",
+		},
+	}, eventtest.HasEvent)
+}
+
 func (s *S) TestAssignRoleNotFound(c *check.C) {
 	_, emptyToken := permissiontest.CustomUserWithPermission(c, nativeScheme, "user2")
 	roleBody := bytes.NewBufferString(fmt.Sprintf("email=%s&context=myteam", emptyToken.GetUserName()))
@@ -1882,3 +1975,131 @@ func (s *S) TestDissociateRoleFromAuthGroupNotAuthorized(c *check.C) {
 		},
 	})
 }
+
+func (s *S) TestCheckPermission(c *check.C) {
+	app1 := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &app1, s.user)
+	c.Assert(err, check.IsNil)
+	role, err := permission.NewRole(context.TODO(), "deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	u := auth.User{Email: "dev@example.com", Password: "123456", Groups: []string{"devs"}}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(context.TODO(), "devs", "deployer", s.team.Name)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleUpdate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req, err := http.NewRequest(http.MethodGet, "/1.32/permissions/check?subject_type=user&subject=dev@example.com&permission=app.deploy&context=app:myapp", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	var result permissionCheckResult
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, permissionCheckResult{
+		Allowed: true,
+		Grants: []auth.PermissionGrant{
+			{Role: "deployer", Permission: "app.deploy", ContextType: permTypes.CtxTeam, ContextValue: s.team.Name, Group: "devs"},
+		},
+	})
+	req, err = http.NewRequest(http.MethodGet, "/1.32/permissions/check?subject_type=user&subject=dev@example.com&permission=app.update&context=app:myapp", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Body.String(), check.Equals, "{\"allowed\":false,\"grants\":[]}\n")
+}
+
+func (s *S) TestCheckPermissionInvalidData(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleUpdate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"subject_type=team&subject=myteam&permission=app.deploy", http.StatusBadRequest},
+		{"subject_type=user&subject=majortom@groundcontrol.com&permission=app.unknown", http.StatusBadRequest},
+		{"subject_type=user&subject=majortom@groundcontrol.com&permission=app.deploy&context=invalid:x", http.StatusBadRequest},
+		{"subject_type=user&subject=nobody@example.com&permission=app.deploy", http.StatusNotFound},
+		{"subject_type=group&subject=nogroup&permission=app.deploy", http.StatusNotFound},
+		{"subject_type=user&subject=majortom@groundcontrol.com&permission=app.deploy&context=app:unknown", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, "/1.32/permissions/check?"+tt.query, nil)
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Authorization", "bearer "+token.GetValue())
+		rec := httptest.NewRecorder()
+		s.testServer.ServeHTTP(rec, req)
+		c.Assert(rec.Code, check.Equals, tt.code, check.Commentf("query: %s", tt.query))
+	}
+}
+
+func (s *S) TestCheckPermissionUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	for _, path := range []string{"/1.32/permissions/check", "/1.32/permissions/subjects"} {
+		req, err := http.NewRequest(http.MethodGet, path+"?permission=app.deploy", nil)
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Authorization", "bearer "+token.GetValue())
+		rec := httptest.NewRecorder()
+		s.testServer.ServeHTTP(rec, req)
+		c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+	}
+}
+
+func (s *S) TestListPermissionHolders(c *check.C) {
+	app1 := appTypes.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &app1, s.user)
+	c.Assert(err, check.IsNil)
+	role, err := permission.NewRole(context.TODO(), "deployer", "app", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u.AddRole(context.TODO(), "deployer", "myapp")
+	c.Assert(err, check.IsNil)
+	teamToken, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:  s.team.Name,
+		Roles: []authTypes.RoleInstance{{Name: "deployer", ContextValue: "otherapp"}},
+	}, s.token)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermRoleUpdate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req, err := http.NewRequest(http.MethodGet, "/1.32/permissions/subjects?permission=app.deploy&context=app:myapp", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	var subjects []auth.PermissionSubject
+	err = json.Unmarshal(rec.Body.Bytes(), &subjects)
+	c.Assert(err, check.IsNil)
+	holders := map[string]auth.PermissionSubject{}
+	for _, subject := range subjects {
+		holders[subject.Type+":"+subject.Name] = subject
+	}
+	c.Assert(holders["user:"+s.user.Email].Grants, check.HasLen, 1)
+	c.Assert(holders["user:"+s.user.Email].Grants[0].Permission, check.Equals, "*")
+	c.Assert(holders["user:dev@example.com"].Grants, check.DeepEquals, []auth.PermissionGrant{
+		{Role: "deployer", Permission: "app.deploy", ContextType: permTypes.CtxApp, ContextValue: "myapp"},
+	})
+	_, ok := holders["token:"+teamToken.TokenID]
+	c.Assert(ok, check.Equals, false)
+	_, ok = holders["user:majortom@groundcontrol.com"]
+	c.Assert(ok, check.Equals, false)
+}
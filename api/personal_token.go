@@ -0,0 +1,144 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: personal token list
+// path: /users/personal-tokens
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List tokens
+//	204: No content
+//	401: Unauthorized
+func personalTokenList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	email := r.URL.Query().Get("user")
+	if email == "" {
+		email = t.GetUserName()
+	}
+	if !permission.Check(ctx, t, permission.PermUserTokenRead, permission.Context(permTypes.CtxUser, email)) {
+		return permission.ErrUnauthorized
+	}
+	tokens, err := servicemanager.PersonalToken.List(ctx, email)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(tokens)
+}
+
+// title: personal token create
+// path: /users/personal-tokens
+// method: POST
+// produce: application/json
+// responses:
+//
+//	201: Token created
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Forbidden
+//	409: Token already exists
+func personalTokenCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var args authTypes.PersonalTokenCreateArgs
+	err = ParseInput(r, &args)
+	if err != nil {
+		return err
+	}
+	if t.Engine() == "personal" {
+		return &errors.HTTP{Code: http.StatusForbidden, Message: "personal tokens cannot be used to create other personal tokens"}
+	}
+	u, err := t.User(ctx)
+	if err != nil {
+		return err
+	}
+	if u.FromToken {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "personal tokens can only be created by users"}
+	}
+	email := u.Email
+	if !permission.Check(ctx, t, permission.PermUserTokenCreate, permission.Context(permTypes.CtxUser, email)) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserTokenCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	token, err := servicemanager.PersonalToken.Create(ctx, email, args)
+	if err == authTypes.ErrPersonalTokenAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(token)
+}
+
+// title: personal token delete
+// path: /users/personal-tokens/{token_id}
+// method: DELETE
+// responses:
+//
+//	200: Token removed
+//	401: Unauthorized
+//	404: Token not found
+func personalTokenDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	tokenID := r.URL.Query().Get(":token_id")
+	token, err := servicemanager.PersonalToken.FindByTokenID(ctx, tokenID)
+	if err == authTypes.ErrPersonalTokenNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermUserTokenDelete, permission.Context(permTypes.CtxUser, token.UserEmail)) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     userTarget(token.UserEmail),
+		Kind:       permission.PermUserTokenDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, token.UserEmail)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.PersonalToken.Delete(ctx, tokenID)
+	if err == authTypes.ErrPersonalTokenNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
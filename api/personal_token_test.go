@@ -0,0 +1,171 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/tokenexpiry"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPersonalTokenCreate(c *check.C) {
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	token, err := nativeScheme.Login(context.TODO(), map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"name":"ci","description":"ci deploys","expires_in":3600}`)
+	req, err := http.NewRequest(http.MethodPost, "/1.32/users/personal-tokens", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	var created authTypes.PersonalToken
+	err = json.Unmarshal(rec.Body.Bytes(), &created)
+	c.Assert(err, check.IsNil)
+	c.Assert(created.Name, check.Equals, "ci")
+	c.Assert(created.UserEmail, check.Equals, u.Email)
+	c.Assert(created.Token, check.Not(check.Equals), "")
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(u.Email),
+		Owner:  u.Email,
+		Kind:   "user.token.create",
+	}, eventtest.HasEvent)
+
+	req, err = http.NewRequest(http.MethodGet, "/1.32/users/info", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+created.Token)
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+
+	req, err = http.NewRequest(http.MethodPost, "/1.32/users/personal-tokens", strings.NewReader(`{"name":"other"}`))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+created.Token)
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestPersonalTokenCreateConflict(c *check.C) {
+	_, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "ci"})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPost, "/1.32/users/personal-tokens", strings.NewReader(`{"name":"ci"}`))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestPersonalTokenList(c *check.C) {
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	token, err := nativeScheme.Login(context.TODO(), map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.PersonalToken.Create(context.TODO(), u.Email, authTypes.PersonalTokenCreateArgs{Name: "ci"})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/1.32/users/personal-tokens", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	var tokens []authTypes.PersonalToken
+	err = json.Unmarshal(rec.Body.Bytes(), &tokens)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 1)
+	c.Assert(tokens[0].Name, check.Equals, "ci")
+	c.Assert(tokens[0].Token, check.Equals, "")
+
+	req, err = http.NewRequest(http.MethodGet, "/1.32/users/personal-tokens?user="+s.user.Email, nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+
+	req, err = http.NewRequest(http.MethodGet, "/1.32/users/personal-tokens?user="+u.Email, nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+}
+
+func (s *S) TestPersonalTokenDelete(c *check.C) {
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	_, err := nativeScheme.Create(context.TODO(), &u)
+	c.Assert(err, check.IsNil)
+	token, err := nativeScheme.Login(context.TODO(), map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	own, err := servicemanager.PersonalToken.Create(context.TODO(), u.Email, authTypes.PersonalTokenCreateArgs{Name: "ci"})
+	c.Assert(err, check.IsNil)
+	other, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "ci"})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodDelete, "/1.32/users/personal-tokens/"+other.TokenID, nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+
+	req, err = http.NewRequest(http.MethodDelete, "/1.32/users/personal-tokens/"+own.TokenID, nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	_, err = servicemanager.PersonalToken.FindByTokenID(context.TODO(), own.TokenID)
+	c.Assert(err, check.Equals, authTypes.ErrPersonalTokenNotFound)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(u.Email),
+		Owner:  u.Email,
+		Kind:   "user.token.delete",
+	}, eventtest.HasEvent)
+
+	req, err = http.NewRequest(http.MethodDelete, "/1.32/users/personal-tokens/"+other.TokenID, nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+
+	req, err = http.NewRequest(http.MethodDelete, "/1.32/users/personal-tokens/unknown", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPersonalTokenExpiryWarning(c *check.C) {
+	token, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "ci", ExpiresIn: 3600})
+	c.Assert(err, check.IsNil)
+	err = tokenexpiry.WarnExpiring(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(s.user.Email),
+		Kind:   tokenexpiry.EventKind,
+		StartCustomData: map[string]interface{}{
+			"token_id": token.TokenID,
+			"name":     "ci",
+		},
+	}, eventtest.HasEvent)
+}
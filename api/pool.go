@@ -7,8 +7,10 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
@@ -16,6 +18,8 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 )
@@ -424,3 +428,180 @@ func poolConstraintSet(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 	}
 	return pool.SetPoolConstraint(ctx, &poolConstraint)
 }
+
+// title: pool freeze list
+// path: /pools/{name}/freezes
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	401: Unauthorized
+//	404: Pool not found
+func poolFreezeList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	freezes, err := listPoolFreezes(r, t)
+	if err != nil {
+		return err
+	}
+	if len(freezes) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(freezes)
+}
+
+// title: pool freeze calendar
+// path: /pools/{name}/freezes/ical
+// method: GET
+// produce: text/calendar
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Pool not found
+func poolFreezeICal(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	freezes, err := listPoolFreezes(r, t)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	return event.WriteICal(w, freezes)
+}
+
+func listPoolFreezes(r *http.Request, t auth.Token) ([]event.Freeze, error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolRead,
+		permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return nil, permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(ctx, poolName)
+	if err == pool.ErrPoolNotFound {
+		return nil, &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var since time.Time
+	if includeEnded, _ := strconv.ParseBool(r.URL.Query().Get("ended")); !includeEnded {
+		since = time.Now()
+	}
+	return event.ListFreezes(ctx, poolName, since)
+}
+
+// title: pool freeze add
+// path: /pools/{name}/freezes
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	201: Freeze created
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Pool not found
+//	409: Freeze already exists
+func poolFreezeAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermPoolUpdateFreezeAdd,
+		permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	var freeze event.Freeze
+	err = ParseInput(r, &freeze)
+	if err != nil {
+		return err
+	}
+	freeze.Pool = poolName
+	freeze.CreatedBy = t.GetUserName()
+	freeze.CreatedAt = time.Time{}
+	_, err = pool.GetPoolByName(ctx, poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	for _, team := range []string{freeze.Owner, freeze.Team} {
+		if team == "" {
+			continue
+		}
+		_, err = servicemanager.Team.FindByName(ctx, team)
+		if err == authTypes.ErrTeamNotFound {
+			return &terrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("team %q not found", team)}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateFreezeAdd,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = event.AddFreeze(ctx, &freeze)
+	if err == event.ErrFreezeAlreadyExists {
+		return &terrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(freeze)
+}
+
+// title: pool freeze remove
+// path: /pools/{name}/freezes/{freeze}
+// method: DELETE
+// responses:
+//
+//	200: Freeze removed
+//	401: Unauthorized
+//	404: Freeze not found
+func poolFreezeRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	freezeName := r.URL.Query().Get(":freeze")
+	allowed := permission.Check(ctx, t, permission.PermPoolUpdateFreezeRemove,
+		permission.Context(permTypes.CtxPool, poolName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	freeze, err := event.GetFreeze(ctx, poolName, freezeName)
+	if err == event.ErrFreezeNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateFreezeRemove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: freeze,
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = event.RemoveFreeze(ctx, poolName, freezeName)
+	if err == event.ErrFreezeNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
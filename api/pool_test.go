@@ -12,11 +12,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/cezarsa/form"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision/pool"
@@ -836,3 +838,150 @@ func (s *S) TestPoolGetHandler(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(pool, check.DeepEquals, expected)
 }
+
+func (s *S) TestPoolFreezeAdd(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	freeze := event.Freeze{
+		Name:        "black-friday",
+		Description: "no changes during sales",
+		Owner:       "sre",
+		StartTime:   start,
+		EndTime:     start.Add(48 * time.Hour),
+	}
+	values, err := form.EncodeToValues(freeze)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPost, "/pools/pool1/freezes", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	dbFreeze, err := event.GetFreeze(context.TODO(), "pool1", "black-friday")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbFreeze.Owner, check.Equals, "sre")
+	c.Assert(dbFreeze.Description, check.Equals, "no changes during sales")
+	c.Assert(dbFreeze.CreatedBy, check.Equals, s.token.GetUserName())
+	c.Assert(dbFreeze.StartTime.Equal(start), check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.freeze.add",
+	}, eventtest.HasEvent)
+	req, err = http.NewRequest(http.MethodGet, "/pools/pool1", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var info pool.PoolInfo
+	err = json.NewDecoder(rec.Body).Decode(&info)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Freezes, check.HasLen, 1)
+	c.Assert(info.Freezes[0].Name, check.Equals, "black-friday")
+}
+
+func (s *S) TestPoolFreezeAddInvalidOwner(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return nil, authTypes.ErrTeamNotFound
+	}
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	start := time.Now().UTC()
+	values, err := form.EncodeToValues(event.Freeze{Name: "f1", Owner: "unknown", StartTime: start, EndTime: start.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPost, "/pools/pool1/freezes", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Equals, "team \"unknown\" not found\n")
+}
+
+func (s *S) TestPoolFreezeAddUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermPoolUpdateFreezeAdd,
+		Context: permission.Context(permTypes.CtxPool, "other-pool"),
+	})
+	req, err := http.NewRequest(http.MethodPost, "/pools/pool1/freezes", strings.NewReader("name=f1"))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestPoolFreezeList(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	now := time.Now()
+	err = event.AddFreeze(context.TODO(), &event.Freeze{Name: "f1", Pool: "pool1", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/pools/pool1/freezes", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var freezes []event.Freeze
+	err = json.NewDecoder(rec.Body).Decode(&freezes)
+	c.Assert(err, check.IsNil)
+	c.Assert(freezes, check.HasLen, 1)
+	c.Assert(freezes[0].Name, check.Equals, "f1")
+	c.Assert(freezes[0].Owner, check.Equals, "sre")
+}
+
+func (s *S) TestPoolFreezeListPoolNotFound(c *check.C) {
+	req, err := http.NewRequest(http.MethodGet, "/pools/unknown/freezes", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPoolFreezeICal(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	start := time.Date(2036, 11, 27, 0, 0, 0, 0, time.UTC)
+	err = event.AddFreeze(context.TODO(), &event.Freeze{Name: "f1", Pool: "pool1", Owner: "sre", StartTime: start, EndTime: start.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/pools/pool1/freezes/ical", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "text/calendar; charset=utf-8")
+	c.Assert(rec.Body.String(), check.Matches, `(?s)BEGIN:VCALENDAR\r\n.*\r\nDTSTART:20361127T000000Z\r\nDTEND:20361127T010000Z\r\nSUMMARY:Change freeze f1 on pool pool1\r\n.*END:VCALENDAR\r\n`)
+}
+
+func (s *S) TestPoolFreezeRemove(c *check.C) {
+	now := time.Now()
+	err := event.AddFreeze(context.TODO(), &event.Freeze{Name: "f1", Pool: "pool1", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodDelete, "/pools/pool1/freezes/f1", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	_, err = event.GetFreeze(context.TODO(), "pool1", "f1")
+	c.Assert(err, check.Equals, event.ErrFreezeNotFound)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.freeze.remove",
+	}, eventtest.HasEvent)
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
@@ -94,6 +94,10 @@ func addAutoScaleUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 			Message: fmt.Sprintf("unable to validate autoscale spec: %v", err),
 		}
 	}
+	if isDryRun(r) {
+		result, dryRunErr := app.AutoScaleDryRun(ctx, a, spec)
+		return writeDryRunResult(w, result, dryRunErr)
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     appTarget(appName),
 		Kind:       permission.PermAppUpdateUnitAutoscaleAdd,
@@ -180,6 +184,10 @@ func removeAutoScaleUnits(w http.ResponseWriter, r *http.Request, t auth.Token)
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	if isDryRun(r) {
+		result, dryRunErr := app.RemoveAutoScaleDryRun(ctx, a, process)
+		return writeDryRunResult(w, result, dryRunErr)
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     appTarget(appName),
 		Kind:       permission.PermAppUpdateUnitAutoscaleRemove,
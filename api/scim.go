@@ -0,0 +1,809 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/validation"
+)
+
+const (
+	scimUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	scimContentType = "application/scim+json"
+	scimOwner       = "scim"
+	scimBasePath    = "/scim/v2"
+)
+
+var (
+	scimFilterRegexp        = regexp.MustCompile(`^\s*([\w.]+)\s+(?i:eq)\s+"([^"]*)"\s*$`)
+	scimMemberFilterRegexp  = regexp.MustCompile(`^members\[\s*value\s+(?i:eq)\s+"([^"]*)"\s*\]$`)
+	errSCIMProvisioningOff  = newSCIMError(http.StatusUnauthorized, "", "SCIM provisioning is not enabled")
+	errSCIMInvalidToken     = newSCIMError(http.StatusUnauthorized, "", "invalid SCIM token")
+	errSCIMUserNotFound     = newSCIMError(http.StatusNotFound, "", "user not found")
+	errSCIMGroupNotFound    = newSCIMError(http.StatusNotFound, "", "group not found")
+	errSCIMInvalidUserName  = newSCIMError(http.StatusBadRequest, "invalidValue", "userName must be a valid email")
+	errSCIMMissingGroupName = newSCIMError(http.StatusBadRequest, "invalidValue", "displayName is required")
+)
+
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+	code     int
+}
+
+func newSCIMError(code int, scimType, detail string) *scimError {
+	return &scimError{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(code),
+		ScimType: scimType,
+		Detail:   detail,
+		code:     code,
+	}
+}
+
+func (e *scimError) Error() string {
+	return e.Detail
+}
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimUser struct {
+	Schemas  []string    `json:"schemas"`
+	ID       string      `json:"id,omitempty"`
+	UserName string      `json:"userName"`
+	Active   *bool       `json:"active,omitempty"`
+	Emails   []scimEmail `json:"emails,omitempty"`
+	Password string      `json:"password,omitempty"`
+	Meta     *scimMeta   `json:"meta,omitempty"`
+}
+
+type scimMember struct {
+	Value string `json:"value"`
+}
+
+type scimGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members"`
+	Meta        *scimMeta    `json:"meta,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+type scimPatchRequest struct {
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+type scimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// scimHandler serves the SCIM provisioning API. Requests are authenticated
+// by the token set in auth:scim:token and errors are rendered as SCIM error
+// responses.
+type scimHandler func(http.ResponseWriter, *http.Request) error
+
+func (fn scimHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := scimAuthenticate(r)
+	if err == nil {
+		err = fn(w, r)
+	}
+	if err != nil {
+		writeSCIMError(w, r, err)
+	}
+}
+
+func scimAuthenticate(r *http.Request) error {
+	expected, _ := config.GetString("auth:scim:token")
+	if expected == "" {
+		return errSCIMProvisioningOff
+	}
+	token, err := auth.ParseToken(r.Header.Get("Authorization"))
+	if err != nil || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return errSCIMInvalidToken
+	}
+	return nil
+}
+
+func writeSCIMError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimErr *scimError
+	switch cause := errors.Cause(err).(type) {
+	case *scimError:
+		scimErr = cause
+	case *tsuruErrors.HTTP:
+		scimErr = newSCIMError(cause.Code, "", cause.Message)
+	case *tsuruErrors.ValidationError:
+		scimErr = newSCIMError(http.StatusBadRequest, "invalidValue", cause.Message)
+	default:
+		scimErr = newSCIMError(http.StatusInternalServerError, "", err.Error())
+	}
+	if scimErr.code >= http.StatusInternalServerError {
+		log.Errorf("failure running SCIM request %s %s: %s", r.Method, r.URL.Path, err)
+	}
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(scimErr.code)
+	json.NewEncoder(w).Encode(scimErr)
+}
+
+func writeSCIMResource(w http.ResponseWriter, code int, resource any) error {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(code)
+	return json.NewEncoder(w).Encode(resource)
+}
+
+// parseSCIMFilter parses the single attribute equality filters sent by
+// identity providers to look up resources, like userName eq "foo@bar.com".
+func parseSCIMFilter(filter string, attrs ...string) (string, string, error) {
+	if filter == "" {
+		return "", "", nil
+	}
+	parts := scimFilterRegexp.FindStringSubmatch(filter)
+	if parts == nil {
+		return "", "", newSCIMError(http.StatusBadRequest, "invalidFilter", fmt.Sprintf("unsupported filter %q", filter))
+	}
+	for _, attr := range attrs {
+		if strings.EqualFold(parts[1], attr) {
+			return attr, parts[2], nil
+		}
+	}
+	return "", "", newSCIMError(http.StatusBadRequest, "invalidFilter", fmt.Sprintf("filtering by %q is not supported", parts[1]))
+}
+
+func scimList(r *http.Request, resources []any) scimListResponse {
+	total := len(resources)
+	startIndex, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	resources = resources[min(startIndex-1, total):]
+	if count, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && count >= 0 && count < len(resources) {
+		resources = resources[:count]
+	}
+	return scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+func parseSCIMBool(data json.RawMessage) (bool, error) {
+	var value any
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return false, err
+	}
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		// Some identity providers send booleans as strings, like "False".
+		return strconv.ParseBool(strings.ToLower(v))
+	}
+	return false, errors.Errorf("invalid boolean value %s", data)
+}
+
+func scimPatchError(op scimPatchOperation) error {
+	return newSCIMError(http.StatusBadRequest, "invalidPath", fmt.Sprintf("unsupported patch operation %q on %q", op.Op, op.Path))
+}
+
+func newSCIMUser(u *auth.User) scimUser {
+	active := !u.Disabled
+	return scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       u.Email,
+		UserName: u.Email,
+		Active:   &active,
+		Emails:   []scimEmail{{Value: u.Email, Primary: true}},
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Location:     scimBasePath + "/Users/" + url.PathEscape(u.Email),
+		},
+	}
+}
+
+func scimUserEmail(user scimUser) string {
+	if validation.ValidateEmail(user.UserName) {
+		return user.UserName
+	}
+	for _, email := range user.Emails {
+		if email.Primary && validation.ValidateEmail(email.Value) {
+			return email.Value
+		}
+	}
+	return ""
+}
+
+func getSCIMUser(r *http.Request) (*auth.User, error) {
+	u, err := auth.GetUserByEmail(r.Context(), r.URL.Query().Get(":id"))
+	if err != nil {
+		var validationErr *tsuruErrors.ValidationError
+		if err == authTypes.ErrUserNotFound || errors.As(err, &validationErr) {
+			return nil, errSCIMUserNotFound
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+func scimUserEvent(r *http.Request, email string, kind *permTypes.PermissionScheme, customData any) (*event.Event, error) {
+	return event.New(r.Context(), &event.Opts{
+		Target:     userTarget(email),
+		Kind:       kind,
+		RawOwner:   eventTypes.Owner{Type: eventTypes.OwnerTypeInternal, Name: scimOwner},
+		RemoteAddr: r.RemoteAddr,
+		CustomData: customData,
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, email)),
+	})
+}
+
+// setSCIMUserActive disables or reenables the user, recording an event when
+// the status changes.
+func setSCIMUserActive(r *http.Request, u *auth.User, active bool) (err error) {
+	if u.Disabled == !active {
+		return nil
+	}
+	ctx := r.Context()
+	evt, err := scimUserEvent(r, u.Email, permission.PermUserUpdateStatus, map[string]bool{"active": active})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	if active {
+		return u.Enable(ctx)
+	}
+	return u.Disable(ctx, app.AuthScheme)
+}
+
+func randomSCIMPassword() (string, error) {
+	data := make([]byte, 16)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// title: scim list users
+// path: /scim/v2/Users
+// method: GET
+// produce: application/scim+json
+// responses:
+//
+//	200: OK
+//	400: Invalid filter
+//	401: Unauthorized
+func scimUserList(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	attr, value, err := parseSCIMFilter(r.URL.Query().Get("filter"), "userName", "id")
+	if err != nil {
+		return err
+	}
+	var users []auth.User
+	if attr != "" {
+		u, err := auth.GetUserByEmail(ctx, value)
+		if err == nil {
+			users = append(users, *u)
+		}
+	} else {
+		users, err = auth.ListUsers(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	resources := make([]any, 0, len(users))
+	for i := range users {
+		resources = append(resources, newSCIMUser(&users[i]))
+	}
+	return writeSCIMResource(w, http.StatusOK, scimList(r, resources))
+}
+
+// title: scim get user
+// path: /scim/v2/Users/{id}
+// method: GET
+// produce: application/scim+json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: User not found
+func scimUserGet(w http.ResponseWriter, r *http.Request) error {
+	u, err := getSCIMUser(r)
+	if err != nil {
+		return err
+	}
+	return writeSCIMResource(w, http.StatusOK, newSCIMUser(u))
+}
+
+// title: scim create user
+// path: /scim/v2/Users
+// method: POST
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//
+//	201: User created
+//	400: Invalid data
+//	401: Unauthorized
+//	409: User already exists
+func scimUserCreate(w http.ResponseWriter, r *http.Request) (err error) {
+	ctx := r.Context()
+	var input scimUser
+	err = ParseJSON(r, &input)
+	if err != nil {
+		return err
+	}
+	email := scimUserEmail(input)
+	if email == "" {
+		return errSCIMInvalidUserName
+	}
+	if _, err = auth.GetUserByEmail(ctx, email); err == nil {
+		return newSCIMError(http.StatusConflict, "uniqueness", fmt.Sprintf("user %q already exists", email))
+	}
+	evt, err := scimUserEvent(r, email, permission.PermUserCreate, map[string]string{"email": email})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	u := &auth.User{Email: email, Password: input.Password}
+	if userScheme, ok := app.AuthScheme.(auth.UserScheme); ok {
+		if u.Password == "" {
+			// Users provisioned without a password must go through the
+			// password reset flow to log in.
+			u.Password, err = randomSCIMPassword()
+			if err != nil {
+				return err
+			}
+		}
+		u, err = userScheme.Create(ctx, u)
+	} else {
+		u.Password = ""
+		err = u.Create(ctx)
+	}
+	if err != nil {
+		return handleAuthError(err)
+	}
+	if input.Active != nil && !*input.Active {
+		err = u.Disable(ctx, app.AuthScheme)
+		if err != nil {
+			return err
+		}
+	}
+	return writeSCIMResource(w, http.StatusCreated, newSCIMUser(u))
+}
+
+// title: scim replace user
+// path: /scim/v2/Users/{id}
+// method: PUT
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	401: Unauthorized
+//	404: User not found
+func scimUserReplace(w http.ResponseWriter, r *http.Request) error {
+	u, err := getSCIMUser(r)
+	if err != nil {
+		return err
+	}
+	var input scimUser
+	err = ParseJSON(r, &input)
+	if err != nil {
+		return err
+	}
+	if email := scimUserEmail(input); email != "" && email != u.Email {
+		return newSCIMError(http.StatusBadRequest, "mutability", "userName cannot be changed")
+	}
+	if input.Active != nil {
+		err = setSCIMUserActive(r, u, *input.Active)
+		if err != nil {
+			return err
+		}
+	}
+	return writeSCIMResource(w, http.StatusOK, newSCIMUser(u))
+}
+
+// title: scim update user
+// path: /scim/v2/Users/{id}
+// method: PATCH
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	401: Unauthorized
+//	404: User not found
+func scimUserPatch(w http.ResponseWriter, r *http.Request) error {
+	u, err := getSCIMUser(r)
+	if err != nil {
+		return err
+	}
+	var input scimPatchRequest
+	err = ParseJSON(r, &input)
+	if err != nil {
+		return err
+	}
+	// Only the active attribute is kept by tsuru, patches to any other
+	// attribute are accepted and ignored.
+	for _, op := range input.Operations {
+		if !strings.EqualFold(op.Op, "replace") && !strings.EqualFold(op.Op, "add") {
+			continue
+		}
+		activeValue := op.Value
+		if op.Path == "" {
+			var values map[string]json.RawMessage
+			if json.Unmarshal(op.Value, &values) != nil {
+				return scimPatchError(op)
+			}
+			activeValue = values["active"]
+		} else if !strings.EqualFold(op.Path, "active") {
+			continue
+		}
+		if activeValue == nil {
+			continue
+		}
+		active, err := parseSCIMBool(activeValue)
+		if err != nil {
+			return newSCIMError(http.StatusBadRequest, "invalidValue", err.Error())
+		}
+		err = setSCIMUserActive(r, u, active)
+		if err != nil {
+			return err
+		}
+	}
+	return writeSCIMResource(w, http.StatusOK, newSCIMUser(u))
+}
+
+// title: scim delete user
+// path: /scim/v2/Users/{id}
+// method: DELETE
+// responses:
+//
+//	204: User removed
+//	401: Unauthorized
+//	404: User not found
+func scimUserDelete(w http.ResponseWriter, r *http.Request) (err error) {
+	ctx := r.Context()
+	u, err := getSCIMUser(r)
+	if err != nil {
+		return err
+	}
+	evt, err := scimUserEvent(r, u.Email, permission.PermUserDelete, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	groups, err := servicemanager.AuthGroup.ListByMember(ctx, u.Email)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		err = servicemanager.AuthGroup.RemoveMembers(ctx, group.Name, []string{u.Email})
+		if err != nil {
+			return err
+		}
+	}
+	err = servicemanager.PersonalToken.DeleteByUser(ctx, u.Email)
+	if err != nil {
+		return err
+	}
+	if userScheme, ok := app.AuthScheme.(auth.UserScheme); ok {
+		err = userScheme.Remove(ctx, u)
+	} else {
+		err = u.Delete(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func newSCIMGroup(group authTypes.Group) scimGroup {
+	members := make([]scimMember, 0, len(group.Members))
+	for _, member := range group.Members {
+		members = append(members, scimMember{Value: member})
+	}
+	return scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          group.Name,
+		DisplayName: group.Name,
+		Members:     members,
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Location:     scimBasePath + "/Groups/" + url.PathEscape(group.Name),
+		},
+	}
+}
+
+func scimMemberValues(members []scimMember) []string {
+	values := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.Value != "" && !slices.Contains(values, member.Value) {
+			values = append(values, member.Value)
+		}
+	}
+	return values
+}
+
+func findSCIMGroup(r *http.Request, name string) (*authTypes.Group, error) {
+	groups, err := servicemanager.AuthGroup.List(r.Context(), []string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, errSCIMGroupNotFound
+	}
+	return &groups[0], nil
+}
+
+func writeSCIMGroup(w http.ResponseWriter, r *http.Request, code int, name string) error {
+	group, err := findSCIMGroup(r, name)
+	if err != nil {
+		return err
+	}
+	return writeSCIMResource(w, code, newSCIMGroup(*group))
+}
+
+// title: scim list groups
+// path: /scim/v2/Groups
+// method: GET
+// produce: application/scim+json
+// responses:
+//
+//	200: OK
+//	400: Invalid filter
+//	401: Unauthorized
+func scimGroupList(w http.ResponseWriter, r *http.Request) error {
+	attr, value, err := parseSCIMFilter(r.URL.Query().Get("filter"), "displayName", "id")
+	if err != nil {
+		return err
+	}
+	var filter []string
+	if attr != "" {
+		filter = []string{value}
+	}
+	groups, err := servicemanager.AuthGroup.List(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+	resources := make([]any, 0, len(groups))
+	for _, group := range groups {
+		resources = append(resources, newSCIMGroup(group))
+	}
+	return writeSCIMResource(w, http.StatusOK, scimList(r, resources))
+}
+
+// title: scim get group
+// path: /scim/v2/Groups/{id}
+// method: GET
+// produce: application/scim+json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Group not found
+func scimGroupGet(w http.ResponseWriter, r *http.Request) error {
+	return writeSCIMGroup(w, r, http.StatusOK, r.URL.Query().Get(":id"))
+}
+
+// title: scim create group
+// path: /scim/v2/Groups
+// method: POST
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//
+//	201: Group created
+//	400: Invalid data
+//	401: Unauthorized
+//	409: Group already exists
+func scimGroupCreate(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	var input scimGroup
+	err := ParseJSON(r, &input)
+	if err != nil {
+		return err
+	}
+	if input.DisplayName == "" {
+		return errSCIMMissingGroupName
+	}
+	if _, err = findSCIMGroup(r, input.DisplayName); err == nil {
+		return newSCIMError(http.StatusConflict, "uniqueness", fmt.Sprintf("group %q already exists", input.DisplayName))
+	}
+	members := scimMemberValues(input.Members)
+	err = servicemanager.AuthGroup.SetMembers(ctx, input.DisplayName, members)
+	if err != nil {
+		return err
+	}
+	log.Debugf("[scim] group %q created with members %v", input.DisplayName, members)
+	return writeSCIMGroup(w, r, http.StatusCreated, input.DisplayName)
+}
+
+// title: scim replace group
+// path: /scim/v2/Groups/{id}
+// method: PUT
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Group not found
+func scimGroupReplace(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	group, err := findSCIMGroup(r, r.URL.Query().Get(":id"))
+	if err != nil {
+		return err
+	}
+	var input scimGroup
+	err = ParseJSON(r, &input)
+	if err != nil {
+		return err
+	}
+	if input.DisplayName != "" && input.DisplayName != group.Name {
+		return newSCIMError(http.StatusBadRequest, "mutability", "displayName cannot be changed")
+	}
+	members := scimMemberValues(input.Members)
+	err = servicemanager.AuthGroup.SetMembers(ctx, group.Name, members)
+	if err != nil {
+		return err
+	}
+	log.Debugf("[scim] group %q members set to %v", group.Name, members)
+	return writeSCIMGroup(w, r, http.StatusOK, group.Name)
+}
+
+// title: scim update group
+// path: /scim/v2/Groups/{id}
+// method: PATCH
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Group not found
+func scimGroupPatch(w http.ResponseWriter, r *http.Request) error {
+	group, err := findSCIMGroup(r, r.URL.Query().Get(":id"))
+	if err != nil {
+		return err
+	}
+	var input scimPatchRequest
+	err = ParseJSON(r, &input)
+	if err != nil {
+		return err
+	}
+	for _, op := range input.Operations {
+		err = applySCIMGroupPatch(r, group.Name, op)
+		if err != nil {
+			return err
+		}
+	}
+	return writeSCIMGroup(w, r, http.StatusOK, group.Name)
+}
+
+func applySCIMGroupPatch(r *http.Request, name string, op scimPatchOperation) error {
+	ctx := r.Context()
+	var members []scimMember
+	if op.Path == "" {
+		var values struct {
+			DisplayName string       `json:"displayName"`
+			Members     []scimMember `json:"members"`
+		}
+		if json.Unmarshal(op.Value, &values) != nil {
+			return scimPatchError(op)
+		}
+		if values.DisplayName != "" && values.DisplayName != name {
+			return newSCIMError(http.StatusBadRequest, "mutability", "displayName cannot be changed")
+		}
+		if values.Members == nil {
+			return nil
+		}
+		op.Path = "members"
+		members = values.Members
+	}
+	if parts := scimMemberFilterRegexp.FindStringSubmatch(op.Path); parts != nil && strings.EqualFold(op.Op, "remove") {
+		log.Debugf("[scim] removing %q from group %q", parts[1], name)
+		return servicemanager.AuthGroup.RemoveMembers(ctx, name, []string{parts[1]})
+	}
+	if !strings.EqualFold(op.Path, "members") {
+		return scimPatchError(op)
+	}
+	if members == nil && len(op.Value) > 0 {
+		if json.Unmarshal(op.Value, &members) != nil {
+			return scimPatchError(op)
+		}
+	}
+	values := scimMemberValues(members)
+	log.Debugf("[scim] %s members %v of group %q", strings.ToLower(op.Op), values, name)
+	switch strings.ToLower(op.Op) {
+	case "add":
+		return servicemanager.AuthGroup.AddMembers(ctx, name, values)
+	case "remove":
+		if len(op.Value) == 0 {
+			// Removing the members attribute without a filter drops every
+			// member of the group.
+			return servicemanager.AuthGroup.SetMembers(ctx, name, nil)
+		}
+		return servicemanager.AuthGroup.RemoveMembers(ctx, name, values)
+	case "replace":
+		return servicemanager.AuthGroup.SetMembers(ctx, name, values)
+	}
+	return scimPatchError(op)
+}
+
+// title: scim delete group
+// path: /scim/v2/Groups/{id}
+// method: DELETE
+// responses:
+//
+//	204: Group removed
+//	401: Unauthorized
+//	404: Group not found
+func scimGroupDelete(w http.ResponseWriter, r *http.Request) error {
+	name := r.URL.Query().Get(":id")
+	err := servicemanager.AuthGroup.Delete(r.Context(), name)
+	if err == authTypes.ErrGroupNotFound {
+		return errSCIMGroupNotFound
+	}
+	if err != nil {
+		return err
+	}
+	log.Debugf("[scim] group %q removed", name)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	check "gopkg.in/check.v1"
+)
+
+const scimTestToken = "scim-secret"
+
+func (s *S) scimRequest(c *check.C, method, path, body string) *httptest.ResponseRecorder {
+	config.Set("auth:scim:token", scimTestToken)
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, path, reader)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "Bearer "+scimTestToken)
+	req.Header.Set("Content-Type", scimContentType)
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *S) TestSCIMInvalidToken(c *check.C) {
+	req, err := http.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "Bearer "+scimTestToken)
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusUnauthorized)
+	config.Set("auth:scim:token", scimTestToken)
+	req.Header.Set("Authorization", "Bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusUnauthorized)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, scimContentType)
+	var scimErr scimError
+	err = json.Unmarshal(rec.Body.Bytes(), &scimErr)
+	c.Assert(err, check.IsNil)
+	c.Assert(scimErr.Status, check.Equals, "401")
+	c.Assert(scimErr.Schemas, check.DeepEquals, []string{scimErrorSchema})
+}
+
+func (s *S) TestSCIMUserCreate(c *check.C) {
+	rec := s.scimRequest(c, http.MethodPost, "/scim/v2/Users", `{"schemas":["`+scimUserSchema+`"],"userName":"new@example.com","active":true}`)
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	var result scimUser
+	err := json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.ID, check.Equals, "new@example.com")
+	c.Assert(*result.Active, check.Equals, true)
+	c.Assert(result.Password, check.Equals, "")
+	u, err := auth.GetUserByEmail(context.TODO(), "new@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(u.Disabled, check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget("new@example.com"),
+		Owner:  scimOwner,
+		Kind:   "user.create",
+	}, eventtest.HasEvent)
+	rec = s.scimRequest(c, http.MethodPost, "/scim/v2/Users", `{"userName":"new@example.com"}`)
+	c.Assert(rec.Code, check.Equals, http.StatusConflict)
+	c.Assert(rec.Body.String(), check.Matches, `.*"scimType":"uniqueness".*`)
+}
+
+func (s *S) TestSCIMUserCreateInvalidUserName(c *check.C) {
+	rec := s.scimRequest(c, http.MethodPost, "/scim/v2/Users", `{"userName":"someone"}`)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	rec = s.scimRequest(c, http.MethodPost, "/scim/v2/Users", `{"userName":"someone","emails":[{"value":"someone@example.com","primary":true}]}`)
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	_, err := auth.GetUserByEmail(context.TODO(), "someone@example.com")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestSCIMUserList(c *check.C) {
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	err := u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	rec := s.scimRequest(c, http.MethodGet, `/scim/v2/Users?filter=userName+eq+"dev@example.com"`, "")
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	var result struct {
+		TotalResults int        `json:"totalResults"`
+		Resources    []scimUser `json:"Resources"`
+	}
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.TotalResults, check.Equals, 1)
+	c.Assert(result.Resources[0].UserName, check.Equals, "dev@example.com")
+	rec = s.scimRequest(c, http.MethodGet, `/scim/v2/Users?filter=userName+eq+"nobody@example.com"`, "")
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.TotalResults, check.Equals, 0)
+	rec = s.scimRequest(c, http.MethodGet, "/scim/v2/Users?count=1", "")
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.TotalResults, check.Equals, 2)
+	c.Assert(result.Resources, check.HasLen, 1)
+	rec = s.scimRequest(c, http.MethodGet, `/scim/v2/Users?filter=title+co+"x"`, "")
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Matches, `.*"scimType":"invalidFilter".*`)
+}
+
+func (s *S) TestSCIMUserPatchDeactivate(c *check.C) {
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	err := u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	token, err := nativeScheme.Login(context.TODO(), map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	body := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Replace","path":"active","value":"False"}]}`
+	rec := s.scimRequest(c, http.MethodPatch, "/scim/v2/Users/dev@example.com", body)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	dbUser, err := auth.GetUserByEmail(context.TODO(), u.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbUser.Disabled, check.Equals, true)
+	_, err = nativeScheme.Auth(context.TODO(), "bearer "+token.GetValue())
+	c.Assert(err, check.NotNil)
+	_, err = nativeScheme.Login(context.TODO(), map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.Equals, auth.ErrUserDisabled)
+	c.Assert(eventtest.EventDesc{
+		Target:          userTarget(u.Email),
+		Owner:           scimOwner,
+		Kind:            permission.PermUserUpdateStatus.FullName(),
+		StartCustomData: map[string]any{"active": false},
+	}, eventtest.HasEvent)
+	body = `{"Operations":[{"op":"replace","value":{"active":true}}]}`
+	rec = s.scimRequest(c, http.MethodPatch, "/scim/v2/Users/dev@example.com", body)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	dbUser, err = auth.GetUserByEmail(context.TODO(), u.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbUser.Disabled, check.Equals, false)
+}
+
+func (s *S) TestSCIMUserReplace(c *check.C) {
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	err := u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	rec := s.scimRequest(c, http.MethodPut, "/scim/v2/Users/dev@example.com", `{"userName":"dev@example.com","active":false}`)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	dbUser, err := auth.GetUserByEmail(context.TODO(), u.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbUser.Disabled, check.Equals, true)
+	rec = s.scimRequest(c, http.MethodPut, "/scim/v2/Users/dev@example.com", `{"userName":"other@example.com"}`)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Matches, `.*"scimType":"mutability".*`)
+	rec = s.scimRequest(c, http.MethodPut, "/scim/v2/Users/nobody@example.com", `{"userName":"nobody@example.com"}`)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSCIMUserDelete(c *check.C) {
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	err := u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.SetMembers(context.TODO(), "devs", []string{u.Email, "other@example.com"})
+	c.Assert(err, check.IsNil)
+	rec := s.scimRequest(c, http.MethodDelete, "/scim/v2/Users/dev@example.com", "")
+	c.Assert(rec.Code, check.Equals, http.StatusNoContent, check.Commentf("body: %s", rec.Body.String()))
+	_, err = auth.GetUserByEmail(context.TODO(), u.Email)
+	c.Assert(err, check.NotNil)
+	groups, err := servicemanager.AuthGroup.List(context.TODO(), []string{"devs"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups[0].Members, check.DeepEquals, []string{"other@example.com"})
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(u.Email),
+		Owner:  scimOwner,
+		Kind:   "user.delete",
+	}, eventtest.HasEvent)
+	rec = s.scimRequest(c, http.MethodDelete, "/scim/v2/Users/dev@example.com", "")
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSCIMGroupCreateAndGet(c *check.C) {
+	rec := s.scimRequest(c, http.MethodPost, "/scim/v2/Groups", `{"schemas":["`+scimGroupSchema+`"],"displayName":"devs","members":[{"value":"a@example.com"},{"value":"b@example.com"}]}`)
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	var result scimGroup
+	err := json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.ID, check.Equals, "devs")
+	c.Assert(result.Members, check.DeepEquals, []scimMember{{Value: "a@example.com"}, {Value: "b@example.com"}})
+	rec = s.scimRequest(c, http.MethodPost, "/scim/v2/Groups", `{"displayName":"devs"}`)
+	c.Assert(rec.Code, check.Equals, http.StatusConflict)
+	rec = s.scimRequest(c, http.MethodGet, "/scim/v2/Groups/devs", "")
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	rec = s.scimRequest(c, http.MethodGet, "/scim/v2/Groups/unknown", "")
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+	rec = s.scimRequest(c, http.MethodGet, `/scim/v2/Groups?filter=displayName+eq+"devs"`, "")
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Body.String(), check.Matches, `.*"totalResults":1.*`)
+}
+
+func (s *S) TestSCIMGroupPatchMembers(c *check.C) {
+	err := servicemanager.AuthGroup.SetMembers(context.TODO(), "devs", []string{"a@example.com"})
+	c.Assert(err, check.IsNil)
+	body := `{"Operations":[
+		{"op":"add","path":"members","value":[{"value":"b@example.com"},{"value":"c@example.com"}]},
+		{"op":"remove","path":"members[value eq \"a@example.com\"]"},
+		{"op":"remove","path":"members","value":[{"value":"c@example.com"}]}
+	]}`
+	rec := s.scimRequest(c, http.MethodPatch, "/scim/v2/Groups/devs", body)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	groups, err := servicemanager.AuthGroup.List(context.TODO(), []string{"devs"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups[0].Members, check.DeepEquals, []string{"b@example.com"})
+	body = `{"Operations":[{"op":"replace","value":{"members":[{"value":"d@example.com"}]}}]}`
+	rec = s.scimRequest(c, http.MethodPatch, "/scim/v2/Groups/devs", body)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	groups, err = servicemanager.AuthGroup.List(context.TODO(), []string{"devs"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups[0].Members, check.DeepEquals, []string{"d@example.com"})
+	body = `{"Operations":[{"op":"add","path":"externalId","value":"x"}]}`
+	rec = s.scimRequest(c, http.MethodPatch, "/scim/v2/Groups/devs", body)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestSCIMGroupMembershipGrantsPermissions(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(context.TODO(), "devs", "deployer", s.team.Name)
+	c.Assert(err, check.IsNil)
+	u := auth.User{Email: "dev@example.com", Password: "123456"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	rec := s.scimRequest(c, http.MethodPatch, "/scim/v2/Groups/devs", `{"Operations":[{"op":"add","path":"members","value":[{"value":"dev@example.com"}]}]}`)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	perms, err := u.Permissions(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppDeploy, permission.Context("team", s.team.Name)), check.Equals, true)
+	rec = s.scimRequest(c, http.MethodPatch, "/scim/v2/Groups/devs", `{"Operations":[{"op":"remove","path":"members[value eq \"dev@example.com\"]"}]}`)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	perms, err = u.Permissions(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppDeploy, permission.Context("team", s.team.Name)), check.Equals, false)
+}
+
+func (s *S) TestSCIMGroupDelete(c *check.C) {
+	err := servicemanager.AuthGroup.SetMembers(context.TODO(), "devs", []string{"a@example.com"})
+	c.Assert(err, check.IsNil)
+	rec := s.scimRequest(c, http.MethodDelete, "/scim/v2/Groups/devs", "")
+	c.Assert(rec.Code, check.Equals, http.StatusNoContent, check.Commentf("body: %s", rec.Body.String()))
+	rec = s.scimRequest(c, http.MethodDelete, "/scim/v2/Groups/devs", "")
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
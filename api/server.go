@@ -40,10 +40,13 @@ import (
 	_ "github.com/tsuru/tsuru/auth/native"
 	_ "github.com/tsuru/tsuru/auth/oauth"
 	_ "github.com/tsuru/tsuru/auth/oidc"
+	"github.com/tsuru/tsuru/auth/roleexpiry"
+	"github.com/tsuru/tsuru/auth/tokenexpiry"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/webhook"
 	"github.com/tsuru/tsuru/hc"
 	"github.com/tsuru/tsuru/job"
+	"github.com/tsuru/tsuru/job/workflowcron"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/cluster"
@@ -113,6 +116,10 @@ func setupServices() error {
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize team token service")
 	}
+	servicemanager.PersonalToken, err = auth.PersonalTokenService()
+	if err != nil {
+		return errors.Wrapf(err, "could not initialize personal token service")
+	}
 	servicemanager.AppCache, err = app.CacheService()
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize app cache service")
@@ -185,6 +192,10 @@ func setupServices() error {
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize job service")
 	}
+	servicemanager.JobWorkflow, err = job.WorkflowService()
+	if err != nil {
+		return errors.Wrapf(err, "could not initialize job workflow service")
+	}
 	servicemanager.Tag, err = tag.TagService()
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize tag service")
@@ -272,6 +283,7 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/apps/{app}/start", AuthorizationRequiredHandler(start))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/stop", AuthorizationRequiredHandler(stop))
 	m.Add("1.10", http.MethodDelete, "/apps/{app}/versions/{version}", AuthorizationRequiredHandler(appVersionDelete))
+	m.Add("1.32", http.MethodGet, "/apps/{app}/versions/{version}/log", AuthorizationRequiredHandler(appVersionLog))
 	m.Add("1.0", http.MethodGet, "/apps/{app}/quota", AuthorizationRequiredHandler(getAppQuota))
 	m.Add("1.0", http.MethodPut, "/apps/{app}/quota", AuthorizationRequiredHandler(changeAppQuota))
 	m.Add("1.0", http.MethodGet, "/apps/{app}/env", AuthorizationRequiredHandler(getAppEnv))
@@ -291,6 +303,9 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/apps/{app}/log", AuthorizationRequiredHandler(addLog))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/deploy/rollback", AuthorizationRequiredHandler(deployRollback))
 	m.Add("1.4", http.MethodPut, "/apps/{app}/deploy/rollback/update", AuthorizationRequiredHandler(deployRollbackUpdate))
+	m.Add("1.32", http.MethodPut, "/apps/{app}/deploy/auto-rollback", AuthorizationRequiredHandler(deployAutoRollbackUpdate))
+	m.Add("1.32", http.MethodGet, "/apps/{app}/manifest", AuthorizationRequiredHandler(appManifest))
+	m.Add("1.32", http.MethodPut, "/apps/{app}/manifest", AuthorizationRequiredHandler(appManifestApply))
 	m.Add("1.3", http.MethodPost, "/apps/{app}/deploy/rebuild", AuthorizationRequiredHandler(deployRebuild))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/routes", AuthorizationRequiredHandler(appRebuildRoutes))
 
@@ -317,12 +332,18 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.1", http.MethodGet, "/events/kinds", AuthorizationRequiredHandler(kindList))
 	m.Add("1.1", http.MethodGet, "/events/{uuid}", AuthorizationRequiredHandler(eventInfo))
 	m.Add("1.1", http.MethodPost, "/events/{uuid}/cancel", AuthorizationRequiredHandler(eventCancel))
+	m.Add("1.32", http.MethodPost, "/events/{uuid}/approve", AuthorizationRequiredHandler(eventApprove))
+	m.Add("1.32", http.MethodPost, "/events/{uuid}/reject", AuthorizationRequiredHandler(eventReject))
 
 	m.Add("1.6", http.MethodGet, "/events/webhooks", AuthorizationRequiredHandler(webhookList))
 	m.Add("1.6", http.MethodPost, "/events/webhooks", AuthorizationRequiredHandler(webhookCreate))
 	m.Add("1.6", http.MethodGet, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookInfo))
 	m.Add("1.6", http.MethodPut, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookUpdate))
 	m.Add("1.6", http.MethodDelete, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookDelete))
+	m.Add("1.32", http.MethodGet, "/events/webhooks/{name}/deliveries", AuthorizationRequiredHandler(webhookDeliveryList))
+	m.Add("1.32", http.MethodPost, "/events/webhooks/{name}/deliveries/redrive", AuthorizationRequiredHandler(webhookDeliveryRedrive))
+	m.Add("1.32", http.MethodPost, "/events/webhooks/{name}/deliveries/{id}/redrive", AuthorizationRequiredHandler(webhookDeliveryRedrive))
+	m.Add("1.32", http.MethodPost, "/events/webhooks/{name}/test", AuthorizationRequiredHandler(webhookTest))
 
 	m.Add("1.0", http.MethodGet, "/platforms", AuthorizationRequiredHandler(platformList))
 	m.Add("1.0", http.MethodPost, "/platforms", AuthorizationRequiredHandler(platformAdd))
@@ -349,6 +370,10 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodGet, "/auth/scheme", Handler(authScheme))
 	m.Add("1.18", http.MethodGet, "/auth/schemes", Handler(authSchemes))
 	m.Add("1.0", http.MethodPost, "/auth/login", Handler(login))
+	m.Add("1.32", http.MethodPost, "/auth/workload-identity/token", Handler(workloadIdentityExchange))
+	m.Add("1.32", http.MethodGet, "/auth/workload-identity/issuers", AuthorizationRequiredHandler(trustedIssuerList))
+	m.Add("1.32", http.MethodPut, "/auth/workload-identity/issuers/{name}", AuthorizationRequiredHandler(trustedIssuerSet))
+	m.Add("1.32", http.MethodDelete, "/auth/workload-identity/issuers/{name}", AuthorizationRequiredHandler(trustedIssuerRemove))
 
 	m.Add("1.0", http.MethodPost, "/users/{email}/password", Handler(resetPassword))
 	m.Add("1.0", http.MethodPost, "/users/{email}/tokens", Handler(login))
@@ -359,6 +384,9 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodDelete, "/users", AuthorizationRequiredHandler(removeUser))
 	m.Add("1.0", http.MethodGet, "/users/api-key", AuthorizationRequiredHandler(showAPIToken))
 	m.Add("1.0", http.MethodPost, "/users/api-key", AuthorizationRequiredHandler(regenerateAPIToken))
+	m.Add("1.32", http.MethodGet, "/users/personal-tokens", AuthorizationRequiredHandler(personalTokenList))
+	m.Add("1.32", http.MethodPost, "/users/personal-tokens", AuthorizationRequiredHandler(personalTokenCreate))
+	m.Add("1.32", http.MethodDelete, "/users/personal-tokens/{token_id}", AuthorizationRequiredHandler(personalTokenDelete))
 
 	m.Add("1.0", http.MethodGet, "/logs", websocket.Handler(addLogs))
 
@@ -388,6 +416,10 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/pools/{name}/team", AuthorizationRequiredHandler(addTeamToPoolHandler))
 	m.Add("1.0", http.MethodDelete, "/pools/{name}/team", AuthorizationRequiredHandler(removeTeamToPoolHandler))
 	m.Add("1.8", http.MethodGet, "/pools/{name}", AuthorizationRequiredHandler(getPoolHandler))
+	m.Add("1.32", http.MethodGet, "/pools/{name}/freezes", AuthorizationRequiredHandler(poolFreezeList))
+	m.Add("1.32", http.MethodPost, "/pools/{name}/freezes", AuthorizationRequiredHandler(poolFreezeAdd))
+	m.Add("1.32", http.MethodGet, "/pools/{name}/freezes/ical", AuthorizationRequiredHandler(poolFreezeICal))
+	m.Add("1.32", http.MethodDelete, "/pools/{name}/freezes/{freeze}", AuthorizationRequiredHandler(poolFreezeRemove))
 
 	m.Add("1.3", http.MethodGet, "/constraints", AuthorizationRequiredHandler(poolConstraintList))
 	m.Add("1.3", http.MethodPut, "/constraints", AuthorizationRequiredHandler(poolConstraintSet))
@@ -405,6 +437,20 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/role/default", AuthorizationRequiredHandler(addDefaultRole))
 	m.Add("1.0", http.MethodDelete, "/role/default", AuthorizationRequiredHandler(removeDefaultRole))
 	m.Add("1.0", http.MethodGet, "/permissions", AuthorizationRequiredHandler(listPermissions))
+	m.Add("1.32", http.MethodGet, "/permissions/check", AuthorizationRequiredHandler(checkPermission))
+	m.Add("1.32", http.MethodGet, "/permissions/subjects", AuthorizationRequiredHandler(listPermissionHolders))
+	m.Add("1.32", http.MethodGet, "/scim/v2/Users", scimHandler(scimUserList))
+	m.Add("1.32", http.MethodPost, "/scim/v2/Users", scimHandler(scimUserCreate))
+	m.Add("1.32", http.MethodGet, "/scim/v2/Users/{id}", scimHandler(scimUserGet))
+	m.Add("1.32", http.MethodPut, "/scim/v2/Users/{id}", scimHandler(scimUserReplace))
+	m.Add("1.32", http.MethodPatch, "/scim/v2/Users/{id}", scimHandler(scimUserPatch))
+	m.Add("1.32", http.MethodDelete, "/scim/v2/Users/{id}", scimHandler(scimUserDelete))
+	m.Add("1.32", http.MethodGet, "/scim/v2/Groups", scimHandler(scimGroupList))
+	m.Add("1.32", http.MethodPost, "/scim/v2/Groups", scimHandler(scimGroupCreate))
+	m.Add("1.32", http.MethodGet, "/scim/v2/Groups/{id}", scimHandler(scimGroupGet))
+	m.Add("1.32", http.MethodPut, "/scim/v2/Groups/{id}", scimHandler(scimGroupReplace))
+	m.Add("1.32", http.MethodPatch, "/scim/v2/Groups/{id}", scimHandler(scimGroupPatch))
+	m.Add("1.32", http.MethodDelete, "/scim/v2/Groups/{id}", scimHandler(scimGroupDelete))
 	m.Add("1.6", http.MethodPost, "/roles/{name}/token", AuthorizationRequiredHandler(assignRoleToToken))
 	m.Add("1.6", http.MethodDelete, "/roles/{name}/token/{token_id}", AuthorizationRequiredHandler(dissociateRoleFromToken))
 	m.Add("1.9", http.MethodPost, "/roles/{name}/group", AuthorizationRequiredHandler(assignRoleToGroup))
@@ -466,9 +512,20 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.13", http.MethodPost, "/jobs/{name}/env", AuthorizationRequiredHandler(setJobEnv))
 	m.Add("1.13", http.MethodDelete, "/jobs/{name}/env", AuthorizationRequiredHandler(unsetJobEnv))
 	m.Add("1.13", http.MethodGet, "/jobs/{name}/log", AuthorizationRequiredHandler(jobLog))
+	m.Add("1.32", http.MethodGet, "/jobs/{name}/runs", AuthorizationRequiredHandler(jobRunList))
+	m.Add("1.32", http.MethodGet, "/jobs/{name}/runs/{id}", AuthorizationRequiredHandler(jobRunInfo))
 	m.Add("1.13", http.MethodDelete, "/jobs/{name}/units/{unit}", AuthorizationRequiredHandler(killJob))
 	m.Add("1.23", http.MethodPost, "/jobs/{name}/deploy", AuthorizationRequiredHandler(jobDeploy))
 
+	m.Add("1.32", http.MethodGet, "/job-workflows", AuthorizationRequiredHandler(workflowList))
+	m.Add("1.32", http.MethodPost, "/job-workflows", AuthorizationRequiredHandler(workflowCreate))
+	m.Add("1.32", http.MethodGet, "/job-workflows/{name}", AuthorizationRequiredHandler(workflowInfo))
+	m.Add("1.32", http.MethodPut, "/job-workflows/{name}", AuthorizationRequiredHandler(workflowUpdate))
+	m.Add("1.32", http.MethodDelete, "/job-workflows/{name}", AuthorizationRequiredHandler(workflowDelete))
+	m.Add("1.32", http.MethodPost, "/job-workflows/{name}/trigger", AuthorizationRequiredHandler(workflowTrigger))
+	m.Add("1.32", http.MethodGet, "/job-workflows/{name}/runs", AuthorizationRequiredHandler(workflowRunList))
+	m.Add("1.32", http.MethodGet, "/job-workflows/{name}/runs/{id}", AuthorizationRequiredHandler(workflowRunInfo))
+
 	n := negroni.New()
 	n.Use(negroni.NewRecovery())
 	if c := corsMiddleware(); c != nil {
@@ -584,6 +641,22 @@ func startServer(handler http.Handler) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to initialize old image gc")
 	}
+	err = roleexpiry.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize role expiry worker")
+	}
+	err = tokenexpiry.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize personal token expiry worker")
+	}
+	err = workflowcron.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize job workflow cron worker")
+	}
+	err = app.ResumeAutoRollbackWatches(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to resume auto rollback watches")
+	}
 	log.Debugf("Checking components status:")
 	results := hc.Check(ctx, "all")
 	for _, result := range results {
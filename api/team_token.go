@@ -8,9 +8,13 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/tsuru/api/context"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	authTypes "github.com/tsuru/tsuru/types/auth"
@@ -109,7 +113,7 @@ func tokenCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 		Target:     teamTarget(args.Team),
 		Kind:       permission.PermTeamTokenCreate,
 		Owner:      t,
-		RemoteAddr: r.RemoteAddr,
+		RemoteAddr: clientAddr(r),
 		CustomData: event.FormToCustomData(InputFields(r)),
 		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, args.Team)),
 	})
@@ -171,7 +175,7 @@ func tokenUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 		Target:     teamTarget(teamToken.Team),
 		Kind:       permission.PermTeamTokenUpdate,
 		Owner:      t,
-		RemoteAddr: r.RemoteAddr,
+		RemoteAddr: clientAddr(r),
 		CustomData: event.FormToCustomData(InputFields(r)),
 		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamToken.Team)),
 	})
@@ -225,7 +229,7 @@ func tokenDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 		Target:     teamTarget(teamName),
 		Kind:       permission.PermTeamTokenDelete,
 		Owner:      t,
-		RemoteAddr: r.RemoteAddr,
+		RemoteAddr: clientAddr(r),
 		CustomData: event.FormToCustomData(InputFields(r)),
 		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
 	})
@@ -242,3 +246,49 @@ func tokenDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	}
 	return err
 }
+
+var teamTokenRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: promNamespace,
+	Subsystem: promSubsystem,
+	Name:      "team_token_rejected_total",
+	Help:      "The number of requests rejected due to team token constraints",
+}, []string{"reason"})
+
+// recordTeamTokenRejection records every request rejected by the constraints
+// of a team token as an event on the token team, holding the token ID. Only
+// the first rejection of the token for the same reason in a window is logged.
+func recordTeamTokenRejection(r *http.Request, rejected *authTypes.ErrTeamTokenRejected) {
+	teamTokenRejectedTotal.WithLabelValues(rejected.Reason).Inc()
+	if !rejected.Repeated {
+		log.Errorf("team token %q of team %q rejected by %s constraint on %s %s from %s", rejected.TokenID, rejected.Team, rejected.Reason, r.Method, r.URL.Path, clientAddr(r))
+	}
+	ctx := r.Context()
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       teamTarget(rejected.Team),
+		InternalKind: "team token rejected",
+		CustomData: map[string]string{
+			"token_id":    rejected.TokenID,
+			"reason":      rejected.Reason,
+			"remote_addr": clientAddr(r),
+			"method":      r.Method,
+			"path":        r.URL.Path,
+		},
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, rejected.Team)),
+	})
+	if err != nil {
+		log.Errorf("unable to record rejection of team token %q: %v", rejected.TokenID, err)
+		return
+	}
+	evt.Done(ctx, rejected)
+}
+
+// recordScopedTokenForbidden records forbidden requests made with team tokens
+// whose permissions are narrowed by their constraints.
+func recordScopedTokenForbidden(r *http.Request) {
+	t, ok := context.GetAuthToken(r).(authTypes.ConstrainedToken)
+	if !ok || t.TokenConstraints() == nil || len(t.TokenConstraints().AllowedPermissions) == 0 {
+		return
+	}
+	recordTeamTokenRejection(r, auth.NewTeamTokenRejection(t.GetTokenName(), t.TeamName(), authTypes.TeamTokenRejectedPermission))
+}
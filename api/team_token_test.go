@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
@@ -262,3 +263,122 @@ func (s *S) TestTeamTokenInfo(c *check.C) {
 	result.CreatedAt = time.Unix(result.CreatedAt.Unix(), 0)
 	c.Assert(newToken, check.DeepEquals, result)
 }
+
+func (s *S) TestTeamTokenCreateWithConstraints(c *check.C) {
+	body := strings.NewReader(`token_id=t1&team=` + s.team.Name + `&allowed_cidrs=10.0.0.0/8&allowed_cidrs=192.168.0.0/16&allowed_permissions=app.deploy&rate_limit=30`)
+	request, err := http.NewRequest("POST", "/1.6/tokens", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+	var result authTypes.TeamToken
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Constraints, check.DeepEquals, &authTypes.TeamTokenConstraints{
+		AllowedCIDRs:       []string{"10.0.0.0/8", "192.168.0.0/16"},
+		AllowedPermissions: []string{"app.deploy"},
+		RateLimit:          30,
+	})
+}
+
+func (s *S) TestTeamTokenCreateWithInvalidConstraints(c *check.C) {
+	body := strings.NewReader(`token_id=t1&team=` + s.team.Name + `&allowed_cidrs=10.0.0.0/99`)
+	request, err := http.NewRequest("POST", "/1.6/tokens", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestTeamTokenRejectedBySourceAddress(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:         s.team.Name,
+		TokenID:      "ci",
+		AllowedCIDRs: []string{"10.0.0.0/8"},
+	}, s.token)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps", nil)
+	c.Assert(err, check.IsNil)
+	request.RemoteAddr = "192.168.0.10:43210"
+	request.Header.Set("Authorization", "bearer "+token.Token)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, "team token \"ci\" is not allowed from this address\n")
+	c.Assert(eventtest.EventDesc{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: s.team.Name},
+		Kind:         "team token rejected",
+		ErrorMatches: `team token "ci" is not allowed from this address`,
+		StartCustomData: map[string]interface{}{
+			"token_id":    "ci",
+			"reason":      authTypes.TeamTokenRejectedSource,
+			"remote_addr": "192.168.0.10:43210",
+		},
+	}, eventtest.HasEvent)
+	request.RemoteAddr = "10.1.1.1:43210"
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Not(check.Equals), http.StatusForbidden)
+}
+
+func (s *S) TestTeamTokenRejectedByRateLimit(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:      s.team.Name,
+		TokenID:   "ci-limited",
+		RateLimit: 1,
+	}, s.token)
+	c.Assert(err, check.IsNil)
+	var codes []int
+	for i := 0; i < 3; i++ {
+		request, err := http.NewRequest("GET", "/apps", nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+token.Token)
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		codes = append(codes, recorder.Code)
+	}
+	c.Assert(codes[1:], check.DeepEquals, []int{http.StatusTooManyRequests, http.StatusTooManyRequests})
+	evts, err := event.List(context.TODO(), &event.Filter{KindNames: []string{"team token rejected"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 2)
+	for _, evt := range evts {
+		var data map[string]string
+		err = evt.StartData(&data)
+		c.Assert(err, check.IsNil)
+		c.Assert(data["token_id"], check.Equals, "ci-limited")
+	}
+}
+
+func (s *S) TestTeamTokenForbiddenByAllowedPermissions(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "ci-app-admin", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app")
+	c.Assert(err, check.IsNil)
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:               s.team.Name,
+		TokenID:            "ci-deploy",
+		AllowedPermissions: []string{"app.deploy"},
+	}, s.token)
+	c.Assert(err, check.IsNil)
+	err = servicemanager.TeamToken.AddRole(context.TODO(), token.TokenID, "ci-app-admin", s.team.Name)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/pools", strings.NewReader("name=mypool"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.Token)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: s.team.Name},
+		Kind:   "team token rejected",
+		StartCustomData: map[string]interface{}{
+			"token_id": "ci-deploy",
+			"reason":   authTypes.TeamTokenRejectedPermission,
+		},
+	}, eventtest.HasEvent)
+}
@@ -6,16 +6,22 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const defaultWebhookDeliveryLimit = 100
+
 // title: webhook list
 // path: /events/webhooks
 // method: GET
@@ -105,7 +111,7 @@ func webhookCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 		Kind:       permission.PermWebhookCreate,
 		Owner:      t,
 		RemoteAddr: r.RemoteAddr,
-		CustomData: event.FormToCustomData(InputFields(r)),
+		CustomData: event.FormToCustomData(InputFields(r, "secret")),
 		Allowed:    event.Allowed(permission.PermWebhookReadEvents, permCtx),
 	})
 	if err != nil {
@@ -147,7 +153,7 @@ func webhookUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 		Kind:       permission.PermWebhookUpdate,
 		Owner:      t,
 		RemoteAddr: r.RemoteAddr,
-		CustomData: event.FormToCustomData(InputFields(r)),
+		CustomData: event.FormToCustomData(InputFields(r, "secret")),
 		Allowed:    event.Allowed(permission.PermWebhookReadEvents, permissionCtx),
 	})
 	if err != nil {
@@ -201,3 +207,153 @@ func webhookDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 	}()
 	return servicemanager.Webhook.Delete(ctx, webhookName)
 }
+
+// title: webhook delivery list
+// path: /events/webhooks/{name}/deliveries
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List webhook deliveries
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Webhook not found
+func webhookDeliveryList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	webhookName := r.URL.Query().Get(":name")
+	webhook, err := servicemanager.Webhook.Find(ctx, webhookName)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	permissionCtx := permission.Context(permTypes.CtxTeam, webhook.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermWebhookRead, permissionCtx) {
+		return permission.ErrUnauthorized
+	}
+	filter := eventTypes.WebhookDeliveryFilter{
+		WebhookName: webhookName,
+		Status:      eventTypes.WebhookDeliveryStatus(r.URL.Query().Get("status")),
+		Limit:       defaultWebhookDeliveryLimit,
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "limit must be an integer"}
+		}
+	}
+	deliveries, err := servicemanager.Webhook.ListDeliveries(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deliveries)
+}
+
+// title: webhook delivery redrive
+// path: /events/webhooks/{name}/deliveries/{id}/redrive
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Deliveries scheduled for retry
+//	401: Unauthorized
+//	404: Webhook or delivery not found
+func webhookDeliveryRedrive(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	webhookName := r.URL.Query().Get(":name")
+	deliveryID := r.URL.Query().Get(":id")
+	webhook, err := servicemanager.Webhook.Find(ctx, webhookName)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	permissionCtx := permission.Context(permTypes.CtxTeam, webhook.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermWebhookUpdate, permissionCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: webhook.Name},
+		Kind:       permission.PermWebhookUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermWebhookReadEvents, permissionCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		evt.Done(ctx, err)
+	}()
+	count, err := servicemanager.Webhook.RedriveDeliveries(ctx, eventTypes.WebhookDeliveryFilter{
+		WebhookName: webhookName,
+		ID:          deliveryID,
+	})
+	if err != nil {
+		return err
+	}
+	if deliveryID != "" && count == 0 {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: eventTypes.ErrWebhookDeliveryNotFound.Error()}
+	}
+	evt.Logf("%d deliveries scheduled for retry", count)
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]int{"redriven": count})
+}
+
+// title: webhook test
+// path: /events/webhooks/{name}/test
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Webhook called
+//	400: Invalid event
+//	401: Unauthorized
+//	404: Webhook or event not found
+func webhookTest(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	webhookName := r.URL.Query().Get(":name")
+	webhook, err := servicemanager.Webhook.Find(ctx, webhookName)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	permissionCtx := permission.Context(permTypes.CtxTeam, webhook.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermWebhookUpdate, permissionCtx) {
+		return permission.ErrUnauthorized
+	}
+	evtID := InputValue(r, "event")
+	if evtID != "" {
+		if _, err = primitive.ObjectIDFromHex(evtID); err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("event is not ObjectId: %s", evtID)}
+		}
+		evt, err := event.GetByHexID(ctx, evtID)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		scheme, err := permission.SafeGet(evt.Allowed.Scheme)
+		if err != nil {
+			return err
+		}
+		if !permission.Check(ctx, t, scheme, evt.Allowed.Contexts...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	result, err := servicemanager.Webhook.Test(ctx, webhookName, evtID)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
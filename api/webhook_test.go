@@ -12,8 +12,11 @@ import (
 	"strings"
 
 	"github.com/cezarsa/form"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	check "gopkg.in/check.v1"
@@ -395,3 +398,191 @@ func (s *S) TestWebhookInfoNotFound(c *check.C) {
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
 }
+
+func (s *S) TestWebhookDeliveryList(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+	})
+	c.Assert(err, check.IsNil)
+	dbDriver, err := storage.GetDefaultDbDriver()
+	c.Assert(err, check.IsNil)
+	for _, d := range []eventTypes.WebhookDelivery{
+		{ID: "d1", WebhookName: "wh1", Status: eventTypes.WebhookDeliverySucceeded},
+		{ID: "d2", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, LastError: "connection refused"},
+		{ID: "d3", WebhookName: "wh2", Status: eventTypes.WebhookDeliveryDead},
+	} {
+		err = dbDriver.WebhookDeliveryStorage.Insert(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest("GET", "/1.32/events/webhooks/wh1/deliveries?status=dead", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result []eventTypes.WebhookDelivery
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 1)
+	c.Assert(result[0].ID, check.Equals, "d2")
+	c.Assert(result[0].LastError, check.Equals, "connection refused")
+}
+
+func (s *S) TestWebhookDeliveryListUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermWebhookRead,
+		Context: permission.Context(permTypes.CtxTeam, "other-team"),
+	})
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.32/events/webhooks/wh1/deliveries", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestWebhookDeliveryRedrive(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+	})
+	c.Assert(err, check.IsNil)
+	dbDriver, err := storage.GetDefaultDbDriver()
+	c.Assert(err, check.IsNil)
+	for _, d := range []eventTypes.WebhookDelivery{
+		{ID: "d1", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, Attempts: 5},
+		{ID: "d2", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, Attempts: 5},
+	} {
+		err = dbDriver.WebhookDeliveryStorage.Insert(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest("POST", "/1.32/events/webhooks/wh1/deliveries/d1/redrive", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "{\"redriven\":1}\n")
+	d, err := dbDriver.WebhookDeliveryStorage.FindByID(context.TODO(), "d1")
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Status, check.Equals, eventTypes.WebhookDeliveryPending)
+	d, err = dbDriver.WebhookDeliveryStorage.FindByID(context.TODO(), "d2")
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Status, check.Equals, eventTypes.WebhookDeliveryDead)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: "wh1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "webhook.update",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestWebhookDeliveryRedriveNotFound(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.32/events/webhooks/wh1/deliveries/d1/redrive", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestWebhookCreateWithSecret(c *check.C) {
+	bodyData, err := form.EncodeToString(eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+		Secret:    "super-secret-value-123",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.6/events/webhooks", strings.NewReader(bodyData))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	request, err = http.NewRequest("GET", "/1.6/events/webhooks/wh1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(strings.Contains(recorder.Body.String(), "super-secret-value-123"), check.Equals, false)
+	var wh eventTypes.Webhook
+	err = json.Unmarshal(recorder.Body.Bytes(), &wh)
+	c.Assert(err, check.IsNil)
+	c.Assert(wh.HasSecret, check.Equals, true)
+	c.Assert(wh.Secret, check.Equals, "")
+	evts, err := event.List(context.TODO(), &event.Filter{KindNames: []string{"webhook.create"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	var customData []map[string]interface{}
+	err = evts[0].StartData(&customData)
+	c.Assert(err, check.IsNil)
+	c.Assert(customData, check.Not(check.HasLen), 0)
+	for _, field := range customData {
+		c.Assert(field["name"], check.Not(check.Equals), "secret")
+	}
+}
+
+func (s *S) TestWebhookTest(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       srv.URL,
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.32/events/webhooks/wh1/test", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result eventTypes.WebhookDeliveryAttempt
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.ResponseStatus, check.Equals, http.StatusOK)
+	c.Assert(result.Error, check.Equals, "")
+}
+
+func (s *S) TestWebhookTestInvalidEvent(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.32/events/webhooks/wh1/test?event=invalid", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestWebhookTestNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.32/events/webhooks/wh1/test", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/oidc"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: list trusted issuers
+// path: /auth/workload-identity/issuers
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List trusted issuers
+//	204: No content
+//	401: Unauthorized
+func trustedIssuerList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermWorkloadIdentityRead) {
+		return permission.ErrUnauthorized
+	}
+	issuers, err := oidc.ListTrustedIssuers(ctx)
+	if err != nil {
+		return err
+	}
+	if len(issuers) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(issuers)
+}
+
+// title: set trusted issuer
+// path: /auth/workload-identity/issuers/{name}
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: Trusted issuer saved
+//	400: Invalid data
+//	401: Unauthorized
+//	403: Forbidden
+func trustedIssuerSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermWorkloadIdentityUpdate) {
+		return permission.ErrUnauthorized
+	}
+	var issuer oidc.TrustedIssuer
+	err = ParseJSON(r, &issuer)
+	if err != nil {
+		return err
+	}
+	issuer.Name = r.URL.Query().Get(":name")
+	for _, rule := range issuer.Rules {
+		for _, roleInstance := range rule.Roles {
+			role, err := getRoleReturnNotFound(ctx, roleInstance.Name)
+			if err != nil {
+				return err
+			}
+			err = canUseRole(ctx, t, role, roleInstance.ContextValue)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeWorkloadIdentity, Value: issuer.Name},
+		Kind:       permission.PermWorkloadIdentityUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: issuer,
+		Allowed:    event.Allowed(permission.PermWorkloadIdentityReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return oidc.SaveTrustedIssuer(ctx, &issuer)
+}
+
+// title: remove trusted issuer
+// path: /auth/workload-identity/issuers/{name}
+// method: DELETE
+// responses:
+//
+//	200: Trusted issuer removed
+//	401: Unauthorized
+//	404: Trusted issuer not found
+func trustedIssuerRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermWorkloadIdentityDelete) {
+		return permission.ErrUnauthorized
+	}
+	name := r.URL.Query().Get(":name")
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeWorkloadIdentity, Value: name},
+		Kind:       permission.PermWorkloadIdentityDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		Allowed:    event.Allowed(permission.PermWorkloadIdentityReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = oidc.RemoveTrustedIssuer(ctx, name)
+	if err == oidc.ErrTrustedIssuerNotFound {
+		return &errors.HTTP{
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		}
+	}
+	return err
+}
+
+type tokenExchangeArgs struct {
+	Token string `json:"token" form:"token"`
+}
+
+// title: exchange workload identity token
+// path: /auth/workload-identity/token
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	201: Token issued
+//	400: Invalid data
+//	401: Token refused
+func workloadIdentityExchange(w http.ResponseWriter, r *http.Request) (err error) {
+	ctx := r.Context()
+	var args tokenExchangeArgs
+	err = ParseInput(r, &args)
+	if err != nil {
+		return err
+	}
+	if args.Token == "" {
+		return &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "token is required",
+		}
+	}
+	result, err := oidc.Exchange(ctx, args.Token)
+	var denied *oidc.ErrExchangeDenied
+	if stderrors.As(err, &denied) {
+		return &errors.HTTP{
+			Code:    http.StatusUnauthorized,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return err
+	}
+	recordTokenExchange(r, result)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(result.Token)
+}
+
+// recordTokenExchange records a team token issued by a token exchange as an
+// event on the token team.
+func recordTokenExchange(r *http.Request, result *oidc.ExchangeResult) {
+	ctx := r.Context()
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       teamTarget(result.Token.Team),
+		InternalKind: "workload identity exchange",
+		CustomData: map[string]string{
+			"token_id":    result.Token.TokenID,
+			"issuer":      result.Issuer,
+			"rule":        result.Rule,
+			"subject":     result.Subject,
+			"remote_addr": r.RemoteAddr,
+		},
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, result.Token.Team)),
+	})
+	if err != nil {
+		log.Errorf("unable to record token exchange for %q: %v", result.Token.TokenID, err)
+		return
+	}
+	evt.Done(ctx, nil)
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/tsuru/tsuru/auth/oidc"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+// fakeJWKSServer serves the public part of a freshly generated RSA key,
+// standing in for the JWKS endpoint of a CI provider.
+func fakeJWKSServer(c *check.C, kid string) (*httptest.Server, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	c.Assert(err, check.IsNil)
+	jwkKey, err := jwk.FromRaw(key.PublicKey)
+	c.Assert(err, check.IsNil)
+	jwkKey.Set(jwk.KeyIDKey, kid)
+	set := jwk.NewSet()
+	set.AddKey(jwkKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(set)
+	}))
+	return server, key
+}
+
+func (s *S) trustedIssuer(jwksURL string) oidc.TrustedIssuer {
+	return oidc.TrustedIssuer{
+		Issuer:   "https://ci.example.com",
+		JWKSURL:  jwksURL,
+		Audience: "tsuru",
+		Rules: []oidc.ExchangeRule{{
+			Name:   "main",
+			Claims: map[string]string{"repository": "tsuru/tsuru", "ref": "refs/heads/main"},
+			Team:   s.team.Name,
+		}},
+	}
+}
+
+func (s *S) TestTrustedIssuerSet(c *check.C) {
+	issuer := s.trustedIssuer("https://ci.example.com/jwks")
+	body, err := json.Marshal(issuer)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPut, "/auth/workload-identity/issuers/ci", bytes.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	dbIssuer, err := oidc.GetTrustedIssuer(context.TODO(), "ci")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbIssuer.Issuer, check.Equals, "https://ci.example.com")
+	c.Assert(dbIssuer.Rules, check.HasLen, 1)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeWorkloadIdentity, Value: "ci"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "workload-identity.update",
+	}, eventtest.HasEvent)
+	req, err = http.NewRequest(http.MethodGet, "/auth/workload-identity/issuers", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var issuers []oidc.TrustedIssuer
+	err = json.NewDecoder(rec.Body).Decode(&issuers)
+	c.Assert(err, check.IsNil)
+	c.Assert(issuers, check.HasLen, 1)
+	c.Assert(issuers[0].Name, check.Equals, "ci")
+}
+
+func (s *S) TestTrustedIssuerSetInvalid(c *check.C) {
+	issuer := s.trustedIssuer("https://ci.example.com/jwks")
+	issuer.Audience = ""
+	body, err := json.Marshal(issuer)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPut, "/auth/workload-identity/issuers/ci", bytes.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestTrustedIssuerSetRoleNotGrantable(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "ci-admin", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app")
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermWorkloadIdentityUpdate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	issuer := s.trustedIssuer("https://ci.example.com/jwks")
+	issuer.Rules[0].Roles = []authTypes.RoleInstance{{Name: "ci-admin", ContextValue: s.team.Name}}
+	body, err := json.Marshal(issuer)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPut, "/auth/workload-identity/issuers/ci", bytes.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+	_, err = oidc.GetTrustedIssuer(context.TODO(), "ci")
+	c.Assert(err, check.Equals, oidc.ErrTrustedIssuerNotFound)
+}
+
+func (s *S) TestTrustedIssuerSetUnauthorized(c *check.C) {
+	token := userWithPermission(c, permTypes.Permission{
+		Scheme:  permission.PermWorkloadIdentityRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	body, err := json.Marshal(s.trustedIssuer("https://ci.example.com/jwks"))
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPut, "/auth/workload-identity/issuers/ci", bytes.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestTrustedIssuerRemove(c *check.C) {
+	issuer := s.trustedIssuer("https://ci.example.com/jwks")
+	issuer.Name = "ci"
+	err := oidc.SaveTrustedIssuer(context.TODO(), &issuer)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodDelete, "/auth/workload-identity/issuers/ci", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	_, err = oidc.GetTrustedIssuer(context.TODO(), "ci")
+	c.Assert(err, check.Equals, oidc.ErrTrustedIssuerNotFound)
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestWorkloadIdentityExchange(c *check.C) {
+	server, key := fakeJWKSServer(c, "ci-key")
+	defer server.Close()
+	issuer := s.trustedIssuer(server.URL)
+	issuer.Name = "ci"
+	err := oidc.SaveTrustedIssuer(context.TODO(), &issuer)
+	c.Assert(err, check.IsNil)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":        "https://ci.example.com",
+		"aud":        "tsuru",
+		"sub":        "repo:tsuru/tsuru:ref:refs/heads/main",
+		"exp":        time.Now().Add(5 * time.Minute).Unix(),
+		"repository": "tsuru/tsuru",
+		"ref":        "refs/heads/main",
+	})
+	token.Header["kid"] = "ci-key"
+	raw, err := token.SignedString(key)
+	c.Assert(err, check.IsNil)
+	body := url.Values{"token": []string{raw}}
+	req, err := http.NewRequest(http.MethodPost, "/auth/workload-identity/token", strings.NewReader(body.Encode()))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	var teamToken authTypes.TeamToken
+	err = json.NewDecoder(rec.Body).Decode(&teamToken)
+	c.Assert(err, check.IsNil)
+	c.Assert(teamToken.Team, check.Equals, s.team.Name)
+	c.Assert(teamToken.ExpiresAt.IsZero(), check.Equals, false)
+	dbToken, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), teamToken.TokenID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbToken.Token, check.Equals, teamToken.Token)
+	c.Assert(eventtest.EventDesc{
+		Target: teamTarget(s.team.Name),
+		Kind:   "workload identity exchange",
+		StartCustomData: map[string]interface{}{
+			"token_id": teamToken.TokenID,
+			"issuer":   "ci",
+			"rule":     "main",
+			"subject":  "repo:tsuru/tsuru:ref:refs/heads/main",
+		},
+	}, eventtest.HasEvent)
+
+	token = jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":        "https://ci.example.com",
+		"aud":        "tsuru",
+		"exp":        time.Now().Add(5 * time.Minute).Unix(),
+		"repository": "tsuru/tsuru",
+		"ref":        "refs/heads/feature",
+	})
+	token.Header["kid"] = "ci-key"
+	raw, err = token.SignedString(key)
+	c.Assert(err, check.IsNil)
+	body = url.Values{"token": []string{raw}}
+	req, err = http.NewRequest(http.MethodPost, "/auth/workload-identity/token", strings.NewReader(body.Encode()))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusUnauthorized)
+	c.Assert(rec.Body.String(), check.Matches, "token exchange denied: token claims match no exchange rule\n")
+}
+
+func (s *S) TestWorkloadIdentityExchangeMissingToken(c *check.C) {
+	req, err := http.NewRequest(http.MethodPost, "/auth/workload-identity/token", strings.NewReader(""))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/action"
 	"github.com/tsuru/tsuru/auth"
-	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
@@ -30,15 +29,13 @@ import (
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	"github.com/tsuru/tsuru/types/quota"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // mockDeployFunc can be set in tests to mock the Deploy function.
 var mockDeployFunc func(ctx context.Context, opts DeployOptions) (string, error)
 
 var (
-	ErrAppAlreadyExists                      = errors.New("there is already an app with this name")
+	ErrAppAlreadyExists                      = appTypes.ErrAppAlreadyExists
 	ErrCNameDoesNotExist                     = errors.New("cname does not exist in app")
 	ErrCertIssuerNotAllowedByPoolConstraints = errors.New("cert issuer not allowed by constraints of this pool")
 )
@@ -143,7 +140,7 @@ var insertApp = action.Action{
 }
 
 func createApp(ctx context.Context, app *appTypes.App) error {
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
@@ -154,21 +151,15 @@ func createApp(ctx context.Context, app *appTypes.App) error {
 	if limit, err = config.GetInt("quota:units-per-app"); err == nil {
 		app.Quota.Limit = limit
 	}
-	_, err = collection.InsertOne(ctx, app)
-	if mongo.IsDuplicateKeyError(err) {
-		return ErrAppAlreadyExists
-	}
-
-	return nil
+	return as.Insert(ctx, app)
 }
 
 func removeApp(ctx context.Context, app *appTypes.App) error {
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-
-	collection.DeleteOne(ctx, mongoBSON.M{"name": app.Name})
+	as.Delete(ctx, app.Name)
 	return nil
 }
 
@@ -372,23 +363,20 @@ var saveApp = action.Action{
 		if !ok {
 			return nil, errors.New("expected app ptr as first arg")
 		}
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
-
-		_, err = collection.ReplaceOne(ctx.Context, mongoBSON.M{"name": app.Name}, app)
-		return nil, err
+		return nil, as.Update(ctx.Context, app)
 	},
 	Backward: func(ctx action.BWContext) {
 		oldApp := ctx.Params[1].(*appTypes.App)
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			log.Errorf("BACKWARD save app - failed to get database connection: %s", err)
 			return
 		}
-
-		_, err = collection.ReplaceOne(ctx.Context, mongoBSON.M{"name": oldApp.Name}, oldApp)
+		err = as.Update(ctx.Context, oldApp)
 		if err != nil {
 			log.Errorf("BACKWARD save app - failed to update app: %s", err)
 		}
@@ -556,7 +544,7 @@ var validateNewCNames = action.Action{
 		app := ctx.Params[0].(*appTypes.App)
 		cnameRegexp := regexp.MustCompile(`^(\*\.)?[a-zA-Z0-9][\w-.]+$`)
 		cnames := ctx.Params[1].([]string)
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
@@ -565,37 +553,29 @@ var validateNewCNames = action.Action{
 			if !cnameRegexp.MatchString(cname) {
 				return nil, errors.New("Invalid cname")
 			}
-			cs, err := collection.CountDocuments(ctx.Context, mongoBSON.M{"cname": cname})
-			if err != nil {
-				return nil, err
-			}
-			if cs == 0 {
-				continue
-			}
-			cs, err = collection.CountDocuments(ctx.Context, mongoBSON.M{"name": app.Name, "cname": cname})
+			cnameApps, err := as.FindByCName(ctx.Context, cname)
 			if err != nil {
 				return nil, err
 			}
-			if cs > 0 {
-				return nil, errors.New(fmt.Sprintf("cname %s already exists for this app", cname))
-			}
-
-			appCName := appTypes.App{}
-			err = collection.FindOne(ctx.Context, mongoBSON.M{"cname": cname, "name": mongoBSON.M{"$ne": app.Name}, "routers": mongoBSON.M{"$in": appRouters}}).Decode(&appCName)
-			if err != nil && err != mongo.ErrNoDocuments {
-				return nil, err
+			var otherApps []appTypes.App
+			for _, a := range cnameApps {
+				if a.Name == app.Name {
+					return nil, errors.New(fmt.Sprintf("cname %s already exists for this app", cname))
+				}
+				otherApps = append(otherApps, a)
 			}
-			if appCName.Name != "" {
-				return nil, errors.New(fmt.Sprintf("cname %s already exists for app %s using same router", cname, appCName.Name))
+			for _, a := range otherApps {
+				for _, r := range appRouters {
+					if hasRouter(a.Routers, r) {
+						return nil, errors.New(fmt.Sprintf("cname %s already exists for app %s using same router", cname, a.Name))
+					}
+				}
 			}
-			err = collection.FindOne(ctx.Context, mongoBSON.M{"cname": cname, "name": mongoBSON.M{"$ne": app.Name}, "teamowner": mongoBSON.M{"$ne": app.TeamOwner}}).Decode(&appCName)
-			if err != nil {
-				if err == mongo.ErrNoDocuments {
-					continue
+			for _, a := range otherApps {
+				if a.TeamOwner != app.TeamOwner {
+					return nil, errors.New(fmt.Sprintf("cname %s already exists for another app %s and belongs to a different team owner", cname, a.Name))
 				}
-				return nil, err
 			}
-			return nil, errors.New(fmt.Sprintf("cname %s already exists for another app %s and belongs to a different team owner", cname, appCName.Name))
 		}
 		return cnames, nil
 	},
@@ -606,17 +586,11 @@ var saveCNames = action.Action{
 	Forward: func(ctx action.FWContext) (action.Result, error) {
 		app := ctx.Params[0].(*appTypes.App)
 		cnames := ctx.Params[1].([]string)
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
-
-		_, err = collection.UpdateOne(
-			ctx.Context,
-			mongoBSON.M{"name": app.Name},
-			mongoBSON.M{"$addToSet": mongoBSON.M{"cname": mongoBSON.M{"$each": cnames}}},
-		)
-
+		err = as.AddCNames(ctx.Context, app.Name, cnames)
 		if err != nil {
 			return nil, err
 		}
@@ -625,22 +599,14 @@ var saveCNames = action.Action{
 	Backward: func(ctx action.BWContext) {
 		app := ctx.Params[0].(*appTypes.App)
 		cnames := ctx.Params[1].([]string)
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			log.Errorf("BACKWARD add cnames db - unable to connect: %s", err)
 			return
 		}
-
-		// TODO: may use $pullAll instead of loop, but requires mongo > 5.0
-		for _, c := range cnames {
-			_, err := collection.UpdateOne(
-				ctx.Context,
-				mongoBSON.M{"name": app.Name},
-				mongoBSON.M{"$pull": mongoBSON.M{"cname": c}},
-			)
-			if err != nil {
-				log.Errorf("BACKWARD add cnames db - unable to update: %s", err)
-			}
+		err = as.RemoveCNames(ctx.Context, app.Name, cnames)
+		if err != nil {
+			log.Errorf("BACKWARD add cnames db - unable to update: %s", err)
 		}
 	},
 }
@@ -658,16 +624,16 @@ var checkCNameExists = action.Action{
 	Name: "cname-exists",
 	Forward: func(ctx action.FWContext) (action.Result, error) {
 		cnames := ctx.Params[1].([]string)
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
 		for _, cname := range cnames {
-			cs, err := collection.CountDocuments(ctx.Context, mongoBSON.M{"cname": cname})
+			cnameApps, err := as.FindByCName(ctx.Context, cname)
 			if err != nil {
 				return nil, err
 			}
-			if cs == 0 {
+			if len(cnameApps) == 0 {
 				return nil, errors.New(fmt.Sprintf("cname %s not exists in app", cname))
 			}
 		}
@@ -680,48 +646,25 @@ var removeCNameFromDatabase = action.Action{
 	Forward: func(ctx action.FWContext) (action.Result, error) {
 		app := ctx.Params[0].(*appTypes.App)
 		cnames := ctx.Params[1].([]string)
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
-		var cnamesDone []string
-		for _, cname := range cnames {
-			// TODO: use $pullAll when uses mongo 5.0
-			_, err = collection.UpdateOne(
-				ctx.Context,
-				mongoBSON.M{"name": app.Name},
-				mongoBSON.M{"$pull": mongoBSON.M{"cname": cname}},
-			)
-			if err != nil {
-				_, revertErr := collection.UpdateOne(
-					ctx.Context,
-					mongoBSON.M{"name": app.Name},
-					mongoBSON.M{"$addToSet": mongoBSON.M{"cname": mongoBSON.M{"$each": cnamesDone}}},
-				)
-				if revertErr != nil {
-					log.Errorf("BACKWARD remove cname db - unable to revert update: %s", revertErr)
-				}
-
-				return nil, err
-			}
-			cnamesDone = append(cnamesDone, cname)
+		err = as.RemoveCNames(ctx.Context, app.Name, cnames)
+		if err != nil {
+			return nil, err
 		}
-		return cnamesDone, nil
+		return cnames, nil
 	},
 	Backward: func(ctx action.BWContext) {
 		app := ctx.Params[0].(*appTypes.App)
 		cnames := ctx.Params[1].([]string)
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			log.Errorf("BACKWARD remove cname db - unable to connect to db: %s", err)
 			return
 		}
-
-		_, revertErr := collection.UpdateOne(
-			ctx.Context,
-			mongoBSON.M{"name": app.Name},
-			mongoBSON.M{"$addToSet": mongoBSON.M{"cname": mongoBSON.M{"$each": cnames}}},
-		)
+		revertErr := as.AddCNames(ctx.Context, app.Name, cnames)
 		if revertErr != nil {
 			log.Errorf("BACKWARD remove cname db - unable to revert update: %s", revertErr)
 		}
@@ -746,16 +689,15 @@ var checkSingleCNameExists = action.Action{
 	Forward: func(ctx action.FWContext) (action.Result, error) {
 		cname := ctx.Params[1].(string)
 
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
-
-		cs, err := collection.CountDocuments(ctx.Context, mongoBSON.M{"cname": cname})
+		cnameApps, err := as.FindByCName(ctx.Context, cname)
 		if err != nil {
 			return nil, err
 		}
-		if cs == 0 {
+		if len(cnameApps) == 0 {
 			return nil, ErrCNameDoesNotExist
 		}
 
@@ -808,40 +750,23 @@ var saveCertIssuer = action.Action{
 		cname := ctx.Params[1].(string)
 		issuer := ctx.Params[2].(string)
 
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
-
-		sanitizedCName := strings.ReplaceAll(cname, ".", appTypes.CertIssuerDotReplacement)
-		certIssuerCName := fmt.Sprintf("certissuers.%s", sanitizedCName)
-
-		_, err = collection.UpdateOne(
-			ctx.Context,
-			mongoBSON.M{"name": app.Name},
-			mongoBSON.M{"$set": mongoBSON.M{certIssuerCName: issuer}},
-		)
+		err = as.SetCertIssuer(ctx.Context, app.Name, cname, issuer)
 		return cname, err
 	},
 	Backward: func(ctx action.BWContext) {
 		app := ctx.Params[0].(*appTypes.App)
 		cname := ctx.Params[1].(string)
 
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			log.Errorf("BACKWARD remove certissuer db. unable to connect: %s", err)
 			return
 		}
-
-		sanitizedCName := strings.ReplaceAll(cname, ".", appTypes.CertIssuerDotReplacement)
-		certIssuerCName := fmt.Sprintf("certissuers.%s", sanitizedCName)
-
-		_, err = collection.UpdateOne(
-			ctx.Context,
-			mongoBSON.M{"name": app.Name},
-			mongoBSON.M{"$unset": mongoBSON.M{certIssuerCName: ""}},
-		)
-
+		err = as.RemoveCertIssuer(ctx.Context, app.Name, cname)
 		if err != nil {
 			log.Errorf("BACKWARD remove certissuer db. failed to update: %s", err)
 		}
@@ -854,19 +779,11 @@ var removeCertIssuer = action.Action{
 		app := ctx.Params[0].(*appTypes.App)
 		cname := ctx.Params[1].(string)
 
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
-
-		sanitizedCName := strings.ReplaceAll(cname, ".", appTypes.CertIssuerDotReplacement)
-		certIssuerCName := fmt.Sprintf("certissuers.%s", sanitizedCName)
-
-		_, err = collection.UpdateOne(
-			ctx.Context,
-			mongoBSON.M{"name": app.Name},
-			mongoBSON.M{"$unset": mongoBSON.M{certIssuerCName: ""}},
-		)
+		err = as.RemoveCertIssuer(ctx.Context, app.Name, cname)
 		return cname, err
 	},
 }
@@ -877,20 +794,12 @@ var removeCertIssuersFromDatabase = action.Action{
 		app := ctx.Params[0].(*appTypes.App)
 		cname := ctx.Params[1].([]string)
 
-		collection, err := storagev2.AppsCollection()
+		as, err := appStorage()
 		if err != nil {
 			return nil, err
 		}
-
 		for _, c := range cname {
-			sanitizedCName := strings.ReplaceAll(c, ".", appTypes.CertIssuerDotReplacement)
-			certIssuerCName := fmt.Sprintf("certissuers.%s", sanitizedCName)
-
-			_, err = collection.UpdateOne(
-				ctx.Context,
-				mongoBSON.M{"name": app.Name},
-				mongoBSON.M{"$unset": mongoBSON.M{certIssuerCName: ""}},
-			)
+			err = as.RemoveCertIssuer(ctx.Context, app.Name, c)
 		}
 		return cname, err
 	},
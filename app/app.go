@@ -28,7 +28,6 @@ import (
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/builder"
-	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
@@ -41,6 +40,7 @@ import (
 	"github.com/tsuru/tsuru/service"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
+	"github.com/tsuru/tsuru/storage"
 	"github.com/tsuru/tsuru/streamfmt"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	imgTypes "github.com/tsuru/tsuru/types/app/image"
@@ -54,8 +54,6 @@ import (
 	routerTypes "github.com/tsuru/tsuru/types/router"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	"github.com/tsuru/tsuru/validation"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 var AuthScheme auth.Scheme
@@ -163,6 +161,8 @@ func AppInfo(ctx context.Context, app *appTypes.App) (*appTypes.AppInfo, error)
 		TeamOwner:   app.TeamOwner,
 		Tags:        app.Tags,
 		Metadata:    app.Metadata,
+
+		AutoRollback: app.AutoRollback,
 	}
 
 	if version := image.GetPlatformVersion(app); version != "latest" {
@@ -310,20 +310,22 @@ func mergeProcesses(providerProcesses, appProcesses []appTypes.Process) []appTyp
 // GetByName queries the database to find an app identified by the given
 // name.
 func GetByName(ctx context.Context, name string) (*appTypes.App, error) {
-	var app appTypes.App
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return nil, err
 	}
-	err = collection.FindOne(ctx, mongoBSON.M{"name": name}).Decode(&app)
-	if err == mongo.ErrNoDocuments {
-		return nil, appTypes.ErrAppNotFound
-	}
+	return as.FindByName(ctx, name)
+}
+
+func appStorage() (appTypes.AppStorage, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
 	if err != nil {
-		return nil, err
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
 	}
-
-	return &app, nil
+	return dbDriver.AppStorage, nil
 }
 
 // CreateApp creates a new app.
@@ -434,21 +436,54 @@ type UpdateAppArgs struct {
 
 // Update changes informations of the application.
 func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err error) {
+	u, err := prepareUpdate(ctx, app, args)
+	if err != nil {
+		return err
+	}
+	if u.team != nil {
+		defer func() {
+			if err == nil {
+				Grant(ctx, app, u.team)
+			}
+		}()
+	}
+	if u.provisionerChanged {
+		defer func() {
+			rebuildErr := rebuild.RebuildRoutesWithAppName(app.Name, args.Writer)
+			if rebuildErr != nil {
+				log.Errorf("Could not rebuild route: %s", rebuildErr.Error())
+			}
+		}()
+	}
+	return action.NewPipeline(u.actions...).Execute(ctx, app, &u.oldApp, args.Writer)
+}
+
+// appUpdate holds the outcome of the validation of an app update, the app
+// itself is changed in place by prepareUpdate and u.actions persist it.
+type appUpdate struct {
+	oldApp             appTypes.App
+	team               *authTypes.Team
+	actions            []*action.Action
+	provisionerChanged bool
+	restart            bool
+}
+
+func prepareUpdate(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (*appUpdate, error) {
 	description := args.UpdateData.Description
 	poolName := args.UpdateData.Pool
 	teamOwner := args.UpdateData.TeamOwner
 	platform := args.UpdateData.Platform
 	tags := processTags(args.UpdateData.Tags)
-	oldApp := *app
+	u := &appUpdate{oldApp: *app}
 
-	oldPlan, err := json.Marshal(oldApp.Plan)
+	oldPlan, err := json.Marshal(u.oldApp.Plan)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	oldMetadata, err := json.Marshal(oldApp.Metadata)
+	oldMetadata, err := json.Marshal(u.oldApp.Metadata)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if description != "" {
@@ -458,21 +493,21 @@ func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err err
 		app.Pool = poolName
 		_, err = getPoolForApp(ctx, app, app.Pool)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	newProv, err := getProvisioner(ctx, app)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	oldProv, err := getProvisioner(ctx, &oldApp)
+	oldProv, err := getProvisioner(ctx, &u.oldApp)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if args.UpdateData.Plan.Name != "" {
 		plan, errFind := servicemanager.Plan.FindByName(ctx, args.UpdateData.Plan.Name)
 		if errFind != nil {
-			return errFind
+			return nil, errFind
 		}
 		app.Plan = *plan
 	}
@@ -484,46 +519,42 @@ func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err err
 
 	newPlan, err := json.Marshal(app.Plan)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if teamOwner != "" {
 		team, errTeam := servicemanager.Team.FindByName(ctx, teamOwner)
 		if errTeam != nil {
-			return errTeam
+			return nil, errTeam
 		}
 		app.TeamOwner = team.Name
-		defer func() {
-			if err == nil {
-				Grant(ctx, app, team)
-			}
-		}()
+		u.team = team
 	}
 	if tags != nil {
 		app.Tags = tags
 	}
 	err = args.UpdateData.Metadata.Validate()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	processesHasChanged, err := updateProcesses(ctx, app, args.UpdateData.Processes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	app.Metadata.Update(args.UpdateData.Metadata)
 
 	newMetadata, err := json.Marshal(app.Metadata)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if platform != "" {
 		var p, v string
 		p, v, err = getPlatformNameAndVersion(ctx, app, platform)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if app.Platform != p || app.PlatformVersion != v {
 			app.UpdatePlatform = true
@@ -536,41 +567,39 @@ func Update(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (err err
 	}
 	err = validate(ctx, app)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	actions := []*action.Action{
+	u.actions = []*action.Action{
 		&saveApp,
 	}
 	updatePipelineAdded := false
 	if newProv.GetName() == oldProv.GetName() {
 		updatePipelineAdded = true
-		actions = append(actions, &updateAppProvisioner)
+		u.actions = append(u.actions, &updateAppProvisioner)
 	}
 	if newProv.GetName() != oldProv.GetName() {
-		defer func() {
-			rebuildErr := rebuild.RebuildRoutesWithAppName(app.Name, args.Writer)
-			if rebuildErr != nil {
-				log.Errorf("Could not rebuild route: %s", rebuildErr.Error())
-			}
-		}()
+		u.provisionerChanged = true
 		err = validateVolumes(ctx, app)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		actions = append(actions,
+		u.actions = append(u.actions,
 			&provisionAppNewProvisioner,
 			&provisionAppAddUnits,
 			&destroyAppOldProvisioner)
 	} else if string(newPlan) != string(oldPlan) && args.ShouldRestart {
-		actions = append(actions, &restartApp)
-	} else if app.Pool != oldApp.Pool && !updatePipelineAdded {
-		actions = append(actions, &restartApp)
+		u.restart = true
+	} else if app.Pool != u.oldApp.Pool && !updatePipelineAdded {
+		u.restart = true
 	} else if processesHasChanged && args.ShouldRestart {
-		actions = append(actions, &restartApp)
+		u.restart = true
 	} else if string(newMetadata) != string(oldMetadata) && args.ShouldRestart {
-		actions = append(actions, &restartApp)
+		u.restart = true
+	}
+	if u.restart {
+		u.actions = append(u.actions, &restartApp)
 	}
-	return action.NewPipeline(actions...).Execute(ctx, app, &oldApp, args.Writer)
+	return u, nil
 }
 
 func updateProcesses(ctx context.Context, app *appTypes.App, newProcs []appTypes.Process) (changed bool, err error) {
@@ -813,9 +842,9 @@ func Delete(ctx context.Context, app *appTypes.App, evt *event.Event, requestID
 		logErr("Unable to release team quota", err)
 	}
 
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err == nil {
-		_, err = collection.DeleteOne(ctx, mongoBSON.M{"name": appName})
+		err = as.Delete(ctx, appName)
 	}
 	if err != nil {
 		logErr("Unable to remove app from db", err)
@@ -1001,16 +1030,11 @@ func Grant(ctx context.Context, app *appTypes.App, team *authTypes.Team) error {
 		return ErrAlreadyHaveAccess
 	}
 	app.Teams = append(app.Teams, team.Name)
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{"$addToSet": mongoBSON.M{"teams": team.Name}})
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return as.AddTeam(ctx, app.Name, team.Name)
 }
 
 // Revoke removes the access from a team. It returns an error if the team do
@@ -1026,15 +1050,11 @@ func Revoke(ctx context.Context, app *appTypes.App, team *authTypes.Team) error
 	last := len(app.Teams) - 1
 	app.Teams[index] = app.Teams[last]
 	app.Teams = app.Teams[:last]
-	collection, err := storagev2.AppsCollection()
-	if err != nil {
-		return err
-	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{"$pull": mongoBSON.M{"teams": team.Name}})
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	return nil
+	return as.RemoveTeam(ctx, app.Name, team.Name)
 }
 
 func SetPool(ctx context.Context, app *appTypes.App) error {
@@ -1528,12 +1548,11 @@ func SetEnvs(ctx context.Context, app *appTypes.App, setEnvs bindTypes.SetEnvArg
 		setEnv(app, env)
 	}
 
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{"$set": mongoBSON.M{"env": app.Env}})
+	err = as.SetEnvs(ctx, app.Name, app.Env)
 	if err != nil {
 		return err
 	}
@@ -1574,11 +1593,11 @@ func UnsetEnvs(ctx context.Context, app *appTypes.App, unsetEnvs bindTypes.Unset
 	for _, name := range unsetEnvs.VariableNames {
 		delete(app.Env, name)
 	}
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{"$set": mongoBSON.M{"env": app.Env}})
+	err = as.SetEnvs(ctx, app.Name, app.Env)
 	if err != nil {
 		return err
 	}
@@ -1662,11 +1681,11 @@ func AddInstance(ctx context.Context, app *appTypes.App, addArgs bindTypes.AddIn
 		streamfmt.FprintlnSectionf(addArgs.Writer, "Setting %d new environment variables", len(addArgs.Envs)+1)
 	}
 	app.ServiceEnvs = append(app.ServiceEnvs, addArgs.Envs...)
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{"$set": mongoBSON.M{"serviceenvs": app.ServiceEnvs}})
+	err = as.SetServiceEnvs(ctx, app.Name, app.ServiceEnvs)
 	if err != nil {
 		return err
 	}
@@ -1692,11 +1711,11 @@ func RemoveInstance(ctx context.Context, app *appTypes.App, removeArgs bindTypes
 	if removeArgs.Writer != nil {
 		streamfmt.FprintlnSectionf(removeArgs.Writer, "Unsetting %d environment variables", toUnset)
 	}
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{"$set": mongoBSON.M{"serviceenvs": app.ServiceEnvs}})
+	err = as.SetServiceEnvs(ctx, app.Name, app.ServiceEnvs)
 	if err != nil {
 		return err
 	}
@@ -1757,60 +1776,13 @@ func (f *Filter) ExtraIn(name string, value string) {
 	f.Extra[name] = append(f.Extra[name], value)
 }
 
-func (f *Filter) Query() mongoBSON.M {
+func (f *Filter) storageFilter() *appTypes.Filter {
 	if f == nil {
-		return mongoBSON.M{}
-	}
-	query := mongoBSON.M{}
-	if f.Extra != nil {
-		var orBlock []mongoBSON.M
-		for field, values := range f.Extra {
-			orBlock = append(orBlock, mongoBSON.M{
-				field: mongoBSON.M{"$in": values},
-			})
-		}
-		query["$or"] = orBlock
-	}
-	if f.NameMatches != "" {
-		query["name"] = mongoBSON.M{"$regex": f.NameMatches}
-	}
-	if f.Name != "" {
-		query["name"] = f.Name
-	}
-	if f.TeamOwner != "" {
-		query["teamowner"] = f.TeamOwner
-	}
-	if f.Platform != "" {
-		parts := strings.SplitN(f.Platform, ":", 2)
-		query["framework"] = parts[0]
-		if len(parts) == 2 {
-			v := parts[1]
-			if v == "latest" {
-				query["$and"] = []mongoBSON.M{
-					{"$or": []mongoBSON.M{
-						{"platformversion": mongoBSON.M{"$in": []string{"latest", ""}}},
-						{"platformversion": mongoBSON.M{"$exists": false}},
-					}},
-				}
-			} else {
-				query["platformversion"] = v
-			}
-		}
-	}
-	if f.UserOwner != "" {
-		query["owner"] = f.UserOwner
-	}
-	if f.Pool != "" {
-		query["pool"] = f.Pool
-	}
-	if len(f.Pools) > 0 {
-		query["pool"] = mongoBSON.M{"$in": f.Pools}
-	}
-	tags := processTags(f.Tags)
-	if len(tags) > 0 {
-		query["tags"] = mongoBSON.M{"$all": tags}
+		return nil
 	}
-	return query
+	filter := appTypes.Filter(*f)
+	filter.Tags = processTags(f.Tags)
+	return &filter
 }
 
 type AppUnitsResponse struct {
@@ -1877,21 +1849,17 @@ func Units(ctx context.Context, apps []*appTypes.App) (map[string]AppUnitsRespon
 
 // List returns the list of apps filtered through the filter parameter.
 func List(ctx context.Context, filter *Filter) ([]*appTypes.App, error) {
-	apps := []*appTypes.App{}
-	query := filter.Query()
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return nil, err
 	}
-
-	cursor, err := collection.Find(ctx, query)
+	storedApps, err := as.List(ctx, filter.storageFilter())
 	if err != nil {
 		return nil, err
 	}
-
-	err = cursor.All(ctx, &apps)
-	if err != nil {
-		return nil, err
+	apps := make([]*appTypes.App, len(storedApps))
+	for i := range storedApps {
+		apps[i] = &storedApps[i]
 	}
 
 	if filter != nil && len(filter.Statuses) > 0 {
@@ -2010,17 +1978,11 @@ func Start(ctx context.Context, app *appTypes.App, w io.Writer, process, version
 }
 
 func SetUpdatePlatform(ctx context.Context, app *appTypes.App, check bool) error {
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(
-		ctx,
-		mongoBSON.M{"name": app.Name},
-		mongoBSON.M{"$set": mongoBSON.M{"updateplatform": check}},
-	)
-
-	return err
+	return as.SetUpdatePlatform(ctx, app.Name, check)
 }
 
 func AddRouter(ctx context.Context, app *appTypes.App, appRouter appTypes.AppRouter) error {
@@ -2029,20 +1991,18 @@ func AddRouter(ctx context.Context, app *appTypes.App, appRouter appTypes.AppRou
 			return ErrRouterAlreadyLinked
 		}
 	}
-	cnames := app.CName
-	appCName := &appTypes.App{}
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	err = collection.FindOne(ctx, mongoBSON.M{"cname": mongoBSON.M{"$in": cnames}, "name": mongoBSON.M{"$ne": app.Name}, "routers": appRouter}).Decode(&appCName)
-	if err != nil && err != mongo.ErrNoDocuments {
-		return err
-	}
-	if appCName.Name != "" {
-		for _, cname := range appCName.CName {
-			if cnameInSet(cname, cnames) {
-				return errors.New(fmt.Sprintf("cname %s already exists for app %s using router %s", cname, appCName.Name, appRouter.Name))
+	for _, cname := range app.CName {
+		cnameApps, err := as.FindByCName(ctx, cname)
+		if err != nil {
+			return err
+		}
+		for _, a := range cnameApps {
+			if a.Name != app.Name && hasRouter(a.Routers, appRouter) {
+				return errors.New(fmt.Sprintf("cname %s already exists for app %s using router %s", cname, a.Name, appRouter.Name))
 			}
 		}
 	}
@@ -2073,15 +2033,6 @@ func AddRouter(ctx context.Context, app *appTypes.App, appRouter appTypes.AppRou
 	return nil
 }
 
-func cnameInSet(cname string, cnames []string) bool {
-	for _, v := range cnames {
-		if v == cname {
-			return true
-		}
-	}
-	return false
-}
-
 func UpdateRouter(ctx context.Context, app *appTypes.App, appRouter appTypes.AppRouter) error {
 	var existing *appTypes.AppRouter
 	routers := GetRouters(app)
@@ -2141,22 +2092,25 @@ func RemoveRouter(ctx context.Context, app *appTypes.App, name string) error {
 }
 
 func updateRoutersDB(ctx context.Context, app *appTypes.App, routers []appTypes.AppRouter) error {
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
 	app.Routers = routers
 	app.Router = ""
 	app.RouterOpts = nil
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app.Name}, mongoBSON.M{
-		"$set": mongoBSON.M{
-			"routers":    app.Routers,
-			"router":     app.Router,
-			"routeropts": app.RouterOpts,
-		},
-	})
+	return as.SetRouters(ctx, app.Name, routers)
+}
 
-	return err
+// hasRouter reports whether routers contains the router with the same
+// name and options.
+func hasRouter(routers []appTypes.AppRouter, r appTypes.AppRouter) bool {
+	for _, candidate := range routers {
+		if candidate.Name == r.Name && reflect.DeepEqual(candidate.Opts, r.Opts) {
+			return true
+		}
+	}
+	return false
 }
 
 func GetRouters(app *appTypes.App) []appTypes.AppRouter {
@@ -2408,27 +2362,11 @@ func RenameTeam(ctx context.Context, oldName, newName string) error {
 		defer evt.Abort(ctx)
 	}
 
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-
-	updates := []mongo.WriteModel{
-		mongo.NewUpdateManyModel().
-			SetFilter(mongoBSON.M{"teamowner": oldName}).
-			SetUpdate(mongoBSON.M{"$set": mongoBSON.M{"teamowner": newName}}),
-
-		mongo.NewUpdateManyModel().
-			SetFilter(mongoBSON.M{"teams": oldName}).
-			SetUpdate(mongoBSON.M{"$push": mongoBSON.M{"teams": newName}}),
-
-		mongo.NewUpdateManyModel().
-			SetFilter(mongoBSON.M{"teams": oldName}).
-			SetUpdate(mongoBSON.M{"$pull": mongoBSON.M{"teams": oldName}}),
-	}
-
-	_, err = collection.BulkWrite(ctx, updates)
-	return err
+	return as.RenameTeam(ctx, oldName, newName)
 }
 
 func GetHealthcheckData(ctx context.Context, app *appTypes.App) (routerTypes.HealthcheckData, error) {
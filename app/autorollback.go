@@ -0,0 +1,400 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const autoRollbackOwner = "auto-rollback"
+
+var (
+	defaultAutoRollbackWindow          = 5 * time.Minute
+	defaultAutoRollbackMaxRestarts     = 3
+	defaultAutoRollbackMaxFailedChecks = 3
+	defaultAutoRollbackCheckInterval   = 15 * time.Second
+	maxAutoRollbackWindow              = time.Hour
+)
+
+func autoRollbackWithDefaults(cfg appTypes.AutoRollback) appTypes.AutoRollback {
+	if cfg.Window == 0 {
+		cfg.Window = defaultAutoRollbackWindow
+	}
+	if cfg.MaxRestarts == 0 {
+		cfg.MaxRestarts = defaultAutoRollbackMaxRestarts
+	}
+	if cfg.MaxFailedChecks == 0 {
+		cfg.MaxFailedChecks = defaultAutoRollbackMaxFailedChecks
+	}
+	return cfg
+}
+
+func autoRollbackCheckInterval() time.Duration {
+	interval, err := config.GetDuration("deploy:auto-rollback:check-interval")
+	if err != nil || interval <= 0 {
+		return defaultAutoRollbackCheckInterval
+	}
+	return interval
+}
+
+// SetAutoRollback stores the post-deploy watch window configuration of the
+// app, it's used by deploys started after the change.
+func SetAutoRollback(ctx context.Context, app *appTypes.App, cfg appTypes.AutoRollback) error {
+	if cfg.Window < 0 || cfg.Window > maxAutoRollbackWindow {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("auto rollback window must be between 0 and %v", maxAutoRollbackWindow)}
+	}
+	if cfg.MaxRestarts < 0 || cfg.MaxFailedChecks < 0 {
+		return &tsuruErrors.ValidationError{Message: "auto rollback thresholds must not be negative"}
+	}
+	as, err := appStorage()
+	if err != nil {
+		return err
+	}
+	var stored *appTypes.AutoRollback
+	if cfg.Enabled {
+		stored = &cfg
+	}
+	err = as.SetAutoRollback(ctx, app.Name, stored)
+	if err != nil {
+		return err
+	}
+	app.AutoRollback = stored
+	return nil
+}
+
+type deployWatcher struct {
+	wg     sync.WaitGroup
+	quitCh chan struct{}
+}
+
+var globalDeployWatcher struct {
+	watcher *deployWatcher
+	once    sync.Once
+}
+
+func getDeployWatcher() *deployWatcher {
+	globalDeployWatcher.once.Do(func() {
+		globalDeployWatcher.watcher = &deployWatcher{
+			quitCh: make(chan struct{}),
+		}
+		shutdown.Register(globalDeployWatcher.watcher)
+	})
+	return globalDeployWatcher.watcher
+}
+
+func (w *deployWatcher) Shutdown(ctx context.Context) error {
+	close(w.quitCh)
+	waitCh := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(waitCh)
+	}()
+	select {
+	case <-waitCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// deployOtherData is stored as the other custom data of deploy events.
+type deployOtherData struct {
+	Diff         string             `bson:"diff,omitempty"`
+	AutoRollback *autoRollbackWatch `bson:"autorollback,omitempty"`
+}
+
+// autoRollbackWatch is the post-deploy watch window stored in the deploy
+// event, so the watch is resumed when tsuru restarts before its deadline.
+type autoRollbackWatch struct {
+	Config   appTypes.AutoRollback
+	Deploys  uint
+	Version  int
+	Previous int
+	Deadline time.Time
+}
+
+type deployWatch struct {
+	app         *appTypes.App
+	cfg         appTypes.AutoRollback
+	deployEvt   *event.Event
+	deploys     uint
+	version     appTypes.AppVersion
+	previous    appTypes.AppVersion
+	deadline    time.Time
+	failedCheck int
+}
+
+// watch starts the post-deploy watch window of version in background.
+func (w *deployWatcher) watch(dw *deployWatch) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ctx := context.Background()
+		interval := autoRollbackCheckInterval()
+		for time.Now().Before(dw.deadline) {
+			select {
+			case <-w.quitCh:
+				return
+			case <-time.After(interval):
+			}
+			reason, stop, err := dw.check(ctx)
+			if err != nil {
+				log.Errorf("[auto-rollback] unable to check app %q version %d: %v", dw.app.Name, dw.version.Version(), err)
+				continue
+			}
+			if stop {
+				return
+			}
+			if reason != "" {
+				err = dw.rollback(ctx, reason)
+				if err != nil {
+					log.Errorf("[auto-rollback] unable to roll back app %q to version %d: %v", dw.app.Name, dw.previous.Version(), err)
+				}
+				return
+			}
+		}
+	}()
+}
+
+// check returns the reason the watched version must be rolled back, if any,
+// or stop as true if a newer deploy replaced it and the watch is no longer
+// needed.
+func (dw *deployWatch) check(ctx context.Context) (reason string, stop bool, err error) {
+	app, err := GetByName(ctx, dw.app.Name)
+	if err != nil {
+		if err == appTypes.ErrAppNotFound {
+			return "", true, nil
+		}
+		return "", false, err
+	}
+	if app.Deploys != dw.deploys {
+		return "", true, nil
+	}
+	dw.app = app
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return "", false, err
+	}
+	var statuses []router.RouterBackendStatus
+	for _, appRouter := range GetRouters(app) {
+		r, err := router.Get(ctx, appRouter.Name)
+		if err != nil {
+			return "", false, err
+		}
+		status, err := r.GetBackendStatus(ctx, app)
+		if err != nil {
+			return "", false, err
+		}
+		statuses = append(statuses, status)
+	}
+	var failed bool
+	reason, failed = evaluateDeployHealth(dw.cfg, units, statuses, dw.version.Version())
+	if reason != "" {
+		return reason, false, nil
+	}
+	if !failed {
+		dw.failedCheck = 0
+		return "", false, nil
+	}
+	dw.failedCheck++
+	if dw.failedCheck >= dw.cfg.MaxFailedChecks {
+		return fmt.Sprintf("version %d failed %d consecutive health checks", dw.version.Version(), dw.failedCheck), false, nil
+	}
+	return "", false, nil
+}
+
+// evaluateDeployHealth returns a rollback reason when the restart threshold
+// is crossed, otherwise it reports whether the current check failed because
+// some unit of version or some router backend is not ready.
+func evaluateDeployHealth(cfg appTypes.AutoRollback, units []provTypes.Unit, statuses []router.RouterBackendStatus, version int) (string, bool) {
+	var restarts int
+	var failed bool
+	for _, u := range units {
+		if u.Version != version {
+			continue
+		}
+		if u.Restarts != nil {
+			restarts += int(*u.Restarts)
+		}
+		if u.Status == provTypes.UnitStatusError || (u.Ready != nil && !*u.Ready) {
+			failed = true
+		}
+	}
+	if restarts > cfg.MaxRestarts {
+		return fmt.Sprintf("units of version %d restarted %d times, more than the limit of %d", version, restarts, cfg.MaxRestarts), true
+	}
+	for _, status := range statuses {
+		if status.Status == router.BackendStatusNotReady {
+			failed = true
+		}
+	}
+	return "", failed
+}
+
+func (dw *deployWatch) rollback(ctx context.Context, reason string) (err error) {
+	opts := DeployOptions{
+		App:      dw.app,
+		Image:    fmt.Sprint(dw.previous.Version()),
+		Origin:   "rollback",
+		Rollback: true,
+		Message:  fmt.Sprintf("automatic rollback: %s", reason),
+		User:     autoRollbackOwner,
+
+		RollbackOf: dw.deployEvt.UniqueID.Hex(),
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: dw.app.Name},
+		Kind:       permission.PermAppDeploy,
+		RawOwner:   eventTypes.Owner{Type: eventTypes.OwnerTypeInternal, Name: autoRollbackOwner},
+		CustomData: opts,
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permTypes.CtxTeam, dw.app.Teams),
+			permission.Context(permTypes.CtxApp, dw.app.Name),
+			permission.Context(permTypes.CtxPool, dw.app.Pool),
+		)...),
+	})
+	if err != nil {
+		return err
+	}
+	var imageID string
+	defer func() { evt.DoneCustomData(ctx, err, map[string]string{"image": imageID}) }()
+	fmt.Fprintf(evt, "Rolling back version %d to version %d, triggered by deploy event %s: %s\n", dw.version.Version(), dw.previous.Version(), opts.RollbackOf, reason)
+	opts.Event = evt
+	imageID, err = Deploy(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return dw.version.ToggleEnabled(false, opts.Message)
+}
+
+// watchAfterDeploy starts the post-deploy watch window when auto rollback is
+// enabled for the app and there's a previous successful version to return
+// to.
+func watchAfterDeploy(ctx context.Context, opts *DeployOptions, previous appTypes.AppVersion) {
+	if opts.App.AutoRollback == nil || !opts.App.AutoRollback.Enabled || previous == nil || opts.version == nil {
+		return
+	}
+	if opts.Rollback || previous.Version() == opts.version.Version() {
+		return
+	}
+	cfg := autoRollbackWithDefaults(*opts.App.AutoRollback)
+	dw := &deployWatch{
+		app:       opts.App,
+		cfg:       cfg,
+		deployEvt: opts.Event,
+		deploys:   opts.App.Deploys,
+		version:   opts.version,
+		previous:  previous,
+		deadline:  time.Now().Add(cfg.Window),
+	}
+	err := opts.Event.SetOtherCustomData(ctx, deployOtherData{AutoRollback: &autoRollbackWatch{
+		Config:   dw.cfg,
+		Deploys:  dw.deploys,
+		Version:  dw.version.Version(),
+		Previous: dw.previous.Version(),
+		Deadline: dw.deadline,
+	}})
+	if err != nil {
+		log.Errorf("[auto-rollback] unable to store watch of app %q version %d, it won't be resumed on restart: %v", opts.App.Name, opts.version.Version(), err)
+	}
+	fmt.Fprintf(opts.Event, "\nWatching version %d for %v, it will be rolled back to version %d if it becomes unhealthy\n", opts.version.Version(), cfg.Window, previous.Version())
+	getDeployWatcher().watch(dw)
+}
+
+// ResumeAutoRollbackWatches restarts the post-deploy watch windows stored in
+// deploy events that didn't reach their deadline, which were interrupted
+// when tsuru stopped.
+func ResumeAutoRollbackWatches(ctx context.Context) error {
+	watches, err := pendingDeployWatches(ctx)
+	if err != nil {
+		return err
+	}
+	for _, dw := range watches {
+		getDeployWatcher().watch(dw)
+	}
+	return nil
+}
+
+func pendingDeployWatches(ctx context.Context) ([]*deployWatch, error) {
+	now := time.Now()
+	evts, err := event.List(ctx, &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp},
+		KindNames: []string{permission.PermAppDeploy.FullName()},
+		Since:     now.Add(-maxAutoRollbackWindow),
+		Raw: mongoBSON.M{
+			"othercustomdata.autorollback.deadline": mongoBSON.M{"$gt": now},
+			"error":                                 "",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var watches []*deployWatch
+	for _, evt := range evts {
+		var data deployOtherData
+		err = evt.OtherData(&data)
+		if err != nil || data.AutoRollback == nil {
+			continue
+		}
+		dw, err := resumeDeployWatch(ctx, evt, data.AutoRollback)
+		if err != nil {
+			log.Errorf("[auto-rollback] unable to resume watch of deploy event %s: %v", evt.UniqueID.Hex(), err)
+			continue
+		}
+		if dw != nil {
+			watches = append(watches, dw)
+		}
+	}
+	return watches, nil
+}
+
+// resumeDeployWatch returns the watch stored in the deploy event, or nil if
+// the app was removed or deployed again since.
+func resumeDeployWatch(ctx context.Context, evt *event.Event, stored *autoRollbackWatch) (*deployWatch, error) {
+	app, err := GetByName(ctx, evt.Target.Value)
+	if err != nil {
+		if err == appTypes.ErrAppNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if app.Deploys != stored.Deploys {
+		return nil, nil
+	}
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, strconv.Itoa(stored.Version))
+	if err != nil {
+		return nil, err
+	}
+	previous, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, strconv.Itoa(stored.Previous))
+	if err != nil {
+		return nil, err
+	}
+	return &deployWatch{
+		app:       app,
+		cfg:       stored.Config,
+		deployEvt: evt,
+		deploys:   stored.Deploys,
+		version:   version,
+		previous:  previous,
+		deadline:  stored.Deadline,
+	}, nil
+}
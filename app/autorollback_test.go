@@ -0,0 +1,148 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAutoRollbackWithDefaults(c *check.C) {
+	cfg := autoRollbackWithDefaults(appTypes.AutoRollback{Enabled: true})
+	c.Assert(cfg, check.DeepEquals, appTypes.AutoRollback{
+		Enabled:         true,
+		Window:          5 * time.Minute,
+		MaxRestarts:     3,
+		MaxFailedChecks: 3,
+	})
+	cfg = autoRollbackWithDefaults(appTypes.AutoRollback{Enabled: true, Window: time.Minute, MaxRestarts: 1, MaxFailedChecks: 2})
+	c.Assert(cfg, check.DeepEquals, appTypes.AutoRollback{
+		Enabled:         true,
+		Window:          time.Minute,
+		MaxRestarts:     1,
+		MaxFailedChecks: 2,
+	})
+}
+
+func (s *S) TestSetAutoRollback(c *check.C) {
+	a := appTypes.App{Name: "some-app", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	cfg := appTypes.AutoRollback{Enabled: true, Window: 10 * time.Minute, MaxRestarts: 2}
+	err = SetAutoRollback(context.TODO(), &a, cfg)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.AutoRollback, check.DeepEquals, &cfg)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AutoRollback, check.DeepEquals, &cfg)
+	err = SetAutoRollback(context.TODO(), &a, appTypes.AutoRollback{})
+	c.Assert(err, check.IsNil)
+	c.Assert(a.AutoRollback, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AutoRollback, check.IsNil)
+}
+
+func (s *S) TestSetAutoRollbackInvalid(c *check.C) {
+	a := appTypes.App{Name: "some-app"}
+	err := SetAutoRollback(context.TODO(), &a, appTypes.AutoRollback{Enabled: true, Window: 2 * time.Hour})
+	c.Assert(err, check.ErrorMatches, "auto rollback window must be between 0 and 1h0m0s")
+	err = SetAutoRollback(context.TODO(), &a, appTypes.AutoRollback{Enabled: true, MaxRestarts: -1})
+	c.Assert(err, check.ErrorMatches, "auto rollback thresholds must not be negative")
+}
+
+func (s *S) TestEvaluateDeployHealth(c *check.C) {
+	cfg := autoRollbackWithDefaults(appTypes.AutoRollback{Enabled: true})
+	ready, notReady := true, false
+	one, three := int32(1), int32(3)
+	units := []provTypes.Unit{
+		{ID: "u1", Version: 1, Ready: &notReady, Restarts: &three},
+		{ID: "u2", Version: 2, Ready: &ready, Restarts: &one},
+		{ID: "u3", Version: 2, Ready: &ready, Restarts: &one},
+	}
+	readyStatus := []router.RouterBackendStatus{{Status: router.BackendStatusReady}}
+	reason, failed := evaluateDeployHealth(cfg, units, readyStatus, 2)
+	c.Assert(reason, check.Equals, "")
+	c.Assert(failed, check.Equals, false)
+	reason, failed = evaluateDeployHealth(cfg, units, []router.RouterBackendStatus{{Status: router.BackendStatusNotReady}}, 2)
+	c.Assert(reason, check.Equals, "")
+	c.Assert(failed, check.Equals, true)
+	units[2].Ready = &notReady
+	reason, failed = evaluateDeployHealth(cfg, units, readyStatus, 2)
+	c.Assert(reason, check.Equals, "")
+	c.Assert(failed, check.Equals, true)
+	units[2].Restarts = &three
+	reason, failed = evaluateDeployHealth(cfg, units, readyStatus, 2)
+	c.Assert(reason, check.Equals, "units of version 2 restarted 4 times, more than the limit of 3")
+	c.Assert(failed, check.Equals, true)
+}
+
+func (s *S) TestDeployWatchCheckStopsAfterNewDeploy(c *check.C) {
+	a := appTypes.App{Name: "some-app", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	dw := &deployWatch{
+		app:     &a,
+		cfg:     autoRollbackWithDefaults(appTypes.AutoRollback{Enabled: true}),
+		deploys: a.Deploys,
+		version: version,
+	}
+	err = incrementDeploy(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	reason, stop, err := dw.check(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(stop, check.Equals, true)
+	c.Assert(reason, check.Equals, "")
+}
+
+func (s *S) TestPendingDeployWatches(c *check.C) {
+	a := appTypes.App{Name: "some-app", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	previous := newSuccessfulAppVersion(c, &a)
+	version := newSuccessfulAppVersion(c, &a)
+	cfg := autoRollbackWithDefaults(appTypes.AutoRollback{Enabled: true})
+	newDeployEvent := func(watch *autoRollbackWatch) *event.Event {
+		evt, err := event.New(context.TODO(), &event.Opts{
+			Target:      eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+			Kind:        permission.PermAppDeploy,
+			RawOwner:    eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+			Allowed:     event.Allowed(permission.PermApp),
+			DisableLock: true,
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.SetOtherCustomData(context.TODO(), deployOtherData{AutoRollback: watch})
+		c.Assert(err, check.IsNil)
+		err = evt.Done(context.TODO(), nil)
+		c.Assert(err, check.IsNil)
+		return evt
+	}
+	deadline := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	evt := newDeployEvent(&autoRollbackWatch{Config: cfg, Deploys: a.Deploys, Version: version.Version(), Previous: previous.Version(), Deadline: deadline})
+	newDeployEvent(&autoRollbackWatch{Config: cfg, Deploys: a.Deploys, Version: version.Version(), Previous: previous.Version(), Deadline: time.Now().Add(-time.Minute)})
+	newDeployEvent(nil)
+	watches, err := pendingDeployWatches(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(watches, check.HasLen, 1)
+	c.Assert(watches[0].deployEvt.UniqueID, check.Equals, evt.UniqueID)
+	c.Assert(watches[0].cfg, check.DeepEquals, cfg)
+	c.Assert(watches[0].version.Version(), check.Equals, version.Version())
+	c.Assert(watches[0].previous.Version(), check.Equals, previous.Version())
+	c.Assert(watches[0].deadline.Equal(deadline), check.Equals, true)
+	err = incrementDeploy(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	watches, err = pendingDeployWatches(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(watches, check.HasLen, 0)
+}
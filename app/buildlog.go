@@ -0,0 +1,73 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/set"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// VersionLog returns the build and deploy output of the version, split by
+// its phases. Only the phases named in phaseNames are returned, when it's not
+// empty, and only the log entries matched by args.
+func VersionLog(ctx context.Context, app *appTypes.App, versionStr string, phaseNames []string, args appTypes.ListLogArgs) (*appTypes.AppVersionLog, error) {
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, versionStr)
+	if err != nil {
+		return nil, err
+	}
+	versionInfo := version.VersionInfo()
+	names := set.FromSlice(phaseNames)
+	events := map[string]*event.Event{}
+	result := &appTypes.AppVersionLog{
+		Version: versionInfo.Version,
+		Phases:  []appTypes.AppVersionLogPhase{},
+	}
+	for _, phase := range versionInfo.BuildPhases {
+		if len(names) > 0 && !names.Includes(phase.Name) {
+			continue
+		}
+		evt, ok := events[phase.EventID]
+		if !ok {
+			evt, err = event.GetByHexID(ctx, phase.EventID)
+			if err != nil && err != event.ErrEventNotFound {
+				return nil, err
+			}
+			events[phase.EventID] = evt
+		}
+		result.Phases = append(result.Phases, appTypes.AppVersionLogPhase{
+			BuildPhase:      phase,
+			DurationSeconds: phase.Duration().Seconds(),
+			Log:             phaseLog(evt, phase, matcher),
+		})
+	}
+	return result, nil
+}
+
+// phaseLog returns the entries in the structured log of the event written
+// while the phase was running.
+func phaseLog(evt *event.Event, phase appTypes.BuildPhase, matcher *appTypes.LogMatcher) []appTypes.AppVersionLogEntry {
+	entries := []appTypes.AppVersionLogEntry{}
+	if evt == nil {
+		return entries
+	}
+	for _, entry := range evt.StructuredLog {
+		if entry.Date.Before(phase.StartTime) || (!phase.EndTime.IsZero() && entry.Date.After(phase.EndTime)) {
+			continue
+		}
+		if !matcher.Match(&appTypes.Applog{Date: entry.Date, Message: entry.Message}) {
+			continue
+		}
+		entries = append(entries, appTypes.AppVersionLogEntry{Date: entry.Date, Message: entry.Message})
+	}
+	return entries
+}
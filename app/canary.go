@@ -0,0 +1,368 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/streamfmt"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+var (
+	defaultCanarySteps    = []int{10, 50}
+	defaultCanaryInterval = time.Minute
+
+	canaryQueryTimeout = 30 * time.Second
+)
+
+// CanaryOptions describes how traffic is shifted from the currently deployed
+// version to the new one during a canary deploy. Each step routes the given
+// percentage of traffic to the new version, which is then observed for
+// Interval before advancing. The new version is promoted after the last step
+// and the deploy is aborted as soon as any check fails.
+type CanaryOptions struct {
+	Steps    []int
+	Interval time.Duration
+
+	// Query is an optional Prometheus query evaluated at the end of each
+	// step, the canary is aborted if any resulting sample is greater than
+	// Threshold. The query is a text/template with {{.App}} and {{.Version}}
+	// available.
+	Query     string
+	Threshold float64
+}
+
+func (o *CanaryOptions) setDefaults() {
+	if len(o.Steps) == 0 {
+		o.Steps = defaultCanarySteps
+	}
+	if o.Interval == 0 {
+		interval, err := config.GetDuration("deploy:canary:step-interval")
+		if err != nil {
+			interval = defaultCanaryInterval
+		}
+		o.Interval = interval
+	}
+}
+
+func (o *CanaryOptions) validate() error {
+	last := 0
+	for _, step := range o.Steps {
+		if step <= last || step >= 100 {
+			return &tsuruErrors.ValidationError{Message: "canary steps must be increasing percentages between 1 and 99"}
+		}
+		last = step
+	}
+	if o.Interval < 0 {
+		return &tsuruErrors.ValidationError{Message: "canary step interval must not be negative"}
+	}
+	if o.Query != "" {
+		if _, err := canaryPrometheusAddress(); err != nil {
+			return &tsuruErrors.ValidationError{Message: "canary queries require deploy:canary:prometheus-address to be configured"}
+		}
+		if _, err := template.New("query").Parse(o.Query); err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid canary query: %v", err)}
+		}
+	}
+	return nil
+}
+
+func canaryPrometheusAddress() (string, error) {
+	return config.GetString("deploy:canary:prometheus-address")
+}
+
+func validateCanary(ctx context.Context, opts DeployOptions) error {
+	if opts.NewVersion || opts.OverrideVersions {
+		return errors.New("canary deploys can't be combined with new-version or override-versions")
+	}
+	if opts.GetKind() == provTypes.DeployRollback {
+		return errors.New("canary deploys are not supported for rollbacks")
+	}
+	opts.Canary.setDefaults()
+	err := opts.Canary.validate()
+	if err != nil {
+		return err
+	}
+	versionProv, err := versionsProvisioner(ctx, opts.App)
+	if err != nil {
+		return err
+	}
+	versions, err := versionProv.DeployedVersions(ctx, opts.App)
+	if err != nil {
+		return err
+	}
+	if len(versions) != 1 {
+		return errors.Errorf("canary deploys require exactly one deployed version, found %d", len(versions))
+	}
+	return nil
+}
+
+func versionsProvisioner(ctx context.Context, app *appTypes.App) (provision.VersionsProvisioner, error) {
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	versionProv, ok := prov.(provision.VersionsProvisioner)
+	if !ok {
+		return nil, provision.ProvisionerNotSupported{Prov: prov, Action: "canary deploys"}
+	}
+	return versionProv, nil
+}
+
+type canaryDeploy struct {
+	app        *appTypes.App
+	opts       CanaryOptions
+	w          io.Writer
+	prov       provision.VersionsProvisioner
+	oldVersion appTypes.AppVersion
+	newVersion appTypes.AppVersion
+	restarts   map[string]int32
+}
+
+// runCanary shifts traffic to newVersion following the configured steps,
+// promoting it at the end or rolling back to the previous version if any
+// step is unhealthy.
+func runCanary(ctx context.Context, app *appTypes.App, opts CanaryOptions, newVersion appTypes.AppVersion, w io.Writer) error {
+	versionProv, err := versionsProvisioner(ctx, app)
+	if err != nil {
+		return err
+	}
+	oldVersion, err := previousDeployedVersion(ctx, app, versionProv, newVersion.Version())
+	if err != nil {
+		return err
+	}
+	cd := &canaryDeploy{
+		app:        app,
+		opts:       opts,
+		w:          w,
+		prov:       versionProv,
+		oldVersion: oldVersion,
+		newVersion: newVersion,
+	}
+	err = cd.run(ctx)
+	if err == nil {
+		return cd.promote(ctx)
+	}
+	fmt.Fprint(w, "\n")
+	streamfmt.FprintlnSectionf(w, "Canary aborted: %v", err)
+	if abortErr := cd.abort(context.WithoutCancel(ctx)); abortErr != nil {
+		return errors.Wrapf(abortErr, "unable to abort canary after error: %v", err)
+	}
+	return errors.Wrap(err, "canary aborted")
+}
+
+func previousDeployedVersion(ctx context.Context, app *appTypes.App, versionProv provision.VersionsProvisioner, newVersion int) (appTypes.AppVersion, error) {
+	versions, err := versionProv.DeployedVersions(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	sort.Ints(versions)
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i] != newVersion {
+			return getVersion(ctx, app, fmt.Sprint(versions[i]))
+		}
+	}
+	return nil, errors.New("no previous version found for canary deploy")
+}
+
+func (cd *canaryDeploy) run(ctx context.Context) error {
+	err := cd.prov.ToggleRoutable(ctx, cd.app, cd.newVersion, true)
+	if err != nil {
+		return err
+	}
+	for i, weight := range cd.opts.Steps {
+		fmt.Fprint(cd.w, "\n")
+		streamfmt.FprintlnSectionf(cd.w, "Canary step %d/%d: routing %d%% of traffic to version %d", i+1, len(cd.opts.Steps), weight, cd.newVersion.Version())
+		err = cd.setWeights(ctx, map[int]int{
+			cd.oldVersion.Version(): 100 - weight,
+			cd.newVersion.Version(): weight,
+		})
+		if err != nil {
+			return err
+		}
+		if err = cd.checkUnits(ctx); err != nil {
+			return err
+		}
+		streamfmt.FprintlnActionf(cd.w, "Observing version %d for %v", cd.newVersion.Version(), cd.opts.Interval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cd.opts.Interval):
+		}
+		if err = cd.checkUnits(ctx); err != nil {
+			return err
+		}
+		if err = cd.checkQuery(ctx); err != nil {
+			return err
+		}
+		streamfmt.FprintlnActionf(cd.w, "Step %d/%d healthy", i+1, len(cd.opts.Steps))
+	}
+	return nil
+}
+
+func (cd *canaryDeploy) promote(ctx context.Context) error {
+	fmt.Fprint(cd.w, "\n")
+	streamfmt.FprintlnSectionf(cd.w, "Promoting version %d to 100%% of traffic", cd.newVersion.Version())
+	err := cd.prov.ToggleRoutable(ctx, cd.app, cd.oldVersion, false)
+	if err != nil {
+		return err
+	}
+	err = cd.setWeights(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return DeleteVersion(ctx, cd.app, cd.w, fmt.Sprint(cd.oldVersion.Version()))
+}
+
+func (cd *canaryDeploy) abort(ctx context.Context) error {
+	streamfmt.FprintlnSectionf(cd.w, "Routing all traffic back to version %d", cd.oldVersion.Version())
+	err := cd.prov.ToggleRoutable(ctx, cd.app, cd.newVersion, false)
+	if err != nil {
+		return err
+	}
+	err = cd.setWeights(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return DeleteVersion(ctx, cd.app, cd.w, fmt.Sprint(cd.newVersion.Version()))
+}
+
+func (cd *canaryDeploy) setWeights(ctx context.Context, weights map[int]int) error {
+	as, err := appStorage()
+	if err != nil {
+		return err
+	}
+	err = as.SetRoutingWeights(ctx, cd.app.Name, weights)
+	if err != nil {
+		return err
+	}
+	cd.app.RoutingWeights = weights
+	return rebuild.RebuildRoutes(ctx, rebuild.RebuildRoutesOpts{
+		App:    cd.app,
+		Writer: cd.w,
+	})
+}
+
+func (cd *canaryDeploy) checkUnits(ctx context.Context) error {
+	units, err := AppUnits(ctx, cd.app)
+	if err != nil {
+		return err
+	}
+	restarts, err := checkCanaryUnits(units, cd.newVersion.Version(), cd.restarts)
+	if err != nil {
+		return err
+	}
+	cd.restarts = restarts
+	return nil
+}
+
+// checkCanaryUnits returns an error if any unit of the given version is not
+// healthy or restarted since the last check, along with the current restart
+// count of each unit.
+func checkCanaryUnits(units []provTypes.Unit, version int, lastRestarts map[string]int32) (map[string]int32, error) {
+	restarts := map[string]int32{}
+	var found bool
+	for _, u := range units {
+		if u.Version != version {
+			continue
+		}
+		found = true
+		if u.Status == provTypes.UnitStatusError {
+			return nil, errors.Errorf("unit %s is in error state: %s", u.ID, u.StatusReason)
+		}
+		if u.Ready != nil && !*u.Ready {
+			return nil, errors.Errorf("unit %s is not ready", u.ID)
+		}
+		if u.Restarts == nil {
+			continue
+		}
+		restarts[u.ID] = *u.Restarts
+		if last, ok := lastRestarts[u.ID]; ok && *u.Restarts > last {
+			return nil, errors.Errorf("unit %s restarted %d time(s) during canary", u.ID, *u.Restarts-last)
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("no units found for version %d", version)
+	}
+	return restarts, nil
+}
+
+func (cd *canaryDeploy) checkQuery(ctx context.Context) error {
+	if cd.opts.Query == "" {
+		return nil
+	}
+	value, err := queryCanaryMetric(ctx, cd.opts.Query, cd.app.Name, cd.newVersion.Version())
+	if err != nil {
+		return err
+	}
+	streamfmt.FprintlnActionf(cd.w, "Canary query returned %v (threshold %v)", value, cd.opts.Threshold)
+	if value > cd.opts.Threshold {
+		return errors.Errorf("canary query value %v is above threshold %v", value, cd.opts.Threshold)
+	}
+	return nil
+}
+
+// queryCanaryMetric runs the query against the configured Prometheus
+// returning the highest sample value.
+func queryCanaryMetric(ctx context.Context, query, appName string, version int) (float64, error) {
+	address, err := canaryPrometheusAddress()
+	if err != nil {
+		return 0, err
+	}
+	tmpl, err := template.New("query").Parse(query)
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]interface{}{
+		"App":     appName,
+		"Version": version,
+	})
+	if err != nil {
+		return 0, err
+	}
+	client, err := promapi.NewClient(promapi.Config{Address: address})
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, canaryQueryTimeout)
+	defer cancel()
+	result, _, err := promv1.NewAPI(client).Query(ctx, buf.String(), time.Now())
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to run canary query")
+	}
+	switch v := result.(type) {
+	case *model.Scalar:
+		return float64(v.Value), nil
+	case model.Vector:
+		if len(v) == 0 {
+			return 0, errors.New("canary query returned no samples")
+		}
+		max := float64(v[0].Value)
+		for _, sample := range v[1:] {
+			if float64(sample.Value) > max {
+				max = float64(sample.Value)
+			}
+		}
+		return max, nil
+	}
+	return 0, errors.Errorf("unsupported canary query result type %q", result.Type())
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCanaryOptionsSetDefaults(c *check.C) {
+	opts := CanaryOptions{}
+	opts.setDefaults()
+	c.Assert(opts.Steps, check.DeepEquals, []int{10, 50})
+	c.Assert(opts.Interval, check.Equals, time.Minute)
+	config.Set("deploy:canary:step-interval", "30s")
+	defer config.Unset("deploy:canary:step-interval")
+	opts = CanaryOptions{Steps: []int{20}}
+	opts.setDefaults()
+	c.Assert(opts.Steps, check.DeepEquals, []int{20})
+	c.Assert(opts.Interval, check.Equals, 30*time.Second)
+}
+
+func (s *S) TestCanaryOptionsValidate(c *check.C) {
+	tests := []struct {
+		opts CanaryOptions
+		err  string
+	}{
+		{CanaryOptions{Steps: []int{10, 50}}, ""},
+		{CanaryOptions{Steps: []int{50, 10}}, "canary steps must be increasing percentages between 1 and 99"},
+		{CanaryOptions{Steps: []int{0, 10}}, "canary steps must be increasing percentages between 1 and 99"},
+		{CanaryOptions{Steps: []int{10, 100}}, "canary steps must be increasing percentages between 1 and 99"},
+		{CanaryOptions{Steps: []int{10}, Interval: -time.Second}, "canary step interval must not be negative"},
+		{CanaryOptions{Steps: []int{10}, Query: "up"}, "canary queries require deploy:canary:prometheus-address to be configured"},
+	}
+	for i, tt := range tests {
+		err := tt.opts.validate()
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("test %d", i))
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("test %d", i))
+		}
+	}
+}
+
+func (s *S) TestValidateCanaryConflictingFlags(c *check.C) {
+	a := appTypes.App{Name: "some-app"}
+	err := validateCanary(context.TODO(), DeployOptions{App: &a, Image: "img", NewVersion: true, Canary: &CanaryOptions{}})
+	c.Assert(err, check.ErrorMatches, "canary deploys can't be combined with new-version or override-versions")
+	err = validateCanary(context.TODO(), DeployOptions{App: &a, Image: "v1", Rollback: true, Canary: &CanaryOptions{}})
+	c.Assert(err, check.ErrorMatches, "canary deploys are not supported for rollbacks")
+}
+
+func (s *S) TestDeployCanaryNotSupportedByProvisioner(c *check.C) {
+	a := appTypes.App{
+		Name:      "some-app",
+		Platform:  "django",
+		TeamOwner: s.team.Name,
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = Deploy(context.TODO(), DeployOptions{
+		App:    &a,
+		Image:  "myimage",
+		Event:  evt,
+		Canary: &CanaryOptions{},
+	})
+	c.Assert(err, check.ErrorMatches, `provisioner "fake" does not support canary deploys`)
+}
+
+func (s *S) TestCheckCanaryUnits(c *check.C) {
+	ready, notReady := true, false
+	zero, one := int32(0), int32(1)
+	units := []provTypes.Unit{
+		{ID: "u1", Version: 1, Ready: &notReady},
+		{ID: "u2", Version: 2, Ready: &ready, Restarts: &zero},
+	}
+	restarts, err := checkCanaryUnits(units, 2, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(restarts, check.DeepEquals, map[string]int32{"u2": 0})
+	_, err = checkCanaryUnits(units, 3, nil)
+	c.Assert(err, check.ErrorMatches, "no units found for version 3")
+	_, err = checkCanaryUnits(units, 1, nil)
+	c.Assert(err, check.ErrorMatches, "unit u1 is not ready")
+	units[1].Restarts = &one
+	_, err = checkCanaryUnits(units, 2, restarts)
+	c.Assert(err, check.ErrorMatches, `unit u2 restarted 1 time\(s\) during canary`)
+	units[1].Status = provTypes.UnitStatusError
+	units[1].StatusReason = "CrashLoopBackOff"
+	_, err = checkCanaryUnits(units, 2, nil)
+	c.Assert(err, check.ErrorMatches, "unit u2 is in error state: CrashLoopBackOff")
+}
+
+func (s *S) TestQueryCanaryMetric(c *check.C) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		query = r.Form.Get("query")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"pod":"a"},"value":[1700000000,"0.01"]},
+			{"metric":{"pod":"b"},"value":[1700000000,"0.2"]}
+		]}}`))
+	}))
+	defer srv.Close()
+	config.Set("deploy:canary:prometheus-address", srv.URL)
+	defer config.Unset("deploy:canary:prometheus-address")
+	value, err := queryCanaryMetric(context.TODO(), `max(errors{app="{{.App}}",version="{{.Version}}"})`, "myapp", 3)
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, 0.2)
+	c.Assert(query, check.Equals, `max(errors{app="myapp",version="3"})`)
+}
+
+func (s *S) TestQueryCanaryMetricNoSamples(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer srv.Close()
+	config.Set("deploy:canary:prometheus-address", srv.URL)
+	defer config.Unset("deploy:canary:prometheus-address")
+	_, err := queryCanaryMetric(context.TODO(), "up", "myapp", 3)
+	c.Assert(err, check.ErrorMatches, "canary query returned no samples")
+}
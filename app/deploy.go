@@ -15,7 +15,6 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/builder"
-	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/log"
@@ -144,9 +143,9 @@ func eventToDeployData(evt *event.Event, validImages set.Set, full bool) *Deploy
 	}
 	if full {
 		data.Log = evt.Log()
-		var otherData map[string]string
+		var otherData deployOtherData
 		if err = evt.OtherData(&otherData); err == nil {
-			data.Diff = otherData["diff"]
+			data.Diff = otherData.Diff
 		} else {
 			log.Errorf("cannot decode the event's other custom data value: event %s - %v", evt.UniqueID, err)
 		}
@@ -186,6 +185,12 @@ type DeployOptions struct {
 	Build            bool
 	NewVersion       bool
 	OverrideVersions bool
+	Canary           *CanaryOptions `bson:",omitempty"`
+	// RollbackOf is the ID of the deploy event whose version was
+	// automatically rolled back by this deploy.
+	RollbackOf string `bson:",omitempty"`
+
+	version appTypes.AppVersion
 }
 
 func (o *DeployOptions) GetOrigin() string {
@@ -306,6 +311,9 @@ func (e *errorWithLog) Error() string {
 }
 
 func validateVersions(ctx context.Context, opts DeployOptions) error {
+	if opts.Canary != nil {
+		return validateCanary(ctx, opts)
+	}
 	if opts.NewVersion && opts.OverrideVersions {
 		return errors.New("conflicting deploy flags, new-version and override-old-versions")
 	}
@@ -337,10 +345,20 @@ func Deploy(ctx context.Context, opts DeployOptions) (string, error) {
 	logWriter.Async()
 	defer logWriter.Close()
 	opts.Event.SetLogWriter(io.MultiWriter(&tsuruIo.NoErrorWriter{Writer: opts.OutputStream}, &logWriter))
+	previousVersion, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, opts.App)
+	if err != nil && err != appTypes.ErrNoVersionsAvailable {
+		return "", err
+	}
 	imageID, err := deployToProvisioner(ctx, &opts, opts.Event)
 	if err != nil {
 		return "", newErrorWithLog(ctx, err, opts.App, "deploy")
 	}
+	if opts.Canary != nil {
+		err = runCanary(ctx, opts.App, *opts.Canary, opts.version, opts.Event)
+		if err != nil {
+			return "", newErrorWithLog(ctx, err, opts.App, "canary deploy")
+		}
+	}
 	err = rebuild.RebuildRoutesWithAppName(opts.App.Name, opts.Event)
 	if err != nil {
 		return "", err
@@ -356,6 +374,7 @@ func Deploy(ctx context.Context, opts DeployOptions) (string, error) {
 	} else if opts.App.UpdatePlatform {
 		SetUpdatePlatform(ctx, opts.App, false)
 	}
+	watchAfterDeploy(ctx, &opts, previousVersion)
 	return imageID, nil
 }
 
@@ -409,13 +428,18 @@ func deployToProvisioner(ctx context.Context, opts *DeployOptions, evt *event.Ev
 		return "", errors.Wrap(err, "failed to set event as non-cancelable")
 	}
 
-	return deployer.Deploy(ctx, provision.DeployArgs{
+	opts.version = version
+	phases := builder.NewPhaseTracker(version, evt.UniqueID.Hex())
+	phases.Start(appTypes.BuildPhaseRollout)
+	imageID, err := deployer.Deploy(ctx, provision.DeployArgs{
 		App:              opts.App,
 		Version:          version,
 		Event:            evt,
-		PreserveVersions: opts.NewVersion,
+		PreserveVersions: opts.NewVersion || opts.Canary != nil,
 		OverrideVersions: opts.OverrideVersions,
 	})
+	phases.Finish(err)
+	return imageID, err
 }
 
 func builderDeploy(ctx context.Context, opts *DeployOptions, evt *event.Event) (appTypes.AppVersion, error) {
@@ -456,15 +480,11 @@ func ValidateOrigin(origin string) bool {
 }
 
 func incrementDeploy(ctx context.Context, app *appTypes.App) error {
-	collection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(
-		ctx,
-		mongoBSON.M{"name": app.Name},
-		mongoBSON.M{"$inc": mongoBSON.M{"deploys": 1}},
-	)
+	err = as.IncrementDeploys(ctx, app.Name)
 	if err == nil {
 		app.Deploys += 1
 	}
@@ -0,0 +1,327 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+// DryRunResult describes the changes a mutating operation would make to an
+// app. It's returned by the DryRun variants of those operations, which run
+// the same validations, quota and pool constraint checks without persisting
+// anything.
+type DryRunResult struct {
+	Changes         []DryRunChange `json:"changes"`
+	RestartRequired bool           `json:"restartRequired"`
+}
+
+type DryRunChange struct {
+	Field string      `json:"field"`
+	Key   string      `json:"key,omitempty"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+func (r *DryRunResult) add(field, key string, old, new interface{}) {
+	if isEmptyValue(old) && isEmptyValue(new) || reflect.DeepEqual(old, new) {
+		return
+	}
+	r.Changes = append(r.Changes, DryRunChange{Field: field, Key: key, Old: old, New: new})
+}
+
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// cloneApp copies app deeply enough for the in place changes made by
+// prepareUpdate and the env functions not to leak into the original.
+func cloneApp(app *appTypes.App) *appTypes.App {
+	newApp := *app
+	newApp.Tags = append([]string(nil), app.Tags...)
+	newApp.Metadata = cloneMetadata(app.Metadata)
+	newApp.Processes = nil
+	for _, p := range app.Processes {
+		p.Metadata = cloneMetadata(p.Metadata)
+		newApp.Processes = append(newApp.Processes, p)
+	}
+	if app.Plan.Override != nil {
+		override := *app.Plan.Override
+		newApp.Plan.Override = &override
+	}
+	newApp.Env = make(map[string]bindTypes.EnvVar, len(app.Env))
+	for k, v := range app.Env {
+		newApp.Env[k] = v
+	}
+	return &newApp
+}
+
+func cloneMetadata(m appTypes.Metadata) appTypes.Metadata {
+	return appTypes.Metadata{
+		Labels:      append([]appTypes.MetadataItem(nil), m.Labels...),
+		Annotations: append([]appTypes.MetadataItem(nil), m.Annotations...),
+	}
+}
+
+func platformWithVersion(app *appTypes.App) string {
+	if app.PlatformVersion == "" || app.PlatformVersion == "latest" {
+		return app.Platform
+	}
+	return app.Platform + ":" + app.PlatformVersion
+}
+
+// UpdateDryRun validates args as Update does and returns the changes Update
+// would apply to the app.
+func UpdateDryRun(ctx context.Context, app *appTypes.App, args UpdateAppArgs) (*DryRunResult, error) {
+	newApp := cloneApp(app)
+	u, err := prepareUpdate(ctx, newApp, args)
+	if err != nil {
+		return nil, err
+	}
+	result := &DryRunResult{RestartRequired: u.restart || u.provisionerChanged}
+	result.add("description", "", app.Description, newApp.Description)
+	result.add("pool", "", app.Pool, newApp.Pool)
+	result.add("teamOwner", "", app.TeamOwner, newApp.TeamOwner)
+	result.add("plan", "", app.Plan, newApp.Plan)
+	result.add("platform", "", platformWithVersion(app), platformWithVersion(newApp))
+	result.add("updatePlatform", "", app.UpdatePlatform, newApp.UpdatePlatform)
+	result.add("tags", "", app.Tags, newApp.Tags)
+	result.add("metadata", "", app.Metadata, newApp.Metadata)
+	result.add("processes", "", app.Processes, newApp.Processes)
+	return result, nil
+}
+
+func dryRunEnv(env bindTypes.EnvVar) bindTypes.EnvVar {
+	if !env.Public {
+		env.Value = SuppressedEnv
+	}
+	return env
+}
+
+func restartRequiredForEnvs(ctx context.Context, app *appTypes.App, shouldRestart bool) (bool, error) {
+	if !shouldRestart {
+		return false, nil
+	}
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return false, err
+	}
+	return len(units) > 0, nil
+}
+
+// SetEnvsDryRun validates setEnvs as SetEnvs does and returns the changes
+// SetEnvs would apply to the app. Values of private variables are
+// suppressed.
+func SetEnvsDryRun(ctx context.Context, app *appTypes.App, setEnvs bindTypes.SetEnvArgs) (*DryRunResult, error) {
+	result := &DryRunResult{}
+	if setEnvs.ManagedBy == "" && len(setEnvs.Envs) == 0 {
+		return result, nil
+	}
+	envNames := []string{}
+	for _, env := range setEnvs.Envs {
+		err := validateEnv(env.Name)
+		if err != nil {
+			return nil, err
+		}
+		envNames = append(envNames, env.Name)
+	}
+	err := validateEnvConflicts(app, envNames)
+	if err != nil {
+		return nil, err
+	}
+	if setEnvs.PruneUnused {
+		var pruned []string
+		for name, value := range app.Env {
+			if !envInSet(name, setEnvs.Envs) && value.ManagedBy == setEnvs.ManagedBy {
+				pruned = append(pruned, name)
+			}
+		}
+		sort.Strings(pruned)
+		for _, name := range pruned {
+			result.add("env", name, dryRunEnv(app.Env[name]), nil)
+		}
+	}
+	for _, env := range setEnvs.Envs {
+		// compared before suppressing values, changes to private
+		// variables would be hidden otherwise
+		change := DryRunChange{Field: "env", Key: env.Name, New: dryRunEnv(env)}
+		if current, ok := app.Env[env.Name]; ok {
+			if current == env {
+				continue
+			}
+			change.Old = dryRunEnv(current)
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	result.RestartRequired, err = restartRequiredForEnvs(ctx, app, setEnvs.ShouldRestart)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UnsetEnvsDryRun returns the changes UnsetEnvs would apply to the app.
+func UnsetEnvsDryRun(ctx context.Context, app *appTypes.App, unsetEnvs bindTypes.UnsetEnvArgs) (*DryRunResult, error) {
+	result := &DryRunResult{}
+	if len(unsetEnvs.VariableNames) == 0 {
+		return result, nil
+	}
+	for _, name := range unsetEnvs.VariableNames {
+		if current, ok := app.Env[name]; ok {
+			result.add("env", name, dryRunEnv(current), nil)
+		}
+	}
+	var err error
+	result.RestartRequired, err = restartRequiredForEnvs(ctx, app, unsetEnvs.ShouldRestart)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// countUnits counts the units of the process in the version, an empty process
+// matching any.
+func countUnits(units []provTypes.Unit, process string, version int) int {
+	var count int
+	for _, u := range units {
+		if (process == "" || u.ProcessName == process) && u.Version == version {
+			count++
+		}
+	}
+	return count
+}
+
+// AddUnitsDryRun validates the addition of n units as AddUnits does,
+// including the app quota, and returns the resulting number of units.
+func AddUnitsDryRun(ctx context.Context, app *appTypes.App, n uint, process, versionStr string) (*DryRunResult, error) {
+	if n == 0 {
+		return nil, errors.New("Cannot add zero units.")
+	}
+	err := ensureNoAutoscaler(ctx, app, process)
+	if err != nil {
+		return nil, err
+	}
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range units {
+		if u.Status == provTypes.UnitStatusStopped {
+			return nil, errors.New("Cannot add units to an app that has stopped units")
+		}
+	}
+	version, err := getVersion(ctx, app, versionStr)
+	if err != nil {
+		return nil, err
+	}
+	q, err := servicemanager.AppQuota.Get(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	err = checkQuotaLimit(q, int(n))
+	if err != nil {
+		return nil, err
+	}
+	current := countUnits(units, process, version.Version())
+	result := &DryRunResult{}
+	result.add("units", process, current, current+int(n))
+	result.add("quota", "inUse", q.InUse, q.InUse+int(n))
+	return result, nil
+}
+
+// RemoveUnitsDryRun validates the removal of n units as RemoveUnits does and
+// returns the resulting number of units of the version the units are removed
+// from. Removing as many units as the process has, or more, stops it.
+func RemoveUnitsDryRun(ctx context.Context, app *appTypes.App, n uint, process, versionStr string) (*DryRunResult, error) {
+	if n == 0 {
+		return nil, &tsuruErrors.ValidationError{Message: "cannot remove 0 units"}
+	}
+	err := ensureNoAutoscaler(ctx, app, process)
+	if err != nil {
+		return nil, err
+	}
+	version, err := getVersion(ctx, app, versionStr)
+	if err != nil {
+		return nil, err
+	}
+	units, err := AppUnits(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	current := countUnits(units, process, version.Version())
+	result := &DryRunResult{}
+	result.add("units", process, current, max(current-int(n), 0))
+	return result, nil
+}
+
+// AutoScaleDryRun returns the autoscale change AutoScale would apply to the
+// app, the spec itself is expected to be already validated.
+func AutoScaleDryRun(ctx context.Context, app *appTypes.App, spec provTypes.AutoScaleSpec) (*DryRunResult, error) {
+	current, err := currentAutoScale(ctx, app, spec.Process)
+	if err != nil {
+		return nil, err
+	}
+	result := &DryRunResult{}
+	if current == nil {
+		result.add("autoscale", spec.Process, nil, spec)
+	} else {
+		result.add("autoscale", spec.Process, *current, spec)
+	}
+	return result, nil
+}
+
+// RemoveAutoScaleDryRun returns the autoscale change RemoveAutoScale would
+// apply to the app.
+func RemoveAutoScaleDryRun(ctx context.Context, app *appTypes.App, process string) (*DryRunResult, error) {
+	current, err := currentAutoScale(ctx, app, process)
+	if err != nil {
+		return nil, err
+	}
+	result := &DryRunResult{}
+	if current != nil {
+		result.add("autoscale", process, *current, nil)
+	}
+	return result, nil
+}
+
+func currentAutoScale(ctx context.Context, app *appTypes.App, process string) (*provTypes.AutoScaleSpec, error) {
+	prov, err := getProvisioner(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	autoscaleProv, ok := prov.(provision.AutoScaleProvisioner)
+	if !ok {
+		return nil, errors.Errorf("provisioner %q does not support native autoscaling", prov.GetName())
+	}
+	specs, err := autoscaleProv.GetAutoScale(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	for i := range specs {
+		if specs[i].Process == process {
+			return &specs[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestUpdateDryRun(c *check.C) {
+	s.plan = appTypes.Plan{Name: "something", Memory: 268435456}
+	a := appTypes.App{
+		Name:        "my-test-app",
+		Routers:     []appTypes.AppRouter{{Name: "fake"}},
+		Plan:        appTypes.Plan{Memory: 536870912},
+		TeamOwner:   s.team.Name,
+		Description: "old description",
+		Tags:        []string{"tag1"},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", newSuccessfulAppVersion(c, &a), nil)
+	oldPlan := a.Plan
+	updateData := appTypes.App{Description: "new description", Plan: appTypes.Plan{Name: "something"}, Tags: []string{"tag2"}}
+	result, err := UpdateDryRun(context.TODO(), &a, UpdateAppArgs{UpdateData: &updateData, ShouldRestart: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &DryRunResult{
+		Changes: []DryRunChange{
+			{Field: "description", Old: "old description", New: "new description"},
+			{Field: "plan", Old: oldPlan, New: s.plan},
+			{Field: "tags", Old: []string{"tag1"}, New: []string{"tag2"}},
+		},
+		RestartRequired: true,
+	})
+	c.Assert(a.Description, check.Equals, "old description")
+	c.Assert(a.Tags, check.DeepEquals, []string{"tag1"})
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "old description")
+	c.Assert(dbApp.Plan, check.DeepEquals, oldPlan)
+	c.Assert(s.provisioner.Restarts(dbApp, ""), check.Equals, 0)
+}
+
+func (s *S) TestUpdateDryRunInvalidPlan(c *check.C) {
+	a := appTypes.App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	updateData := appTypes.App{Plan: appTypes.Plan{Name: "unknown"}}
+	_, err = UpdateDryRun(context.TODO(), &a, UpdateAppArgs{UpdateData: &updateData})
+	c.Assert(err, check.Equals, appTypes.ErrPlanNotFound)
+}
+
+func (s *S) TestSetEnvsDryRun(c *check.C) {
+	a := appTypes.App{
+		Name:      "my-test-app",
+		TeamOwner: s.team.Name,
+		Env: map[string]bindTypes.EnvVar{
+			"OLD":    {Name: "OLD", Value: "1", Public: true},
+			"SECRET": {Name: "SECRET", Value: "s3cr3t"},
+		},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	result, err := SetEnvsDryRun(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{
+			{Name: "OLD", Value: "1", Public: true},
+			{Name: "SECRET", Value: "other"},
+			{Name: "NEW", Value: "2", Public: true},
+		},
+		ShouldRestart: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &DryRunResult{
+		Changes: []DryRunChange{
+			{Field: "env", Key: "SECRET", Old: bindTypes.EnvVar{Name: "SECRET", Value: SuppressedEnv}, New: bindTypes.EnvVar{Name: "SECRET", Value: SuppressedEnv}},
+			{Field: "env", Key: "NEW", New: bindTypes.EnvVar{Name: "NEW", Value: "2", Public: true}},
+		},
+	})
+	c.Assert(a.Env, check.HasLen, 2)
+	_, err = SetEnvsDryRun(context.TODO(), &a, bindTypes.SetEnvArgs{
+		Envs: []bindTypes.EnvVar{{Name: "INVALID-NAME", Value: "1"}},
+	})
+	c.Assert(err, check.ErrorMatches, "Invalid environment variable name: 'INVALID-NAME'")
+}
+
+func (s *S) TestUnsetEnvsDryRun(c *check.C) {
+	a := appTypes.App{
+		Name:      "my-test-app",
+		TeamOwner: s.team.Name,
+		Env: map[string]bindTypes.EnvVar{
+			"OLD": {Name: "OLD", Value: "1", Public: true},
+		},
+	}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	result, err := UnsetEnvsDryRun(context.TODO(), &a, bindTypes.UnsetEnvArgs{VariableNames: []string{"OLD", "MISSING"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &DryRunResult{
+		Changes: []DryRunChange{
+			{Field: "env", Key: "OLD", Old: bindTypes.EnvVar{Name: "OLD", Value: "1", Public: true}},
+		},
+	})
+	c.Assert(a.Env, check.HasLen, 1)
+}
+
+func (s *S) TestAddUnitsDryRun(c *check.C) {
+	a := appTypes.App{Name: "warpaint", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 2, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	s.mockService.AppQuota.OnGet = func(_ *appTypes.App) (*quota.Quota, error) {
+		return &quota.Quota{Limit: 5, InUse: 2}, nil
+	}
+	result, err := AddUnitsDryRun(context.TODO(), &a, 3, "web", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &DryRunResult{
+		Changes: []DryRunChange{
+			{Field: "units", Key: "web", Old: 2, New: 5},
+			{Field: "quota", Key: "inUse", Old: 2, New: 5},
+		},
+	})
+	c.Assert(s.provisioner.GetUnits(&a), check.HasLen, 2)
+	_, err = AddUnitsDryRun(context.TODO(), &a, 4, "web", "")
+	c.Assert(err, check.FitsTypeOf, &quota.QuotaExceededError{})
+}
+
+func (s *S) TestRemoveUnitsDryRun(c *check.C) {
+	a := appTypes.App{Name: "warpaint", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 2, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	result, err := RemoveUnitsDryRun(context.TODO(), &a, 2, "web", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &DryRunResult{
+		Changes: []DryRunChange{
+			{Field: "units", Key: "web", Old: 2, New: 0},
+		},
+	})
+	c.Assert(s.provisioner.GetUnits(&a), check.HasLen, 2)
+	result, err = RemoveUnitsDryRun(context.TODO(), &a, 3, "web", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Changes, check.DeepEquals, []DryRunChange{{Field: "units", Key: "web", Old: 2, New: 0}})
+	_, err = RemoveUnitsDryRun(context.TODO(), &a, 0, "web", "")
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, "cannot remove 0 units")
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/streamfmt"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+)
+
+type ApplyOptions struct {
+	Event     *event.Event
+	RequestID string
+	NoRestart bool
+}
+
+// Apply runs changes, as returned by Diff, against the app. Changes to the
+// app attributes and to the environment are applied in a single operation
+// each, and the app is restarted at most once at the end when some of the
+// changes require it.
+func Apply(ctx context.Context, a *appTypes.App, changes []Change, opts ApplyOptions) error {
+	var w io.Writer = opts.Event
+	var updateData appTypes.App
+	var updateApp, restart bool
+	var setEnvs []bindTypes.EnvVar
+	var unsetEnvs []string
+	var others []Change
+	for _, c := range changes {
+		switch c.Section {
+		case SectionDescription:
+			updateData.Description = c.New.(string)
+		case SectionPlatform:
+			updateData.Platform = c.New.(string)
+		case SectionTeamOwner:
+			updateData.TeamOwner = c.New.(string)
+		case SectionPlan:
+			updateData.Plan.Name = c.New.(string)
+			restart = true
+		case SectionTags:
+			updateData.Tags = c.New.([]string)
+			if len(updateData.Tags) == 0 {
+				// app.Update ignores empty tag lists, a single blank tag
+				// is dropped while still clearing the existing ones
+				updateData.Tags = []string{""}
+			}
+		case SectionMetadata:
+			kind, name, _ := strings.Cut(c.Key, ":")
+			item := appTypes.MetadataItem{Name: name, Delete: c.Action == ActionRemove}
+			if value, ok := c.New.(string); ok {
+				item.Value = value
+			}
+			if kind == "label" {
+				updateData.Metadata.Labels = append(updateData.Metadata.Labels, item)
+			} else {
+				updateData.Metadata.Annotations = append(updateData.Metadata.Annotations, item)
+			}
+			restart = true
+		case SectionEnv:
+			if c.Action == ActionRemove {
+				unsetEnvs = append(unsetEnvs, c.Key)
+			} else {
+				env := c.New.(EnvVar)
+				setEnvs = append(setEnvs, bindTypes.EnvVar{Name: env.Name, Value: env.Value, Public: !env.Private})
+			}
+			restart = true
+			continue
+		default:
+			others = append(others, c)
+			continue
+		}
+		updateApp = true
+	}
+	if updateApp {
+		streamfmt.FprintlnSectionf(w, "Updating app attributes")
+		err := app.Update(ctx, a, app.UpdateAppArgs{UpdateData: &updateData, Writer: w})
+		if err != nil {
+			return err
+		}
+	}
+	if len(setEnvs) > 0 {
+		err := app.SetEnvs(ctx, a, bindTypes.SetEnvArgs{Envs: setEnvs, Writer: w})
+		if err != nil {
+			return err
+		}
+	}
+	if len(unsetEnvs) > 0 {
+		err := app.UnsetEnvs(ctx, a, bindTypes.UnsetEnvArgs{VariableNames: unsetEnvs, Writer: w})
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range others {
+		changed, err := applyChange(ctx, a, c, opts)
+		if err != nil {
+			return fmt.Errorf("unable to %s %s %q: %w", c.Action, c.Section, c.Key, err)
+		}
+		restart = restart || changed
+	}
+	if restart && !opts.NoRestart {
+		return app.Restart(ctx, a, "", "", w)
+	}
+	return nil
+}
+
+// applyChange applies a single change, it returns whether the app must be
+// restarted to pick it up.
+func applyChange(ctx context.Context, a *appTypes.App, c Change, opts ApplyOptions) (bool, error) {
+	w := opts.Event
+	streamfmt.FprintlnActionf(w, "%s %s %s", c.Action, c.Section, c.Key)
+	switch c.Section {
+	case SectionRouter:
+		switch c.Action {
+		case ActionAdd:
+			r := c.New.(Router)
+			return false, app.AddRouter(ctx, a, appTypes.AppRouter{Name: r.Name, Opts: r.Opts})
+		case ActionUpdate:
+			r := c.New.(Router)
+			return false, app.UpdateRouter(ctx, a, appTypes.AppRouter{Name: r.Name, Opts: r.Opts})
+		case ActionRemove:
+			return false, app.RemoveRouter(ctx, a, c.Key)
+		}
+	case SectionCName:
+		if c.Action == ActionAdd {
+			return false, app.AddCName(ctx, a, c.Key)
+		}
+		return false, app.RemoveCName(ctx, a, c.Key)
+	case SectionCertIssuer:
+		if c.Action == ActionRemove {
+			return false, app.UnsetCertIssuer(ctx, a, c.Key)
+		}
+		return false, app.SetCertIssuer(ctx, a, c.Key, c.New.(string))
+	case SectionAutoscale:
+		if c.Action == ActionRemove {
+			return false, app.RemoveAutoScale(ctx, a, c.Key)
+		}
+		return false, app.AutoScale(ctx, a, c.New.(provTypes.AutoScaleSpec))
+	case SectionVolume:
+		if c.Action != ActionAdd {
+			err := bindVolume(ctx, a, c.Old.(VolumeBind), false)
+			if err != nil {
+				return false, err
+			}
+		}
+		if c.Action != ActionRemove {
+			err := bindVolume(ctx, a, c.New.(VolumeBind), true)
+			if err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	case SectionService:
+		if c.Action == ActionAdd {
+			b := c.New.(ServiceBind)
+			instance, err := service.GetServiceInstance(ctx, b.Service, b.Instance)
+			if err != nil {
+				return false, err
+			}
+			err = app.ValidateService(ctx, a, b.Service)
+			if err != nil {
+				return false, err
+			}
+			return true, instance.BindApp(ctx, a, nil, false, w, w, opts.RequestID)
+		}
+		b := c.Old.(ServiceBind)
+		instance, err := service.GetServiceInstance(ctx, b.Service, b.Instance)
+		if err != nil {
+			return false, err
+		}
+		return true, instance.UnbindApp(ctx, service.UnbindAppArgs{
+			App:       a,
+			Event:     w,
+			RequestID: opts.RequestID,
+		})
+	}
+	return false, fmt.Errorf("unknown manifest section %q", c.Section)
+}
+
+func bindVolume(ctx context.Context, a *appTypes.App, b VolumeBind, bind bool) error {
+	v, err := servicemanager.Volume.Get(ctx, b.Volume)
+	if err != nil {
+		return err
+	}
+	bindOpts := &volumeTypes.BindOpts{
+		Volume:     v,
+		AppName:    a.Name,
+		MountPoint: b.MountPoint,
+		ReadOnly:   b.ReadOnly,
+	}
+	if bind {
+		return servicemanager.Volume.BindApp(ctx, bindOpts)
+	}
+	return servicemanager.Volume.UnbindApp(ctx, bindOpts)
+}
@@ -0,0 +1,326 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/tsuru/tsuru/app"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+const (
+	SectionDescription = "description"
+	SectionPlatform    = "platform"
+	SectionTeamOwner   = "teamOwner"
+	SectionPlan        = "plan"
+	SectionTags        = "tags"
+	SectionMetadata    = "metadata"
+	SectionEnv         = "env"
+	SectionRouter      = "router"
+	SectionCName       = "cname"
+	SectionCertIssuer  = "certIssuer"
+	SectionAutoscale   = "autoscale"
+	SectionVolume      = "volume"
+	SectionService     = "service"
+)
+
+type Action string
+
+const (
+	ActionAdd    = Action("add")
+	ActionUpdate = Action("update")
+	ActionRemove = Action("remove")
+)
+
+// Change is a single difference between the current and the desired
+// manifests. Changes are returned by Diff in the order they must be applied.
+type Change struct {
+	Section string      `json:"section"`
+	Action  Action      `json:"action"`
+	Key     string      `json:"key,omitempty"`
+	Old     interface{} `json:"old,omitempty"`
+	New     interface{} `json:"new,omitempty"`
+}
+
+// Diff computes the changes required to turn current into desired. Sections
+// omitted from desired, i.e. nil slices and maps, are left untouched, while
+// empty ones remove every existing entry.
+func Diff(current, desired *Manifest) ([]Change, error) {
+	if desired.Version != Version {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("unsupported manifest version %q, expected %q", desired.Version, Version)}
+	}
+	if desired.Name != "" && desired.Name != current.Name {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("manifest name %q doesn't match the app %q", desired.Name, current.Name)}
+	}
+	if desired.Pool != "" && desired.Pool != current.Pool {
+		return nil, &tsuruErrors.ValidationError{Message: "pool updates are no longer supported, create a new app in the desired pool instead"}
+	}
+	var changes []Change
+	addField := func(section, old, new string) {
+		if new != "" && new != old {
+			changes = append(changes, Change{Section: section, Action: ActionUpdate, Old: old, New: new})
+		}
+	}
+	addField(SectionDescription, current.Description, desired.Description)
+	addField(SectionPlatform, current.Platform, desired.Platform)
+	addField(SectionTeamOwner, current.TeamOwner, desired.TeamOwner)
+	addField(SectionPlan, current.Plan, desired.Plan)
+	if desired.Tags != nil && !sameSet(current.Tags, desired.Tags) {
+		changes = append(changes, Change{Section: SectionTags, Action: ActionUpdate, Old: current.Tags, New: desired.Tags})
+	}
+	changes = append(changes, diffMetadata("label", current.Metadata.Labels, desired.Metadata.Labels)...)
+	changes = append(changes, diffMetadata("annotation", current.Metadata.Annotations, desired.Metadata.Annotations)...)
+	envChanges, err := diffEnv(current.Env, desired.Env)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, envChanges...)
+	if desired.Routers != nil {
+		currentRouters := map[string]Router{}
+		for _, r := range current.Routers {
+			currentRouters[r.Name] = r
+		}
+		desiredRouters := map[string]Router{}
+		for _, r := range desired.Routers {
+			desiredRouters[r.Name] = r
+			old, ok := currentRouters[r.Name]
+			if !ok {
+				changes = append(changes, Change{Section: SectionRouter, Action: ActionAdd, Key: r.Name, New: r})
+			} else if !sameOpts(old.Opts, r.Opts) {
+				changes = append(changes, Change{Section: SectionRouter, Action: ActionUpdate, Key: r.Name, Old: old, New: r})
+			}
+		}
+		for _, r := range current.Routers {
+			if _, ok := desiredRouters[r.Name]; !ok {
+				changes = append(changes, Change{Section: SectionRouter, Action: ActionRemove, Key: r.Name, Old: r})
+			}
+		}
+	}
+	var removedCNames []Change
+	if desired.CNames != nil {
+		for _, cname := range desired.CNames {
+			if !contains(current.CNames, cname) {
+				changes = append(changes, Change{Section: SectionCName, Action: ActionAdd, Key: cname})
+			}
+		}
+		for _, cname := range current.CNames {
+			if !contains(desired.CNames, cname) {
+				removedCNames = append(removedCNames, Change{Section: SectionCName, Action: ActionRemove, Key: cname})
+			}
+		}
+	}
+	if desired.CertIssuers != nil {
+		for _, cname := range sortedKeys(desired.CertIssuers) {
+			issuer := desired.CertIssuers[cname]
+			old, ok := current.CertIssuers[cname]
+			if !ok {
+				changes = append(changes, Change{Section: SectionCertIssuer, Action: ActionAdd, Key: cname, New: issuer})
+			} else if old != issuer {
+				changes = append(changes, Change{Section: SectionCertIssuer, Action: ActionUpdate, Key: cname, Old: old, New: issuer})
+			}
+		}
+		remainingCNames := current.CNames
+		if desired.CNames != nil {
+			remainingCNames = desired.CNames
+		}
+		for _, cname := range sortedKeys(current.CertIssuers) {
+			// cert issuers of removed cnames are dropped along with them
+			if _, ok := desired.CertIssuers[cname]; !ok && contains(remainingCNames, cname) {
+				changes = append(changes, Change{Section: SectionCertIssuer, Action: ActionRemove, Key: cname, Old: current.CertIssuers[cname]})
+			}
+		}
+	}
+	changes = append(changes, removedCNames...)
+	if desired.Autoscale != nil {
+		currentSpecs := map[string]provTypes.AutoScaleSpec{}
+		for _, spec := range current.Autoscale {
+			currentSpecs[spec.Process] = spec
+		}
+		desiredSpecs := map[string]struct{}{}
+		for _, spec := range desired.Autoscale {
+			desiredSpecs[spec.Process] = struct{}{}
+			old, ok := currentSpecs[spec.Process]
+			if !ok {
+				changes = append(changes, Change{Section: SectionAutoscale, Action: ActionAdd, Key: spec.Process, New: spec})
+				continue
+			}
+			cmp := spec
+			cmp.Version = old.Version
+			if !reflect.DeepEqual(old, cmp) {
+				changes = append(changes, Change{Section: SectionAutoscale, Action: ActionUpdate, Key: spec.Process, Old: old, New: spec})
+			}
+		}
+		for _, spec := range current.Autoscale {
+			if _, ok := desiredSpecs[spec.Process]; !ok {
+				changes = append(changes, Change{Section: SectionAutoscale, Action: ActionRemove, Key: spec.Process, Old: spec})
+			}
+		}
+	}
+	if desired.Volumes != nil {
+		key := func(b VolumeBind) string { return b.Volume + ":" + b.MountPoint }
+		currentBinds := map[string]VolumeBind{}
+		for _, b := range current.Volumes {
+			currentBinds[key(b)] = b
+		}
+		desiredBinds := map[string]struct{}{}
+		for _, b := range desired.Volumes {
+			desiredBinds[key(b)] = struct{}{}
+			old, ok := currentBinds[key(b)]
+			if !ok {
+				changes = append(changes, Change{Section: SectionVolume, Action: ActionAdd, Key: key(b), New: b})
+			} else if old != b {
+				changes = append(changes, Change{Section: SectionVolume, Action: ActionUpdate, Key: key(b), Old: old, New: b})
+			}
+		}
+		for _, b := range current.Volumes {
+			if _, ok := desiredBinds[key(b)]; !ok {
+				changes = append(changes, Change{Section: SectionVolume, Action: ActionRemove, Key: key(b), Old: b})
+			}
+		}
+	}
+	if desired.Services != nil {
+		key := func(b ServiceBind) string { return b.Service + "/" + b.Instance }
+		for _, b := range desired.Services {
+			if !containsService(current.Services, b) {
+				changes = append(changes, Change{Section: SectionService, Action: ActionAdd, Key: key(b), New: b})
+			}
+		}
+		for _, b := range current.Services {
+			if !containsService(desired.Services, b) {
+				changes = append(changes, Change{Section: SectionService, Action: ActionRemove, Key: key(b), Old: b})
+			}
+		}
+	}
+	return changes, nil
+}
+
+func diffMetadata(kind string, current, desired []appTypes.MetadataItem) []Change {
+	if desired == nil {
+		return nil
+	}
+	var changes []Change
+	for _, item := range desired {
+		old, ok := findItem(current, item.Name)
+		if !ok {
+			changes = append(changes, Change{Section: SectionMetadata, Action: ActionAdd, Key: kind + ":" + item.Name, New: item.Value})
+		} else if old.Value != item.Value {
+			changes = append(changes, Change{Section: SectionMetadata, Action: ActionUpdate, Key: kind + ":" + item.Name, Old: old.Value, New: item.Value})
+		}
+	}
+	for _, item := range current {
+		if _, ok := findItem(desired, item.Name); !ok {
+			changes = append(changes, Change{Section: SectionMetadata, Action: ActionRemove, Key: kind + ":" + item.Name, Old: item.Value})
+		}
+	}
+	return changes
+}
+
+func diffEnv(current, desired []EnvVar) ([]Change, error) {
+	if desired == nil {
+		return nil, nil
+	}
+	currentEnvs := map[string]EnvVar{}
+	for _, env := range current {
+		currentEnvs[env.Name] = env
+	}
+	var changes []Change
+	desiredEnvs := map[string]struct{}{}
+	for _, env := range desired {
+		desiredEnvs[env.Name] = struct{}{}
+		old, ok := currentEnvs[env.Name]
+		if env.Value == app.SuppressedEnv {
+			if !ok || !old.Private || !env.Private {
+				return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("the value of the environment variable %q must be set explicitly", env.Name)}
+			}
+			continue
+		}
+		if !ok {
+			changes = append(changes, Change{Section: SectionEnv, Action: ActionAdd, Key: env.Name, New: env})
+		} else if old.Private || old.Private != env.Private || old.Value != env.Value {
+			changes = append(changes, Change{Section: SectionEnv, Action: ActionUpdate, Key: env.Name, Old: old, New: env})
+		}
+	}
+	for _, env := range current {
+		if _, ok := desiredEnvs[env.Name]; !ok {
+			changes = append(changes, Change{Section: SectionEnv, Action: ActionRemove, Key: env.Name, Old: env})
+		}
+	}
+	return changes, nil
+}
+
+// Redacted returns a copy of changes with the values of private environment
+// variables replaced by app.SuppressedEnv, suitable for responses and event
+// data.
+func Redacted(changes []Change) []Change {
+	result := make([]Change, len(changes))
+	for i, c := range changes {
+		if env, ok := c.New.(EnvVar); ok && env.Private {
+			env.Value = app.SuppressedEnv
+			c.New = env
+		}
+		result[i] = c
+	}
+	return result
+}
+
+func findItem(items []appTypes.MetadataItem, name string) (appTypes.MetadataItem, bool) {
+	for _, item := range items {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return appTypes.MetadataItem{}, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsService(binds []ServiceBind, bind ServiceBind) bool {
+	for _, b := range binds {
+		if b == bind {
+			return true
+		}
+	}
+	return false
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, v := range a {
+		if !contains(b, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameOpts(a, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"github.com/tsuru/tsuru/app"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func currentManifest() *Manifest {
+	return &Manifest{
+		Version:     Version,
+		Name:        "myapp",
+		Description: "my app",
+		Platform:    "python",
+		Pool:        "pool1",
+		TeamOwner:   "team1",
+		Plan:        "small",
+		Tags:        []string{"a", "b"},
+		Metadata: appTypes.Metadata{
+			Labels: []appTypes.MetadataItem{{Name: "l1", Value: "v1"}},
+		},
+		Env: []EnvVar{
+			{Name: "PUBLIC", Value: "1"},
+			{Name: "SECRET", Value: app.SuppressedEnv, Private: true},
+		},
+		Routers:     []Router{{Name: "r1"}},
+		CNames:      []string{"a.example.com", "b.example.com"},
+		CertIssuers: map[string]string{"a.example.com": "issuer1"},
+		Autoscale:   []provTypes.AutoScaleSpec{{Process: "web", MinUnits: 1, MaxUnits: 2, Version: 3}},
+		Volumes:     []VolumeBind{{Volume: "v1", MountPoint: "/data"}},
+		Services:    []ServiceBind{{Service: "mysql", Instance: "db"}},
+	}
+}
+
+func (s *S) TestDiffNoChanges(c *check.C) {
+	desired := currentManifest()
+	desired.Autoscale[0].Version = 0
+	changes, err := Diff(currentManifest(), desired)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 0)
+}
+
+func (s *S) TestDiffOmittedSections(c *check.C) {
+	changes, err := Diff(currentManifest(), &Manifest{Version: Version})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 0)
+}
+
+func (s *S) TestDiff(c *check.C) {
+	desired := currentManifest()
+	desired.Description = "new description"
+	desired.Tags = []string{"b", "c"}
+	desired.Metadata.Labels = nil
+	desired.Metadata.Annotations = []appTypes.MetadataItem{{Name: "an1", Value: "x"}}
+	desired.Env = []EnvVar{
+		{Name: "SECRET", Value: app.SuppressedEnv, Private: true},
+		{Name: "NEW", Value: "2"},
+	}
+	desired.Routers = []Router{{Name: "r1", Opts: map[string]string{"a": "b"}}, {Name: "r2"}}
+	desired.CNames = []string{"b.example.com", "c.example.com"}
+	desired.CertIssuers = map[string]string{"c.example.com": "issuer2"}
+	desired.Autoscale = []provTypes.AutoScaleSpec{}
+	desired.Volumes = []VolumeBind{{Volume: "v1", MountPoint: "/data", ReadOnly: true}}
+	desired.Services = []ServiceBind{{Service: "redis", Instance: "cache"}}
+	changes, err := Diff(currentManifest(), desired)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []Change{
+		{Section: SectionDescription, Action: ActionUpdate, Old: "my app", New: "new description"},
+		{Section: SectionTags, Action: ActionUpdate, Old: []string{"a", "b"}, New: []string{"b", "c"}},
+		{Section: SectionMetadata, Action: ActionAdd, Key: "annotation:an1", New: "x"},
+		{Section: SectionEnv, Action: ActionAdd, Key: "NEW", New: EnvVar{Name: "NEW", Value: "2"}},
+		{Section: SectionEnv, Action: ActionRemove, Key: "PUBLIC", Old: EnvVar{Name: "PUBLIC", Value: "1"}},
+		{Section: SectionRouter, Action: ActionUpdate, Key: "r1", Old: Router{Name: "r1"}, New: Router{Name: "r1", Opts: map[string]string{"a": "b"}}},
+		{Section: SectionRouter, Action: ActionAdd, Key: "r2", New: Router{Name: "r2"}},
+		{Section: SectionCName, Action: ActionAdd, Key: "c.example.com"},
+		{Section: SectionCertIssuer, Action: ActionAdd, Key: "c.example.com", New: "issuer2"},
+		{Section: SectionCName, Action: ActionRemove, Key: "a.example.com"},
+		{Section: SectionAutoscale, Action: ActionRemove, Key: "web", Old: provTypes.AutoScaleSpec{Process: "web", MinUnits: 1, MaxUnits: 2, Version: 3}},
+		{Section: SectionVolume, Action: ActionUpdate, Key: "v1:/data", Old: VolumeBind{Volume: "v1", MountPoint: "/data"}, New: VolumeBind{Volume: "v1", MountPoint: "/data", ReadOnly: true}},
+		{Section: SectionService, Action: ActionAdd, Key: "redis/cache", New: ServiceBind{Service: "redis", Instance: "cache"}},
+		{Section: SectionService, Action: ActionRemove, Key: "mysql/db", Old: ServiceBind{Service: "mysql", Instance: "db"}},
+	})
+}
+
+func (s *S) TestDiffRemovesCertIssuerOfRemainingCName(c *check.C) {
+	desired := &Manifest{Version: Version, CertIssuers: map[string]string{}}
+	changes, err := Diff(currentManifest(), desired)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []Change{
+		{Section: SectionCertIssuer, Action: ActionRemove, Key: "a.example.com", Old: "issuer1"},
+	})
+}
+
+func (s *S) TestDiffPrivateEnv(c *check.C) {
+	desired := &Manifest{Version: Version, Env: []EnvVar{
+		{Name: "PUBLIC", Value: "1"},
+		{Name: "SECRET", Value: "new-secret", Private: true},
+	}}
+	changes, err := Diff(currentManifest(), desired)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []Change{
+		{
+			Section: SectionEnv,
+			Action:  ActionUpdate,
+			Key:     "SECRET",
+			Old:     EnvVar{Name: "SECRET", Value: app.SuppressedEnv, Private: true},
+			New:     EnvVar{Name: "SECRET", Value: "new-secret", Private: true},
+		},
+	})
+	c.Assert(Redacted(changes)[0].New, check.DeepEquals, EnvVar{Name: "SECRET", Value: app.SuppressedEnv, Private: true})
+	c.Assert(changes[0].New, check.DeepEquals, EnvVar{Name: "SECRET", Value: "new-secret", Private: true})
+	desired.Env[0] = EnvVar{Name: "PUBLIC", Value: app.SuppressedEnv, Private: true}
+	_, err = Diff(currentManifest(), desired)
+	c.Assert(err, check.ErrorMatches, `the value of the environment variable "PUBLIC" must be set explicitly`)
+}
+
+func (s *S) TestDiffInvalid(c *check.C) {
+	_, err := Diff(currentManifest(), &Manifest{Version: "v2"})
+	c.Assert(err, check.ErrorMatches, `unsupported manifest version "v2", expected "v1"`)
+	_, err = Diff(currentManifest(), &Manifest{Version: Version, Name: "other"})
+	c.Assert(err, check.ErrorMatches, `manifest name "other" doesn't match the app "myapp"`)
+	_, err = Diff(currentManifest(), &Manifest{Version: Version, Pool: "pool2"})
+	c.Assert(err, check.ErrorMatches, "pool updates are no longer supported, create a new app in the desired pool instead")
+}
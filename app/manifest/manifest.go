@@ -0,0 +1,120 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package manifest provides a declarative, versioned representation of the
+// state of an app, along with the functions used to export it, to compare it
+// with a desired state and to apply the differences.
+package manifest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tsuru/tsuru/app"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+// Version is the only manifest format version currently supported.
+const Version = "v1"
+
+type Manifest struct {
+	Version     string                    `json:"version"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Platform    string                    `json:"platform,omitempty"`
+	Pool        string                    `json:"pool"`
+	TeamOwner   string                    `json:"teamOwner"`
+	Plan        string                    `json:"plan"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Metadata    appTypes.Metadata         `json:"metadata"`
+	Env         []EnvVar                  `json:"env,omitempty"`
+	Routers     []Router                  `json:"routers,omitempty"`
+	CNames      []string                  `json:"cnames,omitempty"`
+	CertIssuers map[string]string         `json:"certIssuers,omitempty"`
+	Autoscale   []provTypes.AutoScaleSpec `json:"autoscale,omitempty"`
+	Volumes     []VolumeBind              `json:"volumes,omitempty"`
+	Services    []ServiceBind             `json:"services,omitempty"`
+}
+
+// EnvVar is an environment variable set by the app owners. Values of private
+// variables are exported as app.SuppressedEnv, and the same placeholder in a
+// desired manifest keeps the current value untouched.
+type EnvVar struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Private bool   `json:"private,omitempty"`
+}
+
+type Router struct {
+	Name string            `json:"name"`
+	Opts map[string]string `json:"opts,omitempty"`
+}
+
+type VolumeBind struct {
+	Volume     string `json:"volume"`
+	MountPoint string `json:"mountPoint"`
+	ReadOnly   bool   `json:"readOnly,omitempty"`
+}
+
+type ServiceBind struct {
+	Service  string `json:"service"`
+	Instance string `json:"instance"`
+}
+
+// Export builds the manifest describing the current state of the app.
+func Export(ctx context.Context, a *appTypes.App) (*Manifest, error) {
+	info, err := app.AppInfo(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		Version:     Version,
+		Name:        info.Name,
+		Description: info.Description,
+		Platform:    info.Platform,
+		Pool:        info.Pool,
+		TeamOwner:   info.TeamOwner,
+		Tags:        info.Tags,
+		Metadata:    info.Metadata,
+		CNames:      info.CName,
+		Autoscale:   info.Autoscale,
+	}
+	if info.Plan != nil {
+		m.Plan = info.Plan.Name
+	}
+	for _, env := range a.Env {
+		if env.ManagedBy != "" {
+			continue
+		}
+		value := env.Value
+		if !env.Public {
+			value = app.SuppressedEnv
+		}
+		m.Env = append(m.Env, EnvVar{Name: env.Name, Value: value, Private: !env.Public})
+	}
+	sort.Slice(m.Env, func(i, j int) bool {
+		return m.Env[i].Name < m.Env[j].Name
+	})
+	for _, r := range info.Routers {
+		m.Routers = append(m.Routers, Router{Name: r.Name, Opts: r.Opts})
+	}
+	if len(a.CertIssuers) > 0 {
+		m.CertIssuers = map[string]string{}
+		for cname, issuer := range a.CertIssuers {
+			m.CertIssuers[cname] = issuer
+		}
+	}
+	for _, b := range info.VolumeBinds {
+		m.Volumes = append(m.Volumes, VolumeBind{
+			Volume:     b.ID.Volume,
+			MountPoint: b.ID.MountPoint,
+			ReadOnly:   b.ReadOnly,
+		})
+	}
+	for _, b := range info.ServiceInstanceBinds {
+		m.Services = append(m.Services, ServiceBind{Service: b.Service, Instance: b.Instance})
+	}
+	return m, nil
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/builder"
-	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/registry"
@@ -18,7 +17,6 @@ import (
 	"github.com/tsuru/tsuru/storage"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	"github.com/tsuru/tsuru/validation"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
 )
 
 var _ appTypes.PlatformService = &platformService{}
@@ -145,25 +143,10 @@ func (s *platformService) Update(ctx context.Context, opts appTypes.PlatformOpti
 			return multiErr.ToError()
 		}
 
-		var apps []*appTypes.App
-
-		appsCollection, err := storagev2.AppsCollection()
-		if err != nil {
-			return err
-		}
-
-		cursor, err := appsCollection.Find(ctx, mongoBSON.M{"framework": opts.Name})
-		if err != nil {
-			return err
-		}
-		err = cursor.All(ctx, &apps)
+		err = setUpdatePlatformForApps(ctx, opts.Name)
 		if err != nil {
 			return err
 		}
-
-		for _, app := range apps {
-			SetUpdatePlatform(ctx, app, true)
-		}
 	}
 
 	if disabledStr := opts.Args["disabled"]; disabledStr != "" {
@@ -179,15 +162,15 @@ func (s *platformService) Remove(ctx context.Context, name string) error {
 	if name == "" {
 		return appTypes.ErrPlatformNameMissing
 	}
-	appsCollection, err := storagev2.AppsCollection()
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-	apps, err := appsCollection.CountDocuments(ctx, mongoBSON.M{"framework": name})
+	apps, err := as.List(ctx, &appTypes.Filter{Platform: name})
 	if err != nil {
 		return err
 	}
-	if apps > 0 {
+	if len(apps) > 0 {
 		return appTypes.ErrDeletePlatformWithApps
 	}
 	images, err := servicemanager.PlatformImage.ListImagesOrDefault(ctx, name)
@@ -237,22 +220,22 @@ func (s *platformService) Rollback(ctx context.Context, opts appTypes.PlatformOp
 	if multiErr.Len() > 0 {
 		return multiErr.ToError()
 	}
-	appsCollection, err := storagev2.AppsCollection()
-	if err != nil {
-		return err
-	}
-	var apps []*appTypes.App
-	cursor, err := appsCollection.Find(ctx, mongoBSON.M{"framework": opts.Name})
+	return setUpdatePlatformForApps(ctx, opts.Name)
+}
+
+// setUpdatePlatformForApps flags the apps using the platform to be rebuilt
+// on their next deploy.
+func setUpdatePlatformForApps(ctx context.Context, platform string) error {
+	as, err := appStorage()
 	if err != nil {
 		return err
 	}
-
-	err = cursor.All(ctx, &apps)
+	apps, err := as.List(ctx, &appTypes.Filter{Platform: platform})
 	if err != nil {
 		return err
 	}
 	for _, app := range apps {
-		SetUpdatePlatform(ctx, app, true)
+		as.SetUpdatePlatform(ctx, app.Name, true)
 	}
 	return nil
 }
@@ -35,14 +35,14 @@ func (s *appQuotaService) Inc(ctx context.Context, app *appTypes.App, quantity i
 	if err != nil {
 		return err
 	}
-	err = s.checkLimit(quota, quantity)
+	err = checkQuotaLimit(quota, quantity)
 	if err != nil {
 		return err
 	}
 	return s.Storage.Set(ctx, app.Name, quota.InUse+quantity)
 }
 
-func (s *appQuotaService) checkLimit(q *quotaTypes.Quota, quantity int) error {
+func checkQuotaLimit(q *quotaTypes.Quota, quantity int) error {
 	if !q.IsUnlimited() && q.InUse+quantity > q.Limit {
 		return &quotaTypes.QuotaExceededError{
 			Available: uint(q.Limit - q.InUse),
@@ -168,6 +168,27 @@ func (v *appVersionImpl) UpdatePastUnits(process string, replicas int) error {
 	return v.storage.UpdateVersion(v.ctx, v.app.Name, v.versionInfo)
 }
 
+func (v *appVersionImpl) SetBuildPhase(phase appTypes.BuildPhase) error {
+	err := v.refresh()
+	if err != nil {
+		return err
+	}
+	phases := v.versionInfo.BuildPhases
+	replaced := false
+	for i := len(phases) - 1; i >= 0; i-- {
+		if phases[i].Name == phase.Name && phases[i].Status == appTypes.BuildPhaseRunning {
+			phases[i] = phase
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		phases = append(phases, phase)
+	}
+	v.versionInfo.BuildPhases = phases
+	return v.storage.UpdateBuildPhases(v.ctx, v.app.Name, v.versionInfo.Version, phases)
+}
+
 func (v *appVersionImpl) ToggleEnabled(enabled bool, reason string) error {
 	err := v.refresh()
 	if err != nil {
@@ -6,6 +6,7 @@ package version
 
 import (
 	"context"
+	"time"
 
 	"github.com/tsuru/config"
 	appTypes "github.com/tsuru/tsuru/types/app"
@@ -343,3 +344,36 @@ func (s *S) TestAppVersionImpl_ToggleEnabled(c *check.C) {
 	c.Assert(version.VersionInfo().Disabled, check.Equals, true)
 	c.Assert(version.VersionInfo().DisabledReason, check.Equals, "other reason")
 }
+
+func (s *S) TestAppVersionImpl_SetBuildPhase(c *check.C) {
+	svc, err := AppVersionService()
+	c.Assert(err, check.IsNil)
+	version, err := svc.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+		App: &appTypes.App{Name: "myapp"},
+	})
+	c.Assert(err, check.IsNil)
+	start := time.Date(2026, time.March, 2, 14, 0, 0, 0, time.UTC)
+	build := appTypes.BuildPhase{Name: "build", Status: appTypes.BuildPhaseRunning, StartTime: start, EventID: "evt1"}
+	err = version.SetBuildPhase(build)
+	c.Assert(err, check.IsNil)
+	build.Status = appTypes.BuildPhaseSucceeded
+	build.EndTime = start.Add(time.Minute)
+	err = version.SetBuildPhase(build)
+	c.Assert(err, check.IsNil)
+	rollout := appTypes.BuildPhase{Name: "rollout", Status: appTypes.BuildPhaseFailed, StartTime: build.EndTime, EndTime: build.EndTime.Add(time.Minute), EventID: "evt1"}
+	err = version.SetBuildPhase(rollout)
+	c.Assert(err, check.IsNil)
+	rollout.Status = appTypes.BuildPhaseRunning
+	rollout.EventID = "evt2"
+	err = version.SetBuildPhase(rollout)
+	c.Assert(err, check.IsNil)
+	versions, err := svc.AppVersions(context.TODO(), &appTypes.App{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	phases := versions.Versions[version.Version()].BuildPhases
+	c.Assert(phases, check.HasLen, 3)
+	c.Assert(phases[0].Status, check.Equals, appTypes.BuildPhaseSucceeded)
+	c.Assert(phases[0].EndTime.Equal(start.Add(time.Minute)), check.Equals, true)
+	c.Assert(phases[1].Status, check.Equals, appTypes.BuildPhaseFailed)
+	c.Assert(phases[2].Status, check.Equals, appTypes.BuildPhaseRunning)
+	c.Assert(phases[2].EventID, check.Equals, "evt2")
+}
@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/auth/peer"
@@ -55,6 +56,10 @@ func (s *aggregatorLogService) List(ctx context.Context, args appTypes.ListLogAr
 }
 
 func listAppLogs(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+	_, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
 	requests, err := buildInstanceRequests(ctx, args, false)
 	if err != nil {
 		return nil, errors.Wrapf(err, "[aggregator service]")
@@ -97,6 +102,10 @@ func listAppLogs(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.App
 
 func (s *aggregatorLogService) Watch(ctx context.Context, args appTypes.ListLogArgs) (appTypes.LogWatcher, error) {
 	args.Limit = -1
+	_, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
 	requests, err := buildInstanceRequests(ctx, args, true)
 	if err != nil {
 		return nil, errors.Wrapf(err, "[aggregator service]")
@@ -223,6 +232,19 @@ func buildInstanceRequests(ctx context.Context, args appTypes.ListLogArgs, follo
 			urlValues.Add("unit", u)
 		}
 		urlValues.Add("invert-source", strconv.FormatBool(args.InvertSource))
+		if !args.Since.IsZero() {
+			urlValues.Add("since", args.Since.Format(time.RFC3339Nano))
+		}
+		if !args.Until.IsZero() {
+			urlValues.Add("until", args.Until.Format(time.RFC3339Nano))
+		}
+		if args.Filter != "" {
+			urlValues.Add("filter", args.Filter)
+			urlValues.Add("filter-regex", strconv.FormatBool(args.FilterRegex))
+		}
+		for _, level := range args.Levels {
+			urlValues.Add("level", level)
+		}
 		if follow {
 			urlValues.Add("follow", "1")
 		}
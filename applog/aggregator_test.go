@@ -122,6 +122,34 @@ func (s *S) Test_Aggregator_ListFilter(c *check.C) {
 	})
 }
 
+func (s *S) Test_Aggregator_ListTimeAndContentFilters(c *check.C) {
+	since := time.Date(2026, time.March, 2, 14, 2, 0, 0, time.UTC)
+	until := time.Date(2026, time.March, 2, 14, 10, 0, 0, time.UTC)
+	rollback := mockServers(2, func(i int, w http.ResponseWriter, r *http.Request) bool {
+		c.Assert(r.URL.Query().Get("since"), check.Equals, "2026-03-02T14:02:00Z")
+		c.Assert(r.URL.Query().Get("until"), check.Equals, "2026-03-02T14:10:00Z")
+		c.Assert(r.URL.Query().Get("filter"), check.Equals, "time.?out")
+		c.Assert(r.URL.Query().Get("filter-regex"), check.Equals, "true")
+		c.Assert(r.URL.Query()["level"], check.DeepEquals, []string{"error", "warning"})
+		return false
+	})
+	defer rollback()
+	svc := &aggregatorLogService{}
+	logs, err := svc.List(context.TODO(), appTypes.ListLogArgs{
+		Name:        "myapp",
+		Type:        "app",
+		Since:       since,
+		Until:       until,
+		Filter:      "time.?out",
+		FilterRegex: true,
+		Levels:      []string{"error", "warning"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	_, err = svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Filter: "(", FilterRegex: true})
+	c.Assert(err, check.ErrorMatches, "invalid log filter: .*")
+}
+
 func (s *S) Test_Aggregator_ListReorderMessages(c *check.C) {
 	rollback := mockServers(6, func(i int, w http.ResponseWriter, r *http.Request) bool {
 		switch i {
@@ -32,5 +32,12 @@ func AppLogService() (appTypes.AppLogService, error) {
 	if err != nil {
 		return nil, err
 	}
+	sinks, err := logSinksFromConfig()
+	if err != nil {
+		return nil, err
+	}
+	if len(sinks) > 0 {
+		svc = newSinkLogService(svc, sinks)
+	}
 	return newProvisionerWrapper(svc), nil
 }
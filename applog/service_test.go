@@ -182,6 +182,62 @@ func (s *ServiceSuite) Test_LogService_ListFilterInvert(c *check.C) {
 	c.Check(logs[0].Source, check.Equals, "circus")
 }
 
+func (s *ServiceSuite) Test_LogService_ListTimeRange(c *check.C) {
+	base := time.Date(2026, time.March, 2, 14, 0, 0, 0, time.UTC)
+	for i := 0; i < 15; i++ {
+		err := s.svc.Enqueue(&appTypes.Applog{
+			Name:    "myapp",
+			Date:    base.Add(time.Duration(i) * time.Minute),
+			Message: strconv.Itoa(i),
+			Source:  "tsuru",
+			Unit:    "rdaneel",
+		})
+		c.Assert(err, check.IsNil)
+	}
+	logs, err := s.svc.List(context.TODO(), appTypes.ListLogArgs{
+		Name:  "myapp",
+		Since: base.Add(2 * time.Minute),
+		Until: base.Add(10 * time.Minute),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 9)
+	for i := 2; i <= 10; i++ {
+		c.Check(logs[i-2].Message, check.Equals, strconv.Itoa(i))
+	}
+	logs, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{
+		Name:  "myapp",
+		Limit: 3,
+		Until: base.Add(10 * time.Minute),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 3)
+	c.Check(logs[0].Message, check.Equals, "8")
+	c.Check(logs[2].Message, check.Equals, "10")
+}
+
+func (s *ServiceSuite) Test_LogService_ListMessageAndLevelFilter(c *check.C) {
+	s.svc.Add("myapp", `level=info msg="request done"`, "web", "u1")
+	s.svc.Add("myapp", `level=error msg="request timeout"`, "web", "u1")
+	s.svc.Add("myapp", `{"level":"warn","msg":"slow request"}`, "web", "u2")
+	s.svc.Add("myapp", "plain timeout", "worker", "u3")
+	logs, err := s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Filter: "timeout"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	c.Check(logs[0].Message, check.Equals, `level=error msg="request timeout"`)
+	c.Check(logs[1].Message, check.Equals, "plain timeout")
+	logs, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Filter: `^\{.*slow`, FilterRegex: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Check(logs[0].Unit, check.Equals, "u2")
+	logs, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Levels: []string{"error", "warning"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	c.Check(logs[0].Message, check.Equals, `level=error msg="request timeout"`)
+	c.Check(logs[1].Unit, check.Equals, "u2")
+	_, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Filter: "(", FilterRegex: true})
+	c.Assert(err, check.NotNil)
+}
+
 func (s *ServiceSuite) Test_LogService_ListEmpty(c *check.C) {
 	logs, err := s.svc.List(context.TODO(), appTypes.ListLogArgs{Limit: 10, Name: "myapp", Source: "tsuru"})
 	c.Assert(err, check.IsNil)
@@ -238,6 +294,23 @@ func (s *ServiceSuite) TestWatchFiltered(c *check.C) {
 	c.Assert(logMsg.Message, check.Equals, "6")
 }
 
+func (s *ServiceSuite) TestWatchFilteredLevel(c *check.C) {
+	l, err := s.svc.Watch(context.TODO(), appTypes.ListLogArgs{
+		Name:   "myapp",
+		Levels: []string{"error"},
+	})
+	c.Assert(err, check.IsNil)
+	defer l.Close()
+	addLog(c, s.svc, "myapp", "level=info msg=1", "web", "u1")
+	addLog(c, s.svc, "myapp", "level=error msg=2", "web", "u1")
+	addLog(c, s.svc, "myapp", "msg=3", "web", "u1")
+	addLog(c, s.svc, "myapp", `{"level":"error","msg":"4"}`, "web", "u1")
+	logMsg := <-l.Chan()
+	c.Assert(logMsg.Message, check.Equals, "level=error msg=2")
+	logMsg = <-l.Chan()
+	c.Assert(logMsg.Message, check.Equals, `{"level":"error","msg":"4"}`)
+}
+
 func (s *ServiceSuite) TestWatchFilteredInvertSource(c *check.C) {
 	l, err := s.svc.Watch(context.TODO(), appTypes.ListLogArgs{
 		Name:         "myapp",
@@ -0,0 +1,482 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/set"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	logTypes "github.com/tsuru/tsuru/types/log"
+)
+
+const (
+	defaultSinkBufferSize    = 10000
+	defaultSinkBatchSize     = 500
+	defaultSinkFlushInterval = time.Second
+	defaultSinkTimeout       = 10 * time.Second
+	defaultSinkMaxRetries    = 3
+	sinkRetryInterval        = 500 * time.Millisecond
+	sinkWarningInterval      = 30 * time.Second
+	sinkPoolCacheTTL         = time.Minute
+	logSinkSubsystem         = "logs_sink"
+
+	sinkDropQueueFull  = "queue_full"
+	sinkDropWriteError = "write_error"
+)
+
+var (
+	logsSinkSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logSinkSubsystem,
+		Name:      "sent_total",
+		Help:      "The number of log entries sent to a log sink.",
+	}, []string{"sink"})
+
+	logsSinkDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logSinkSubsystem,
+		Name:      "dropped_total",
+		Help:      "The number of log entries dropped by a log sink, due to a full queue or failed writes.",
+	}, []string{"sink", "reason"})
+
+	logsSinkWriteErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logSinkSubsystem,
+		Name:      "write_errors_total",
+		Help:      "The number of failed attempts to write a batch of log entries to a log sink.",
+	}, []string{"sink"})
+
+	logsSinkQueueLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: promNamespace,
+		Subsystem: logSinkSubsystem,
+		Name:      "queue_length",
+		Help:      "The number of log entries waiting to be sent to a log sink.",
+	}, []string{"sink"})
+)
+
+// sinkWriter writes batches of log entries to an external destination.
+type sinkWriter interface {
+	Write(ctx context.Context, entries []appTypes.Applog) error
+	Close() error
+}
+
+type sinkWriterFactory func(name string, conf sinkConfig) (sinkWriter, error)
+
+var sinkWriters = map[string]sinkWriterFactory{
+	"syslog": newSyslogWriter,
+	"otlp":   newOTLPWriter,
+	"http":   newHTTPBatchWriter,
+}
+
+// sinkConfig reads the settings of a sink under log:sinks:<name>.
+type sinkConfig string
+
+func (c sinkConfig) key(name string) string {
+	return fmt.Sprintf("log:sinks:%s:%s", string(c), name)
+}
+
+func (c sinkConfig) getString(name string) string {
+	value, _ := config.GetString(c.key(name))
+	return value
+}
+
+func (c sinkConfig) getBool(name string) bool {
+	value, _ := config.GetBool(c.key(name))
+	return value
+}
+
+func (c sinkConfig) getList(name string) []string {
+	value, _ := config.GetList(c.key(name))
+	return value
+}
+
+func (c sinkConfig) getInt(name string, defaultValue int) int {
+	if value, err := config.GetInt(c.key(name)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func (c sinkConfig) getDuration(name string, defaultValue time.Duration) time.Duration {
+	if value, err := config.GetDuration(c.key(name)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func (c sinkConfig) getMap(name string) map[string]string {
+	raw, err := config.Get(c.key(name))
+	if err != nil {
+		return nil
+	}
+	rawMap, _ := raw.(map[interface{}]interface{})
+	result := make(map[string]string, len(rawMap))
+	for k, v := range rawMap {
+		result[fmt.Sprint(k)] = fmt.Sprint(v)
+	}
+	return result
+}
+
+// logSinksFromConfig creates the sinks configured under log:sinks, sorted by
+// name. Each sink forwards the entries of the apps and jobs listed in its
+// apps setting or running in one of the pools in its pools setting, or every
+// entry when neither is set.
+func logSinksFromConfig() ([]*logSink, error) {
+	raw, err := config.Get("log:sinks")
+	if err != nil {
+		return nil, nil
+	}
+	sinksMap, _ := raw.(map[interface{}]interface{})
+	names := make([]string, 0, len(sinksMap))
+	for name := range sinksMap {
+		names = append(names, fmt.Sprint(name))
+	}
+	sort.Strings(names)
+	resolver := newPoolResolver()
+	sinks := make([]*logSink, 0, len(names))
+	for _, name := range names {
+		conf := sinkConfig(name)
+		sinkType := conf.getString("type")
+		factory, ok := sinkWriters[sinkType]
+		if !ok {
+			return nil, errors.Errorf(`invalid type %q for log sink %q, valid values are: "syslog", "otlp" or "http"`, sinkType, name)
+		}
+		writer, err := factory(name, conf)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create log sink %q", name)
+		}
+		sinks = append(sinks, newLogSink(name, writer, conf, resolver))
+	}
+	return sinks, nil
+}
+
+var _ appTypes.AppLogService = &sinkLogService{}
+
+// sinkLogService forwards every enqueued entry to the log sinks, besides
+// handing it to the wrapped service.
+type sinkLogService struct {
+	logService appTypes.AppLogService
+	sinks      []*logSink
+}
+
+func newSinkLogService(logService appTypes.AppLogService, sinks []*logSink) *sinkLogService {
+	s := &sinkLogService{
+		logService: logService,
+		sinks:      sinks,
+	}
+	for _, sink := range sinks {
+		sink.start()
+	}
+	shutdown.Register(s)
+	return s
+}
+
+func (s *sinkLogService) Enqueue(entry *appTypes.Applog) error {
+	err := s.logService.Enqueue(entry)
+	if err != nil {
+		return err
+	}
+	for _, sink := range s.sinks {
+		sink.enqueue(entry)
+	}
+	return nil
+}
+
+func (s *sinkLogService) Add(appName, message, source, unit string) error {
+	for _, entry := range newLogEntries(appName, message, source, unit) {
+		err := s.Enqueue(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sinkLogService) List(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+	return s.logService.List(ctx, args)
+}
+
+func (s *sinkLogService) Watch(ctx context.Context, args appTypes.ListLogArgs) (appTypes.LogWatcher, error) {
+	return s.logService.Watch(ctx, args)
+}
+
+func (s *sinkLogService) Instance() appTypes.AppLogService {
+	if svcInstance, ok := s.logService.(appTypes.AppLogServiceInstance); ok {
+		return svcInstance.Instance()
+	}
+	return s.logService
+}
+
+// Shutdown flushes the entries queued in the sinks before closing them.
+func (s *sinkLogService) Shutdown(ctx context.Context) error {
+	for _, sink := range s.sinks {
+		err := sink.stop(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type logSink struct {
+	name          string
+	writer        sinkWriter
+	apps          set.Set
+	pools         set.Set
+	resolver      *poolResolver
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+	maxRetries    int
+	ch            chan *appTypes.Applog
+	quit          chan struct{}
+	done          chan struct{}
+
+	mu          sync.Mutex
+	dropped     map[string]uint64
+	nextWarning time.Time
+
+	sentCounter   prometheus.Counter
+	fullCounter   prometheus.Counter
+	failedCounter prometheus.Counter
+	errorsCounter prometheus.Counter
+	queueGauge    prometheus.Gauge
+}
+
+func newLogSink(name string, writer sinkWriter, conf sinkConfig, resolver *poolResolver) *logSink {
+	return &logSink{
+		name:          name,
+		writer:        writer,
+		apps:          set.FromSlice(conf.getList("apps")),
+		pools:         set.FromSlice(conf.getList("pools")),
+		resolver:      resolver,
+		batchSize:     conf.getInt("batch-size", defaultSinkBatchSize),
+		flushInterval: conf.getDuration("flush-interval", defaultSinkFlushInterval),
+		timeout:       conf.getDuration("timeout", defaultSinkTimeout),
+		maxRetries:    conf.getInt("max-retries", defaultSinkMaxRetries),
+		ch:            make(chan *appTypes.Applog, conf.getInt("buffer-size", defaultSinkBufferSize)),
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+		dropped:       map[string]uint64{},
+		sentCounter:   logsSinkSent.WithLabelValues(name),
+		fullCounter:   logsSinkDropped.WithLabelValues(name, sinkDropQueueFull),
+		failedCounter: logsSinkDropped.WithLabelValues(name, sinkDropWriteError),
+		errorsCounter: logsSinkWriteErrors.WithLabelValues(name),
+		queueGauge:    logsSinkQueueLength.WithLabelValues(name),
+	}
+}
+
+func (s *logSink) start() {
+	go s.run()
+}
+
+func (s *logSink) stop(ctx context.Context) error {
+	close(s.quit)
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return s.writer.Close()
+}
+
+// enqueue never blocks the caller: when the sink can't keep up and its queue
+// is full the entry is dropped, and a warning is sent along the next batch.
+func (s *logSink) enqueue(entry *appTypes.Applog) {
+	if !s.matches(entry, false) {
+		return
+	}
+	select {
+	case s.ch <- entry:
+	default:
+		s.fullCounter.Inc()
+		s.mu.Lock()
+		s.dropped[entry.Name]++
+		s.mu.Unlock()
+	}
+}
+
+// matches reports whether the sink forwards the entry. The pool of the entry
+// is only looked up when resolve is set, otherwise an entry whose pool isn't
+// cached yet is assumed to match.
+func (s *logSink) matches(entry *appTypes.Applog, resolve bool) bool {
+	if len(s.apps) == 0 && len(s.pools) == 0 {
+		return true
+	}
+	if s.apps.Includes(entry.Name) {
+		return true
+	}
+	if len(s.pools) == 0 {
+		return false
+	}
+	pool, ok := s.resolver.cached(entry)
+	if !ok {
+		if !resolve {
+			return true
+		}
+		pool = s.resolver.resolve(entry)
+	}
+	return s.pools.Includes(pool)
+}
+
+func (s *logSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+	batch := make([]appTypes.Applog, 0, s.batchSize)
+	add := func(entry *appTypes.Applog) {
+		if s.matches(entry, true) {
+			batch = append(batch, *entry)
+		}
+		if len(batch) >= s.batchSize {
+			s.flush(batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case entry := <-s.ch:
+			add(entry)
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+		case <-s.quit:
+			for {
+				select {
+				case entry := <-s.ch:
+					add(entry)
+				default:
+					s.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *logSink) flush(batch []appTypes.Applog) {
+	s.queueGauge.Set(float64(len(s.ch)))
+	batch = append(batch, s.droppedWarnings(time.Now())...)
+	if len(batch) == 0 {
+		return
+	}
+	var err error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(sinkRetryInterval << (attempt - 1)):
+			case <-s.quit:
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		err = s.writer.Write(ctx, batch)
+		cancel()
+		if err == nil {
+			s.sentCounter.Add(float64(len(batch)))
+			return
+		}
+		s.errorsCounter.Inc()
+	}
+	s.failedCounter.Add(float64(len(batch)))
+	log.Errorf("[log sink %s] unable to send %d log entries: %v", s.name, len(batch), err)
+}
+
+// droppedWarnings returns a warning for each app with entries dropped since
+// the last warnings, at most once every sinkWarningInterval.
+func (s *logSink) droppedWarnings(now time.Time) []appTypes.Applog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.dropped) == 0 || now.Before(s.nextWarning) {
+		return nil
+	}
+	names := make([]string, 0, len(s.dropped))
+	for name := range s.dropped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	warnings := make([]appTypes.Applog, 0, len(names))
+	for _, name := range names {
+		warnings = append(warnings, sinkDroppedWarning(name, s.dropped[name], now))
+	}
+	s.dropped = map[string]uint64{}
+	s.nextWarning = now.Add(sinkWarningInterval)
+	return warnings
+}
+
+func sinkDroppedWarning(appName string, count uint64, now time.Time) appTypes.Applog {
+	return appTypes.Applog{
+		Name:    appName,
+		Date:    now,
+		Message: fmt.Sprintf("%d log messages dropped due to slow log sink or too many messages being produced.", count),
+		Source:  "tsuru",
+		Unit:    "api",
+	}
+}
+
+type poolCacheEntry struct {
+	pool    string
+	expires time.Time
+}
+
+// poolResolver caches the pools of apps and jobs, so sinks filtering by pool
+// don't hit the database for every entry.
+type poolResolver struct {
+	mu      sync.RWMutex
+	entries map[string]poolCacheEntry
+	lookup  func(ctx context.Context, lType logTypes.LogType, name string) (string, error)
+}
+
+func newPoolResolver() *poolResolver {
+	return &poolResolver{
+		entries: map[string]poolCacheEntry{},
+		lookup: func(ctx context.Context, lType logTypes.LogType, name string) (string, error) {
+			obj, err := defineLogabbleObject(ctx, lType, name)
+			if err != nil {
+				return "", err
+			}
+			return obj.Pool, nil
+		},
+	}
+}
+
+func poolCacheKey(entry *appTypes.Applog) string {
+	return string(entry.Type) + "/" + entry.Name
+}
+
+func (r *poolResolver) cached(entry *appTypes.Applog) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cached, ok := r.entries[poolCacheKey(entry)]
+	if !ok || time.Now().After(cached.expires) {
+		return "", false
+	}
+	return cached.pool, true
+}
+
+// resolve looks up the pool of the entry, caching an empty pool when the app
+// or job can't be found.
+func (r *poolResolver) resolve(entry *appTypes.Applog) string {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSinkTimeout)
+	defer cancel()
+	pool, err := r.lookup(ctx, entry.Type, entry.Name)
+	if err != nil {
+		log.Debugf("[log sinks] unable to find pool for %q: %v", entry.Name, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[poolCacheKey(entry)] = poolCacheEntry{pool: pool, expires: time.Now().Add(sinkPoolCacheTTL)}
+	return pool
+}
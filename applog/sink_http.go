@@ -0,0 +1,73 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const maxSinkErrorBodySize = 1024
+
+// httpBatchWriter posts each batch as a JSON array of entries, in the same
+// format used by the log API.
+type httpBatchWriter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newHTTPBatchWriter(name string, conf sinkConfig) (sinkWriter, error) {
+	url := conf.getString("url")
+	if url == "" {
+		return nil, errors.New("http url is required")
+	}
+	return &httpBatchWriter{
+		url:     url,
+		headers: conf.getMap("headers"),
+		client:  tsuruNet.Dial15Full300Client,
+	}, nil
+}
+
+func (w *httpBatchWriter) Write(ctx context.Context, entries []appTypes.Applog) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return postSinkBatch(ctx, w.client, w.url, "application/json", w.headers, data)
+}
+
+func (w *httpBatchWriter) Close() error {
+	return nil
+}
+
+func postSinkBatch(ctx context.Context, client *http.Client, url, contentType string, headers map[string]string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", contentType)
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(rsp.Body, maxSinkErrorBodySize))
+		return errors.Errorf("invalid status code %d from %q: %s", rsp.StatusCode, url, body)
+	}
+	io.Copy(io.Discard, rsp.Body)
+	return nil
+}
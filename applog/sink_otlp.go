@@ -0,0 +1,159 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+
+	"github.com/pkg/errors"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	logTypes "github.com/tsuru/tsuru/types/log"
+	collectorLogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonProto "go.opentelemetry.io/proto/otlp/common/v1"
+	logsProto "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourceProto "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	otlpScopeName       = "tsuru"
+	otlpDefaultHTTPPath = "/v1/logs"
+)
+
+// otlpSeverities maps the normalized log levels to OTLP severity numbers.
+var otlpSeverities = map[string]logsProto.SeverityNumber{
+	"trace":    logsProto.SeverityNumber_SEVERITY_NUMBER_TRACE,
+	"debug":    logsProto.SeverityNumber_SEVERITY_NUMBER_DEBUG,
+	"info":     logsProto.SeverityNumber_SEVERITY_NUMBER_INFO,
+	"notice":   logsProto.SeverityNumber_SEVERITY_NUMBER_INFO2,
+	"warning":  logsProto.SeverityNumber_SEVERITY_NUMBER_WARN,
+	"error":    logsProto.SeverityNumber_SEVERITY_NUMBER_ERROR,
+	"critical": logsProto.SeverityNumber_SEVERITY_NUMBER_FATAL,
+	"fatal":    logsProto.SeverityNumber_SEVERITY_NUMBER_FATAL,
+}
+
+// otlpWriter exports the entries as OTLP logs, over gRPC or HTTP according to
+// the protocol setting, grouped in a resource for each app or job.
+type otlpWriter struct {
+	export func(ctx context.Context, req *collectorLogs.ExportLogsServiceRequest) error
+	close  func() error
+}
+
+func newOTLPWriter(name string, conf sinkConfig) (sinkWriter, error) {
+	endpoint := conf.getString("endpoint")
+	if endpoint == "" {
+		return nil, errors.New("otlp endpoint is required")
+	}
+	headers := conf.getMap("headers")
+	switch protocol := conf.getString("protocol"); protocol {
+	case "", "grpc":
+		creds := insecure.NewCredentials()
+		if !conf.getBool("insecure") {
+			creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: conf.getBool("tls-skip-verify")})
+		}
+		conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, err
+		}
+		client := collectorLogs.NewLogsServiceClient(conn)
+		return &otlpWriter{
+			export: func(ctx context.Context, req *collectorLogs.ExportLogsServiceRequest) error {
+				if len(headers) > 0 {
+					ctx = metadata.NewOutgoingContext(ctx, metadata.New(headers))
+				}
+				_, err := client.Export(ctx, req)
+				return err
+			},
+			close: conn.Close,
+		}, nil
+	case "http":
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid otlp endpoint %q", endpoint)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = otlpDefaultHTTPPath
+		}
+		exportURL := u.String()
+		return &otlpWriter{
+			export: func(ctx context.Context, req *collectorLogs.ExportLogsServiceRequest) error {
+				data, err := proto.Marshal(req)
+				if err != nil {
+					return err
+				}
+				return postSinkBatch(ctx, tsuruNet.Dial15Full300Client, exportURL, "application/x-protobuf", headers, data)
+			},
+			close: func() error { return nil },
+		}, nil
+	default:
+		return nil, errors.Errorf(`invalid otlp protocol %q, valid values are: "grpc" or "http"`, protocol)
+	}
+}
+
+func (w *otlpWriter) Write(ctx context.Context, entries []appTypes.Applog) error {
+	return w.export(ctx, otlpRequest(entries))
+}
+
+func (w *otlpWriter) Close() error {
+	return w.close()
+}
+
+func otlpRequest(entries []appTypes.Applog) *collectorLogs.ExportLogsServiceRequest {
+	req := &collectorLogs.ExportLogsServiceRequest{}
+	scopes := map[string]*logsProto.ScopeLogs{}
+	for i := range entries {
+		entry := &entries[i]
+		key := poolCacheKey(entry)
+		scope, ok := scopes[key]
+		if !ok {
+			scope = &logsProto.ScopeLogs{
+				Scope: &commonProto.InstrumentationScope{Name: otlpScopeName},
+			}
+			scopes[key] = scope
+			req.ResourceLogs = append(req.ResourceLogs, &logsProto.ResourceLogs{
+				Resource:  otlpResource(entry),
+				ScopeLogs: []*logsProto.ScopeLogs{scope},
+			})
+		}
+		level := appTypes.LogLevel(entry.Message)
+		timestamp := uint64(entry.Date.UnixNano())
+		scope.LogRecords = append(scope.LogRecords, &logsProto.LogRecord{
+			TimeUnixNano:         timestamp,
+			ObservedTimeUnixNano: timestamp,
+			SeverityNumber:       otlpSeverities[level],
+			SeverityText:         level,
+			Body:                 otlpString(entry.Message),
+			Attributes: []*commonProto.KeyValue{
+				{Key: "tsuru.log.source", Value: otlpString(entry.Source)},
+				{Key: "tsuru.log.unit", Value: otlpString(entry.Unit)},
+			},
+		})
+	}
+	return req
+}
+
+func otlpResource(entry *appTypes.Applog) *resourceProto.Resource {
+	nameKey := "tsuru.app.name"
+	if entry.Type == logTypes.LogTypeJob {
+		nameKey = "tsuru.job.name"
+	}
+	return &resourceProto.Resource{
+		Attributes: []*commonProto.KeyValue{
+			{Key: "service.name", Value: otlpString(entry.Name)},
+			{Key: nameKey, Value: otlpString(entry.Name)},
+		},
+	}
+}
+
+func otlpString(value string) *commonProto.AnyValue {
+	return &commonProto.AnyValue{Value: &commonProto.AnyValue_StringValue{StringValue: value}}
+}
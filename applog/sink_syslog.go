@@ -0,0 +1,161 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	defaultSyslogFacility = 1 // user-level messages
+	syslogNilValue        = "-"
+)
+
+// syslogSeverities maps the normalized log levels to RFC 5424 severities.
+var syslogSeverities = map[string]int{
+	"fatal":    0,
+	"critical": 2,
+	"error":    3,
+	"warning":  4,
+	"notice":   5,
+	"info":     6,
+	"debug":    7,
+	"trace":    7,
+}
+
+// syslogWriter sends RFC 5424 messages over TCP, or TLS when the tls setting
+// is set, framed by octet counting as described in RFC 6587.
+type syslogWriter struct {
+	address  string
+	facility int
+	dialer   func(ctx context.Context) (net.Conn, error)
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogWriter(name string, conf sinkConfig) (sinkWriter, error) {
+	address := conf.getString("address")
+	if address == "" {
+		return nil, errors.New("syslog address is required")
+	}
+	facility := defaultSyslogFacility
+	if conf.getString("facility") != "" {
+		facility = conf.getInt("facility", 0)
+		if facility > 23 {
+			return nil, errors.Errorf("invalid syslog facility %d", facility)
+		}
+	}
+	w := &syslogWriter{
+		address:  address,
+		facility: facility,
+	}
+	dialer := &net.Dialer{Timeout: defaultSinkTimeout}
+	w.dialer = func(ctx context.Context) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+	if conf.getBool("tls") {
+		host, _, _ := net.SplitHostPort(address)
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config: &tls.Config{
+				ServerName:         host,
+				InsecureSkipVerify: conf.getBool("tls-skip-verify"),
+			},
+		}
+		w.dialer = func(ctx context.Context) (net.Conn, error) {
+			return tlsDialer.DialContext(ctx, "tcp", address)
+		}
+	}
+	return w, nil
+}
+
+func (w *syslogWriter) Write(ctx context.Context, entries []appTypes.Applog) error {
+	var buf bytes.Buffer
+	for i := range entries {
+		msg := w.format(&entries[i])
+		fmt.Fprintf(&buf, "%d %s", len(msg), msg)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		conn, err := w.dialer(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "unable to connect to syslog server %q", w.address)
+		}
+		w.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		w.conn.SetWriteDeadline(deadline)
+	}
+	_, err := w.conn.Write(buf.Bytes())
+	if err != nil {
+		// The connection is reopened on the next write, as some of the
+		// messages may have been written already.
+		w.conn.Close()
+		w.conn = nil
+		return errors.Wrapf(err, "unable to write to syslog server %q", w.address)
+	}
+	return nil
+}
+
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// format returns the entry as a RFC 5424 message. The unit is used as the
+// hostname, the app or job name as the app name and the source as the
+// process id.
+func (w *syslogWriter) format(entry *appTypes.Applog) string {
+	severity, ok := syslogSeverities[appTypes.LogLevel(entry.Message)]
+	if !ok {
+		severity = syslogSeverities["info"]
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s",
+		w.facility*8+severity,
+		entry.Date.UTC().Format(time.RFC3339Nano),
+		syslogHeaderField(entry.Unit, 255),
+		syslogHeaderField(entry.Name, 48),
+		syslogHeaderField(entry.Source, 128),
+		syslogHeaderField(string(entry.Type), 32),
+		syslogNilValue,
+		entry.Message,
+	)
+}
+
+// syslogHeaderField returns value with only printable ASCII characters and
+// truncated to size, or the nil value when it's empty.
+func syslogHeaderField(value string, size int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > size {
+		value = value[:size]
+	}
+	if value == "" {
+		return syslogNilValue
+	}
+	return value
+}
@@ -0,0 +1,311 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	logTypes "github.com/tsuru/tsuru/types/log"
+	collectorLogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logsProto "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/check.v1"
+)
+
+type fakeSinkWriter struct {
+	mu      sync.Mutex
+	batches [][]appTypes.Applog
+	fail    int
+	block   chan struct{}
+	closed  bool
+}
+
+func (w *fakeSinkWriter) Write(ctx context.Context, entries []appTypes.Applog) error {
+	if w.block != nil {
+		<-w.block
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.fail > 0 {
+		w.fail--
+		return errors.New("sink unavailable")
+	}
+	w.batches = append(w.batches, append([]appTypes.Applog{}, entries...))
+	return nil
+}
+
+func (w *fakeSinkWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func (w *fakeSinkWriter) messages() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var messages []string
+	for _, batch := range w.batches {
+		for _, entry := range batch {
+			messages = append(messages, entry.Message)
+		}
+	}
+	return messages
+}
+
+func newTestSink(c *check.C, name string, writer sinkWriter) *logSink {
+	resolver := newPoolResolver()
+	resolver.lookup = func(ctx context.Context, lType logTypes.LogType, name string) (string, error) {
+		if name == "app-in-pool1" {
+			return "pool1", nil
+		}
+		return "pool2", nil
+	}
+	return newLogSink(name, writer, sinkConfig(name), resolver)
+}
+
+func (s *S) TestLogSinksFromConfig(c *check.C) {
+	config.Set("log:sinks", map[interface{}]interface{}{
+		"b-http":   map[interface{}]interface{}{"type": "http", "url": "http://localhost:9999/logs"},
+		"a-syslog": map[interface{}]interface{}{"type": "syslog", "address": "localhost:514", "apps": []interface{}{"myapp"}},
+	})
+	defer config.Unset("log:sinks")
+	sinks, err := logSinksFromConfig()
+	c.Assert(err, check.IsNil)
+	c.Assert(sinks, check.HasLen, 2)
+	c.Assert(sinks[0].name, check.Equals, "a-syslog")
+	c.Assert(sinks[0].apps.ToList(), check.DeepEquals, []string{"myapp"})
+	c.Assert(sinks[0].writer, check.FitsTypeOf, &syslogWriter{})
+	c.Assert(sinks[1].name, check.Equals, "b-http")
+	c.Assert(sinks[1].writer, check.FitsTypeOf, &httpBatchWriter{})
+	c.Assert(cap(sinks[1].ch), check.Equals, defaultSinkBufferSize)
+	c.Assert(sinks[1].batchSize, check.Equals, defaultSinkBatchSize)
+}
+
+func (s *S) TestLogSinksFromConfigInvalid(c *check.C) {
+	defer config.Unset("log:sinks")
+	config.Set("log:sinks", map[interface{}]interface{}{
+		"mysink": map[interface{}]interface{}{"type": "kafka"},
+	})
+	_, err := logSinksFromConfig()
+	c.Assert(err, check.ErrorMatches, `invalid type "kafka" for log sink "mysink".*`)
+	config.Set("log:sinks", map[interface{}]interface{}{
+		"mysink": map[interface{}]interface{}{"type": "otlp", "endpoint": "localhost:4317", "protocol": "udp"},
+	})
+	_, err = logSinksFromConfig()
+	c.Assert(err, check.ErrorMatches, `unable to create log sink "mysink": invalid otlp protocol "udp".*`)
+	config.Set("log:sinks", map[interface{}]interface{}{
+		"mysink": map[interface{}]interface{}{"type": "syslog"},
+	})
+	_, err = logSinksFromConfig()
+	c.Assert(err, check.ErrorMatches, `unable to create log sink "mysink": syslog address is required`)
+}
+
+func (s *S) TestSinkLogServiceForwardsEntries(c *check.C) {
+	config.Set("log:sinks:all:flush-interval", "10ms")
+	config.Set("log:sinks:filtered:flush-interval", "10ms")
+	config.Set("log:sinks:filtered:apps", []interface{}{"app1"})
+	config.Set("log:sinks:filtered:pools", []interface{}{"pool1"})
+	defer config.Unset("log:sinks")
+	all := &fakeSinkWriter{}
+	filtered := &fakeSinkWriter{}
+	base, err := memoryAppLogService()
+	c.Assert(err, check.IsNil)
+	svc := newSinkLogService(base, []*logSink{
+		newTestSink(c, "all", all),
+		newTestSink(c, "filtered", filtered),
+	})
+	c.Assert(svc.Add("app1", "msg1\nmsg2", "web", "u1"), check.IsNil)
+	c.Assert(svc.Add("app2", "msg3", "web", "u1"), check.IsNil)
+	c.Assert(svc.Add("app-in-pool1", "msg4", "web", "u1"), check.IsNil)
+	c.Assert(svc.Shutdown(context.TODO()), check.IsNil)
+	c.Assert(all.messages(), check.DeepEquals, []string{"msg1", "msg2", "msg3", "msg4"})
+	c.Assert(filtered.messages(), check.DeepEquals, []string{"msg1", "msg2", "msg4"})
+	c.Assert(all.closed, check.Equals, true)
+	logs, err := svc.List(context.TODO(), appTypes.ListLogArgs{Name: "app1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+}
+
+func (s *S) TestLogSinkBatches(c *check.C) {
+	config.Set("log:sinks:mysink:batch-size", 2)
+	config.Set("log:sinks:mysink:flush-interval", "1h")
+	defer config.Unset("log:sinks")
+	writer := &fakeSinkWriter{}
+	sink := newTestSink(c, "mysink", writer)
+	sink.start()
+	for i := 0; i < 5; i++ {
+		sink.enqueue(&appTypes.Applog{Name: "myapp", Message: strconv.Itoa(i)})
+	}
+	c.Assert(sink.stop(context.TODO()), check.IsNil)
+	c.Assert(writer.batches, check.HasLen, 3)
+	c.Assert(writer.batches[0], check.HasLen, 2)
+	c.Assert(writer.batches[2], check.HasLen, 1)
+	c.Assert(writer.messages(), check.DeepEquals, []string{"0", "1", "2", "3", "4"})
+}
+
+func (s *S) TestLogSinkDropsWhenQueueIsFull(c *check.C) {
+	config.Set("log:sinks:mysink:buffer-size", 2)
+	config.Set("log:sinks:mysink:batch-size", 1)
+	defer config.Unset("log:sinks")
+	writer := &fakeSinkWriter{block: make(chan struct{})}
+	sink := newTestSink(c, "mysink", writer)
+	sink.start()
+	sink.enqueue(&appTypes.Applog{Name: "myapp", Message: "0"})
+	for len(sink.ch) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 1; i < 6; i++ {
+		sink.enqueue(&appTypes.Applog{Name: "myapp", Message: strconv.Itoa(i)})
+	}
+	close(writer.block)
+	c.Assert(sink.stop(context.TODO()), check.IsNil)
+	c.Assert(writer.messages(), check.DeepEquals, []string{
+		"0",
+		"1",
+		"3 log messages dropped due to slow log sink or too many messages being produced.",
+		"2",
+	})
+}
+
+func (s *S) TestLogSinkRetriesFailedWrites(c *check.C) {
+	config.Set("log:sinks:mysink:max-retries", 1)
+	defer config.Unset("log:sinks")
+	writer := &fakeSinkWriter{fail: 1}
+	sink := newTestSink(c, "mysink", writer)
+	sink.flush([]appTypes.Applog{{Name: "myapp", Message: "msg1"}})
+	c.Assert(writer.messages(), check.DeepEquals, []string{"msg1"})
+	writer.fail = 2
+	sink.flush([]appTypes.Applog{{Name: "myapp", Message: "msg2"}})
+	c.Assert(writer.messages(), check.DeepEquals, []string{"msg1"})
+}
+
+func (s *S) TestSyslogWriter(c *check.C) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer l.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			lenStr, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(lenStr))
+			msg := make([]byte, size)
+			_, err = io.ReadFull(reader, msg)
+			if err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+	config.Set("log:sinks:mysink:address", l.Addr().String())
+	config.Set("log:sinks:mysink:facility", 16)
+	defer config.Unset("log:sinks")
+	writer, err := newSyslogWriter("mysink", sinkConfig("mysink"))
+	c.Assert(err, check.IsNil)
+	defer writer.Close()
+	date := time.Date(2026, time.March, 2, 14, 2, 0, 0, time.UTC)
+	err = writer.Write(context.TODO(), []appTypes.Applog{
+		{Name: "myapp", Date: date, Message: "level=error msg=timeout", Source: "web", Unit: "myapp-web-1", Type: logTypes.LogTypeApp},
+		{Name: "myapp", Date: date, Message: "hello world", Source: "tsuru"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(<-received, check.Equals, "<131>1 2026-03-02T14:02:00Z myapp-web-1 myapp web app - level=error msg=timeout")
+	c.Assert(<-received, check.Equals, "<134>1 2026-03-02T14:02:00Z - myapp tsuru - - hello world")
+}
+
+func (s *S) TestHTTPBatchWriter(c *check.C) {
+	var received []appTypes.Applog
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Header.Get("Content-Type"), check.Equals, "application/json")
+		c.Check(r.Header.Get("Authorization"), check.Equals, "Bearer abc")
+		err := json.NewDecoder(r.Body).Decode(&received)
+		c.Check(err, check.IsNil)
+	}))
+	defer srv.Close()
+	config.Set("log:sinks:mysink:url", srv.URL)
+	config.Set("log:sinks:mysink:headers", map[interface{}]interface{}{"Authorization": "Bearer abc"})
+	defer config.Unset("log:sinks")
+	writer, err := newHTTPBatchWriter("mysink", sinkConfig("mysink"))
+	c.Assert(err, check.IsNil)
+	err = writer.Write(context.TODO(), []appTypes.Applog{{Name: "myapp", Message: "msg1"}, {Name: "myapp", Message: "msg2"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(received, check.HasLen, 2)
+	c.Assert(received[1].Message, check.Equals, "msg2")
+}
+
+func (s *S) TestHTTPBatchWriterInvalidStatus(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("overloaded"))
+	}))
+	defer srv.Close()
+	config.Set("log:sinks:mysink:url", srv.URL)
+	defer config.Unset("log:sinks")
+	writer, err := newHTTPBatchWriter("mysink", sinkConfig("mysink"))
+	c.Assert(err, check.IsNil)
+	err = writer.Write(context.TODO(), []appTypes.Applog{{Name: "myapp", Message: "msg1"}})
+	c.Assert(err, check.ErrorMatches, `invalid status code 503 from ".*": overloaded`)
+}
+
+func (s *S) TestOTLPWriterHTTP(c *check.C) {
+	var received collectorLogs.ExportLogsServiceRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v1/logs")
+		c.Check(r.Header.Get("Content-Type"), check.Equals, "application/x-protobuf")
+		data, err := io.ReadAll(r.Body)
+		c.Check(err, check.IsNil)
+		c.Check(proto.Unmarshal(data, &received), check.IsNil)
+	}))
+	defer srv.Close()
+	config.Set("log:sinks:mysink:endpoint", srv.URL)
+	config.Set("log:sinks:mysink:protocol", "http")
+	defer config.Unset("log:sinks")
+	writer, err := newOTLPWriter("mysink", sinkConfig("mysink"))
+	c.Assert(err, check.IsNil)
+	defer writer.Close()
+	date := time.Date(2026, time.March, 2, 14, 2, 0, 0, time.UTC)
+	err = writer.Write(context.TODO(), []appTypes.Applog{
+		{Name: "app1", Date: date, Message: `{"level":"warn","msg":"slow"}`, Source: "web", Unit: "u1"},
+		{Name: "job1", Date: date, Message: "done", Source: "job", Unit: "u2", Type: logTypes.LogTypeJob},
+		{Name: "app1", Date: date, Message: "msg2", Source: "web", Unit: "u1"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(received.ResourceLogs, check.HasLen, 2)
+	app := received.ResourceLogs[0]
+	c.Assert(app.Resource.Attributes[1].Key, check.Equals, "tsuru.app.name")
+	c.Assert(app.Resource.Attributes[1].Value.GetStringValue(), check.Equals, "app1")
+	records := app.ScopeLogs[0].LogRecords
+	c.Assert(records, check.HasLen, 2)
+	c.Assert(records[0].TimeUnixNano, check.Equals, uint64(date.UnixNano()))
+	c.Assert(records[0].SeverityNumber, check.Equals, logsProto.SeverityNumber_SEVERITY_NUMBER_WARN)
+	c.Assert(records[0].SeverityText, check.Equals, "warning")
+	c.Assert(records[0].Body.GetStringValue(), check.Equals, `{"level":"warn","msg":"slow"}`)
+	c.Assert(records[1].SeverityNumber, check.Equals, logsProto.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED)
+	job := received.ResourceLogs[1]
+	c.Assert(job.Resource.Attributes[1].Key, check.Equals, "tsuru.job.name")
+	c.Assert(job.ScopeLogs[0].LogRecords, check.HasLen, 1)
+}
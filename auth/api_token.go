@@ -50,7 +50,7 @@ func APIAuth(ctx context.Context, header string) (*APIToken, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = usersCollection.FindOne(ctx, mongoBSON.M{"apikey": token}).Decode(&t)
+	err = usersCollection.FindOne(ctx, mongoBSON.M{"apikey": token, "disabled": mongoBSON.M{"$ne": true}}).Decode(&t)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, ErrInvalidToken
@@ -57,3 +57,32 @@ func (s *groupService) RemoveRole(ctx context.Context, name, roleName, contextVa
 	}
 	return s.storage.RemoveRole(ctx, name, roleName, contextValue)
 }
+
+func (s *groupService) ListByMember(ctx context.Context, email string) ([]authTypes.Group, error) {
+	return s.storage.ListByMember(ctx, email)
+}
+
+func (s *groupService) SetMembers(ctx context.Context, name string, members []string) error {
+	if name == "" {
+		return errGroupNameEmpty
+	}
+	return s.storage.SetMembers(ctx, name, members)
+}
+
+func (s *groupService) AddMembers(ctx context.Context, name string, members []string) error {
+	if name == "" {
+		return errGroupNameEmpty
+	}
+	return s.storage.AddMembers(ctx, name, members)
+}
+
+func (s *groupService) RemoveMembers(ctx context.Context, name string, members []string) error {
+	if name == "" {
+		return errGroupNameEmpty
+	}
+	return s.storage.RemoveMembers(ctx, name, members)
+}
+
+func (s *groupService) Delete(ctx context.Context, name string) error {
+	return s.storage.Delete(ctx, name)
+}
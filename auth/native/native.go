@@ -31,9 +31,10 @@ func init() {
 }
 
 var (
-	_ auth.Scheme        = &NativeScheme{}
-	_ auth.UserScheme    = &NativeScheme{}
-	_ auth.ManagedScheme = &NativeScheme{}
+	_ auth.Scheme            = &NativeScheme{}
+	_ auth.UserScheme        = &NativeScheme{}
+	_ auth.ManagedScheme     = &NativeScheme{}
+	_ auth.ProvisionedScheme = &NativeScheme{}
 )
 
 func (s NativeScheme) Login(ctx context.Context, params map[string]string) (auth.Token, error) {
@@ -49,6 +50,9 @@ func (s NativeScheme) Login(ctx context.Context, params map[string]string) (auth
 	if err != nil {
 		return nil, err
 	}
+	if user.Disabled {
+		return nil, auth.ErrUserDisabled
+	}
 	token, err := createToken(ctx, user, password)
 	if err != nil {
 		return nil, err
@@ -146,6 +150,10 @@ func (s NativeScheme) Remove(ctx context.Context, u *auth.User) error {
 	return u.Delete(ctx)
 }
 
+func (s NativeScheme) Deactivate(ctx context.Context, u *auth.User) error {
+	return deleteAllTokens(ctx, u.Email)
+}
+
 func (s NativeScheme) Info(ctx context.Context) (*authTypes.SchemeInfo, error) {
 	return &authTypes.SchemeInfo{Name: "native"}, nil
 }
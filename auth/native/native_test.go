@@ -67,6 +67,25 @@ func (s *S) TestNativeLoginInvalidUser(c *check.C) {
 	c.Assert(err, check.Equals, authTypes.ErrUserNotFound)
 }
 
+func (s *S) TestNativeLoginDisabledUser(c *check.C) {
+	scheme := NativeScheme{}
+	params := map[string]string{"email": "timeredbull@globo.com", "password": "123456"}
+	token, err := scheme.Login(context.TODO(), params)
+	c.Assert(err, check.IsNil)
+	u, err := auth.GetUserByEmail(context.TODO(), "timeredbull@globo.com")
+	c.Assert(err, check.IsNil)
+	err = u.Disable(context.TODO(), scheme)
+	c.Assert(err, check.IsNil)
+	_, err = scheme.Auth(context.TODO(), "bearer "+token.GetValue())
+	c.Assert(err, check.NotNil)
+	_, err = scheme.Login(context.TODO(), params)
+	c.Assert(err, check.Equals, auth.ErrUserDisabled)
+	err = u.Enable(context.TODO())
+	c.Assert(err, check.IsNil)
+	_, err = scheme.Login(context.TODO(), params)
+	c.Assert(err, check.IsNil)
+}
+
 func (s *S) TestNativeCreateNoPassword(c *check.C) {
 	scheme := NativeScheme{}
 	user := &auth.User{Email: "x@x.com"}
@@ -0,0 +1,450 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/validation"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	trustedIssuerCollectionName = "oidc_trusted_issuers"
+
+	// WorkloadIdentityEmailDomain is the e-mail domain recorded as the
+	// creator of team tokens issued by token exchanges.
+	WorkloadIdentityEmailDomain = "tsuru-workload-identity"
+
+	defaultExchangeExpiresIn = 15 * 60
+	maxExchangeExpiresIn     = 60 * 60
+)
+
+var (
+	ErrTrustedIssuerNotFound = errors.New("trusted issuer not found")
+	ErrUntrustedIssuer       = errors.New("token issuer is not trusted")
+	ErrNoMatchingRule        = errors.New("token claims match no exchange rule")
+
+	federationKeys = &federationKeyCache{}
+)
+
+// ErrExchangeDenied is returned when a token is refused by the token exchange,
+// either because it's invalid or because no trusted issuer allows it.
+type ErrExchangeDenied struct {
+	Err error
+}
+
+func (e *ErrExchangeDenied) Error() string {
+	return fmt.Sprintf("token exchange denied: %v", e.Err)
+}
+
+func (e *ErrExchangeDenied) Unwrap() error {
+	return e.Err
+}
+
+// TrustedIssuer is an external OIDC provider, e.g. a CI system, whose tokens
+// can be exchanged for short-lived team tokens. Exchanged tokens must be
+// issued by Issuer, signed by a key served at JWKSURL and include Audience in
+// their audiences. The first rule matching the token claims defines the team
+// and roles of the team token.
+type TrustedIssuer struct {
+	Name     string         `json:"name" bson:"_id"`
+	Issuer   string         `json:"issuer"`
+	JWKSURL  string         `json:"jwks_url" bson:"jwks_url"`
+	Audience string         `json:"audience"`
+	Rules    []ExchangeRule `json:"rules"`
+}
+
+// ExchangeRule matches tokens whose claims are equal to every entry in Claims,
+// e.g. {"repository": "tsuru/tsuru", "ref": "refs/heads/main"}, issuing team
+// tokens for Team with Roles, valid for ExpiresIn seconds.
+type ExchangeRule struct {
+	Name      string                   `json:"name"`
+	Claims    map[string]string        `json:"claims"`
+	Team      string                   `json:"team"`
+	Roles     []authTypes.RoleInstance `json:"roles,omitempty"`
+	ExpiresIn int                      `json:"expires_in,omitempty" bson:"expires_in,omitempty"`
+}
+
+// Matches returns whether every claim of the rule is present in claims with
+// the same value. Non string claims are compared by their formatted value.
+func (r *ExchangeRule) Matches(claims jwt.MapClaims) bool {
+	for name, expected := range r.Claims {
+		value, ok := claims[name]
+		if !ok {
+			return false
+		}
+		str, isString := value.(string)
+		if !isString {
+			str = fmt.Sprint(value)
+		}
+		if str != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ExchangeRule) expiresIn() int {
+	if r.ExpiresIn == 0 {
+		return defaultExchangeExpiresIn
+	}
+	return r.ExpiresIn
+}
+
+func (i *TrustedIssuer) validate(ctx context.Context) error {
+	if !validation.ValidateName(i.Name) {
+		return &tsuruErrors.ValidationError{Message: "invalid trusted issuer name"}
+	}
+	if i.Issuer == "" {
+		return &tsuruErrors.ValidationError{Message: "trusted issuer URL is required"}
+	}
+	if u, err := url.Parse(i.JWKSURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return &tsuruErrors.ValidationError{Message: "trusted issuer requires a valid JWKS URL"}
+	}
+	if i.Audience == "" {
+		return &tsuruErrors.ValidationError{Message: "trusted issuer audience is required"}
+	}
+	if len(i.Rules) == 0 {
+		return &tsuruErrors.ValidationError{Message: "trusted issuer requires at least one rule"}
+	}
+	names := map[string]struct{}{}
+	for _, rule := range i.Rules {
+		if rule.Name == "" {
+			return &tsuruErrors.ValidationError{Message: "exchange rule name is required"}
+		}
+		if _, ok := names[rule.Name]; ok {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("duplicated exchange rule %q", rule.Name)}
+		}
+		names[rule.Name] = struct{}{}
+		if len(rule.Claims) == 0 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("exchange rule %q must match at least one claim", rule.Name)}
+		}
+		if rule.ExpiresIn < 0 || rule.ExpiresIn > maxExchangeExpiresIn {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("exchange rule %q expiration must be at most %d seconds", rule.Name, maxExchangeExpiresIn)}
+		}
+		_, err := servicemanager.Team.FindByName(ctx, rule.Team)
+		if err == authTypes.ErrTeamNotFound {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("exchange rule %q: team %q not found", rule.Name, rule.Team)}
+		}
+		if err != nil {
+			return err
+		}
+		for _, role := range rule.Roles {
+			_, err = permission.FindRole(ctx, role.Name)
+			if err == permTypes.ErrRoleNotFound {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("exchange rule %q: role %q not found", rule.Name, role.Name)}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SaveTrustedIssuer validates and stores i, replacing the trusted issuer with
+// the same name. Each issuer URL may be trusted only once.
+func SaveTrustedIssuer(ctx context.Context, i *TrustedIssuer) error {
+	err := i.validate(ctx)
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.Collection(trustedIssuerCollectionName)
+	if err != nil {
+		return err
+	}
+	var existing TrustedIssuer
+	err = collection.FindOne(ctx, mongoBSON.M{"issuer": i.Issuer, "_id": mongoBSON.M{"$ne": i.Name}}).Decode(&existing)
+	if err == nil {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("issuer %q is already trusted as %q", i.Issuer, existing.Name)}
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+	var previous TrustedIssuer
+	err = collection.FindOneAndReplace(ctx, mongoBSON.M{"_id": i.Name}, i, options.FindOneAndReplace().SetUpsert(true)).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if previous.JWKSURL != i.JWKSURL {
+		federationKeys.forget(previous.JWKSURL)
+	}
+	return nil
+}
+
+// RemoveTrustedIssuer removes the trusted issuer named name. Team tokens
+// already issued by its exchanges are kept until they expire.
+func RemoveTrustedIssuer(ctx context.Context, name string) error {
+	collection, err := storagev2.Collection(trustedIssuerCollectionName)
+	if err != nil {
+		return err
+	}
+	var issuer TrustedIssuer
+	err = collection.FindOneAndDelete(ctx, mongoBSON.M{"_id": name}).Decode(&issuer)
+	if err == mongo.ErrNoDocuments {
+		return ErrTrustedIssuerNotFound
+	}
+	if err != nil {
+		return err
+	}
+	federationKeys.forget(issuer.JWKSURL)
+	return nil
+}
+
+func GetTrustedIssuer(ctx context.Context, name string) (*TrustedIssuer, error) {
+	return findTrustedIssuer(ctx, mongoBSON.M{"_id": name})
+}
+
+func ListTrustedIssuers(ctx context.Context) ([]TrustedIssuer, error) {
+	collection, err := storagev2.Collection(trustedIssuerCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{}, options.Find().SetSort(mongoBSON.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var issuers []TrustedIssuer
+	err = cursor.All(ctx, &issuers)
+	if err != nil {
+		return nil, err
+	}
+	return issuers, nil
+}
+
+func findTrustedIssuer(ctx context.Context, query mongoBSON.M) (*TrustedIssuer, error) {
+	collection, err := storagev2.Collection(trustedIssuerCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	var i TrustedIssuer
+	err = collection.FindOne(ctx, query).Decode(&i)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrTrustedIssuerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// ExchangeResult holds the team token issued by a token exchange along with
+// the identity it was issued for.
+type ExchangeResult struct {
+	Token   authTypes.TeamToken
+	Issuer  string
+	Rule    string
+	Subject string
+}
+
+// Exchange validates a token issued by a trusted issuer and, when its claims
+// match one of the issuer rules, issues a short-lived team token with the
+// team and roles of the rule. Each exchange issues its own team token.
+func Exchange(ctx context.Context, rawToken string) (*ExchangeResult, error) {
+	unverified := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(rawToken, unverified)
+	if err != nil {
+		return nil, &ErrExchangeDenied{Err: err}
+	}
+	iss, _ := unverified.GetIssuer()
+	issuer, err := findTrustedIssuer(ctx, mongoBSON.M{"issuer": iss})
+	if err == ErrTrustedIssuerNotFound {
+		return nil, &ErrExchangeDenied{Err: ErrUntrustedIssuer}
+	}
+	if err != nil {
+		return nil, err
+	}
+	keyFunc, err := federationKeys.keyFunc(ctx, issuer.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(
+		jwt.WithIssuer(issuer.Issuer),
+		jwt.WithAudience(issuer.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+	)
+	_, err = parser.ParseWithClaims(rawToken, claims, keyFunc)
+	if err != nil {
+		return nil, &ErrExchangeDenied{Err: err}
+	}
+	var rule *ExchangeRule
+	for i := range issuer.Rules {
+		if issuer.Rules[i].Matches(claims) {
+			rule = &issuer.Rules[i]
+			break
+		}
+	}
+	if rule == nil {
+		return nil, &ErrExchangeDenied{Err: ErrNoMatchingRule}
+	}
+	subject, _ := claims.GetSubject()
+	token, err := exchangeToken(ctx, issuer, rule, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &ExchangeResult{
+		Token:   token,
+		Issuer:  issuer.Name,
+		Rule:    rule.Name,
+		Subject: subject,
+	}, nil
+}
+
+// exchangeTokenPrefix returns the prefix of the IDs of the team tokens issued
+// by exchanges of the same issuer, rule and subject.
+func exchangeTokenPrefix(issuer, rule, subject string) string {
+	sum := sha256.Sum256([]byte(issuer + "\x00" + rule + "\x00" + subject))
+	return fmt.Sprintf("wi-%x-", sum[:12])
+}
+
+// exchangeToken issues a new team token for subject on every exchange, valid
+// for the expiration of the rule, so an exchanged token is never extended or
+// shared with another exchange. Expired tokens of previous exchanges of the
+// same subject are removed, so exchanges don't pile up team tokens.
+func exchangeToken(ctx context.Context, issuer *TrustedIssuer, rule *ExchangeRule, subject string) (authTypes.TeamToken, error) {
+	prefix := exchangeTokenPrefix(issuer.Name, rule.Name, subject)
+	err := removeExpiredExchangeTokens(ctx, rule.Team, prefix)
+	if err != nil {
+		return authTypes.TeamToken{}, err
+	}
+	description := fmt.Sprintf("exchanged from %s token by rule %s", issuer.Name, rule.Name)
+	if subject != "" {
+		description += fmt.Sprintf(" for %s", subject)
+	}
+	for attempt := 0; ; attempt++ {
+		suffix := make([]byte, 4)
+		_, err = rand.Read(suffix)
+		if err != nil {
+			return authTypes.TeamToken{}, err
+		}
+		token, err := servicemanager.TeamToken.Create(ctx, authTypes.TeamTokenCreateArgs{
+			TokenID:     fmt.Sprintf("%s%x", prefix, suffix),
+			Team:        rule.Team,
+			Description: description,
+			ExpiresIn:   rule.expiresIn(),
+			Roles:       rule.Roles,
+		}, &workloadToken{issuer: issuer.Name})
+		if err == authTypes.ErrTeamTokenAlreadyExists && attempt == 0 {
+			continue
+		}
+		return token, err
+	}
+}
+
+func removeExpiredExchangeTokens(ctx context.Context, team, prefix string) error {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		return err
+	}
+	tokens, err := dbDriver.TeamTokenStorage.FindByTeams(ctx, []string{team})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, t := range tokens {
+		if !strings.HasPrefix(t.TokenID, prefix) || t.ExpiresAt.IsZero() || t.ExpiresAt.After(now) {
+			continue
+		}
+		err = servicemanager.TeamToken.Delete(ctx, t.TokenID)
+		if err != nil && err != authTypes.ErrTeamTokenNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// federationKeyCache holds the key sets of trusted issuers, registering their
+// JWKS URLs on first use.
+type federationKeyCache struct {
+	once  sync.Once
+	mu    sync.Mutex
+	cache *jwk.Cache
+}
+
+func (c *federationKeyCache) keyFunc(ctx context.Context, jwksURL string) (jwt.Keyfunc, error) {
+	c.once.Do(func() {
+		c.cache = jwk.NewCache(context.Background())
+	})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.cache.IsRegistered(jwksURL) {
+		err := c.cache.Register(jwksURL, jwk.WithMinRefreshInterval(jwksRefreshInterval()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jwksKeyFunc(ctx, c.cache, jwksURL), nil
+}
+
+// forget stops refreshing the key set served at jwksURL.
+func (c *federationKeyCache) forget(jwksURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cache == nil || !c.cache.IsRegistered(jwksURL) {
+		return
+	}
+	err := c.cache.Unregister(jwksURL)
+	if err != nil {
+		log.Errorf("unable to unregister JWKS URL %q: %v", jwksURL, err)
+	}
+}
+
+// workloadToken identifies a trusted issuer as the creator of the team tokens
+// issued by its exchanges.
+type workloadToken struct {
+	issuer string
+}
+
+var _ authTypes.Token = &workloadToken{}
+
+func (t *workloadToken) GetValue() string {
+	return ""
+}
+
+func (t *workloadToken) GetUserName() string {
+	return t.issuer
+}
+
+func (t *workloadToken) User(ctx context.Context) (*authTypes.User, error) {
+	return &authTypes.User{
+		Email:     fmt.Sprintf("%s@%s", t.issuer, WorkloadIdentityEmailDomain),
+		FromToken: true,
+	}, nil
+}
+
+func (t *workloadToken) Engine() string {
+	return "workload-identity"
+}
+
+func (t *workloadToken) Permissions(ctx context.Context) ([]permTypes.Permission, error) {
+	return nil, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const testIssuerURL = "https://ci.example.com"
+
+// setUpTrustedIssuer stores a trusted issuer whose keys are served at a JWKS
+// URL exclusive to the test, so the key cache is always fetched fresh.
+func (s *AuthSuite) setUpTrustedIssuer(c *check.C, name string) (*TrustedIssuer, *rsa.PrivateKey) {
+	collection, err := storagev2.Collection(trustedIssuerCollectionName)
+	c.Assert(err, check.IsNil)
+	_, err = collection.DeleteMany(context.TODO(), mongoBSON.M{})
+	c.Assert(err, check.IsNil)
+	servicemanager.Team.Remove(context.TODO(), "ci-team")
+	err = servicemanager.Team.Create(context.TODO(), "ci-team", nil, &authTypes.User{Email: "admin@company.com"})
+	c.Assert(err, check.IsNil)
+	permission.DestroyRole(context.TODO(), "ci-deployer")
+	_, err = permission.NewRole(context.TODO(), "ci-deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	key, err := s.generateNewPrivateRSAKey(name)
+	c.Assert(err, check.IsNil)
+	issuer := &TrustedIssuer{
+		Name:     name,
+		Issuer:   testIssuerURL,
+		JWKSURL:  s.fakeJWKSServer.URL + "/" + name,
+		Audience: "tsuru",
+		Rules: []ExchangeRule{
+			{
+				Name:   "main",
+				Claims: map[string]string{"repository": "tsuru/tsuru", "ref": "refs/heads/main"},
+				Team:   "ci-team",
+				Roles:  []authTypes.RoleInstance{{Name: "ci-deployer", ContextValue: "ci-team"}},
+			},
+			{
+				Name:      "any-branch",
+				Claims:    map[string]string{"repository": "tsuru/tsuru"},
+				Team:      "ci-team",
+				ExpiresIn: 60,
+			},
+		},
+	}
+	err = SaveTrustedIssuer(context.TODO(), issuer)
+	c.Assert(err, check.IsNil)
+	return issuer, key
+}
+
+func signToken(c *check.C, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	str, err := token.SignedString(key)
+	c.Assert(err, check.IsNil)
+	return str
+}
+
+func (s *AuthSuite) TestExchangeRuleMatches(c *check.C) {
+	rule := ExchangeRule{Claims: map[string]string{"repository": "tsuru/tsuru", "run_attempt": "1"}}
+	c.Assert(rule.Matches(jwt.MapClaims{"repository": "tsuru/tsuru", "run_attempt": "1", "ref": "x"}), check.Equals, true)
+	c.Assert(rule.Matches(jwt.MapClaims{"repository": "tsuru/tsuru", "run_attempt": float64(1)}), check.Equals, true)
+	c.Assert(rule.Matches(jwt.MapClaims{"repository": "tsuru/other", "run_attempt": "1"}), check.Equals, false)
+	c.Assert(rule.Matches(jwt.MapClaims{"repository": "tsuru/tsuru"}), check.Equals, false)
+}
+
+func (s *AuthSuite) TestSaveTrustedIssuer(c *check.C) {
+	issuer, _ := s.setUpTrustedIssuer(c, "ci-save")
+	dbIssuer, err := GetTrustedIssuer(context.TODO(), "ci-save")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbIssuer, check.DeepEquals, issuer)
+	issuer.Audience = "other"
+	err = SaveTrustedIssuer(context.TODO(), issuer)
+	c.Assert(err, check.IsNil)
+	issuers, err := ListTrustedIssuers(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(issuers, check.HasLen, 1)
+	c.Assert(issuers[0].Audience, check.Equals, "other")
+	duplicated := *issuer
+	duplicated.Name = "ci-duplicated"
+	err = SaveTrustedIssuer(context.TODO(), &duplicated)
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = RemoveTrustedIssuer(context.TODO(), "ci-save")
+	c.Assert(err, check.IsNil)
+	err = RemoveTrustedIssuer(context.TODO(), "ci-save")
+	c.Assert(err, check.Equals, ErrTrustedIssuerNotFound)
+}
+
+func (s *AuthSuite) TestSaveTrustedIssuerInvalid(c *check.C) {
+	valid, _ := s.setUpTrustedIssuer(c, "ci-invalid")
+	tests := []func(i *TrustedIssuer){
+		func(i *TrustedIssuer) { i.Name = "Invalid Name" },
+		func(i *TrustedIssuer) { i.Issuer = "" },
+		func(i *TrustedIssuer) { i.JWKSURL = "not a url" },
+		func(i *TrustedIssuer) { i.Audience = "" },
+		func(i *TrustedIssuer) { i.Rules = nil },
+		func(i *TrustedIssuer) { i.Rules = []ExchangeRule{{Name: "r1", Team: "ci-team"}} },
+		func(i *TrustedIssuer) { i.Rules = append(i.Rules, i.Rules[0]) },
+		func(i *TrustedIssuer) {
+			i.Rules = []ExchangeRule{{Name: "r1", Claims: map[string]string{"a": "b"}, Team: "unknown"}}
+		},
+		func(i *TrustedIssuer) {
+			i.Rules = []ExchangeRule{{Name: "r1", Claims: map[string]string{"a": "b"}, Team: "ci-team", ExpiresIn: 7200}}
+		},
+		func(i *TrustedIssuer) {
+			i.Rules = []ExchangeRule{{Name: "r1", Claims: map[string]string{"a": "b"}, Team: "ci-team", Roles: []authTypes.RoleInstance{{Name: "unknown"}}}}
+		},
+	}
+	for n, tt := range tests {
+		issuer := *valid
+		issuer.Rules = append([]ExchangeRule(nil), valid.Rules...)
+		tt(&issuer)
+		err := SaveTrustedIssuer(context.TODO(), &issuer)
+		c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{}, check.Commentf("test %d", n))
+	}
+}
+
+func (s *AuthSuite) TestExchange(c *check.C) {
+	_, key := s.setUpTrustedIssuer(c, "ci-exchange")
+	now := time.Now()
+	raw := signToken(c, key, "ci-exchange", jwt.MapClaims{
+		"iss":        testIssuerURL,
+		"aud":        "tsuru",
+		"sub":        "repo:tsuru/tsuru:ref:refs/heads/main",
+		"exp":        now.Add(5 * time.Minute).Unix(),
+		"repository": "tsuru/tsuru",
+		"ref":        "refs/heads/main",
+	})
+	result, err := Exchange(context.TODO(), raw)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Issuer, check.Equals, "ci-exchange")
+	c.Assert(result.Rule, check.Equals, "main")
+	c.Assert(result.Subject, check.Equals, "repo:tsuru/tsuru:ref:refs/heads/main")
+	c.Assert(result.Token.Team, check.Equals, "ci-team")
+	c.Assert(result.Token.CreatorEmail, check.Equals, "ci-exchange@"+WorkloadIdentityEmailDomain)
+	c.Assert(result.Token.Roles, check.DeepEquals, []authTypes.RoleInstance{{Name: "ci-deployer", ContextValue: "ci-team"}})
+	c.Assert(result.Token.ExpiresAt.Sub(now) > 14*time.Minute, check.Equals, true)
+	c.Assert(result.Token.ExpiresAt.Sub(now) <= 15*time.Minute+time.Second, check.Equals, true)
+	tsuruToken, err := servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+result.Token.Token)
+	c.Assert(err, check.IsNil)
+	c.Assert(tsuruToken.GetUserName(), check.Equals, result.Token.TokenID)
+
+	raw = signToken(c, key, "ci-exchange", jwt.MapClaims{
+		"iss":        testIssuerURL,
+		"aud":        []string{"other", "tsuru"},
+		"exp":        now.Add(5 * time.Minute).Unix(),
+		"repository": "tsuru/tsuru",
+		"ref":        "refs/heads/feature",
+	})
+	result, err = Exchange(context.TODO(), raw)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Rule, check.Equals, "any-branch")
+	c.Assert(result.Token.Roles, check.HasLen, 0)
+	c.Assert(result.Token.ExpiresAt.Sub(now) <= time.Minute+time.Second, check.Equals, true)
+}
+
+func (s *AuthSuite) TestExchangeIssuesNewTokens(c *check.C) {
+	_, key := s.setUpTrustedIssuer(c, "ci-reuse")
+	claims := jwt.MapClaims{
+		"iss":        testIssuerURL,
+		"aud":        "tsuru",
+		"sub":        "repo:tsuru/tsuru:ref:refs/heads/main",
+		"exp":        time.Now().Add(5 * time.Minute).Unix(),
+		"repository": "tsuru/tsuru",
+		"ref":        "refs/heads/main",
+	}
+	first, err := Exchange(context.TODO(), signToken(c, key, "ci-reuse", claims))
+	c.Assert(err, check.IsNil)
+	second, err := Exchange(context.TODO(), signToken(c, key, "ci-reuse", claims))
+	c.Assert(err, check.IsNil)
+	c.Assert(second.Token.TokenID, check.Not(check.Equals), first.Token.TokenID)
+	c.Assert(second.Token.Token, check.Not(check.Equals), first.Token.Token)
+	stored, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), first.Token.TokenID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.ExpiresAt.Equal(first.Token.ExpiresAt), check.Equals, true)
+	collection, err := storagev2.TeamTokensCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(context.TODO(), mongoBSON.M{"token_id": first.Token.TokenID}, mongoBSON.M{
+		"$set": mongoBSON.M{"expires_at": time.Now().Add(-time.Minute)},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = Exchange(context.TODO(), signToken(c, key, "ci-reuse", claims))
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.TeamToken.FindByTokenID(context.TODO(), first.Token.TokenID)
+	c.Assert(err, check.Equals, authTypes.ErrTeamTokenNotFound)
+	exchanged, err := collection.CountDocuments(context.TODO(), mongoBSON.M{"creator_email": "ci-reuse@" + WorkloadIdentityEmailDomain})
+	c.Assert(err, check.IsNil)
+	c.Assert(exchanged, check.Equals, int64(2))
+}
+
+func (s *AuthSuite) TestRemoveTrustedIssuerForgetsKeys(c *check.C) {
+	issuer, key := s.setUpTrustedIssuer(c, "ci-forget")
+	raw := signToken(c, key, "ci-forget", jwt.MapClaims{
+		"iss":        testIssuerURL,
+		"aud":        "tsuru",
+		"exp":        time.Now().Add(5 * time.Minute).Unix(),
+		"repository": "tsuru/tsuru",
+	})
+	_, err := Exchange(context.TODO(), raw)
+	c.Assert(err, check.IsNil)
+	c.Assert(federationKeys.cache.IsRegistered(issuer.JWKSURL), check.Equals, true)
+	err = RemoveTrustedIssuer(context.TODO(), "ci-forget")
+	c.Assert(err, check.IsNil)
+	c.Assert(federationKeys.cache.IsRegistered(issuer.JWKSURL), check.Equals, false)
+}
+
+func (s *AuthSuite) TestExchangeDenied(c *check.C) {
+	_, key := s.setUpTrustedIssuer(c, "ci-denied")
+	otherKey, err := s.generateNewPrivateRSAKey("ci-denied-other")
+	c.Assert(err, check.IsNil)
+	now := time.Now()
+	claims := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"iss":        testIssuerURL,
+			"aud":        "tsuru",
+			"exp":        now.Add(5 * time.Minute).Unix(),
+			"repository": "tsuru/tsuru",
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(claims, k)
+				continue
+			}
+			claims[k] = v
+		}
+		return claims
+	}
+	tests := []struct {
+		token    string
+		expected error
+	}{
+		{"not a token", nil},
+		{signToken(c, key, "ci-denied", claims(jwt.MapClaims{"iss": "https://untrusted.example.com"})), ErrUntrustedIssuer},
+		{signToken(c, key, "ci-denied", claims(jwt.MapClaims{"repository": "tsuru/other"})), ErrNoMatchingRule},
+		{signToken(c, key, "ci-denied", claims(jwt.MapClaims{"aud": "other"})), jwt.ErrTokenInvalidAudience},
+		{signToken(c, key, "ci-denied", claims(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()})), jwt.ErrTokenExpired},
+		{signToken(c, key, "ci-denied", claims(jwt.MapClaims{"exp": nil})), jwt.ErrTokenRequiredClaimMissing},
+		{signToken(c, otherKey, "ci-denied", claims(nil)), jwt.ErrTokenSignatureInvalid},
+	}
+	for i, tt := range tests {
+		_, err := Exchange(context.TODO(), tt.token)
+		var denied *ErrExchangeDenied
+		c.Assert(errors.As(err, &denied), check.Equals, true, check.Commentf("test %d: %v", i, err))
+		if tt.expected != nil {
+			c.Assert(errors.Is(err, tt.expected), check.Equals, true, check.Commentf("test %d: %v", i, err))
+		}
+	}
+}
@@ -219,17 +219,7 @@ func (s *oidcScheme) lazyInitialize(ctx context.Context) error {
 		s.validClaims = map[string]interface{}{}
 		internalConfig.UnmarshalConfig("auth:oidc:valid-claims", &s.validClaims)
 
-		var refreshIntervalErr error
-		var refreshInterval time.Duration
-		refreshInterval, refreshIntervalErr = config.GetDuration("auth:oidc:jwks-refresh-interval")
-		if refreshIntervalErr != nil {
-			log.Errorf(`Failed to fetch "auth:oidc:jwks-refresh-interval", falling on default setting (15m), error: %s`, refreshIntervalErr.Error())
-		}
-		if refreshInterval == 0 {
-			refreshInterval = 15 * time.Minute
-		}
-
-		err = s.cache.Register(s.jwksURL, jwk.WithMinRefreshInterval(refreshInterval))
+		err = s.cache.Register(s.jwksURL, jwk.WithMinRefreshInterval(jwksRefreshInterval()))
 		if err != nil {
 			return
 		}
@@ -241,11 +231,29 @@ func (s *oidcScheme) lazyInitialize(ctx context.Context) error {
 	return err
 }
 
+func jwksRefreshInterval() time.Duration {
+	refreshInterval, err := config.GetDuration("auth:oidc:jwks-refresh-interval")
+	if err != nil {
+		log.Errorf(`Failed to fetch "auth:oidc:jwks-refresh-interval", falling on default setting (15m), error: %s`, err.Error())
+	}
+	if refreshInterval == 0 {
+		refreshInterval = 15 * time.Minute
+	}
+	return refreshInterval
+}
+
 func (s *oidcScheme) jwtGetKey(ctx context.Context) jwt.Keyfunc {
+	return jwksKeyFunc(ctx, s.cache, s.jwksURL)
+}
+
+// jwksKeyFunc returns a jwt.Keyfunc looking up the key identified by the kid
+// header of tokens in the key set served at jwksURL, which must be registered
+// in cache.
+func jwksKeyFunc(ctx context.Context, cache *jwk.Cache, jwksURL string) jwt.Keyfunc {
 	return func(token *jwt.Token) (interface{}, error) {
 		var err error
 
-		jwkKeySet, err := s.cache.Get(ctx, s.jwksURL)
+		jwkKeySet, err := cache.Get(ctx, jwksURL)
 		if err != nil {
 			return nil, err
 		}
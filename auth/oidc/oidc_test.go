@@ -55,6 +55,8 @@ func (s *AuthSuite) SetUpSuite(c *check.C) {
 	var err error
 	servicemanager.Team, err = auth.TeamService()
 	c.Assert(err, check.IsNil)
+	servicemanager.TeamToken, err = auth.TeamTokenService()
+	c.Assert(err, check.IsNil)
 
 	err = s.scheme.lazyInitialize(context.Background())
 	c.Check(err, check.IsNil)
@@ -0,0 +1,315 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	SubjectUser  = "user"
+	SubjectToken = "token"
+	SubjectGroup = "group"
+)
+
+var ErrInvalidSubjectType = errors.New("invalid subject type, must be one of user, token or group")
+
+// PermissionGrant is a role assignment granting a permission to a subject.
+// Group is set when the assignment is inherited from one of the user groups.
+type PermissionGrant struct {
+	Role         string                `json:"role,omitempty"`
+	Permission   string                `json:"permission"`
+	ContextType  permTypes.ContextType `json:"contextType"`
+	ContextValue string                `json:"contextValue,omitempty"`
+	Group        string                `json:"group,omitempty"`
+	ExpiresAt    *time.Time            `json:"expiresAt,omitempty"`
+}
+
+// PermissionSubject is a user, team token or group holding a permission.
+type PermissionSubject struct {
+	Type   string            `json:"type"`
+	Name   string            `json:"name"`
+	Grants []PermissionGrant `json:"grants"`
+}
+
+// permissionQuery is a permission, static or dynamic, requested on a set of
+// contexts.
+type permissionQuery struct {
+	name     string
+	scheme   *permTypes.PermissionScheme
+	dynamic  bool
+	contexts []permTypes.PermissionContext
+}
+
+func newPermissionQuery(permName string, contexts []permTypes.PermissionContext) (*permissionQuery, error) {
+	if scheme, ok := permission.NewDynamic(permName); ok {
+		return &permissionQuery{name: permName, scheme: scheme, dynamic: true, contexts: contexts}, nil
+	}
+	scheme, err := permission.SafeGet(permName)
+	if err != nil {
+		return nil, &permTypes.ErrPermissionNotFound{Permission: permName}
+	}
+	return &permissionQuery{name: permName, scheme: scheme, contexts: contexts}, nil
+}
+
+func (q *permissionQuery) matches(perm permTypes.Permission) bool {
+	perms := []permTypes.Permission{perm}
+	if q.dynamic {
+		return permission.CheckDynamic(perms, q.name, q.contexts...)
+	}
+	return permission.CheckFromPermList(perms, q.scheme, q.contexts...)
+}
+
+// grantSource is a role instance along with where it was assigned from.
+type grantSource struct {
+	role  authTypes.RoleInstance
+	group string
+}
+
+// grantsFor evaluates each role instance against the query, mirroring the
+// expansion done by User.Permissions and User.DynamicPermissions. When allowed
+// isn't empty, static permissions are narrowed to it as done for team tokens.
+func (q *permissionQuery) grantsFor(ctx context.Context, roles map[string]*permission.Role, sources []grantSource, allowed []string) ([]PermissionGrant, error) {
+	now := time.Now()
+	var grants []PermissionGrant
+	for _, source := range sources {
+		if source.role.Expired(now) {
+			continue
+		}
+		role, ok := roles[source.role.Name]
+		if !ok {
+			foundRole, err := permission.FindRole(ctx, source.role.Name)
+			if err != nil && err != permTypes.ErrRoleNotFound {
+				return nil, err
+			}
+			if err == nil {
+				role = &foundRole
+			}
+			roles[source.role.Name] = role
+		}
+		if role == nil {
+			continue
+		}
+		var perms []permTypes.Permission
+		if q.dynamic {
+			perms = role.DynamicPermissionsFor(source.role.ContextValue)
+		} else {
+			perms = narrowPermissions(role.PermissionsFor(source.role.ContextValue), allowed)
+		}
+		for _, perm := range perms {
+			if !q.matches(perm) {
+				continue
+			}
+			grants = append(grants, PermissionGrant{
+				Role:         role.Name,
+				Permission:   schemeName(perm.Scheme),
+				ContextType:  perm.Context.CtxType,
+				ContextValue: perm.Context.Value,
+				Group:        source.group,
+				ExpiresAt:    source.role.ExpiresAt,
+			})
+		}
+	}
+	return grants, nil
+}
+
+func (q *permissionQuery) userGrants(ctx context.Context, roles map[string]*permission.Role, u *User, groups []authTypes.Group) ([]PermissionGrant, error) {
+	var grants []PermissionGrant
+	implicit := permTypes.Permission{
+		Scheme:  permission.PermUser,
+		Context: permission.Context(permTypes.CtxUser, u.Email),
+	}
+	if !q.dynamic && q.matches(implicit) {
+		grants = append(grants, PermissionGrant{
+			Permission:   schemeName(implicit.Scheme),
+			ContextType:  implicit.Context.CtxType,
+			ContextValue: implicit.Context.Value,
+		})
+	}
+	sources := make([]grantSource, 0, len(u.Roles))
+	for _, r := range u.Roles {
+		sources = append(sources, grantSource{role: r})
+	}
+	for _, group := range groups {
+		for _, r := range group.Roles {
+			sources = append(sources, grantSource{role: r, group: group.Name})
+		}
+	}
+	roleGrants, err := q.grantsFor(ctx, roles, sources, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(grants, roleGrants...), nil
+}
+
+func (q *permissionQuery) tokenGrants(ctx context.Context, roles map[string]*permission.Role, token *authTypes.TeamToken) ([]PermissionGrant, error) {
+	sources := make([]grantSource, 0, len(token.Roles))
+	for _, r := range token.Roles {
+		sources = append(sources, grantSource{role: r})
+	}
+	var allowed []string
+	if token.Constraints != nil {
+		allowed = token.Constraints.AllowedPermissions
+	}
+	return q.grantsFor(ctx, roles, sources, allowed)
+}
+
+func (q *permissionQuery) groupGrants(ctx context.Context, roles map[string]*permission.Role, group authTypes.Group) ([]PermissionGrant, error) {
+	sources := make([]grantSource, 0, len(group.Roles))
+	for _, r := range group.Roles {
+		sources = append(sources, grantSource{role: r, group: group.Name})
+	}
+	return q.grantsFor(ctx, roles, sources, nil)
+}
+
+func schemeName(scheme *permTypes.PermissionScheme) string {
+	name := scheme.FullName()
+	if name == "" {
+		return "*"
+	}
+	return name
+}
+
+func groupsByName(ctx context.Context, filter []string) (map[string]authTypes.Group, error) {
+	groups, err := servicemanager.AuthGroup.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]authTypes.Group, len(groups))
+	for _, g := range groups {
+		result[g.Name] = g
+	}
+	return result, nil
+}
+
+// groupsForUser filters the groups the user belongs to, either through the
+// groups in the user document or as a member kept in the group storage.
+func groupsForUser(groups map[string]authTypes.Group, u *User) []authTypes.Group {
+	var result []authTypes.Group
+	for _, name := range sortedGroupNames(groups) {
+		group := groups[name]
+		if slices.Contains(u.Groups, name) || slices.Contains(group.Members, u.Email) {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+func sortedGroupNames(groups map[string]authTypes.Group) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExplainPermission returns the role assignments granting the permission to
+// the subject on any of the contexts. The subject is not allowed to perform
+// the action when no grants are returned.
+func ExplainPermission(ctx context.Context, subjectType, subjectName, permName string, contexts ...permTypes.PermissionContext) ([]PermissionGrant, error) {
+	q, err := newPermissionQuery(permName, contexts)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[string]*permission.Role)
+	switch subjectType {
+	case SubjectUser:
+		u, err := GetUserByEmail(ctx, subjectName)
+		if err != nil {
+			return nil, err
+		}
+		groups, err := u.UserGroups()
+		if err != nil {
+			return nil, err
+		}
+		return q.userGrants(ctx, roles, u, groups)
+	case SubjectToken:
+		token, err := servicemanager.TeamToken.FindByTokenID(ctx, subjectName)
+		if err != nil {
+			return nil, err
+		}
+		return q.tokenGrants(ctx, roles, &token)
+	case SubjectGroup:
+		groups, err := groupsByName(ctx, []string{subjectName})
+		if err != nil {
+			return nil, err
+		}
+		group, ok := groups[subjectName]
+		if !ok {
+			return nil, authTypes.ErrGroupNotFound
+		}
+		return q.groupGrants(ctx, roles, group)
+	}
+	return nil, ErrInvalidSubjectType
+}
+
+// PermissionHolders returns every user, team token and group holding the
+// permission on any of the contexts.
+func PermissionHolders(ctx context.Context, permName string, contexts ...permTypes.PermissionContext) ([]PermissionSubject, error) {
+	q, err := newPermissionQuery(permName, contexts)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[string]*permission.Role)
+	groups, err := groupsByName(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var subjects []PermissionSubject
+	users, err := ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		grants, err := q.userGrants(ctx, roles, &users[i], groupsForUser(groups, &users[i]))
+		if err != nil {
+			return nil, err
+		}
+		if len(grants) > 0 {
+			subjects = append(subjects, PermissionSubject{Type: SubjectUser, Name: users[i].Email, Grants: grants})
+		}
+	}
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	tokens, err := dbDriver.TeamTokenStorage.FindByTeams(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tokens {
+		grants, err := q.tokenGrants(ctx, roles, &tokens[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(grants) > 0 {
+			subjects = append(subjects, PermissionSubject{Type: SubjectToken, Name: tokens[i].TokenID, Grants: grants})
+		}
+	}
+	for _, name := range sortedGroupNames(groups) {
+		grants, err := q.groupGrants(ctx, roles, groups[name])
+		if err != nil {
+			return nil, err
+		}
+		if len(grants) > 0 {
+			subjects = append(subjects, PermissionSubject{Type: SubjectGroup, Name: name, Grants: grants})
+		}
+	}
+	return subjects, nil
+}
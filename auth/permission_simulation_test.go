@@ -0,0 +1,176 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestExplainPermissionUser(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	reader, err := permission.NewRole(context.TODO(), "reader", "app", "")
+	c.Assert(err, check.IsNil)
+	err = reader.AddPermissions(context.TODO(), "app.read")
+	c.Assert(err, check.IsNil)
+	u := User{Email: "dev@example.com", Password: "123456", Groups: []string{"devs"}}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u.AddRole(context.TODO(), "reader", "myapp")
+	c.Assert(err, check.IsNil)
+	err = u.AddRole(context.TODO(), "deployer", "other-team")
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(context.TODO(), "devs", "deployer", "team1")
+	c.Assert(err, check.IsNil)
+	contexts := []permTypes.PermissionContext{
+		permission.Context(permTypes.CtxApp, "myapp"),
+		permission.Context(permTypes.CtxTeam, "team1"),
+	}
+	grants, err := ExplainPermission(context.TODO(), SubjectUser, u.Email, "app.deploy", contexts...)
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.DeepEquals, []PermissionGrant{
+		{Role: "deployer", Permission: "app.deploy", ContextType: permTypes.CtxTeam, ContextValue: "team1", Group: "devs"},
+	})
+	grants, err = ExplainPermission(context.TODO(), SubjectUser, u.Email, "app.read.env", contexts...)
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.DeepEquals, []PermissionGrant{
+		{Role: "reader", Permission: "app.read", ContextType: permTypes.CtxApp, ContextValue: "myapp"},
+	})
+	grants, err = ExplainPermission(context.TODO(), SubjectUser, u.Email, "app.update", contexts...)
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.HasLen, 0)
+}
+
+func (s *S) TestExplainPermissionIgnoresExpiredRoles(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	u := User{Email: "oncall@example.com", Password: "123456"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u.AddTemporaryRole(context.TODO(), "deployer", "team1", time.Now().Add(-time.Minute), "")
+	c.Assert(err, check.IsNil)
+	expiresAt := time.Now().Add(time.Hour)
+	err = u.AddTemporaryRole(context.TODO(), "deployer", "team2", expiresAt, "")
+	c.Assert(err, check.IsNil)
+	grants, err := ExplainPermission(context.TODO(), SubjectUser, u.Email, "app.deploy",
+		permission.Context(permTypes.CtxTeam, "team1"),
+		permission.Context(permTypes.CtxTeam, "team2"),
+	)
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.HasLen, 1)
+	c.Assert(grants[0].ContextValue, check.Equals, "team2")
+	c.Assert(grants[0].ExpiresAt, check.NotNil)
+}
+
+func (s *S) TestExplainPermissionTeamTokenConstraints(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "app-admin", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app")
+	c.Assert(err, check.IsNil)
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team: s.team.Name,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	err = servicemanager.TeamToken.AddRole(context.TODO(), token.TokenID, "app-admin", "team1")
+	c.Assert(err, check.IsNil)
+	ctx := permission.Context(permTypes.CtxTeam, "team1")
+	grants, err := ExplainPermission(context.TODO(), SubjectToken, token.TokenID, "app.update.env.set", ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.DeepEquals, []PermissionGrant{
+		{Role: "app-admin", Permission: "app", ContextType: permTypes.CtxTeam, ContextValue: "team1"},
+	})
+	token, err = servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:               s.team.Name,
+		Roles:              []authTypes.RoleInstance{{Name: "app-admin", ContextValue: "team1"}},
+		AllowedPermissions: []string{"app.deploy"},
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	grants, err = ExplainPermission(context.TODO(), SubjectToken, token.TokenID, "app.update.env.set", ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.HasLen, 0)
+	grants, err = ExplainPermission(context.TODO(), SubjectToken, token.TokenID, "app.deploy", ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.DeepEquals, []PermissionGrant{
+		{Role: "app-admin", Permission: "app.deploy", ContextType: permTypes.CtxTeam, ContextValue: "team1"},
+	})
+}
+
+func (s *S) TestExplainPermissionDynamic(c *check.C) {
+	createServiceWithDynamicAction(c, "acl", "rules.sync")
+	role, err := permission.NewRole(context.TODO(), "acl-operator", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddDynamicPermissions(context.TODO(), "service-action.acl")
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(context.TODO(), "sre", role.Name, "team1")
+	c.Assert(err, check.IsNil)
+	grants, err := ExplainPermission(context.TODO(), SubjectGroup, "sre", "service-action.acl.rules.sync", permission.Context(permTypes.CtxTeam, "team1"))
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.DeepEquals, []PermissionGrant{
+		{Role: "acl-operator", Permission: "service-action.acl", ContextType: permTypes.CtxTeam, ContextValue: "team1", Group: "sre"},
+	})
+	grants, err = ExplainPermission(context.TODO(), SubjectGroup, "sre", "service-action.acl.rules.sync", permission.Context(permTypes.CtxTeam, "team2"))
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.HasLen, 0)
+}
+
+func (s *S) TestExplainPermissionErrors(c *check.C) {
+	_, err := ExplainPermission(context.TODO(), SubjectUser, "nobody@example.com", "app.deploy")
+	c.Assert(err, check.Equals, authTypes.ErrUserNotFound)
+	_, err = ExplainPermission(context.TODO(), SubjectGroup, "nogroup", "app.deploy")
+	c.Assert(err, check.Equals, authTypes.ErrGroupNotFound)
+	_, err = ExplainPermission(context.TODO(), "team", "myteam", "app.deploy")
+	c.Assert(err, check.Equals, ErrInvalidSubjectType)
+	_, err = ExplainPermission(context.TODO(), SubjectUser, s.user.Email, "app.unknown")
+	c.Assert(err, check.DeepEquals, &permTypes.ErrPermissionNotFound{Permission: "app.unknown"})
+}
+
+func (s *S) TestPermissionHolders(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "deployer", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.deploy")
+	c.Assert(err, check.IsNil)
+	u1 := User{Email: "dev1@example.com", Password: "123456"}
+	err = u1.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u1.AddRole(context.TODO(), "deployer", "team1")
+	c.Assert(err, check.IsNil)
+	u2 := User{Email: "dev2@example.com", Password: "123456", Groups: []string{"devs"}}
+	err = u2.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	u3 := User{Email: "dev3@example.com", Password: "123456"}
+	err = u3.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u3.AddRole(context.TODO(), "deployer", "team2")
+	c.Assert(err, check.IsNil)
+	err = servicemanager.AuthGroup.AddRole(context.TODO(), "devs", "deployer", "team1")
+	c.Assert(err, check.IsNil)
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:  s.team.Name,
+		Roles: []authTypes.RoleInstance{{Name: "deployer", ContextValue: "team1"}},
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	subjects, err := PermissionHolders(context.TODO(), "app.deploy", permission.Context(permTypes.CtxTeam, "team1"))
+	c.Assert(err, check.IsNil)
+	teamGrant := PermissionGrant{Role: "deployer", Permission: "app.deploy", ContextType: permTypes.CtxTeam, ContextValue: "team1"}
+	groupGrant := teamGrant
+	groupGrant.Group = "devs"
+	c.Assert(subjects, check.DeepEquals, []PermissionSubject{
+		{Type: SubjectUser, Name: u1.Email, Grants: []PermissionGrant{teamGrant}},
+		{Type: SubjectUser, Name: u2.Email, Grants: []PermissionGrant{groupGrant}},
+		{Type: SubjectToken, Name: token.TokenID, Grants: []PermissionGrant{teamGrant}},
+		{Type: SubjectGroup, Name: "devs", Grants: []PermissionGrant{groupGrant}},
+	})
+}
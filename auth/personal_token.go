@@ -0,0 +1,201 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"time"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/storage"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/validation"
+)
+
+// DefaultPersonalTokenExpiration is the lifetime of personal tokens created
+// without an explicit expiration.
+const DefaultPersonalTokenExpiration = 30 * 24 * time.Hour
+
+type personalToken authTypes.PersonalToken
+
+var (
+	_ authTypes.Token      = &personalToken{}
+	_ authTypes.NamedToken = &personalToken{}
+)
+
+func (t *personalToken) GetValue() string {
+	return t.Token
+}
+
+func (t *personalToken) User(ctx context.Context) (*authTypes.User, error) {
+	return ConvertOldUser(GetUserByEmail(ctx, t.UserEmail))
+}
+
+func (t *personalToken) GetUserName() string {
+	return t.UserEmail
+}
+
+func (t *personalToken) GetTokenName() string {
+	return t.TokenID
+}
+
+func (t *personalToken) Engine() string {
+	return "personal"
+}
+
+func (t *personalToken) Permissions(ctx context.Context) ([]permTypes.Permission, error) {
+	perms, err := BaseTokenPermission(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	return narrowPermissions(perms, t.AllowedPermissions), nil
+}
+
+// DynamicPermissions returns the dynamic permissions of the token owner.
+// Tokens restricted to a set of permissions don't hold any, as they can only
+// be narrowed to static permissions.
+func (t *personalToken) DynamicPermissions(ctx context.Context) ([]permTypes.Permission, error) {
+	if len(t.AllowedPermissions) > 0 {
+		return nil, nil
+	}
+	u, err := GetUserByEmail(ctx, t.UserEmail)
+	if err != nil {
+		return nil, err
+	}
+	return u.DynamicPermissions(ctx)
+}
+
+type personalTokenService struct {
+	storage authTypes.PersonalTokenStorage
+}
+
+func PersonalTokenService() (authTypes.PersonalTokenService, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &personalTokenService{
+		storage: dbDriver.PersonalTokenStorage,
+	}, nil
+}
+
+func (s *personalTokenService) Create(ctx context.Context, email string, args authTypes.PersonalTokenCreateArgs) (authTypes.PersonalToken, error) {
+	if !validation.ValidateName(args.Name) {
+		return authTypes.PersonalToken{}, &tsuruErrors.ValidationError{Message: "invalid token name"}
+	}
+	if args.ExpiresIn < 0 {
+		return authTypes.PersonalToken{}, &tsuruErrors.ValidationError{Message: "expires_in must not be negative"}
+	}
+	for _, name := range args.AllowedPermissions {
+		if _, err := permission.SafeGet(name); err != nil || name == "" {
+			return authTypes.PersonalToken{}, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid allowed permission %q", name)}
+		}
+	}
+	_, err := GetUserByEmail(ctx, email)
+	if err != nil {
+		return authTypes.PersonalToken{}, err
+	}
+	expiration := DefaultPersonalTokenExpiration
+	if args.ExpiresIn > 0 {
+		expiration = time.Duration(args.ExpiresIn) * time.Second
+	}
+	now := time.Now().UTC()
+	token := authTypes.PersonalToken{
+		Token:              generateToken(email, crypto.SHA256),
+		Name:               args.Name,
+		Description:        args.Description,
+		UserEmail:          email,
+		CreatedAt:          now,
+		ExpiresAt:          now.Add(expiration),
+		AllowedPermissions: args.AllowedPermissions,
+	}
+	token.TokenID = fmt.Sprintf("%s-%s", token.Name, token.Token[:8])
+	err = s.storage.Insert(ctx, token)
+	return token, err
+}
+
+func (s *personalTokenService) List(ctx context.Context, email string) ([]authTypes.PersonalToken, error) {
+	tokens, err := s.storage.FindByUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tokens {
+		tokens[i].Token = ""
+	}
+	return tokens, nil
+}
+
+func (s *personalTokenService) FindByTokenID(ctx context.Context, tokenID string) (authTypes.PersonalToken, error) {
+	token, err := s.storage.FindByTokenID(ctx, tokenID)
+	if err != nil {
+		return authTypes.PersonalToken{}, err
+	}
+	token.Token = ""
+	return *token, nil
+}
+
+func (s *personalTokenService) Delete(ctx context.Context, tokenID string) error {
+	return s.storage.Delete(ctx, tokenID)
+}
+
+func (s *personalTokenService) DeleteByUser(ctx context.Context, email string) error {
+	return s.storage.DeleteByUser(ctx, email)
+}
+
+func (s *personalTokenService) Authenticate(ctx context.Context, header string) (authTypes.Token, error) {
+	tokenStr, err := ParseToken(header)
+	if err != nil {
+		return nil, err
+	}
+	storedToken, err := s.storage.FindByToken(ctx, tokenStr)
+	if err != nil {
+		if err == authTypes.ErrPersonalTokenNotFound {
+			err = ErrInvalidToken
+		}
+		return nil, err
+	}
+	if !storedToken.ExpiresAt.IsZero() && storedToken.ExpiresAt.Before(time.Now()) {
+		return nil, authTypes.ErrPersonalTokenExpired
+	}
+	u, err := GetUserByEmail(ctx, storedToken.UserEmail)
+	if err != nil {
+		if err == authTypes.ErrUserNotFound {
+			err = ErrInvalidToken
+		}
+		return nil, err
+	}
+	if u.Disabled {
+		return nil, ErrUserDisabled
+	}
+	err = s.storage.UpdateLastAccess(ctx, tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	token := personalToken(*storedToken)
+	return &token, nil
+}
+
+func (s *personalTokenService) NotifyExpiring(ctx context.Context, window time.Duration, notify func(authTypes.PersonalToken)) error {
+	tokens, err := s.storage.FindExpiring(ctx, time.Now().UTC().Add(window))
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		err = s.storage.SetExpiryNotified(ctx, token.TokenID)
+		if err != nil {
+			return err
+		}
+		token.Token = ""
+		notify(token)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"time"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPersonalTokenCreate(c *check.C) {
+	token, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{
+		Name:        "ci",
+		Description: "deploys from ci",
+		ExpiresIn:   3600,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Token, check.Not(check.Equals), "")
+	c.Assert(token.TokenID, check.Matches, `ci-\w{8}`)
+	c.Assert(token.UserEmail, check.Equals, s.user.Email)
+	c.Assert(token.ExpiresAt.Sub(token.CreatedAt), check.Equals, time.Hour)
+	tokens, err := servicemanager.PersonalToken.List(context.TODO(), s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 1)
+	c.Assert(tokens[0].TokenID, check.Equals, token.TokenID)
+	c.Assert(tokens[0].Token, check.Equals, "")
+	_, err = servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "ci"})
+	c.Assert(err, check.Equals, authTypes.ErrPersonalTokenAlreadyExists)
+}
+
+func (s *S) TestPersonalTokenCreateDefaultExpiration(c *check.C) {
+	token, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "ci"})
+	c.Assert(err, check.IsNil)
+	c.Assert(token.ExpiresAt.Sub(token.CreatedAt), check.Equals, DefaultPersonalTokenExpiration)
+}
+
+func (s *S) TestPersonalTokenCreateInvalid(c *check.C) {
+	_, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "Not Valid"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	_, err = servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "ci", ExpiresIn: -1})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	_, err = servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "ci", AllowedPermissions: []string{"app.unknown"}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	_, err = servicemanager.PersonalToken.Create(context.TODO(), "nobody@example.com", authTypes.PersonalTokenCreateArgs{Name: "ci"})
+	c.Assert(err, check.Equals, authTypes.ErrUserNotFound)
+}
+
+func (s *S) TestPersonalTokenAuthenticate(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "app-admin", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app")
+	c.Assert(err, check.IsNil)
+	err = s.user.AddRole(context.TODO(), "app-admin", "myteam")
+	c.Assert(err, check.IsNil)
+	token, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{
+		Name:               "deploy",
+		AllowedPermissions: []string{"app.deploy"},
+	})
+	c.Assert(err, check.IsNil)
+	t, err := servicemanager.PersonalToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.GetUserName(), check.Equals, s.user.Email)
+	c.Assert(t.Engine(), check.Equals, "personal")
+	u, err := t.User(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(u.Email, check.Equals, s.user.Email)
+	teamCtx := permission.Context(permTypes.CtxTeam, "myteam")
+	c.Assert(permission.Check(context.TODO(), t, permission.PermAppDeploy, teamCtx), check.Equals, true)
+	c.Assert(permission.Check(context.TODO(), t, permission.PermAppUpdateEnvSet, teamCtx), check.Equals, false)
+	dynamicPerms, err := BaseTokenDynamicPermission(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	c.Assert(dynamicPerms, check.HasLen, 0)
+	stored, err := servicemanager.PersonalToken.FindByTokenID(context.TODO(), token.TokenID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.LastAccess.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestPersonalTokenAuthenticateRejected(c *check.C) {
+	_, err := servicemanager.PersonalToken.Authenticate(context.TODO(), "bearer unknown")
+	c.Assert(err, check.Equals, ErrInvalidToken)
+	token, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "short", ExpiresIn: 1})
+	c.Assert(err, check.IsNil)
+	svc := servicemanager.PersonalToken.(*personalTokenService)
+	stored, err := svc.storage.FindByTokenID(context.TODO(), token.TokenID)
+	c.Assert(err, check.IsNil)
+	err = svc.storage.Delete(context.TODO(), token.TokenID)
+	c.Assert(err, check.IsNil)
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	err = svc.storage.Insert(context.TODO(), *stored)
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.PersonalToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.Equals, authTypes.ErrPersonalTokenExpired)
+	token, err = servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "ci"})
+	c.Assert(err, check.IsNil)
+	err = s.user.Disable(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.PersonalToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.Equals, ErrUserDisabled)
+}
+
+func (s *S) TestPersonalTokenNotifyExpiring(c *check.C) {
+	soon, err := servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "soon", ExpiresIn: 3600})
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.PersonalToken.Create(context.TODO(), s.user.Email, authTypes.PersonalTokenCreateArgs{Name: "later"})
+	c.Assert(err, check.IsNil)
+	var notified []string
+	notify := func(t authTypes.PersonalToken) {
+		notified = append(notified, t.TokenID)
+	}
+	err = servicemanager.PersonalToken.NotifyExpiring(context.TODO(), 24*time.Hour, notify)
+	c.Assert(err, check.IsNil)
+	c.Assert(notified, check.DeepEquals, []string{soon.TokenID})
+	err = servicemanager.PersonalToken.NotifyExpiring(context.TODO(), 24*time.Hour, notify)
+	c.Assert(err, check.IsNil)
+	c.Assert(notified, check.HasLen, 1)
+}
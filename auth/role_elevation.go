@@ -0,0 +1,107 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrRoleAlreadyAssigned = errors.New("role is already permanently assigned to the user in this context")
+
+// ExpiredRole is a temporary role assignment removed from a user.
+type ExpiredRole struct {
+	Email string
+	Role  authTypes.RoleInstance
+}
+
+// AddTemporaryRole assigns a role to the user until expiresAt, replacing any
+// temporary assignment of the same role and context.
+func (u *User) AddTemporaryRole(ctx context.Context, roleName, contextValue string, expiresAt time.Time, justification string) error {
+	_, err := permission.FindRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	for _, r := range u.Roles {
+		if r.Name == roleName && r.ContextValue == contextValue && r.ExpiresAt == nil {
+			return ErrRoleAlreadyAssigned
+		}
+	}
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return err
+	}
+	_, err = usersCollection.UpdateOne(ctx, mongoBSON.M{"email": u.Email}, mongoBSON.M{
+		"$pull": mongoBSON.M{
+			"roles": mongoBSON.M{
+				"name":         roleName,
+				"contextvalue": contextValue,
+				"expiresat":    mongoBSON.M{"$exists": true},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	expiresAt = expiresAt.UTC().Truncate(time.Millisecond)
+	_, err = usersCollection.UpdateOne(ctx, mongoBSON.M{"email": u.Email}, mongoBSON.M{
+		"$push": mongoBSON.M{
+			"roles": authTypes.RoleInstance{
+				Name:          roleName,
+				ContextValue:  contextValue,
+				ExpiresAt:     &expiresAt,
+				Justification: justification,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return u.reload(ctx)
+}
+
+// RemoveExpiredRoles removes every temporary role assignment that expired
+// before now, returning the removed assignments. Assignments removed
+// concurrently by another caller aren't returned.
+func RemoveExpiredRoles(ctx context.Context, now time.Time) ([]ExpiredRole, error) {
+	usersCollection, err := storagev2.UsersCollection()
+	if err != nil {
+		return nil, err
+	}
+	users, err := listUsers(ctx, mongoBSON.M{"roles.expiresat": mongoBSON.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+	var removed []ExpiredRole
+	for _, u := range users {
+		for _, r := range u.Roles {
+			if !r.Expired(now) {
+				continue
+			}
+			result, err := usersCollection.UpdateOne(ctx, mongoBSON.M{"email": u.Email}, mongoBSON.M{
+				"$pull": mongoBSON.M{
+					"roles": mongoBSON.M{
+						"name":         r.Name,
+						"contextvalue": r.ContextValue,
+						"expiresat":    r.ExpiresAt,
+					},
+				},
+			})
+			if err != nil {
+				return removed, err
+			}
+			if result.ModifiedCount > 0 {
+				removed = append(removed, ExpiredRole{Email: u.Email, Role: r})
+			}
+		}
+	}
+	return removed, nil
+}
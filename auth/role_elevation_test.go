@@ -0,0 +1,98 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddTemporaryRole(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "app-admin", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.update")
+	c.Assert(err, check.IsNil)
+	u := User{Email: "oncall@example.com", Password: "123456"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	expiresAt := time.Now().Add(2 * time.Hour)
+	err = u.AddTemporaryRole(context.TODO(), "app-admin", "myteam", expiresAt, "incident 42")
+	c.Assert(err, check.IsNil)
+	c.Assert(u.Roles, check.HasLen, 1)
+	c.Assert(u.Roles[0].Name, check.Equals, "app-admin")
+	c.Assert(u.Roles[0].Justification, check.Equals, "incident 42")
+	c.Assert(u.Roles[0].ExpiresAt.Sub(expiresAt) < time.Millisecond, check.Equals, true)
+	perms, err := u.Permissions(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppUpdate, permission.Context(permTypes.CtxTeam, "myteam")), check.Equals, true)
+
+	err = u.AddTemporaryRole(context.TODO(), "app-admin", "myteam", expiresAt.Add(time.Hour), "")
+	c.Assert(err, check.IsNil)
+	c.Assert(u.Roles, check.HasLen, 1)
+	c.Assert(u.Roles[0].ExpiresAt.After(expiresAt), check.Equals, true)
+
+	err = u.RemoveRole(context.TODO(), "app-admin", "myteam")
+	c.Assert(err, check.IsNil)
+	c.Assert(u.Roles, check.HasLen, 0)
+}
+
+func (s *S) TestAddTemporaryRolePermanentlyAssigned(c *check.C) {
+	_, err := permission.NewRole(context.TODO(), "app-admin", "team", "")
+	c.Assert(err, check.IsNil)
+	u := User{Email: "oncall@example.com", Password: "123456"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u.AddRole(context.TODO(), "app-admin", "myteam")
+	c.Assert(err, check.IsNil)
+	err = u.AddTemporaryRole(context.TODO(), "app-admin", "myteam", time.Now().Add(time.Hour), "")
+	c.Assert(err, check.Equals, ErrRoleAlreadyAssigned)
+}
+
+func (s *S) TestExpiredRoleGrantsNoPermissions(c *check.C) {
+	role, err := permission.NewRole(context.TODO(), "app-admin", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions(context.TODO(), "app.update")
+	c.Assert(err, check.IsNil)
+	u := User{Email: "oncall@example.com", Password: "123456"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u.AddTemporaryRole(context.TODO(), "app-admin", "myteam", time.Now().Add(-time.Minute), "")
+	c.Assert(err, check.IsNil)
+	perms, err := u.Permissions(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppUpdate, permission.Context(permTypes.CtxTeam, "myteam")), check.Equals, false)
+}
+
+func (s *S) TestRemoveExpiredRoles(c *check.C) {
+	_, err := permission.NewRole(context.TODO(), "app-admin", "team", "")
+	c.Assert(err, check.IsNil)
+	u := User{Email: "oncall@example.com", Password: "123456"}
+	err = u.Create(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = u.AddRole(context.TODO(), "app-admin", "team1")
+	c.Assert(err, check.IsNil)
+	err = u.AddTemporaryRole(context.TODO(), "app-admin", "team2", time.Now().Add(-time.Minute), "incident 42")
+	c.Assert(err, check.IsNil)
+	err = u.AddTemporaryRole(context.TODO(), "app-admin", "team3", time.Now().Add(time.Hour), "")
+	c.Assert(err, check.IsNil)
+	removed, err := RemoveExpiredRoles(context.TODO(), time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(removed, check.HasLen, 1)
+	c.Assert(removed[0].Email, check.Equals, u.Email)
+	c.Assert(removed[0].Role.ContextValue, check.Equals, "team2")
+	c.Assert(removed[0].Role.Justification, check.Equals, "incident 42")
+	err = u.reload(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(u.Roles, check.HasLen, 2)
+	c.Assert(u.Roles[0].ContextValue, check.Equals, "team1")
+	c.Assert(u.Roles[1].ContextValue, check.Equals, "team3")
+	removed, err = RemoveExpiredRoles(context.TODO(), time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(removed, check.HasLen, 0)
+}
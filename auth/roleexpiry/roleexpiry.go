@@ -0,0 +1,98 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package roleexpiry runs the worker revoking temporary role assignments once
+// they expire.
+package roleexpiry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const EventKind = "role expire"
+
+var runInterval = time.Minute
+
+func Initialize() error {
+	w := &worker{once: &sync.Once{}}
+	w.start()
+	shutdown.Register(w)
+	return nil
+}
+
+type worker struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (w *worker) start() {
+	w.once.Do(func() {
+		w.stopCh = make(chan struct{})
+		go w.spin()
+	})
+}
+
+func (w *worker) Shutdown(ctx context.Context) error {
+	if w.stopCh == nil {
+		return nil
+	}
+	w.stopCh <- struct{}{}
+	w.stopCh = nil
+	w.once = &sync.Once{}
+	return nil
+}
+
+func (w *worker) spin() {
+	for {
+		err := RevokeExpired(context.Background())
+		if err != nil {
+			log.Errorf("[role expiry] %v", err)
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-time.After(runInterval):
+		}
+	}
+}
+
+// RevokeExpired removes the expired temporary role assignments, recording an
+// event on the role for each one.
+func RevokeExpired(ctx context.Context) error {
+	removed, err := auth.RemoveExpiredRoles(ctx, time.Now())
+	for _, r := range removed {
+		recordExpiry(ctx, r)
+	}
+	return err
+}
+
+func recordExpiry(ctx context.Context, r auth.ExpiredRole) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeRole, Value: r.Role.Name},
+		InternalKind: EventKind,
+		CustomData: map[string]interface{}{
+			"email":         r.Email,
+			"context":       r.Role.ContextValue,
+			"expiresAt":     r.Role.ExpiresAt,
+			"justification": r.Role.Justification,
+		},
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		log.Errorf("[role expiry] unable to record expiry of role %q for %q: %v", r.Role.Name, r.Email, err)
+		return
+	}
+	evt.Done(ctx, nil)
+}
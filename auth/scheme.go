@@ -36,6 +36,13 @@ type ManagedScheme interface {
 	ChangePassword(ctx context.Context, token Token, oldPassword string, newPassword string) error
 }
 
+// ProvisionedScheme is implemented by schemes keeping sessions which must be
+// dropped once an identity provider deactivates the user.
+type ProvisionedScheme interface {
+	Scheme
+	Deactivate(ctx context.Context, user *User) error
+}
+
 type AuthenticationFailure struct {
 	Message string
 }
@@ -42,6 +42,8 @@ func (s *S) SetUpSuite(c *check.C) {
 
 	servicemanager.TeamToken, err = TeamTokenService()
 	c.Assert(err, check.IsNil)
+	servicemanager.PersonalToken, err = PersonalTokenService()
+	c.Assert(err, check.IsNil)
 	servicemanager.Team, err = TeamService()
 	c.Assert(err, check.IsNil)
 	servicemanager.AuthGroup, err = GroupService()
@@ -11,14 +11,12 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
-	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/storage"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	"github.com/tsuru/tsuru/types/quota"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	serviceTypes "github.com/tsuru/tsuru/types/service"
 )
 
 // for some compatibility reasons the name of team must be compliant on some cloud providers
@@ -114,58 +112,31 @@ func (t *teamService) FindByNames(ctx context.Context, names []string) ([]authTy
 }
 
 func (t *teamService) Remove(ctx context.Context, teamName string) error {
-	appsCollection, err := storagev2.AppsCollection()
+	dbDriver, err := storage.GetCurrentDbDriver()
 	if err != nil {
-		return err
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return err
+		}
 	}
-	result, err := appsCollection.Distinct(ctx, "name", mongoBSON.M{"teams": teamName})
+	apps, err := dbDriver.AppStorage.FindNamesByTeam(ctx, teamName)
 	if err != nil {
 		return err
 	}
-	if len(result) > 0 {
-		var apps []string
-
-		for _, app := range result {
-			appStr, ok := app.(string)
-			if ok {
-				apps = append(apps, appStr)
-			}
-		}
-
+	if len(apps) > 0 {
 		return &authTypes.ErrTeamStillUsed{Apps: apps}
 	}
 
-	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
-	if err != nil {
-		return err
-	}
-
-	cursor, err := serviceInstancesCollection.Find(ctx, mongoBSON.M{"teams": teamName}, &options.FindOptions{
-		Projection: mongoBSON.M{"name": 1, "service_name": 1},
+	serviceInstances, err := dbDriver.ServiceInstanceStorage.FindAll(ctx, serviceTypes.ServiceInstanceFilter{
+		Teams: []string{teamName},
 	})
 	if err != nil {
 		return err
 	}
-
-	type serviceInstance struct {
-		Name        string `bson:"name"`
-		ServiceName string `bson:"service_name"`
-	}
-
-	var (
-		serviceInstanceNames []string
-		serviceInstances     []serviceInstance
-	)
-
-	err = cursor.All(ctx, &serviceInstances)
-	if err != nil {
-		return err
-	}
-
+	var serviceInstanceNames []string
 	for _, si := range serviceInstances {
 		serviceInstanceNames = append(serviceInstanceNames, si.ServiceName+"/"+si.Name)
 	}
-
 	if len(serviceInstanceNames) > 0 {
 		return &authTypes.ErrTeamStillUsed{ServiceInstances: serviceInstanceNames}
 	}
@@ -29,8 +29,9 @@ func IsEmailFromTeamToken(email string) bool {
 type teamToken authTypes.TeamToken
 
 var (
-	_ authTypes.Token      = &teamToken{}
-	_ authTypes.NamedToken = &teamToken{}
+	_ authTypes.Token            = &teamToken{}
+	_ authTypes.NamedToken       = &teamToken{}
+	_ authTypes.ConstrainedToken = &teamToken{}
 )
 
 func (t *teamToken) GetValue() string {
@@ -58,8 +59,23 @@ func (t *teamToken) Engine() string {
 	return "team"
 }
 
+func (t *teamToken) TeamName() string {
+	return t.Team
+}
+
+func (t *teamToken) TokenConstraints() *authTypes.TeamTokenConstraints {
+	return t.Constraints
+}
+
 func (t *teamToken) Permissions(ctx context.Context) ([]permTypes.Permission, error) {
-	return expandRolePermissions(ctx, t.Roles)
+	perms, err := expandRolePermissions(ctx, t.Roles)
+	if err != nil {
+		return nil, err
+	}
+	if t.Constraints != nil {
+		perms = narrowPermissions(perms, t.Constraints.AllowedPermissions)
+	}
+	return perms, nil
 }
 
 type teamTokenService struct {
@@ -95,6 +111,10 @@ func (s *teamTokenService) Authenticate(ctx context.Context, header string) (aut
 	if !storedToken.ExpiresAt.IsZero() && storedToken.ExpiresAt.Before(now) {
 		return nil, authTypes.ErrTeamTokenExpired
 	}
+	err = checkTeamTokenConstraints(ctx, storedToken)
+	if err != nil {
+		return nil, err
+	}
 	err = s.storage.UpdateLastAccess(ctx, tokenStr)
 	if err != nil {
 		return nil, err
@@ -132,6 +152,15 @@ func (s *teamTokenService) Create(ctx context.Context, args authTypes.TeamTokenC
 	if err != nil {
 		return authTypes.TeamToken{}, err
 	}
+	constraints := &authTypes.TeamTokenConstraints{
+		AllowedCIDRs:       args.AllowedCIDRs,
+		AllowedPermissions: args.AllowedPermissions,
+		RateLimit:          args.RateLimit,
+	}
+	err = validateTeamTokenConstraints(constraints)
+	if err != nil {
+		return authTypes.TeamToken{}, err
+	}
 	now := time.Now().UTC()
 	resultToken := authTypes.TeamToken{
 		Token:        generateToken(args.Team, crypto.SHA256),
@@ -140,6 +169,8 @@ func (s *teamTokenService) Create(ctx context.Context, args authTypes.TeamTokenC
 		Team:         args.Team,
 		CreatedAt:    now,
 		CreatorEmail: u.Email,
+		Roles:        args.Roles,
+		Constraints:  normalizeTeamTokenConstraints(constraints),
 	}
 	if args.ExpiresIn != 0 {
 		resultToken.ExpiresAt = now.Add(time.Duration(args.ExpiresIn) * time.Second)
@@ -233,6 +264,10 @@ func (s *teamTokenService) Update(ctx context.Context, args authTypes.TeamTokenU
 	if args.Regenerate {
 		token.Token = generateToken(token.Team, crypto.SHA256)
 	}
+	token.Constraints, err = updatedConstraints(token.Constraints, args)
+	if err != nil {
+		return authTypes.TeamToken{}, err
+	}
 	err = s.storage.Update(ctx, *token)
 	if err != nil {
 		return authTypes.TeamToken{}, err
@@ -251,6 +286,29 @@ func (s *teamTokenService) Update(ctx context.Context, args authTypes.TeamTokenU
 	return *token, nil
 }
 
+func updatedConstraints(current *authTypes.TeamTokenConstraints, args authTypes.TeamTokenUpdateArgs) (*authTypes.TeamTokenConstraints, error) {
+	c := authTypes.TeamTokenConstraints{}
+	if current != nil && !args.ClearConstraints {
+		c = *current
+	}
+	if len(args.AllowedCIDRs) > 0 {
+		c.AllowedCIDRs = args.AllowedCIDRs
+	}
+	if len(args.AllowedPermissions) > 0 {
+		c.AllowedPermissions = args.AllowedPermissions
+	}
+	if args.RateLimit > 0 {
+		c.RateLimit = args.RateLimit
+	} else if args.RateLimit < 0 {
+		c.RateLimit = 0
+	}
+	err := validateTeamTokenConstraints(&c)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeTeamTokenConstraints(&c), nil
+}
+
 func (s *teamTokenService) Info(ctx context.Context, tokenID string, t authTypes.Token) (authTypes.TeamToken, error) {
 	token, err := s.storage.FindByTokenID(ctx, tokenID)
 	if err != nil {
@@ -0,0 +1,211 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+type sourceAddrKey struct{}
+
+// WithSourceAddr returns a copy of ctx carrying the address of the client
+// being authenticated, which is checked against the allowed CIDRs of team
+// tokens.
+func WithSourceAddr(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, sourceAddrKey{}, addr)
+}
+
+func sourceIP(ctx context.Context) net.IP {
+	addr, _ := ctx.Value(sourceAddrKey{}).(string)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// parseCIDR parses a CIDR, or a single address which is turned into a CIDR
+// matching only itself.
+func parseCIDR(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address %q", value)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, ipNet, err := net.ParseCIDR(value)
+	return ipNet, err
+}
+
+func validateTeamTokenConstraints(c *authTypes.TeamTokenConstraints) error {
+	if c == nil {
+		return nil
+	}
+	for _, cidr := range c.AllowedCIDRs {
+		if _, err := parseCIDR(cidr); err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid allowed CIDR: %v", err)}
+		}
+	}
+	for _, name := range c.AllowedPermissions {
+		if _, err := permission.SafeGet(name); err != nil || name == "" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid allowed permission %q", name)}
+		}
+	}
+	if c.RateLimit < 0 {
+		return &tsuruErrors.ValidationError{Message: "rate limit must not be negative"}
+	}
+	return nil
+}
+
+func normalizeTeamTokenConstraints(c *authTypes.TeamTokenConstraints) *authTypes.TeamTokenConstraints {
+	if c.IsEmpty() {
+		return nil
+	}
+	return c
+}
+
+func allowsSource(c *authTypes.TeamTokenConstraints, ip net.IP) bool {
+	if len(c.AllowedCIDRs) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range c.AllowedCIDRs {
+		ipNet, err := parseCIDR(cidr)
+		if err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// narrowPermissions restricts perms to the allowed schemes and their
+// children, keeping the contexts granted by the roles.
+func narrowPermissions(perms []permTypes.Permission, allowed []string) []permTypes.Permission {
+	if len(allowed) == 0 {
+		return perms
+	}
+	var result []permTypes.Permission
+	for _, name := range allowed {
+		scheme, err := permission.SafeGet(name)
+		if err != nil {
+			continue
+		}
+		for _, p := range perms {
+			switch {
+			case scheme.IsParent(p.Scheme):
+				result = append(result, p)
+			case p.Scheme.IsParent(scheme):
+				result = append(result, permTypes.Permission{Scheme: scheme, Context: p.Context})
+			}
+		}
+	}
+	return result
+}
+
+// rateLimiter counts the requests made by each token in fixed one minute
+// windows. Counters are kept in memory, so limits are enforced by each API
+// instance independently.
+type rateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+var teamTokenRateLimiter = &rateLimiter{windows: map[string]*rateWindow{}}
+
+// allow returns whether a new request by key is within limit.
+func (l *rateLimiter) allow(key string, limit int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w := l.windows[key]
+	if w == nil || now.Sub(w.start) >= time.Minute {
+		for k, old := range l.windows {
+			if now.Sub(old.start) >= time.Minute {
+				delete(l.windows, k)
+			}
+		}
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= limit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// rejectionTracker remembers when each token was first rejected for each
+// reason in fixed one minute windows, so only the first rejection in a
+// window is logged.
+type rejectionTracker struct {
+	mu      sync.Mutex
+	windows map[string]time.Time
+}
+
+var teamTokenRejections = &rejectionTracker{windows: map[string]time.Time{}}
+
+// first returns whether key wasn't rejected yet in the current window.
+func (t *rejectionTracker) first(key string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start, ok := t.windows[key]
+	if ok && now.Sub(start) < time.Minute {
+		return false
+	}
+	for k, old := range t.windows {
+		if now.Sub(old) >= time.Minute {
+			delete(t.windows, k)
+		}
+	}
+	t.windows[key] = now
+	return true
+}
+
+// NewTeamTokenRejection returns the rejection of a team token for reason,
+// flagged as repeated when the token was already rejected for the same reason
+// in the current window.
+func NewTeamTokenRejection(tokenID, team, reason string) *authTypes.ErrTeamTokenRejected {
+	return &authTypes.ErrTeamTokenRejected{
+		TokenID:  tokenID,
+		Team:     team,
+		Reason:   reason,
+		Repeated: !teamTokenRejections.first(tokenID+"\x00"+reason, time.Now()),
+	}
+}
+
+func checkTeamTokenConstraints(ctx context.Context, t *authTypes.TeamToken) error {
+	c := t.Constraints
+	if c.IsEmpty() {
+		return nil
+	}
+	if !allowsSource(c, sourceIP(ctx)) {
+		return NewTeamTokenRejection(t.TokenID, t.Team, authTypes.TeamTokenRejectedSource)
+	}
+	if c.RateLimit > 0 && !teamTokenRateLimiter.allow(t.TokenID, c.RateLimit, time.Now()) {
+		return NewTeamTokenRejection(t.TokenID, t.Team, authTypes.TeamTokenRejectedRateLimit)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) Test_TeamTokenService_Create_WithConstraints(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:               s.team.Name,
+		AllowedCIDRs:       []string{"10.0.0.0/8", "192.168.1.10"},
+		AllowedPermissions: []string{"app.deploy"},
+		RateLimit:          10,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	expected := &authTypes.TeamTokenConstraints{
+		AllowedCIDRs:       []string{"10.0.0.0/8", "192.168.1.10"},
+		AllowedPermissions: []string{"app.deploy"},
+		RateLimit:          10,
+	}
+	c.Assert(token.Constraints, check.DeepEquals, expected)
+	dbToken, err := servicemanager.TeamToken.FindByTokenID(context.TODO(), token.TokenID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbToken.Constraints, check.DeepEquals, expected)
+}
+
+func (s *S) Test_TeamTokenService_Create_InvalidConstraints(c *check.C) {
+	tests := []authTypes.TeamTokenCreateArgs{
+		{Team: s.team.Name, AllowedCIDRs: []string{"10.0.0.0/33"}},
+		{Team: s.team.Name, AllowedCIDRs: []string{"not-an-ip"}},
+		{Team: s.team.Name, AllowedPermissions: []string{"app.unknown"}},
+		{Team: s.team.Name, RateLimit: -1},
+	}
+	for i, args := range tests {
+		_, err := servicemanager.TeamToken.Create(context.TODO(), args, &userToken{user: s.user})
+		c.Assert(err, check.NotNil, check.Commentf("test %d", i))
+	}
+}
+
+func (s *S) Test_TeamTokenService_Authenticate_AllowedCIDRs(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:         s.team.Name,
+		AllowedCIDRs: []string{"10.0.0.0/8", "2001:db8::/32"},
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	for _, addr := range []string{"10.1.2.3:4567", "[2001:db8::1]:80", "10.0.0.1"} {
+		_, err = servicemanager.TeamToken.Authenticate(WithSourceAddr(context.TODO(), addr), "bearer "+token.Token)
+		c.Assert(err, check.IsNil, check.Commentf("address %s", addr))
+	}
+	for i, addr := range []string{"192.168.0.1:4567", ""} {
+		_, err = servicemanager.TeamToken.Authenticate(WithSourceAddr(context.TODO(), addr), "bearer "+token.Token)
+		c.Assert(err, check.DeepEquals, &authTypes.ErrTeamTokenRejected{
+			TokenID:  token.TokenID,
+			Team:     s.team.Name,
+			Reason:   authTypes.TeamTokenRejectedSource,
+			Repeated: i > 0,
+		}, check.Commentf("address %s", addr))
+	}
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.FitsTypeOf, &authTypes.ErrTeamTokenRejected{})
+}
+
+func (s *S) Test_TeamTokenService_Authenticate_RateLimit(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:      s.team.Name,
+		RateLimit: 2,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	for i := 0; i < 2; i++ {
+		_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+		c.Assert(err, check.IsNil)
+	}
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.DeepEquals, &authTypes.ErrTeamTokenRejected{
+		TokenID: token.TokenID,
+		Team:    s.team.Name,
+		Reason:  authTypes.TeamTokenRejectedRateLimit,
+	})
+	_, err = servicemanager.TeamToken.Authenticate(context.TODO(), "bearer "+token.Token)
+	c.Assert(err, check.DeepEquals, &authTypes.ErrTeamTokenRejected{
+		TokenID:  token.TokenID,
+		Team:     s.team.Name,
+		Reason:   authTypes.TeamTokenRejectedRateLimit,
+		Repeated: true,
+	})
+}
+
+func (s *S) Test_TeamTokenService_Update_Constraints(c *check.C) {
+	token, err := servicemanager.TeamToken.Create(context.TODO(), authTypes.TeamTokenCreateArgs{
+		Team:         s.team.Name,
+		AllowedCIDRs: []string{"10.0.0.0/8"},
+		RateLimit:    10,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	updated, err := servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID:            token.TokenID,
+		AllowedPermissions: []string{"app.deploy"},
+		RateLimit:          -1,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Constraints, check.DeepEquals, &authTypes.TeamTokenConstraints{
+		AllowedCIDRs:       []string{"10.0.0.0/8"},
+		AllowedPermissions: []string{"app.deploy"},
+	})
+	_, err = servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID:      token.TokenID,
+		AllowedCIDRs: []string{"invalid"},
+	}, &userToken{user: s.user})
+	c.Assert(err, check.NotNil)
+	updated, err = servicemanager.TeamToken.Update(context.TODO(), authTypes.TeamTokenUpdateArgs{
+		TokenID:          token.TokenID,
+		ClearConstraints: true,
+	}, &userToken{user: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Constraints, check.IsNil)
+}
+
+func (s *S) Test_TeamToken_Permissions_AllowedPermissions(c *check.C) {
+	r1, err := permission.NewRole(context.TODO(), "app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	err = r1.AddPermissions(context.TODO(), "app")
+	c.Assert(err, check.IsNil)
+	r2, err := permission.NewRole(context.TODO(), "app-updater", "app", "")
+	c.Assert(err, check.IsNil)
+	err = r2.AddPermissions(context.TODO(), "app.update", "app.read")
+	c.Assert(err, check.IsNil)
+	token := &teamToken{
+		Team: s.team.Name,
+		Roles: []authTypes.RoleInstance{
+			{Name: "app-admin", ContextValue: "myapp"},
+			{Name: "app-updater", ContextValue: "otherapp"},
+		},
+		Constraints: &authTypes.TeamTokenConstraints{AllowedPermissions: []string{"app.deploy", "app.update.env"}},
+	}
+	perms, err := token.Permissions(context.TODO())
+	c.Assert(err, check.IsNil)
+	sort.Slice(perms, func(i, j int) bool {
+		return perms[i].Scheme.FullName()+perms[i].Context.Value < perms[j].Scheme.FullName()+perms[j].Context.Value
+	})
+	c.Assert(perms, check.DeepEquals, []permTypes.Permission{
+		{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxApp, "myapp")},
+		{Scheme: permission.PermAppUpdateEnv, Context: permission.Context(permTypes.CtxApp, "myapp")},
+		{Scheme: permission.PermAppUpdateEnv, Context: permission.Context(permTypes.CtxApp, "otherapp")},
+	})
+}
+
+func (s *S) Test_RateLimiterWindow(c *check.C) {
+	l := &rateLimiter{windows: map[string]*rateWindow{}}
+	now := time.Now()
+	c.Assert(l.allow("t1", 1, now), check.Equals, true)
+	c.Assert(l.allow("t1", 1, now.Add(time.Second)), check.Equals, false)
+	c.Assert(l.allow("t1", 1, now.Add(2*time.Second)), check.Equals, false)
+	c.Assert(l.allow("t2", 1, now.Add(2*time.Second)), check.Equals, true)
+	c.Assert(l.allow("t1", 1, now.Add(time.Minute)), check.Equals, true)
+}
+
+func (s *S) Test_RejectionTrackerWindow(c *check.C) {
+	t := &rejectionTracker{windows: map[string]time.Time{}}
+	now := time.Now()
+	c.Assert(t.first("t1\x00source", now), check.Equals, true)
+	c.Assert(t.first("t1\x00source", now.Add(time.Second)), check.Equals, false)
+	c.Assert(t.first("t1\x00permission", now.Add(time.Second)), check.Equals, true)
+	c.Assert(t.first("t2\x00source", now.Add(time.Second)), check.Equals, true)
+	c.Assert(t.first("t1\x00source", now.Add(time.Minute)), check.Equals, true)
+}
@@ -61,7 +61,16 @@ func BaseTokenPermission(ctx context.Context, t Token) ([]permTypes.Permission,
 	return u.Permissions(ctx)
 }
 
+// dynamicPermissionsToken is implemented by tokens restricting the dynamic
+// permissions inherited from their users.
+type dynamicPermissionsToken interface {
+	DynamicPermissions(ctx context.Context) ([]permTypes.Permission, error)
+}
+
 func BaseTokenDynamicPermission(ctx context.Context, t Token) ([]permTypes.Permission, error) {
+	if dt, ok := t.(dynamicPermissionsToken); ok {
+		return dt.DynamicPermissions(ctx)
+	}
 	u, err := ConvertNewUser(t.User(ctx))
 	if err != nil {
 		return nil, err
@@ -0,0 +1,108 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tokenexpiry runs the worker warning users about personal tokens
+// about to expire.
+package tokenexpiry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const EventKind = "personal token expiring"
+
+const defaultWarningWindow = 7 * 24 * time.Hour
+
+var runInterval = time.Hour
+
+func Initialize() error {
+	w := &worker{once: &sync.Once{}}
+	w.start()
+	shutdown.Register(w)
+	return nil
+}
+
+type worker struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (w *worker) start() {
+	w.once.Do(func() {
+		w.stopCh = make(chan struct{})
+		go w.spin()
+	})
+}
+
+func (w *worker) Shutdown(ctx context.Context) error {
+	if w.stopCh == nil {
+		return nil
+	}
+	w.stopCh <- struct{}{}
+	w.stopCh = nil
+	w.once = &sync.Once{}
+	return nil
+}
+
+func (w *worker) spin() {
+	for {
+		err := WarnExpiring(context.Background())
+		if err != nil {
+			log.Errorf("[token expiry] %v", err)
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-time.After(runInterval):
+		}
+	}
+}
+
+func warningWindow() time.Duration {
+	window, err := config.GetDuration("auth:personal-token:expiry-warning")
+	if err != nil || window <= 0 {
+		return defaultWarningWindow
+	}
+	return window
+}
+
+// WarnExpiring records an event on the owner of each personal token expiring
+// within the warning window, set by auth:personal-token:expiry-warning.
+func WarnExpiring(ctx context.Context) error {
+	return servicemanager.PersonalToken.NotifyExpiring(ctx, warningWindow(), func(t authTypes.PersonalToken) {
+		recordWarning(ctx, t)
+	})
+}
+
+func recordWarning(ctx context.Context, t authTypes.PersonalToken) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeUser, Value: t.UserEmail},
+		InternalKind: EventKind,
+		CustomData: map[string]interface{}{
+			"token_id":  t.TokenID,
+			"name":      t.Name,
+			"expiresAt": t.ExpiresAt,
+		},
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, t.UserEmail)),
+	})
+	if err != nil {
+		log.Errorf("[token expiry] unable to record expiry warning of token %q for %q: %v", t.TokenID, t.UserEmail, err)
+		return
+	}
+	evt.Done(ctx, nil)
+}
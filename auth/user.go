@@ -10,6 +10,7 @@ import (
 	"crypto/rand"
 	_ "crypto/sha256"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/pkg/errors"
@@ -134,6 +135,25 @@ func (u *User) Update(ctx context.Context) error {
 	return err
 }
 
+// Disable keeps the user from authenticating, dropping the sessions kept by
+// the scheme.
+func (u *User) Disable(ctx context.Context, scheme Scheme) error {
+	u.Disabled = true
+	err := u.Update(ctx)
+	if err != nil {
+		return err
+	}
+	if provisioned, ok := scheme.(ProvisionedScheme); ok {
+		return provisioned.Deactivate(ctx, u)
+	}
+	return nil
+}
+
+func (u *User) Enable(ctx context.Context) error {
+	u.Disabled = false
+	return u.Update(ctx)
+}
+
 func (u *User) ShowAPIKey(ctx context.Context) (string, error) {
 	if u.APIKey == "" {
 		u.RegenerateAPIKey(ctx)
@@ -178,7 +198,11 @@ type roleWithContext struct {
 func resolveRoles(ctx context.Context, roleInstances []authTypes.RoleInstance) ([]roleWithContext, error) {
 	resolved := make([]roleWithContext, 0, len(roleInstances))
 	roles := make(map[string]*permission.Role)
+	now := time.Now()
 	for _, roleData := range roleInstances {
+		if roleData.Expired(now) {
+			continue
+		}
 		role := roles[roleData.Name]
 		if role == nil {
 			foundRole, err := permission.FindRole(ctx, roleData.Name)
@@ -226,6 +250,17 @@ func (u *User) UserGroups() ([]authTypes.Group, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Groups provisioned through SCIM keep their members in the group
+	// storage instead of the user document.
+	memberOf, err := servicemanager.AuthGroup.ListByMember(context.TODO(), u.Email)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range memberOf {
+		if !slices.Contains(groupsFilter, group.Name) {
+			groups = append(groups, group)
+		}
+	}
 	return groups, nil
 }
 
@@ -267,7 +267,7 @@ func (b *kubernetesBuilder) buildPlatformImage(ctx context.Context, bc buildpb.B
 	return images, nil
 }
 
-func (b *kubernetesBuilder) buildContainerImage(ctx context.Context, app *apptypes.App, evt *event.Event, opts builder.BuildOpts) (apptypes.AppVersion, error) {
+func (b *kubernetesBuilder) buildContainerImage(ctx context.Context, app *apptypes.App, evt *event.Event, opts builder.BuildOpts) (_ apptypes.AppVersion, err error) {
 	w := opts.Output
 	if w == nil {
 		w = io.Discard
@@ -298,6 +298,10 @@ func (b *kubernetesBuilder) buildContainerImage(ctx context.Context, app *apptyp
 		return nil, err
 	}
 
+	phases := builder.NewPhaseTracker(appVersion, evt.UniqueID.Hex())
+	phases.Start(apptypes.BuildPhaseFetchSource)
+	defer func() { phases.Finish(err) }()
+
 	data := make([]byte, opts.ArchiveSize)
 	if opts.ArchiveSize > 0 {
 		_, err = opts.ArchiveFile.Read(data)
@@ -351,6 +355,7 @@ func (b *kubernetesBuilder) buildContainerImage(ctx context.Context, app *apptyp
 		Containerfile:     opts.Dockerfile,
 	}
 
+	phases.Start(apptypes.BuildPhaseBuild)
 	tc, err := callBuildService(ctx, bs, req, w)
 	if err != nil {
 		return nil, err
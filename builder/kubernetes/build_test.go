@@ -9,6 +9,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +17,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/tsuru/deploy-agent/pkg/build"
+	buildfake "github.com/tsuru/deploy-agent/pkg/build/fake"
 	buildpb "github.com/tsuru/deploy-agent/pkg/build/grpc_build_v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -26,6 +29,7 @@ import (
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	imagetypes "github.com/tsuru/tsuru/types/app/image"
 	bindTypes "github.com/tsuru/tsuru/types/bind"
@@ -791,6 +795,77 @@ func (s *S) TestBuild_BuildWithContainerImage(c *check.C) {
 	c.Assert(tsuruYaml, check.DeepEquals, provisiontypes.TsuruYamlData{})
 }
 
+func (s *S) TestBuild_RecordsBuildPhases(c *check.C) {
+	a, _, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+
+	buildServiceAddress := setupBuildServer(s.t, build.NewServer(&buildfake.FakeBuilder{
+		OnBuild: func(ctx context.Context, r *buildpb.BuildRequest, w io.Writer) (*buildpb.TsuruConfig, error) {
+			fmt.Fprintln(w, "--> Pushing image")
+			return &buildpb.TsuruConfig{ImageConfig: &buildpb.ContainerImageConfig{Cmd: []string{"/app.sh"}}}, nil
+		},
+	}))
+	s.clusterClient.CustomData[buildServiceAddressKey] = buildServiceAddress
+
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	c.Assert(err, check.IsNil)
+
+	var output bytes.Buffer
+
+	appVersion, err := s.b.Build(context.TODO(), a, evt, builder.BuildOpts{
+		ImageID: "registry.example/my-repository/my-app:v42",
+		Output:  &output,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(output.String(), check.Matches, "(?s).*Starting container image build.*--> Pushing image.*Container image build finished.*")
+
+	phases := appVersion.VersionInfo().BuildPhases
+	c.Assert(phases, check.HasLen, 2)
+	for i, name := range []string{"fetch-source", "build"} {
+		c.Check(phases[i].Name, check.Equals, name)
+		c.Check(phases[i].Status, check.Equals, appTypes.BuildPhaseSucceeded)
+		c.Check(phases[i].EventID, check.Equals, evt.UniqueID.Hex())
+		c.Check(phases[i].EndTime.Before(phases[i].StartTime), check.Equals, false)
+	}
+}
+
+func (s *S) TestBuild_BuildServiceReturnsErrorFailsBuildPhase(c *check.C) {
+	a, _, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+
+	buildServiceAddress := setupBuildServer(s.t, build.NewServer(&buildfake.FakeBuilder{
+		OnBuild: func(ctx context.Context, r *buildpb.BuildRequest, w io.Writer) (*buildpb.TsuruConfig, error) {
+			return nil, fmt.Errorf("push denied")
+		},
+	}))
+	s.clusterClient.CustomData[buildServiceAddressKey] = buildServiceAddress
+
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	c.Assert(err, check.IsNil)
+
+	_, err = s.b.Build(context.TODO(), a, evt, builder.BuildOpts{ImageID: "registry.example/my-app:v42"})
+	c.Assert(err, check.ErrorMatches, ".*push denied.*")
+
+	versions, err := servicemanager.AppVersion.AppVersions(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	phases := versions.Versions[1].BuildPhases
+	c.Assert(phases, check.HasLen, 2)
+	c.Assert(phases[0].Name, check.Equals, "fetch-source")
+	c.Assert(phases[0].Status, check.Equals, appTypes.BuildPhaseSucceeded)
+	c.Assert(phases[1].Name, check.Equals, "build")
+	c.Assert(phases[1].Status, check.Equals, appTypes.BuildPhaseFailed)
+}
+
 func (s *S) TestBuild_BuildWithContainerImageWithTsuruYamlProcessesHealthcheck(c *check.C) {
 	a, _, rollback := s.mock.DefaultReactions(c)
 	defer rollback()
@@ -0,0 +1,62 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/log"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// PhaseTracker records the build phases of a version, finishing the current
+// phase whenever a new one starts. Failures to store the phases are only
+// logged, as they must not break the build.
+type PhaseTracker struct {
+	version appTypes.AppVersion
+	eventID string
+	current *appTypes.BuildPhase
+}
+
+func NewPhaseTracker(version appTypes.AppVersion, eventID string) *PhaseTracker {
+	return &PhaseTracker{version: version, eventID: eventID}
+}
+
+// Start finishes the current phase successfully and starts a new one, unless
+// the current phase has the same name.
+func (t *PhaseTracker) Start(name string) {
+	if t == nil || (t.current != nil && t.current.Name == name) {
+		return
+	}
+	t.Finish(nil)
+	t.current = &appTypes.BuildPhase{
+		Name:      name,
+		Status:    appTypes.BuildPhaseRunning,
+		StartTime: time.Now().UTC(),
+		EventID:   t.eventID,
+	}
+	t.store()
+}
+
+// Finish finishes the current phase, as failed when err isn't nil.
+func (t *PhaseTracker) Finish(err error) {
+	if t == nil || t.current == nil {
+		return
+	}
+	t.current.EndTime = time.Now().UTC()
+	t.current.Status = appTypes.BuildPhaseSucceeded
+	if err != nil {
+		t.current.Status = appTypes.BuildPhaseFailed
+	}
+	t.store()
+	t.current = nil
+}
+
+func (t *PhaseTracker) store() {
+	err := t.version.SetBuildPhase(*t.current)
+	if err != nil {
+		log.Errorf("unable to store build phase %q of version %d: %v", t.current.Name, t.version.Version(), err)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tsuru/gnuflag"
+	"github.com/tsuru/tsuru/cmd"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/envs/encryption"
+	"github.com/tsuru/tsuru/storage"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+type envEncryptionRotateCmd struct {
+	fs          *gnuflag.FlagSet
+	generateKey bool
+}
+
+func (*envEncryptionRotateCmd) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "env-encryption-rotate",
+		Usage: "env-encryption-rotate [--generate-key]",
+		Desc: `Re-encrypts the private environment variables of every app and job with
+the active key of the configured encryption provider. Variables stored before
+encryption was enabled are encrypted as well.
+
+With the --generate-key flag, a new key is added to the keyfile of the local
+provider before re-encrypting. Older keys are kept in the keyfile so every
+tsurud instance can still read values until the rotation finishes, and the
+updated keyfile must be made available to all instances.`,
+	}
+}
+
+func (c *envEncryptionRotateCmd) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("env-encryption-rotate", gnuflag.ExitOnError)
+		c.fs.BoolVar(&c.generateKey, "generate-key", false, "Generate a new active key for the local provider")
+	}
+	return c.fs
+}
+
+func (c *envEncryptionRotateCmd) Run(cmdContext *cmd.Context) error {
+	if c.generateKey {
+		keyfile, err := encryption.LocalKeyfile()
+		if err != nil {
+			return err
+		}
+		keyID, err := encryption.AddLocalKey(keyfile)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmdContext.Stdout, "Key %q added to %s.\n", keyID, keyfile)
+	}
+	encryption.Reset()
+	enabled, err := encryption.Enabled()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return encryption.ErrNotConfigured
+	}
+	apps, err := reencryptApps()
+	if err != nil {
+		return err
+	}
+	jobs, err := reencryptJobs()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmdContext.Stdout, "Environment variables re-encrypted for %d apps and %d jobs.\n", apps, jobs)
+	return nil
+}
+
+// reencryptApps rewrites the envs of every app with private values through
+// the configured storage, which encrypts them with the active key.
+func reencryptApps() (int, error) {
+	driver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		return 0, err
+	}
+	return driver.AppStorage.ReencryptEnvs(context.Background())
+}
+
+// reencryptJobs does for jobs what reencryptApps does for apps, jobs are
+// always kept in MongoDB.
+func reencryptJobs() (int, error) {
+	collection, err := storagev2.JobsCollection()
+	if err != nil {
+		return 0, err
+	}
+	return storagev2.ReencryptEnvs(context.Background(), collection, "job", []string{"spec.envs", "spec.serviceenvs"}, func(raw mongoBSON.Raw) (mongoBSON.M, error) {
+		var job struct {
+			Spec struct {
+				Envs        []bindTypes.EnvVar
+				ServiceEnvs []bindTypes.ServiceEnvVar
+			}
+		}
+		err := mongoBSON.Unmarshal(raw, &job)
+		if err != nil {
+			return nil, err
+		}
+		if !storagev2.HasPrivateEnvs(job.Spec.Envs, job.Spec.ServiceEnvs) {
+			return nil, nil
+		}
+		return mongoBSON.M{
+			"spec.envs":        job.Spec.Envs,
+			"spec.serviceenvs": job.Spec.ServiceEnvs,
+		}, nil
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	stdContext "context"
+	"path/filepath"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/cmd"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/envs/encryption"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestEnvEncryptionRotateCmdInfo(c *check.C) {
+	c.Assert((&envEncryptionRotateCmd{}).Info(), check.NotNil)
+}
+
+func (s *S) TestEnvEncryptionRotateCmdRunNotConfigured(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	context := cmd.Context{Stdout: &stdout, Stderr: &stderr}
+	err := (&envEncryptionRotateCmd{}).Run(&context)
+	c.Assert(err, check.Equals, encryption.ErrNotConfigured)
+}
+
+func (s *S) TestEnvEncryptionRotateCmdRun(c *check.C) {
+	keyfile := filepath.Join(c.MkDir(), "keyfile")
+	config.Set("envs:encryption:provider", "local")
+	config.Set("envs:encryption:local:keyfile", keyfile)
+	defer encryption.Reset()
+	collection, err := storagev2.AppsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.InsertOne(stdContext.TODO(), mongoBSON.M{
+		"name": "myapp",
+		"env": mongoBSON.M{
+			"SECRET": mongoBSON.M{"name": "SECRET", "value": "s3cr3t"},
+			"PUBLIC": mongoBSON.M{"name": "PUBLIC", "value": "visible", "public": true},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	var stdout, stderr bytes.Buffer
+	context := cmd.Context{Stdout: &stdout, Stderr: &stderr}
+	command := &envEncryptionRotateCmd{}
+	err = command.Flags().Parse(true, []string{"--generate-key"})
+	c.Assert(err, check.IsNil)
+	err = command.Run(&context)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Matches, `(?s)Key ".+" added to .+\nEnvironment variables re-encrypted for 1 apps and 0 jobs.\n`)
+	var raw struct {
+		Env map[string]struct {
+			Value string
+		}
+	}
+	err = collection.FindOne(stdContext.TODO(), mongoBSON.M{"name": "myapp"}).Decode(&raw)
+	c.Assert(err, check.IsNil)
+	c.Assert(encryption.IsEncrypted(raw.Env["SECRET"].Value), check.Equals, true)
+	c.Assert(raw.Env["PUBLIC"].Value, check.Equals, "visible")
+	var app struct {
+		Env map[string]bindTypes.EnvVar
+	}
+	err = collection.FindOne(stdContext.TODO(), mongoBSON.M{"name": "myapp"}).Decode(&app)
+	c.Assert(err, check.IsNil)
+	c.Assert(app.Env["SECRET"].Value, check.Equals, "s3cr3t")
+}
+
+func (s *S) TestReencryptDocumentRetriesConcurrentChanges(c *check.C) {
+	keyfile := filepath.Join(c.MkDir(), "keyfile")
+	config.Set("envs:encryption:provider", "local")
+	config.Set("envs:encryption:local:keyfile", keyfile)
+	defer encryption.Reset()
+	_, err := encryption.AddLocalKey(keyfile)
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.AppsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.InsertOne(stdContext.TODO(), mongoBSON.M{
+		"name": "myapp",
+		"env": mongoBSON.M{
+			"SECRET": mongoBSON.M{"name": "SECRET", "value": "s3cr3t"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	stale, err := collection.FindOne(stdContext.TODO(), mongoBSON.M{"name": "myapp"}).Raw()
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(stdContext.TODO(), mongoBSON.M{"name": "myapp"}, mongoBSON.M{"$set": mongoBSON.M{
+		"env.OTHER": mongoBSON.M{"name": "OTHER", "value": "added"},
+	}})
+	c.Assert(err, check.IsNil)
+	var prepared int
+	updated, err := storagev2.ReencryptDocument(stdContext.TODO(), collection, []string{"env", "serviceenvs"}, stale, func(raw mongoBSON.Raw) (mongoBSON.M, error) {
+		prepared++
+		var app struct {
+			Env map[string]bindTypes.EnvVar
+		}
+		err := mongoBSON.Unmarshal(raw, &app)
+		if err != nil {
+			return nil, err
+		}
+		return mongoBSON.M{"env": app.Env}, nil
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated, check.Equals, true)
+	c.Assert(prepared, check.Equals, 2)
+	var app struct {
+		Env map[string]bindTypes.EnvVar
+	}
+	err = collection.FindOne(stdContext.TODO(), mongoBSON.M{"name": "myapp"}).Decode(&app)
+	c.Assert(err, check.IsNil)
+	c.Assert(app.Env["SECRET"].Value, check.Equals, "s3cr3t")
+	c.Assert(app.Env["OTHER"].Value, check.Equals, "added")
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/tsuru/tsuru/cmd"
 	_ "github.com/tsuru/tsuru/provision/kubernetes"
 	_ "github.com/tsuru/tsuru/storage/mongodb"
+	_ "github.com/tsuru/tsuru/storage/sqldb"
 	_ "go.uber.org/automaxprocs"
 )
 
@@ -28,6 +29,7 @@ func buildManager() *cmd.Manager {
 	m.Register(&tsurudCommand{Command: &migrateCmd{}})
 	m.Register(&tsurudCommand{Command: createRootUserCmd{}})
 	m.Register(&tsurudCommand{Command: &migrationListCmd{}})
+	m.Register(&tsurudCommand{Command: &envEncryptionRotateCmd{}})
 	return m
 }
 
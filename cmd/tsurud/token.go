@@ -17,6 +17,7 @@ import (
 	"github.com/tsuru/tsuru/cmd"
 	"github.com/tsuru/tsuru/permission"
 	_ "github.com/tsuru/tsuru/storage/mongodb"
+	_ "github.com/tsuru/tsuru/storage/sqldb"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 )
 
@@ -28,6 +28,10 @@ func EventsCollection() (*mongo.Collection, error) {
 	return Collection("events")
 }
 
+func EventBlocksCollection() (*mongo.Collection, error) {
+	return Collection("event_blocks")
+}
+
 func ServicesCollection() (*mongo.Collection, error) {
 	return Collection("services")
 }
@@ -48,6 +52,18 @@ func JobsCollection() (*mongo.Collection, error) {
 	return Collection("jobs")
 }
 
+func JobRunsCollection() (*mongo.Collection, error) {
+	return Collection("job_runs")
+}
+
+func JobWorkflowsCollection() (*mongo.Collection, error) {
+	return Collection("job_workflows")
+}
+
+func JobWorkflowRunsCollection() (*mongo.Collection, error) {
+	return Collection("job_workflow_runs")
+}
+
 func TokensCollection() (*mongo.Collection, error) {
 	return Collection("tokens")
 }
@@ -64,6 +80,10 @@ func TeamTokensCollection() (*mongo.Collection, error) {
 	return Collection("team_tokens")
 }
 
+func PersonalTokensCollection() (*mongo.Collection, error) {
+	return Collection("personal_tokens")
+}
+
 func TeamsCollection() (*mongo.Collection, error) {
 	return Collection("teams")
 }
@@ -76,6 +96,10 @@ func WebhookCollection() (*mongo.Collection, error) {
 	return Collection("webhook")
 }
 
+func WebhookDeliveriesCollection() (*mongo.Collection, error) {
+	return Collection("webhook_deliveries")
+}
+
 func VolumesCollection() (*mongo.Collection, error) {
 	return Collection("volumes")
 }
@@ -0,0 +1,165 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagev2
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/envs/encryption"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// plainRegistry encodes environment variables after their private values are
+// encrypted, and decodes them before their values are decrypted.
+var plainRegistry = bson.NewRegistry()
+
+func init() {
+	for _, t := range []reflect.Type{
+		reflect.TypeOf(bindTypes.EnvVar{}),
+		reflect.TypeOf(bindTypes.ServiceEnvVar{}),
+	} {
+		customRegistry.RegisterTypeEncoder(t, bsoncodec.ValueEncoderFunc(encodeEnvVar))
+		customRegistry.RegisterTypeDecoder(t, bsoncodec.ValueDecoderFunc(decodeEnvVar))
+	}
+}
+
+func encodeEnvVar(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	encoder, err := plainRegistry.LookupEncoder(val.Type())
+	if err != nil {
+		return err
+	}
+	copied := reflect.New(val.Type()).Elem()
+	copied.Set(val)
+	if !copied.FieldByName("Public").Bool() {
+		value, err := encryption.Encrypt(context.Background(), copied.FieldByName("Value").String())
+		if err != nil {
+			return errors.Wrapf(err, "unable to encrypt env %q", copied.FieldByName("Name").String())
+		}
+		copied.FieldByName("Value").SetString(value)
+	}
+	ec.Registry = plainRegistry
+	return encoder.EncodeValue(ec, vw, copied)
+}
+
+func decodeEnvVar(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	decoder, err := plainRegistry.LookupDecoder(val.Type())
+	if err != nil {
+		return err
+	}
+	dc.Registry = plainRegistry
+	err = decoder.DecodeValue(dc, vr, val)
+	if err != nil {
+		return err
+	}
+	field := val.FieldByName("Value")
+	value, err := encryption.Decrypt(context.Background(), field.String())
+	if err != nil {
+		return errors.Wrapf(err, "unable to decrypt env %q", val.FieldByName("Name").String())
+	}
+	field.SetString(value)
+	return nil
+}
+
+// HasPrivateEnvs returns whether any of the envs must be kept encrypted.
+func HasPrivateEnvs(envs []bindTypes.EnvVar, serviceEnvs []bindTypes.ServiceEnvVar) bool {
+	for _, env := range envs {
+		if !env.Public {
+			return true
+		}
+	}
+	for _, env := range serviceEnvs {
+		if !env.Public {
+			return true
+		}
+	}
+	return false
+}
+
+// maxReencryptAttempts limits how many times the envs of a single document
+// are read again after being changed while re-encrypting them.
+const maxReencryptAttempts = 5
+
+// ReencryptEnvs rewrites the env fields of every document in the collection
+// for which prepare returns the values to be set, returning the number of
+// updated documents. A nil result from prepare skips the document. The
+// values are decrypted when read and encrypted with the active key when
+// written back.
+func ReencryptEnvs(ctx context.Context, collection *mongo.Collection, kind string, fields []string, prepare func(bson.Raw) (bson.M, error)) (int, error) {
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(envsProjection(fields)))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+	count := 0
+	for cursor.Next(ctx) {
+		name, _ := cursor.Current.Lookup("name").StringValueOK()
+		updated, err := ReencryptDocument(ctx, collection, fields, cursor.Current, prepare)
+		if err != nil {
+			return count, fmt.Errorf("unable to update envs of %s %q: %w", kind, name, err)
+		}
+		if updated {
+			count++
+		}
+	}
+	return count, cursor.Err()
+}
+
+// ReencryptDocument only writes the envs of a document back while they still
+// hold the encrypted values previously read, so env changes made
+// concurrently aren't lost. Documents changed in the meantime are read and
+// re-encrypted again.
+func ReencryptDocument(ctx context.Context, collection *mongo.Collection, fields []string, raw bson.Raw, prepare func(bson.Raw) (bson.M, error)) (bool, error) {
+	name, _ := raw.Lookup("name").StringValueOK()
+	for attempt := 0; attempt < maxReencryptAttempts; attempt++ {
+		set, err := prepare(raw)
+		if err != nil {
+			return false, fmt.Errorf("unable to read envs: %w", err)
+		}
+		if set == nil {
+			return false, nil
+		}
+		query := bson.M{"name": name}
+		for _, field := range fields {
+			value, lookupErr := raw.LookupErr(strings.Split(field, ".")...)
+			if lookupErr != nil {
+				query[field] = bson.M{"$exists": false}
+			} else {
+				query[field] = value
+			}
+		}
+		result, err := collection.UpdateOne(ctx, query, bson.M{"$set": set})
+		if err != nil {
+			return false, err
+		}
+		if result.MatchedCount > 0 {
+			return true, nil
+		}
+		raw, err = collection.FindOne(ctx, bson.M{"name": name}, options.FindOne().SetProjection(envsProjection(fields))).Raw()
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return false, fmt.Errorf("envs changed concurrently %d times", maxReencryptAttempts)
+}
+
+func envsProjection(fields []string) bson.M {
+	projection := bson.M{"name": 1}
+	for _, field := range fields {
+		projection[field] = 1
+	}
+	return projection
+}
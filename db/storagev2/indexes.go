@@ -153,6 +153,24 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "personal_tokens",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "token", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "token_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "user_email", Value: 1}, {Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
 	{
 		Collection: "cache",
 		Indexes: []mongo.IndexModel{
@@ -173,6 +191,60 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "webhook_deliveries",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "status", Value: 1}, {Key: "nextattemptat", Value: 1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "webhookname", Value: 1}, {Key: "createdat", Value: -1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0).SetSparse(true),
+			},
+		},
+	},
+
+	{
+		Collection: "job_runs",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "jobname", Value: 1}, {Key: "starttime", Value: -1}},
+			},
+		},
+	},
+
+	{
+		Collection: "job_workflows",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
+	{
+		Collection: "job_workflow_runs",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "workflowname", Value: 1}, {Key: "starttime", Value: -1}},
+			},
+		},
+	},
+
+	{
+		Collection: "change_freezes",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "pool", Value: 1}, {Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
 	{
 		Collection: "auth_groups",
 		Indexes: []mongo.IndexModel{
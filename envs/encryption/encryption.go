@@ -0,0 +1,275 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package encryption implements envelope encryption of private environment
+// variables stored in the database.
+//
+// Each value is encrypted with AES-256-GCM using a data key, which is itself
+// encrypted by a key provider holding the key encryption keys, e.g. a local
+// keyfile or a KMS. A data key is generated once per process and reused for
+// every value encrypted with the same provider key, so the provider is only
+// called once to wrap it, and once per distinct data key to unwrap values.
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+const (
+	prefix      = "tsuru-enc:v1:"
+	dataKeySize = 32
+
+	maxCachedDataKeys = 1024
+)
+
+var (
+	ErrNotConfigured = errors.New("environment variables encryption is not configured")
+
+	providersMu sync.Mutex
+	providers   = map[string]ProviderFactory{}
+
+	state = &encryptionState{}
+)
+
+// KeyProvider holds the key encryption keys, wrapping and unwrapping data
+// keys. Implementations may keep keys locally or delegate to a KMS.
+type KeyProvider interface {
+	// WrapKey encrypts dataKey with the active key, returning the ID of the
+	// key used, which is later given to UnwrapKey.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+	// ActiveKeyID returns the ID of the key used by WrapKey.
+	ActiveKeyID(ctx context.Context) (string, error)
+}
+
+// ProviderFactory creates a key provider from the config entries under
+// envs:encryption:<name>.
+type ProviderFactory func(configPrefix string) (KeyProvider, error)
+
+// RegisterProvider makes a key provider available by name, to be selected by
+// the envs:encryption:provider config.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+type dataKey struct {
+	keyID   string
+	wrapped []byte
+	aead    cipher.AEAD
+}
+
+type encryptionState struct {
+	sync.Mutex
+	initialized bool
+	provider    KeyProvider
+	current     *dataKey
+	unwrapped   map[string]cipher.AEAD
+}
+
+func (s *encryptionState) getProvider() (KeyProvider, error) {
+	s.Lock()
+	defer s.Unlock()
+	if !s.initialized {
+		provider, err := loadProvider()
+		if err != nil {
+			return nil, err
+		}
+		s.provider = provider
+		s.unwrapped = map[string]cipher.AEAD{}
+		s.initialized = true
+	}
+	return s.provider, nil
+}
+
+func loadProvider() (KeyProvider, error) {
+	name, _ := config.GetString("envs:encryption:provider")
+	if name == "" {
+		return nil, nil
+	}
+	providersMu.Lock()
+	factory, ok := providers[name]
+	providersMu.Unlock()
+	if !ok {
+		return nil, errors.Errorf("unknown environment variables encryption provider %q", name)
+	}
+	provider, err := factory("envs:encryption:" + name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to initialize encryption provider %q", name)
+	}
+	return provider, nil
+}
+
+// Reset discards the configured provider and cached data keys, which are
+// loaded again from the config on next use.
+func Reset() {
+	state.Lock()
+	defer state.Unlock()
+	state.initialized = false
+	state.provider = nil
+	state.current = nil
+	state.unwrapped = nil
+}
+
+// Enabled returns whether a key provider is configured, in which case private
+// values are encrypted before being stored.
+func Enabled() (bool, error) {
+	provider, err := state.getProvider()
+	return provider != nil, err
+}
+
+// IsEncrypted returns whether value was produced by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *encryptionState) currentDataKey(ctx context.Context) (*dataKey, error) {
+	provider, err := s.getProvider()
+	if err != nil || provider == nil {
+		return nil, err
+	}
+	activeKeyID, err := provider.ActiveKeyID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.current != nil && s.current.keyID == activeKeyID {
+		return s.current, nil
+	}
+	key := make([]byte, dataKeySize)
+	_, err = io.ReadFull(rand.Reader, key)
+	if err != nil {
+		return nil, err
+	}
+	keyID, wrapped, err := provider.WrapKey(ctx, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to wrap data key")
+	}
+	if strings.Contains(keyID, ":") {
+		return nil, errors.Errorf("invalid key id %q", keyID)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	s.current = &dataKey{keyID: keyID, wrapped: wrapped, aead: aead}
+	return s.current, nil
+}
+
+func (s *encryptionState) unwrap(ctx context.Context, keyID string, wrapped []byte) (cipher.AEAD, error) {
+	provider, err := s.getProvider()
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, ErrNotConfigured
+	}
+	cacheKey := keyID + ":" + string(wrapped)
+	s.Lock()
+	aead, ok := s.unwrapped[cacheKey]
+	s.Unlock()
+	if ok {
+		return aead, nil
+	}
+	key, err := provider.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to unwrap data key with key %q", keyID)
+	}
+	aead, err = newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	s.Lock()
+	defer s.Unlock()
+	if len(s.unwrapped) >= maxCachedDataKeys {
+		s.unwrapped = map[string]cipher.AEAD{}
+	}
+	s.unwrapped[cacheKey] = aead
+	return aead, nil
+}
+
+// Encrypt encrypts value with the current data key. Values are returned
+// unchanged when no provider is configured.
+func Encrypt(ctx context.Context, value string) (string, error) {
+	key, err := state.currentDataKey(ctx)
+	if err != nil || key == nil {
+		return value, err
+	}
+	nonce := make([]byte, key.aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", err
+	}
+	sealed := key.aead.Seal(nonce, nonce, []byte(value), nil)
+	return fmt.Sprintf("%s%s:%s:%s",
+		prefix,
+		key.keyID,
+		base64.RawStdEncoding.EncodeToString(key.wrapped),
+		base64.RawStdEncoding.EncodeToString(sealed),
+	), nil
+}
+
+// Decrypt returns the plain value of a value returned by Encrypt. Values
+// that aren't encrypted are returned unchanged.
+func Decrypt(ctx context.Context, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted value")
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, "malformed encrypted value")
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.Wrap(err, "malformed encrypted value")
+	}
+	aead, err := state.unwrap(ctx, parts[0], wrapped)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to decrypt value")
+	}
+	return string(plain), nil
+}
+
+// KeyID returns the ID of the provider key used to wrap the data key of an
+// encrypted value.
+func KeyID(value string) string {
+	if !IsEncrypted(value) {
+		return ""
+	}
+	keyID, _, _ := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	return keyID
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package encryption
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	keyfile string
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpTest(c *check.C) {
+	s.keyfile = filepath.Join(c.MkDir(), "keyfile")
+	_, err := AddLocalKey(s.keyfile)
+	c.Assert(err, check.IsNil)
+	config.Set("envs:encryption:provider", "local")
+	config.Set("envs:encryption:local:keyfile", s.keyfile)
+	Reset()
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("envs:encryption")
+	Reset()
+}
+
+func (s *S) TestEncryptDecrypt(c *check.C) {
+	enabled, err := Enabled()
+	c.Assert(err, check.IsNil)
+	c.Assert(enabled, check.Equals, true)
+	encrypted, err := Encrypt(context.TODO(), "my-secret")
+	c.Assert(err, check.IsNil)
+	c.Assert(IsEncrypted(encrypted), check.Equals, true)
+	c.Assert(strings.Contains(encrypted, "my-secret"), check.Equals, false)
+	other, err := Encrypt(context.TODO(), "my-secret")
+	c.Assert(err, check.IsNil)
+	c.Assert(other, check.Not(check.Equals), encrypted)
+	decrypted, err := Decrypt(context.TODO(), encrypted)
+	c.Assert(err, check.IsNil)
+	c.Assert(decrypted, check.Equals, "my-secret")
+	decrypted, err = Decrypt(context.TODO(), "plain")
+	c.Assert(err, check.IsNil)
+	c.Assert(decrypted, check.Equals, "plain")
+}
+
+func (s *S) TestEncryptNotConfigured(c *check.C) {
+	encrypted, err := Encrypt(context.TODO(), "my-secret")
+	c.Assert(err, check.IsNil)
+	config.Unset("envs:encryption")
+	Reset()
+	enabled, err := Enabled()
+	c.Assert(err, check.IsNil)
+	c.Assert(enabled, check.Equals, false)
+	value, err := Encrypt(context.TODO(), "my-secret")
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "my-secret")
+	_, err = Decrypt(context.TODO(), encrypted)
+	c.Assert(err, check.Equals, ErrNotConfigured)
+}
+
+func (s *S) TestDecryptTampered(c *check.C) {
+	encrypted, err := Encrypt(context.TODO(), "my-secret")
+	c.Assert(err, check.IsNil)
+	tampered := encrypted[:len(encrypted)-2] + "AA"
+	if tampered == encrypted {
+		tampered = encrypted[:len(encrypted)-2] + "BB"
+	}
+	_, err = Decrypt(context.TODO(), tampered)
+	c.Assert(err, check.NotNil)
+	_, err = Decrypt(context.TODO(), prefix+"invalid")
+	c.Assert(err, check.ErrorMatches, "malformed encrypted value")
+}
+
+func (s *S) TestKeyRotation(c *check.C) {
+	oldValue, err := Encrypt(context.TODO(), "old-secret")
+	c.Assert(err, check.IsNil)
+	oldKeyID := KeyID(oldValue)
+	newKeyID, err := AddLocalKey(s.keyfile)
+	c.Assert(err, check.IsNil)
+	c.Assert(newKeyID, check.Not(check.Equals), oldKeyID)
+	// The keyfile is only read again when its modification time changes,
+	// which may not happen within the file system timestamp resolution.
+	Reset()
+	newValue, err := Encrypt(context.TODO(), "new-secret")
+	c.Assert(err, check.IsNil)
+	c.Assert(KeyID(newValue), check.Equals, newKeyID)
+	decrypted, err := Decrypt(context.TODO(), oldValue)
+	c.Assert(err, check.IsNil)
+	c.Assert(decrypted, check.Equals, "old-secret")
+	decrypted, err = Decrypt(context.TODO(), newValue)
+	c.Assert(err, check.IsNil)
+	c.Assert(decrypted, check.Equals, "new-secret")
+	info, err := os.Stat(s.keyfile)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Mode().Perm(), check.Equals, os.FileMode(0600))
+}
+
+func (s *S) TestUnknownProvider(c *check.C) {
+	config.Set("envs:encryption:provider", "unknown")
+	Reset()
+	_, err := Enabled()
+	c.Assert(err, check.ErrorMatches, `unknown environment variables encryption provider "unknown"`)
+}
+
+func (s *S) TestParseKeyfileInvalid(c *check.C) {
+	_, err := parseKeyfile([]byte("# only comments\n\n"))
+	c.Assert(err, check.ErrorMatches, "keyfile has no keys")
+	_, err = parseKeyfile([]byte("key1:c2hvcnQ=\n"))
+	c.Assert(err, check.ErrorMatches, `invalid key "key1", keys must be 32 bytes encoded in base64`)
+	_, err = parseKeyfile([]byte("no-separator\n"))
+	c.Assert(err, check.ErrorMatches, `invalid keyfile line "no-separator"`)
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+const LocalProviderName = "local"
+
+func init() {
+	RegisterProvider(LocalProviderName, newLocalProvider)
+}
+
+type localKey struct {
+	id  string
+	key []byte
+}
+
+// localProvider keeps the key encryption keys in a keyfile, one key per line
+// in the "<id>:<base64 key>" format. The first key is the active one, used to
+// wrap new data keys, while the others are kept to unwrap data keys wrapped
+// before a rotation. The keyfile is read again whenever it changes, so keys
+// added by a rotation are picked up without restarting.
+type localProvider struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	keys    []localKey
+}
+
+func newLocalProvider(configPrefix string) (KeyProvider, error) {
+	path, err := config.GetString(configPrefix + ":keyfile")
+	if err != nil {
+		return nil, err
+	}
+	p := &localProvider{path: path}
+	_, err = p.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func parseKeyfile(data []byte) ([]localKey, error) {
+	var keys []localKey
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok || id == "" {
+			return nil, errors.Errorf("invalid keyfile line %q", line)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != dataKeySize {
+			return nil, errors.Errorf("invalid key %q, keys must be %d bytes encoded in base64", id, dataKeySize)
+		}
+		keys = append(keys, localKey{id: id, key: key})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("keyfile has no keys")
+	}
+	return keys, scanner.Err()
+}
+
+func (p *localProvider) loadKeys() ([]localKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, err
+	}
+	if p.keys != nil && info.ModTime().Equal(p.modTime) {
+		return p.keys, nil
+	}
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseKeyfile(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read keyfile %s", p.path)
+	}
+	p.keys = keys
+	p.modTime = info.ModTime()
+	return keys, nil
+}
+
+func (p *localProvider) ActiveKeyID(ctx context.Context) (string, error) {
+	keys, err := p.loadKeys()
+	if err != nil {
+		return "", err
+	}
+	return keys[0].id, nil
+}
+
+func (p *localProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	keys, err := p.loadKeys()
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := newAEAD(keys[0].key)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", nil, err
+	}
+	return keys[0].id, aead.Seal(nonce, nonce, dataKey, []byte(keys[0].id)), nil
+}
+
+func (p *localProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	keys, err := p.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if k.id != keyID {
+			continue
+		}
+		aead, err := newAEAD(k.key)
+		if err != nil {
+			return nil, err
+		}
+		if len(wrapped) < aead.NonceSize() {
+			return nil, errors.New("malformed wrapped key")
+		}
+		return aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+	}
+	return nil, errors.Errorf("key %q not found in keyfile %s", keyID, p.path)
+}
+
+// AddLocalKey generates a new key and makes it the active key of the keyfile
+// at path, creating the file when it doesn't exist. Existing keys are kept
+// so values encrypted with them can still be decrypted. It returns the ID of
+// the new key.
+func AddLocalKey(path string) (string, error) {
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	key := make([]byte, dataKeySize)
+	_, err = io.ReadFull(rand.Reader, key)
+	if err != nil {
+		return "", err
+	}
+	suffix := make([]byte, 4)
+	_, err = io.ReadFull(rand.Reader, suffix)
+	if err != nil {
+		return "", err
+	}
+	id := fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102"), hex.EncodeToString(suffix))
+	data := fmt.Sprintf("%s:%s\n%s", id, base64.StdEncoding.EncodeToString(key), current)
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".keyfile-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(data)
+	if err == nil {
+		err = tmp.Chmod(0600)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return id, os.Rename(tmp.Name(), path)
+}
+
+// LocalKeyfile returns the keyfile configured for the local provider.
+func LocalKeyfile() (string, error) {
+	name, _ := config.GetString("envs:encryption:provider")
+	if name != LocalProviderName {
+		return "", errors.Errorf("the configured encryption provider is %q, keys can only be generated for the %q provider", name, LocalProviderName)
+	}
+	return config.GetString("envs:encryption:" + LocalProviderName + ":keyfile")
+}
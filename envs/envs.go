@@ -23,6 +23,7 @@ func Interpolate(mergedEnvs map[string]bindTypes.EnvVar, toInterpolate map[strin
 	}
 	env := mergedEnvs[envName]
 	env.Value = mergedEnvs[varName].Value
+	env.SecretRef = mergedEnvs[varName].SecretRef
 	mergedEnvs[envName] = env
 }
 
@@ -0,0 +1,110 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package secretref handles environment variables whose values are kept in an
+// external secret store, referenced as <scheme>://<path>#<key>. Values are
+// never stored by tsuru, they're resolved when the app is deployed.
+package secretref
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// KubernetesScheme references a key of a Secret in the app namespace, e.g.
+// k8s://my-secret#password. Provisioners project these references directly
+// into the pod spec instead of resolving them. The Secret must list the app,
+// or the team owning it, in its tsuru.io/allowed-apps or
+// tsuru.io/allowed-teams annotations.
+const KubernetesScheme = "k8s"
+
+var (
+	ErrInvalidRef = errors.New("secret reference must be in the format <scheme>://<path>#<key>")
+
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{}
+)
+
+// Ref is a parsed secret reference.
+type Ref struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%s://%s#%s", r.Scheme, r.Path, r.Key)
+}
+
+// Resolver reads secrets of a reference scheme.
+type Resolver interface {
+	// Validate checks that the referenced secret exists and holds the
+	// referenced key, without returning its value.
+	Validate(ctx context.Context, app *appTypes.App, ref Ref) error
+	Resolve(ctx context.Context, app *appTypes.App, ref Ref) (string, error)
+}
+
+// Register makes a resolver available for references with the given scheme.
+func Register(scheme string, resolver Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = resolver
+}
+
+func Parse(value string) (Ref, error) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok || scheme == "" {
+		return Ref{}, ErrInvalidRef
+	}
+	path, key, ok := strings.Cut(rest, "#")
+	if !ok || path == "" || key == "" {
+		return Ref{}, ErrInvalidRef
+	}
+	return Ref{Scheme: scheme, Path: path, Key: key}, nil
+}
+
+func getResolver(scheme string) (Resolver, error) {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	resolver, ok := resolvers[scheme]
+	if !ok {
+		return nil, errors.Errorf("unknown secret reference scheme %q", scheme)
+	}
+	return resolver, nil
+}
+
+// Validate checks that value is a valid reference to an existing secret.
+func Validate(ctx context.Context, app *appTypes.App, value string) error {
+	ref, err := Parse(value)
+	if err != nil {
+		return err
+	}
+	resolver, err := getResolver(ref.Scheme)
+	if err != nil {
+		return err
+	}
+	return resolver.Validate(ctx, app, ref)
+}
+
+// Resolve returns the value of the secret referenced by value.
+func Resolve(ctx context.Context, app *appTypes.App, value string) (string, error) {
+	ref, err := Parse(value)
+	if err != nil {
+		return "", err
+	}
+	resolver, err := getResolver(ref.Scheme)
+	if err != nil {
+		return "", err
+	}
+	secret, err := resolver.Resolve(ctx, app, ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve secret reference %q", ref)
+	}
+	return secret, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secretref
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tsuru/config"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("envs:secret-refs")
+}
+
+func (s *S) TestParse(c *check.C) {
+	ref, err := Parse("vault://secret/myapp/db#password")
+	c.Assert(err, check.IsNil)
+	c.Assert(ref, check.Equals, Ref{Scheme: "vault", Path: "secret/myapp/db", Key: "password"})
+	c.Assert(ref.String(), check.Equals, "vault://secret/myapp/db#password")
+	for _, value := range []string{"", "plain", "vault://secret", "vault://secret#", "://secret#key", "vault://#key"} {
+		_, err = Parse(value)
+		c.Assert(err, check.Equals, ErrInvalidRef, check.Commentf("value %q", value))
+	}
+}
+
+func (s *S) TestUnknownScheme(c *check.C) {
+	err := Validate(context.TODO(), &appTypes.App{}, "unknown://path#key")
+	c.Assert(err, check.ErrorMatches, `unknown secret reference scheme "unknown"`)
+}
+
+func (s *S) TestVaultResolver(c *check.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "my-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/myapp/db" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"password": "s3cr3t", "port": 5432}}}`))
+	}))
+	defer server.Close()
+	config.Set("envs:secret-refs:vault:address", server.URL)
+	config.Set("envs:secret-refs:vault:token", "my-token")
+	value, err := Resolve(context.TODO(), &appTypes.App{}, "vault://secret/myapp/db#password")
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "s3cr3t")
+	value, err = Resolve(context.TODO(), &appTypes.App{}, "vault://secret/myapp/db#port")
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "5432")
+	err = Validate(context.TODO(), &appTypes.App{}, "vault://secret/myapp/db#user")
+	c.Assert(err, check.ErrorMatches, `key "user" not found in vault secret "secret/myapp/db"`)
+	err = Validate(context.TODO(), &appTypes.App{}, "vault://secret/other#password")
+	c.Assert(err, check.ErrorMatches, `vault secret "secret/other" not found`)
+	err = Validate(context.TODO(), &appTypes.App{}, "vault://secret#password")
+	c.Assert(err, check.ErrorMatches, `vault path "secret" must include the engine mount`)
+}
+
+func (s *S) TestVaultResolverNotConfigured(c *check.C) {
+	err := Validate(context.TODO(), &appTypes.App{}, "vault://secret/myapp/db#password")
+	c.Assert(err, check.ErrorMatches, "vault secret references are not configured")
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const VaultScheme = "vault"
+
+func init() {
+	Register(VaultScheme, &vaultResolver{})
+}
+
+// vaultResolver reads secrets from a Vault KV version 2 engine. References
+// are in the format vault://<mount>/<path>#<key>, and the Vault address and
+// token are taken from the envs:secret-refs:vault config.
+type vaultResolver struct{}
+
+func (r *vaultResolver) read(ctx context.Context, ref Ref) (map[string]interface{}, error) {
+	address, _ := config.GetString("envs:secret-refs:vault:address")
+	token, _ := config.GetString("envs:secret-refs:vault:token")
+	if address == "" || token == "" {
+		return nil, errors.New("vault secret references are not configured")
+	}
+	mount, path, ok := strings.Cut(ref.Path, "/")
+	if !ok || path == "" {
+		return nil, errors.Errorf("vault path %q must include the engine mount", ref.Path)
+	}
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(address, "/"), mount, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace, _ := config.GetString("envs:secret-refs:vault:namespace"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	rsp, err := tsuruNet.Dial15Full60ClientWithPool.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, errors.Errorf("vault secret %q not found", ref.Path)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code reading vault secret %q: %d", ref.Path, rsp.StatusCode)
+	}
+	var data struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	err = json.NewDecoder(rsp.Body).Decode(&data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse vault response")
+	}
+	return data.Data.Data, nil
+}
+
+func (r *vaultResolver) Validate(ctx context.Context, app *appTypes.App, ref Ref) error {
+	_, err := r.Resolve(ctx, app, ref)
+	return err
+}
+
+func (r *vaultResolver) Resolve(ctx context.Context, app *appTypes.App, ref Ref) (string, error) {
+	data, err := r.read(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("key %q not found in vault secret %q", ref.Key, ref.Path)
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	internalConfig "github.com/tsuru/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/log"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	approvalSpecs          []ApprovalSpec
+	defaultApprovalTimeout = time.Hour
+	// approvalResumeTimeout is how long an approved event waits for its
+	// operation to be requested again before it's finished.
+	approvalResumeTimeout = 15 * time.Minute
+
+	ErrNotPendingApproval = errors.New("event is not pending approval")
+	ErrSelfApproval       = errors.New("event owner is not allowed to approve or reject the event")
+	ErrApprovalNotOwner   = errors.New("approved event belongs to another owner")
+	ErrApprovalResumed    = errors.New("approved event was already resumed")
+)
+
+type ErrNotApproved struct {
+	Event *Event
+	Cause string
+}
+
+func (err ErrNotApproved) Error() string {
+	return fmt.Sprintf("event %q on %s not approved: %s", err.Event.Kind, err.Event.Target, err.Cause)
+}
+
+// ErrApprovalPending is returned by New when the event must be approved
+// before running. The event is stored as pending approval, holding its
+// lock, and once approved the operation must be requested again, resuming
+// the event with ResumeApproved.
+type ErrApprovalPending struct {
+	Event *Event
+}
+
+func (err ErrApprovalPending) Error() string {
+	return fmt.Sprintf("event %q on %s is pending approval until %s", err.Event.Kind, err.Event.Target, err.Event.ApprovalInfo.Deadline.Format(timeFormat))
+}
+
+type approvedEventKey struct{}
+
+type approvedEvent struct {
+	mu  sync.Mutex
+	evt *Event
+}
+
+// WithApprovedEvent returns a context in which the first New call for the
+// same kind, target and owner of the approved event resumes it instead of
+// creating another event, allowing the operation to be retried once the
+// event is approved. The returned function must be called after the retry,
+// finishing the approved event with the given error when it wasn't resumed.
+func WithApprovedEvent(ctx context.Context, evt *Event) (context.Context, func(error)) {
+	approved := &approvedEvent{evt: evt}
+	finish := func(err error) {
+		approved.mu.Lock()
+		unused := approved.evt
+		approved.evt = nil
+		approved.mu.Unlock()
+		if unused == nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("approved operation did not run")
+		}
+		if doneErr := unused.Done(context.TODO(), err); doneErr != nil {
+			log.Errorf("unable to finish approved event: %v", doneErr)
+		}
+	}
+	return context.WithValue(ctx, approvedEventKey{}, approved), finish
+}
+
+func takeApprovedEvent(ctx context.Context, kind eventTypes.Kind, target eventTypes.Target, owner eventTypes.Owner) *Event {
+	approved, _ := ctx.Value(approvedEventKey{}).(*approvedEvent)
+	if approved == nil {
+		return nil
+	}
+	approved.mu.Lock()
+	defer approved.mu.Unlock()
+	evt := approved.evt
+	if evt == nil || evt.Kind != kind || evt.Target != target || evt.Owner != owner {
+		return nil
+	}
+	approved.evt = nil
+	return evt
+}
+
+// ApprovalSpec describes events that must be approved by someone other than
+// their owner before running. Events match when their kind starts with
+// KindName and when either their target or one of their extra targets
+// matches TargetType and, if set, TargetValue. Timeout is expressed in
+// seconds in the config file.
+type ApprovalSpec struct {
+	KindName    string                `json:"kind-name"`
+	TargetType  eventTypes.TargetType `json:"target-type"`
+	TargetValue string                `json:"target-value"`
+	Timeout     time.Duration         `json:"timeout"`
+}
+
+func (s *ApprovalSpec) UnmarshalJSON(data []byte) error {
+	type approvalSpecAlias ApprovalSpec
+	var v approvalSpecAlias
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*s = ApprovalSpec(v)
+	s.Timeout = s.Timeout * time.Second
+	return nil
+}
+
+func (s *ApprovalSpec) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return defaultApprovalTimeout
+	}
+	return s.Timeout
+}
+
+func (s *ApprovalSpec) matches(evt *Event) bool {
+	if s.KindName != "" && !strings.HasPrefix(evt.Kind.Name, s.KindName) {
+		return false
+	}
+	if s.TargetType == "" {
+		return true
+	}
+	targets := []eventTypes.Target{evt.Target}
+	for _, et := range evt.ExtraTargets {
+		targets = append(targets, et.Target)
+	}
+	for _, t := range targets {
+		if t.Type == s.TargetType && (s.TargetValue == "" || t.Value == s.TargetValue) {
+			return true
+		}
+	}
+	return false
+}
+
+func loadApprovals() error {
+	var specs []ApprovalSpec
+	err := internalConfig.UnmarshalConfig("event:approval", &specs)
+	if err != nil {
+		if _, isNotFound := errors.Cause(err).(config.ErrKeyNotFound); isNotFound {
+			return nil
+		}
+		return err
+	}
+	approvalSpecs = specs
+	return nil
+}
+
+func SetApproval(spec ApprovalSpec) {
+	approvalSpecs = append(approvalSpecs, spec)
+}
+
+func getApproval(evt *Event) *ApprovalSpec {
+	if evt.Owner.Type == eventTypes.OwnerTypeInternal {
+		return nil
+	}
+	for i := range approvalSpecs {
+		if approvalSpecs[i].matches(evt) {
+			return &approvalSpecs[i]
+		}
+	}
+	return nil
+}
+
+// tokenOwner returns the owner of the events created with the token.
+func tokenOwner(t auth.Token) eventTypes.Owner {
+	if token, ok := t.(authTypes.NamedToken); ok {
+		return eventTypes.Owner{Type: eventTypes.OwnerTypeToken, Name: token.GetTokenName()}
+	}
+	return eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: t.GetUserName()}
+}
+
+// ResumeApproved claims the approved event with the given unique ID, so the
+// operation it guards may be requested again by its owner and run within
+// WithApprovedEvent. Each approved event can only be resumed once, and must
+// be resumed within approvalResumeTimeout of its approval.
+func ResumeApproved(ctx context.Context, uniqueID string, t auth.Token) (*Event, error) {
+	evt, err := GetByHexID(ctx, uniqueID)
+	if err != nil {
+		return nil, err
+	}
+	if evt.Owner != tokenOwner(t) {
+		return nil, ErrApprovalNotOwner
+	}
+	switch {
+	case evt.Running && evt.ApprovalInfo.Pending:
+		return nil, ErrApprovalPending{Event: evt}
+	case evt.ApprovalInfo.Rejected:
+		return nil, ErrNotApproved{Event: evt, Cause: fmt.Sprintf("rejected by %s: %s", evt.ApprovalInfo.Owner, evt.ApprovalInfo.Reason)}
+	case !evt.ApprovalInfo.Approved:
+		return nil, ErrNotApproved{Event: evt, Cause: "approval not required"}
+	case !evt.Running:
+		return nil, ErrNotApproved{Event: evt, Cause: "event already finished: " + evt.Error}
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return nil, err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{
+		"_id":                   evt.ID,
+		"running":               true,
+		"approvalinfo.approved": true,
+		"approvalinfo.resumed":  mongoBSON.M{"$ne": true},
+	}, mongoBSON.M{"$set": mongoBSON.M{"approvalinfo.resumed": true}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrApprovalResumed
+	}
+	evt.ApprovalInfo.Resumed = true
+	updater.add(evt.ID)
+	return evt, nil
+}
+
+// Approve allows a pending event to run. Events can't be approved by their
+// own owners.
+func (e *Event) Approve(ctx context.Context, owner, reason string) error {
+	return e.decideApproval(ctx, owner, reason, true)
+}
+
+// Reject prevents a pending event from running, the event finishes with an
+// error containing the reason. Events can't be rejected by their own owners.
+func (e *Event) Reject(ctx context.Context, owner, reason string) error {
+	err := e.decideApproval(ctx, owner, reason, false)
+	if err != nil {
+		return err
+	}
+	eventsRejected.WithLabelValues(e.Kind.Name, rejectNotApproved).Inc()
+	return e.Done(ctx, ErrNotApproved{Event: e, Cause: fmt.Sprintf("rejected by %s: %s", owner, reason)})
+}
+
+func (e *Event) decideApproval(ctx context.Context, owner, reason string, approved bool) error {
+	e.logMu.Lock()
+	defer e.logMu.Unlock()
+	if !e.ApprovalInfo.Pending || !e.Running {
+		return ErrNotPendingApproval
+	}
+	if owner == e.Owner.Name {
+		return ErrSelfApproval
+	}
+
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	update := mongoBSON.M{"$set": mongoBSON.M{
+		"approvalinfo.pending":      false,
+		"approvalinfo.owner":        owner,
+		"approvalinfo.reason":       reason,
+		"approvalinfo.decisiontime": now,
+		"approvalinfo.approved":     approved,
+		"approvalinfo.rejected":     !approved,
+	}}
+	query := mongoBSON.M{
+		"_id":                   e.ID,
+		"running":               true,
+		"approvalinfo.pending":  true,
+		"approvalinfo.deadline": mongoBSON.M{"$gt": now},
+	}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err = collection.FindOneAndUpdate(ctx, query, update, options).Decode(&e.EventData)
+	if err == mongo.ErrNoDocuments {
+		if _, errID := GetByID(ctx, e.ID); errID == ErrEventNotFound {
+			return ErrEventNotFound
+		}
+		return ErrNotPendingApproval
+	}
+	return err
+}
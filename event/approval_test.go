@@ -0,0 +1,228 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestApprovalSpecMatches(c *check.C) {
+	evt := &Event{EventData: eventTypes.EventData{
+		Kind:   eventTypes.Kind{Name: "app.deploy"},
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		ExtraTargets: []eventTypes.ExtraTarget{
+			{Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: "prod"}},
+		},
+	}}
+	tt := []struct {
+		spec     ApprovalSpec
+		expected bool
+	}{
+		{ApprovalSpec{}, true},
+		{ApprovalSpec{KindName: "app.deploy"}, true},
+		{ApprovalSpec{KindName: "app"}, true},
+		{ApprovalSpec{KindName: "app.update"}, false},
+		{ApprovalSpec{KindName: "app.deploy", TargetType: eventTypes.TargetTypePool}, true},
+		{ApprovalSpec{KindName: "app.deploy", TargetType: eventTypes.TargetTypePool, TargetValue: "prod"}, true},
+		{ApprovalSpec{KindName: "app.deploy", TargetType: eventTypes.TargetTypePool, TargetValue: "dev"}, false},
+		{ApprovalSpec{TargetType: eventTypes.TargetTypeApp, TargetValue: "myapp"}, true},
+		{ApprovalSpec{TargetType: eventTypes.TargetTypeCluster}, false},
+	}
+	for i, t := range tt {
+		c.Check(t.spec.matches(evt), check.Equals, t.expected, check.Commentf("(%d)", i))
+	}
+}
+
+func (s *S) newPendingEvent(c *check.C) *Event {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(evt, check.IsNil)
+	c.Assert(err, check.FitsTypeOf, ErrApprovalPending{})
+	pending := err.(ErrApprovalPending).Event
+	c.Assert(pending.Running, check.Equals, true)
+	c.Assert(pending.ApprovalInfo.Required, check.Equals, true)
+	c.Assert(pending.ApprovalInfo.Pending, check.Equals, true)
+	evts, err := List(context.TODO(), &Filter{PendingApproval: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].UniqueID, check.Equals, pending.UniqueID)
+	return pending
+}
+
+func (s *S) TestNewEventPendingApproval(c *check.C) {
+	SetApproval(ApprovalSpec{KindName: "app.deploy", TargetType: eventTypes.TargetTypeApp})
+	pending := s.newPendingEvent(c)
+	_, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.FitsTypeOf, ErrEventLocked{})
+	err = pending.Reject(context.TODO(), "approver@example.com", "")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestNewEventApproved(c *check.C) {
+	SetApproval(ApprovalSpec{KindName: "app.deploy", TargetType: eventTypes.TargetTypeApp})
+	pending := s.newPendingEvent(c)
+	_, err := ResumeApproved(context.TODO(), pending.UniqueID.Hex(), s.token)
+	c.Assert(err, check.FitsTypeOf, ErrApprovalPending{})
+	approver, err := GetByID(context.TODO(), pending.UniqueID)
+	c.Assert(err, check.IsNil)
+	err = approver.Approve(context.TODO(), "approver@example.com", "looks good")
+	c.Assert(err, check.IsNil)
+	approved, err := ResumeApproved(context.TODO(), pending.UniqueID.Hex(), s.token)
+	c.Assert(err, check.IsNil)
+	c.Assert(approved.ApprovalInfo.Pending, check.Equals, false)
+	c.Assert(approved.ApprovalInfo.Approved, check.Equals, true)
+	c.Assert(approved.ApprovalInfo.Resumed, check.Equals, true)
+	c.Assert(approved.ApprovalInfo.Owner, check.Equals, "approver@example.com")
+	c.Assert(approved.ApprovalInfo.Reason, check.Equals, "looks good")
+	_, err = ResumeApproved(context.TODO(), pending.UniqueID.Hex(), s.token)
+	c.Assert(err, check.Equals, ErrApprovalResumed)
+	ctx, finish := WithApprovedEvent(context.TODO(), approved)
+	evt, err := New(ctx, &Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt, check.Equals, approved)
+	finish(nil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	evts, err := All(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+	c.Assert(evts[0].Error, check.Equals, "")
+	c.Assert(evts[0].ApprovalInfo.Approved, check.Equals, true)
+	c.Assert(evts[0].ApprovalInfo.Owner, check.Equals, "approver@example.com")
+}
+
+func (s *S) TestResumeApprovedByOtherOwner(c *check.C) {
+	SetApproval(ApprovalSpec{KindName: "app.deploy"})
+	pending := s.newPendingEvent(c)
+	err := pending.Approve(context.TODO(), "approver@example.com", "")
+	c.Assert(err, check.IsNil)
+	nativeScheme := auth.ManagedScheme(native.NativeScheme{})
+	_, err = nativeScheme.Create(context.TODO(), &auth.User{Email: "other@example.com", Password: "123456"})
+	c.Assert(err, check.IsNil)
+	other, err := nativeScheme.Login(context.TODO(), map[string]string{"email": "other@example.com", "password": "123456"})
+	c.Assert(err, check.IsNil)
+	_, err = ResumeApproved(context.TODO(), pending.UniqueID.Hex(), other)
+	c.Assert(err, check.Equals, ErrApprovalNotOwner)
+	_, err = ResumeApproved(context.TODO(), pending.UniqueID.Hex(), s.token)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestWithApprovedEventNotResumed(c *check.C) {
+	SetApproval(ApprovalSpec{KindName: "app.deploy"})
+	pending := s.newPendingEvent(c)
+	err := pending.Approve(context.TODO(), "approver@example.com", "")
+	c.Assert(err, check.IsNil)
+	approved, err := ResumeApproved(context.TODO(), pending.UniqueID.Hex(), s.token)
+	c.Assert(err, check.IsNil)
+	ctx, finish := WithApprovedEvent(context.TODO(), approved)
+	_, err = New(ctx, &Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "otherapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.FitsTypeOf, ErrApprovalPending{})
+	err.(ErrApprovalPending).Event.Abort(context.TODO())
+	finish(nil)
+	evt, err := GetByID(context.TODO(), pending.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, false)
+	c.Assert(evt.Error, check.Equals, "approved operation did not run")
+}
+
+func (s *S) TestNewEventRejected(c *check.C) {
+	SetApproval(ApprovalSpec{KindName: "app.deploy"})
+	pending := s.newPendingEvent(c)
+	err := pending.Reject(context.TODO(), "approver@example.com", "not today")
+	c.Assert(err, check.IsNil)
+	evts, err := All(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+	c.Assert(evts[0].ApprovalInfo.Rejected, check.Equals, true)
+	c.Assert(evts[0].Error, check.Matches, ".*rejected by approver@example.com: not today")
+	_, err = ResumeApproved(context.TODO(), pending.UniqueID.Hex(), s.token)
+	c.Assert(err, check.FitsTypeOf, ErrNotApproved{})
+	c.Assert(err, check.ErrorMatches, `event "app.deploy" on app\(myapp\) not approved: rejected by approver@example.com: not today`)
+}
+
+func (s *S) TestEventCleanerFinishesApprovedNotResumed(c *check.C) {
+	approvalResumeTimeout = 50 * time.Millisecond
+	defer func() { approvalResumeTimeout = 15 * time.Minute }()
+	SetApproval(ApprovalSpec{KindName: "app.deploy"})
+	pending := s.newPendingEvent(c)
+	err := pending.Approve(context.TODO(), "approver@example.com", "")
+	c.Assert(err, check.IsNil)
+	err = cleaner.tryCleaning()
+	c.Assert(err, check.IsNil)
+	evt, err := GetByID(context.TODO(), pending.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, true)
+	time.Sleep(100 * time.Millisecond)
+	err = cleaner.tryCleaning()
+	c.Assert(err, check.IsNil)
+	evt, err = GetByID(context.TODO(), pending.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Running, check.Equals, false)
+	c.Assert(evt.Error, check.Matches, ".*approved operation was not requested again")
+	_, err = ResumeApproved(context.TODO(), pending.UniqueID.Hex(), s.token)
+	c.Assert(err, check.FitsTypeOf, ErrNotApproved{})
+}
+
+func (s *S) TestEventCleanerFinishesExpiredApprovals(c *check.C) {
+	SetApproval(ApprovalSpec{KindName: "app.deploy", Timeout: 100 * time.Millisecond})
+	s.newPendingEvent(c)
+	time.Sleep(150 * time.Millisecond)
+	err := cleaner.tryCleaning()
+	c.Assert(err, check.IsNil)
+	evts, err := All(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+	c.Assert(evts[0].ApprovalInfo.Pending, check.Equals, false)
+	c.Assert(evts[0].Error, check.Matches, ".*approval timed out")
+}
+
+func (s *S) TestEventApproveByOwner(c *check.C) {
+	SetApproval(ApprovalSpec{KindName: "app.deploy"})
+	pending := s.newPendingEvent(c)
+	err := pending.Approve(context.TODO(), s.token.GetUserName(), "")
+	c.Assert(err, check.Equals, ErrSelfApproval)
+	err = pending.Reject(context.TODO(), "approver@example.com", "no")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestNewEventInternalSkipsApproval(c *check.C) {
+	SetApproval(ApprovalSpec{})
+	evt, err := NewInternal(context.TODO(), &Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		InternalKind: "healer",
+		Allowed:      Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.ApprovalInfo.Required, check.Equals, false)
+}
@@ -10,17 +10,16 @@ import (
 	"strings"
 	"time"
 
-	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/robfig/cron/v3"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
 	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
-
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
 )
 
-const eventBlockCollectionName = "event_blocks"
-
 type ErrActiveEventBlockNotFound struct {
 	id string
 }
@@ -43,16 +42,66 @@ func (e ErrEventBlocked) Error() string {
 	)
 }
 
-type Block struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty"`
-	StartTime  time.Time
-	EndTime    time.Time `bson:"endtime,omitempty"`
-	KindName   string
-	OwnerName  string
-	Target     eventTypes.Target `bson:"target,omitempty"`
-	Conditions map[string]string `bson:"conditions,omitempty"`
-	Reason     string
-	Active     bool
+// Block prevents events from being created while it's active. A block takes
+// effect at StartTime and, when EndTime is set, is deactivated by the event
+// cleaner once EndTime is reached. Blocks with a Window only take effect
+// inside the recurring window, and teams or roles in AllowedTeams and
+// AllowedRoles are able to bypass them.
+type Block eventTypes.Block
+
+// BlockWindow is a recurring window defined by a pair of cron expressions,
+// e.g. Start "0 18 * * 5" and End "0 8 * * 1" for a window going from Friday
+// 18:00 to Monday 08:00. Expressions are evaluated in Timezone, defaulting
+// to UTC.
+type BlockWindow = eventTypes.BlockWindow
+
+func validateBlockWindow(w *BlockWindow) error {
+	if w.Start == "" || w.End == "" {
+		return &tsuruErrors.ValidationError{Message: "block window requires both start and end"}
+	}
+	_, _, _, err := parseBlockWindow(w)
+	return err
+}
+
+func parseBlockWindow(w *BlockWindow) (start, end cron.Schedule, loc *time.Location, err error) {
+	start, err = cron.ParseStandard(w.Start)
+	if err != nil {
+		return nil, nil, nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid block window start: %v", err)}
+	}
+	end, err = cron.ParseStandard(w.End)
+	if err != nil {
+		return nil, nil, nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid block window end: %v", err)}
+	}
+	loc = time.UTC
+	if w.Timezone != "" {
+		loc, err = time.LoadLocation(w.Timezone)
+		if err != nil {
+			return nil, nil, nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid block window timezone: %v", err)}
+		}
+	}
+	return start, end, loc, nil
+}
+
+// blockWindowContains returns whether t is inside the window, which is the
+// case when the window end comes before the next window start.
+func blockWindowContains(w *BlockWindow, t time.Time) bool {
+	start, end, loc, err := parseBlockWindow(w)
+	if err != nil {
+		return false
+	}
+	t = t.In(loc)
+	return end.Next(t).Before(start.Next(t))
+}
+
+// InEffect returns whether the block applies to events created at t.
+func (b *Block) InEffect(t time.Time) bool {
+	if !b.Active || t.Before(b.StartTime) {
+		return false
+	}
+	if !b.EndTime.IsZero() && !t.Before(b.EndTime) {
+		return false
+	}
+	return b.Window == nil || blockWindowContains(b.Window, t)
 }
 
 type startCustomDataMatch struct {
@@ -102,97 +151,168 @@ func (b *Block) String() string {
 	if b.Target.Type != "" {
 		target = b.Target.String()
 	}
-	return fmt.Sprintf("block %s by %s on %s: %s", kind, owner, target, b.Reason)
+	msg := fmt.Sprintf("block %s by %s on %s", kind, owner, target)
+	if b.Window != nil {
+		msg += fmt.Sprintf(" from %q to %q", b.Window.Start, b.Window.End)
+	}
+	if !b.EndTime.IsZero() {
+		msg += fmt.Sprintf(" until %s", b.EndTime.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s: %s", msg, b.Reason)
 }
 
-func AddBlock(ctx context.Context, b *Block) error {
-	collection, err := storagev2.Collection(eventBlockCollectionName)
-	if err != nil {
-		return err
+// bypassedBy returns whether the owner of an event belongs to one of the
+// teams, or has one of the roles, allowed to bypass the block.
+func (b *Block) bypassedBy(ctx context.Context, owner auth.Token) (bool, error) {
+	if owner == nil || (len(b.AllowedTeams) == 0 && len(b.AllowedRoles) == 0) {
+		return false, nil
 	}
-	b.Active = true
-	b.ID = primitive.NewObjectID()
-	b.StartTime = time.Now()
+	if len(b.AllowedTeams) > 0 {
+		perms, err := owner.Permissions(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, p := range perms {
+			if p.Context.CtxType == permTypes.CtxTeam && contains(b.AllowedTeams, p.Context.Value) {
+				return true, nil
+			}
+		}
+	}
+	if len(b.AllowedRoles) > 0 {
+		u, err := owner.User(ctx)
+		if err != nil {
+			return false, err
+		}
+		roles := u.Roles
+		if len(u.Groups) > 0 {
+			groups, err := servicemanager.AuthGroup.List(ctx, u.Groups)
+			if err != nil {
+				return false, err
+			}
+			for _, g := range groups {
+				roles = append(roles, g.Roles...)
+			}
+		}
+		for _, r := range roles {
+			if contains(b.AllowedRoles, r.Name) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
 
-	_, err = collection.InsertOne(ctx, b)
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
 
+func eventStorage() (eventTypes.EventStorage, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
 	if err != nil {
-		return err
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
 	}
-
-	return nil
+	return dbDriver.EventStorage, nil
 }
 
-func RemoveBlock(ctx context.Context, id primitive.ObjectID) error {
-	collection, err := storagev2.Collection(eventBlockCollectionName)
+func AddBlock(ctx context.Context, b *Block) error {
+	es, err := eventStorage()
 	if err != nil {
 		return err
 	}
-	query := mongoBSON.M{"_id": id, "active": true}
-
-	result, err := collection.UpdateOne(ctx, query, mongoBSON.M{"$set": mongoBSON.M{"active": false, "endtime": time.Now()}})
-
-	if err == mongo.ErrNoDocuments {
-		return &ErrActiveEventBlockNotFound{id: id.Hex()}
+	now := time.Now()
+	if b.StartTime.IsZero() {
+		b.StartTime = now
+	}
+	if !b.EndTime.IsZero() && (!b.EndTime.After(b.StartTime) || !b.EndTime.After(now)) {
+		return &tsuruErrors.ValidationError{Message: "block end time must be in the future and after its start time"}
 	}
+	if b.Window != nil && *b.Window == (BlockWindow{}) {
+		b.Window = nil
+	}
+	if b.Window != nil {
+		err = validateBlockWindow(b.Window)
+		if err != nil {
+			return err
+		}
+	}
+	b.Active = true
+	b.ID = primitive.NewObjectID()
+	return es.InsertBlock(ctx, eventTypes.Block(*b))
+}
 
+func RemoveBlock(ctx context.Context, id primitive.ObjectID) error {
+	es, err := eventStorage()
 	if err != nil {
 		return err
 	}
-
-	if result.MatchedCount == 0 {
+	err = es.DeactivateBlock(ctx, id, time.Now())
+	if err == eventTypes.ErrActiveBlockNotFound {
 		return &ErrActiveEventBlockNotFound{id: id.Hex()}
 	}
-
 	return err
 }
 
 func ListBlocks(ctx context.Context, active *bool) ([]Block, error) {
-	query := mongoBSON.M{}
-	if active != nil {
-		query["active"] = *active
-	}
-	return listBlocks(ctx, query)
+	return listBlocks(ctx, active)
 }
 
-func listBlocks(ctx context.Context, query mongoBSON.M) ([]Block, error) {
-	collection, err := storagev2.Collection(eventBlockCollectionName)
+func listBlocks(ctx context.Context, active *bool) ([]Block, error) {
+	es, err := eventStorage()
 	if err != nil {
 		return nil, err
 	}
-	var blocks []Block
-
-	if query == nil {
-		query = mongoBSON.M{}
-	}
-
-	cursor, err := collection.Find(ctx, query, options.Find().SetSort(mongoBSON.M{"starttime": -1}))
+	dbBlocks, err := es.FindBlocks(ctx, active)
 	if err != nil {
 		return nil, err
 	}
+	var blocks []Block
+	for _, b := range dbBlocks {
+		blocks = append(blocks, Block(b))
+	}
+	return blocks, nil
+}
 
-	err = cursor.All(ctx, &blocks)
-
+// deactivateExpiredBlocks deactivates the active blocks whose end time has
+// been reached.
+func deactivateExpiredBlocks(ctx context.Context, now time.Time) error {
+	es, err := eventStorage()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return blocks, nil
+	return es.DeactivateExpiredBlocks(ctx, now)
 }
 
-func checkIsBlocked(ctx context.Context, evt *Event) error {
+func checkIsBlocked(ctx context.Context, evt *Event, owner auth.Token) error {
 	if evt.Target.Type == eventTypes.TargetTypeEventBlock {
 		return nil
 	}
 
-	blocks, err := listBlocks(ctx, mongoBSON.M{"active": true})
+	active := true
+	blocks, err := listBlocks(ctx, &active)
 	if err != nil {
 		return err
 	}
 
+	now := time.Now()
 	for _, b := range blocks {
-		if b.Blocks(evt) {
+		if !b.InEffect(now) || !b.Blocks(evt) {
+			continue
+		}
+		bypassed, err := b.bypassedBy(ctx, owner)
+		if err != nil {
+			return err
+		}
+		if !bypassed {
 			return ErrEventBlocked{event: evt, block: &b}
 		}
 	}
-	return nil
+	return checkIsFrozen(ctx, evt, now)
 }
@@ -9,7 +9,10 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	check "gopkg.in/check.v1"
@@ -124,7 +127,7 @@ func (s *S) TestCheckIsBlocked(c *check.C) {
 		{&Event{EventData: eventTypes.EventData{Kind: eventTypes.Kind{Name: "app.create"}, Target: eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "my-app"}, StartCustomData: bsonDataUnhandledFields}}, nil},
 	}
 	for i, t := range tt {
-		errBlock := checkIsBlocked(context.TODO(), t.event)
+		errBlock := checkIsBlocked(context.TODO(), t.event, nil)
 		var expectedErr error
 		if t.blockedBy != nil {
 			if errBlock == nil {
@@ -138,3 +141,118 @@ func (s *S) TestCheckIsBlocked(c *check.C) {
 		}
 	}
 }
+
+func (s *S) TestAddBlockInvalid(c *check.C) {
+	tt := []struct {
+		block *Block
+		err   string
+	}{
+		{&Block{Reason: "freeze", EndTime: time.Now().Add(-time.Hour)}, "block end time must be in the future and after its start time"},
+		{&Block{Reason: "freeze", StartTime: time.Now().Add(2 * time.Hour), EndTime: time.Now().Add(time.Hour)}, "block end time must be in the future and after its start time"},
+		{&Block{Reason: "freeze", Window: &BlockWindow{Start: "0 18 * * 5"}}, "block window requires both start and end"},
+		{&Block{Reason: "freeze", Window: &BlockWindow{Start: "0 18 * * 5", End: "invalid"}}, "invalid block window end: .*"},
+		{&Block{Reason: "freeze", Window: &BlockWindow{Start: "0 18 * * 5", End: "0 8 * * 1", Timezone: "Mars/Olympus"}}, "invalid block window timezone: .*"},
+	}
+	for _, t := range tt {
+		err := AddBlock(context.TODO(), t.block)
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+	blocks, err := listBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 0)
+}
+
+func (s *S) TestBlockInEffect(c *check.C) {
+	now := time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC) // Saturday
+	window := &BlockWindow{Start: "0 18 * * 5", End: "0 8 * * 1"}
+	tt := []struct {
+		block    Block
+		t        time.Time
+		expected bool
+	}{
+		{Block{Active: true, StartTime: now.Add(-time.Hour)}, now, true},
+		{Block{Active: false, StartTime: now.Add(-time.Hour)}, now, false},
+		{Block{Active: true, StartTime: now.Add(time.Hour)}, now, false},
+		{Block{Active: true, StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)}, now, true},
+		{Block{Active: true, StartTime: now.Add(-time.Hour), EndTime: now}, now, false},
+		{Block{Active: true, Window: window}, now, true},
+		{Block{Active: true, Window: window}, time.Date(2024, time.March, 1, 18, 0, 0, 0, time.UTC), true},
+		{Block{Active: true, Window: window}, time.Date(2024, time.March, 1, 17, 59, 0, 0, time.UTC), false},
+		{Block{Active: true, Window: window}, time.Date(2024, time.March, 4, 7, 59, 0, 0, time.UTC), true},
+		{Block{Active: true, Window: window}, time.Date(2024, time.March, 4, 8, 0, 0, 0, time.UTC), false},
+		{Block{Active: true, Window: window}, time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC), false},
+		{Block{Active: true, Window: &BlockWindow{Start: "0 18 * * 5", End: "0 8 * * 1", Timezone: "America/Sao_Paulo"}}, time.Date(2024, time.March, 1, 20, 0, 0, 0, time.UTC), false},
+		{Block{Active: true, Window: &BlockWindow{Start: "0 18 * * 5", End: "0 8 * * 1", Timezone: "America/Sao_Paulo"}}, time.Date(2024, time.March, 1, 21, 0, 0, 0, time.UTC), true},
+	}
+	for i, t := range tt {
+		c.Check(t.block.InEffect(t.t), check.Equals, t.expected, check.Commentf("(%d)", i))
+	}
+}
+
+type fakeBlockOwner struct {
+	authTypes.Token
+	user        *authTypes.User
+	permissions []permTypes.Permission
+}
+
+func (t *fakeBlockOwner) User(ctx context.Context) (*authTypes.User, error) {
+	return t.user, nil
+}
+
+func (t *fakeBlockOwner) Permissions(ctx context.Context) ([]permTypes.Permission, error) {
+	return t.permissions, nil
+}
+
+func (s *S) TestCheckIsBlockedBypass(c *check.C) {
+	block := &Block{KindName: "app.deploy", Reason: "freeze", AllowedTeams: []string{"sre"}, AllowedRoles: []string{"release-manager"}}
+	err := AddBlock(context.TODO(), block)
+	c.Assert(err, check.IsNil)
+	evt := &Event{EventData: eventTypes.EventData{Kind: eventTypes.Kind{Name: "app.deploy"}}}
+	tt := []struct {
+		owner   *fakeBlockOwner
+		blocked bool
+	}{
+		{&fakeBlockOwner{user: &authTypes.User{}}, true},
+		{&fakeBlockOwner{user: &authTypes.User{Roles: []authTypes.RoleInstance{{Name: "deployer"}}}}, true},
+		{&fakeBlockOwner{user: &authTypes.User{Roles: []authTypes.RoleInstance{{Name: "release-manager", ContextValue: "myteam"}}}}, false},
+		{&fakeBlockOwner{user: &authTypes.User{}, permissions: []permTypes.Permission{
+			{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxTeam, "dev")},
+		}}, true},
+		{&fakeBlockOwner{user: &authTypes.User{}, permissions: []permTypes.Permission{
+			{Scheme: permission.PermAppDeploy, Context: permission.Context(permTypes.CtxTeam, "sre")},
+		}}, false},
+	}
+	for i, t := range tt {
+		err = checkIsBlocked(context.TODO(), evt, t.owner)
+		if t.blocked {
+			c.Check(err, check.FitsTypeOf, ErrEventBlocked{}, check.Commentf("(%d)", i))
+		} else {
+			c.Check(err, check.IsNil, check.Commentf("(%d)", i))
+		}
+	}
+	err = checkIsBlocked(context.TODO(), evt, nil)
+	c.Assert(err, check.FitsTypeOf, ErrEventBlocked{})
+}
+
+func (s *S) TestCheckIsBlockedOutsideWindow(c *check.C) {
+	now := time.Now().UTC()
+	// a window that started an hour ago and ends in an hour is always in
+	// effect, while the one starting in an hour is never in effect
+	inWindow := &BlockWindow{
+		Start: now.Add(-time.Hour).Format("4 15 * * *"),
+		End:   now.Add(time.Hour).Format("4 15 * * *"),
+	}
+	outWindow := &BlockWindow{
+		Start: now.Add(time.Hour).Format("4 15 * * *"),
+		End:   now.Add(2 * time.Hour).Format("4 15 * * *"),
+	}
+	err := AddBlock(context.TODO(), &Block{KindName: "app.update", Reason: "freeze", Window: outWindow})
+	c.Assert(err, check.IsNil)
+	evt := &Event{EventData: eventTypes.EventData{Kind: eventTypes.Kind{Name: "app.update"}}}
+	err = checkIsBlocked(context.TODO(), evt, nil)
+	c.Assert(err, check.IsNil)
+	err = AddBlock(context.TODO(), &Block{KindName: "app.update", Reason: "freeze", Window: inWindow})
+	c.Assert(err, check.IsNil)
+	err = checkIsBlocked(context.TODO(), evt, nil)
+	c.Assert(err, check.FitsTypeOf, ErrEventBlocked{})
+}
@@ -60,9 +60,10 @@ var (
 )
 
 const (
-	rejectLocked    = "locked"
-	rejectBlocked   = "blocked"
-	rejectThrottled = "throttled"
+	rejectLocked      = "locked"
+	rejectBlocked     = "blocked"
+	rejectThrottled   = "throttled"
+	rejectNotApproved = "not-approved"
 
 	timeFormat = "2006-01-02 15:04:05 -0700"
 )
@@ -163,6 +164,10 @@ func Initialize() error {
 	if err != nil {
 		return errors.Wrap(err, "unable to load event throttling")
 	}
+	err = loadApprovals()
+	if err != nil {
+		return errors.Wrap(err, "unable to load event approvals")
+	}
 	cleaner.start()
 	return nil
 }
@@ -246,18 +251,19 @@ type TargetFilter struct {
 }
 
 type Filter struct {
-	Target         eventTypes.Target
-	KindType       eventTypes.KindType
-	KindNames      []string `form:"-"`
-	OwnerType      eventTypes.OwnerType
-	OwnerName      string
-	Since          time.Time
-	Until          time.Time
-	Running        *bool
-	ErrorOnly      bool
-	Raw            mongoBSON.M
-	AllowedTargets []TargetFilter
-	Permissions    []permTypes.Permission
+	Target          eventTypes.Target
+	KindType        eventTypes.KindType
+	KindNames       []string `form:"-"`
+	OwnerType       eventTypes.OwnerType
+	OwnerName       string
+	Since           time.Time
+	Until           time.Time
+	Running         *bool
+	ErrorOnly       bool
+	PendingApproval bool
+	Raw             mongoBSON.M
+	AllowedTargets  []TargetFilter
+	Permissions     []permTypes.Permission
 
 	Limit int
 	Skip  int
@@ -378,6 +384,9 @@ func (f *Filter) toQuery() (mongoBSON.M, error) {
 	if f.ErrorOnly {
 		query["error"] = mongoBSON.M{"$ne": ""}
 	}
+	if f.PendingApproval {
+		query["approvalinfo.pending"] = true
+	}
 	if f.Raw != nil {
 		for k, v := range f.Raw {
 			query[k] = v
@@ -716,8 +725,15 @@ func newEvt(ctx context.Context, opts *Opts) (evt *Event, err error) {
 
 func newEvtOnce(ctx context.Context, opts *Opts) (evt *Event, err error) {
 	var k eventTypes.Kind
+	var resumed bool
 	defer func() {
+		if resumed {
+			return
+		}
 		eventCurrent.WithLabelValues(k.Name).Inc()
+		if _, isPending := err.(ErrApprovalPending); isPending {
+			return
+		}
 		if err != nil {
 			reason := "other"
 			switch err.(type) {
@@ -728,7 +744,7 @@ func newEvtOnce(ctx context.Context, opts *Opts) (evt *Event, err error) {
 			case ErrThrottled:
 				reason = rejectThrottled
 			}
-			if !(reason == rejectBlocked) {
+			if reason != rejectBlocked {
 				eventCurrent.WithLabelValues(k.Name).Dec()
 			}
 			eventsRejected.WithLabelValues(k.Name, reason).Inc()
@@ -768,13 +784,11 @@ func newEvtOnce(ctx context.Context, opts *Opts) (evt *Event, err error) {
 			o.Type = eventTypes.OwnerTypeInternal
 		}
 	} else {
-		if token, ok := opts.Owner.(authTypes.NamedToken); ok {
-			o.Type = eventTypes.OwnerTypeToken
-			o.Name = token.GetTokenName()
-		} else {
-			o.Type = eventTypes.OwnerTypeUser
-			o.Name = opts.Owner.GetUserName()
-		}
+		o = tokenOwner(opts.Owner)
+	}
+	if approved := takeApprovedEvent(ctx, k, opts.Target, o); approved != nil {
+		resumed = true
+		return approved, nil
 	}
 
 	collection, err := storagev2.EventsCollection()
@@ -833,6 +847,13 @@ func newEvtOnce(ctx context.Context, opts *Opts) (evt *Event, err error) {
 	if opts.ExpireAt != nil {
 		evt.EventData.ExpireAt = *opts.ExpireAt
 	}
+	if spec := getApproval(evt); spec != nil {
+		evt.ApprovalInfo = eventTypes.ApprovalInfo{
+			Required: true,
+			Pending:  true,
+			Deadline: now.Add(spec.timeout()),
+		}
+	}
 
 	maxRetries := 1
 	for i := 0; i < maxRetries+1; i++ {
@@ -844,12 +865,15 @@ func newEvtOnce(ctx context.Context, opts *Opts) (evt *Event, err error) {
 				evt.Abort(context.TODO())
 				return nil, err
 			}
-			err = checkIsBlocked(ctx, evt)
+			err = checkIsBlocked(ctx, evt, opts.Owner)
 			if err != nil {
 				evt.Done(context.TODO(), err)
 				return nil, err
 			}
 			updater.add(uniqID)
+			if evt.ApprovalInfo.Pending {
+				return nil, ErrApprovalPending{Event: evt}
+			}
 			return evt, nil
 		}
 
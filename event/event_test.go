@@ -55,6 +55,7 @@ func (s *S) SetUpTest(c *check.C) {
 	defaultAppRetryTimeout = 200 * time.Millisecond
 	setBaseConfig()
 	throttlingInfo = map[string]ThrottlingSpec{}
+	approvalSpecs = nil
 	err := storagev2.ClearAllCollections(nil)
 	c.Assert(err, check.IsNil)
 	nativeScheme := auth.ManagedScheme(native.NativeScheme{})
@@ -0,0 +1,301 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+const freezeCollectionName = "change_freezes"
+
+var (
+	ErrFreezeNotFound      = errors.New("change freeze not found")
+	ErrFreezeAlreadyExists = errors.New("change freeze already exists")
+
+	// frozenKinds are the kinds of the events rejected while a change freeze
+	// is in effect, along with their sub kinds.
+	frozenKinds = []string{"app.deploy", "app.update", "job.deploy"}
+)
+
+type ErrEventFrozen struct {
+	event  *Event
+	freeze *Freeze
+}
+
+func (e ErrEventFrozen) Error() string {
+	return fmt.Sprintf("error running %q on %s(%s): %s",
+		e.event.Kind,
+		e.event.Target.Type,
+		e.event.Target.Value,
+		e.freeze,
+	)
+}
+
+// Freeze is a change freeze on a pool, optionally restricted to the apps and
+// jobs of a team. Deploys and updates of apps and jobs in the pool are
+// rejected from StartTime until EndTime.
+type Freeze struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Pool        string             `json:"pool"`
+	Team        string             `json:"team,omitempty" bson:"team,omitempty"`
+	Owner       string             `json:"owner"`
+	CreatedBy   string             `json:"createdBy"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	StartTime   time.Time          `json:"startTime"`
+	EndTime     time.Time          `json:"endTime"`
+}
+
+// InEffect returns whether the freeze applies to events created at t.
+func (f *Freeze) InEffect(t time.Time) bool {
+	return !t.Before(f.StartTime) && t.Before(f.EndTime)
+}
+
+// Freezes returns whether the freeze applies to the event, based on its kind
+// and on the pool and teams the event is allowed for. Internal events, e.g.
+// automatic rollbacks, are never frozen.
+func (f *Freeze) Freezes(e *Event) bool {
+	if e.Owner.Type == eventTypes.OwnerTypeInternal {
+		return false
+	}
+	frozen := false
+	for _, kind := range frozenKinds {
+		if e.Kind.Name == kind || strings.HasPrefix(e.Kind.Name, kind+".") {
+			frozen = true
+			break
+		}
+	}
+	if !frozen {
+		return false
+	}
+	var inPool, inTeam bool
+	for _, ctx := range e.Allowed.Contexts {
+		switch ctx.CtxType {
+		case permTypes.CtxPool:
+			inPool = inPool || ctx.Value == f.Pool
+		case permTypes.CtxTeam:
+			inTeam = inTeam || ctx.Value == f.Team
+		}
+	}
+	for _, et := range e.ExtraTargets {
+		if et.Target.Type == eventTypes.TargetTypePool && et.Target.Value == f.Pool {
+			inPool = true
+		}
+	}
+	return inPool && (f.Team == "" || inTeam)
+}
+
+func (f *Freeze) String() string {
+	msg := fmt.Sprintf("change freeze %q owned by team %q on pool %q", f.Name, f.Owner, f.Pool)
+	if f.Team != "" {
+		msg += fmt.Sprintf(" for team %q", f.Team)
+	}
+	msg += fmt.Sprintf(" until %s", f.EndTime.UTC().Format(time.RFC3339))
+	if f.Description != "" {
+		msg += ": " + f.Description
+	}
+	return msg
+}
+
+func (f *Freeze) validate() error {
+	if f.Name == "" {
+		return &tsuruErrors.ValidationError{Message: "change freeze name is required"}
+	}
+	if f.Pool == "" {
+		return &tsuruErrors.ValidationError{Message: "change freeze pool is required"}
+	}
+	if f.Owner == "" {
+		return &tsuruErrors.ValidationError{Message: "change freeze owner is required"}
+	}
+	if f.StartTime.IsZero() || f.EndTime.IsZero() {
+		return &tsuruErrors.ValidationError{Message: "change freeze start and end times are required"}
+	}
+	if !f.EndTime.After(f.StartTime) || !f.EndTime.After(time.Now()) {
+		return &tsuruErrors.ValidationError{Message: "change freeze end time must be in the future and after its start time"}
+	}
+	return nil
+}
+
+// AddFreeze validates and stores a new change freeze. Freeze names are unique
+// within a pool.
+func AddFreeze(ctx context.Context, f *Freeze) error {
+	err := f.validate()
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.Collection(freezeCollectionName)
+	if err != nil {
+		return err
+	}
+	f.ID = primitive.NewObjectID()
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now()
+	}
+	_, err = collection.InsertOne(ctx, f)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrFreezeAlreadyExists
+	}
+	return err
+}
+
+// RemoveFreeze removes the change freeze named name from pool.
+func RemoveFreeze(ctx context.Context, pool, name string) error {
+	collection, err := storagev2.Collection(freezeCollectionName)
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"pool": pool, "name": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrFreezeNotFound
+	}
+	return nil
+}
+
+// GetFreeze returns the change freeze named name from pool.
+func GetFreeze(ctx context.Context, pool, name string) (*Freeze, error) {
+	collection, err := storagev2.Collection(freezeCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	var f Freeze
+	err = collection.FindOne(ctx, mongoBSON.M{"pool": pool, "name": name}).Decode(&f)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrFreezeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ListFreezes returns the change freezes of pool, every pool when it's empty,
+// sorted by start time. When since is set, freezes that ended before it are
+// left out.
+func ListFreezes(ctx context.Context, pool string, since time.Time) ([]Freeze, error) {
+	query := mongoBSON.M{}
+	if pool != "" {
+		query["pool"] = pool
+	}
+	if !since.IsZero() {
+		query["endtime"] = mongoBSON.M{"$gt": since}
+	}
+	return listFreezes(ctx, query)
+}
+
+func listFreezes(ctx context.Context, query mongoBSON.M) ([]Freeze, error) {
+	collection, err := storagev2.Collection(freezeCollectionName)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, query, options.Find().SetSort(mongoBSON.M{"starttime": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var freezes []Freeze
+	err = cursor.All(ctx, &freezes)
+	if err != nil {
+		return nil, err
+	}
+	return freezes, nil
+}
+
+func checkIsFrozen(ctx context.Context, evt *Event, now time.Time) error {
+	freezes, err := listFreezes(ctx, mongoBSON.M{
+		"starttime": mongoBSON.M{"$lte": now},
+		"endtime":   mongoBSON.M{"$gt": now},
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range freezes {
+		if f.InEffect(now) && f.Freezes(evt) {
+			return ErrEventFrozen{event: evt, freeze: &f}
+		}
+	}
+	return nil
+}
+
+const icalTimeFormat = "20060102T150405Z"
+
+// WriteICal writes freezes to w as an iCalendar (RFC 5545) calendar, with
+// one event per freeze.
+func WriteICal(w io.Writer, freezes []Freeze) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		icalLine(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//tsuru//change freezes//EN")
+	line("CALSCALE", "GREGORIAN")
+	for _, f := range freezes {
+		summary := fmt.Sprintf("Change freeze %s on pool %s", f.Name, f.Pool)
+		if f.Team != "" {
+			summary += fmt.Sprintf(" for team %s", f.Team)
+		}
+		description := fmt.Sprintf("Owned by team %s, created by %s.", f.Owner, f.CreatedBy)
+		if f.Description != "" {
+			description = f.Description + "\n\n" + description
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", f.ID.Hex()+"@tsuru")
+		line("DTSTAMP", f.CreatedAt.UTC().Format(icalTimeFormat))
+		line("DTSTART", f.StartTime.UTC().Format(icalTimeFormat))
+		line("DTEND", f.EndTime.UTC().Format(icalTimeFormat))
+		line("SUMMARY", icalEscape(summary))
+		line("DESCRIPTION", icalEscape(description))
+		line("CATEGORIES", "CHANGE FREEZE")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func icalEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// icalLine writes a content line folded at 75 octets, without splitting
+// multi-byte characters, as required by the iCalendar format.
+func icalLine(w *bufio.Writer, s string) {
+	const limit = 75
+	size := 0
+	for _, r := range s {
+		n := len(string(r))
+		if size+n > limit {
+			w.WriteString("\r\n ")
+			size = 1
+		}
+		w.WriteRune(r)
+		size += n
+	}
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,239 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddFreeze(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	freeze := &Freeze{
+		Name:        "black-friday",
+		Description: "no changes during black friday",
+		Pool:        "prod",
+		Owner:       "sre",
+		CreatedBy:   "me@me.com",
+		StartTime:   now,
+		EndTime:     now.Add(time.Hour),
+	}
+	err := AddFreeze(context.TODO(), freeze)
+	c.Assert(err, check.IsNil)
+	c.Assert(freeze.ID.IsZero(), check.Equals, false)
+	c.Assert(freeze.CreatedAt.IsZero(), check.Equals, false)
+	dbFreeze, err := GetFreeze(context.TODO(), "prod", "black-friday")
+	c.Assert(err, check.IsNil)
+	freeze.CreatedAt = dbFreeze.CreatedAt
+	dbFreeze.StartTime = dbFreeze.StartTime.UTC()
+	dbFreeze.EndTime = dbFreeze.EndTime.UTC()
+	c.Assert(*dbFreeze, check.DeepEquals, *freeze)
+	err = AddFreeze(context.TODO(), &Freeze{Name: "black-friday", Pool: "prod", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)})
+	c.Assert(err, check.Equals, ErrFreezeAlreadyExists)
+	err = AddFreeze(context.TODO(), &Freeze{Name: "black-friday", Pool: "dev", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestAddFreezeConcurrent(c *check.C) {
+	now := time.Now()
+	errCh := make(chan error, 10)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errCh <- AddFreeze(context.TODO(), &Freeze{Name: "black-friday", Pool: "prod", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)})
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	var added int
+	for err := range errCh {
+		if err == nil {
+			added++
+			continue
+		}
+		c.Assert(err, check.Equals, ErrFreezeAlreadyExists)
+	}
+	c.Assert(added, check.Equals, 1)
+}
+
+func (s *S) TestAddFreezeInvalid(c *check.C) {
+	now := time.Now()
+	tests := []Freeze{
+		{Pool: "prod", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)},
+		{Name: "f1", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)},
+		{Name: "f1", Pool: "prod", StartTime: now, EndTime: now.Add(time.Hour)},
+		{Name: "f1", Pool: "prod", Owner: "sre", EndTime: now.Add(time.Hour)},
+		{Name: "f1", Pool: "prod", Owner: "sre", StartTime: now, EndTime: now.Add(-time.Minute)},
+		{Name: "f1", Pool: "prod", Owner: "sre", StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour)},
+	}
+	for i := range tests {
+		err := AddFreeze(context.TODO(), &tests[i])
+		c.Assert(err, check.NotNil, check.Commentf("test %d", i))
+	}
+}
+
+func (s *S) TestRemoveFreeze(c *check.C) {
+	now := time.Now()
+	err := AddFreeze(context.TODO(), &Freeze{Name: "f1", Pool: "prod", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	err = RemoveFreeze(context.TODO(), "prod", "f1")
+	c.Assert(err, check.IsNil)
+	_, err = GetFreeze(context.TODO(), "prod", "f1")
+	c.Assert(err, check.Equals, ErrFreezeNotFound)
+	err = RemoveFreeze(context.TODO(), "prod", "f1")
+	c.Assert(err, check.Equals, ErrFreezeNotFound)
+}
+
+func (s *S) TestListFreezes(c *check.C) {
+	now := time.Now()
+	for _, f := range []*Freeze{
+		{Name: "next", Pool: "prod", Owner: "sre", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)},
+		{Name: "current", Pool: "prod", Owner: "sre", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)},
+		{Name: "other", Pool: "dev", Owner: "sre", StartTime: now, EndTime: now.Add(time.Hour)},
+	} {
+		err := AddFreeze(context.TODO(), f)
+		c.Assert(err, check.IsNil)
+	}
+	freezes, err := ListFreezes(context.TODO(), "prod", time.Time{})
+	c.Assert(err, check.IsNil)
+	c.Assert(freezes, check.HasLen, 2)
+	c.Assert(freezes[0].Name, check.Equals, "current")
+	c.Assert(freezes[1].Name, check.Equals, "next")
+	freezes, err = ListFreezes(context.TODO(), "", time.Time{})
+	c.Assert(err, check.IsNil)
+	c.Assert(freezes, check.HasLen, 3)
+	freezes, err = ListFreezes(context.TODO(), "prod", now.Add(90*time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(freezes, check.HasLen, 1)
+	c.Assert(freezes[0].Name, check.Equals, "next")
+}
+
+func (s *S) TestFreezeFreezes(c *check.C) {
+	freeze := &Freeze{Name: "f1", Pool: "prod", Owner: "sre"}
+	teamFreeze := &Freeze{Name: "f2", Pool: "prod", Team: "team1", Owner: "sre"}
+	appEvent := func(kind, pool, team string) *Event {
+		return &Event{EventData: eventTypes.EventData{
+			Kind:  eventTypes.Kind{Type: eventTypes.KindTypePermission, Name: kind},
+			Owner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: "me@me.com"},
+			Allowed: eventTypes.AllowedPermission{
+				Scheme: permission.PermAppReadEvents.FullName(),
+				Contexts: []permTypes.PermissionContext{
+					permission.Context(permTypes.CtxTeam, team),
+					permission.Context(permTypes.CtxPool, pool),
+				},
+			},
+		}}
+	}
+	tests := []struct {
+		freeze   *Freeze
+		event    *Event
+		expected bool
+	}{
+		{freeze, appEvent("app.deploy", "prod", "team1"), true},
+		{freeze, appEvent("app.update", "prod", "team1"), true},
+		{freeze, appEvent("app.update.env.set", "prod", "team1"), true},
+		{freeze, appEvent("job.deploy", "prod", "team1"), true},
+		{freeze, appEvent("app.deploy", "dev", "team1"), false},
+		{freeze, appEvent("app.create", "prod", "team1"), false},
+		{freeze, appEvent("app.deployment", "prod", "team1"), false},
+		{teamFreeze, appEvent("app.deploy", "prod", "team1"), true},
+		{teamFreeze, appEvent("app.deploy", "prod", "team2"), false},
+	}
+	for i, tt := range tests {
+		c.Check(tt.freeze.Freezes(tt.event), check.Equals, tt.expected, check.Commentf("test %d", i))
+	}
+	internal := appEvent("app.deploy", "prod", "team1")
+	internal.Owner = eventTypes.Owner{Type: eventTypes.OwnerTypeInternal, Name: "auto-rollback"}
+	c.Assert(freeze.Freezes(internal), check.Equals, false)
+	withExtraTarget := &Event{EventData: eventTypes.EventData{
+		Kind:         eventTypes.Kind{Type: eventTypes.KindTypePermission, Name: "app.deploy"},
+		ExtraTargets: []eventTypes.ExtraTarget{{Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: "prod"}}},
+	}}
+	c.Assert(freeze.Freezes(withExtraTarget), check.Equals, true)
+}
+
+func (s *S) TestNewFrozen(c *check.C) {
+	now := time.Now()
+	err := AddFreeze(context.TODO(), &Freeze{
+		Name:        "black-friday",
+		Description: "sales are up",
+		Pool:        "prod",
+		Owner:       "sre",
+		StartTime:   now.Add(-time.Minute),
+		EndTime:     now.Add(time.Hour),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxPool, "prod")),
+	})
+	c.Assert(err, check.FitsTypeOf, ErrEventFrozen{})
+	c.Assert(err, check.ErrorMatches, `error running "app.deploy" on app\(myapp\): change freeze "black-friday" owned by team "sre" on pool "prod" until .*: sales are up`)
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxPool, "dev")),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Done(context.TODO(), nil), check.IsNil)
+}
+
+func (s *S) TestNewFreezeNotStarted(c *check.C) {
+	now := time.Now()
+	err := AddFreeze(context.TODO(), &Freeze{Name: "later", Pool: "prod", Owner: "sre", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)})
+	c.Assert(err, check.IsNil)
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxPool, "prod")),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Done(context.TODO(), nil), check.IsNil)
+}
+
+func (s *S) TestWriteICal(c *check.C) {
+	start := time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)
+	freezes := []Freeze{{
+		Name:        "black-friday",
+		Description: "sales, sales; and more sales",
+		Pool:        "prod",
+		Team:        "team1",
+		Owner:       "sre",
+		CreatedBy:   "me@me.com",
+		CreatedAt:   start.Add(-24 * time.Hour),
+		StartTime:   start,
+		EndTime:     start.Add(72 * time.Hour),
+	}}
+	var buf bytes.Buffer
+	err := WriteICal(&buf, freezes)
+	c.Assert(err, check.IsNil)
+	lines := strings.Split(buf.String(), "\r\n")
+	c.Assert(lines[0], check.Equals, "BEGIN:VCALENDAR")
+	c.Assert(lines[len(lines)-2], check.Equals, "END:VCALENDAR")
+	c.Assert(lines[len(lines)-1], check.Equals, "")
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	c.Assert(unfolded, check.Matches, `(?s).*\r\nUID:`+freezes[0].ID.Hex()+`@tsuru\r\n.*`)
+	c.Assert(unfolded, check.Matches, `(?s).*\r\nDTSTAMP:20261126T000000Z\r\n.*`)
+	c.Assert(unfolded, check.Matches, `(?s).*\r\nDTSTART:20261127T000000Z\r\nDTEND:20261130T000000Z\r\n.*`)
+	c.Assert(unfolded, check.Matches, `(?s).*\r\nSUMMARY:Change freeze black-friday on pool prod for team team1\r\n.*`)
+	c.Assert(unfolded, check.Matches, `(?s).*\r\nDESCRIPTION:sales\\, sales\\; and more sales\\n\\nOwned by team sre\\, created by me@me.com.\r\n.*`)
+	for _, l := range lines {
+		c.Assert(len(l) <= 75, check.Equals, true)
+	}
+}
@@ -7,17 +7,23 @@ package webhook
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/shutdown"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -26,6 +32,7 @@ import (
 	"github.com/tsuru/tsuru/storage"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	"github.com/tsuru/tsuru/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
@@ -33,8 +40,82 @@ var (
 
 	chanBufferSize   = 1000
 	defaultUserAgent = "tsuru-webhook-client/1.0"
+
+	signatureHeader             = "X-Tsuru-Signature"
+	timestampHeader             = "X-Tsuru-Timestamp"
+	minSecretLength             = 16
+	defaultSecretRotationPeriod = 24 * time.Hour
+
+	maxHistoryRequestBodySize  = 16 * 1024
+	maxHistoryResponseBodySize = 4 * 1024
+	maxAttemptsHistory         = 10
+	defaultDeliveryHistorySize = 100
+
+	deliveryPollInterval     = 5 * time.Second
+	deliveryLockTimeout      = 5 * time.Minute
+	defaultMaxAttempts       = 5
+	defaultInitialBackoff    = 10 * time.Second
+	defaultMaxBackoff        = time.Hour
+	defaultDeliveryRetention = 24 * time.Hour
 )
 
+type deliveryConfig struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	retention      time.Duration
+	historySize    int
+}
+
+func loadDeliveryConfig() deliveryConfig {
+	conf := deliveryConfig{
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		retention:      defaultDeliveryRetention,
+		historySize:    defaultDeliveryHistorySize,
+	}
+	if v, err := config.GetInt("events:webhooks:retry:max-attempts"); err == nil && v > 0 {
+		conf.maxAttempts = v
+	}
+	if v, err := config.GetDuration("events:webhooks:retry:initial-interval"); err == nil && v > 0 {
+		conf.initialBackoff = v
+	}
+	if v, err := config.GetDuration("events:webhooks:retry:max-interval"); err == nil && v > 0 {
+		conf.maxBackoff = v
+	}
+	if v, err := config.GetDuration("events:webhooks:delivery-retention"); err == nil && v > 0 {
+		conf.retention = v
+	}
+	if v, err := config.GetInt("events:webhooks:delivery-history-size"); err == nil && v > 0 {
+		conf.historySize = v
+	}
+	return conf
+}
+
+func secretRotationPeriod() time.Duration {
+	if v, err := config.GetDuration("events:webhooks:secret-rotation-period"); err == nil && v > 0 {
+		return v
+	}
+	return defaultSecretRotationPeriod
+}
+
+// backoff returns the exponential delay to wait before the next attempt,
+// given the number of attempts already made.
+func (c deliveryConfig) backoff(attempts int) time.Duration {
+	delay := c.initialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= c.maxBackoff {
+			return c.maxBackoff
+		}
+	}
+	if delay > c.maxBackoff {
+		return c.maxBackoff
+	}
+	return delay
+}
+
 func WebhookService() (eventTypes.WebhookService, error) {
 	dbDriver, err := storage.GetCurrentDbDriver()
 	if err != nil {
@@ -44,29 +125,37 @@ func WebhookService() (eventTypes.WebhookService, error) {
 		}
 	}
 	s := &webhookService{
-		storage: dbDriver.WebhookStorage,
-		evtCh:   make(chan string, chanBufferSize),
-		quitCh:  make(chan struct{}),
-		doneCh:  make(chan struct{}),
+		storage:         dbDriver.WebhookStorage,
+		deliveryStorage: dbDriver.WebhookDeliveryStorage,
+		conf:            loadDeliveryConfig(),
+		evtCh:           make(chan string, chanBufferSize),
+		quitCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+		retryDoneCh:     make(chan struct{}),
 	}
 	err = s.initMetrics()
 	if err != nil {
 		return nil, err
 	}
 	go s.run()
+	go s.runRetries()
 	shutdown.Register(s)
 	return s, nil
 }
 
 type webhookService struct {
-	storage eventTypes.WebhookStorage
-	evtCh   chan string
-	quitCh  chan struct{}
-	doneCh  chan struct{}
+	storage         eventTypes.WebhookStorage
+	deliveryStorage eventTypes.WebhookDeliveryStorage
+	conf            deliveryConfig
+	evtCh           chan string
+	quitCh          chan struct{}
+	doneCh          chan struct{}
+	retryDoneCh     chan struct{}
 
 	webhooksLatency prometheus.Histogram
 	webhooksTotal   prometheus.Counter
 	webhooksError   prometheus.Counter
+	webhooksDead    prometheus.Counter
 	webhooksQueue   prometheus.Collector
 }
 
@@ -83,6 +172,10 @@ func (s *webhookService) initMetrics() error {
 		Name: "tsuru_webhooks_calls_error",
 		Help: "The total number of webhooks calls with error",
 	})
+	s.webhooksDead = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_webhooks_deliveries_dead_total",
+		Help: "The total number of webhooks deliveries moved to the dead-letter state",
+	})
 	s.webhooksQueue = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
 		Name: "tsuru_webhooks_event_queue_current",
 		Help: "The current number of queued events waiting for webhooks processing",
@@ -93,6 +186,7 @@ func (s *webhookService) initMetrics() error {
 		s.webhooksLatency,
 		s.webhooksTotal,
 		s.webhooksError,
+		s.webhooksDead,
 		s.webhooksQueue,
 	} {
 		err := prometheus.Register(c)
@@ -107,12 +201,15 @@ func (s *webhookService) Shutdown(ctx context.Context) error {
 	prometheus.Unregister(s.webhooksLatency)
 	prometheus.Unregister(s.webhooksTotal)
 	prometheus.Unregister(s.webhooksError)
+	prometheus.Unregister(s.webhooksDead)
 	prometheus.Unregister(s.webhooksQueue)
 	close(s.quitCh)
-	select {
-	case <-s.doneCh:
-	case <-ctx.Done():
-		return ctx.Err()
+	for _, ch := range []chan struct{}{s.doneCh, s.retryDoneCh} {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 	return nil
 }
@@ -159,27 +256,146 @@ func (s *webhookService) handleEvent(ctx context.Context, evtID string) error {
 		return err
 	}
 	for _, h := range hooks {
-		err = s.doHook(h, evt)
+		s.deliver(ctx, h, evt)
+	}
+	return nil
+}
+
+// deliver persists a new delivery for the hook before making the first
+// attempt, so that failures can be retried later by runRetries.
+func (s *webhookService) deliver(ctx context.Context, hook eventTypes.Webhook, evt *event.Event) {
+	maxAttempts := hook.RetryPolicy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = s.conf.maxAttempts
+	}
+	now := time.Now().UTC()
+	delivery := eventTypes.WebhookDelivery{
+		ID:            uuid.NewString(),
+		WebhookName:   hook.Name,
+		EventID:       evt.UniqueID.Hex(),
+		Status:        eventTypes.WebhookDeliveryPending,
+		MaxAttempts:   maxAttempts,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		NextAttemptAt: now,
+		LockedUntil:   now.Add(deliveryLockTimeout),
+	}
+	err := s.deliveryStorage.Insert(ctx, delivery)
+	if err != nil {
+		log.Errorf("[webhooks] unable to persist delivery of webhook %q for event %q, trying only once: %v", hook.Name, delivery.EventID, err)
+		_, err = s.doHook(hook, evt)
 		if err != nil {
-			log.Errorf("[webhooks] error calling webhook %q for event %q: %v", h.Name, evtID, err)
+			log.Errorf("[webhooks] error calling webhook %q for event %q: %v", hook.Name, delivery.EventID, err)
 		}
+		return
+	}
+	s.attempt(ctx, &delivery, hook, evt)
+}
+
+func (s *webhookService) attempt(ctx context.Context, delivery *eventTypes.WebhookDelivery, hook eventTypes.Webhook, evt *event.Event) {
+	result, err := s.doHook(hook, evt)
+	if err != nil {
+		log.Errorf("[webhooks] error calling webhook %q for event %q (attempt %d/%d): %v", hook.Name, delivery.EventID, delivery.Attempts+1, delivery.MaxAttempts, err)
+	}
+	s.finishAttempt(ctx, delivery, result, err)
+}
+
+func (s *webhookService) finishAttempt(ctx context.Context, delivery *eventTypes.WebhookDelivery, result *eventTypes.WebhookDeliveryAttempt, hookErr error) {
+	now := time.Now().UTC()
+	if result == nil {
+		result = &eventTypes.WebhookDeliveryAttempt{EventID: delivery.EventID, Time: now}
+		if hookErr != nil {
+			result.Error = hookErr.Error()
+		}
+	}
+	delivery.History = append(delivery.History, *result)
+	if len(delivery.History) > maxAttemptsHistory {
+		delivery.History = delivery.History[len(delivery.History)-maxAttemptsHistory:]
+	}
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+	delivery.LockedUntil = time.Time{}
+	if hookErr == nil {
+		delivery.Status = eventTypes.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.ExpireAt = now.Add(s.conf.retention)
+	} else {
+		delivery.LastError = hookErr.Error()
+		if delivery.Attempts >= delivery.MaxAttempts {
+			delivery.Status = eventTypes.WebhookDeliveryDead
+			s.webhooksDead.Inc()
+		} else {
+			delivery.NextAttemptAt = now.Add(s.conf.backoff(delivery.Attempts))
+		}
+	}
+	err := s.deliveryStorage.Update(ctx, *delivery)
+	if err != nil {
+		log.Errorf("[webhooks] unable to update delivery %q of webhook %q: %v", delivery.ID, delivery.WebhookName, err)
+	}
+	if delivery.Status == eventTypes.WebhookDeliverySucceeded {
+		err = s.deliveryStorage.Prune(ctx, delivery.WebhookName, s.conf.historySize)
+		if err != nil {
+			log.Errorf("[webhooks] unable to prune deliveries of webhook %q: %v", delivery.WebhookName, err)
+		}
+	}
+}
+
+func (s *webhookService) runRetries() {
+	defer close(s.retryDoneCh)
+	for {
+		select {
+		case <-s.quitCh:
+			return
+		case <-time.After(deliveryPollInterval):
+		}
+		s.processDueDeliveries(context.Background())
+	}
+}
+
+func (s *webhookService) processDueDeliveries(ctx context.Context) {
+	for {
+		select {
+		case <-s.quitCh:
+			return
+		default:
+		}
+		delivery, err := s.deliveryStorage.AcquireNext(ctx, deliveryLockTimeout)
+		if err != nil {
+			if err != eventTypes.ErrWebhookDeliveryNotFound {
+				log.Errorf("[webhooks] unable to acquire pending delivery: %v", err)
+			}
+			return
+		}
+		hook, err := s.storage.FindByName(ctx, delivery.WebhookName)
+		if err != nil {
+			if err == eventTypes.ErrWebhookNotFound {
+				delivery.MaxAttempts = delivery.Attempts + 1
+			}
+			s.finishAttempt(ctx, delivery, nil, err)
+			continue
+		}
+		evt, err := event.GetByHexID(ctx, delivery.EventID)
+		if err != nil {
+			s.finishAttempt(ctx, delivery, nil, err)
+			continue
+		}
+		s.attempt(ctx, delivery, *hook, evt)
 	}
-	return nil
 }
 
-func webhookBody(hook *eventTypes.Webhook, evt *event.Event) (io.Reader, error) {
+func webhookBody(hook *eventTypes.Webhook, evt *event.Event) ([]byte, error) {
 	if hook.Body != "" {
 		tpl, err := template.New(hook.Name).Parse(hook.Body)
 		if err != nil {
 			log.Errorf("[webhooks] unable to parse hook body for %q as template, using raw string: %v", hook.Name, err)
-			return strings.NewReader(hook.Body), nil
+			return []byte(hook.Body), nil
 		}
 		buf := bytes.NewBuffer(nil)
 		err = tpl.Execute(buf, evt)
 		if err != nil {
 			return nil, err
 		}
-		return bytes.NewReader(buf.Bytes()), nil
+		return buf.Bytes(), nil
 	}
 	if hook.Method != http.MethodPost &&
 		hook.Method != http.MethodPut &&
@@ -190,18 +406,50 @@ func webhookBody(hook *eventTypes.Webhook, evt *event.Event) (io.Reader, error)
 		hook.Headers = make(http.Header)
 	}
 	hook.Headers.Set("Content-Type", "application/json")
-	data, err := json.Marshal(evt)
-	if err != nil {
-		return nil, err
+	return json.Marshal(evt)
+}
+
+// signRequest adds the timestamp and HMAC-SHA256 signature headers to the
+// request. The signed content is the timestamp, a dot and the request body.
+// While a rotation is in progress, one signature is sent for each valid
+// secret, so receivers may accept any of them.
+func signRequest(req *http.Request, hook eventTypes.Webhook, body []byte, now time.Time) {
+	if hook.Secret == "" {
+		return
+	}
+	secrets := []string{hook.Secret}
+	if hook.PreviousSecret != "" && now.Before(hook.PreviousSecretExpiresAt) {
+		secrets = append(secrets, hook.PreviousSecret)
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signatures := make([]string, len(secrets))
+	for i, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		signatures[i] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(signatureHeader, strings.Join(signatures, ","))
+}
+
+func truncate(data []byte, size int) string {
+	if len(data) > size {
+		data = data[:size]
 	}
-	return bytes.NewReader(data), nil
+	return string(data)
 }
 
-func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err error) {
+func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (attempt *eventTypes.WebhookDeliveryAttempt, err error) {
+	attempt = &eventTypes.WebhookDeliveryAttempt{
+		EventID: evt.UniqueID.Hex(),
+		Time:    time.Now().UTC(),
+	}
 	defer func() {
 		s.webhooksTotal.Inc()
 		if err != nil {
 			s.webhooksError.Inc()
+			attempt.Error = err.Error()
 		}
 	}()
 	hook.Method = strings.ToUpper(hook.Method)
@@ -210,17 +458,23 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 	}
 	body, err := webhookBody(&hook, evt)
 	if err != nil {
-		return err
+		return attempt, err
+	}
+	attempt.RequestBody = truncate(body, maxHistoryRequestBodySize)
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
 	}
-	req, err := http.NewRequest(hook.Method, hook.URL, body)
+	req, err := http.NewRequest(hook.Method, hook.URL, reqBody)
 	if err != nil {
-		return err
+		return attempt, err
 	}
 	req.Header = hook.Headers
 
 	if req.Header == nil {
 		req.Header = make(http.Header)
 	}
+	signRequest(req, hook, body, time.Now())
 
 	if req.UserAgent() == "" {
 		req.Header.Set("User-Agent", defaultUserAgent)
@@ -232,26 +486,29 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 	if hook.ProxyURL != "" {
 		client, err = tsuruNet.WithProxy(*client, hook.ProxyURL)
 		if err != nil {
-			return err
+			return attempt, err
 		}
 	} else {
 		client, err = tsuruNet.WithProxyFromConfig(*client, hook.URL)
 		if err != nil {
-			return err
+			return attempt, err
 		}
 	}
 	reqStart := time.Now()
 	rsp, err := client.Do(req)
-	s.webhooksLatency.Observe(time.Since(reqStart).Seconds())
+	attempt.Latency = time.Since(reqStart)
+	s.webhooksLatency.Observe(attempt.Latency.Seconds())
 	if err != nil {
-		return err
+		return attempt, err
 	}
 	defer rsp.Body.Close()
+	attempt.ResponseStatus = rsp.StatusCode
+	data, _ := io.ReadAll(io.LimitReader(rsp.Body, int64(maxHistoryResponseBodySize)))
+	attempt.ResponseBody = string(data)
 	if rsp.StatusCode < 200 || rsp.StatusCode >= 400 {
-		data, _ := io.ReadAll(rsp.Body)
-		return errors.Errorf("invalid status code calling hook: %d: %s", rsp.StatusCode, string(data))
+		return attempt, errors.Errorf("invalid status code calling hook: %d: %s", rsp.StatusCode, string(data))
 	}
-	return nil
+	return attempt, nil
 }
 
 func validateURLs(w eventTypes.Webhook) error {
@@ -275,6 +532,52 @@ func validateURLs(w eventTypes.Webhook) error {
 	return nil
 }
 
+func validateSecret(w eventTypes.Webhook) error {
+	if w.Secret != "" && len(w.Secret) < minSecretLength {
+		return &tsuruErrors.ValidationError{
+			Message: fmt.Sprintf("webhook secret must have at least %d characters", minSecretLength),
+		}
+	}
+	return nil
+}
+
+// updateSecrets sets the secret fields in w based on the secrets currently
+// stored. An empty secret keeps the current one and a new secret starts a
+// rotation, in which the replaced secret remains valid for a while.
+func updateSecrets(w *eventTypes.Webhook, current *eventTypes.Webhook, now time.Time) {
+	switch {
+	case w.RemoveSecret:
+		w.Secret = ""
+		w.PreviousSecret = ""
+		w.PreviousSecretExpiresAt = time.Time{}
+	case w.Secret == "" || w.Secret == current.Secret:
+		w.Secret = current.Secret
+		w.PreviousSecret = current.PreviousSecret
+		w.PreviousSecretExpiresAt = current.PreviousSecretExpiresAt
+	case current.Secret != "":
+		w.PreviousSecret = current.Secret
+		w.PreviousSecretExpiresAt = now.Add(secretRotationPeriod())
+	default:
+		w.PreviousSecret = ""
+		w.PreviousSecretExpiresAt = time.Time{}
+	}
+	w.RemoveSecret = false
+}
+
+func redactSecrets(w *eventTypes.Webhook) {
+	w.HasSecret = w.Secret != ""
+	w.Secret = ""
+	w.PreviousSecret = ""
+	w.PreviousSecretExpiresAt = time.Time{}
+}
+
+func validateRetryPolicy(w eventTypes.Webhook) error {
+	if w.RetryPolicy.MaxAttempts < 0 {
+		return &tsuruErrors.ValidationError{Message: "webhook max attempts must not be negative"}
+	}
+	return nil
+}
+
 func (s *webhookService) Create(ctx context.Context, w eventTypes.Webhook) error {
 	if w.Name == "" {
 		return &tsuruErrors.ValidationError{Message: "webhook name must not be empty"}
@@ -288,6 +591,15 @@ func (s *webhookService) Create(ctx context.Context, w eventTypes.Webhook) error
 	if err != nil {
 		return err
 	}
+	err = validateRetryPolicy(w)
+	if err != nil {
+		return err
+	}
+	err = validateSecret(w)
+	if err != nil {
+		return err
+	}
+	updateSecrets(&w, &eventTypes.Webhook{}, time.Now().UTC())
 	return s.storage.Insert(ctx, w)
 }
 
@@ -296,11 +608,28 @@ func (s *webhookService) Update(ctx context.Context, w eventTypes.Webhook) error
 	if err != nil {
 		return err
 	}
+	err = validateRetryPolicy(w)
+	if err != nil {
+		return err
+	}
+	err = validateSecret(w)
+	if err != nil {
+		return err
+	}
+	current, err := s.storage.FindByName(ctx, w.Name)
+	if err != nil {
+		return err
+	}
+	updateSecrets(&w, current, time.Now().UTC())
 	return s.storage.Update(ctx, w)
 }
 
 func (s *webhookService) Delete(ctx context.Context, name string) error {
-	return s.storage.Delete(ctx, name)
+	err := s.storage.Delete(ctx, name)
+	if err != nil {
+		return err
+	}
+	return s.deliveryStorage.DeleteByWebhook(ctx, name)
 }
 
 func (s *webhookService) Find(ctx context.Context, name string) (eventTypes.Webhook, error) {
@@ -308,9 +637,80 @@ func (s *webhookService) Find(ctx context.Context, name string) (eventTypes.Webh
 	if err != nil {
 		return eventTypes.Webhook{}, err
 	}
+	redactSecrets(w)
 	return *w, nil
 }
 
 func (s *webhookService) List(ctx context.Context, teams []string) ([]eventTypes.Webhook, error) {
-	return s.storage.FindAllByTeams(ctx, teams)
+	hooks, err := s.storage.FindAllByTeams(ctx, teams)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hooks {
+		redactSecrets(&hooks[i])
+	}
+	return hooks, nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, filter eventTypes.WebhookDeliveryFilter) ([]eventTypes.WebhookDelivery, error) {
+	return s.deliveryStorage.Find(ctx, filter)
+}
+
+func (s *webhookService) RedriveDeliveries(ctx context.Context, filter eventTypes.WebhookDeliveryFilter) (int, error) {
+	return s.deliveryStorage.Redrive(ctx, filter)
+}
+
+func (s *webhookService) Test(ctx context.Context, name, evtID string) (*eventTypes.WebhookDeliveryAttempt, error) {
+	hook, err := s.storage.FindByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	var evt *event.Event
+	if evtID == "" {
+		evt = syntheticEvent(*hook)
+	} else {
+		evt, err = event.GetByHexID(ctx, evtID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result, err := s.doHook(*hook, evt)
+	if err != nil {
+		log.Debugf("[webhooks] error testing webhook %q: %v", name, err)
+	}
+	return result, nil
+}
+
+// syntheticEvent builds a finished event matching the webhook filter, to be
+// used when testing a webhook without a real event.
+func syntheticEvent(hook eventTypes.Webhook) *event.Event {
+	now := time.Now().UTC()
+	id := primitive.NewObjectID()
+	evt := &event.Event{EventData: eventTypes.EventData{
+		ID:        id,
+		UniqueID:  id,
+		StartTime: now,
+		EndTime:   now,
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: "sample-app"},
+		Kind:      eventTypes.Kind{Type: eventTypes.KindTypePermission, Name: "app.deploy"},
+		Owner:     eventTypes.Owner{Type: eventTypes.OwnerTypeInternal, Name: "webhook-test"},
+		Log:       "This is a test event sent by tsuru.\n",
+	}}
+	filter := hook.EventFilter
+	if len(filter.TargetTypes) > 0 {
+		evt.Target.Type = eventTypes.TargetType(filter.TargetTypes[0])
+	}
+	if len(filter.TargetValues) > 0 {
+		evt.Target.Value = filter.TargetValues[0]
+	}
+	if len(filter.KindTypes) > 0 {
+		evt.Kind.Type = eventTypes.KindType(filter.KindTypes[0])
+	}
+	if len(filter.KindNames) > 0 {
+		evt.Kind.Name = filter.KindNames[0]
+	}
+	if filter.ErrorOnly {
+		evt.Error = "this is a test error"
+	}
+	return evt
 }
@@ -6,12 +6,17 @@ package webhook
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/db/storagev2"
@@ -19,6 +24,7 @@ import (
 	"github.com/tsuru/tsuru/permission"
 	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
 	_ "github.com/tsuru/tsuru/storage/mongodb"
+	"github.com/tsuru/tsuru/tsurutest"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	check "gopkg.in/check.v1"
@@ -248,6 +254,200 @@ func (s *S) TestWebhookServiceNotifyProxy(c *check.C) {
 	c.Assert(receivedReq.Header.Get("Content-Type"), check.Equals, "application/json")
 }
 
+func (s *S) TestWebhookServiceNotifyPersistsDelivery(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppUpdateEnvSet,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err = s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name: "xyz",
+		URL:  srv.URL,
+	})
+	c.Assert(err, check.IsNil)
+	s.service.Notify(context.TODO(), evt.UniqueID.Hex())
+	var deliveries []eventTypes.WebhookDelivery
+	err = tsurutest.WaitCondition(5*time.Second, func() bool {
+		deliveries, err = s.service.ListDeliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "xyz"})
+		return err == nil && len(deliveries) == 1 && deliveries[0].Status != eventTypes.WebhookDeliveryPending
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliverySucceeded)
+	c.Assert(deliveries[0].Attempts, check.Equals, 1)
+	c.Assert(deliveries[0].MaxAttempts, check.Equals, defaultMaxAttempts)
+	c.Assert(deliveries[0].EventID, check.Equals, evt.UniqueID.Hex())
+	c.Assert(deliveries[0].History, check.HasLen, 1)
+	c.Assert(deliveries[0].History[0].ResponseStatus, check.Equals, http.StatusOK)
+	c.Assert(deliveries[0].History[0].RequestBody, check.Not(check.Equals), "")
+	c.Assert(deliveries[0].History[0].Error, check.Equals, "")
+}
+
+func (s *S) TestWebhookServiceNotifyRetriesUntilDead(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppUpdateEnvSet,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	s.service.conf.initialBackoff = time.Millisecond
+	err = s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name:        "xyz",
+		URL:         srv.URL,
+		RetryPolicy: eventTypes.WebhookRetryPolicy{MaxAttempts: 2},
+	})
+	c.Assert(err, check.IsNil)
+	s.service.Notify(context.TODO(), evt.UniqueID.Hex())
+	var deliveries []eventTypes.WebhookDelivery
+	err = tsurutest.WaitCondition(5*time.Second, func() bool {
+		deliveries, err = s.service.ListDeliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "xyz"})
+		return err == nil && len(deliveries) == 1 && deliveries[0].Attempts == 1
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliveryPending)
+	c.Assert(deliveries[0].LastError, check.Matches, "invalid status code calling hook: 503.*")
+	time.Sleep(10 * time.Millisecond)
+	s.service.processDueDeliveries(context.TODO())
+	deliveries, err = s.service.ListDeliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "xyz"})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliveryDead)
+	c.Assert(deliveries[0].Attempts, check.Equals, 2)
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(2))
+	n, err := s.service.RedriveDeliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "xyz"})
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 1)
+	deliveries, err = s.service.ListDeliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "xyz"})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliveryPending)
+	c.Assert(deliveries[0].Attempts, check.Equals, 0)
+}
+
+func (s *S) TestWebhookServiceTestSyntheticEvent(c *check.C) {
+	var receivedBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("received"))
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name: "xyz",
+		URL:  srv.URL,
+		EventFilter: eventTypes.WebhookEventFilter{
+			TargetTypes:  []string{"job"},
+			TargetValues: []string{"myjob"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	result, err := s.service.Test(context.TODO(), "xyz", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.ResponseStatus, check.Equals, http.StatusAccepted)
+	c.Assert(result.ResponseBody, check.Equals, "received")
+	c.Assert(result.RequestBody, check.Equals, string(receivedBody))
+	c.Assert(result.Error, check.Equals, "")
+	var evt event.Event
+	err = json.Unmarshal(receivedBody, &evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Target, check.DeepEquals, eventTypes.Target{Type: "job", Value: "myjob"})
+	deliveries, err := s.service.ListDeliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "xyz"})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 0)
+}
+
+func (s *S) TestWebhookServiceTestNotFound(c *check.C) {
+	_, err := s.service.Test(context.TODO(), "xyz", "")
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
+}
+
+func (s *S) TestWebhookServiceNotifySigned(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppUpdateEnvSet,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	called := make(chan struct{})
+	var receivedReq *http.Request
+	var receivedBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(called)
+		receivedBody, _ = io.ReadAll(r.Body)
+		receivedReq = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	err = s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name:   "xyz",
+		URL:    srv.URL,
+		Body:   "{{.Kind.Name}}",
+		Secret: "my-very-secret-key",
+	})
+	c.Assert(err, check.IsNil)
+	s.service.Notify(context.TODO(), evt.UniqueID.Hex())
+	<-called
+	c.Assert(string(receivedBody), check.Equals, "app.update.env.set")
+	timestamp := receivedReq.Header.Get("X-Tsuru-Timestamp")
+	c.Assert(timestamp, check.Not(check.Equals), "")
+	mac := hmac.New(sha256.New, []byte("my-very-secret-key"))
+	mac.Write([]byte(timestamp + ".app.update.env.set"))
+	c.Assert(receivedReq.Header.Get("X-Tsuru-Signature"), check.Equals, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (s *S) TestSignRequestWithPreviousSecret(c *check.C) {
+	now := time.Unix(1700000000, 0)
+	hook := eventTypes.Webhook{
+		Secret:                  "new-secret-0123456789",
+		PreviousSecret:          "old-secret-0123456789",
+		PreviousSecretExpiresAt: now.Add(time.Hour),
+	}
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("1700000000.body"))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	signRequest(req, hook, []byte("body"), now)
+	c.Assert(req.Header.Get("X-Tsuru-Timestamp"), check.Equals, "1700000000")
+	c.Assert(req.Header.Get("X-Tsuru-Signature"), check.Equals, sign("new-secret-0123456789")+","+sign("old-secret-0123456789"))
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	signRequest(req, hook, []byte("body"), now.Add(2*time.Hour))
+	c.Assert(req.Header.Get("X-Tsuru-Signature"), check.Equals, sign("new-secret-0123456789"))
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	signRequest(req, eventTypes.Webhook{}, []byte("body"), now)
+	c.Assert(req.Header.Get("X-Tsuru-Signature"), check.Equals, "")
+	c.Assert(req.Header.Get("X-Tsuru-Timestamp"), check.Equals, "")
+}
+
+func (s *S) TestDeliveryConfigBackoff(c *check.C) {
+	conf := deliveryConfig{initialBackoff: time.Second, maxBackoff: 10 * time.Second}
+	c.Assert(conf.backoff(1), check.Equals, time.Second)
+	c.Assert(conf.backoff(2), check.Equals, 2*time.Second)
+	c.Assert(conf.backoff(3), check.Equals, 4*time.Second)
+	c.Assert(conf.backoff(4), check.Equals, 8*time.Second)
+	c.Assert(conf.backoff(5), check.Equals, 10*time.Second)
+	c.Assert(conf.backoff(50), check.Equals, 10*time.Second)
+}
+
 func (s *S) TestWebhookServiceCreate(c *check.C) {
 	err := s.service.Create(context.TODO(), eventTypes.Webhook{
 		Name: "xyz",
@@ -282,6 +482,7 @@ func (s *S) TestWebhookServiceCreate(c *check.C) {
 func (s *S) TestWebhookServiceCreateInvalid(c *check.C) {
 	var tests = []struct {
 		name, url, proxyURL string
+		maxAttempts         int
 		expectedErr         string
 	}{
 		{
@@ -320,13 +521,20 @@ func (s *S) TestWebhookServiceCreateInvalid(c *check.C) {
 			proxyURL:    ":/:x",
 			expectedErr: "webhook proxy url is not valid: parse .*: missing protocol scheme",
 		},
+		{
+			name:        "d",
+			url:         "http://valid",
+			maxAttempts: -1,
+			expectedErr: "webhook max attempts must not be negative",
+		},
 	}
 
 	for _, test := range tests {
 		err := s.service.Create(context.TODO(), eventTypes.Webhook{
-			Name:     test.name,
-			URL:      test.url,
-			ProxyURL: test.proxyURL,
+			Name:        test.name,
+			URL:         test.url,
+			ProxyURL:    test.proxyURL,
+			RetryPolicy: eventTypes.WebhookRetryPolicy{MaxAttempts: test.maxAttempts},
 		})
 		if test.expectedErr == "" {
 			c.Check(err, check.IsNil)
@@ -362,6 +570,58 @@ func (s *S) TestWebhookServiceUpdate(c *check.C) {
 	})
 }
 
+func (s *S) TestWebhookServiceSecretRotation(c *check.C) {
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{
+		Name:   "xyz",
+		URL:    "http://a",
+		Secret: "first-secret-0123456789",
+	})
+	c.Assert(err, check.IsNil)
+	w, err := s.service.Find(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.HasSecret, check.Equals, true)
+	c.Assert(w.Secret, check.Equals, "")
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{
+		Name: "xyz",
+		URL:  "http://b",
+	})
+	c.Assert(err, check.IsNil)
+	stored, err := s.service.storage.FindByName(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Secret, check.Equals, "first-secret-0123456789")
+	c.Assert(stored.PreviousSecret, check.Equals, "")
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{
+		Name:   "xyz",
+		URL:    "http://b",
+		Secret: "second-secret-0123456789",
+	})
+	c.Assert(err, check.IsNil)
+	stored, err = s.service.storage.FindByName(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Secret, check.Equals, "second-secret-0123456789")
+	c.Assert(stored.PreviousSecret, check.Equals, "first-secret-0123456789")
+	c.Assert(stored.PreviousSecretExpiresAt.After(time.Now().Add(23*time.Hour)), check.Equals, true)
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{
+		Name:         "xyz",
+		URL:          "http://b",
+		RemoveSecret: true,
+	})
+	c.Assert(err, check.IsNil)
+	stored, err = s.service.storage.FindByName(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Secret, check.Equals, "")
+	c.Assert(stored.PreviousSecret, check.Equals, "")
+}
+
+func (s *S) TestWebhookServiceCreateShortSecret(c *check.C) {
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{
+		Name:   "xyz",
+		URL:    "http://a",
+		Secret: "short",
+	})
+	c.Assert(err, check.ErrorMatches, "webhook secret must have at least 16 characters")
+}
+
 func (s *S) TestWebhookServiceUpdateInvalid(c *check.C) {
 	err := s.service.Update(context.TODO(), eventTypes.Webhook{
 		Name: "xyz",
@@ -382,6 +642,21 @@ func (s *S) TestWebhookServiceDelete(c *check.C) {
 	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
 }
 
+func (s *S) TestWebhookServiceDeleteRemovesDeliveries(c *check.C) {
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{
+		Name: "xyz",
+		URL:  "http://a",
+	})
+	c.Assert(err, check.IsNil)
+	err = s.service.deliveryStorage.Insert(context.TODO(), eventTypes.WebhookDelivery{ID: "d1", WebhookName: "xyz"})
+	c.Assert(err, check.IsNil)
+	err = s.service.Delete(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	deliveries, err := s.service.ListDeliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "xyz"})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 0)
+}
+
 func (s *S) TestWebhookServiceDeleteNotFound(c *check.C) {
 	err := s.service.Delete(context.TODO(), "xyz")
 	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
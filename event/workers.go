@@ -6,6 +6,7 @@ package event
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -83,6 +84,62 @@ func (l *eventCleaner) tryCleaning() error {
 			eventsExpired.WithLabelValues(evt.Kind.Name).Inc()
 		}
 	}
+	err = finishExpiredApprovals(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "[events] [event cleaner] error finishing expired approvals")
+	}
+	err = deactivateExpiredBlocks(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "[events] [event cleaner] error deactivating expired blocks")
+	}
+	return nil
+}
+
+// finishExpiredApprovals finishes the events still pending approval after
+// their deadline or whose cancel was asked, and the approved events whose
+// operation wasn't requested again within approvalResumeTimeout.
+func finishExpiredApprovals(ctx context.Context, now time.Time) error {
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	var allData []eventTypes.EventData
+	cursor, err := collection.Find(ctx, mongoBSON.M{
+		"running": true,
+		"$or": []mongoBSON.M{
+			{"approvalinfo.pending": true, "approvalinfo.deadline": mongoBSON.M{"$lt": now}},
+			{"approvalinfo.pending": true, "cancelinfo.asked": true},
+			{
+				"approvalinfo.approved":     true,
+				"approvalinfo.resumed":      mongoBSON.M{"$ne": true},
+				"approvalinfo.decisiontime": mongoBSON.M{"$lt": now.Add(-approvalResumeTimeout)},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	err = cursor.All(ctx, &allData)
+	if err != nil {
+		return err
+	}
+	for _, evtData := range allData {
+		evt := Event{EventData: evtData}
+		cause := "approval timed out"
+		switch {
+		case evt.ApprovalInfo.Approved:
+			cause = "approved operation was not requested again"
+		case evt.CancelInfo.Asked:
+			cause = fmt.Sprintf("canceled by %s: %s", evt.CancelInfo.Owner, evt.CancelInfo.Reason)
+		}
+		evt.ApprovalInfo.Pending = false
+		err = evt.Done(ctx, ErrNotApproved{Event: &evt, Cause: cause})
+		if err != nil {
+			log.Errorf("[events] [event cleaner] error marking evt as done: %v", err)
+		} else {
+			eventsRejected.WithLabelValues(evt.Kind.Name, rejectNotApproved).Inc()
+		}
+	}
 	return nil
 }
 
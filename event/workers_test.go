@@ -213,3 +213,21 @@ func (s *S) TestEventCleaner(c *check.C) {
 	c.Assert(evts[0].Running, check.Equals, false)
 	c.Assert(evts[0].Error, check.Matches, `event expired, no update for .*ms`)
 }
+
+func (s *S) TestEventCleanerDeactivatesExpiredBlocks(c *check.C) {
+	cleaner.stop()
+	expiring := &Block{KindName: "app.deploy", Reason: "freeze", EndTime: time.Now().Add(100 * time.Millisecond)}
+	err := AddBlock(context.TODO(), expiring)
+	c.Assert(err, check.IsNil)
+	permanent := &Block{KindName: "app.create", Reason: "maintenance"}
+	err = AddBlock(context.TODO(), permanent)
+	c.Assert(err, check.IsNil)
+	time.Sleep(150 * time.Millisecond)
+	err = cleaner.tryCleaning()
+	c.Assert(err, check.IsNil)
+	active := true
+	blocks, err := ListBlocks(context.TODO(), &active)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 1)
+	c.Assert(blocks[0].ID, check.Equals, permanent.ID)
+}
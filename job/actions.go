@@ -62,13 +62,14 @@ var triggerCron = action.Action{
 		default:
 			return nil, errors.New("first parameter must be *Job")
 		}
+		args, _ := ctx.Params[1].(jobTypes.TriggerArgs)
 		prov, err := getProvisioner(ctx.Context, job)
 		if err != nil {
 			return nil, err
 		}
-		return nil, prov.TriggerCron(ctx.Context, job, job.Pool)
+		return nil, prov.TriggerCron(ctx.Context, job, job.Pool, args)
 	},
-	MinParams: 1,
+	MinParams: 2,
 }
 
 var updateJobProv = action.Action{
@@ -21,10 +21,12 @@ import (
 	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruEnvs "github.com/tsuru/tsuru/envs"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
+	"github.com/tsuru/tsuru/storage"
 	"github.com/tsuru/tsuru/streamfmt"
 	imgTypes "github.com/tsuru/tsuru/types/app/image"
 	authTypes "github.com/tsuru/tsuru/types/auth"
@@ -35,7 +37,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type jobService struct{}
+type jobService struct {
+	runStorage jobTypes.RunStorage
+}
 
 var _ jobTypes.JobService = &jobService{}
 
@@ -62,7 +66,14 @@ func Units(ctx context.Context, job *jobTypes.Job) ([]provTypes.Unit, error) {
 }
 
 func JobService() (jobTypes.JobService, error) {
-	return &jobService{}, nil
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &jobService{runStorage: dbDriver.JobRunStorage}, nil
 }
 
 func (*jobService) KillUnit(ctx context.Context, job *jobTypes.Job, unit string, force bool) error {
@@ -88,7 +99,7 @@ func (*jobService) GetByName(ctx context.Context, name string) (*jobTypes.Job, e
 	return &job, err
 }
 
-func (*jobService) RemoveJob(ctx context.Context, job *jobTypes.Job) error {
+func (s *jobService) RemoveJob(ctx context.Context, job *jobTypes.Job) error {
 	collection, err := storagev2.JobsCollection()
 	if err != nil {
 		return err
@@ -104,6 +115,10 @@ func (*jobService) RemoveJob(ctx context.Context, job *jobTypes.Job) error {
 	if result.DeletedCount == 0 {
 		return jobTypes.ErrJobNotFound
 	}
+	err = s.runStorage.DeleteByJob(ctx, job.Name)
+	if err != nil {
+		log.Errorf("unable to remove runs of job %q: %v", job.Name, err)
+	}
 
 	servicemanager.TeamQuota.Inc(ctx, &authTypes.Team{Name: job.TeamOwner}, -1)
 	var user *auth.User
@@ -415,8 +430,8 @@ func (*jobService) UpdateJobProv(ctx context.Context, job *jobTypes.Job) error {
 }
 
 // Trigger triggers an execution of either job or cronjob object
-func (*jobService) Trigger(ctx context.Context, job *jobTypes.Job) error {
-	return action.NewPipeline([]*action.Action{&triggerCron}...).Execute(ctx, job)
+func (*jobService) Trigger(ctx context.Context, job *jobTypes.Job, args jobTypes.TriggerArgs) error {
+	return action.NewPipeline([]*action.Action{&triggerCron}...).Execute(ctx, job, args)
 }
 
 func processTags(tags []string) []string {
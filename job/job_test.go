@@ -1014,7 +1014,7 @@ func (s *S) TestTriggerCronShouldExecuteJob(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(s.provisioner.ProvisionedJob(j1.Name), check.Equals, true)
 	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 0)
-	err = servicemanager.Job.Trigger(context.TODO(), &j1)
+	err = servicemanager.Job.Trigger(context.TODO(), &j1, jobTypes.TriggerArgs{})
 	c.Assert(err, check.IsNil)
 	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 1)
 }
@@ -0,0 +1,68 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+)
+
+const defaultMaxRuns = 100
+
+// maxRuns returns how many finished runs are kept for each job, set by
+// jobs:run-history:max-runs.
+func maxRuns() int {
+	n, err := config.GetInt("jobs:run-history:max-runs")
+	if err != nil || n <= 0 {
+		return defaultMaxRuns
+	}
+	return n
+}
+
+// RecordRun stores the current state of a job run, removing the oldest runs
+// of the job once it finishes. Runs started by workflows update their
+// workflow run step.
+func (s *jobService) RecordRun(ctx context.Context, run jobTypes.Run) error {
+	err := s.runStorage.Upsert(ctx, run)
+	if err != nil {
+		return err
+	}
+	if run.Workflow != nil {
+		err = servicemanager.JobWorkflow.UpdateStep(ctx, run)
+		if err != nil {
+			log.Errorf("unable to update step %q of workflow run %q: %v", run.Workflow.Step, run.Workflow.Run, err)
+		}
+	}
+	if !run.Finished() {
+		return nil
+	}
+	err = s.runStorage.Prune(ctx, run.JobName, maxRuns())
+	if err != nil {
+		log.Errorf("unable to prune runs of job %q: %v", run.JobName, err)
+	}
+	return nil
+}
+
+// ListRuns returns the runs of a job, the most recent first, without their
+// captured logs.
+func (s *jobService) ListRuns(ctx context.Context, job *jobTypes.Job, filter jobTypes.RunFilter) ([]jobTypes.Run, error) {
+	filter.JobName = job.Name
+	runs, err := s.runStorage.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		runs[i].Log = nil
+	}
+	return runs, nil
+}
+
+func (s *jobService) GetRun(ctx context.Context, job *jobTypes.Job, id string) (*jobTypes.Run, error) {
+	return s.runStorage.FindByID(ctx, job.Name, id)
+}
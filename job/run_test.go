@@ -0,0 +1,89 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestRecordRunPrunesFinishedRuns(c *check.C) {
+	config.Set("jobs:run-history:max-runs", 2)
+	defer config.Unset("jobs:run-history:max-runs")
+	j := &jobTypes.Job{Name: "myjob"}
+	now := time.Now().UTC()
+	for i, status := range []jobTypes.RunStatus{
+		jobTypes.RunStatusSucceeded,
+		jobTypes.RunStatusFailed,
+		jobTypes.RunStatusRunning,
+		jobTypes.RunStatusSucceeded,
+	} {
+		err := servicemanager.Job.RecordRun(context.TODO(), jobTypes.Run{
+			ID:        string(rune('a' + i)),
+			JobName:   j.Name,
+			Status:    status,
+			StartTime: now.Add(time.Duration(i) * time.Minute),
+		})
+		c.Assert(err, check.IsNil)
+	}
+	runs, err := servicemanager.Job.ListRuns(context.TODO(), j, jobTypes.RunFilter{})
+	c.Assert(err, check.IsNil)
+	var ids []string
+	for _, r := range runs {
+		ids = append(ids, r.ID)
+	}
+	c.Assert(ids, check.DeepEquals, []string{"d", "c", "b"})
+}
+
+func (s *S) TestListRunsOmitsLog(c *check.C) {
+	j := &jobTypes.Job{Name: "myjob"}
+	run := jobTypes.Run{
+		ID:        "r1",
+		JobName:   j.Name,
+		Status:    jobTypes.RunStatusSucceeded,
+		StartTime: time.Now().UTC(),
+		Log:       &jobTypes.RunLog{Entries: []jobTypes.RunLogEntry{{Unit: "myjob-abc", Message: "hello"}}},
+	}
+	err := servicemanager.Job.RecordRun(context.TODO(), run)
+	c.Assert(err, check.IsNil)
+	runs, err := servicemanager.Job.ListRuns(context.TODO(), j, jobTypes.RunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+	c.Assert(runs[0].Log, check.IsNil)
+	result, err := servicemanager.Job.GetRun(context.TODO(), j, "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Log.Entries, check.HasLen, 1)
+	_, err = servicemanager.Job.GetRun(context.TODO(), &jobTypes.Job{Name: "other"}, "r1")
+	c.Assert(err, check.Equals, jobTypes.ErrJobRunNotFound)
+}
+
+func (s *S) TestRemoveJobRemovesRuns(c *check.C) {
+	j := jobTypes.Job{
+		Name:      "myjob",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "busybox:1.28",
+				Command:          []string{"/bin/sh", "-c", "echo Hello!"},
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &j, s.user)
+	c.Assert(err, check.IsNil)
+	err = servicemanager.Job.RecordRun(context.TODO(), jobTypes.Run{ID: "r1", JobName: j.Name, Status: jobTypes.RunStatusSucceeded})
+	c.Assert(err, check.IsNil)
+	err = servicemanager.Job.RemoveJob(context.TODO(), &j)
+	c.Assert(err, check.IsNil)
+	runs, err := servicemanager.Job.ListRuns(context.TODO(), &j, jobTypes.RunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 0)
+}
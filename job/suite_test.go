@@ -188,4 +188,6 @@ func (s *S) SetUpTest(c *check.C) {
 	c.Assert(err, check.IsNil)
 	servicemanager.Job, err = JobService()
 	c.Assert(err, check.IsNil)
+	servicemanager.JobWorkflow, err = WorkflowService()
+	c.Assert(err, check.IsNil)
 }
@@ -0,0 +1,556 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+)
+
+// maxWorkflowRunUpdates limits how many times an update of a workflow run is
+// retried when the run is concurrently modified.
+const maxWorkflowRunUpdates = 10
+
+const defaultWorkflowStepTimeout = 24 * time.Hour
+
+// workflowStepTimeout returns how long a workflow step may run before it's
+// failed, set by jobs:workflows:step-timeout.
+func workflowStepTimeout() time.Duration {
+	timeout, err := config.GetDuration("jobs:workflows:step-timeout")
+	if err != nil || timeout <= 0 {
+		return defaultWorkflowStepTimeout
+	}
+	return timeout
+}
+
+var workflowNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]{0,39}$`)
+
+type workflowService struct {
+	storage    jobTypes.WorkflowStorage
+	runStorage jobTypes.WorkflowRunStorage
+}
+
+var _ jobTypes.WorkflowService = &workflowService{}
+
+func WorkflowService() (jobTypes.WorkflowService, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &workflowService{
+		storage:    dbDriver.WorkflowStorage,
+		runStorage: dbDriver.WorkflowRunStorage,
+	}, nil
+}
+
+func (s *workflowService) Create(ctx context.Context, w jobTypes.Workflow) error {
+	if !workflowNameRegexp.MatchString(w.Name) {
+		return &tsuruErrors.ValidationError{Message: jobTypes.ErrInvalidWorkflowName.Error()}
+	}
+	err := validateWorkflow(ctx, &w)
+	if err != nil {
+		return err
+	}
+	w.LastScheduleTime = time.Now().UTC()
+	return s.storage.Insert(ctx, w)
+}
+
+func (s *workflowService) Update(ctx context.Context, w jobTypes.Workflow) error {
+	old, err := s.storage.FindByName(ctx, w.Name)
+	if err != nil {
+		return err
+	}
+	err = validateWorkflow(ctx, &w)
+	if err != nil {
+		return err
+	}
+	w.LastScheduleTime = old.LastScheduleTime
+	if w.Schedule != old.Schedule {
+		w.LastScheduleTime = time.Now().UTC()
+	}
+	return s.storage.Update(ctx, w)
+}
+
+func (s *workflowService) List(ctx context.Context, teams []string) ([]jobTypes.Workflow, error) {
+	return s.storage.FindAllByTeams(ctx, teams)
+}
+
+func (s *workflowService) Find(ctx context.Context, name string) (*jobTypes.Workflow, error) {
+	return s.storage.FindByName(ctx, name)
+}
+
+func (s *workflowService) Delete(ctx context.Context, name string) error {
+	err := s.storage.Delete(ctx, name)
+	if err != nil {
+		return err
+	}
+	err = s.runStorage.DeleteByWorkflow(ctx, name)
+	if err != nil {
+		log.Errorf("unable to remove runs of workflow %q: %v", name, err)
+	}
+	return nil
+}
+
+func validateWorkflow(ctx context.Context, w *jobTypes.Workflow) error {
+	_, err := servicemanager.Team.FindByName(ctx, w.TeamOwner)
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	if w.Schedule != "" {
+		if _, err = cron.ParseStandard(w.Schedule); err != nil {
+			return &tsuruErrors.ValidationError{Message: jobTypes.ErrInvalidSchedule.Error()}
+		}
+	}
+	err = validateWorkflowSteps(w.Steps)
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	for i := range w.Steps {
+		j, err := servicemanager.Job.GetByName(ctx, w.Steps[i].Job)
+		if err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("step %q: job %q: %v", w.Steps[i].Name, w.Steps[i].Job, err)}
+		}
+		w.Steps[i].JobTeam = j.TeamOwner
+		if w.Steps[i].Condition == "" {
+			w.Steps[i].Condition = jobTypes.WorkflowConditionSuccess
+		}
+	}
+	return nil
+}
+
+// validateWorkflowSteps checks the step names and conditions, and that their
+// dependencies exist and don't form cycles.
+func validateWorkflowSteps(steps []jobTypes.WorkflowStep) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("workflow must have at least one step")
+	}
+	deps := map[string][]string{}
+	for _, step := range steps {
+		if !workflowNameRegexp.MatchString(step.Name) {
+			return fmt.Errorf("invalid step name %q, it should have at most 40 characters, containing only lower case letters, numbers or dashes, starting with a letter", step.Name)
+		}
+		if _, ok := deps[step.Name]; ok {
+			return fmt.Errorf("duplicated step %q", step.Name)
+		}
+		if step.Job == "" {
+			return fmt.Errorf("step %q: job is required", step.Name)
+		}
+		switch step.Condition {
+		case "", jobTypes.WorkflowConditionSuccess, jobTypes.WorkflowConditionFailure, jobTypes.WorkflowConditionAlways:
+		default:
+			return fmt.Errorf("step %q: invalid condition %q, allowed values are: success, failure, always", step.Name, step.Condition)
+		}
+		deps[step.Name] = step.DependsOn
+	}
+	for _, step := range steps {
+		for _, dep := range step.DependsOn {
+			if _, ok := deps[dep]; !ok {
+				return fmt.Errorf("step %q depends on unknown step %q", step.Name, dep)
+			}
+		}
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		path = append(path, name)
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("steps have a dependency cycle: %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range deps[name] {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, step := range steps {
+		if err := visit(step.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeEnvs merges the lists of envs, the ones in later lists overriding the
+// ones with the same name in earlier lists.
+func mergeEnvs(lists ...[]bindTypes.EnvVar) []bindTypes.EnvVar {
+	var result []bindTypes.EnvVar
+	index := map[string]int{}
+	for _, envs := range lists {
+		for _, env := range envs {
+			if i, ok := index[env.Name]; ok {
+				result[i] = env
+				continue
+			}
+			index[env.Name] = len(result)
+			result = append(result, env)
+		}
+	}
+	return result
+}
+
+func (s *workflowService) Trigger(ctx context.Context, w *jobTypes.Workflow, args jobTypes.WorkflowTriggerArgs) (*jobTypes.WorkflowRun, error) {
+	err := checkStepJobTeams(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	run := &jobTypes.WorkflowRun{
+		ID:           uuid.NewString(),
+		WorkflowName: w.Name,
+		Trigger:      args.Trigger,
+		TriggeredBy:  args.TriggeredBy,
+		Status:       jobTypes.RunStatusRunning,
+		Envs:         mergeEnvs(w.Envs, args.Envs),
+		StartTime:    time.Now().UTC(),
+	}
+	for _, step := range w.Steps {
+		run.Steps = append(run.Steps, jobTypes.WorkflowStepRun{
+			WorkflowStep: step,
+			Status:       jobTypes.WorkflowStepPending,
+		})
+	}
+	err = s.runStorage.Insert(ctx, *run)
+	if err != nil {
+		return nil, err
+	}
+	err = s.updateRun(ctx, run, func(*jobTypes.WorkflowRun) {})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// checkStepJobTeams returns ErrWorkflowJobTeamChanged when the job of a step
+// is no longer owned by the team it had when the workflow was saved, as the
+// permission to run it was only checked for that team. Steps saved without a
+// team must run jobs of the team owning the workflow.
+func checkStepJobTeams(ctx context.Context, w *jobTypes.Workflow) error {
+	for _, step := range w.Steps {
+		j, err := servicemanager.Job.GetByName(ctx, step.Job)
+		if err == jobTypes.ErrJobNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		team := step.JobTeam
+		if team == "" {
+			team = w.TeamOwner
+		}
+		if j.TeamOwner != team {
+			return fmt.Errorf("step %q: %w", step.Name, jobTypes.ErrWorkflowJobTeamChanged)
+		}
+	}
+	return nil
+}
+
+// TriggerScheduled triggers the workflows whose next scheduled time after
+// their last schedule time is due. Only the API instance able to update the
+// last schedule time of a workflow triggers it.
+func (s *workflowService) TriggerScheduled(ctx context.Context, now time.Time) error {
+	workflows, err := s.storage.FindScheduled(ctx)
+	if err != nil {
+		return err
+	}
+	multiErr := tsuruErrors.NewMultiError()
+	for i := range workflows {
+		w := &workflows[i]
+		schedule, err := cron.ParseStandard(w.Schedule)
+		if err != nil {
+			multiErr.Add(fmt.Errorf("workflow %q: %w", w.Name, err))
+			continue
+		}
+		if schedule.Next(w.LastScheduleTime).After(now) {
+			continue
+		}
+		err = s.storage.UpdateLastSchedule(ctx, w.Name, w.LastScheduleTime, now)
+		if err == jobTypes.ErrWorkflowScheduleConflict {
+			continue
+		}
+		if err != nil {
+			multiErr.Add(fmt.Errorf("workflow %q: %w", w.Name, err))
+			continue
+		}
+		_, err = s.Trigger(ctx, w, jobTypes.WorkflowTriggerArgs{Trigger: jobTypes.RunTriggerCron})
+		if err != nil {
+			multiErr.Add(fmt.Errorf("workflow %q: %w", w.Name, err))
+		}
+	}
+	return multiErr.ToError()
+}
+
+// ReconcileRuns fails the steps of the running workflow runs started longer
+// than the step timeout ago. The jobs of these steps aren't stopped, their
+// later updates are just ignored.
+func (s *workflowService) ReconcileRuns(ctx context.Context, now time.Time) error {
+	runs, err := s.runStorage.Find(ctx, jobTypes.WorkflowRunFilter{Status: jobTypes.RunStatusRunning})
+	if err != nil {
+		return err
+	}
+	timeout := workflowStepTimeout()
+	multiErr := tsuruErrors.NewMultiError()
+	for i := range runs {
+		run := &runs[i]
+		steps := append([]jobTypes.WorkflowStepRun(nil), run.Steps...)
+		if !failTimedOutSteps(steps, now, timeout) {
+			continue
+		}
+		err = s.updateRun(ctx, run, func(r *jobTypes.WorkflowRun) {
+			failTimedOutSteps(r.Steps, now, timeout)
+		})
+		if err != nil {
+			multiErr.Add(fmt.Errorf("workflow %q run %q: %w", run.WorkflowName, run.ID, err))
+		}
+	}
+	return multiErr.ToError()
+}
+
+// failTimedOutSteps fails the running steps started longer than timeout
+// before now, returning whether any step failed.
+func failTimedOutSteps(steps []jobTypes.WorkflowStepRun, now time.Time, timeout time.Duration) bool {
+	var failed bool
+	for i := range steps {
+		step := &steps[i]
+		if step.Status != jobTypes.WorkflowStepRunning || now.Sub(step.StartTime) < timeout {
+			continue
+		}
+		step.Status = jobTypes.WorkflowStepFailed
+		step.Reason = fmt.Sprintf("step timed out after %v", timeout)
+		step.EndTime = now
+		failed = true
+	}
+	return failed
+}
+
+func (s *workflowService) UpdateStep(ctx context.Context, jobRun jobTypes.Run) error {
+	ref := jobRun.Workflow
+	if ref == nil {
+		return nil
+	}
+	run, err := s.runStorage.FindByID(ctx, ref.Workflow, ref.Run)
+	if err == jobTypes.ErrWorkflowRunNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	step := run.Step(ref.Step)
+	if step == nil || step.Finished() || (step.JobRun == jobRun.ID && !jobRun.Finished()) {
+		return nil
+	}
+	return s.updateRun(ctx, run, func(r *jobTypes.WorkflowRun) {
+		step := r.Step(ref.Step)
+		if step == nil || step.Finished() {
+			return
+		}
+		step.JobRun = jobRun.ID
+		if !jobRun.Finished() {
+			return
+		}
+		step.Status = jobTypes.WorkflowStepSucceeded
+		if jobRun.Status == jobTypes.RunStatusFailed {
+			step.Status = jobTypes.WorkflowStepFailed
+			step.Reason = jobRun.Reason
+		}
+		step.EndTime = jobRun.EndTime
+	})
+}
+
+// updateRun applies fn to the run, schedules its steps and stores it,
+// starting the steps that became ready. The run is reloaded and fn applied
+// again whenever the run is concurrently modified.
+func (s *workflowService) updateRun(ctx context.Context, run *jobTypes.WorkflowRun, fn func(*jobTypes.WorkflowRun)) error {
+	for i := 0; ; i++ {
+		fn(run)
+		started := scheduleSteps(run, time.Now().UTC())
+		err := s.runStorage.Update(ctx, *run)
+		if err == nil {
+			run.Version++
+			if run.Finished() {
+				s.pruneRuns(ctx, run.WorkflowName)
+			}
+			return s.startSteps(ctx, run, started)
+		}
+		if err != jobTypes.ErrWorkflowRunConflict || i == maxWorkflowRunUpdates {
+			return err
+		}
+		current, err := s.runStorage.FindByID(ctx, run.WorkflowName, run.ID)
+		if err != nil {
+			return err
+		}
+		*run = *current
+	}
+}
+
+func (s *workflowService) pruneRuns(ctx context.Context, workflowName string) {
+	err := s.runStorage.Prune(ctx, workflowName, maxRuns())
+	if err != nil {
+		log.Errorf("unable to prune runs of workflow %q: %v", workflowName, err)
+	}
+}
+
+// scheduleSteps moves the pending steps whose dependencies finished to
+// running, or to skipped when their condition isn't met, returning the names
+// of the steps to be started. The run finishes once all its steps finish,
+// failing if any of them failed.
+func scheduleSteps(run *jobTypes.WorkflowRun, now time.Time) []string {
+	if run.Finished() {
+		return nil
+	}
+	var started []string
+	for changed := true; changed; {
+		changed = false
+		for i := range run.Steps {
+			step := &run.Steps[i]
+			if step.Status != jobTypes.WorkflowStepPending {
+				continue
+			}
+			ready, met := stepCondition(run, step)
+			if !ready {
+				continue
+			}
+			changed = true
+			if !met {
+				step.Status = jobTypes.WorkflowStepSkipped
+				step.EndTime = now
+				continue
+			}
+			step.Status = jobTypes.WorkflowStepRunning
+			step.StartTime = now
+			started = append(started, step.Name)
+		}
+	}
+	status := jobTypes.RunStatusSucceeded
+	for _, step := range run.Steps {
+		if !step.Finished() {
+			return started
+		}
+		if step.Status == jobTypes.WorkflowStepFailed {
+			status = jobTypes.RunStatusFailed
+		}
+	}
+	run.Status = status
+	run.EndTime = now
+	return started
+}
+
+// stepCondition returns whether all the dependencies of the step finished
+// and, if so, whether the condition of the step is met.
+func stepCondition(run *jobTypes.WorkflowRun, step *jobTypes.WorkflowStepRun) (ready bool, met bool) {
+	var succeeded, failed int
+	for _, name := range step.DependsOn {
+		dep := run.Step(name)
+		if dep == nil {
+			continue
+		}
+		switch dep.Status {
+		case jobTypes.WorkflowStepPending, jobTypes.WorkflowStepRunning:
+			return false, false
+		case jobTypes.WorkflowStepSucceeded:
+			succeeded++
+		case jobTypes.WorkflowStepFailed:
+			failed++
+		}
+	}
+	switch step.Condition {
+	case jobTypes.WorkflowConditionFailure:
+		return true, failed > 0
+	case jobTypes.WorkflowConditionAlways:
+		return true, true
+	}
+	return true, succeeded == len(step.DependsOn)
+}
+
+// startSteps triggers the jobs of the steps, failing the steps whose job
+// couldn't be triggered.
+func (s *workflowService) startSteps(ctx context.Context, run *jobTypes.WorkflowRun, names []string) error {
+	failures := map[string]string{}
+	for _, name := range names {
+		err := s.startStep(ctx, run, run.Step(name))
+		if err != nil {
+			failures[name] = err.Error()
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return s.updateRun(ctx, run, func(r *jobTypes.WorkflowRun) {
+		for name, reason := range failures {
+			step := r.Step(name)
+			if step == nil || step.Status != jobTypes.WorkflowStepRunning || step.JobRun != "" {
+				continue
+			}
+			step.Status = jobTypes.WorkflowStepFailed
+			step.Reason = reason
+			step.EndTime = time.Now().UTC()
+		}
+	})
+}
+
+// startStep triggers the job of the step. Besides the envs of the run and
+// the step, the job gets the workflow, run and step names and the steps that
+// failed so far, so failure handlers know what to handle.
+func (s *workflowService) startStep(ctx context.Context, run *jobTypes.WorkflowRun, step *jobTypes.WorkflowStepRun) error {
+	j, err := servicemanager.Job.GetByName(ctx, step.Job)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, other := range run.Steps {
+		if other.Status == jobTypes.WorkflowStepFailed {
+			failed = append(failed, other.Name)
+		}
+	}
+	envs := mergeEnvs(run.Envs, step.Envs, []bindTypes.EnvVar{
+		{Name: "TSURU_WORKFLOW", Value: run.WorkflowName, Public: true},
+		{Name: "TSURU_WORKFLOW_RUN", Value: run.ID, Public: true},
+		{Name: "TSURU_WORKFLOW_STEP", Value: step.Name, Public: true},
+		{Name: "TSURU_WORKFLOW_FAILED_STEPS", Value: strings.Join(failed, ","), Public: true},
+	})
+	return servicemanager.Job.Trigger(ctx, j, jobTypes.TriggerArgs{
+		Trigger:     jobTypes.RunTriggerWorkflow,
+		TriggeredBy: run.WorkflowName,
+		Envs:        envs,
+		Workflow: &jobTypes.WorkflowRef{
+			Workflow: run.WorkflowName,
+			Run:      run.ID,
+			Step:     step.Name,
+		},
+	})
+}
+
+func (s *workflowService) ListRuns(ctx context.Context, w *jobTypes.Workflow, filter jobTypes.WorkflowRunFilter) ([]jobTypes.WorkflowRun, error) {
+	filter.WorkflowName = w.Name
+	return s.runStorage.Find(ctx, filter)
+}
+
+func (s *workflowService) GetRun(ctx context.Context, w *jobTypes.Workflow, id string) (*jobTypes.WorkflowRun, error) {
+	return s.runStorage.FindByID(ctx, w.Name, id)
+}
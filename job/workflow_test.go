@@ -0,0 +1,432 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) createWorkflowJobs(c *check.C, names ...string) {
+	for _, name := range names {
+		j := jobTypes.Job{
+			Name:      name,
+			TeamOwner: s.team.Name,
+			Pool:      s.Pool,
+			Spec: jobTypes.JobSpec{
+				Manual: true,
+				Container: jobTypes.ContainerInfo{
+					OriginalImageSrc: "busybox:1.28",
+					Command:          []string{"/bin/sh", "-c", "echo Hello!"},
+				},
+			},
+		}
+		err := servicemanager.Job.CreateJob(context.TODO(), &j, s.user)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *S) TestValidateWorkflowSteps(c *check.C) {
+	tests := []struct {
+		steps []jobTypes.WorkflowStep
+		err   string
+	}{
+		{
+			steps: nil,
+			err:   "workflow must have at least one step",
+		},
+		{
+			steps: []jobTypes.WorkflowStep{{Name: "Extract", Job: "job1"}},
+			err:   `invalid step name "Extract", .*`,
+		},
+		{
+			steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "job1"}, {Name: "extract", Job: "job2"}},
+			err:   `duplicated step "extract"`,
+		},
+		{
+			steps: []jobTypes.WorkflowStep{{Name: "extract"}},
+			err:   `step "extract": job is required`,
+		},
+		{
+			steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "job1", Condition: "sometimes"}},
+			err:   `step "extract": invalid condition "sometimes".*`,
+		},
+		{
+			steps: []jobTypes.WorkflowStep{{Name: "load", Job: "job1", DependsOn: []string{"extract"}}},
+			err:   `step "load" depends on unknown step "extract"`,
+		},
+		{
+			steps: []jobTypes.WorkflowStep{
+				{Name: "extract", Job: "job1", DependsOn: []string{"load"}},
+				{Name: "transform", Job: "job2", DependsOn: []string{"extract"}},
+				{Name: "load", Job: "job3", DependsOn: []string{"transform"}},
+			},
+			err: "steps have a dependency cycle: extract -> load -> transform -> extract",
+		},
+		{
+			steps: []jobTypes.WorkflowStep{
+				{Name: "extract", Job: "job1"},
+				{Name: "transform", Job: "job2", DependsOn: []string{"extract"}},
+				{Name: "load", Job: "job3", DependsOn: []string{"extract", "transform"}},
+				{Name: "notify", Job: "job4", DependsOn: []string{"load"}, Condition: jobTypes.WorkflowConditionFailure},
+			},
+		},
+	}
+	for i, tt := range tests {
+		err := validateWorkflowSteps(tt.steps)
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("test %d", i))
+			continue
+		}
+		c.Check(err, check.ErrorMatches, tt.err, check.Commentf("test %d", i))
+	}
+}
+
+func (s *S) TestScheduleSteps(c *check.C) {
+	now := time.Now().UTC()
+	run := &jobTypes.WorkflowRun{
+		Status: jobTypes.RunStatusRunning,
+		Steps: []jobTypes.WorkflowStepRun{
+			{WorkflowStep: jobTypes.WorkflowStep{Name: "extract"}, Status: jobTypes.WorkflowStepPending},
+			{WorkflowStep: jobTypes.WorkflowStep{Name: "load", DependsOn: []string{"extract"}}, Status: jobTypes.WorkflowStepPending},
+			{WorkflowStep: jobTypes.WorkflowStep{Name: "report", DependsOn: []string{"load"}}, Status: jobTypes.WorkflowStepPending},
+			{WorkflowStep: jobTypes.WorkflowStep{Name: "notify", DependsOn: []string{"extract", "load"}, Condition: jobTypes.WorkflowConditionFailure}, Status: jobTypes.WorkflowStepPending},
+			{WorkflowStep: jobTypes.WorkflowStep{Name: "cleanup", DependsOn: []string{"report"}, Condition: jobTypes.WorkflowConditionAlways}, Status: jobTypes.WorkflowStepPending},
+		},
+	}
+	started := scheduleSteps(run, now)
+	c.Assert(started, check.DeepEquals, []string{"extract"})
+	c.Assert(run.Step("extract").Status, check.Equals, jobTypes.WorkflowStepRunning)
+	c.Assert(run.Step("extract").StartTime, check.Equals, now)
+	started = scheduleSteps(run, now)
+	c.Assert(started, check.HasLen, 0)
+
+	run.Step("extract").Status = jobTypes.WorkflowStepSucceeded
+	started = scheduleSteps(run, now)
+	c.Assert(started, check.DeepEquals, []string{"load"})
+
+	run.Step("load").Status = jobTypes.WorkflowStepFailed
+	started = scheduleSteps(run, now)
+	c.Assert(started, check.DeepEquals, []string{"notify", "cleanup"})
+	c.Assert(run.Step("report").Status, check.Equals, jobTypes.WorkflowStepSkipped)
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusRunning)
+
+	run.Step("notify").Status = jobTypes.WorkflowStepSucceeded
+	run.Step("cleanup").Status = jobTypes.WorkflowStepSucceeded
+	started = scheduleSteps(run, now)
+	c.Assert(started, check.HasLen, 0)
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(run.EndTime, check.Equals, now)
+}
+
+func (s *S) TestScheduleStepsSkipsFailureHandlers(c *check.C) {
+	run := &jobTypes.WorkflowRun{
+		Status: jobTypes.RunStatusRunning,
+		Steps: []jobTypes.WorkflowStepRun{
+			{WorkflowStep: jobTypes.WorkflowStep{Name: "extract"}, Status: jobTypes.WorkflowStepSucceeded},
+			{WorkflowStep: jobTypes.WorkflowStep{Name: "notify", DependsOn: []string{"extract"}, Condition: jobTypes.WorkflowConditionFailure}, Status: jobTypes.WorkflowStepPending},
+		},
+	}
+	started := scheduleSteps(run, time.Now().UTC())
+	c.Assert(started, check.HasLen, 0)
+	c.Assert(run.Step("notify").Status, check.Equals, jobTypes.WorkflowStepSkipped)
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusSucceeded)
+}
+
+func (s *S) TestMergeEnvs(c *check.C) {
+	envs := mergeEnvs(
+		[]bindTypes.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "1"}},
+		nil,
+		[]bindTypes.EnvVar{{Name: "B", Value: "2"}, {Name: "C", Value: "2"}},
+	)
+	c.Assert(envs, check.DeepEquals, []bindTypes.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}, {Name: "C", Value: "2"}})
+}
+
+func (s *S) TestCreateWorkflow(c *check.C) {
+	s.createWorkflowJobs(c, "extract-job")
+	err := servicemanager.JobWorkflow.Create(context.TODO(), jobTypes.Workflow{
+		Name:      "etl",
+		TeamOwner: s.team.Name,
+		Schedule:  "0 3 * * *",
+		Steps:     []jobTypes.WorkflowStep{{Name: "extract", Job: "extract-job"}},
+	})
+	c.Assert(err, check.IsNil)
+	w, err := servicemanager.JobWorkflow.Find(context.TODO(), "etl")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Steps[0].Condition, check.Equals, jobTypes.WorkflowConditionSuccess)
+	c.Assert(w.LastScheduleTime.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestCreateWorkflowInvalid(c *check.C) {
+	s.createWorkflowJobs(c, "extract-job")
+	tests := []struct {
+		workflow jobTypes.Workflow
+		err      string
+	}{
+		{
+			workflow: jobTypes.Workflow{Name: "ETL", TeamOwner: s.team.Name, Steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "extract-job"}}},
+			err:      jobTypes.ErrInvalidWorkflowName.Error(),
+		},
+		{
+			workflow: jobTypes.Workflow{Name: "etl", TeamOwner: "other-team", Steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "extract-job"}}},
+			err:      authTypes.ErrTeamNotFound.Error(),
+		},
+		{
+			workflow: jobTypes.Workflow{Name: "etl", TeamOwner: s.team.Name, Schedule: "every day", Steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "extract-job"}}},
+			err:      jobTypes.ErrInvalidSchedule.Error(),
+		},
+		{
+			workflow: jobTypes.Workflow{Name: "etl", TeamOwner: s.team.Name, Steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "unknown-job"}}},
+			err:      `step "extract": job "unknown-job": Job not found`,
+		},
+	}
+	for i, tt := range tests {
+		err := servicemanager.JobWorkflow.Create(context.TODO(), tt.workflow)
+		c.Check(err, check.FitsTypeOf, &tsuruErrors.ValidationError{}, check.Commentf("test %d", i))
+		c.Check(err.Error(), check.Equals, tt.err, check.Commentf("test %d", i))
+	}
+}
+
+func (s *S) TestTriggerWorkflowStartsStepsWithoutDependencies(c *check.C) {
+	s.createWorkflowJobs(c, "extract-job", "load-job")
+	w := jobTypes.Workflow{
+		Name:      "etl",
+		TeamOwner: s.team.Name,
+		Envs:      []bindTypes.EnvVar{{Name: "REGION", Value: "us-east-1"}, {Name: "DATE", Value: "yesterday"}},
+		Steps: []jobTypes.WorkflowStep{
+			{Name: "extract", Job: "extract-job", Envs: []bindTypes.EnvVar{{Name: "SOURCE", Value: "db"}}},
+			{Name: "load", Job: "load-job", DependsOn: []string{"extract"}},
+		},
+	}
+	err := servicemanager.JobWorkflow.Create(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	run, err := servicemanager.JobWorkflow.Trigger(context.TODO(), &w, jobTypes.WorkflowTriggerArgs{
+		Trigger:     jobTypes.RunTriggerManual,
+		TriggeredBy: s.user.Email,
+		Envs:        []bindTypes.EnvVar{{Name: "DATE", Value: "2026-10-16"}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusRunning)
+	c.Assert(run.Step("extract").Status, check.Equals, jobTypes.WorkflowStepRunning)
+	c.Assert(run.Step("load").Status, check.Equals, jobTypes.WorkflowStepPending)
+	c.Assert(s.provisioner.JobTriggers("load-job"), check.HasLen, 0)
+	triggers := s.provisioner.JobTriggers("extract-job")
+	c.Assert(triggers, check.HasLen, 1)
+	c.Assert(triggers[0].Trigger, check.Equals, jobTypes.RunTriggerWorkflow)
+	c.Assert(triggers[0].TriggeredBy, check.Equals, "etl")
+	c.Assert(triggers[0].Workflow, check.DeepEquals, &jobTypes.WorkflowRef{Workflow: "etl", Run: run.ID, Step: "extract"})
+	envs := map[string]string{}
+	for _, env := range triggers[0].Envs {
+		envs[env.Name] = env.Value
+	}
+	c.Assert(envs, check.DeepEquals, map[string]string{
+		"REGION":                      "us-east-1",
+		"DATE":                        "2026-10-16",
+		"SOURCE":                      "db",
+		"TSURU_WORKFLOW":              "etl",
+		"TSURU_WORKFLOW_RUN":          run.ID,
+		"TSURU_WORKFLOW_STEP":         "extract",
+		"TSURU_WORKFLOW_FAILED_STEPS": "",
+	})
+	stored, err := servicemanager.JobWorkflow.GetRun(context.TODO(), &w, run.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Step("extract").Status, check.Equals, jobTypes.WorkflowStepRunning)
+}
+
+func (s *S) TestTriggerWorkflowFailsStepsWithoutJob(c *check.C) {
+	s.createWorkflowJobs(c, "extract-job", "notify-job")
+	w := jobTypes.Workflow{
+		Name:      "etl",
+		TeamOwner: s.team.Name,
+		Steps: []jobTypes.WorkflowStep{
+			{Name: "extract", Job: "extract-job"},
+			{Name: "notify", Job: "notify-job", DependsOn: []string{"extract"}, Condition: jobTypes.WorkflowConditionFailure},
+		},
+	}
+	err := servicemanager.JobWorkflow.Create(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	w.Steps[0].Job = "removed-job"
+	run, err := servicemanager.JobWorkflow.Trigger(context.TODO(), &w, jobTypes.WorkflowTriggerArgs{Trigger: jobTypes.RunTriggerAPI})
+	c.Assert(err, check.IsNil)
+	c.Assert(run.Step("extract").Status, check.Equals, jobTypes.WorkflowStepFailed)
+	c.Assert(run.Step("extract").Reason, check.Equals, jobTypes.ErrJobNotFound.Error())
+	c.Assert(run.Step("notify").Status, check.Equals, jobTypes.WorkflowStepRunning)
+	triggers := s.provisioner.JobTriggers("notify-job")
+	c.Assert(triggers, check.HasLen, 1)
+	c.Assert(triggers[0].Envs[len(triggers[0].Envs)-1], check.DeepEquals, bindTypes.EnvVar{Name: "TSURU_WORKFLOW_FAILED_STEPS", Value: "extract", Public: true})
+}
+
+func (s *S) TestTriggerWorkflowJobMovedToAnotherTeam(c *check.C) {
+	s.createWorkflowJobs(c, "extract-job")
+	w := jobTypes.Workflow{
+		Name:      "etl",
+		TeamOwner: s.team.Name,
+		Steps:     []jobTypes.WorkflowStep{{Name: "extract", Job: "extract-job"}},
+	}
+	err := servicemanager.JobWorkflow.Create(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	saved, err := servicemanager.JobWorkflow.Find(context.TODO(), "etl")
+	c.Assert(err, check.IsNil)
+	c.Assert(saved.Steps[0].JobTeam, check.Equals, s.team.Name)
+	saved.Steps[0].JobTeam = "other-team"
+	_, err = servicemanager.JobWorkflow.Trigger(context.TODO(), saved, jobTypes.WorkflowTriggerArgs{Trigger: jobTypes.RunTriggerCron})
+	c.Assert(errors.Is(err, jobTypes.ErrWorkflowJobTeamChanged), check.Equals, true)
+	c.Assert(s.provisioner.JobTriggers("extract-job"), check.HasLen, 0)
+}
+
+func (s *S) TestRecordRunUpdatesWorkflowStep(c *check.C) {
+	s.createWorkflowJobs(c, "extract-job", "load-job")
+	w := jobTypes.Workflow{
+		Name:      "etl",
+		TeamOwner: s.team.Name,
+		Steps: []jobTypes.WorkflowStep{
+			{Name: "extract", Job: "extract-job"},
+			{Name: "load", Job: "load-job", DependsOn: []string{"extract"}},
+		},
+	}
+	err := servicemanager.JobWorkflow.Create(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	run, err := servicemanager.JobWorkflow.Trigger(context.TODO(), &w, jobTypes.WorkflowTriggerArgs{Trigger: jobTypes.RunTriggerCron})
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC()
+	jobRun := jobTypes.Run{
+		ID:        "extract-run",
+		JobName:   "extract-job",
+		Status:    jobTypes.RunStatusRunning,
+		StartTime: now,
+		Workflow:  &jobTypes.WorkflowRef{Workflow: "etl", Run: run.ID, Step: "extract"},
+	}
+	err = servicemanager.Job.RecordRun(context.TODO(), jobRun)
+	c.Assert(err, check.IsNil)
+	stored, err := servicemanager.JobWorkflow.GetRun(context.TODO(), &w, run.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Step("extract").JobRun, check.Equals, "extract-run")
+	c.Assert(stored.Step("extract").Status, check.Equals, jobTypes.WorkflowStepRunning)
+	c.Assert(s.provisioner.JobTriggers("load-job"), check.HasLen, 0)
+
+	jobRun.Status = jobTypes.RunStatusSucceeded
+	jobRun.EndTime = now.Add(time.Minute)
+	err = servicemanager.Job.RecordRun(context.TODO(), jobRun)
+	c.Assert(err, check.IsNil)
+	stored, err = servicemanager.JobWorkflow.GetRun(context.TODO(), &w, run.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Step("extract").Status, check.Equals, jobTypes.WorkflowStepSucceeded)
+	c.Assert(stored.Step("load").Status, check.Equals, jobTypes.WorkflowStepRunning)
+	c.Assert(s.provisioner.JobTriggers("load-job"), check.HasLen, 1)
+
+	err = servicemanager.Job.RecordRun(context.TODO(), jobTypes.Run{
+		ID:       "load-run",
+		JobName:  "load-job",
+		Status:   jobTypes.RunStatusFailed,
+		Reason:   "BackoffLimitExceeded",
+		EndTime:  now.Add(2 * time.Minute),
+		Workflow: &jobTypes.WorkflowRef{Workflow: "etl", Run: run.ID, Step: "load"},
+	})
+	c.Assert(err, check.IsNil)
+	stored, err = servicemanager.JobWorkflow.GetRun(context.TODO(), &w, run.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Step("load").Status, check.Equals, jobTypes.WorkflowStepFailed)
+	c.Assert(stored.Step("load").Reason, check.Equals, "BackoffLimitExceeded")
+	c.Assert(stored.Status, check.Equals, jobTypes.RunStatusFailed)
+}
+
+func (s *S) TestReconcileRunsFailsTimedOutSteps(c *check.C) {
+	config.Set("jobs:workflows:step-timeout", "1h")
+	defer config.Unset("jobs:workflows:step-timeout")
+	s.createWorkflowJobs(c, "extract-job", "cleanup-job")
+	w := jobTypes.Workflow{
+		Name:      "etl",
+		TeamOwner: s.team.Name,
+		Steps: []jobTypes.WorkflowStep{
+			{Name: "extract", Job: "extract-job"},
+			{Name: "cleanup", Job: "cleanup-job", DependsOn: []string{"extract"}, Condition: jobTypes.WorkflowConditionFailure},
+		},
+	}
+	err := servicemanager.JobWorkflow.Create(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	run, err := servicemanager.JobWorkflow.Trigger(context.TODO(), &w, jobTypes.WorkflowTriggerArgs{Trigger: jobTypes.RunTriggerAPI})
+	c.Assert(err, check.IsNil)
+	startTime := run.Step("extract").StartTime
+
+	err = servicemanager.JobWorkflow.ReconcileRuns(context.TODO(), startTime.Add(59*time.Minute))
+	c.Assert(err, check.IsNil)
+	stored, err := servicemanager.JobWorkflow.GetRun(context.TODO(), &w, run.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Step("extract").Status, check.Equals, jobTypes.WorkflowStepRunning)
+
+	err = servicemanager.JobWorkflow.ReconcileRuns(context.TODO(), startTime.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	stored, err = servicemanager.JobWorkflow.GetRun(context.TODO(), &w, run.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Step("extract").Status, check.Equals, jobTypes.WorkflowStepFailed)
+	c.Assert(stored.Step("extract").Reason, check.Equals, "step timed out after 1h0m0s")
+	c.Assert(stored.Step("cleanup").Status, check.Equals, jobTypes.WorkflowStepRunning)
+	c.Assert(s.provisioner.JobTriggers("cleanup-job"), check.HasLen, 1)
+
+	err = servicemanager.Job.RecordRun(context.TODO(), jobTypes.Run{
+		ID:       "extract-run",
+		JobName:  "extract-job",
+		Status:   jobTypes.RunStatusSucceeded,
+		Workflow: &jobTypes.WorkflowRef{Workflow: "etl", Run: run.ID, Step: "extract"},
+	})
+	c.Assert(err, check.IsNil)
+	stored, err = servicemanager.JobWorkflow.GetRun(context.TODO(), &w, run.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Step("extract").Status, check.Equals, jobTypes.WorkflowStepFailed)
+}
+
+func (s *S) TestTriggerScheduledWorkflows(c *check.C) {
+	s.createWorkflowJobs(c, "extract-job")
+	for _, w := range []jobTypes.Workflow{
+		{Name: "hourly", TeamOwner: s.team.Name, Schedule: "0 * * * *", Steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "extract-job"}}},
+		{Name: "manual", TeamOwner: s.team.Name, Steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "extract-job"}}},
+	} {
+		err := servicemanager.JobWorkflow.Create(context.TODO(), w)
+		c.Assert(err, check.IsNil)
+	}
+	w, err := servicemanager.JobWorkflow.Find(context.TODO(), "hourly")
+	c.Assert(err, check.IsNil)
+	schedule, err := cron.ParseStandard(w.Schedule)
+	c.Assert(err, check.IsNil)
+	now := schedule.Next(w.LastScheduleTime)
+	err = servicemanager.JobWorkflow.TriggerScheduled(context.TODO(), now.Add(-time.Second))
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.JobTriggers("extract-job"), check.HasLen, 0)
+	err = servicemanager.JobWorkflow.TriggerScheduled(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	err = servicemanager.JobWorkflow.TriggerScheduled(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.JobTriggers("extract-job"), check.HasLen, 1)
+	runs, err := servicemanager.JobWorkflow.ListRuns(context.TODO(), w, jobTypes.WorkflowRunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+	c.Assert(runs[0].Trigger, check.Equals, jobTypes.RunTriggerCron)
+}
+
+func (s *S) TestDeleteWorkflowRemovesRuns(c *check.C) {
+	s.createWorkflowJobs(c, "extract-job")
+	w := jobTypes.Workflow{Name: "etl", TeamOwner: s.team.Name, Steps: []jobTypes.WorkflowStep{{Name: "extract", Job: "extract-job"}}}
+	err := servicemanager.JobWorkflow.Create(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.JobWorkflow.Trigger(context.TODO(), &w, jobTypes.WorkflowTriggerArgs{Trigger: jobTypes.RunTriggerAPI})
+	c.Assert(err, check.IsNil)
+	err = servicemanager.JobWorkflow.Delete(context.TODO(), "etl")
+	c.Assert(err, check.IsNil)
+	_, err = servicemanager.JobWorkflow.Find(context.TODO(), "etl")
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowNotFound)
+	runs, err := servicemanager.JobWorkflow.ListRuns(context.TODO(), &w, jobTypes.WorkflowRunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 0)
+}
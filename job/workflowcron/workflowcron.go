@@ -0,0 +1,68 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package workflowcron runs the worker triggering the job workflows with a
+// schedule and failing the workflow steps that timed out.
+package workflowcron
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/servicemanager"
+)
+
+var runInterval = 15 * time.Second
+
+func Initialize() error {
+	w := &worker{once: &sync.Once{}}
+	w.start()
+	shutdown.Register(w)
+	return nil
+}
+
+type worker struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (w *worker) start() {
+	w.once.Do(func() {
+		w.stopCh = make(chan struct{})
+		go w.spin()
+	})
+}
+
+func (w *worker) Shutdown(ctx context.Context) error {
+	if w.stopCh == nil {
+		return nil
+	}
+	w.stopCh <- struct{}{}
+	w.stopCh = nil
+	w.once = &sync.Once{}
+	return nil
+}
+
+func (w *worker) spin() {
+	for {
+		now := time.Now().UTC()
+		err := servicemanager.JobWorkflow.TriggerScheduled(context.Background(), now)
+		if err != nil {
+			log.Errorf("[workflow cron] %v", err)
+		}
+		err = servicemanager.JobWorkflow.ReconcileRuns(context.Background(), now)
+		if err != nil {
+			log.Errorf("[workflow cron] %v", err)
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-time.After(runInterval):
+		}
+	}
+}
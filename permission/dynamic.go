@@ -9,11 +9,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/storage"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	serviceTypes "github.com/tsuru/tsuru/types/service"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 const (
@@ -52,13 +50,15 @@ func NewDynamic(name string) (*permTypes.PermissionScheme, bool) {
 // declared by an enabled service manifest.
 func ExistsDynamic(ctx context.Context, name string) (bool, error) {
 	serviceName, _, _ := strings.Cut(strings.TrimPrefix(name, DynamicPermissionPrefix+"."), ".")
-	servicesCollection, err := storagev2.ServicesCollection()
+	dbDriver, err := storage.GetCurrentDbDriver()
 	if err != nil {
-		return false, err
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return false, err
+		}
 	}
-	var svc serviceTypes.Service
-	err = servicesCollection.FindOne(ctx, mongoBSON.M{"_id": serviceName}).Decode(&svc)
-	if err == mongo.ErrNoDocuments {
+	svc, err := dbDriver.ServiceStorage.FindByName(ctx, serviceName)
+	if err == serviceTypes.ErrServiceNotFound {
 		return false, nil
 	}
 	if err != nil {
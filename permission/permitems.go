@@ -93,6 +93,9 @@ var (
 	PermClusterReadEvents                = PermissionRegistry.get("cluster.read.events")                 // [global]
 	PermClusterUpdate                    = PermissionRegistry.get("cluster.update")                      // [global]
 	PermDebug                            = PermissionRegistry.get("debug")                               // [global]
+	PermEventApproval                    = PermissionRegistry.get("event-approval")                      // [global app team pool job]
+	PermEventApprovalApprove             = PermissionRegistry.get("event-approval.approve")              // [global app team pool job]
+	PermEventApprovalReject              = PermissionRegistry.get("event-approval.reject")               // [global app team pool job]
 	PermEventBlock                       = PermissionRegistry.get("event-block")                         // [global]
 	PermEventBlockAdd                    = PermissionRegistry.get("event-block.add")                     // [global]
 	PermEventBlockRead                   = PermissionRegistry.get("event-block.read")                    // [global]
@@ -132,6 +135,9 @@ var (
 	PermPoolUpdate                       = PermissionRegistry.get("pool.update")                         // [global pool]
 	PermPoolUpdateConstraints            = PermissionRegistry.get("pool.update.constraints")             // [global pool]
 	PermPoolUpdateConstraintsSet         = PermissionRegistry.get("pool.update.constraints.set")         // [global pool]
+	PermPoolUpdateFreeze                 = PermissionRegistry.get("pool.update.freeze")                  // [global pool]
+	PermPoolUpdateFreezeAdd              = PermissionRegistry.get("pool.update.freeze.add")              // [global pool]
+	PermPoolUpdateFreezeRemove           = PermissionRegistry.get("pool.update.freeze.remove")           // [global pool]
 	PermPoolUpdateTeam                   = PermissionRegistry.get("pool.update.team")                    // [global pool]
 	PermPoolUpdateTeamAdd                = PermissionRegistry.get("pool.update.team.add")                // [global pool]
 	PermPoolUpdateTeamRemove             = PermissionRegistry.get("pool.update.team.remove")             // [global pool]
@@ -207,10 +213,15 @@ var (
 	PermUserRead                         = PermissionRegistry.get("user.read")                           // [global user]
 	PermUserReadEvents                   = PermissionRegistry.get("user.read.events")                    // [global user]
 	PermUserReadQuota                    = PermissionRegistry.get("user.read.quota")                     // [global user]
+	PermUserToken                        = PermissionRegistry.get("user.token")                          // [global user]
+	PermUserTokenCreate                  = PermissionRegistry.get("user.token.create")                   // [global user]
+	PermUserTokenDelete                  = PermissionRegistry.get("user.token.delete")                   // [global user]
+	PermUserTokenRead                    = PermissionRegistry.get("user.token.read")                     // [global user]
 	PermUserUpdate                       = PermissionRegistry.get("user.update")                         // [global user]
 	PermUserUpdatePassword               = PermissionRegistry.get("user.update.password")                // [global user]
 	PermUserUpdateQuota                  = PermissionRegistry.get("user.update.quota")                   // [global user]
 	PermUserUpdateReset                  = PermissionRegistry.get("user.update.reset")                   // [global user]
+	PermUserUpdateStatus                 = PermissionRegistry.get("user.update.status")                  // [global user]
 	PermVolume                           = PermissionRegistry.get("volume")                              // [global volume team pool]
 	PermVolumeCreate                     = PermissionRegistry.get("volume.create")                       // [global team pool]
 	PermVolumeDelete                     = PermissionRegistry.get("volume.delete")                       // [global volume team pool]
@@ -225,4 +236,9 @@ var (
 	PermWebhookRead                      = PermissionRegistry.get("webhook.read")                        // [global team]
 	PermWebhookReadEvents                = PermissionRegistry.get("webhook.read.events")                 // [global team]
 	PermWebhookUpdate                    = PermissionRegistry.get("webhook.update")                      // [global team]
+	PermWorkloadIdentity                 = PermissionRegistry.get("workload-identity")                   // [global]
+	PermWorkloadIdentityDelete           = PermissionRegistry.get("workload-identity.delete")            // [global]
+	PermWorkloadIdentityRead             = PermissionRegistry.get("workload-identity.read")              // [global]
+	PermWorkloadIdentityReadEvents       = PermissionRegistry.get("workload-identity.read.events")       // [global]
+	PermWorkloadIdentityUpdate           = PermissionRegistry.get("workload-identity.update")            // [global]
 )
@@ -105,6 +105,10 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"user.update.quota",
 	"user.update.password",
 	"user.update.reset",
+	"user.update.status",
+	"user.token.read",
+	"user.token.create",
+	"user.token.delete",
 ).addWithCtx(
 	"apikey", []permTypes.ContextType{permTypes.CtxUser},
 ).add(
@@ -172,6 +176,8 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"pool.update.team.add",
 	"pool.update.team.remove",
 	"pool.update.constraints.set",
+	"pool.update.freeze.add",
+	"pool.update.freeze.remove",
 	"pool.read.constraints",
 	"pool.delete",
 ).add(
@@ -181,12 +187,22 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"event-block.read.events",
 	"event-block.add",
 	"event-block.remove",
+).addWithCtx(
+	"event-approval", []permTypes.ContextType{permTypes.CtxApp, permTypes.CtxTeam, permTypes.CtxPool, permTypes.CtxJob},
+).add(
+	"event-approval.approve",
+	"event-approval.reject",
 ).add(
 	"cluster.admin",
 	"cluster.read.events",
 	"cluster.create",
 	"cluster.update",
 	"cluster.delete",
+).add(
+	"workload-identity.read",
+	"workload-identity.read.events",
+	"workload-identity.update",
+	"workload-identity.delete",
 ).addWithCtx(
 	"volume", []permTypes.ContextType{permTypes.CtxVolume, permTypes.CtxTeam, permTypes.CtxPool},
 ).addWithCtx(
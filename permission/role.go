@@ -11,19 +11,30 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
-	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/storage"
 	permTypes "github.com/tsuru/tsuru/types/permission"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type Role struct {
-	Name               string                `bson:"_id" json:"name"`
-	ContextType        permTypes.ContextType `json:"context"`
-	Description        string
-	SchemeNames        []string `json:"scheme_names,omitempty"`
-	DynamicSchemeNames []string `bson:"dynamic_scheme_names,omitempty" json:"dynamic_scheme_names,omitempty"`
-	Events             []string `json:"events,omitempty"`
+type Role permTypes.Role
+
+func roleStorage() (permTypes.RoleStorage, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dbDriver.RoleStorage, nil
+}
+
+func toRoles(roles []permTypes.Role) []Role {
+	result := make([]Role, len(roles))
+	for i := range roles {
+		result[i] = Role(roles[i])
+		result[i].filterValidSchemes()
+	}
+	return result
 }
 
 func NewRole(ctx context.Context, name string, permissionCtx string, description string) (Role, error) {
@@ -36,80 +47,69 @@ func NewRole(ctx context.Context, name string, permissionCtx string, description
 		return Role{}, permTypes.ErrInvalidRoleName
 	}
 
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
 		return Role{}, err
 	}
 	role := Role{Name: name, ContextType: ctxType, Description: description}
-	_, err = collection.InsertOne(ctx, role)
-	if mongo.IsDuplicateKeyError(err) {
-		return Role{}, permTypes.ErrRoleAlreadyExists
+	err = rs.Insert(ctx, permTypes.Role(role))
+	if err != nil {
+		return Role{}, err
 	}
-	return role, err
+	return role, nil
 }
 
 func ListRoles(ctx context.Context) ([]Role, error) {
-	var roles []Role
-	collection, err := storagev2.RolesCollection()
-	if err != nil {
-		return roles, err
-	}
-
-	cursor, err := collection.Find(ctx, mongoBSON.M{})
+	rs, err := roleStorage()
 	if err != nil {
 		return nil, err
 	}
-	err = cursor.All(ctx, &roles)
+	roles, err := rs.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
-	for i := range roles {
-		roles[i].filterValidSchemes()
-	}
-	return roles, nil
+	return toRoles(roles), nil
 }
 
 func ListRolesWithEvents(ctx context.Context) ([]Role, error) {
-	var roles []Role
-	collection, err := storagev2.RolesCollection()
-	if err != nil {
-		return roles, err
-	}
-	cursor, err := collection.Find(ctx, mongoBSON.M{"events": mongoBSON.M{"$not": mongoBSON.M{"$size": 0}, "$exists": true}})
+	rs, err := roleStorage()
 	if err != nil {
 		return nil, err
 	}
-	err = cursor.All(ctx, &roles)
+	roles, err := rs.FindWithEvents(ctx)
 	if err != nil {
 		return nil, err
 	}
-	for i := range roles {
-		roles[i].filterValidSchemes()
-	}
-	return roles, nil
+	return toRoles(roles), nil
 }
 
 func ListRolesForEvent(ctx context.Context, evt *permTypes.RoleEvent) ([]Role, error) {
 	if evt == nil {
 		return nil, errors.New("invalid role event")
 	}
-	var roles []Role
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
-		return roles, err
+		return nil, err
 	}
-	cursor, err := collection.Find(ctx, mongoBSON.M{"events": evt.Name})
+	roles, err := rs.FindByEvent(ctx, evt.Name)
 	if err != nil {
 		return nil, err
 	}
-	err = cursor.All(ctx, &roles)
+	return toRoles(roles), nil
+}
+
+// ListRolesWithDynamicPermissionPrefix returns the roles granting a dynamic
+// permission equal to prefix or nested under it.
+func ListRolesWithDynamicPermissionPrefix(ctx context.Context, prefix string) ([]Role, error) {
+	rs, err := roleStorage()
 	if err != nil {
 		return nil, err
 	}
-	for i := range roles {
-		roles[i].filterValidSchemes()
+	roles, err := rs.FindByDynamicSchemePrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
 	}
-	return roles, nil
+	return toRoles(roles), nil
 }
 
 // ListRolesWithPermissionWithContextMap returns a map with all roles valid for a
@@ -144,43 +144,25 @@ func (r *Role) hasPermissionWithContext(contextValue permTypes.ContextType) bool
 }
 
 func FindRole(ctx context.Context, name string) (Role, error) {
-	var role Role
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
-		return role, err
-	}
-	err = collection.FindOne(ctx, mongoBSON.M{"_id": name}).Decode(&role)
-	if err == mongo.ErrNoDocuments {
-		return role, permTypes.ErrRoleNotFound
+		return Role{}, err
 	}
+	dbRole, err := rs.FindByName(ctx, name)
 	if err != nil {
-		return role, err
+		return Role{}, err
 	}
+	role := Role(*dbRole)
 	role.filterValidSchemes()
 	return role, nil
 }
 
 func DestroyRole(ctx context.Context, name string) error {
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
 		return err
 	}
-
-	result, err := collection.DeleteOne(ctx, mongoBSON.M{"_id": name})
-
-	if err == mongo.ErrNoDocuments {
-		return permTypes.ErrRoleNotFound
-	}
-
-	if err != nil {
-		return err
-	}
-
-	if result.DeletedCount == 0 {
-		return permTypes.ErrRoleNotFound
-	}
-
-	return nil
+	return rs.Delete(ctx, name)
 }
 
 func (r *Role) AddPermissions(ctx context.Context, permNames ...string) error {
@@ -206,11 +188,11 @@ func (r *Role) AddPermissions(ctx context.Context, permNames ...string) error {
 			}
 		}
 	}
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": r.Name}, mongoBSON.M{"$addToSet": mongoBSON.M{"schemenames": mongoBSON.M{"$each": permNames}}})
+	err = rs.AddSchemeNames(ctx, r.Name, permNames)
 	if err != nil {
 		return err
 	}
@@ -223,11 +205,11 @@ func (r *Role) AddPermissions(ctx context.Context, permNames ...string) error {
 }
 
 func (r *Role) RemovePermissions(ctx context.Context, permNames ...string) error {
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": r.Name}, mongoBSON.M{"$pullAll": mongoBSON.M{"schemenames": permNames}})
+	err = rs.RemoveSchemeNames(ctx, r.Name, permNames)
 	if err != nil {
 		return err
 	}
@@ -259,7 +241,11 @@ func (r *Role) AddDynamicPermissions(ctx context.Context, permNames ...string) e
 			}
 		}
 	}
-	err := r.updateDynamicSchemeNames(ctx, mongoBSON.M{"$addToSet": mongoBSON.M{"dynamic_scheme_names": mongoBSON.M{"$each": permNames}}})
+	rs, err := roleStorage()
+	if err != nil {
+		return err
+	}
+	err = rs.AddDynamicSchemeNames(ctx, r.Name, permNames)
 	if err != nil {
 		return err
 	}
@@ -272,7 +258,11 @@ func (r *Role) AddDynamicPermissions(ctx context.Context, permNames ...string) e
 }
 
 func (r *Role) RemoveDynamicPermissions(ctx context.Context, permNames ...string) error {
-	err := r.updateDynamicSchemeNames(ctx, mongoBSON.M{"$pullAll": mongoBSON.M{"dynamic_scheme_names": permNames}})
+	rs, err := roleStorage()
+	if err != nil {
+		return err
+	}
+	err = rs.RemoveDynamicSchemeNames(ctx, r.Name, permNames)
 	if err != nil {
 		return err
 	}
@@ -282,15 +272,6 @@ func (r *Role) RemoveDynamicPermissions(ctx context.Context, permNames ...string
 	return nil
 }
 
-func (r *Role) updateDynamicSchemeNames(ctx context.Context, update mongoBSON.M) error {
-	collection, err := storagev2.RolesCollection()
-	if err != nil {
-		return err
-	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": r.Name}, update)
-	return err
-}
-
 func (r *Role) filterValidSchemes() permTypes.PermissionSchemeList {
 	schemes := make(permTypes.PermissionSchemeList, 0, len(r.SchemeNames))
 	sort.Strings(r.SchemeNames)
@@ -355,11 +336,11 @@ func (r *Role) AddEvent(ctx context.Context, eventName string) error {
 	if r.ContextType != roleEvent.Context {
 		return permTypes.ErrRoleEventWrongContext{Expected: string(roleEvent.Context), Role: string(r.ContextType)}
 	}
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": r.Name}, mongoBSON.M{"$addToSet": mongoBSON.M{"events": eventName}})
+	err = rs.AddEvent(ctx, r.Name, eventName)
 	if err != nil {
 		return err
 	}
@@ -372,11 +353,11 @@ func (r *Role) AddEvent(ctx context.Context, eventName string) error {
 }
 
 func (r *Role) RemoveEvent(ctx context.Context, eventName string) error {
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": r.Name}, mongoBSON.M{"$pull": mongoBSON.M{"events": eventName}})
+	err = rs.RemoveEvent(ctx, r.Name, eventName)
 	if err != nil {
 		return err
 	}
@@ -389,16 +370,11 @@ func (r *Role) RemoveEvent(ctx context.Context, eventName string) error {
 }
 
 func (r *Role) Update(ctx context.Context) error {
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
 		return err
 	}
-	err = collection.FindOneAndUpdate(ctx, mongoBSON.M{"_id": r.Name}, mongoBSON.M{"$set": mongoBSON.M{"contexttype": r.ContextType, "description": r.Description}}).Err()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return rs.Update(ctx, permTypes.Role(*r))
 }
 
 func (r *Role) Add(ctx context.Context) error {
@@ -406,14 +382,10 @@ func (r *Role) Add(ctx context.Context) error {
 	if len(name) == 0 {
 		return permTypes.ErrInvalidRoleName
 	}
-	collection, err := storagev2.RolesCollection()
+	rs, err := roleStorage()
 	if err != nil {
 		return err
 	}
-	insertRole := Role{Name: name, ContextType: r.ContextType, Description: r.Description, SchemeNames: r.SchemeNames, Events: r.Events}
-	_, err = collection.InsertOne(ctx, insertRole)
-	if mongo.IsDuplicateKeyError(err) {
-		return permTypes.ErrRoleAlreadyExists
-	}
-	return nil
+	insertRole := permTypes.Role{Name: name, ContextType: r.ContextType, Description: r.Description, SchemeNames: r.SchemeNames, Events: r.Events}
+	return rs.Insert(ctx, insertRole)
 }
@@ -82,6 +82,7 @@ const (
 	buildServiceTLSKey            = "build-service-tls"
 	buildServiceTLSSkipVerify     = "build-service-tls-skip-verify"
 	jobEventCreationKey           = "job-event-creation"
+	jobRunHistoryKey              = "job-run-history"
 	topologySpreadConstraintsKey  = "topology-spread-constraints"
 	debugContainerImage           = "debug-container-image"
 
@@ -118,6 +119,7 @@ var (
 		buildServiceTLSKey:            "Whether should access Build service through TLS",
 		buildServiceTLSSkipVerify:     "Whether should skip certificate chain validation",
 		jobEventCreationKey:           "Enable k8s event data tracking cross-referencing with Jobs and send them to tsuru database",
+		jobRunHistoryKey:              "Enable recording the history of job executions, capturing their logs before the pods are removed",
 		topologySpreadConstraintsKey:  "Enable topology spread constraints for apps",
 		debugContainerImage:           "Image used to create debug containers (Ephemeral Containers)",
 	}
@@ -540,6 +542,14 @@ func (c *ClusterClient) EnableJobEventCreation() (bool, error) {
 	return strconv.ParseBool(jobEventCreation)
 }
 
+func (c *ClusterClient) EnableJobRunHistory() (bool, error) {
+	jobRunHistory := c.configForContext("", jobRunHistoryKey)
+	if jobRunHistory == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(jobRunHistory)
+}
+
 func (c *ClusterClient) Registry() imgTypes.ImageRegistry {
 	registry := c.configForContext("", registryKey)
 	return imgTypes.ImageRegistry(registry)
@@ -28,6 +28,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/envs/secretref"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
@@ -617,7 +618,10 @@ func ensureAppSecret(ctx context.Context, opts *ensureAppSecretOptions) (*apiv1.
 		}
 		oldSecret = nil
 	}
-	envs := appSecretEnvs(opts.app, opts.process, opts.version)
+	envs, err := appSecretEnvs(ctx, opts.app, opts.process, opts.version)
+	if err != nil {
+		return nil, err
+	}
 
 	labels := provision.SecretLabels(provision.SecretLabelsOpts{
 		App:    opts.app,
@@ -1013,7 +1017,9 @@ func appEnvs(a *appTypes.App, process string, secretName string, version appType
 	appEnvs := envsForApp(a, process, version)
 	envs := make([]apiv1.EnvVar, len(appEnvs))
 	for i, envData := range appEnvs {
-		if disableSecrets || envData.Public {
+		if env, ok := secretRefEnvVar(envData); ok {
+			envs[i] = env
+		} else if (disableSecrets || envData.Public) && envData.SecretRef == "" {
 			envs[i] = apiv1.EnvVar{
 				Name:  envData.Name,
 				Value: strings.ReplaceAll(envData.Value, "$", "$$"),
@@ -1035,7 +1041,7 @@ func appEnvs(a *appTypes.App, process string, secretName string, version appType
 	return envs
 }
 
-func appSecretEnvs(a *appTypes.App, process string, version appTypes.AppVersion) map[string][]byte {
+func appSecretEnvs(ctx context.Context, a *appTypes.App, process string, version appTypes.AppVersion) (map[string][]byte, error) {
 	appEnvs := envsForApp(a, process, version)
 
 	result := map[string][]byte{}
@@ -1044,9 +1050,26 @@ func appSecretEnvs(a *appTypes.App, process string, version appTypes.AppVersion)
 		if envData.Public {
 			continue
 		}
+		if envData.SecretRef != "" {
+			if _, ok := secretRefEnvVar(envData); ok {
+				// projected by appEnvs, checked here as both are
+				// built for every deployed process
+				err := secretref.Validate(ctx, a, envData.SecretRef)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid secret reference in env %q", envData.Name)
+				}
+				continue
+			}
+			value, err := secretref.Resolve(ctx, a, envData.SecretRef)
+			if err != nil {
+				return nil, err
+			}
+			result[envData.Name] = []byte(value)
+			continue
+		}
 		result[envData.Name] = []byte(envData.Value)
 	}
-	return result
+	return result, nil
 }
 
 type serviceManager struct {
@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/envs/secretref"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
@@ -3289,6 +3290,17 @@ func (s *S) TestServiceManagerDeployServiceWithEscapedEnvs(c *check.C) {
 	version := newCommittedVersion(c, a, map[string][]string{
 		"p1": {"cm1"},
 	})
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	require.NoError(s.t, err)
+	_, err = s.client.Clientset.CoreV1().Secrets(ns).Create(context.TODO(), &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-secret",
+			Namespace:   ns,
+			Annotations: map[string]string{"tsuru.io/allowed-apps": "myapp"},
+		},
+		Data: map[string][]byte{"api-key": []byte("abc")},
+	}, metav1.CreateOptions{})
+	require.NoError(s.t, err)
 
 	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
 		App:              a,
@@ -3300,9 +3312,6 @@ func (s *S) TestServiceManagerDeployServiceWithEscapedEnvs(c *check.C) {
 	require.NoError(s.t, err)
 	waitDep()
 
-	ns, err := s.client.AppNamespace(context.TODO(), a)
-	require.NoError(s.t, err)
-
 	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
 	require.NoError(s.t, err)
 	require.EqualValues(s.t, []apiv1.EnvVar{
@@ -3340,6 +3349,130 @@ func (s *S) TestServiceManagerDeployServiceWithEscapedEnvs(c *check.C) {
 	}, secret.Data)
 }
 
+type fakeSecretResolver struct {
+	values map[string]string
+}
+
+func (r *fakeSecretResolver) Validate(ctx context.Context, app *appTypes.App, ref secretref.Ref) error {
+	_, err := r.Resolve(ctx, app, ref)
+	return err
+}
+
+func (r *fakeSecretResolver) Resolve(ctx context.Context, app *appTypes.App, ref secretref.Ref) (string, error) {
+	value, ok := r.values[ref.Path+"#"+ref.Key]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return value, nil
+}
+
+func (s *S) TestServiceManagerDeployServiceWithSecretRefs(c *check.C) {
+	secretref.Register("fake", &fakeSecretResolver{values: map[string]string{"db#password": "s3cr3t"}})
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	a.Env = map[string]bindTypes.EnvVar{
+		"DB_PASSWORD": {Name: "DB_PASSWORD", SecretRef: "fake://db#password"},
+		"API_KEY":     {Name: "API_KEY", SecretRef: "k8s://my-secret#api-key"},
+	}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	require.NoError(s.t, err)
+	version := newCommittedVersion(c, a, map[string][]string{
+		"p1": {"cm1"},
+	})
+
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:              a,
+		Version:          version,
+		PreserveVersions: true,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true, Restart: true},
+	})
+	require.NoError(s.t, err)
+	waitDep()
+
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	require.NoError(s.t, err)
+
+	dep, err := s.client.Clientset.AppsV1().Deployments(ns).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	require.NoError(s.t, err)
+	envs := map[string]apiv1.EnvVar{}
+	for _, env := range dep.Spec.Template.Spec.Containers[0].Env {
+		envs[env.Name] = env
+	}
+	require.EqualValues(s.t, apiv1.EnvVar{Name: "API_KEY", ValueFrom: &apiv1.EnvVarSource{
+		SecretKeyRef: &apiv1.SecretKeySelector{
+			LocalObjectReference: apiv1.LocalObjectReference{
+				Name: "my-secret",
+			},
+			Key: "api-key",
+		},
+	}}, envs["API_KEY"])
+	require.EqualValues(s.t, apiv1.EnvVar{Name: "DB_PASSWORD", ValueFrom: &apiv1.EnvVarSource{
+		SecretKeyRef: &apiv1.SecretKeySelector{
+			LocalObjectReference: apiv1.LocalObjectReference{
+				Name: appSecretPrefix + "myapp-p1",
+			},
+			Key: "DB_PASSWORD",
+		},
+	}}, envs["DB_PASSWORD"])
+
+	secret, err := s.client.Clientset.CoreV1().Secrets(ns).Get(context.TODO(), appSecretPrefix+"myapp-p1", metav1.GetOptions{})
+	require.NoError(s.t, err)
+	require.EqualValues(s.t, map[string][]byte{
+		"TSURU_SERVICES": []byte("{}"),
+		"DB_PASSWORD":    []byte("s3cr3t"),
+	}, secret.Data)
+}
+
+func (s *S) TestSecretResolver(c *check.C) {
+	a := &appTypes.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	require.NoError(s.t, err)
+	ns, err := s.client.AppNamespace(context.TODO(), a)
+	require.NoError(s.t, err)
+	for _, secret := range []*apiv1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "my-secret",
+				Namespace:   ns,
+				Annotations: map[string]string{"tsuru.io/allowed-teams": "other-team, " + s.team.Name},
+			},
+			Data: map[string][]byte{"api-key": []byte("abc")},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "unlisted", Namespace: ns, Annotations: map[string]string{"tsuru.io/allowed-apps": "otherapp"}},
+			Data:       map[string][]byte{"api-key": []byte("abc")},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        appSecretPrefix + "otherapp-web",
+				Namespace:   ns,
+				Labels:      map[string]string{"tsuru.io/is-tsuru": "true", "tsuru.io/app-name": "otherapp"},
+				Annotations: map[string]string{"tsuru.io/allowed-apps": "myapp"},
+			},
+			Data: map[string][]byte{"PASSWORD": []byte("abc")},
+		},
+	} {
+		_, err = s.client.Clientset.CoreV1().Secrets(ns).Create(context.TODO(), secret, metav1.CreateOptions{})
+		require.NoError(s.t, err)
+	}
+	err = secretref.Validate(context.TODO(), a, "k8s://my-secret#api-key")
+	require.NoError(s.t, err)
+	err = secretref.Validate(context.TODO(), a, "k8s://unlisted#api-key")
+	require.ErrorContains(s.t, err, `secret "unlisted" doesn't allow app "myapp"`)
+	err = secretref.Validate(context.TODO(), a, "k8s://"+appSecretPrefix+"otherapp-web#PASSWORD")
+	require.ErrorContains(s.t, err, "is managed by tsuru and can't be referenced")
+	value, err := secretref.Resolve(context.TODO(), a, "k8s://my-secret#api-key")
+	require.NoError(s.t, err)
+	require.Equal(s.t, "abc", value)
+	err = secretref.Validate(context.TODO(), a, "k8s://my-secret#other")
+	require.ErrorContains(s.t, err, `key "other" not found in secret "my-secret"`)
+	err = secretref.Validate(context.TODO(), a, "k8s://unknown#api-key")
+	require.ErrorContains(s.t, err, `secret "unknown" not found`)
+}
+
 func (s *S) TestServiceManagerDeployServiceWithVolumes(c *check.C) {
 	config.Set("docker:uid", 1001)
 	defer config.Unset("docker:uid")
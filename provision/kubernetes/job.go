@@ -20,6 +20,7 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	batchv1 "k8s.io/api/batch/v1"
@@ -427,7 +428,7 @@ func (p *kubernetesProvisioner) EnsureJob(ctx context.Context, job *jobTypes.Job
 	return nil
 }
 
-func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, job *jobTypes.Job, pool string) error {
+func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, job *jobTypes.Job, pool string, args jobTypes.TriggerArgs) error {
 	client, err := clusterForPool(ctx, pool)
 	if err != nil {
 		return err
@@ -455,11 +456,27 @@ func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, job *jobTypes.J
 		},
 	}
 	cronChild.Name = getManualJobName(job.Name)
+	if args.Workflow != nil {
+		// steps may run the same job more than once a minute
+		cronChild.Name = getWorkflowJobName(job.Name, args.Workflow)
+	}
 	if cronChild.Annotations == nil {
-		cronChild.Annotations = map[string]string{"cronjob.kubernetes.io/instantiate": "manual"}
+		cronChild.Annotations = map[string]string{cronJobInstantiateAnnotation: "manual"}
 	} else {
-		cronChild.Annotations["cronjob.kubernetes.io/instantiate"] = "manual"
+		cronChild.Annotations[cronJobInstantiateAnnotation] = "manual"
+	}
+	if args.Trigger != "" {
+		cronChild.Annotations[jobRunTriggerAnnotation] = string(args.Trigger)
+	}
+	if args.TriggeredBy != "" {
+		cronChild.Annotations[jobRunTriggeredByAnnotation] = args.TriggeredBy
+	}
+	if args.Workflow != nil {
+		cronChild.Annotations[jobWorkflowAnnotation] = args.Workflow.Workflow
+		cronChild.Annotations[jobWorkflowRunAnnotation] = args.Workflow.Run
+		cronChild.Annotations[jobWorkflowStepAnnotation] = args.Workflow.Step
 	}
+	setJobRunEnvs(&cronChild.Spec.Template.Spec, args.Envs)
 	_, err = client.BatchV1().Jobs(cron.Namespace).Create(ctx, &cronChild, metav1.CreateOptions{})
 	if err != nil && k8sErrors.IsAlreadyExists(err) {
 		return errors.Errorf("manual job %q already exists (cronjobs can only be triggered once per minute)", cronChild.Name)
@@ -472,6 +489,35 @@ func getManualJobName(job string) string {
 	return fmt.Sprintf("%s-manual-job-%d", job, scheduledTime.Unix()/60)
 }
 
+func getWorkflowJobName(job string, ref *jobTypes.WorkflowRef) string {
+	h := sha256.Sum256([]byte(ref.Workflow + "/" + ref.Run + "/" + ref.Step))
+	return fmt.Sprintf("%s-wf-%x", job, h[:6])
+}
+
+// setJobRunEnvs sets the envs in the containers of the run, overriding the
+// ones with the same name.
+func setJobRunEnvs(spec *apiv1.PodSpec, envs []bindTypes.EnvVar) {
+	if len(envs) == 0 {
+		return
+	}
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		for _, env := range envs {
+			found := false
+			for j := range c.Env {
+				if c.Env[j].Name == env.Name {
+					c.Env[j] = apiv1.EnvVar{Name: env.Name, Value: env.Value}
+					found = true
+					break
+				}
+			}
+			if !found {
+				c.Env = append(c.Env, apiv1.EnvVar{Name: env.Name, Value: env.Value})
+			}
+		}
+	}
+}
+
 // JobUnits returns information about units related to a specific Job or CronJob
 func (p *kubernetesProvisioner) JobUnits(ctx context.Context, job *jobTypes.Job) ([]provTypes.Unit, error) {
 	client, err := clusterForPool(ctx, job.Pool)
@@ -0,0 +1,186 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sLabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	jobRunTriggerAnnotation      = tsuruLabelPrefix + "job-run-trigger"
+	jobRunTriggeredByAnnotation  = tsuruLabelPrefix + "job-run-triggered-by"
+	cronJobInstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+	jobWorkflowAnnotation        = tsuruLabelPrefix + "job-workflow"
+	jobWorkflowRunAnnotation     = tsuruLabelPrefix + "job-workflow-run"
+	jobWorkflowStepAnnotation    = tsuruLabelPrefix + "job-workflow-step"
+
+	defaultJobRunLogLines = 500
+)
+
+// jobRunLogLines returns how many lines of each unit are captured when a
+// run finishes, set by jobs:run-history:log-lines.
+func jobRunLogLines() int {
+	n, err := config.GetInt("jobs:run-history:log-lines")
+	if err != nil || n <= 0 {
+		return defaultJobRunLogLines
+	}
+	return n
+}
+
+// recordJobRun stores the state of a Kubernetes job as a run of its tsuru
+// job. The output of the units is captured once the run finishes, as the
+// pods are removed along with the job.
+func recordJobRun(ctx context.Context, client *ClusterClient, job *batchv1.Job) error {
+	jobName := job.Labels[tsuruLabelJobName]
+	if jobName == "" {
+		return nil
+	}
+	run := jobRunFromJob(job)
+	run.Cluster = client.Name
+	if run.Finished() {
+		existing, err := servicemanager.Job.GetRun(ctx, &jobTypes.Job{Name: jobName}, run.ID)
+		if err == nil && existing.Finished() {
+			return nil
+		}
+		if err != nil && err != jobTypes.ErrJobRunNotFound {
+			return err
+		}
+	}
+	podList, err := client.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: k8sLabels.SelectorFromSet(k8sLabels.Set{"job-name": job.Name}).String(),
+	})
+	if err != nil {
+		return err
+	}
+	pods := make([]*apiv1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		pods[i] = &podList.Items[i]
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+	})
+	var restarts int
+	for _, pod := range pods {
+		run.Units = append(run.Units, pod.Name)
+		restarts += int(*containersRestarts(pod.Status.ContainerStatuses))
+	}
+	// Every container restart and failed pod is a retry, but the last
+	// attempt of a failed run.
+	run.Retries = restarts + int(job.Status.Failed)
+	if run.Status == jobTypes.RunStatusFailed && run.Retries > 0 {
+		run.Retries--
+	}
+	if run.Finished() {
+		if len(pods) > 0 {
+			run.ExitCode = podExitCode(pods[len(pods)-1])
+		}
+		run.Log, err = captureJobRunLog(ctx, client, job.Namespace, pods)
+		if err != nil {
+			return err
+		}
+	}
+	return servicemanager.Job.RecordRun(ctx, run)
+}
+
+// updateJobWorkflowStep updates the workflow step started by a Kubernetes
+// job, if any, without recording its run.
+func updateJobWorkflowStep(ctx context.Context, job *batchv1.Job) error {
+	if job.Labels[tsuruLabelJobName] == "" || job.Annotations[jobWorkflowRunAnnotation] == "" {
+		return nil
+	}
+	return servicemanager.JobWorkflow.UpdateStep(ctx, jobRunFromJob(job))
+}
+
+func jobRunFromJob(job *batchv1.Job) jobTypes.Run {
+	run := jobTypes.Run{
+		ID:          string(job.UID),
+		JobName:     job.Labels[tsuruLabelJobName],
+		Trigger:     jobTypes.RunTrigger(job.Annotations[jobRunTriggerAnnotation]),
+		TriggeredBy: job.Annotations[jobRunTriggeredByAnnotation],
+		Status:      jobTypes.RunStatusRunning,
+		StartTime:   job.CreationTimestamp.Time.UTC(),
+	}
+	if run.Trigger == "" {
+		run.Trigger = jobTypes.RunTriggerCron
+		if job.Annotations[cronJobInstantiateAnnotation] == "manual" {
+			run.Trigger = jobTypes.RunTriggerManual
+		}
+	}
+	if runID := job.Annotations[jobWorkflowRunAnnotation]; runID != "" {
+		run.Workflow = &jobTypes.WorkflowRef{
+			Workflow: job.Annotations[jobWorkflowAnnotation],
+			Run:      runID,
+			Step:     job.Annotations[jobWorkflowStepAnnotation],
+		}
+	}
+	if job.Status.StartTime != nil {
+		run.StartTime = job.Status.StartTime.Time.UTC()
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != apiv1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			run.Status = jobTypes.RunStatusSucceeded
+		case batchv1.JobFailed:
+			run.Status = jobTypes.RunStatusFailed
+			run.Reason = condition.Reason
+		default:
+			continue
+		}
+		run.EndTime = condition.LastTransitionTime.Time.UTC()
+	}
+	if run.Status == jobTypes.RunStatusSucceeded && job.Status.CompletionTime != nil {
+		run.EndTime = job.Status.CompletionTime.Time.UTC()
+	}
+	return run
+}
+
+// podExitCode returns the exit code of the last termination of the job
+// container in the pod.
+func podExitCode(pod *apiv1.Pod) *int32 {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return &status.State.Terminated.ExitCode
+		}
+		if status.LastTerminationState.Terminated != nil {
+			return &status.LastTerminationState.Terminated.ExitCode
+		}
+	}
+	return nil
+}
+
+func captureJobRunLog(ctx context.Context, client *ClusterClient, namespace string, pods []*apiv1.Pod) (*jobTypes.RunLog, error) {
+	limit := jobRunLogLines()
+	logs, err := listLogsFromPods(ctx, client, namespace, pods, appTypes.ListLogArgs{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	runLog := &jobTypes.RunLog{}
+	lines := map[string]int{}
+	for _, l := range logs {
+		lines[l.Unit]++
+		if lines[l.Unit] >= limit {
+			runLog.Truncated = true
+		}
+		runLog.Entries = append(runLog.Entries, jobTypes.RunLogEntry{
+			Date:    l.Date,
+			Unit:    l.Unit,
+			Message: l.Message,
+		})
+	}
+	return runLog, nil
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	check "gopkg.in/check.v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *S) TestJobRunFromJob(c *check.C) {
+	start := time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	tests := []struct {
+		job      batchv1.Job
+		expected jobTypes.Run
+	}{
+		{
+			job: batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:    "uid-1",
+					Labels: map[string]string{"tsuru.io/job-name": "myjob"},
+				},
+				Status: batchv1.JobStatus{StartTime: &metav1.Time{Time: start}},
+			},
+			expected: jobTypes.Run{
+				ID:        "uid-1",
+				JobName:   "myjob",
+				Trigger:   jobTypes.RunTriggerCron,
+				Status:    jobTypes.RunStatusRunning,
+				StartTime: start,
+			},
+		},
+		{
+			job: batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:         "uid-2",
+					Labels:      map[string]string{"tsuru.io/job-name": "myjob"},
+					Annotations: map[string]string{"cronjob.kubernetes.io/instantiate": "manual"},
+				},
+				Status: batchv1.JobStatus{
+					StartTime:      &metav1.Time{Time: start},
+					CompletionTime: &metav1.Time{Time: end},
+					Conditions: []batchv1.JobCondition{
+						{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: end}},
+					},
+				},
+			},
+			expected: jobTypes.Run{
+				ID:        "uid-2",
+				JobName:   "myjob",
+				Trigger:   jobTypes.RunTriggerManual,
+				Status:    jobTypes.RunStatusSucceeded,
+				StartTime: start,
+				EndTime:   end,
+			},
+		},
+		{
+			job: batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:    "uid-3",
+					Labels: map[string]string{"tsuru.io/job-name": "myjob"},
+					Annotations: map[string]string{
+						"cronjob.kubernetes.io/instantiate": "manual",
+						"tsuru.io/job-run-trigger":          "api",
+						"tsuru.io/job-run-triggered-by":     "my-token",
+					},
+				},
+				Status: batchv1.JobStatus{
+					StartTime: &metav1.Time{Time: start},
+					Conditions: []batchv1.JobCondition{
+						{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", LastTransitionTime: metav1.Time{Time: end}},
+					},
+				},
+			},
+			expected: jobTypes.Run{
+				ID:          "uid-3",
+				JobName:     "myjob",
+				Trigger:     jobTypes.RunTriggerAPI,
+				TriggeredBy: "my-token",
+				Status:      jobTypes.RunStatusFailed,
+				Reason:      "BackoffLimitExceeded",
+				StartTime:   start,
+				EndTime:     end,
+			},
+		},
+		{
+			job: batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:    "uid-4",
+					Labels: map[string]string{"tsuru.io/job-name": "myjob"},
+					Annotations: map[string]string{
+						"cronjob.kubernetes.io/instantiate": "manual",
+						"tsuru.io/job-run-trigger":          "workflow",
+						"tsuru.io/job-run-triggered-by":     "etl",
+						"tsuru.io/job-workflow":             "etl",
+						"tsuru.io/job-workflow-run":         "run-1",
+						"tsuru.io/job-workflow-step":        "extract",
+					},
+				},
+				Status: batchv1.JobStatus{StartTime: &metav1.Time{Time: start}},
+			},
+			expected: jobTypes.Run{
+				ID:          "uid-4",
+				JobName:     "myjob",
+				Trigger:     jobTypes.RunTriggerWorkflow,
+				TriggeredBy: "etl",
+				Status:      jobTypes.RunStatusRunning,
+				StartTime:   start,
+				Workflow:    &jobTypes.WorkflowRef{Workflow: "etl", Run: "run-1", Step: "extract"},
+			},
+		},
+	}
+	for i, tt := range tests {
+		c.Check(jobRunFromJob(&tt.job), check.DeepEquals, tt.expected, check.Commentf("test %d", i))
+	}
+}
+
+func (s *S) TestRecordJobRun(c *check.C) {
+	var recorded []jobTypes.Run
+	s.mockService.JobService.OnRecordRun = func(run jobTypes.Run) error {
+		recorded = append(recorded, run)
+		return nil
+	}
+	s.mockService.JobService.OnGetRun = func(job *jobTypes.Job, id string) (*jobTypes.Run, error) {
+		c.Assert(job.Name, check.Equals, "myjob")
+		for i := range recorded {
+			if recorded[i].ID == id {
+				return &recorded[i], nil
+			}
+		}
+		return nil, jobTypes.ErrJobRunNotFound
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myjob-28000000",
+			Namespace: "default",
+			UID:       "uid-1",
+			Labels:    map[string]string{"tsuru.io/job-name": "myjob"},
+		},
+		Status: batchv1.JobStatus{
+			StartTime: &metav1.Time{Time: now},
+			Failed:    1,
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", LastTransitionTime: metav1.Time{Time: now.Add(time.Minute)}},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myjob-28000000-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{"job-name": "myjob-28000000"},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "job",
+					RestartCount: 2,
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 3},
+					},
+				},
+			},
+		},
+	}
+	_, err := s.client.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+	err = recordJobRun(context.TODO(), s.clusterClient, job)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorded, check.HasLen, 1)
+	run := recorded[0]
+	c.Assert(run.ID, check.Equals, "uid-1")
+	c.Assert(run.JobName, check.Equals, "myjob")
+	c.Assert(run.Cluster, check.Equals, s.clusterClient.Name)
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(run.Retries, check.Equals, 2)
+	c.Assert(run.Units, check.DeepEquals, []string{"myjob-28000000-abcde"})
+	c.Assert(run.ExitCode, check.NotNil)
+	c.Assert(*run.ExitCode, check.Equals, int32(3))
+	c.Assert(run.Log, check.NotNil)
+	err = recordJobRun(context.TODO(), s.clusterClient, job)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorded, check.HasLen, 1)
+}
+
+func (s *S) TestRecordJobRunIgnoresNonTsuruJobs(c *check.C) {
+	s.mockService.JobService.OnRecordRun = func(run jobTypes.Run) error {
+		c.Fatal("unexpected run recorded")
+		return nil
+	}
+	err := recordJobRun(context.TODO(), s.clusterClient, &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+	})
+	c.Assert(err, check.IsNil)
+}
+
+type stepRecorderWorkflowService struct {
+	jobTypes.WorkflowService
+	runs []jobTypes.Run
+}
+
+func (s *stepRecorderWorkflowService) UpdateStep(ctx context.Context, run jobTypes.Run) error {
+	s.runs = append(s.runs, run)
+	return nil
+}
+
+func (s *S) TestUpdateJobWorkflowStep(c *check.C) {
+	workflows := &stepRecorderWorkflowService{}
+	oldWorkflows := servicemanager.JobWorkflow
+	servicemanager.JobWorkflow = workflows
+	defer func() { servicemanager.JobWorkflow = oldWorkflows }()
+	s.mockService.JobService.OnRecordRun = func(run jobTypes.Run) error {
+		c.Fatal("unexpected run recorded")
+		return nil
+	}
+	err := updateJobWorkflowStep(context.TODO(), &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myjob-28000000",
+			Namespace: "default",
+			Labels:    map[string]string{"tsuru.io/job-name": "myjob"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(workflows.runs, check.HasLen, 0)
+	err = updateJobWorkflowStep(context.TODO(), &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myjob-workflow",
+			Namespace: "default",
+			UID:       "uid-1",
+			Labels:    map[string]string{"tsuru.io/job-name": "myjob"},
+			Annotations: map[string]string{
+				jobWorkflowAnnotation:     "etl",
+				jobWorkflowRunAnnotation:  "run-1",
+				jobWorkflowStepAnnotation: "extract",
+			},
+		},
+		Status: batchv1.JobStatus{
+			Succeeded:  1,
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(workflows.runs, check.HasLen, 1)
+	c.Assert(workflows.runs[0].ID, check.Equals, "uid-1")
+	c.Assert(workflows.runs[0].Status, check.Equals, jobTypes.RunStatusSucceeded)
+	c.Assert(workflows.runs[0].Workflow, check.DeepEquals, &jobTypes.WorkflowRef{Workflow: "etl", Run: "run-1", Step: "extract"})
+}
@@ -730,7 +730,7 @@ func (s *S) TestProvisionerTriggerCron(c *check.C) {
 			},
 			scenario: func(t *time.Time) {
 				*t = time.Now()
-				err := s.p.TriggerCron(context.TODO(), &cj, "test-default")
+				err := s.p.TriggerCron(context.TODO(), &cj, "test-default", jobTypes.TriggerArgs{})
 				require.NoError(s.t, err)
 				waitCron()
 			},
@@ -876,12 +876,12 @@ func (s *S) TestProvisionerTriggerCronDuplicate(c *check.C) {
 	require.NoError(s.t, err)
 
 	// Trigger it once - should succeed
-	err = s.p.TriggerCron(context.TODO(), &cj, "test-default")
+	err = s.p.TriggerCron(context.TODO(), &cj, "test-default", jobTypes.TriggerArgs{})
 	require.NoError(s.t, err)
 	waitCron()
 
 	// Trigger it again in the same minute - should get a better error message
-	err = s.p.TriggerCron(context.TODO(), &cj, "test-default")
+	err = s.p.TriggerCron(context.TODO(), &cj, "test-default", jobTypes.TriggerArgs{})
 	require.Error(s.t, err)
 	c.Assert(err.Error(), check.Matches, `.*manual job .* already exists.*once per minute.*`)
 }
@@ -932,7 +932,7 @@ func (s *S) TestBackwardCompatibilityOldNaming(c *check.C) {
 		},
 	}
 
-	err = s.p.TriggerCron(context.TODO(), job, "test-default")
+	err = s.p.TriggerCron(context.TODO(), job, "test-default", jobTypes.TriggerArgs{})
 	require.NoError(s.t, err)
 	waitCron()
 
@@ -948,6 +948,85 @@ func (s *S) TestBackwardCompatibilityOldNaming(c *check.C) {
 	require.True(s.t, k8sErrors.IsNotFound(err))
 }
 
+func (s *S) TestProvisionerTriggerCronRecordsTrigger(c *check.C) {
+	waitCron := s.mock.CronJobReactions(c)
+	defer waitCron()
+	cj := jobTypes.Job{
+		Name:      "myjob",
+		TeamOwner: s.team.Name,
+		Pool:      "test-default",
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+				Command:          []string{"echo", "hello world"},
+			},
+		},
+	}
+	err := s.p.EnsureJob(context.TODO(), &cj)
+	require.NoError(s.t, err)
+	waitCron()
+	err = s.p.TriggerCron(context.TODO(), &cj, "test-default", jobTypes.TriggerArgs{
+		Trigger:     jobTypes.RunTriggerAPI,
+		TriggeredBy: "my-team-token",
+	})
+	require.NoError(s.t, err)
+	waitCron()
+	jobs, err := s.client.BatchV1().Jobs("default").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(s.t, err)
+	require.Len(s.t, jobs.Items, 1)
+	require.Equal(s.t, "manual", jobs.Items[0].Annotations["cronjob.kubernetes.io/instantiate"])
+	require.Equal(s.t, "api", jobs.Items[0].Annotations["tsuru.io/job-run-trigger"])
+	require.Equal(s.t, "my-team-token", jobs.Items[0].Annotations["tsuru.io/job-run-triggered-by"])
+}
+
+func (s *S) TestProvisionerTriggerCronForWorkflow(c *check.C) {
+	waitCron := s.mock.CronJobReactions(c)
+	defer waitCron()
+	cj := jobTypes.Job{
+		Name:      "myjob",
+		TeamOwner: s.team.Name,
+		Pool:      "test-default",
+		Spec: jobTypes.JobSpec{
+			Manual: true,
+			Envs:   []bindTypes.EnvVar{{Name: "REGION", Value: "sa-east-1"}},
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+				Command:          []string{"echo", "hello world"},
+			},
+		},
+	}
+	err := s.p.EnsureJob(context.TODO(), &cj)
+	require.NoError(s.t, err)
+	waitCron()
+	ref := &jobTypes.WorkflowRef{Workflow: "etl", Run: "run-1", Step: "extract"}
+	args := jobTypes.TriggerArgs{
+		Trigger:     jobTypes.RunTriggerWorkflow,
+		TriggeredBy: "etl",
+		Envs:        []bindTypes.EnvVar{{Name: "REGION", Value: "us-east-1"}, {Name: "TSURU_WORKFLOW_STEP", Value: "extract"}},
+		Workflow:    ref,
+	}
+	err = s.p.TriggerCron(context.TODO(), &cj, "test-default", args)
+	require.NoError(s.t, err)
+	waitCron()
+	jobs, err := s.client.BatchV1().Jobs("default").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(s.t, err)
+	require.Len(s.t, jobs.Items, 1)
+	require.Equal(s.t, getWorkflowJobName("myjob", ref), jobs.Items[0].Name)
+	require.Equal(s.t, "workflow", jobs.Items[0].Annotations["tsuru.io/job-run-trigger"])
+	require.Equal(s.t, "etl", jobs.Items[0].Annotations["tsuru.io/job-workflow"])
+	require.Equal(s.t, "run-1", jobs.Items[0].Annotations["tsuru.io/job-workflow-run"])
+	require.Equal(s.t, "extract", jobs.Items[0].Annotations["tsuru.io/job-workflow-step"])
+	envs := map[string]string{}
+	for _, env := range jobs.Items[0].Spec.Template.Spec.Containers[0].Env {
+		envs[env.Name] = env.Value
+	}
+	require.Equal(s.t, "us-east-1", envs["REGION"])
+	require.Equal(s.t, "extract", envs["TSURU_WORKFLOW_STEP"])
+	err = s.p.TriggerCron(context.TODO(), &cj, "test-default", args)
+	require.Error(s.t, err)
+}
+
 func (s *S) TestScheduleChangeTriggersNameMigration(c *check.C) {
 	waitCron := s.mock.CronJobReactions(c)
 	defer waitCron()
@@ -1068,10 +1147,10 @@ func (s *S) TestMixedOldAndNewJobsCoexisting(c *check.C) {
 	require.Equal(s.t, "0 4 * * *", foundNew.Spec.Schedule)
 
 	oldJobSpec := &jobTypes.Job{Name: "old-style-job", Pool: "test-default", Spec: jobTypes.JobSpec{Schedule: "0 3 * * *"}}
-	err = s.p.TriggerCron(context.TODO(), oldJobSpec, "test-default")
+	err = s.p.TriggerCron(context.TODO(), oldJobSpec, "test-default", jobTypes.TriggerArgs{})
 	require.NoError(s.t, err)
 
-	err = s.p.TriggerCron(context.TODO(), newJob, "test-default")
+	err = s.p.TriggerCron(context.TODO(), newJob, "test-default", jobTypes.TriggerArgs{})
 	require.NoError(s.t, err)
 	waitCron()
 
@@ -2051,7 +2130,7 @@ func (s *S) TestMaxJobNameLengthWithHash(c *check.C) {
 	_, err = s.client.BatchV1().CronJobs("default").Get(context.TODO(), expectedName, metav1.GetOptions{})
 	require.True(s.t, k8sErrors.IsNotFound(err))
 
-	err = s.p.TriggerCron(context.TODO(), job, "test-default")
+	err = s.p.TriggerCron(context.TODO(), job, "test-default", jobTypes.TriggerArgs{})
 	require.NoError(s.t, err)
 	waitCron()
 
@@ -19,6 +19,7 @@ import (
 	appTypes "github.com/tsuru/tsuru/types/app"
 	logTypes "github.com/tsuru/tsuru/types/log"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	knet "k8s.io/apimachinery/pkg/util/net"
 )
@@ -58,6 +59,10 @@ func (p *kubernetesProvisioner) ListLogs(ctx context.Context, obj *logTypes.Loga
 }
 
 func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, obj *logTypes.LogabbleObject, args appTypes.ListLogArgs) (appTypes.LogWatcher, error) {
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
 	pool := obj.Pool
 	clusterClient, err := clusterForPool(ctx, pool)
 	if err != nil {
@@ -93,6 +98,7 @@ func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, obj *logTypes.Log
 	watcher := &k8sLogsWatcher{
 		id:           uuidV4.String(),
 		logArgs:      args,
+		matcher:      matcher,
 		ctx:          ctx,
 		ch:           make(chan appTypes.Applog, logWatchBufferSize),
 		ns:           ns,
@@ -116,14 +122,31 @@ func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, obj *logTypes.Log
 	return watcher, nil
 }
 
+// listLogsFromPods reads the logs of the pods, leaving the start time filter
+// to the API server and applying the other filters in args to each line.
 func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns string, pods []*apiv1.Pod, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
 	var wg sync.WaitGroup
 
 	errs := make([]error, len(pods))
 	logs := make([][]appTypes.Applog, len(pods))
-	tailLimit := tailLines(args.Limit)
-	if args.Limit == 0 {
-		tailLimit = tailLines(100)
+	limit := args.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	// logs filtered by message, level or end time are read from the start
+	// time up to the end time, keeping only the last matching lines of each
+	// unit, so no matching line is missed.
+	var tailLimit *int64
+	if args.Filter == "" && len(args.Levels) == 0 && args.Until.IsZero() {
+		tailLimit = tailLines(limit)
+	}
+	var sinceTime *metav1.Time
+	if !args.Since.IsZero() {
+		sinceTime = &metav1.Time{Time: args.Since}
 	}
 
 	for index, pod := range pods {
@@ -136,6 +159,7 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 
 			request := clusterClient.CoreV1().Pods(ns).GetLogs(pod.ObjectMeta.Name, &apiv1.PodLogOptions{
 				TailLines:  tailLimit,
+				SinceTime:  sinceTime,
 				Timestamps: true,
 			})
 			stream, err := request.Stream(ctx)
@@ -143,6 +167,7 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 				errs[index] = err
 				return
 			}
+			defer stream.Close()
 
 			name, logType := logMetadata(pod.ObjectMeta.Labels)
 			appProcess := pod.ObjectMeta.Labels[tsuruLabelAppProcess]
@@ -168,7 +193,16 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 				tsuruLog.Name = name
 				tsuruLog.Type = logType
 				tsuruLog.Source = appProcess
+				if !args.Until.IsZero() && tsuruLog.Date.After(args.Until) {
+					break
+				}
+				if !matcher.Match(&tsuruLog) {
+					continue
+				}
 				tsuruLogs = append(tsuruLogs, tsuruLog)
+				if len(tsuruLogs) > limit {
+					tsuruLogs = tsuruLogs[1:]
+				}
 			}
 
 			logs[index] = tsuruLogs
@@ -183,6 +217,9 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 	}
 
 	sort.Slice(unifiedLog, func(i, j int) bool { return unifiedLog[i].Date.Before(unifiedLog[j].Date) })
+	if args.Filtered() && len(unifiedLog) > limit {
+		unifiedLog = unifiedLog[len(unifiedLog)-limit:]
+	}
 
 	for index, err := range errs {
 		if err == nil {
@@ -266,6 +303,7 @@ type k8sLogsWatcher struct {
 	done context.CancelFunc
 
 	logArgs           appTypes.ListLogArgs
+	matcher           *appTypes.LogMatcher
 	clusterClient     *ClusterClient
 	clusterController *clusterController
 	watchingPods      map[string]bool
@@ -322,6 +360,9 @@ func (k *k8sLogsWatcher) watchPod(pod *apiv1.Pod, addedLater bool) {
 		tsuruLog.Name = name
 		tsuruLog.Type = logType
 		tsuruLog.Source = appProcess
+		if !k.matcher.Match(&tsuruLog) {
+			continue
+		}
 		k.ch <- tsuruLog
 	}
 }
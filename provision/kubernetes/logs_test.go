@@ -87,6 +87,61 @@ func (s *S) Test_LogsProvisioner_ListLogs(c *check.C) {
 	require.Equal(s.t, "myapp-web-pod-1-1", logs[0].Unit)
 }
 
+func (s *S) Test_LogsProvisioner_ListLogsWithFilters(c *check.C) {
+	var sinceTime, tailLines string
+	s.mock.LogHook = func(w io.Writer, r *http.Request) {
+		sinceTime = r.URL.Query().Get("sinceTime")
+		tailLines = r.URL.Query().Get("tailLines")
+		fmt.Fprintf(w, "2019-05-06T15:04:05Z level=info msg=\"request done\"\n")
+		fmt.Fprintf(w, "2019-05-06T15:04:06Z level=error msg=\"request timeout\"\n")
+		fmt.Fprintf(w, "2019-05-06T15:04:07Z {\"level\":\"ERROR\",\"msg\":\"connection refused\"}\n")
+		fmt.Fprintf(w, "2019-05-06T15:04:08Z level=error msg=\"late timeout\"\n")
+	}
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	require.NoError(s.t, err)
+	customData := map[string][]string{
+		"web": {"run", "mycmd", "arg1"},
+	}
+	version := newCommittedVersion(c, a, customData)
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version, Event: evt})
+	require.NoError(s.t, err)
+	wait()
+	since := time.Date(2019, time.May, 6, 15, 4, 0, 0, time.UTC)
+	logs, err := s.p.ListLogs(context.TODO(), loggableApp(a), appTypes.ListLogArgs{
+		Name:   a.Name,
+		Type:   logTypes.LogTypeApp,
+		Limit:  10,
+		Since:  since,
+		Until:  time.Date(2019, time.May, 6, 15, 4, 7, 0, time.UTC),
+		Levels: []string{"error"},
+	})
+	require.NoError(s.t, err)
+	require.Equal(s.t, since.Format(time.RFC3339), sinceTime)
+	require.Equal(s.t, "", tailLines)
+	require.Len(s.t, logs, 2)
+	require.Equal(s.t, `level=error msg="request timeout"`, logs[0].Message)
+	require.Equal(s.t, `{"level":"ERROR","msg":"connection refused"}`, logs[1].Message)
+
+	logs, err = s.p.ListLogs(context.TODO(), loggableApp(a), appTypes.ListLogArgs{
+		Name:        a.Name,
+		Type:        logTypes.LogTypeApp,
+		Limit:       1,
+		Filter:      "time.ut",
+		FilterRegex: true,
+	})
+	require.NoError(s.t, err)
+	require.Len(s.t, logs, 1)
+	require.Equal(s.t, `level=error msg="late timeout"`, logs[0].Message)
+}
+
 func (s *S) Test_LogsProvisioner_ListLongLogs(c *check.C) {
 	s.mock.LogHook = func(w io.Writer, r *http.Request) {
 		m := ""
@@ -14,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
+	batchv1 "k8s.io/api/batch/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 )
 
 const (
@@ -39,6 +41,9 @@ const (
 	leaseDuration      = 15 * time.Second
 	renewDeadline      = 10 * time.Second
 	retryPeriod        = 2 * time.Second
+
+	jobWorkflowReconcileInterval = time.Minute
+	jobRunMaxRetries             = 5
 )
 
 type podListener interface {
@@ -57,6 +62,7 @@ type clusterController struct {
 	vpaInformer        vpaV1Informers.VerticalPodAutoscalerInformer
 	jobsInformer       jobsInformer.JobInformer
 	eventsInformer     v1informers.EventInformer
+	jobRunQueue        workqueue.TypedRateLimitingInterface[string]
 	stopCh             chan struct{}
 	cancel             context.CancelFunc
 	startedAt          time.Time
@@ -86,6 +92,7 @@ func getClusterController(p *kubernetesProvisioner, cluster *ClusterClient) (*cl
 		cancel:       cancel,
 		startedAt:    time.Now(),
 		podListeners: make(map[string]podListener),
+		jobRunQueue:  workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]()),
 	}
 	err := c.initLeaderElection(ctx)
 	if err != nil {
@@ -129,6 +136,7 @@ func (c *clusterController) stop() {
 	// lived, this mostly affects tests.
 	<-time.After(time.Second - time.Since(c.startedAt))
 	c.cancel()
+	c.jobRunQueue.ShutDown()
 	c.wg.Wait()
 }
 
@@ -137,6 +145,10 @@ func (c *clusterController) isLeader() bool {
 }
 
 func (c *clusterController) startJobInformer() error {
+	err := c.startJobRunHistory()
+	if err != nil {
+		return err
+	}
 	if enable, _ := c.cluster.EnableJobEventCreation(); !enable {
 		log.Debugf("job event creation is not enabled, skipping job informer")
 		return nil
@@ -180,6 +192,156 @@ func (c *clusterController) startJobInformer() error {
 	return nil
 }
 
+// startJobRunHistory records the runs of tsuru jobs as the state of their
+// Kubernetes jobs changes, when run history is enabled in the cluster. The
+// workflow steps started by jobs are updated either way, and reconciled
+// periodically so steps aren't left running when an update is missed.
+//
+// The informer handlers only queue the changed jobs, the runs are recorded
+// by a worker as it may list pods and read their logs.
+func (c *clusterController) startJobRunHistory() error {
+	jobInformer, err := c.getJobInformer()
+	if err != nil {
+		return err
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for c.processNextJobRun(jobInformer) {
+		}
+	}()
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case <-c.stopCh:
+				return
+			case <-time.After(jobWorkflowReconcileInterval):
+			}
+			c.reconcileJobWorkflowSteps(jobInformer)
+		}
+	}()
+	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			job, ok := obj.(*batchv1.Job)
+			if !ok {
+				return
+			}
+			c.enqueueJobRun(job)
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			oldJob, ok := oldObj.(*batchv1.Job)
+			if !ok {
+				return
+			}
+			newJob, ok := newObj.(*batchv1.Job)
+			if !ok || !jobRunChanged(oldJob, newJob) {
+				return
+			}
+			c.enqueueJobRun(newJob)
+		},
+	})
+	return nil
+}
+
+// jobRunChanged reports whether the update of a job changed what's recorded
+// in its run, that is its conditions or the counts of its pods. Resyncs and
+// changes to the spec or metadata of the job are ignored.
+func jobRunChanged(oldJob, newJob *batchv1.Job) bool {
+	oldStatus, newStatus := oldJob.Status, newJob.Status
+	if oldStatus.Active != newStatus.Active ||
+		oldStatus.Succeeded != newStatus.Succeeded ||
+		oldStatus.Failed != newStatus.Failed ||
+		len(oldStatus.Conditions) != len(newStatus.Conditions) {
+		return true
+	}
+	for i, cond := range newStatus.Conditions {
+		if cond.Type != oldStatus.Conditions[i].Type || cond.Status != oldStatus.Conditions[i].Status {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *clusterController) enqueueJobRun(job *batchv1.Job) {
+	// if not leader, do nothing
+	if !c.isLeader() {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(job)
+	if err != nil {
+		log.Errorf("unable to queue run of job %q: %v", job.Name, err)
+		return
+	}
+	c.jobRunQueue.Add(key)
+}
+
+// processNextJobRun handles the next job in the run queue, using its current
+// state in the informer cache. It returns false once the queue is shut down.
+func (c *clusterController) processNextJobRun(jobInformer jobsInformer.JobInformer) bool {
+	key, shutdown := c.jobRunQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.jobRunQueue.Done(key)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		c.jobRunQueue.Forget(key)
+		return true
+	}
+	job, err := jobInformer.Lister().Jobs(namespace).Get(name)
+	if err != nil {
+		// the job was removed before its turn, there's nothing left to record
+		c.jobRunQueue.Forget(key)
+		return true
+	}
+	err = c.onJobRunChange(job)
+	if err == nil {
+		c.jobRunQueue.Forget(key)
+		return true
+	}
+	if c.jobRunQueue.NumRequeues(key) < jobRunMaxRetries {
+		log.Errorf("unable to record run of job %q, retrying: %v", job.Name, err)
+		c.jobRunQueue.AddRateLimited(key)
+		return true
+	}
+	log.Errorf("unable to record run of job %q: %v", job.Name, err)
+	c.jobRunQueue.Forget(key)
+	return true
+}
+
+func (c *clusterController) onJobRunChange(job *batchv1.Job) error {
+	// if not leader, do nothing
+	if !c.isLeader() {
+		return nil
+	}
+	if enable, _ := c.cluster.EnableJobRunHistory(); !enable {
+		return updateJobWorkflowStep(context.Background(), job)
+	}
+	return recordJobRun(context.Background(), c.cluster, job)
+}
+
+// reconcileJobWorkflowSteps updates the workflow steps started by every job
+// in the cluster.
+func (c *clusterController) reconcileJobWorkflowSteps(jobInformer jobsInformer.JobInformer) {
+	// if not leader, do nothing
+	if !c.isLeader() {
+		return
+	}
+	jobs, err := jobInformer.Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("unable to list jobs to reconcile workflow steps: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		err = updateJobWorkflowStep(context.Background(), job)
+		if err != nil {
+			log.Errorf("unable to update workflow step of job %q: %v", job.Name, err)
+		}
+	}
+}
+
 func (c *clusterController) start() (v1informers.PodInformer, error) {
 	informer, err := c.getPodInformerWait(false)
 	if err != nil {
@@ -10,8 +10,10 @@ import (
 
 	"github.com/stretchr/testify/require"
 	check "gopkg.in/check.v1"
+	batchv1 "k8s.io/api/batch/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
 )
 
 func (s *S) TestNewRouterControllerSameInstance(_ *check.C) {
@@ -112,3 +114,48 @@ func (s *S) TestStartJobInformerDisabledIsNotAnError(_ *check.C) {
 	require.False(s.t, enabled, "job event creation should be disabled in the test cluster")
 	require.NoError(s.t, clusterController.startJobInformer())
 }
+
+func (s *S) TestJobRunChanged(c *check.C) {
+	running := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{ResourceVersion: "1"},
+		Status:     batchv1.JobStatus{Active: 1},
+	}
+	resynced := running.DeepCopy()
+	resynced.ResourceVersion = "2"
+	resynced.Annotations = map[string]string{"some": "annotation"}
+	c.Assert(jobRunChanged(running, resynced), check.Equals, false)
+
+	retried := running.DeepCopy()
+	retried.Status.Failed = 1
+	c.Assert(jobRunChanged(running, retried), check.Equals, true)
+
+	succeeded := running.DeepCopy()
+	succeeded.Status.Active = 0
+	succeeded.Status.Succeeded = 1
+	succeeded.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: apiv1.ConditionTrue}}
+	c.Assert(jobRunChanged(running, succeeded), check.Equals, true)
+
+	probed := succeeded.DeepCopy()
+	probed.Status.Conditions[0].LastProbeTime = metav1.Now()
+	c.Assert(jobRunChanged(succeeded, probed), check.Equals, false)
+
+	reverted := succeeded.DeepCopy()
+	reverted.Status.Conditions[0].Status = apiv1.ConditionFalse
+	c.Assert(jobRunChanged(succeeded, reverted), check.Equals, true)
+}
+
+func (s *S) TestProcessNextJobRunRemovedJob(_ *check.C) {
+	controller, err := getClusterController(s.p, s.clusterClient)
+	require.NoError(s.t, err)
+	jobInformer, err := controller.getJobInformer()
+	require.NoError(s.t, err)
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]())
+	c := &clusterController{cluster: s.clusterClient, jobRunQueue: queue, leader: 1}
+	c.enqueueJobRun(&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "removed", Namespace: "default"}})
+	require.Equal(s.t, 1, queue.Len())
+	require.True(s.t, c.processNextJobRun(jobInformer))
+	require.Equal(s.t, 0, queue.Len())
+	require.Equal(s.t, 0, queue.NumRequeues("default/removed"))
+	queue.ShutDown()
+	require.False(s.t, c.processNextJobRun(jobInformer))
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/tsuru/tsuru/api/shutdown"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/envs/secretref"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
@@ -1271,7 +1272,22 @@ func runIsolatedCmdPod(ctx context.Context, client *ClusterClient, opts execOpts
 	appEnvs := provision.EnvsForAppAndVersion(opts.app, "", version)
 	var envs []apiv1.EnvVar
 	for _, envData := range appEnvs {
-		envs = append(envs, apiv1.EnvVar{Name: envData.Name, Value: envData.Value})
+		if env, ok := secretRefEnvVar(envData); ok {
+			err = secretref.Validate(ctx, opts.app, envData.SecretRef)
+			if err != nil {
+				return errors.Wrapf(err, "invalid secret reference in env %q", envData.Name)
+			}
+			envs = append(envs, env)
+			continue
+		}
+		value := envData.Value
+		if envData.SecretRef != "" {
+			value, err = secretref.Resolve(ctx, opts.app, envData.SecretRef)
+			if err != nil {
+				return err
+			}
+		}
+		envs = append(envs, apiv1.EnvVar{Name: envData.Name, Value: value})
 	}
 
 	plan := opts.app.Plan
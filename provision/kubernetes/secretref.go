@@ -0,0 +1,123 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/envs/secretref"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// secretAllowedAppsAnnotation and secretAllowedTeamsAnnotation list,
+	// separated by commas, the apps and the teams owning apps allowed to
+	// reference a Secret.
+	secretAllowedAppsAnnotation  = tsuruLabelPrefix + "allowed-apps"
+	secretAllowedTeamsAnnotation = tsuruLabelPrefix + "allowed-teams"
+)
+
+func init() {
+	secretref.Register(secretref.KubernetesScheme, &secretResolver{})
+}
+
+// secretResolver handles references to Secrets in the app namespace. They're
+// projected in the pod spec with secretKeyRef, so they're only resolved when
+// there's no pod spec to project them into. As the namespace is shared by the
+// apps of a pool, only Secrets allowing the app through their annotations may
+// be referenced, and never the ones holding the envs of tsuru apps and jobs.
+type secretResolver struct{}
+
+func (r *secretResolver) get(ctx context.Context, app *appTypes.App, ref secretref.Ref) ([]byte, error) {
+	client, err := clusterForPool(ctx, app.Pool)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := client.AppNamespace(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := client.CoreV1().Secrets(ns).Get(ctx, ref.Path, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return nil, errors.Errorf("secret %q not found in namespace %q", ref.Path, ns)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = checkSecretAccess(secret, app)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, errors.Errorf("key %q not found in secret %q", ref.Key, ref.Path)
+	}
+	return value, nil
+}
+
+// checkSecretAccess returns an error unless the app is allowed to reference
+// the secret.
+func checkSecretAccess(secret *apiv1.Secret, app *appTypes.App) error {
+	for _, label := range []string{provision.LabelAppName, provision.LabelJobName} {
+		if _, ok := secret.Labels[tsuruLabelPrefix+label]; ok {
+			return errors.Errorf("secret %q is managed by tsuru and can't be referenced", secret.Name)
+		}
+	}
+	if listContains(secret.Annotations[secretAllowedAppsAnnotation], app.Name) ||
+		listContains(secret.Annotations[secretAllowedTeamsAnnotation], app.TeamOwner) {
+		return nil
+	}
+	return errors.Errorf("secret %q doesn't allow app %q, it must be listed in the %q or %q annotations", secret.Name, app.Name, secretAllowedAppsAnnotation, secretAllowedTeamsAnnotation)
+}
+
+func listContains(list, value string) bool {
+	for _, item := range strings.Split(list, ",") {
+		if value != "" && strings.TrimSpace(item) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *secretResolver) Validate(ctx context.Context, app *appTypes.App, ref secretref.Ref) error {
+	_, err := r.get(ctx, app, ref)
+	return err
+}
+
+func (r *secretResolver) Resolve(ctx context.Context, app *appTypes.App, ref secretref.Ref) (string, error) {
+	value, err := r.get(ctx, app, ref)
+	return string(value), err
+}
+
+// secretRefEnvVar returns the env var projecting a reference to a Secret in
+// the app namespace, and whether env holds such a reference. The reference
+// must be validated with secretref.Validate before it's projected.
+func secretRefEnvVar(env bindTypes.EnvVar) (apiv1.EnvVar, bool) {
+	if env.SecretRef == "" {
+		return apiv1.EnvVar{}, false
+	}
+	ref, err := secretref.Parse(env.SecretRef)
+	if err != nil || ref.Scheme != secretref.KubernetesScheme {
+		return apiv1.EnvVar{}, false
+	}
+	return apiv1.EnvVar{
+		Name: env.Name,
+		ValueFrom: &apiv1.EnvVarSource{
+			SecretKeyRef: &apiv1.SecretKeySelector{
+				LocalObjectReference: apiv1.LocalObjectReference{
+					Name: ref.Path,
+				},
+				Key: ref.Key,
+			},
+		},
+	}, true
+}
@@ -10,10 +10,12 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/service"
@@ -54,11 +56,20 @@ type Pool struct {
 	Labels map[string]string
 }
 
+func (p Pool) toStored() provisionTypes.Pool {
+	return provisionTypes.Pool{Name: p.Name, Provisioner: p.Provisioner, Default: p.Default, Labels: p.Labels}
+}
+
+func fromStored(p provisionTypes.Pool) Pool {
+	return Pool{Name: p.Name, Provisioner: p.Provisioner, Default: p.Default, Labels: p.Labels}
+}
+
 type PoolInfo struct {
 	Pool
 	Public  bool                            `json:"public"`
 	Teams   []string                        `json:"teams"`
 	Allowed map[PoolConstraintType][]string `json:"allowed"`
+	Freezes []event.Freeze                  `json:"freezes,omitempty"`
 }
 
 type AddPoolOptions struct {
@@ -367,12 +378,17 @@ func (p *Pool) Info(ctx context.Context) (*PoolInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	freezes, err := event.ListFreezes(ctx, p.Name, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
 	return &PoolInfo{
 		Pool:    *p,
 		Public:  teams.AllowsAll(),
 		Teams:   resolvedConstraints[ConstraintTypeTeam],
 		Allowed: resolvedConstraints,
+		Freezes: freezes,
 	}, nil
 }
 
@@ -409,7 +425,7 @@ func AddPool(ctx context.Context, opts AddPoolOptions) error {
 	if err := pool.validate(); err != nil {
 		return err
 	}
-	collection, err := storagev2.PoolCollection()
+	poolStorage, err := PoolStorage()
 	if err != nil {
 		return err
 	}
@@ -419,9 +435,9 @@ func AddPool(ctx context.Context, opts AddPoolOptions) error {
 			return err
 		}
 	}
-	_, err = collection.InsertOne(ctx, pool)
+	err = poolStorage.Insert(ctx, pool.toStored())
 	if err != nil {
-		if mongo.IsDuplicateKeyError(err) {
+		if err == provisionTypes.ErrPoolAlreadyExists {
 			return ErrPoolAlreadyExists
 		}
 		return err
@@ -455,55 +471,46 @@ func RenamePoolTeam(ctx context.Context, oldName, newName string) error {
 }
 
 func changeDefaultPool(ctx context.Context, force bool) error {
-	collection, err := storagev2.PoolCollection()
-	if err != nil {
-		return err
+	p, err := GetDefaultPool(ctx)
+	if err == ErrPoolNotFound {
+		return nil
 	}
-	p, err := listPools(ctx, mongoBSON.M{"default": true})
 	if err != nil {
 		return err
 	}
-	if len(p) > 0 {
-		if !force {
-			return ErrDefaultPoolAlreadyExists
-		}
-		_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": p[0].Name}, mongoBSON.M{"$set": mongoBSON.M{"default": false}})
-		return err
+	if !force {
+		return ErrDefaultPoolAlreadyExists
 	}
-	return nil
+	p.Default = false
+	return updatePool(ctx, *p)
 }
 
 func RemovePool(ctx context.Context, poolName string) error {
-	collection, err := storagev2.PoolCollection()
-	if err != nil {
-		return err
-	}
-	result, err := collection.DeleteOne(ctx, mongoBSON.M{"_id": poolName})
+	poolStorage, err := PoolStorage()
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return ErrPoolNotFound
-		}
 		return err
-
 	}
-
-	if result.DeletedCount == 0 {
+	err = poolStorage.Delete(ctx, poolName)
+	if err == provisionTypes.ErrPoolNotFound {
 		return ErrPoolNotFound
 	}
-
 	return err
 }
 
-func AddTeamsToPool(ctx context.Context, poolName string, teams []string) error {
-	collection, err := storagev2.PoolCollection()
+func updatePool(ctx context.Context, p Pool) error {
+	poolStorage, err := PoolStorage()
 	if err != nil {
 		return err
 	}
-	var pool Pool
-	err = collection.FindOne(ctx, mongoBSON.M{"_id": poolName}).Decode(&pool)
-	if err == mongo.ErrNoDocuments {
+	err = poolStorage.Update(ctx, p.toStored())
+	if err == provisionTypes.ErrPoolNotFound {
 		return ErrPoolNotFound
 	}
+	return err
+}
+
+func AddTeamsToPool(ctx context.Context, poolName string, teams []string) error {
+	pool, err := GetPoolByName(ctx, poolName)
 	if err != nil {
 		return err
 	}
@@ -526,15 +533,7 @@ func AddTeamsToPool(ctx context.Context, poolName string, teams []string) error
 }
 
 func RemoveTeamsFromPool(ctx context.Context, poolName string, teams []string) error {
-	collection, err := storagev2.PoolCollection()
-	if err != nil {
-		return err
-	}
-	var pool Pool
-	err = collection.FindOne(ctx, mongoBSON.M{"_id": poolName}).Decode(&pool)
-	if err == mongo.ErrNoDocuments {
-		return ErrPoolNotFound
-	}
+	_, err := GetPoolByName(ctx, poolName)
 	if err != nil {
 		return err
 	}
@@ -549,7 +548,9 @@ func RemoveTeamsFromPool(ctx context.Context, poolName string, teams []string) e
 }
 
 func ListPools(ctx context.Context, names ...string) ([]Pool, error) {
-	return listPools(ctx, mongoBSON.M{"_id": mongoBSON.M{"$in": names}})
+	return listPools(ctx, func(p Pool) bool {
+		return contains(names, p.Name)
+	})
 }
 
 func ListAllPools(ctx context.Context) ([]Pool, error) {
@@ -568,19 +569,23 @@ func ListPoolsForTeam(ctx context.Context, team string) ([]Pool, error) {
 	return getPoolsSatisfyConstraints(ctx, true, ConstraintTypeTeam, team)
 }
 
-func listPools(ctx context.Context, query mongoBSON.M) ([]Pool, error) {
-	collection, err := storagev2.PoolCollection()
+// listPools returns the pools accepted by filter, or every pool when filter
+// is nil.
+func listPools(ctx context.Context, filter func(Pool) bool) ([]Pool, error) {
+	poolStorage, err := PoolStorage()
 	if err != nil {
 		return nil, err
 	}
-	pools := []Pool{}
-	cursor, err := collection.Find(ctx, query)
+	stored, err := poolStorage.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
-	err = cursor.All(ctx, &pools)
-	if err != nil {
-		return nil, err
+	pools := []Pool{}
+	for _, sp := range stored {
+		p := fromStored(sp)
+		if filter == nil || filter(p) {
+			pools = append(pools, p)
+		}
 	}
 	return pools, nil
 }
@@ -607,18 +612,18 @@ func GetProvisionerForPool(ctx context.Context, name string) (provision.Provisio
 
 // GetPoolByName finds a pool by name
 func GetPoolByName(ctx context.Context, name string) (*Pool, error) {
-	collection, err := storagev2.PoolCollection()
+	poolStorage, err := PoolStorage()
 	if err != nil {
 		return nil, err
 	}
-	var p Pool
-	err = collection.FindOne(ctx, mongoBSON.M{"_id": name}).Decode(&p)
+	sp, err := poolStorage.FindByName(ctx, name)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
+		if err == provisionTypes.ErrPoolNotFound {
 			return nil, ErrPoolNotFound
 		}
 		return nil, err
 	}
+	p := fromStored(*sp)
 	return &p, nil
 }
 
@@ -650,19 +655,16 @@ func contains(arr []string, c string) bool {
 }
 
 func GetDefaultPool(ctx context.Context) (*Pool, error) {
-	collection, err := storagev2.PoolCollection()
+	pools, err := listPools(ctx, func(p Pool) bool {
+		return p.Default
+	})
 	if err != nil {
 		return nil, err
 	}
-	var pool Pool
-	err = collection.FindOne(ctx, mongoBSON.M{"default": true}).Decode(&pool)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, ErrPoolNotFound
-		}
-		return nil, err
+	if len(pools) == 0 {
+		return nil, ErrPoolNotFound
 	}
-	return &pool, nil
+	return &pools[0], nil
 }
 
 func PoolUpdate(ctx context.Context, name string, opts UpdatePoolOptions) error {
@@ -676,18 +678,11 @@ func PoolUpdate(ctx context.Context, name string, opts UpdatePoolOptions) error
 			return err
 		}
 	}
-	query := mongoBSON.M{}
-	if opts.Default != nil {
-		query["default"] = *opts.Default
-	}
 	if len(opts.Labels) > 0 {
 		if err = validateLabels(opts.Labels); err != nil {
 			return err
 		}
 	}
-	if opts.Labels != nil {
-		query["labels"] = opts.Labels
-	}
 	if (opts.Public != nil && *opts.Public) || (opts.Default != nil && *opts.Default) {
 		errConstraint := SetPoolConstraint(ctx, &PoolConstraint{PoolExpr: name, Field: ConstraintTypeTeam, Values: []string{"*"}})
 		if errConstraint != nil {
@@ -700,27 +695,22 @@ func PoolUpdate(ctx context.Context, name string, opts UpdatePoolOptions) error
 			return err
 		}
 	}
-	if len(query) == 0 {
+	if opts.Default == nil && opts.Labels == nil {
 		return nil
 	}
-
-	collection, err := storagev2.PoolCollection()
+	// The pool is read again as changing the default pool may have updated
+	// it.
+	pool, err := GetPoolByName(ctx, name)
 	if err != nil {
 		return err
 	}
-	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": name}, mongoBSON.M{"$set": query})
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return ErrPoolNotFound
-		}
-		return err
+	if opts.Default != nil {
+		pool.Default = *opts.Default
 	}
-
-	if result.MatchedCount == 0 {
-		return ErrPoolNotFound
+	if opts.Labels != nil {
+		pool.Labels = opts.Labels
 	}
-
-	return nil
+	return updatePool(ctx, *pool)
 }
 
 func exprAsGlobPattern(expr string) string {
@@ -655,7 +655,9 @@ func (s *S) TestListPoolByQuery(c *check.C) {
 	pool2 := Pool{Name: "pool2", Default: true}
 	_, err = s.collection.InsertOne(context.TODO(), pool2)
 	c.Assert(err, check.IsNil)
-	pools, err := listPools(context.TODO(), mongoBSON.M{"_id": "pool2"})
+	pools, err := listPools(context.TODO(), func(p Pool) bool {
+		return p.Name == "pool2"
+	})
 	c.Assert(err, check.IsNil)
 	c.Assert(pools, check.HasLen, 1)
 	c.Assert(pools[0].Name, check.Equals, "pool2")
@@ -215,6 +215,16 @@ func (p *FakeProvisioner) JobExecutions(jobName string) int {
 	return 0
 }
 
+// JobTriggers returns the arguments of each time a job has been triggered
+func (p *FakeProvisioner) JobTriggers(jobName string) []jobTypes.TriggerArgs {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	if j, ok := p.jobs[jobName]; ok {
+		return j.triggers
+	}
+	return nil
+}
+
 func (p *FakeProvisioner) GetUnits(app *appTypes.App) []provTypes.Unit {
 	p.mut.RLock()
 	pApp := p.apps[app.Name]
@@ -787,6 +797,7 @@ type provisionedJob struct {
 	units      []provTypes.Unit
 	job        *jobTypes.Job
 	executions int
+	triggers   []jobTypes.TriggerArgs
 }
 
 type AutoScaleProvisioner struct {
@@ -889,7 +900,7 @@ func (p *JobProvisioner) DestroyJob(ctx context.Context, job *jobTypes.Job) erro
 	return nil
 }
 
-func (p *JobProvisioner) TriggerCron(ctx context.Context, job *jobTypes.Job, pool string) error {
+func (p *JobProvisioner) TriggerCron(ctx context.Context, job *jobTypes.Job, pool string, args jobTypes.TriggerArgs) error {
 	p.mut.Lock()
 	defer p.mut.Unlock()
 	j, ok := p.jobs[job.Name]
@@ -897,6 +908,7 @@ func (p *JobProvisioner) TriggerCron(ctx context.Context, job *jobTypes.Job, poo
 		return errNotProvisioned
 	}
 	j.executions++
+	j.triggers = append(j.triggers, args)
 	return nil
 }
 
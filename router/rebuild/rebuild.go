@@ -101,6 +101,12 @@ func RebuildRoutesInRouter(ctx context.Context, appRouter appTypes.AppRouter, o
 			Target: route.ExtraData,
 		})
 	}
+	if len(o.App.RoutingWeights) > 0 {
+		opts.Weights = map[string]int{}
+		for version, weight := range o.App.RoutingWeights {
+			opts.Weights[fmt.Sprintf("v%d.version", version)] = weight
+		}
+	}
 	return r.EnsureBackend(ctx, o.App, opts)
 }
 
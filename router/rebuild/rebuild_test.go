@@ -86,3 +86,19 @@ func (s *S) TestRebuildRoutesSetsHealthcheck(c *check.C) {
 	}
 	c.Assert(routertest.FakeRouter.GetHealthcheck("my-test-app"), check.DeepEquals, expected)
 }
+
+func (s *S) TestRebuildRoutesSetsWeights(c *check.C) {
+	a := appTypes.App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newVersion(c, &a)
+	a.RoutingWeights = map[int]int{1: 90, 2: 10}
+	err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{
+		App: &a,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.BackendOpts["my-test-app"].Weights, check.DeepEquals, map[string]int{
+		"v1.version": 90,
+		"v2.version": 10,
+	})
+}
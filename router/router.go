@@ -154,6 +154,11 @@ type EnsureBackendOpts struct {
 	CertIssuers map[string]string      `json:"certIssuers,omitempty"`
 	Prefixes    []BackendPrefix        `json:"prefixes"`
 	Healthcheck router.HealthcheckData `json:"healthcheck"`
+	// Weights maps version prefixes (e.g. "v2.version") to the percentage of
+	// the default route traffic they must receive. It's only set while a
+	// canary deploy is in progress, otherwise traffic is split among all
+	// routable units.
+	Weights map[string]int `json:"weights,omitempty"`
 }
 
 // TLSRouter is a router that supports adding and removing
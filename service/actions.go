@@ -11,15 +11,13 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/action"
-	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	bindTypes "github.com/tsuru/tsuru/types/bind"
 	jobTypes "github.com/tsuru/tsuru/types/job"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	serviceTypes "github.com/tsuru/tsuru/types/service"
 )
 
 // notifyCreateServiceInstance is an action that calls the service endpoint
@@ -94,26 +92,23 @@ var createServiceInstance = action.Action{
 			return nil, errors.New("Second parameter must be a *ServiceInstance.")
 		}
 
-		serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+		sis, err := serviceInstanceStorage()
 		if err != nil {
 			return nil, err
 		}
-
-		_, err = serviceInstancesCollection.InsertOne(ctx.Context, instance)
-		return nil, err
+		return nil, sis.Insert(ctx.Context, serviceTypes.ServiceInstance(*instance))
 	},
 	Backward: func(ctx action.BWContext) {
 		instance, ok := ctx.Params[1].(*ServiceInstance)
 		if !ok {
 			return
 		}
-		serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+		sis, err := serviceInstanceStorage()
 		if err != nil {
-			log.Errorf("[create-service-instance backward] could not get service instances collection: %s", err)
+			log.Errorf("[create-service-instance backward] could not get service instances storage: %s", err)
 			return
 		}
-
-		_, err = serviceInstancesCollection.DeleteOne(ctx.Context, mongoBSON.M{"name": instance.Name, "service_name": instance.ServiceName})
+		err = sis.Delete(ctx.Context, instance.ServiceName, instance.Name)
 		if err != nil {
 			log.Errorf("[create-service-instance backward] could not remove service instance: %s", err)
 			return
@@ -137,54 +132,33 @@ var updateServiceInstance = action.Action{
 		if !ok {
 			return nil, errors.New("Third parameter must be a ServiceInstance.")
 		}
-		collection, err := storagev2.ServiceInstancesCollection()
+		sis, err := serviceInstanceStorage()
 		if err != nil {
 			return nil, err
 		}
-		_, err = collection.UpdateOne(
-			ctx.Context,
-			mongoBSON.M{"name": instance.Name, "service_name": instance.ServiceName},
-			mongoBSON.M{
-				"$set": mongoBSON.M{
-					"description": updateData.Description,
-					"tags":        updateData.Tags,
-					"teamowner":   updateData.TeamOwner,
-					"plan_name":   updateData.PlanName,
-					"parameters":  updateData.Parameters,
-				},
-				"$addToSet": mongoBSON.M{
-					"teams": updateData.TeamOwner,
-				},
-			},
-		)
-
-		return nil, err
+		updated := instance
+		updated.Description = updateData.Description
+		updated.Tags = updateData.Tags
+		updated.TeamOwner = updateData.TeamOwner
+		updated.PlanName = updateData.PlanName
+		updated.Parameters = updateData.Parameters
+		updated.Teams = append([]string{}, instance.Teams...)
+		if !hasString(updated.Teams, updateData.TeamOwner) {
+			updated.Teams = append(updated.Teams, updateData.TeamOwner)
+		}
+		return nil, sis.Update(ctx.Context, serviceTypes.ServiceInstance(updated))
 	},
 	Backward: func(ctx action.BWContext) {
 		instance, ok := ctx.Params[1].(ServiceInstance)
 		if !ok {
 			return
 		}
-		collection, err := storagev2.ServiceInstancesCollection()
+		sis, err := serviceInstanceStorage()
 		if err != nil {
-			log.Errorf("[update-service-instance backward] could not get service instances collection: %s", err)
+			log.Errorf("[update-service-instance backward] could not get service instances storage: %s", err)
 			return
 		}
-
-		_, err = collection.UpdateOne(
-			ctx.Context,
-			mongoBSON.M{"name": instance.Name, "service_name": instance.ServiceName},
-			mongoBSON.M{
-				"$set": mongoBSON.M{
-					"description": instance.Description,
-					"tags":        instance.Tags,
-					"teamowner":   instance.TeamOwner,
-					"teams":       instance.Teams,
-					"plan_name":   instance.PlanName,
-				},
-			},
-		)
-
+		err = sis.Update(ctx.Context, serviceTypes.ServiceInstance(instance))
 		if err != nil {
 			log.Errorf("[update-service-instance backward] could not update service instance: %s", err)
 		}
@@ -259,29 +233,11 @@ var bindAppDBAction = &action.Action{
 		if args == nil {
 			return nil, errors.New("invalid arguments for pipeline, expected *bindAppPipelineArgs.")
 		}
-		collection, err := storagev2.ServiceInstancesCollection()
-		if err != nil {
-			return nil, err
-		}
-		si := args.serviceInstance
-		updateOp := mongoBSON.M{"$addToSet": mongoBSON.M{"apps": args.app.Name}}
-		result, err := collection.UpdateOne(ctx.Context, mongoBSON.M{"name": si.Name, "service_name": si.ServiceName, "apps": mongoBSON.M{"$ne": args.app.Name}}, updateOp)
-		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				return nil, ErrAppAlreadyBound
-			}
-			return nil, err
-		}
-
-		if result.ModifiedCount == 0 {
-			return nil, ErrAppAlreadyBound
-		}
-
-		return nil, nil
+		return nil, args.serviceInstance.addApp(ctx.Context, args.app.Name)
 	},
 	Backward: func(ctx action.BWContext) {
 		args, _ := ctx.Params[0].(*bindAppPipelineArgs)
-		if err := args.serviceInstance.updateData(ctx.Context, mongoBSON.M{"$pull": mongoBSON.M{"apps": args.app.Name}}); err != nil {
+		if err := args.serviceInstance.removeApp(ctx.Context, args.app.Name); err != nil {
 			log.Errorf("[bind-app-db backward] could not remove app from service instance: %s", err)
 		}
 	},
@@ -295,29 +251,11 @@ var bindJobDBAction = &action.Action{
 		if args == nil {
 			return nil, errors.New("invalid arguments for pipeline, expected *bindJobPipelineArgs.")
 		}
-		collection, err := storagev2.ServiceInstancesCollection()
-		if err != nil {
-			return nil, err
-		}
-		si := args.serviceInstance
-		updateOp := mongoBSON.M{"$addToSet": mongoBSON.M{"jobs": args.job.Name}}
-		result, err := collection.UpdateOne(ctx.Context, mongoBSON.M{"name": si.Name, "service_name": si.ServiceName, "jobs": mongoBSON.M{"$ne": args.job.Name}}, updateOp)
-		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				return nil, ErrJobAlreadyBound
-			}
-			return nil, err
-		}
-
-		if result.ModifiedCount == 0 {
-			return nil, ErrJobAlreadyBound
-		}
-
-		return nil, nil
+		return nil, args.serviceInstance.addJob(ctx.Context, args.job.Name)
 	},
 	Backward: func(ctx action.BWContext) {
 		args, _ := ctx.Params[0].(*bindJobPipelineArgs)
-		if err := args.serviceInstance.updateData(ctx.Context, mongoBSON.M{"$pull": mongoBSON.M{"jobs": args.job.Name}}); err != nil {
+		if err := args.serviceInstance.removeJob(ctx.Context, args.job.Name); err != nil {
 			log.Errorf("[bind-job-db backward] could not remove job from service instance: %s", err)
 		}
 	},
@@ -518,12 +456,12 @@ var unbindAppDB = action.Action{
 		if args == nil {
 			return nil, errors.New("invalid arguments for pipeline, expected *bindAppPipelineArgs.")
 		}
-		return nil, args.serviceInstance.updateData(ctx.Context, mongoBSON.M{"$pull": mongoBSON.M{"apps": args.app.Name}})
+		return nil, args.serviceInstance.removeApp(ctx.Context, args.app.Name)
 	},
 	Backward: func(ctx action.BWContext) {
 		args, _ := ctx.Params[0].(*bindAppPipelineArgs)
-		err := args.serviceInstance.updateData(ctx.Context, mongoBSON.M{"$addToSet": mongoBSON.M{"apps": args.app.Name}})
-		if err != nil {
+		err := args.serviceInstance.addApp(ctx.Context, args.app.Name)
+		if err != nil && err != ErrAppAlreadyBound {
 			log.Errorf("[unbind-app-db backward] failed to rebind app in db: %s", err)
 		}
 	},
@@ -601,12 +539,12 @@ var unbindJobDB = action.Action{
 		if args == nil {
 			return nil, errors.New("invalid arguments for pipeline, expected *bindJobPipelineArgs.")
 		}
-		return nil, args.serviceInstance.updateData(ctx.Context, mongoBSON.M{"$pull": mongoBSON.M{"jobs": args.job.Name}})
+		return nil, args.serviceInstance.removeJob(ctx.Context, args.job.Name)
 	},
 	Backward: func(ctx action.BWContext) {
 		args, _ := ctx.Params[0].(*bindJobPipelineArgs)
-		err := args.serviceInstance.updateData(ctx.Context, mongoBSON.M{"$addToSet": mongoBSON.M{"jobs": args.job.Name}})
-		if err != nil {
+		err := args.serviceInstance.addJob(ctx.Context, args.job.Name)
+		if err != nil && err != ErrJobAlreadyBound {
 			log.Errorf("[unbind-job-db backward] failed to rebind job in db: %s", err)
 		}
 	},
@@ -14,10 +14,9 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/permission"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	serviceTypes "github.com/tsuru/tsuru/types/service"
 )
 
 var (
@@ -148,22 +147,11 @@ func manifestActionNames(manifest *ServiceManifest) map[string]struct{} {
 }
 
 func persistManifest(ctx context.Context, serviceName string, manifest *ServiceManifest) error {
-	servicesCollection, err := storagev2.ServicesCollection()
+	ss, err := serviceStorage()
 	if err != nil {
 		return err
 	}
-	update := mongoBSON.M{"$unset": mongoBSON.M{"manifest": 1}}
-	if manifest != nil {
-		update = mongoBSON.M{"$set": mongoBSON.M{"manifest": manifest}}
-	}
-	result, err := servicesCollection.UpdateOne(ctx, mongoBSON.M{"_id": serviceName}, update)
-	if err != nil {
-		return err
-	}
-	if result.MatchedCount == 0 {
-		return ErrServiceNotFound
-	}
-	return nil
+	return ss.SetManifest(ctx, serviceName, (*serviceTypes.ServiceManifest)(manifest))
 }
 
 // manifestGrantConflicts finds roles whose dynamic grants for serviceName
@@ -180,20 +168,10 @@ func manifestGrantConflicts(ctx context.Context, serviceName string, removedActi
 	for action := range manifestActionNames(next) {
 		remainingPerms = append(remainingPerms, permission.DynamicActionPermissionName(serviceName, action))
 	}
-	rolesCollection, err := storagev2.RolesCollection()
+	roles, err := permission.ListRolesWithDynamicPermissionPrefix(ctx, servicePermPrefix)
 	if err != nil {
 		return nil, err
 	}
-	cursor, err := rolesCollection.Find(ctx, mongoBSON.M{
-		"dynamic_scheme_names": mongoBSON.M{"$regex": "^" + regexp.QuoteMeta(servicePermPrefix) + "(\\.|$)"},
-	})
-	if err != nil {
-		return nil, err
-	}
-	var roles []permission.Role
-	if err := cursor.All(ctx, &roles); err != nil {
-		return nil, err
-	}
 	roleNamesByAction := map[string]map[string]struct{}{}
 	for _, role := range roles {
 		for _, grant := range role.DynamicSchemeNames {
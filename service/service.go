@@ -12,21 +12,19 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
-	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	jobTypes "github.com/tsuru/tsuru/types/job"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	serviceTypes "github.com/tsuru/tsuru/types/service"
 	"github.com/tsuru/tsuru/validation"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type ServiceEncoding string
+type ServiceEncoding = serviceTypes.ServiceEncoding
 
 var (
 	ServiceEncodingDefault = ServiceEncoding("") // default is form
@@ -172,81 +170,96 @@ type ServiceClient interface {
 }
 
 var (
-	ErrServiceAlreadyExists = errors.New("Service already exists.")
-	ErrServiceNotFound      = errors.New("Service not found.")
+	ErrServiceAlreadyExists = serviceTypes.ErrServiceAlreadyExists
+	ErrServiceNotFound      = serviceTypes.ErrServiceNotFound
 	ErrMissingPool          = errors.New("Missing pool")
 
 	schemeRegexp = regexp.MustCompile("^https?://")
 )
 
+func serviceStorage() (serviceTypes.ServiceStorage, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dbDriver.ServiceStorage, nil
+}
+
+func (s *Service) toStorage() serviceTypes.Service {
+	return serviceTypes.Service{
+		Name:           s.Name,
+		Username:       s.Username,
+		Password:       s.Password,
+		Endpoint:       s.Endpoint,
+		OwnerTeams:     s.OwnerTeams,
+		Teams:          s.Teams,
+		Doc:            s.Doc,
+		IsRestricted:   s.IsRestricted,
+		IsMultiCluster: s.IsMultiCluster,
+		Encoding:       s.Encoding,
+		Manifest:       (*serviceTypes.ServiceManifest)(s.Manifest),
+	}
+}
+
+func fromStorage(s serviceTypes.Service) Service {
+	return Service{
+		Name:           s.Name,
+		Username:       s.Username,
+		Password:       s.Password,
+		Endpoint:       s.Endpoint,
+		OwnerTeams:     s.OwnerTeams,
+		Teams:          s.Teams,
+		Doc:            s.Doc,
+		IsRestricted:   s.IsRestricted,
+		IsMultiCluster: s.IsMultiCluster,
+		Encoding:       s.Encoding,
+		Manifest:       (*ServiceManifest)(s.Manifest),
+	}
+}
+
 func Get(ctx context.Context, service string) (Service, error) {
-	collection, err := storagev2.ServicesCollection()
+	ss, err := serviceStorage()
 	if err != nil {
 		return Service{}, err
 	}
-	var s Service
-	if err := collection.FindOne(ctx, mongoBSON.M{"_id": service}).Decode(&s); err != nil {
-		if err == mongo.ErrNoDocuments {
-			return Service{}, ErrServiceNotFound
-		}
+	s, err := ss.FindByName(ctx, service)
+	if err != nil {
 		return Service{}, err
 	}
-	return s, nil
+	return fromStorage(*s), nil
 }
 
 func Create(ctx context.Context, s Service) error {
 	if err := s.validate(ctx, false); err != nil {
 		return err
 	}
-	collection, err := storagev2.ServicesCollection()
+	ss, err := serviceStorage()
 	if err != nil {
 		return err
 	}
-	n, err := collection.CountDocuments(ctx, mongoBSON.M{"_id": s.Name})
-	if err != nil {
-		return err
-	}
-	if n != 0 {
-		return ErrServiceAlreadyExists
-	}
-	_, err = collection.InsertOne(ctx, s)
-
-	return err
+	return ss.Insert(ctx, s.toStorage())
 }
 
 func Update(ctx context.Context, s Service) error {
 	if err := s.validate(ctx, true); err != nil {
 		return err
 	}
-	collection, err := storagev2.ServicesCollection()
+	ss, err := serviceStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": s.Name}, s)
-	if err == mongo.ErrNoDocuments {
-		return ErrServiceNotFound
-	}
-	return err
+	return ss.Update(ctx, s.toStorage())
 }
 
 func Delete(ctx context.Context, s Service) error {
-	collection, err := storagev2.ServicesCollection()
-	if err != nil {
-		return err
-	}
-	result, err := collection.DeleteOne(ctx, mongoBSON.M{"_id": s.Name})
-	if err == mongo.ErrNoDocuments {
-		return ErrServiceNotFound
-	}
+	ss, err := serviceStorage()
 	if err != nil {
 		return err
 	}
-
-	if result.DeletedCount == 0 {
-		return ErrServiceNotFound
-	}
-
-	return nil
+	return ss.Delete(ctx, s.Name)
 }
 
 func GetServices(ctx context.Context) ([]Service, error) {
@@ -254,102 +267,50 @@ func GetServices(ctx context.Context) ([]Service, error) {
 }
 
 func GetServicesByTeamsAndServices(ctx context.Context, teams []string, services []string) ([]Service, error) {
-	var filter mongoBSON.M
+	var filter *serviceTypes.ServiceFilter
 	if teams != nil || services != nil {
-		orFilter := []mongoBSON.M{
-			{"is_restricted": false},
-		}
-
-		if teams != nil {
-			orFilter = append(orFilter, mongoBSON.M{"teams": mongoBSON.M{"$in": teams}})
-		}
-		if services != nil {
-			orFilter = append(orFilter, mongoBSON.M{"_id": mongoBSON.M{"$in": services}})
-		}
-
-		if len(orFilter) > 1 {
-			filter = mongoBSON.M{
-				"$or": orFilter,
-			}
-		} else if len(orFilter) == 1 {
-			filter = orFilter[0]
+		filter = &serviceTypes.ServiceFilter{
+			Unrestricted: true,
+			Teams:        teams,
+			Names:        services,
 		}
 	}
 	return getServicesByFilter(ctx, filter)
 }
 
 func GetServicesByOwnerTeamsAndServices(ctx context.Context, teams []string, services []string) ([]Service, error) {
-	var filter mongoBSON.M
+	var filter *serviceTypes.ServiceFilter
 	if teams != nil || services != nil {
-		orFilter := []mongoBSON.M{}
-
-		if teams != nil {
-			orFilter = append(orFilter, mongoBSON.M{"owner_teams": mongoBSON.M{"$in": teams}})
-		}
-		if services != nil {
-			orFilter = append(orFilter, mongoBSON.M{"_id": mongoBSON.M{"$in": services}})
-		}
-
-		if len(orFilter) > 1 {
-			filter = mongoBSON.M{
-				"$or": orFilter,
-			}
-		} else if len(orFilter) == 1 {
-			filter = orFilter[0]
+		filter = &serviceTypes.ServiceFilter{
+			OwnerTeams: teams,
+			Names:      services,
 		}
 	}
 	return getServicesByFilter(ctx, filter)
 }
 
 func RenameServiceTeam(ctx context.Context, oldName, newName string) error {
-	collection, err := storagev2.ServicesCollection()
-	if err != nil {
-		return err
-	}
-
-	models := []mongo.WriteModel{}
-
-	for _, field := range []string{"owner_teams", "teams"} {
-		models = append(
-			models,
-			mongo.NewUpdateManyModel().
-				SetFilter(mongoBSON.M{field: oldName}).
-				SetUpdate(mongoBSON.M{"$addToSet": mongoBSON.M{field: newName}}),
-
-			mongo.NewUpdateManyModel().
-				SetFilter(mongoBSON.M{field: oldName}).
-				SetUpdate(mongoBSON.M{"$pull": mongoBSON.M{field: oldName}}),
-		)
-	}
-
-	_, err = collection.BulkWrite(ctx, models)
+	ss, err := serviceStorage()
 	if err != nil {
 		return err
 	}
-
-	return nil
+	return ss.RenameTeam(ctx, oldName, newName)
 }
 
-func getServicesByFilter(ctx context.Context, filter mongoBSON.M) ([]Service, error) {
-	collection, err := storagev2.ServicesCollection()
+func getServicesByFilter(ctx context.Context, filter *serviceTypes.ServiceFilter) ([]Service, error) {
+	ss, err := serviceStorage()
 	if err != nil {
 		return nil, err
 	}
-	if filter == nil {
-		filter = mongoBSON.M{}
-	}
-	var services []Service
-	cursor, err := collection.Find(ctx, filter)
+	dbServices, err := ss.FindAll(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-
-	err = cursor.All(ctx, &services)
-	if err != nil {
-		return nil, err
+	var services []Service
+	for _, s := range dbServices {
+		services = append(services, fromStorage(s))
 	}
-
-	return services, err
+	return services, nil
 }
 
 func (s *Service) HasTeam(team *authTypes.Team) bool {
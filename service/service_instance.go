@@ -15,26 +15,24 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/action"
-	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	jobTypes "github.com/tsuru/tsuru/types/job"
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	serviceTypes "github.com/tsuru/tsuru/types/service"
 )
 
 var (
-	ErrServiceInstanceNotFound                  = errors.New("service instance not found")
+	ErrServiceInstanceNotFound                  = serviceTypes.ErrServiceInstanceNotFound
 	ErrInvalidInstanceName                      = errors.New("invalid service instance name")
-	ErrInstanceNameAlreadyExists                = errors.New("instance name already exists.")
+	ErrInstanceNameAlreadyExists                = serviceTypes.ErrInstanceNameAlreadyExists
 	ErrAccessNotAllowed                         = errors.New("user does not have access to this service instance")
 	ErrTeamMandatory                            = errors.New("please specify the team that owns the service instance")
-	ErrAppAlreadyBound                          = errors.New("app is already bound to this service instance")
-	ErrJobAlreadyBound                          = errors.New("job is already bound to this service instance")
+	ErrAppAlreadyBound                          = serviceTypes.ErrAppAlreadyBound
+	ErrJobAlreadyBound                          = serviceTypes.ErrJobAlreadyBound
 	ErrAppNotBound                              = errors.New("app is not bound to this service instance")
 	ErrJobNotBound                              = errors.New("job is not bound to this service instance")
 	ErrUnitNotBound                             = errors.New("unit is not bound to this service instance")
@@ -48,27 +46,25 @@ var (
 	instanceNameRegexp                          = regexp.MustCompile(`^[A-Za-z][-a-zA-Z0-9_]+$`)
 )
 
-type ServiceInstance struct {
-	Name        string                 `json:"name"`
-	ServiceName string                 `bson:"service_name" json:"service_name"`
-	PlanName    string                 `bson:"plan_name" json:"plan_name"`
-	Apps        []string               `json:"apps"`
-	Jobs        []string               `json:"jobs"`
-	Teams       []string               `json:"teams"`
-	TeamOwner   string                 `json:"team_owner"`
-	Description string                 `json:"description"`
-	Tags        []string               `json:"tags"`
-	Parameters  map[string]interface{} `json:"parameters,omitempty"`
-	// Pool is the pool name which the Service Instance should run into.
-	// This field is mandatory iff the parent Service is running in
-	// multi-cluster mode (see Service.IsMultiCluster field)
-	//
-	// NOTE: after the service instance is created, this field turns immutable.
-	Pool string `json:"pool,omitempty"`
-
-	// ForceRemove indicates whether service instance should be removed even the
-	// related call to service API fails.
-	ForceRemove bool `bson:"-" json:"-"`
+type ServiceInstance serviceTypes.ServiceInstance
+
+func serviceInstanceStorage() (serviceTypes.ServiceInstanceStorage, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dbDriver.ServiceInstanceStorage, nil
+}
+
+func toServiceInstances(instances []serviceTypes.ServiceInstance) []ServiceInstance {
+	var result []ServiceInstance
+	for _, si := range instances {
+		result = append(result, ServiceInstance(si))
+	}
+	return result
 }
 
 // DeleteInstance deletes the service instance from the database.
@@ -91,13 +87,11 @@ func DeleteInstance(ctx context.Context, si *ServiceInstance, evt *event.Event,
 		}
 		fmt.Fprintf(evt, "could not delete the service instance on service api: %v. ignoring this error due to force removal...\n", err)
 	}
-	collection, err := storagev2.ServiceInstancesCollection()
+	sis, err := serviceInstanceStorage()
 	if err != nil {
 		return err
 	}
-
-	_, err = collection.DeleteOne(ctx, mongoBSON.M{"name": si.Name, "service_name": si.ServiceName})
-	return err
+	return sis.Delete(ctx, si.ServiceName, si.Name)
 }
 
 func (si *ServiceInstance) GetIdentifier() string {
@@ -191,16 +185,38 @@ func (si *ServiceInstance) Update(ctx context.Context, service Service, updateDa
 	return pipeline.Execute(ctx, service, *si, updateData, evt, requestID)
 }
 
-func (si *ServiceInstance) updateData(ctx context.Context, update mongoBSON.M) error {
-	collection, err := storagev2.ServiceInstancesCollection()
+func (si *ServiceInstance) addApp(ctx context.Context, appName string) error {
+	sis, err := serviceInstanceStorage()
 	if err != nil {
 		return err
 	}
-	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": si.Name, "service_name": si.ServiceName}, update)
-	return err
+	return sis.AddApp(ctx, si.ServiceName, si.Name, appName)
+}
+
+func (si *ServiceInstance) removeApp(ctx context.Context, appName string) error {
+	sis, err := serviceInstanceStorage()
+	if err != nil {
+		return err
+	}
+	return sis.RemoveApp(ctx, si.ServiceName, si.Name, appName)
+}
+
+func (si *ServiceInstance) addJob(ctx context.Context, jobName string) error {
+	sis, err := serviceInstanceStorage()
+	if err != nil {
+		return err
+	}
+	return sis.AddJob(ctx, si.ServiceName, si.Name, jobName)
+}
+
+func (si *ServiceInstance) removeJob(ctx context.Context, jobName string) error {
+	sis, err := serviceInstanceStorage()
+	if err != nil {
+		return err
+	}
+	return sis.RemoveJob(ctx, si.ServiceName, si.Name, jobName)
 }
 
-// BindApp makes the bind between the service instance and an app.
 func (si *ServiceInstance) BindApp(ctx context.Context, app *appTypes.App, params BindAppParameters, shouldRestart bool, writer io.Writer, evt *event.Event, requestID string) error {
 	args := bindAppPipelineArgs{
 		serviceInstance: si,
@@ -318,7 +334,11 @@ func (si *ServiceInstance) Grant(ctx context.Context, teamName string) error {
 	if err != nil {
 		return err
 	}
-	return si.updateData(ctx, mongoBSON.M{"$addToSet": mongoBSON.M{"teams": team.Name}})
+	sis, err := serviceInstanceStorage()
+	if err != nil {
+		return err
+	}
+	return sis.AddTeam(ctx, si.ServiceName, si.Name, team.Name)
 }
 
 func (si *ServiceInstance) Revoke(ctx context.Context, teamName string) error {
@@ -329,22 +349,21 @@ func (si *ServiceInstance) Revoke(ctx context.Context, teamName string) error {
 	if err != nil {
 		return err
 	}
-	return si.updateData(ctx, mongoBSON.M{"$pull": mongoBSON.M{"teams": team.Name}})
+	sis, err := serviceInstanceStorage()
+	if err != nil {
+		return err
+	}
+	return sis.RemoveTeam(ctx, si.ServiceName, si.Name, team.Name)
 }
 
-func genericServiceInstancesFilter(services interface{}, teams []string) mongoBSON.M {
-	query := mongoBSON.M{}
-	if len(teams) != 0 {
-		query["teams"] = mongoBSON.M{"$in": teams}
+func genericServiceInstancesFilter(services []Service, teams []string) serviceTypes.ServiceInstanceFilter {
+	filter := serviceTypes.ServiceInstanceFilter{
+		ServiceNames: getServicesNames(services),
 	}
-	if v, ok := services.([]Service); ok {
-		names := getServicesNames(v)
-		query["service_name"] = mongoBSON.M{"$in": names}
-	}
-	if v, ok := services.(Service); ok {
-		query["service_name"] = v.Name
+	if len(teams) != 0 {
+		filter.Teams = teams
 	}
-	return query
+	return filter
 }
 
 func validateServiceInstance(ctx context.Context, si ServiceInstance, s *Service) error {
@@ -363,18 +382,13 @@ func validateServiceInstanceName(ctx context.Context, service, instance string)
 	if !instanceNameRegexp.MatchString(instance) {
 		return ErrInvalidInstanceName
 	}
-	collection, err := storagev2.ServiceInstancesCollection()
-	if err != nil {
-		return nil
+	_, err := GetServiceInstance(ctx, service, instance)
+	if err == nil {
+		return ErrInstanceNameAlreadyExists
 	}
-	query := mongoBSON.M{"name": instance, "service_name": service}
-	length, err := collection.CountDocuments(ctx, query)
-	if err != nil {
+	if err != ErrServiceInstanceNotFound {
 		return err
 	}
-	if length > 0 {
-		return ErrInstanceNameAlreadyExists
-	}
 	return nil
 }
 
@@ -403,121 +417,67 @@ func CreateServiceInstance(ctx context.Context, instance ServiceInstance, servic
 }
 
 func GetServiceInstancesByServices(ctx context.Context, services []Service, tags []string) ([]ServiceInstance, error) {
-	var instances []ServiceInstance
-	collection, err := storagev2.ServiceInstancesCollection()
+	sis, err := serviceInstanceStorage()
 	if err != nil {
 		return nil, err
 	}
-	query := genericServiceInstancesFilter(services, []string{})
-	if len(tags) > 0 {
-		query["tags"] = mongoBSON.M{"$all": tags}
-	}
-	f := mongoBSON.M{"name": 1, "service_name": 1, "tags": 1}
-
-	opts := options.Find().SetProjection(f)
-	cursor, err := collection.Find(ctx, query, opts)
+	filter := genericServiceInstancesFilter(services, []string{})
+	filter.Tags = tags
+	dbInstances, err := sis.FindAll(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-
-	err = cursor.All(ctx, &instances)
-	if err != nil {
-		return nil, err
+	var instances []ServiceInstance
+	for _, si := range dbInstances {
+		instances = append(instances, ServiceInstance{Name: si.Name, ServiceName: si.ServiceName, Tags: si.Tags})
 	}
-	return instances, err
+	return instances, nil
 }
 
 func GetServicesInstancesByTeamsAndNames(ctx context.Context, teams []string, names []string, appName, serviceName string, tags []string) ([]ServiceInstance, error) {
-	filter := mongoBSON.M{}
-	if teams != nil || names != nil {
-		orConditions := []mongoBSON.M{}
-
-		if teams != nil {
-			orConditions = append(orConditions, mongoBSON.M{"teams": mongoBSON.M{"$in": teams}})
-		}
-
-		if names != nil {
-			orConditions = append(orConditions, mongoBSON.M{"name": mongoBSON.M{"$in": names}})
-		}
-
-		filter = mongoBSON.M{"$or": orConditions}
-	}
-	if appName != "" {
-		filter["apps"] = appName
+	filter := serviceTypes.ServiceInstanceFilter{
+		Teams:   teams,
+		Names:   names,
+		AppName: appName,
+		Tags:    tags,
 	}
 	if serviceName != "" {
-		filter["service_name"] = serviceName
-	}
-	if len(tags) > 0 {
-		filter["tags"] = mongoBSON.M{"$all": tags}
-	}
-	collection, err := storagev2.ServiceInstancesCollection()
-	if err != nil {
-		return nil, err
+		filter.ServiceNames = []string{serviceName}
 	}
-	var instances []ServiceInstance
-	cursor, err := collection.Find(ctx, filter)
-	if err != nil {
-		return nil, err
-	}
-	err = cursor.All(ctx, &instances)
-	if err != nil {
-		return nil, err
-	}
-	return instances, nil
+	return findServiceInstances(ctx, filter)
 }
 
 func GetServiceInstance(ctx context.Context, serviceName string, instanceName string) (*ServiceInstance, error) {
-	collection, err := storagev2.ServiceInstancesCollection()
+	sis, err := serviceInstanceStorage()
 	if err != nil {
 		return nil, err
 	}
-	var instance ServiceInstance
-	err = collection.FindOne(ctx, mongoBSON.M{"name": instanceName, "service_name": serviceName}).Decode(&instance)
+	si, err := sis.FindByName(ctx, serviceName, instanceName)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, ErrServiceInstanceNotFound
-		}
-
 		return nil, err
 	}
+	instance := ServiceInstance(*si)
 	return &instance, nil
 }
 
 func GetServiceInstancesBoundToApp(ctx context.Context, appName string) ([]ServiceInstance, error) {
-	collection, err := storagev2.ServiceInstancesCollection()
-	if err != nil {
-		return nil, err
-	}
-	var instances []ServiceInstance
-	q := mongoBSON.M{"apps": mongoBSON.M{"$in": []string{appName}}}
-	cursor, err := collection.Find(ctx, q)
-	if err != nil {
-		return nil, err
-	}
-	err = cursor.All(ctx, &instances)
-	if err != nil {
-		return nil, err
-	}
-	return instances, nil
+	return findServiceInstances(ctx, serviceTypes.ServiceInstanceFilter{AppName: appName})
 }
 
 func GetServiceInstancesBoundToJob(ctx context.Context, jobName string) ([]ServiceInstance, error) {
-	collection, err := storagev2.ServiceInstancesCollection()
-	if err != nil {
-		return nil, err
-	}
-	var instances []ServiceInstance
-	q := mongoBSON.M{"jobs": mongoBSON.M{"$in": []string{jobName}}}
-	cursor, err := collection.Find(ctx, q)
+	return findServiceInstances(ctx, serviceTypes.ServiceInstanceFilter{JobName: jobName})
+}
+
+func findServiceInstances(ctx context.Context, filter serviceTypes.ServiceInstanceFilter) ([]ServiceInstance, error) {
+	sis, err := serviceInstanceStorage()
 	if err != nil {
 		return nil, err
 	}
-	err = cursor.All(ctx, &instances)
+	instances, err := sis.FindAll(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-	return instances, nil
+	return toServiceInstances(instances), nil
 }
 
 func processTags(tags []string) []string {
@@ -537,27 +497,11 @@ func processTags(tags []string) []string {
 }
 
 func RenameServiceInstanceTeam(ctx context.Context, oldName, newName string) error {
-	collection, err := storagev2.ServiceInstancesCollection()
+	sis, err := serviceInstanceStorage()
 	if err != nil {
 		return err
 	}
-
-	updates := []mongo.WriteModel{
-		mongo.NewUpdateManyModel().
-			SetFilter(mongoBSON.M{"teamowner": oldName}).
-			SetUpdate(mongoBSON.M{"$set": mongoBSON.M{"teamowner": newName}}),
-
-		mongo.NewUpdateManyModel().
-			SetFilter(mongoBSON.M{"teams": oldName}).
-			SetUpdate(mongoBSON.M{"$addToSet": mongoBSON.M{"teams": newName}}),
-
-		mongo.NewUpdateManyModel().
-			SetFilter(mongoBSON.M{"teams": oldName}).
-			SetUpdate(mongoBSON.M{"$pull": mongoBSON.M{"teams": oldName}}),
-	}
-
-	_, err = collection.BulkWrite(ctx, updates)
-	return err
+	return sis.RenameTeam(ctx, oldName, newName)
 }
 
 // ProxyInstance is a proxy between tsuru and the service instance.
@@ -32,6 +32,7 @@ import (
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	bindTypes "github.com/tsuru/tsuru/types/bind"
 	provTypes "github.com/tsuru/tsuru/types/provision"
+	serviceTypes "github.com/tsuru/tsuru/types/service"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	check "gopkg.in/check.v1"
@@ -583,8 +584,8 @@ func (s *InstanceSuite) TestGetServiceInstancesByServicesFilteringByTags(c *chec
 func (s *InstanceSuite) TestGenericServiceInstancesFilter(c *check.C) {
 	srvc := Service{Name: "mysql"}
 	teams := []string{s.team.Name}
-	query := genericServiceInstancesFilter(srvc, teams)
-	c.Assert(query, check.DeepEquals, mongoBSON.M{"service_name": srvc.Name, "teams": mongoBSON.M{"$in": teams}})
+	filter := genericServiceInstancesFilter([]Service{srvc}, teams)
+	c.Assert(filter, check.DeepEquals, serviceTypes.ServiceInstanceFilter{ServiceNames: []string{srvc.Name}, Teams: teams})
 }
 
 func (s *InstanceSuite) TestGenericServiceInstancesFilterWithServiceSlice(c *check.C) {
@@ -594,8 +595,8 @@ func (s *InstanceSuite) TestGenericServiceInstancesFilterWithServiceSlice(c *che
 	}
 	names := []string{"mysql", "mongodb"}
 	teams := []string{s.team.Name}
-	query := genericServiceInstancesFilter(services, teams)
-	c.Assert(query, check.DeepEquals, mongoBSON.M{"service_name": mongoBSON.M{"$in": names}, "teams": mongoBSON.M{"$in": teams}})
+	filter := genericServiceInstancesFilter(services, teams)
+	c.Assert(filter, check.DeepEquals, serviceTypes.ServiceInstanceFilter{ServiceNames: names, Teams: teams})
 }
 
 func (s *InstanceSuite) TestGenericServiceInstancesFilterWithoutSpecifingTeams(c *check.C) {
@@ -605,8 +606,8 @@ func (s *InstanceSuite) TestGenericServiceInstancesFilterWithoutSpecifingTeams(c
 	}
 	names := []string{"mysql", "mongodb"}
 	teams := []string{}
-	query := genericServiceInstancesFilter(services, teams)
-	c.Assert(query, check.DeepEquals, mongoBSON.M{"service_name": mongoBSON.M{"$in": names}})
+	filter := genericServiceInstancesFilter(services, teams)
+	c.Assert(filter, check.DeepEquals, serviceTypes.ServiceInstanceFilter{ServiceNames: names})
 }
 
 func (s *InstanceSuite) TestAdditionalInfo(c *check.C) {
@@ -27,7 +27,9 @@ var (
 	PlatformImage   image.PlatformImageService
 	Team            auth.TeamService
 	TeamToken       auth.TeamTokenService
+	PersonalToken   auth.PersonalTokenService
 	Job             job.JobService
+	JobWorkflow     job.WorkflowService
 	Webhook         event.WebhookService
 	AppQuota        quota.QuotaService[*app.App]
 	UserQuota       quota.LegacyQuotaService
@@ -14,9 +14,12 @@ import (
 	"github.com/tsuru/tsuru/types/auth"
 	"github.com/tsuru/tsuru/types/cache"
 	"github.com/tsuru/tsuru/types/event"
+	"github.com/tsuru/tsuru/types/job"
+	"github.com/tsuru/tsuru/types/permission"
 	"github.com/tsuru/tsuru/types/provision"
 	"github.com/tsuru/tsuru/types/quota"
 	"github.com/tsuru/tsuru/types/router"
+	"github.com/tsuru/tsuru/types/service"
 	"github.com/tsuru/tsuru/types/tracker"
 	"github.com/tsuru/tsuru/types/volume"
 )
@@ -27,10 +30,12 @@ type DbDriver struct {
 	PlanStorage            app.PlanStorage
 	AppCacheStorage        cache.CacheStorage
 	TeamTokenStorage       auth.TeamTokenStorage
+	PersonalTokenStorage   auth.PersonalTokenStorage
 	UserQuotaStorage       quota.QuotaStorage
 	AppQuotaStorage        quota.QuotaStorage
 	TeamQuotaStorage       quota.QuotaStorage
 	WebhookStorage         event.WebhookStorage
+	WebhookDeliveryStorage event.WebhookDeliveryStorage
 	ClusterStorage         provision.ClusterStorage
 	PlatformImageStorage   image.PlatformImageStorage
 	InstanceTrackerStorage tracker.InstanceStorage
@@ -39,6 +44,14 @@ type DbDriver struct {
 	AuthGroupStorage       auth.GroupStorage
 	PoolStorage            provision.PoolStorage
 	VolumeStorage          volume.VolumeStorage
+	AppStorage             app.AppStorage
+	EventStorage           event.EventStorage
+	RoleStorage            permission.RoleStorage
+	ServiceStorage         service.ServiceStorage
+	ServiceInstanceStorage service.ServiceInstanceStorage
+	JobRunStorage          job.RunStorage
+	WorkflowStorage        job.WorkflowStorage
+	WorkflowRunStorage     job.WorkflowRunStorage
 }
 
 var (
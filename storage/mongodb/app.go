@@ -0,0 +1,294 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/app"
+	"github.com/tsuru/tsuru/types/bind"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type appStorage struct{}
+
+var _ app.AppStorage = &appStorage{}
+
+func (s *appStorage) Insert(ctx context.Context, a *app.App) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, a)
+	if mongo.IsDuplicateKeyError(err) {
+		return app.ErrAppAlreadyExists
+	}
+	return err
+}
+
+func (s *appStorage) Update(ctx context.Context, a *app.App) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"name": a.Name}, a)
+	return err
+}
+
+func (s *appStorage) FindByName(ctx context.Context, name string) (*app.App, error) {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var a app.App
+	err = collection.FindOne(ctx, mongoBSON.M{"name": name}).Decode(&a)
+	if err == mongo.ErrNoDocuments {
+		return nil, app.ErrAppNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func appFilterQuery(f *app.Filter) mongoBSON.M {
+	query := mongoBSON.M{}
+	if f == nil {
+		return query
+	}
+	if f.Extra != nil {
+		var orBlock []mongoBSON.M
+		for field, values := range f.Extra {
+			orBlock = append(orBlock, mongoBSON.M{
+				field: mongoBSON.M{"$in": values},
+			})
+		}
+		query["$or"] = orBlock
+	}
+	if f.NameMatches != "" {
+		query["name"] = mongoBSON.M{"$regex": f.NameMatches}
+	}
+	if f.Name != "" {
+		query["name"] = f.Name
+	}
+	if f.TeamOwner != "" {
+		query["teamowner"] = f.TeamOwner
+	}
+	if f.Platform != "" {
+		parts := strings.SplitN(f.Platform, ":", 2)
+		query["framework"] = parts[0]
+		if len(parts) == 2 {
+			v := parts[1]
+			if v == "latest" {
+				query["$and"] = []mongoBSON.M{
+					{"$or": []mongoBSON.M{
+						{"platformversion": mongoBSON.M{"$in": []string{"latest", ""}}},
+						{"platformversion": mongoBSON.M{"$exists": false}},
+					}},
+				}
+			} else {
+				query["platformversion"] = v
+			}
+		}
+	}
+	if f.UserOwner != "" {
+		query["owner"] = f.UserOwner
+	}
+	if f.Pool != "" {
+		query["pool"] = f.Pool
+	}
+	if len(f.Pools) > 0 {
+		query["pool"] = mongoBSON.M{"$in": f.Pools}
+	}
+	if len(f.Tags) > 0 {
+		query["tags"] = mongoBSON.M{"$all": f.Tags}
+	}
+	return query
+}
+
+func (s *appStorage) List(ctx context.Context, f *app.Filter) ([]app.App, error) {
+	return s.find(ctx, appFilterQuery(f))
+}
+
+func (s *appStorage) FindByCName(ctx context.Context, cname string) ([]app.App, error) {
+	return s.find(ctx, mongoBSON.M{"cname": cname})
+}
+
+func (s *appStorage) find(ctx context.Context, query mongoBSON.M) ([]app.App, error) {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	apps := []app.App{}
+	err = cursor.All(ctx, &apps)
+	if err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+func (s *appStorage) FindNamesByTeam(ctx context.Context, team string) ([]string, error) {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return nil, err
+	}
+	result, err := collection.Distinct(ctx, "name", mongoBSON.M{"teams": team})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range result {
+		if nameStr, ok := name.(string); ok {
+			names = append(names, nameStr)
+		}
+	}
+	return names, nil
+}
+
+func (s *appStorage) AddTeam(ctx context.Context, appName, team string) error {
+	return s.update(ctx, appName, mongoBSON.M{"$addToSet": mongoBSON.M{"teams": team}})
+}
+
+func (s *appStorage) RemoveTeam(ctx context.Context, appName, team string) error {
+	return s.update(ctx, appName, mongoBSON.M{"$pull": mongoBSON.M{"teams": team}})
+}
+
+func (s *appStorage) RenameTeam(ctx context.Context, oldName, newName string) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.BulkWrite(ctx, []mongo.WriteModel{
+		mongo.NewUpdateManyModel().
+			SetFilter(mongoBSON.M{"teamowner": oldName}).
+			SetUpdate(mongoBSON.M{"$set": mongoBSON.M{"teamowner": newName}}),
+		mongo.NewUpdateManyModel().
+			SetFilter(mongoBSON.M{"teams": oldName}).
+			SetUpdate(mongoBSON.M{"$addToSet": mongoBSON.M{"teams": newName}}),
+		mongo.NewUpdateManyModel().
+			SetFilter(mongoBSON.M{"teams": oldName}).
+			SetUpdate(mongoBSON.M{"$pull": mongoBSON.M{"teams": oldName}}),
+	})
+	return err
+}
+
+func (s *appStorage) AddCNames(ctx context.Context, appName string, cnames []string) error {
+	return s.update(ctx, appName, mongoBSON.M{"$addToSet": mongoBSON.M{"cname": mongoBSON.M{"$each": cnames}}})
+}
+
+func (s *appStorage) RemoveCNames(ctx context.Context, appName string, cnames []string) error {
+	return s.update(ctx, appName, mongoBSON.M{"$pull": mongoBSON.M{"cname": mongoBSON.M{"$in": cnames}}})
+}
+
+// certIssuerField returns the field of the cname in the cert issuers, whose
+// keys are stored with their dots replaced.
+func certIssuerField(cname string) string {
+	return "certissuers." + strings.ReplaceAll(cname, ".", app.CertIssuerDotReplacement)
+}
+
+func (s *appStorage) SetCertIssuer(ctx context.Context, appName, cname, issuer string) error {
+	return s.update(ctx, appName, mongoBSON.M{"$set": mongoBSON.M{certIssuerField(cname): issuer}})
+}
+
+func (s *appStorage) RemoveCertIssuer(ctx context.Context, appName, cname string) error {
+	return s.update(ctx, appName, mongoBSON.M{"$unset": mongoBSON.M{certIssuerField(cname): ""}})
+}
+
+func (s *appStorage) SetRouters(ctx context.Context, appName string, routers []app.AppRouter) error {
+	return s.update(ctx, appName, mongoBSON.M{"$set": mongoBSON.M{
+		"routers":    routers,
+		"router":     "",
+		"routeropts": nil,
+	}})
+}
+
+func (s *appStorage) SetRoutingWeights(ctx context.Context, appName string, weights map[int]int) error {
+	if len(weights) == 0 {
+		return s.update(ctx, appName, mongoBSON.M{"$unset": mongoBSON.M{"routingweights": ""}})
+	}
+	return s.update(ctx, appName, mongoBSON.M{"$set": mongoBSON.M{"routingweights": weights}})
+}
+
+func (s *appStorage) SetAutoRollback(ctx context.Context, appName string, cfg *app.AutoRollback) error {
+	if cfg == nil {
+		return s.update(ctx, appName, mongoBSON.M{"$unset": mongoBSON.M{"autorollback": ""}})
+	}
+	return s.update(ctx, appName, mongoBSON.M{"$set": mongoBSON.M{"autorollback": cfg}})
+}
+
+func (s *appStorage) SetEnvs(ctx context.Context, appName string, envs map[string]bind.EnvVar) error {
+	return s.update(ctx, appName, mongoBSON.M{"$set": mongoBSON.M{"env": envs}})
+}
+
+func (s *appStorage) SetServiceEnvs(ctx context.Context, appName string, envs []bind.ServiceEnvVar) error {
+	return s.update(ctx, appName, mongoBSON.M{"$set": mongoBSON.M{"serviceenvs": envs}})
+}
+
+func (s *appStorage) SetUpdatePlatform(ctx context.Context, appName string, updatePlatform bool) error {
+	return s.update(ctx, appName, mongoBSON.M{"$set": mongoBSON.M{"updateplatform": updatePlatform}})
+}
+
+func (s *appStorage) IncrementDeploys(ctx context.Context, appName string) error {
+	return s.update(ctx, appName, mongoBSON.M{"$inc": mongoBSON.M{"deploys": 1}})
+}
+
+func (s *appStorage) ReencryptEnvs(ctx context.Context) (int, error) {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return 0, err
+	}
+	return storagev2.ReencryptEnvs(ctx, collection, "app", []string{"env", "serviceenvs"}, func(raw mongoBSON.Raw) (mongoBSON.M, error) {
+		var a struct {
+			Env         map[string]bind.EnvVar
+			ServiceEnvs []bind.ServiceEnvVar
+		}
+		err := mongoBSON.Unmarshal(raw, &a)
+		if err != nil {
+			return nil, err
+		}
+		envs := make([]bind.EnvVar, 0, len(a.Env))
+		for _, env := range a.Env {
+			envs = append(envs, env)
+		}
+		if !storagev2.HasPrivateEnvs(envs, a.ServiceEnvs) {
+			return nil, nil
+		}
+		return mongoBSON.M{
+			"env":         a.Env,
+			"serviceenvs": a.ServiceEnvs,
+		}, nil
+	})
+}
+
+func (s *appStorage) update(ctx context.Context, appName string, update mongoBSON.M) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": appName}, update)
+	return err
+}
+
+func (s *appStorage) Delete(ctx context.Context, name string) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"name": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return app.ErrAppNotFound
+	}
+	return nil
+}
@@ -53,6 +53,29 @@ func (s *appVersionStorage) UpdateVersionSuccess(ctx context.Context, appName st
 	}, opts...)
 }
 
+func (s *appVersionStorage) UpdateBuildPhases(ctx context.Context, appName string, version int, phases []appTypes.BuildPhase, opts ...*appTypes.AppVersionWriteOptions) error {
+	now := time.Now().UTC()
+	uuidV4, err := uuid.NewRandom()
+	if err != nil {
+		return errors.WithMessage(err, "failed to generate uuid v4")
+	}
+	where := mongoBSON.M{
+		"appname":                           appName,
+		fmt.Sprintf("versions.%d", version): mongoBSON.M{"$exists": true},
+	}
+	if len(opts) > 0 && opts[0].PreviousUpdatedHash != "" {
+		where["updatedhash"] = opts[0].PreviousUpdatedHash
+	}
+	return s.baseUpdateWhere(ctx, where, mongoBSON.M{
+		"$set": mongoBSON.M{
+			fmt.Sprintf("versions.%d.buildphases", version): phases,
+			fmt.Sprintf("versions.%d.updatedat", version):   now,
+			"updatedat":   now,
+			"updatedhash": uuidV4.String(),
+		},
+	})
+}
+
 func (s *appVersionStorage) baseUpdate(ctx context.Context, appName string, updateQuery mongoBSON.M, opts ...*appTypes.AppVersionWriteOptions) error {
 	where := mongoBSON.M{"appname": appName}
 
@@ -84,3 +84,80 @@ func roleToBson(ri auth.RoleInstance) mongoBSON.D {
 		{Key: "contextvalue", Value: ri.ContextValue},
 	})
 }
+
+func (s *authGroupStorage) ListByMember(ctx context.Context, email string) ([]auth.Group, error) {
+	collection, err := storagev2.AuthGroupsCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"members": email})
+	if err != nil {
+		return nil, err
+	}
+	var groups []auth.Group
+	err = cursor.All(ctx, &groups)
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (s *authGroupStorage) SetMembers(ctx context.Context, name string, members []string) error {
+	if name == "" {
+		return errAuthGroupNameEmpty
+	}
+	if members == nil {
+		members = []string{}
+	}
+	collection, err := storagev2.AuthGroupsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": name}, mongoBSON.M{
+		"$set": mongoBSON.M{"members": members},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *authGroupStorage) AddMembers(ctx context.Context, name string, members []string) error {
+	if name == "" {
+		return errAuthGroupNameEmpty
+	}
+	collection, err := storagev2.AuthGroupsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": name}, mongoBSON.M{
+		"$addToSet": mongoBSON.M{"members": mongoBSON.M{"$each": members}},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *authGroupStorage) RemoveMembers(ctx context.Context, name string, members []string) error {
+	if name == "" {
+		return errAuthGroupNameEmpty
+	}
+	collection, err := storagev2.AuthGroupsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": name}, mongoBSON.M{
+		"$pullAll": mongoBSON.M{"members": members},
+	})
+	return err
+}
+
+func (s *authGroupStorage) Delete(ctx context.Context, name string) error {
+	collection, err := storagev2.AuthGroupsCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"name": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return auth.ErrGroupNotFound
+	}
+	return nil
+}
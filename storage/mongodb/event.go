@@ -0,0 +1,79 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type eventStorage struct{}
+
+var _ event.EventStorage = &eventStorage{}
+
+func (s *eventStorage) InsertBlock(ctx context.Context, b event.Block) error {
+	collection, err := storagev2.EventBlocksCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, b)
+	return err
+}
+
+func (s *eventStorage) FindBlocks(ctx context.Context, active *bool) ([]event.Block, error) {
+	collection, err := storagev2.EventBlocksCollection()
+	if err != nil {
+		return nil, err
+	}
+	query := mongoBSON.M{}
+	if active != nil {
+		query["active"] = *active
+	}
+	cursor, err := collection.Find(ctx, query, options.Find().SetSort(mongoBSON.M{"starttime": -1}))
+	if err != nil {
+		return nil, err
+	}
+	var blocks []event.Block
+	err = cursor.All(ctx, &blocks)
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (s *eventStorage) DeactivateBlock(ctx context.Context, id primitive.ObjectID, endTime time.Time) error {
+	collection, err := storagev2.EventBlocksCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": id, "active": true}, mongoBSON.M{
+		"$set": mongoBSON.M{"active": false, "endtime": endTime},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return event.ErrActiveBlockNotFound
+	}
+	return nil
+}
+
+func (s *eventStorage) DeactivateExpiredBlocks(ctx context.Context, now time.Time) error {
+	collection, err := storagev2.EventBlocksCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateMany(ctx, mongoBSON.M{
+		"active":  true,
+		"endtime": mongoBSON.M{"$lte": now},
+	}, mongoBSON.M{"$set": mongoBSON.M{"active": false}})
+	return err
+}
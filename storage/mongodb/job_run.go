@@ -0,0 +1,169 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type jobRunStorage struct{}
+
+var _ jobTypes.RunStorage = &jobRunStorage{}
+
+type jobRun struct {
+	ID          string `bson:"_id"`
+	JobName     string
+	Cluster     string
+	Trigger     jobTypes.RunTrigger
+	TriggeredBy string
+	Status      jobTypes.RunStatus
+	Reason      string
+	ExitCode    *int32 `bson:",omitempty"`
+	Retries     int
+	StartTime   time.Time
+	EndTime     time.Time
+	Units       []string              `bson:",omitempty"`
+	Log         *jobTypes.RunLog      `bson:",omitempty"`
+	Workflow    *jobTypes.WorkflowRef `bson:",omitempty"`
+}
+
+func runFilterQuery(f jobTypes.RunFilter) mongoBSON.M {
+	query := mongoBSON.M{}
+	if f.JobName != "" {
+		query["jobname"] = f.JobName
+	}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	return query
+}
+
+func (s *jobRunStorage) Upsert(ctx context.Context, r jobTypes.Run) error {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanUpsertID, collection.Name())
+	span.SetMongoID(r.ID)
+	defer span.Finish()
+
+	opts := options.Replace().SetUpsert(true)
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": r.ID, "jobname": r.JobName}, jobRun(r), opts)
+	span.SetError(err)
+	return err
+}
+
+func (s *jobRunStorage) FindByID(ctx context.Context, jobName, id string) (*jobTypes.Run, error) {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFindID, collection.Name())
+	span.SetMongoID(id)
+	defer span.Finish()
+
+	var r jobRun
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id, "jobname": jobName}).Decode(&r)
+	if err == mongo.ErrNoDocuments {
+		return nil, jobTypes.ErrJobRunNotFound
+	}
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := jobTypes.Run(r)
+	return &result, nil
+}
+
+func (s *jobRunStorage) Find(ctx context.Context, f jobTypes.RunFilter) ([]jobTypes.Run, error) {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFind, collection.Name())
+	defer span.Finish()
+
+	opts := options.Find().SetSort(mongoBSON.D{{Key: "starttime", Value: -1}})
+	if f.Limit > 0 {
+		opts.SetLimit(int64(f.Limit))
+	}
+	cursor, err := collection.Find(ctx, runFilterQuery(f), opts)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	var runs []jobRun
+	err = cursor.All(ctx, &runs)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := make([]jobTypes.Run, len(runs))
+	for i, r := range runs {
+		result[i] = jobTypes.Run(r)
+	}
+	return result, nil
+}
+
+func (s *jobRunStorage) Prune(ctx context.Context, jobName string, keep int) error {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	query := mongoBSON.M{
+		"jobname": jobName,
+		"status":  mongoBSON.M{"$in": []jobTypes.RunStatus{jobTypes.RunStatusSucceeded, jobTypes.RunStatusFailed}},
+	}
+	opts := options.Find().
+		SetSort(mongoBSON.D{{Key: "starttime", Value: -1}}).
+		SetSkip(int64(keep)).
+		SetProjection(mongoBSON.M{"_id": 1})
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	var old []struct {
+		ID string `bson:"_id"`
+	}
+	err = cursor.All(ctx, &old)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if len(old) == 0 {
+		return nil
+	}
+	ids := make([]string, len(old))
+	for i := range old {
+		ids[i] = old[i].ID
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"_id": mongoBSON.M{"$in": ids}})
+	span.SetError(err)
+	return err
+}
+
+func (s *jobRunStorage) DeleteByJob(ctx context.Context, jobName string) error {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"jobname": jobName})
+	span.SetError(err)
+	return err
+}
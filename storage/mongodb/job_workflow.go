@@ -0,0 +1,150 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type workflowStorage struct{}
+
+var _ jobTypes.WorkflowStorage = &workflowStorage{}
+
+func (s *workflowStorage) Insert(ctx context.Context, w jobTypes.Workflow) error {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanInsert, collection.Name())
+	defer span.Finish()
+
+	_, err = collection.InsertOne(ctx, w)
+	if mongo.IsDuplicateKeyError(err) {
+		return jobTypes.ErrWorkflowAlreadyExists
+	}
+	span.SetError(err)
+	return err
+}
+
+func (s *workflowStorage) Update(ctx context.Context, w jobTypes.Workflow) error {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, collection.Name())
+	defer span.Finish()
+
+	result, err := collection.ReplaceOne(ctx, mongoBSON.M{"name": w.Name}, w)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return jobTypes.ErrWorkflowNotFound
+	}
+	return nil
+}
+
+func (s *workflowStorage) findQuery(ctx context.Context, query mongoBSON.M) ([]jobTypes.Workflow, error) {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFind, collection.Name())
+	defer span.Finish()
+
+	cursor, err := collection.Find(ctx, query, options.Find().SetSort(mongoBSON.M{"name": 1}))
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	var workflows []jobTypes.Workflow
+	err = cursor.All(ctx, &workflows)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	return workflows, nil
+}
+
+func (s *workflowStorage) FindAllByTeams(ctx context.Context, teams []string) ([]jobTypes.Workflow, error) {
+	query := mongoBSON.M{}
+	if teams != nil {
+		query["teamowner"] = mongoBSON.M{"$in": teams}
+	}
+	return s.findQuery(ctx, query)
+}
+
+func (s *workflowStorage) FindByName(ctx context.Context, name string) (*jobTypes.Workflow, error) {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFindOne, collection.Name())
+	defer span.Finish()
+
+	var w jobTypes.Workflow
+	err = collection.FindOne(ctx, mongoBSON.M{"name": name}).Decode(&w)
+	if err == mongo.ErrNoDocuments {
+		return nil, jobTypes.ErrWorkflowNotFound
+	}
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (s *workflowStorage) FindScheduled(ctx context.Context) ([]jobTypes.Workflow, error) {
+	return s.findQuery(ctx, mongoBSON.M{"schedule": mongoBSON.M{"$nin": []interface{}{"", nil}}})
+}
+
+func (s *workflowStorage) UpdateLastSchedule(ctx context.Context, name string, previous, last time.Time) error {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, collection.Name())
+	defer span.Finish()
+
+	result, err := collection.UpdateOne(ctx,
+		mongoBSON.M{"name": name, "lastscheduletime": previous},
+		mongoBSON.M{"$set": mongoBSON.M{"lastscheduletime": last}},
+	)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return jobTypes.ErrWorkflowScheduleConflict
+	}
+	return nil
+}
+
+func (s *workflowStorage) Delete(ctx context.Context, name string) error {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"name": name})
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return jobTypes.ErrWorkflowNotFound
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type workflowRunStorage struct{}
+
+var _ jobTypes.WorkflowRunStorage = &workflowRunStorage{}
+
+type workflowRun struct {
+	ID           string `bson:"_id"`
+	WorkflowName string
+	Trigger      jobTypes.RunTrigger
+	TriggeredBy  string
+	Status       jobTypes.RunStatus
+	Envs         []bindTypes.EnvVar
+	StartTime    time.Time
+	EndTime      time.Time
+	Steps        []jobTypes.WorkflowStepRun
+	Version      int
+}
+
+func workflowRunFilterQuery(f jobTypes.WorkflowRunFilter) mongoBSON.M {
+	query := mongoBSON.M{}
+	if f.WorkflowName != "" {
+		query["workflowname"] = f.WorkflowName
+	}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	return query
+}
+
+func (s *workflowRunStorage) Insert(ctx context.Context, r jobTypes.WorkflowRun) error {
+	collection, err := storagev2.JobWorkflowRunsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanInsert, collection.Name())
+	span.SetMongoID(r.ID)
+	defer span.Finish()
+
+	_, err = collection.InsertOne(ctx, workflowRun(r))
+	span.SetError(err)
+	return err
+}
+
+func (s *workflowRunStorage) Update(ctx context.Context, r jobTypes.WorkflowRun) error {
+	collection, err := storagev2.JobWorkflowRunsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanUpdateID, collection.Name())
+	span.SetMongoID(r.ID)
+	defer span.Finish()
+
+	query := mongoBSON.M{"_id": r.ID, "version": r.Version}
+	r.Version++
+	result, err := collection.ReplaceOne(ctx, query, workflowRun(r))
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return jobTypes.ErrWorkflowRunConflict
+	}
+	return nil
+}
+
+func (s *workflowRunStorage) FindByID(ctx context.Context, workflowName, id string) (*jobTypes.WorkflowRun, error) {
+	collection, err := storagev2.JobWorkflowRunsCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFindID, collection.Name())
+	span.SetMongoID(id)
+	defer span.Finish()
+
+	var r workflowRun
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id, "workflowname": workflowName}).Decode(&r)
+	if err == mongo.ErrNoDocuments {
+		return nil, jobTypes.ErrWorkflowRunNotFound
+	}
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := jobTypes.WorkflowRun(r)
+	return &result, nil
+}
+
+func (s *workflowRunStorage) Find(ctx context.Context, f jobTypes.WorkflowRunFilter) ([]jobTypes.WorkflowRun, error) {
+	collection, err := storagev2.JobWorkflowRunsCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFind, collection.Name())
+	defer span.Finish()
+
+	opts := options.Find().SetSort(mongoBSON.D{{Key: "starttime", Value: -1}})
+	if f.Limit > 0 {
+		opts.SetLimit(int64(f.Limit))
+	}
+	cursor, err := collection.Find(ctx, workflowRunFilterQuery(f), opts)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	var runs []workflowRun
+	err = cursor.All(ctx, &runs)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := make([]jobTypes.WorkflowRun, len(runs))
+	for i, r := range runs {
+		result[i] = jobTypes.WorkflowRun(r)
+	}
+	return result, nil
+}
+
+func (s *workflowRunStorage) Prune(ctx context.Context, workflowName string, keep int) error {
+	collection, err := storagev2.JobWorkflowRunsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	query := mongoBSON.M{
+		"workflowname": workflowName,
+		"status":       mongoBSON.M{"$in": []jobTypes.RunStatus{jobTypes.RunStatusSucceeded, jobTypes.RunStatusFailed}},
+	}
+	opts := options.Find().
+		SetSort(mongoBSON.D{{Key: "starttime", Value: -1}}).
+		SetSkip(int64(keep)).
+		SetProjection(mongoBSON.M{"_id": 1})
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	var old []struct {
+		ID string `bson:"_id"`
+	}
+	err = cursor.All(ctx, &old)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if len(old) == 0 {
+		return nil
+	}
+	ids := make([]string, len(old))
+	for i := range old {
+		ids[i] = old[i].ID
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"_id": mongoBSON.M{"$in": ids}})
+	span.SetError(err)
+	return err
+}
+
+func (s *workflowRunStorage) DeleteByWorkflow(ctx context.Context, workflowName string) error {
+	collection, err := storagev2.JobWorkflowRunsCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"workflowname": workflowName})
+	span.SetError(err)
+	return err
+}
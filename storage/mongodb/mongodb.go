@@ -16,10 +16,12 @@ func init() {
 		PlanStorage:            &PlanStorage{},
 		AppCacheStorage:        appCacheStorage(),
 		TeamTokenStorage:       &teamTokenStorage{},
+		PersonalTokenStorage:   &personalTokenStorage{},
 		UserQuotaStorage:       authQuotaStorage(),
 		AppQuotaStorage:        appQuotaStorage(),
 		TeamQuotaStorage:       teamQuotaStorage(),
 		WebhookStorage:         &webhookStorage{},
+		WebhookDeliveryStorage: &webhookDeliveryStorage{},
 		ClusterStorage:         &clusterStorage{},
 		InstanceTrackerStorage: &instanceTrackerStorage{},
 		AppVersionStorage:      &appVersionStorage{},
@@ -27,6 +29,14 @@ func init() {
 		AuthGroupStorage:       &authGroupStorage{},
 		PoolStorage:            &PoolStorage{},
 		VolumeStorage:          &volumeStorage{},
+		AppStorage:             &appStorage{},
+		EventStorage:           &eventStorage{},
+		RoleStorage:            &roleStorage{},
+		ServiceStorage:         &serviceStorage{},
+		ServiceInstanceStorage: &serviceInstanceStorage{},
+		JobRunStorage:          &jobRunStorage{},
+		WorkflowStorage:        &workflowStorage{},
+		WorkflowRunStorage:     &workflowRunStorage{},
 	}
 	storage.RegisterDbDriver("mongodb", mongodbDriver)
 }
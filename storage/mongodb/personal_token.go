@@ -0,0 +1,168 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/auth"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type personalTokenStorage struct{}
+
+type personalToken struct {
+	Token              string
+	TokenID            string `bson:"token_id"`
+	Name               string
+	Description        string
+	UserEmail          string    `bson:"user_email"`
+	CreatedAt          time.Time `bson:"created_at"`
+	ExpiresAt          time.Time `bson:"expires_at,omitempty"`
+	LastAccess         time.Time `bson:"last_access,omitempty"`
+	AllowedPermissions []string  `bson:"allowed_permissions,omitempty"`
+	ExpiryNotified     bool      `bson:"expiry_notified,omitempty"`
+}
+
+var _ auth.PersonalTokenStorage = &personalTokenStorage{}
+
+func (s *personalTokenStorage) Insert(ctx context.Context, t auth.PersonalToken) error {
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanInsert, collection.Name())
+	defer span.Finish()
+
+	_, err = collection.InsertOne(ctx, personalToken(t))
+	if mongo.IsDuplicateKeyError(err) {
+		err = auth.ErrPersonalTokenAlreadyExists
+	}
+	span.SetError(err)
+	return err
+}
+
+func (s *personalTokenStorage) findOne(ctx context.Context, query mongoBSON.M) (*auth.PersonalToken, error) {
+	results, err := s.findByQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, auth.ErrPersonalTokenNotFound
+	}
+	return &results[0], nil
+}
+
+func (s *personalTokenStorage) FindByToken(ctx context.Context, token string) (*auth.PersonalToken, error) {
+	return s.findOne(ctx, mongoBSON.M{"token": token})
+}
+
+func (s *personalTokenStorage) FindByTokenID(ctx context.Context, tokenID string) (*auth.PersonalToken, error) {
+	return s.findOne(ctx, mongoBSON.M{"token_id": tokenID})
+}
+
+func (s *personalTokenStorage) FindByUser(ctx context.Context, email string) ([]auth.PersonalToken, error) {
+	return s.findByQuery(ctx, mongoBSON.M{"user_email": email})
+}
+
+func (s *personalTokenStorage) FindExpiring(ctx context.Context, before time.Time) ([]auth.PersonalToken, error) {
+	return s.findByQuery(ctx, mongoBSON.M{
+		"expires_at":      mongoBSON.M{"$lte": before},
+		"expiry_notified": mongoBSON.M{"$ne": true},
+	})
+}
+
+func (s *personalTokenStorage) findByQuery(ctx context.Context, query mongoBSON.M) ([]auth.PersonalToken, error) {
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFind, collection.Name())
+	defer span.Finish()
+
+	cursor, err := collection.Find(ctx, query, options.Find().SetSort(mongoBSON.M{"created_at": 1}))
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	var tokens []personalToken
+	err = cursor.All(ctx, &tokens)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := make([]auth.PersonalToken, len(tokens))
+	for i, t := range tokens {
+		result[i] = auth.PersonalToken(t)
+	}
+	return result, nil
+}
+
+func (s *personalTokenStorage) updateOne(ctx context.Context, query, update mongoBSON.M) error {
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, collection.Name())
+	defer span.Finish()
+
+	result, err := collection.UpdateOne(ctx, query, update)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return auth.ErrPersonalTokenNotFound
+	}
+	return nil
+}
+
+func (s *personalTokenStorage) UpdateLastAccess(ctx context.Context, token string) error {
+	return s.updateOne(ctx, mongoBSON.M{"token": token}, mongoBSON.M{
+		"$set": mongoBSON.M{"last_access": time.Now().UTC()},
+	})
+}
+
+func (s *personalTokenStorage) SetExpiryNotified(ctx context.Context, tokenID string) error {
+	return s.updateOne(ctx, mongoBSON.M{"token_id": tokenID}, mongoBSON.M{
+		"$set": mongoBSON.M{"expiry_notified": true},
+	})
+}
+
+func (s *personalTokenStorage) Delete(ctx context.Context, tokenID string) error {
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"token_id": tokenID})
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return auth.ErrPersonalTokenNotFound
+	}
+	return nil
+}
+
+func (s *personalTokenStorage) DeleteByUser(ctx context.Context, email string) error {
+	collection, err := storagev2.PersonalTokensCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"user_email": email})
+	span.SetError(err)
+	return err
+}
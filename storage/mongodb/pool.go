@@ -17,6 +17,69 @@ var _ provision.PoolStorage = &PoolStorage{}
 
 type PoolStorage struct{}
 
+func (ps *PoolStorage) Insert(ctx context.Context, p provision.Pool) error {
+	span := newMongoDBSpan(ctx, mongoSpanInsert, "pool")
+	defer span.Finish()
+
+	collection, err := storagev2.PoolCollection()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	_, err = collection.InsertOne(ctx, p)
+	if mongo.IsDuplicateKeyError(err) {
+		return provision.ErrPoolAlreadyExists
+	}
+	if err != nil {
+		span.SetError(err)
+	}
+	return err
+}
+
+func (ps *PoolStorage) Update(ctx context.Context, p provision.Pool) error {
+	query := mongoBSON.M{"_id": p.Name}
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, "pool")
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	collection, err := storagev2.PoolCollection()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	result, err := collection.ReplaceOne(ctx, query, p)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return provision.ErrPoolNotFound
+	}
+	return nil
+}
+
+func (ps *PoolStorage) Delete(ctx context.Context, name string) error {
+	query := mongoBSON.M{"_id": name}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, "pool")
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	collection, err := storagev2.PoolCollection()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, query)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return provision.ErrPoolNotFound
+	}
+	return nil
+}
+
 func (ps *PoolStorage) FindAll(ctx context.Context) ([]provision.Pool, error) {
 	return findPoolsByQuery(ctx, mongoBSON.M{})
 }
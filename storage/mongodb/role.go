@@ -0,0 +1,140 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type roleStorage struct{}
+
+var _ permission.RoleStorage = &roleStorage{}
+
+func (s *roleStorage) Insert(ctx context.Context, role permission.Role) error {
+	collection, err := storagev2.RolesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, role)
+	if mongo.IsDuplicateKeyError(err) {
+		return permission.ErrRoleAlreadyExists
+	}
+	return err
+}
+
+func (s *roleStorage) findQuery(ctx context.Context, query mongoBSON.M) ([]permission.Role, error) {
+	collection, err := storagev2.RolesCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var roles []permission.Role
+	err = cursor.All(ctx, &roles)
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (s *roleStorage) FindAll(ctx context.Context) ([]permission.Role, error) {
+	return s.findQuery(ctx, mongoBSON.M{})
+}
+
+func (s *roleStorage) FindByName(ctx context.Context, name string) (*permission.Role, error) {
+	collection, err := storagev2.RolesCollection()
+	if err != nil {
+		return nil, err
+	}
+	var role permission.Role
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": name}).Decode(&role)
+	if err == mongo.ErrNoDocuments {
+		return nil, permission.ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (s *roleStorage) FindWithEvents(ctx context.Context) ([]permission.Role, error) {
+	return s.findQuery(ctx, mongoBSON.M{"events": mongoBSON.M{"$not": mongoBSON.M{"$size": 0}, "$exists": true}})
+}
+
+func (s *roleStorage) FindByEvent(ctx context.Context, eventName string) ([]permission.Role, error) {
+	return s.findQuery(ctx, mongoBSON.M{"events": eventName})
+}
+
+func (s *roleStorage) FindByDynamicSchemePrefix(ctx context.Context, prefix string) ([]permission.Role, error) {
+	return s.findQuery(ctx, mongoBSON.M{
+		"dynamic_scheme_names": mongoBSON.M{"$regex": "^" + regexp.QuoteMeta(prefix) + "(\\.|$)"},
+	})
+}
+
+func (s *roleStorage) Update(ctx context.Context, role permission.Role) error {
+	return s.update(ctx, role.Name, mongoBSON.M{"$set": mongoBSON.M{"contexttype": role.ContextType, "description": role.Description}})
+}
+
+func (s *roleStorage) AddSchemeNames(ctx context.Context, roleName string, schemeNames []string) error {
+	return s.update(ctx, roleName, mongoBSON.M{"$addToSet": mongoBSON.M{"schemenames": mongoBSON.M{"$each": schemeNames}}})
+}
+
+func (s *roleStorage) RemoveSchemeNames(ctx context.Context, roleName string, schemeNames []string) error {
+	return s.update(ctx, roleName, mongoBSON.M{"$pullAll": mongoBSON.M{"schemenames": schemeNames}})
+}
+
+func (s *roleStorage) AddDynamicSchemeNames(ctx context.Context, roleName string, schemeNames []string) error {
+	return s.update(ctx, roleName, mongoBSON.M{"$addToSet": mongoBSON.M{"dynamic_scheme_names": mongoBSON.M{"$each": schemeNames}}})
+}
+
+func (s *roleStorage) RemoveDynamicSchemeNames(ctx context.Context, roleName string, schemeNames []string) error {
+	return s.update(ctx, roleName, mongoBSON.M{"$pullAll": mongoBSON.M{"dynamic_scheme_names": schemeNames}})
+}
+
+func (s *roleStorage) AddEvent(ctx context.Context, roleName, eventName string) error {
+	return s.update(ctx, roleName, mongoBSON.M{"$addToSet": mongoBSON.M{"events": eventName}})
+}
+
+func (s *roleStorage) RemoveEvent(ctx context.Context, roleName, eventName string) error {
+	return s.update(ctx, roleName, mongoBSON.M{"$pull": mongoBSON.M{"events": eventName}})
+}
+
+func (s *roleStorage) update(ctx context.Context, roleName string, update mongoBSON.M) error {
+	collection, err := storagev2.RolesCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": roleName}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return permission.ErrRoleNotFound
+	}
+	return nil
+}
+
+func (s *roleStorage) Delete(ctx context.Context, name string) error {
+	collection, err := storagev2.RolesCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return permission.ErrRoleNotFound
+	}
+	return nil
+}
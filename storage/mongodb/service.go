@@ -0,0 +1,154 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/service"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type serviceStorage struct{}
+
+var _ service.ServiceStorage = &serviceStorage{}
+
+func (s *serviceStorage) Insert(ctx context.Context, svc service.Service) error {
+	collection, err := storagev2.ServicesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, svc)
+	if mongo.IsDuplicateKeyError(err) {
+		return service.ErrServiceAlreadyExists
+	}
+	return err
+}
+
+func (s *serviceStorage) Update(ctx context.Context, svc service.Service) error {
+	collection, err := storagev2.ServicesCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.ReplaceOne(ctx, mongoBSON.M{"_id": svc.Name}, svc)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return service.ErrServiceNotFound
+	}
+	return nil
+}
+
+func (s *serviceStorage) FindByName(ctx context.Context, name string) (*service.Service, error) {
+	collection, err := storagev2.ServicesCollection()
+	if err != nil {
+		return nil, err
+	}
+	var svc service.Service
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": name}).Decode(&svc)
+	if err == mongo.ErrNoDocuments {
+		return nil, service.ErrServiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &svc, nil
+}
+
+func (s *serviceStorage) FindAll(ctx context.Context, f *service.ServiceFilter) ([]service.Service, error) {
+	collection, err := storagev2.ServicesCollection()
+	if err != nil {
+		return nil, err
+	}
+	query := mongoBSON.M{}
+	if f != nil {
+		orQueries := []mongoBSON.M{}
+		if f.Unrestricted {
+			orQueries = append(orQueries, mongoBSON.M{"is_restricted": false})
+		}
+		if f.Teams != nil {
+			orQueries = append(orQueries, mongoBSON.M{"teams": mongoBSON.M{"$in": f.Teams}})
+		}
+		if f.OwnerTeams != nil {
+			orQueries = append(orQueries, mongoBSON.M{"owner_teams": mongoBSON.M{"$in": f.OwnerTeams}})
+		}
+		if f.Names != nil {
+			orQueries = append(orQueries, mongoBSON.M{"_id": mongoBSON.M{"$in": f.Names}})
+		}
+		if len(orQueries) == 0 {
+			return nil, nil
+		}
+		query["$or"] = orQueries
+	}
+	cursor, err := collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var services []service.Service
+	err = cursor.All(ctx, &services)
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+func (s *serviceStorage) SetManifest(ctx context.Context, name string, manifest *service.ServiceManifest) error {
+	collection, err := storagev2.ServicesCollection()
+	if err != nil {
+		return err
+	}
+	update := mongoBSON.M{"$unset": mongoBSON.M{"manifest": 1}}
+	if manifest != nil {
+		update = mongoBSON.M{"$set": mongoBSON.M{"manifest": manifest}}
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": name}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return service.ErrServiceNotFound
+	}
+	return nil
+}
+
+func (s *serviceStorage) RenameTeam(ctx context.Context, oldName, newName string) error {
+	collection, err := storagev2.ServicesCollection()
+	if err != nil {
+		return err
+	}
+	models := []mongo.WriteModel{}
+	for _, field := range []string{"owner_teams", "teams"} {
+		models = append(
+			models,
+			mongo.NewUpdateManyModel().
+				SetFilter(mongoBSON.M{field: oldName}).
+				SetUpdate(mongoBSON.M{"$addToSet": mongoBSON.M{field: newName}}),
+
+			mongo.NewUpdateManyModel().
+				SetFilter(mongoBSON.M{field: oldName}).
+				SetUpdate(mongoBSON.M{"$pull": mongoBSON.M{field: oldName}}),
+		)
+	}
+	_, err = collection.BulkWrite(ctx, models)
+	return err
+}
+
+func (s *serviceStorage) Delete(ctx context.Context, name string) error {
+	collection, err := storagev2.ServicesCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return service.ErrServiceNotFound
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/service"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type serviceInstanceStorage struct{}
+
+var _ service.ServiceInstanceStorage = &serviceInstanceStorage{}
+
+func instanceQuery(serviceName, instanceName string) mongoBSON.M {
+	return mongoBSON.M{"name": instanceName, "service_name": serviceName}
+}
+
+// Insert checks for an instance with the same name before inserting it, as
+// the collection has no unique index on the instance names.
+func (s *serviceInstanceStorage) Insert(ctx context.Context, si service.ServiceInstance) error {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return err
+	}
+	n, err := collection.CountDocuments(ctx, instanceQuery(si.ServiceName, si.Name))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return service.ErrInstanceNameAlreadyExists
+	}
+	_, err = collection.InsertOne(ctx, si)
+	return err
+}
+
+func (s *serviceInstanceStorage) FindByName(ctx context.Context, serviceName, instanceName string) (*service.ServiceInstance, error) {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return nil, err
+	}
+	var si service.ServiceInstance
+	err = collection.FindOne(ctx, instanceQuery(serviceName, instanceName)).Decode(&si)
+	if err == mongo.ErrNoDocuments {
+		return nil, service.ErrServiceInstanceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &si, nil
+}
+
+func (s *serviceInstanceStorage) FindAll(ctx context.Context, f service.ServiceInstanceFilter) ([]service.ServiceInstance, error) {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return nil, err
+	}
+	query := mongoBSON.M{}
+	if f.Teams != nil || f.Names != nil {
+		orQueries := []mongoBSON.M{}
+		if f.Teams != nil {
+			orQueries = append(orQueries, mongoBSON.M{"teams": mongoBSON.M{"$in": f.Teams}})
+		}
+		if f.Names != nil {
+			orQueries = append(orQueries, mongoBSON.M{"name": mongoBSON.M{"$in": f.Names}})
+		}
+		query["$or"] = orQueries
+	}
+	if f.ServiceNames != nil {
+		query["service_name"] = mongoBSON.M{"$in": f.ServiceNames}
+	}
+	if f.AppName != "" {
+		query["apps"] = f.AppName
+	}
+	if f.JobName != "" {
+		query["jobs"] = f.JobName
+	}
+	if len(f.Tags) > 0 {
+		query["tags"] = mongoBSON.M{"$all": f.Tags}
+	}
+	cursor, err := collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var instances []service.ServiceInstance
+	err = cursor.All(ctx, &instances)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func (s *serviceInstanceStorage) Update(ctx context.Context, si service.ServiceInstance) error {
+	return s.update(ctx, si.ServiceName, si.Name, mongoBSON.M{
+		"$set": mongoBSON.M{
+			"description": si.Description,
+			"tags":        si.Tags,
+			"teamowner":   si.TeamOwner,
+			"teams":       si.Teams,
+			"plan_name":   si.PlanName,
+			"parameters":  si.Parameters,
+		},
+	})
+}
+
+func (s *serviceInstanceStorage) AddApp(ctx context.Context, serviceName, instanceName, appName string) error {
+	return s.addUnique(ctx, serviceName, instanceName, "apps", appName, service.ErrAppAlreadyBound)
+}
+
+func (s *serviceInstanceStorage) RemoveApp(ctx context.Context, serviceName, instanceName, appName string) error {
+	return s.update(ctx, serviceName, instanceName, mongoBSON.M{"$pull": mongoBSON.M{"apps": appName}})
+}
+
+func (s *serviceInstanceStorage) AddJob(ctx context.Context, serviceName, instanceName, jobName string) error {
+	return s.addUnique(ctx, serviceName, instanceName, "jobs", jobName, service.ErrJobAlreadyBound)
+}
+
+func (s *serviceInstanceStorage) RemoveJob(ctx context.Context, serviceName, instanceName, jobName string) error {
+	return s.update(ctx, serviceName, instanceName, mongoBSON.M{"$pull": mongoBSON.M{"jobs": jobName}})
+}
+
+func (s *serviceInstanceStorage) AddTeam(ctx context.Context, serviceName, instanceName, teamName string) error {
+	return s.update(ctx, serviceName, instanceName, mongoBSON.M{"$addToSet": mongoBSON.M{"teams": teamName}})
+}
+
+func (s *serviceInstanceStorage) RemoveTeam(ctx context.Context, serviceName, instanceName, teamName string) error {
+	return s.update(ctx, serviceName, instanceName, mongoBSON.M{"$pull": mongoBSON.M{"teams": teamName}})
+}
+
+// addUnique adds value to the field of the instance, returning
+// alreadyExists when the instance already has it.
+func (s *serviceInstanceStorage) addUnique(ctx context.Context, serviceName, instanceName, field, value string, alreadyExists error) error {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return err
+	}
+	query := instanceQuery(serviceName, instanceName)
+	query[field] = mongoBSON.M{"$ne": value}
+	result, err := collection.UpdateOne(ctx, query, mongoBSON.M{"$addToSet": mongoBSON.M{field: value}})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return alreadyExists
+	}
+	return nil
+}
+
+func (s *serviceInstanceStorage) update(ctx context.Context, serviceName, instanceName string, update mongoBSON.M) error {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, instanceQuery(serviceName, instanceName), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return service.ErrServiceInstanceNotFound
+	}
+	return nil
+}
+
+func (s *serviceInstanceStorage) RenameTeam(ctx context.Context, oldName, newName string) error {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return err
+	}
+	updates := []mongo.WriteModel{
+		mongo.NewUpdateManyModel().
+			SetFilter(mongoBSON.M{"teamowner": oldName}).
+			SetUpdate(mongoBSON.M{"$set": mongoBSON.M{"teamowner": newName}}),
+
+		mongo.NewUpdateManyModel().
+			SetFilter(mongoBSON.M{"teams": oldName}).
+			SetUpdate(mongoBSON.M{"$addToSet": mongoBSON.M{"teams": newName}}),
+
+		mongo.NewUpdateManyModel().
+			SetFilter(mongoBSON.M{"teams": oldName}).
+			SetUpdate(mongoBSON.M{"$pull": mongoBSON.M{"teams": oldName}}),
+	}
+	_, err = collection.BulkWrite(ctx, updates)
+	return err
+}
+
+func (s *serviceInstanceStorage) Delete(ctx context.Context, serviceName, instanceName string) error {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, instanceQuery(serviceName, instanceName))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return service.ErrServiceInstanceNotFound
+	}
+	return nil
+}
@@ -26,6 +26,8 @@ type teamToken struct {
 	CreatorEmail string    `bson:"creator_email"`
 	Team         string
 	Roles        []auth.RoleInstance `bson:",omitempty"`
+
+	Constraints *auth.TeamTokenConstraints `bson:",omitempty"`
 }
 
 var _ auth.TeamTokenStorage = &teamTokenStorage{}
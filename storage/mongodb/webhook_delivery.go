@@ -0,0 +1,246 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type webhookDeliveryStorage struct{}
+
+var _ event.WebhookDeliveryStorage = &webhookDeliveryStorage{}
+
+type webhookDelivery struct {
+	ID            string `bson:"_id"`
+	WebhookName   string
+	EventID       string
+	Status        event.WebhookDeliveryStatus
+	Attempts      int
+	MaxAttempts   int
+	LastError     string
+	History       []event.WebhookDeliveryAttempt `bson:",omitempty"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	NextAttemptAt time.Time
+	LockedUntil   time.Time `bson:",omitempty"`
+	ExpireAt      time.Time `bson:",omitempty"`
+}
+
+func deliveryFilterQuery(f event.WebhookDeliveryFilter) mongoBSON.M {
+	query := mongoBSON.M{}
+	if f.WebhookName != "" {
+		query["webhookname"] = f.WebhookName
+	}
+	if f.ID != "" {
+		query["_id"] = f.ID
+	}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	return query
+}
+
+func (s *webhookDeliveryStorage) Insert(ctx context.Context, d event.WebhookDelivery) error {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanInsert, collection.Name())
+	defer span.Finish()
+
+	_, err = collection.InsertOne(ctx, webhookDelivery(d))
+	span.SetError(err)
+	return err
+}
+
+func (s *webhookDeliveryStorage) Update(ctx context.Context, d event.WebhookDelivery) error {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanUpdateID, collection.Name())
+	span.SetMongoID(d.ID)
+	defer span.Finish()
+
+	result, err := collection.ReplaceOne(ctx, mongoBSON.M{"_id": d.ID}, webhookDelivery(d))
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return event.ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+func (s *webhookDeliveryStorage) FindByID(ctx context.Context, id string) (*event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFindID, collection.Name())
+	span.SetMongoID(id)
+	defer span.Finish()
+
+	var d webhookDelivery
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id}).Decode(&d)
+	if err == mongo.ErrNoDocuments {
+		return nil, event.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := event.WebhookDelivery(d)
+	return &result, nil
+}
+
+func (s *webhookDeliveryStorage) Find(ctx context.Context, f event.WebhookDeliveryFilter) ([]event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanFind, collection.Name())
+	defer span.Finish()
+
+	opts := options.Find().SetSort(mongoBSON.D{{Key: "createdat", Value: -1}})
+	if f.Limit > 0 {
+		opts.SetLimit(int64(f.Limit))
+	}
+	cursor, err := collection.Find(ctx, deliveryFilterQuery(f), opts)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	var deliveries []webhookDelivery
+	err = cursor.All(ctx, &deliveries)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := make([]event.WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = event.WebhookDelivery(d)
+	}
+	return result, nil
+}
+
+func (s *webhookDeliveryStorage) AcquireNext(ctx context.Context, lockFor time.Duration) (*event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, collection.Name())
+	defer span.Finish()
+
+	now := time.Now().UTC()
+	query := mongoBSON.M{
+		"status":        event.WebhookDeliveryPending,
+		"nextattemptat": mongoBSON.M{"$lte": now},
+		"$or": []mongoBSON.M{
+			{"lockeduntil": mongoBSON.M{"$exists": false}},
+			{"lockeduntil": mongoBSON.M{"$lte": now}},
+		},
+	}
+	update := mongoBSON.M{"$set": mongoBSON.M{"lockeduntil": now.Add(lockFor)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(mongoBSON.D{{Key: "nextattemptat", Value: 1}}).
+		SetReturnDocument(options.After)
+	var d webhookDelivery
+	err = collection.FindOneAndUpdate(ctx, query, update, opts).Decode(&d)
+	if err == mongo.ErrNoDocuments {
+		return nil, event.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	result := event.WebhookDelivery(d)
+	return &result, nil
+}
+
+func (s *webhookDeliveryStorage) Redrive(ctx context.Context, f event.WebhookDeliveryFilter) (int, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return 0, err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanUpdateAll, collection.Name())
+	defer span.Finish()
+
+	f.Status = event.WebhookDeliveryDead
+	now := time.Now().UTC()
+	result, err := collection.UpdateMany(ctx, deliveryFilterQuery(f), mongoBSON.M{
+		"$set": mongoBSON.M{
+			"status":        event.WebhookDeliveryPending,
+			"attempts":      0,
+			"nextattemptat": now,
+			"updatedat":     now,
+		},
+		"$unset": mongoBSON.M{"lockeduntil": "", "expireat": ""},
+	})
+	if err != nil {
+		span.SetError(err)
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
+
+func (s *webhookDeliveryStorage) Prune(ctx context.Context, webhookName string, keep int) error {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	query := mongoBSON.M{"webhookname": webhookName, "status": event.WebhookDeliverySucceeded}
+	opts := options.Find().
+		SetSort(mongoBSON.D{{Key: "createdat", Value: -1}}).
+		SetSkip(int64(keep)).
+		SetProjection(mongoBSON.M{"_id": 1})
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	var old []struct {
+		ID string `bson:"_id"`
+	}
+	err = cursor.All(ctx, &old)
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	if len(old) == 0 {
+		return nil
+	}
+	ids := make([]string, len(old))
+	for i := range old {
+		ids[i] = old[i].ID
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"_id": mongoBSON.M{"$in": ids}})
+	span.SetError(err)
+	return err
+}
+
+func (s *webhookDeliveryStorage) DeleteByWebhook(ctx context.Context, name string) error {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, collection.Name())
+	defer span.Finish()
+
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"webhookname": name})
+	span.SetError(err)
+	return err
+}
@@ -0,0 +1,618 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/envs/encryption"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"github.com/tsuru/tsuru/types/bind"
+)
+
+type appStorage struct {
+	db *database
+}
+
+var _ appTypes.AppStorage = &appStorage{}
+
+// appDoc is the document of an app, its private env values are encrypted like
+// the ones kept by the mongodb driver. The teams and cnames of the app are
+// also kept in the app_teams and app_cnames tables to look apps up by them.
+type appDoc struct {
+	appTypes.App
+	Env         map[string]bind.EnvVar
+	ServiceEnvs []serviceEnvVar
+}
+
+// serviceEnvVar keeps the service and instance of the env, left out of the
+// JSON encoding of bind.ServiceEnvVar.
+type serviceEnvVar struct {
+	bind.EnvVar
+	ServiceName  string
+	InstanceName string
+}
+
+func encryptEnv(ctx context.Context, env bind.EnvVar) (bind.EnvVar, error) {
+	if env.Public {
+		return env, nil
+	}
+	value, err := encryption.Encrypt(ctx, env.Value)
+	if err != nil {
+		return env, errors.Wrapf(err, "unable to encrypt env %q", env.Name)
+	}
+	env.Value = value
+	return env, nil
+}
+
+func decryptEnv(ctx context.Context, env bind.EnvVar) (bind.EnvVar, error) {
+	value, err := encryption.Decrypt(ctx, env.Value)
+	if err != nil {
+		return env, errors.Wrapf(err, "unable to decrypt env %q", env.Name)
+	}
+	env.Value = value
+	return env, nil
+}
+
+func toAppDoc(ctx context.Context, a *appTypes.App) (*appDoc, error) {
+	doc := &appDoc{App: *a}
+	doc.App.Env = nil
+	doc.App.ServiceEnvs = nil
+	if a.Env != nil {
+		doc.Env = make(map[string]bind.EnvVar, len(a.Env))
+		for k, env := range a.Env {
+			encrypted, err := encryptEnv(ctx, env)
+			if err != nil {
+				return nil, err
+			}
+			doc.Env[k] = encrypted
+		}
+	}
+	for _, env := range a.ServiceEnvs {
+		encrypted, err := encryptEnv(ctx, env.EnvVar)
+		if err != nil {
+			return nil, err
+		}
+		doc.ServiceEnvs = append(doc.ServiceEnvs, serviceEnvVar{
+			EnvVar:       encrypted,
+			ServiceName:  env.ServiceName,
+			InstanceName: env.InstanceName,
+		})
+	}
+	if a.Routers != nil {
+		// Addresses and status are computed by the routers, not stored.
+		doc.Routers = make([]appTypes.AppRouter, len(a.Routers))
+		for i, r := range a.Routers {
+			doc.Routers[i] = appTypes.AppRouter{Name: r.Name, Opts: r.Opts}
+		}
+	}
+	return doc, nil
+}
+
+func (doc *appDoc) toApp(ctx context.Context) (*appTypes.App, error) {
+	a := doc.App
+	if doc.Env != nil {
+		a.Env = make(map[string]bind.EnvVar, len(doc.Env))
+		for k, env := range doc.Env {
+			decrypted, err := decryptEnv(ctx, env)
+			if err != nil {
+				return nil, err
+			}
+			a.Env[k] = decrypted
+		}
+	}
+	for _, env := range doc.ServiceEnvs {
+		decrypted, err := decryptEnv(ctx, env.EnvVar)
+		if err != nil {
+			return nil, err
+		}
+		a.ServiceEnvs = append(a.ServiceEnvs, bind.ServiceEnvVar{
+			EnvVar:       decrypted,
+			ServiceName:  env.ServiceName,
+			InstanceName: env.InstanceName,
+		})
+	}
+	return &a, nil
+}
+
+func (doc *appDoc) hasPrivateEnvs() bool {
+	for _, env := range doc.Env {
+		if !env.Public {
+			return true
+		}
+	}
+	for _, env := range doc.ServiceEnvs {
+		if !env.Public {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *appStorage) Insert(ctx context.Context, a *appTypes.App) error {
+	doc, err := toAppDoc(ctx, a)
+	if err != nil {
+		return err
+	}
+	return s.db.transaction(ctx, func(h handle) error {
+		data, err := marshal(doc)
+		if err != nil {
+			return err
+		}
+		_, err = h.exec(ctx, "INSERT INTO apps (name, team_owner, pool, data) VALUES (?, ?, ?, ?)",
+			a.Name, a.TeamOwner, a.Pool, data)
+		if isDuplicateKeyError(err) {
+			return appTypes.ErrAppAlreadyExists
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return s.setLookups(ctx, h, doc)
+	})
+}
+
+// Update replaces the stored app, doing nothing when it doesn't exist.
+func (s *appStorage) Update(ctx context.Context, a *appTypes.App) error {
+	doc, err := toAppDoc(ctx, a)
+	if err != nil {
+		return err
+	}
+	return s.db.transaction(ctx, func(h handle) error {
+		return s.save(ctx, h, doc)
+	})
+}
+
+func (s *appStorage) save(ctx context.Context, h handle, doc *appDoc) error {
+	data, err := marshal(doc)
+	if err != nil {
+		return err
+	}
+	result, err := h.exec(ctx, "UPDATE apps SET team_owner = ?, pool = ?, data = ? WHERE name = ?",
+		doc.TeamOwner, doc.Pool, data, doc.Name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return errors.WithStack(err)
+	}
+	return s.setLookups(ctx, h, doc)
+}
+
+// setLookups replaces the rows of the teams and cnames of the app.
+func (s *appStorage) setLookups(ctx context.Context, h handle, doc *appDoc) error {
+	for _, lookup := range []struct {
+		table  string
+		column string
+		values []string
+	}{
+		{table: "app_teams", column: "team", values: doc.Teams},
+		{table: "app_cnames", column: "cname", values: doc.CName},
+	} {
+		_, err := h.exec(ctx, "DELETE FROM "+lookup.table+" WHERE app = ?", doc.Name)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, v := range lookup.values {
+			_, err = h.exec(ctx, "INSERT INTO "+lookup.table+" (app, "+lookup.column+") VALUES (?, ?) ON CONFLICT DO NOTHING", doc.Name, v)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *appStorage) FindByName(ctx context.Context, name string) (*appTypes.App, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := queryDoc[appDoc](ctx, h, appTypes.ErrAppNotFound, "SELECT data FROM apps WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	return doc.toApp(ctx)
+}
+
+// List filters the apps by name, owner team and pool in the query, the
+// remaining conditions are checked on the decoded apps.
+func (s *appStorage) List(ctx context.Context, f *appTypes.Filter) ([]appTypes.App, error) {
+	query := "SELECT data FROM apps"
+	var conds []string
+	var args []interface{}
+	var nameMatches *regexp.Regexp
+	if f != nil {
+		if f.Extra != nil {
+			var extraConds []string
+			for field, values := range f.Extra {
+				if len(values) == 0 {
+					continue
+				}
+				var cond string
+				var condArgs []interface{}
+				switch field {
+				case "name", "pool":
+					cond, condArgs = inClause(field, values)
+				case "teamowner":
+					cond, condArgs = inClause("team_owner", values)
+				case "teams":
+					cond, condArgs = inClause("team", values)
+					cond = "name IN (SELECT app FROM app_teams WHERE " + cond + ")"
+				default:
+					return nil, errors.Errorf("unsupported app filter field %q", field)
+				}
+				extraConds = append(extraConds, cond)
+				args = append(args, condArgs...)
+			}
+			if len(extraConds) == 0 {
+				return []appTypes.App{}, nil
+			}
+			conds = append(conds, "("+strings.Join(extraConds, " OR ")+")")
+		}
+		if f.Name != "" {
+			conds = append(conds, "name = ?")
+			args = append(args, f.Name)
+		}
+		if f.TeamOwner != "" {
+			conds = append(conds, "team_owner = ?")
+			args = append(args, f.TeamOwner)
+		}
+		pools := f.Pools
+		if len(pools) == 0 && f.Pool != "" {
+			pools = []string{f.Pool}
+		}
+		if len(pools) > 0 {
+			cond, condArgs := inClause("pool", pools)
+			conds = append(conds, cond)
+			args = append(args, condArgs...)
+		}
+		if f.NameMatches != "" && f.Name == "" {
+			var err error
+			nameMatches, err = regexp.Compile(f.NameMatches)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := queryDocs[appDoc](ctx, h, query+" ORDER BY name", args...)
+	if err != nil {
+		return nil, err
+	}
+	apps := []appTypes.App{}
+	for i := range docs {
+		if f != nil && !matchesApp(f, nameMatches, &docs[i].App) {
+			continue
+		}
+		a, err := docs[i].toApp(ctx)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, *a)
+	}
+	return apps, nil
+}
+
+func matchesApp(f *appTypes.Filter, nameMatches *regexp.Regexp, a *appTypes.App) bool {
+	if nameMatches != nil && !nameMatches.MatchString(a.Name) {
+		return false
+	}
+	if f.UserOwner != "" && a.Owner != f.UserOwner {
+		return false
+	}
+	if f.Platform != "" {
+		parts := strings.SplitN(f.Platform, ":", 2)
+		if a.Platform != parts[0] {
+			return false
+		}
+		if len(parts) == 2 {
+			if parts[1] == "latest" {
+				if a.PlatformVersion != "" && a.PlatformVersion != "latest" {
+					return false
+				}
+			} else if a.PlatformVersion != parts[1] {
+				return false
+			}
+		}
+	}
+	for _, tag := range f.Tags {
+		if !contains(a.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *appStorage) FindByCName(ctx context.Context, cname string) ([]appTypes.App, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := queryDocs[appDoc](ctx, h, "SELECT data FROM apps WHERE name IN (SELECT app FROM app_cnames WHERE cname = ?) ORDER BY name", cname)
+	if err != nil {
+		return nil, err
+	}
+	apps := []appTypes.App{}
+	for i := range docs {
+		a, err := docs[i].toApp(ctx)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, *a)
+	}
+	return apps, nil
+}
+
+func (s *appStorage) FindNamesByTeam(ctx context.Context, team string) ([]string, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.query(ctx, "SELECT app FROM app_teams WHERE team = ? ORDER BY app", team)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		names = append(names, name)
+	}
+	return names, errors.WithStack(rows.Err())
+}
+
+func (s *appStorage) AddTeam(ctx context.Context, appName, team string) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		for _, t := range doc.Teams {
+			if t == team {
+				return
+			}
+		}
+		doc.Teams = append(doc.Teams, team)
+	})
+}
+
+func (s *appStorage) RemoveTeam(ctx context.Context, appName, team string) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		teams := doc.Teams[:0]
+		for _, t := range doc.Teams {
+			if t != team {
+				teams = append(teams, t)
+			}
+		}
+		doc.Teams = teams
+	})
+}
+
+func (s *appStorage) RenameTeam(ctx context.Context, oldName, newName string) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		docs, err := queryDocs[appDoc](ctx, h, "SELECT data FROM apps WHERE team_owner = ? OR name IN (SELECT app FROM app_teams WHERE team = ?)", oldName, oldName)
+		if err != nil {
+			return err
+		}
+		for i := range docs {
+			doc := &docs[i]
+			if doc.TeamOwner == oldName {
+				doc.TeamOwner = newName
+			}
+			if contains(doc.Teams, oldName) {
+				teams := []string{}
+				for _, t := range doc.Teams {
+					if t != oldName && t != newName {
+						teams = append(teams, t)
+					}
+				}
+				doc.Teams = append(teams, newName)
+			}
+			err = s.save(ctx, h, doc)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *appStorage) AddCNames(ctx context.Context, appName string, cnames []string) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		for _, cname := range cnames {
+			if !contains(doc.CName, cname) {
+				doc.CName = append(doc.CName, cname)
+			}
+		}
+	})
+}
+
+func (s *appStorage) RemoveCNames(ctx context.Context, appName string, cnames []string) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		kept := []string{}
+		for _, cname := range doc.CName {
+			if !contains(cnames, cname) {
+				kept = append(kept, cname)
+			}
+		}
+		doc.CName = kept
+	})
+}
+
+func (s *appStorage) SetCertIssuer(ctx context.Context, appName, cname, issuer string) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		if doc.CertIssuers == nil {
+			doc.CertIssuers = appTypes.CertIssuers{}
+		}
+		doc.CertIssuers[cname] = issuer
+	})
+}
+
+func (s *appStorage) RemoveCertIssuer(ctx context.Context, appName, cname string) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		delete(doc.CertIssuers, cname)
+	})
+}
+
+func (s *appStorage) SetRouters(ctx context.Context, appName string, routers []appTypes.AppRouter) error {
+	routerDoc, err := toAppDoc(ctx, &appTypes.App{Routers: routers})
+	if err != nil {
+		return err
+	}
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		doc.Routers = routerDoc.Routers
+		doc.Router = ""
+		doc.RouterOpts = nil
+	})
+}
+
+func (s *appStorage) SetRoutingWeights(ctx context.Context, appName string, weights map[int]int) error {
+	if len(weights) == 0 {
+		weights = nil
+	}
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		doc.RoutingWeights = weights
+	})
+}
+
+func (s *appStorage) SetAutoRollback(ctx context.Context, appName string, cfg *appTypes.AutoRollback) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		doc.AutoRollback = cfg
+	})
+}
+
+func (s *appStorage) SetEnvs(ctx context.Context, appName string, envs map[string]bind.EnvVar) error {
+	envDoc, err := toAppDoc(ctx, &appTypes.App{Env: envs})
+	if err != nil {
+		return err
+	}
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		doc.Env = envDoc.Env
+	})
+}
+
+func (s *appStorage) SetServiceEnvs(ctx context.Context, appName string, envs []bind.ServiceEnvVar) error {
+	envDoc, err := toAppDoc(ctx, &appTypes.App{ServiceEnvs: envs})
+	if err != nil {
+		return err
+	}
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		doc.ServiceEnvs = envDoc.ServiceEnvs
+	})
+}
+
+func (s *appStorage) SetUpdatePlatform(ctx context.Context, appName string, updatePlatform bool) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		doc.UpdatePlatform = updatePlatform
+	})
+}
+
+func (s *appStorage) IncrementDeploys(ctx context.Context, appName string) error {
+	return s.modify(ctx, appName, nil, func(doc *appDoc) {
+		doc.Deploys++
+	})
+}
+
+// ReencryptEnvs re-encrypts the envs of each app inside its own transaction,
+// so env changes made concurrently aren't lost.
+func (s *appStorage) ReencryptEnvs(ctx context.Context) (int, error) {
+	names, err := s.names(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, name := range names {
+		updated := false
+		err = s.db.transaction(ctx, func(h handle) error {
+			docs, err := queryDocs[appDoc](ctx, h, "SELECT data FROM apps WHERE name = ?", name)
+			if err != nil || len(docs) == 0 {
+				return err
+			}
+			if !docs[0].hasPrivateEnvs() {
+				return nil
+			}
+			a, err := docs[0].toApp(ctx)
+			if err != nil {
+				return err
+			}
+			doc, err := toAppDoc(ctx, a)
+			if err != nil {
+				return err
+			}
+			updated = true
+			return s.save(ctx, h, doc)
+		})
+		if err != nil {
+			return count, errors.Wrapf(err, "unable to update envs of app %q", name)
+		}
+		if updated {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *appStorage) names(ctx context.Context) ([]string, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.query(ctx, "SELECT name FROM apps ORDER BY name")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		names = append(names, name)
+	}
+	return names, errors.WithStack(rows.Err())
+}
+
+// modify changes the stored document of the app inside a transaction,
+// returning notFound when the app doesn't exist.
+func (s *appStorage) modify(ctx context.Context, appName string, notFound error, fn func(*appDoc)) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		docs, err := queryDocs[appDoc](ctx, h, "SELECT data FROM apps WHERE name = ?", appName)
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return notFound
+		}
+		fn(&docs[0])
+		return s.save(ctx, h, &docs[0])
+	})
+}
+
+func (s *appStorage) Delete(ctx context.Context, name string) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		err := h.execOne(ctx, appTypes.ErrAppNotFound, "DELETE FROM apps WHERE name = ?", name)
+		if err != nil {
+			return err
+		}
+		_, err = h.exec(ctx, "DELETE FROM app_teams WHERE app = ?", name)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		_, err = h.exec(ctx, "DELETE FROM app_cnames WHERE app = ?", name)
+		return errors.WithStack(err)
+	})
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+type appVersionStorage struct {
+	db *database
+}
+
+var _ appTypes.AppVersionStorage = &appVersionStorage{}
+
+func (s *appVersionStorage) UpdateVersion(ctx context.Context, appName string, vi *appTypes.AppVersionInfo, opts ...*appTypes.AppVersionWriteOptions) error {
+	now := time.Now().UTC()
+	vi.UpdatedAt = now
+	return s.modify(ctx, appName, opts, func(versions *appTypes.AppVersions) error {
+		versions.Versions[vi.Version] = *vi
+		versions.UpdatedAt = now
+		return nil
+	})
+}
+
+func (s *appVersionStorage) UpdateVersionSuccess(ctx context.Context, appName string, vi *appTypes.AppVersionInfo, opts ...*appTypes.AppVersionWriteOptions) error {
+	now := time.Now().UTC()
+	vi.UpdatedAt = now
+	return s.modify(ctx, appName, opts, func(versions *appTypes.AppVersions) error {
+		versions.Versions[vi.Version] = *vi
+		versions.LastSuccessfulVersion = vi.Version
+		versions.UpdatedAt = now
+		return nil
+	})
+}
+
+func (s *appVersionStorage) UpdateBuildPhases(ctx context.Context, appName string, version int, phases []appTypes.BuildPhase, opts ...*appTypes.AppVersionWriteOptions) error {
+	return s.modify(ctx, appName, opts, func(versions *appTypes.AppVersions) error {
+		vi, ok := versions.Versions[version]
+		if !ok {
+			return appTypes.ErrNoVersionsAvailable
+		}
+		now := time.Now().UTC()
+		vi.BuildPhases = phases
+		vi.UpdatedAt = now
+		versions.Versions[version] = vi
+		versions.UpdatedAt = now
+		return nil
+	})
+}
+
+// modify applies fn to the versions of the app and stores them with a new
+// updated hash. When opts carry a PreviousUpdatedHash the change is only
+// stored if the versions were not changed since that hash was read.
+func (s *appVersionStorage) modify(ctx context.Context, appName string, opts []*appTypes.AppVersionWriteOptions, fn func(*appTypes.AppVersions) error) error {
+	var previousHash string
+	if len(opts) > 0 && opts[0] != nil {
+		previousHash = opts[0].PreviousUpdatedHash
+	}
+	return s.db.transaction(ctx, func(h handle) error {
+		versions, err := s.find(ctx, h, appName)
+		if err == appTypes.ErrNoVersionsAvailable && previousHash != "" {
+			return appTypes.ErrTransactionCancelledByChange
+		}
+		if err != nil {
+			return err
+		}
+		if previousHash != "" && versions.UpdatedHash != previousHash {
+			return appTypes.ErrTransactionCancelledByChange
+		}
+		err = fn(versions)
+		if err != nil {
+			return err
+		}
+		return s.save(ctx, h, versions)
+	})
+}
+
+func (s *appVersionStorage) NewAppVersion(ctx context.Context, args appTypes.NewVersionArgs) (*appTypes.AppVersionInfo, error) {
+	var appVersionInfo appTypes.AppVersionInfo
+	err := s.db.transaction(ctx, func(h handle) error {
+		versions, err := s.find(ctx, h, args.App.Name)
+		if err == appTypes.ErrNoVersionsAvailable {
+			versions = &appTypes.AppVersions{
+				AppName:  args.App.Name,
+				Versions: map[int]appTypes.AppVersionInfo{},
+			}
+		} else if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		appVersionInfo = appTypes.AppVersionInfo{
+			Description:    args.Description,
+			Version:        versions.Count + 1,
+			EventID:        args.EventID,
+			CustomBuildTag: args.CustomBuildTag,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		versions.Count = appVersionInfo.Version
+		versions.UpdatedAt = now
+		versions.Versions[appVersionInfo.Version] = appVersionInfo
+		return s.save(ctx, h, versions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &appVersionInfo, nil
+}
+
+func (s *appVersionStorage) DeleteVersions(ctx context.Context, appName string, opts ...*appTypes.AppVersionWriteOptions) error {
+	err := s.modify(ctx, appName, opts, func(versions *appTypes.AppVersions) error {
+		versions.Versions = map[int]appTypes.AppVersionInfo{}
+		versions.MarkedToRemoval = false
+		return nil
+	})
+	if err == appTypes.ErrNoVersionsAvailable {
+		return nil
+	}
+	return err
+}
+
+func (s *appVersionStorage) AllAppVersions(ctx context.Context, appNamesFilter ...string) ([]appTypes.AppVersions, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT data FROM app_versions"
+	var args []interface{}
+	if len(appNamesFilter) > 0 {
+		var cond string
+		cond, args = inClause("app_name", appNamesFilter)
+		query += " WHERE " + cond
+	}
+	allVersions, err := queryDocs[appTypes.AppVersions](ctx, h, query+" ORDER BY app_name", args...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range allVersions {
+		normalizeVersions(&allVersions[i])
+	}
+	return allVersions, nil
+}
+
+func (s *appVersionStorage) AppVersions(ctx context.Context, app *appTypes.App) (appTypes.AppVersions, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return appTypes.AppVersions{}, err
+	}
+	versions, err := s.find(ctx, h, app.Name)
+	if err != nil {
+		return appTypes.AppVersions{}, err
+	}
+	return *versions, nil
+}
+
+func (s *appVersionStorage) DeleteVersionIDs(ctx context.Context, appName string, versions []int, opts ...*appTypes.AppVersionWriteOptions) error {
+	return s.modify(ctx, appName, opts, func(appVersions *appTypes.AppVersions) error {
+		for _, version := range versions {
+			delete(appVersions.Versions, version)
+		}
+		appVersions.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+func (s *appVersionStorage) MarkToRemoval(ctx context.Context, appName string, opts ...*appTypes.AppVersionWriteOptions) error {
+	return s.modify(ctx, appName, opts, func(versions *appTypes.AppVersions) error {
+		versions.MarkedToRemoval = true
+		versions.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+// MarkVersionsToRemoval flags each of the versions, failing without changes
+// when any of them does not exist.
+func (s *appVersionStorage) MarkVersionsToRemoval(ctx context.Context, appName string, versions []int, opts ...*appTypes.AppVersionWriteOptions) error {
+	notFound := appTypes.ErrNoVersionsAvailable
+	if len(opts) > 0 && opts[0] != nil && opts[0].PreviousUpdatedHash != "" {
+		notFound = appTypes.ErrTransactionCancelledByChange
+	}
+	return s.modify(ctx, appName, opts, func(appVersions *appTypes.AppVersions) error {
+		now := time.Now().UTC()
+		for _, version := range versions {
+			vi, ok := appVersions.Versions[version]
+			if !ok {
+				return notFound
+			}
+			vi.MarkedToRemoval = true
+			vi.UpdatedAt = now
+			appVersions.Versions[version] = vi
+		}
+		appVersions.UpdatedAt = now
+		return nil
+	})
+}
+
+func (s *appVersionStorage) find(ctx context.Context, h handle, appName string) (*appTypes.AppVersions, error) {
+	versions, err := queryDoc[appTypes.AppVersions](ctx, h, appTypes.ErrNoVersionsAvailable, "SELECT data FROM app_versions WHERE app_name = ?", appName)
+	if err != nil {
+		return nil, err
+	}
+	normalizeVersions(versions)
+	return versions, nil
+}
+
+// save stores the versions under a newly generated updated hash.
+func (s *appVersionStorage) save(ctx context.Context, h handle, versions *appTypes.AppVersions) error {
+	uuidV4, err := uuid.NewRandom()
+	if err != nil {
+		return errors.WithMessage(err, "failed to generate uuid v4")
+	}
+	versions.UpdatedHash = uuidV4.String()
+	data, err := marshal(versions)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO app_versions (app_name, updated_hash, data) VALUES (?, ?, ?)
+		ON CONFLICT (app_name) DO UPDATE SET updated_hash = excluded.updated_hash, data = excluded.data`,
+		versions.AppName, versions.UpdatedHash, data)
+	return errors.WithStack(err)
+}
+
+// normalizeVersions replaces nil collections read from storage with empty
+// ones, matching the documents returned by the MongoDB driver.
+func normalizeVersions(versions *appTypes.AppVersions) {
+	if versions.Versions == nil {
+		versions.Versions = map[int]appTypes.AppVersionInfo{}
+	}
+	for k, vi := range versions.Versions {
+		if vi.CustomData == nil {
+			vi.CustomData = map[string]interface{}{}
+		}
+		if vi.Processes == nil {
+			vi.Processes = map[string][]string{}
+		}
+		if vi.ExposedPorts == nil {
+			vi.ExposedPorts = []string{}
+		}
+		if vi.PastUnits == nil {
+			vi.PastUnits = map[string]int{}
+		}
+		versions.Versions[k] = vi
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/auth"
+)
+
+var errAuthGroupNameEmpty = errors.New("group name cannot be empty")
+
+type authGroupStorage struct {
+	db *database
+}
+
+var _ auth.GroupStorage = &authGroupStorage{}
+
+// authGroup keeps empty role and member lists, which the omitempty tags of
+// auth.Group would drop.
+type authGroup struct {
+	Name    string
+	Roles   []auth.RoleInstance
+	Members []string
+}
+
+func (s *authGroupStorage) List(ctx context.Context, filter []string) ([]auth.Group, error) {
+	if filter == nil {
+		return s.findByQuery(ctx, "SELECT data FROM auth_groups ORDER BY name")
+	}
+	if len(filter) == 0 {
+		return nil, nil
+	}
+	cond, args := inClause("name", filter)
+	return s.findByQuery(ctx, "SELECT data FROM auth_groups WHERE "+cond+" ORDER BY name", args...)
+}
+
+func (s *authGroupStorage) AddRole(ctx context.Context, name, roleName, contextValue string) error {
+	return s.modify(ctx, name, true, func(g *authGroup) {
+		for _, r := range g.Roles {
+			if r.Name == roleName && r.ContextValue == contextValue {
+				return
+			}
+		}
+		g.Roles = append(g.Roles, auth.RoleInstance{Name: roleName, ContextValue: contextValue})
+	})
+}
+
+func (s *authGroupStorage) RemoveRole(ctx context.Context, name, roleName, contextValue string) error {
+	return s.modify(ctx, name, false, func(g *authGroup) {
+		roles := []auth.RoleInstance{}
+		for _, r := range g.Roles {
+			if r.Name != roleName || r.ContextValue != contextValue {
+				roles = append(roles, r)
+			}
+		}
+		g.Roles = roles
+	})
+}
+
+func (s *authGroupStorage) ListByMember(ctx context.Context, email string) ([]auth.Group, error) {
+	groups, err := s.findByQuery(ctx, "SELECT data FROM auth_groups ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	var result []auth.Group
+	for _, g := range groups {
+		for _, m := range g.Members {
+			if m == email {
+				result = append(result, g)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *authGroupStorage) SetMembers(ctx context.Context, name string, members []string) error {
+	if members == nil {
+		members = []string{}
+	}
+	return s.modify(ctx, name, true, func(g *authGroup) {
+		g.Members = members
+	})
+}
+
+func (s *authGroupStorage) AddMembers(ctx context.Context, name string, members []string) error {
+	return s.modify(ctx, name, true, func(g *authGroup) {
+		for _, member := range members {
+			if !contains(g.Members, member) {
+				g.Members = append(g.Members, member)
+			}
+		}
+	})
+}
+
+func (s *authGroupStorage) RemoveMembers(ctx context.Context, name string, members []string) error {
+	return s.modify(ctx, name, false, func(g *authGroup) {
+		result := []string{}
+		for _, m := range g.Members {
+			if !contains(members, m) {
+				result = append(result, m)
+			}
+		}
+		g.Members = result
+	})
+}
+
+func (s *authGroupStorage) Delete(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, auth.ErrGroupNotFound, "DELETE FROM auth_groups WHERE name = ?", name)
+}
+
+// modify applies fn to the named group and stores it back. Missing groups
+// are created when upsert is set and left alone otherwise.
+func (s *authGroupStorage) modify(ctx context.Context, name string, upsert bool, fn func(*authGroup)) error {
+	if name == "" {
+		return errAuthGroupNameEmpty
+	}
+	return s.db.transaction(ctx, func(h handle) error {
+		g, err := queryDoc[authGroup](ctx, h, auth.ErrGroupNotFound, "SELECT data FROM auth_groups WHERE name = ?", name)
+		if err == auth.ErrGroupNotFound {
+			if !upsert {
+				return nil
+			}
+			g = &authGroup{Name: name}
+		} else if err != nil {
+			return err
+		}
+		fn(g)
+		data, err := marshal(g)
+		if err != nil {
+			return err
+		}
+		_, err = h.exec(ctx, `INSERT INTO auth_groups (name, data) VALUES (?, ?)
+			ON CONFLICT (name) DO UPDATE SET data = excluded.data`, name, data)
+		return errors.WithStack(err)
+	})
+}
+
+func (s *authGroupStorage) findByQuery(ctx context.Context, query string, args ...interface{}) ([]auth.Group, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := queryDocs[authGroup](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]auth.Group, len(groups))
+	for i, g := range groups {
+		result[i] = auth.Group(g)
+	}
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
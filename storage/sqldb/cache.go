@@ -0,0 +1,83 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/cache"
+)
+
+type cacheStorage struct {
+	db *database
+}
+
+var _ cache.CacheStorage = &cacheStorage{}
+
+// notExpired filters out entries past their expiration, which are only
+// removed on the next Put.
+const notExpired = "(expire_at = 0 OR expire_at > ?)"
+
+func (s *cacheStorage) GetAll(ctx context.Context, keys ...string) ([]cache.CacheEntry, error) {
+	if len(keys) == 0 {
+		return []cache.CacheEntry{}, nil
+	}
+	cond, args := inClause("key", keys)
+	return s.find(ctx, "SELECT key, value, expire_at FROM cache_entries WHERE "+cond+" AND "+notExpired, append(args, time.Now().UnixNano())...)
+}
+
+func (s *cacheStorage) Get(ctx context.Context, key string) (cache.CacheEntry, error) {
+	entries, err := s.find(ctx, "SELECT key, value, expire_at FROM cache_entries WHERE key = ? AND "+notExpired, key, time.Now().UnixNano())
+	if err != nil {
+		return cache.CacheEntry{}, err
+	}
+	if len(entries) == 0 {
+		return cache.CacheEntry{}, cache.ErrEntryNotFound
+	}
+	return entries[0], nil
+}
+
+func (s *cacheStorage) find(ctx context.Context, query string, args ...interface{}) ([]cache.CacheEntry, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	entries := []cache.CacheEntry{}
+	for rows.Next() {
+		var entry cache.CacheEntry
+		var expireAt int64
+		err = rows.Scan(&entry.Key, &entry.Value, &expireAt)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if expireAt != 0 {
+			entry.ExpireAt = time.Unix(0, expireAt).UTC()
+		}
+		entries = append(entries, entry)
+	}
+	return entries, errors.WithStack(rows.Err())
+}
+
+func (s *cacheStorage) Put(ctx context.Context, entry cache.CacheEntry) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "DELETE FROM cache_entries WHERE expire_at > 0 AND expire_at <= ?", time.Now().UnixNano())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = h.exec(ctx, `INSERT INTO cache_entries (key, value, expire_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expire_at = excluded.expire_at`,
+		entry.Key, entry.Value, timestamp(entry.ExpireAt))
+	return errors.WithStack(err)
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/provision"
+)
+
+type clusterStorage struct {
+	db *database
+}
+
+var _ provision.ClusterStorage = &clusterStorage{}
+
+type cluster struct {
+	Name        string
+	Addresses   []string
+	Provisioner string
+	CaCert      []byte
+	ClientCert  []byte
+	ClientKey   []byte
+	Pools       []string
+	CustomData  map[string]string
+	Local       bool
+	Default     bool
+	KubeConfig  *provision.KubeConfig
+	HTTPProxy   string
+}
+
+// Upsert stores the cluster, removing its pools from the other clusters of
+// the same provisioner and unsetting their default flag when c is the new
+// default.
+func (s *clusterStorage) Upsert(ctx context.Context, c provision.Cluster) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		if len(c.Pools) > 0 || c.Default {
+			others, err := s.findByQuery(ctx, h, "SELECT data FROM clusters WHERE provisioner = ? AND name <> ?", c.Provisioner, c.Name)
+			if err != nil && err != provision.ErrNoCluster {
+				return err
+			}
+			for _, other := range others {
+				other.Pools = removeValues(other.Pools, c.Pools)
+				if c.Default {
+					other.Default = false
+				}
+				data, err := marshal(cluster(other))
+				if err != nil {
+					return err
+				}
+				_, err = h.exec(ctx, "UPDATE clusters SET data = ? WHERE name = ?", data, other.Name)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+			}
+		}
+		data, err := marshal(cluster(c))
+		if err != nil {
+			return err
+		}
+		_, err = h.exec(ctx, `INSERT INTO clusters (name, provisioner, data) VALUES (?, ?, ?)
+			ON CONFLICT (name) DO UPDATE SET provisioner = excluded.provisioner, data = excluded.data`,
+			c.Name, c.Provisioner, data)
+		return errors.WithStack(err)
+	})
+}
+
+// removeValues returns values without any of the entries in remove.
+func removeValues(values, remove []string) []string {
+	var result []string
+	for _, v := range values {
+		keep := true
+		for _, r := range remove {
+			if v == r {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (s *clusterStorage) FindAll(ctx context.Context) ([]provision.Cluster, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.findByQuery(ctx, h, "SELECT data FROM clusters ORDER BY name")
+}
+
+func (s *clusterStorage) FindByName(ctx context.Context, name string) (*provision.Cluster, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := queryDoc[cluster](ctx, h, provision.ErrClusterNotFound, "SELECT data FROM clusters WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	result := provision.Cluster(*c)
+	return &result, nil
+}
+
+func (s *clusterStorage) FindByProvisioner(ctx context.Context, provisioner string) ([]provision.Cluster, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.findByQuery(ctx, h, "SELECT data FROM clusters WHERE provisioner = ? ORDER BY name", provisioner)
+}
+
+// FindByPool returns the cluster of the provisioner serving pool, falling
+// back to its default cluster.
+func (s *clusterStorage) FindByPool(ctx context.Context, provisioner, pool string) (*provision.Cluster, error) {
+	clusters, err := s.FindByProvisioner(ctx, provisioner)
+	if err != nil {
+		return nil, err
+	}
+	if pool != "" {
+		for i := range clusters {
+			for _, p := range clusters[i].Pools {
+				if p == pool {
+					return &clusters[i], nil
+				}
+			}
+		}
+	}
+	for i := range clusters {
+		if clusters[i].Default {
+			return &clusters[i], nil
+		}
+	}
+	return nil, provision.ErrNoCluster
+}
+
+func (s *clusterStorage) findByQuery(ctx context.Context, h handle, query string, args ...interface{}) ([]provision.Cluster, error) {
+	clusters, err := queryDocs[cluster](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		return nil, provision.ErrNoCluster
+	}
+	result := make([]provision.Cluster, len(clusters))
+	for i, c := range clusters {
+		result[i] = provision.Cluster(c)
+	}
+	return result, nil
+}
+
+func (s *clusterStorage) Delete(ctx context.Context, c provision.Cluster) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, provision.ErrClusterNotFound, "DELETE FROM clusters WHERE name = ?", c.Name)
+}
+
+// addValues appends to values the entries of add it doesn't have yet.
+func addValues(values, add []string) []string {
+	for _, v := range add {
+		if !contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
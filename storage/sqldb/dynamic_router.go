@@ -0,0 +1,56 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/router"
+)
+
+type dynamicRouterStorage struct {
+	db *database
+}
+
+var _ router.DynamicRouterStorage = &dynamicRouterStorage{}
+
+func (s *dynamicRouterStorage) Save(ctx context.Context, dr router.DynamicRouter) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(dr)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO dynamic_routers (name, data) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET data = excluded.data`, dr.Name, data)
+	return errors.WithStack(err)
+}
+
+func (s *dynamicRouterStorage) Get(ctx context.Context, name string) (*router.DynamicRouter, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryDoc[router.DynamicRouter](ctx, h, router.ErrDynamicRouterNotFound, "SELECT data FROM dynamic_routers WHERE name = ?", name)
+}
+
+func (s *dynamicRouterStorage) List(ctx context.Context) ([]router.DynamicRouter, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryDocs[router.DynamicRouter](ctx, h, "SELECT data FROM dynamic_routers ORDER BY name")
+}
+
+func (s *dynamicRouterStorage) Remove(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, router.ErrDynamicRouterNotFound, "DELETE FROM dynamic_routers WHERE name = ?", name)
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/event"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type eventStorage struct {
+	db *database
+}
+
+var _ event.EventStorage = &eventStorage{}
+
+type eventBlock event.Block
+
+func (s *eventStorage) InsertBlock(ctx context.Context, b event.Block) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return s.saveBlock(ctx, h, eventBlock(b), "INSERT INTO event_blocks (active, start_time, end_time, data, id) VALUES (?, ?, ?, ?, ?)")
+}
+
+func (s *eventStorage) saveBlock(ctx context.Context, h handle, b eventBlock, query string) error {
+	data, err := marshal(b)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, query, b.Active, timestamp(b.StartTime), timestamp(b.EndTime), data, b.ID.Hex())
+	return errors.WithStack(err)
+}
+
+func (s *eventStorage) FindBlocks(ctx context.Context, active *bool) ([]event.Block, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []eventBlock
+	if active == nil {
+		blocks, err = queryDocs[eventBlock](ctx, h, "SELECT data FROM event_blocks ORDER BY start_time DESC")
+	} else {
+		blocks, err = queryDocs[eventBlock](ctx, h, "SELECT data FROM event_blocks WHERE active = ? ORDER BY start_time DESC", *active)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []event.Block
+	for _, b := range blocks {
+		result = append(result, event.Block(b))
+	}
+	return result, nil
+}
+
+func (s *eventStorage) DeactivateBlock(ctx context.Context, id primitive.ObjectID, endTime time.Time) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		b, err := queryDoc[eventBlock](ctx, h, event.ErrActiveBlockNotFound,
+			"SELECT data FROM event_blocks WHERE id = ? AND active = ?", id.Hex(), true)
+		if err != nil {
+			return err
+		}
+		b.Active = false
+		b.EndTime = endTime
+		return s.saveBlock(ctx, h, *b, "UPDATE event_blocks SET active = ?, start_time = ?, end_time = ?, data = ? WHERE id = ?")
+	})
+}
+
+func (s *eventStorage) DeactivateExpiredBlocks(ctx context.Context, now time.Time) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		blocks, err := queryDocs[eventBlock](ctx, h,
+			"SELECT data FROM event_blocks WHERE active = ? AND end_time > 0 AND end_time <= ?", true, timestamp(now))
+		if err != nil {
+			return err
+		}
+		for _, b := range blocks {
+			b.Active = false
+			err = s.saveBlock(ctx, h, b, "UPDATE event_blocks SET active = ?, start_time = ?, end_time = ?, data = ? WHERE id = ?")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
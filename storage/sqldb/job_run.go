@@ -0,0 +1,102 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+)
+
+type jobRunStorage struct {
+	db *database
+}
+
+var _ jobTypes.RunStorage = &jobRunStorage{}
+
+func runFilterQuery(f jobTypes.RunFilter) (string, []interface{}) {
+	conds := []string{"1 = 1"}
+	var args []interface{}
+	if f.JobName != "" {
+		conds = append(conds, "job_name = ?")
+		args = append(args, f.JobName)
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, string(f.Status))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (s *jobRunStorage) Upsert(ctx context.Context, r jobTypes.Run) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO job_runs (id, job_name, status, start_time, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET job_name = excluded.job_name, status = excluded.status,
+		start_time = excluded.start_time, data = excluded.data`,
+		r.ID, r.JobName, string(r.Status), timestamp(r.StartTime), data)
+	return errors.WithStack(err)
+}
+
+func (s *jobRunStorage) FindByID(ctx context.Context, jobName, id string) (*jobTypes.Run, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryDoc[jobTypes.Run](ctx, h, jobTypes.ErrJobRunNotFound,
+		"SELECT data FROM job_runs WHERE job_name = ? AND id = ?", jobName, id)
+}
+
+func (s *jobRunStorage) Find(ctx context.Context, f jobTypes.RunFilter) ([]jobTypes.Run, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cond, args := runFilterQuery(f)
+	query := "SELECT data FROM job_runs WHERE " + cond + " ORDER BY start_time DESC"
+	if f.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(f.Limit)
+	}
+	return queryDocs[jobTypes.Run](ctx, h, query, args...)
+}
+
+func (s *jobRunStorage) Prune(ctx context.Context, jobName string, keep int) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		runs, err := queryDocs[jobTypes.Run](ctx, h, `SELECT data FROM job_runs
+			WHERE job_name = ? AND status IN (?, ?) ORDER BY start_time DESC`,
+			jobName, string(jobTypes.RunStatusSucceeded), string(jobTypes.RunStatusFailed))
+		if err != nil {
+			return err
+		}
+		if len(runs) <= keep {
+			return nil
+		}
+		ids := make([]string, 0, len(runs)-keep)
+		for _, r := range runs[keep:] {
+			ids = append(ids, r.ID)
+		}
+		cond, args := inClause("id", ids)
+		_, err = h.exec(ctx, "DELETE FROM job_runs WHERE "+cond, args...)
+		return errors.WithStack(err)
+	})
+}
+
+func (s *jobRunStorage) DeleteByJob(ctx context.Context, jobName string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "DELETE FROM job_runs WHERE job_name = ?", jobName)
+	return errors.WithStack(err)
+}
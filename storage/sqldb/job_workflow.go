@@ -0,0 +1,108 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+)
+
+type workflowStorage struct {
+	db *database
+}
+
+var _ jobTypes.WorkflowStorage = &workflowStorage{}
+
+func (s *workflowStorage) Insert(ctx context.Context, w jobTypes.Workflow) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(w)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO job_workflows (name, team_owner, schedule, last_schedule, data) VALUES (?, ?, ?, ?, ?)",
+		w.Name, w.TeamOwner, w.Schedule, timestamp(w.LastScheduleTime), data)
+	if isDuplicateKeyError(err) {
+		return jobTypes.ErrWorkflowAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+func (s *workflowStorage) Update(ctx context.Context, w jobTypes.Workflow) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(w)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, jobTypes.ErrWorkflowNotFound,
+		"UPDATE job_workflows SET team_owner = ?, schedule = ?, last_schedule = ?, data = ? WHERE name = ?",
+		w.TeamOwner, w.Schedule, timestamp(w.LastScheduleTime), data, w.Name)
+}
+
+func (s *workflowStorage) FindAllByTeams(ctx context.Context, teams []string) ([]jobTypes.Workflow, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if teams == nil {
+		return queryDocs[jobTypes.Workflow](ctx, h, "SELECT data FROM job_workflows ORDER BY name")
+	}
+	if len(teams) == 0 {
+		return nil, nil
+	}
+	cond, args := inClause("team_owner", teams)
+	return queryDocs[jobTypes.Workflow](ctx, h, "SELECT data FROM job_workflows WHERE "+cond+" ORDER BY name", args...)
+}
+
+func (s *workflowStorage) FindByName(ctx context.Context, name string) (*jobTypes.Workflow, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryDoc[jobTypes.Workflow](ctx, h, jobTypes.ErrWorkflowNotFound,
+		"SELECT data FROM job_workflows WHERE name = ?", name)
+}
+
+func (s *workflowStorage) FindScheduled(ctx context.Context) ([]jobTypes.Workflow, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryDocs[jobTypes.Workflow](ctx, h, "SELECT data FROM job_workflows WHERE schedule <> '' ORDER BY name")
+}
+
+func (s *workflowStorage) UpdateLastSchedule(ctx context.Context, name string, previous, last time.Time) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		w, err := queryDoc[jobTypes.Workflow](ctx, h, jobTypes.ErrWorkflowNotFound,
+			"SELECT data FROM job_workflows WHERE name = ?", name)
+		if err != nil {
+			return err
+		}
+		w.LastScheduleTime = last
+		data, err := marshal(w)
+		if err != nil {
+			return err
+		}
+		return h.execOne(ctx, jobTypes.ErrWorkflowScheduleConflict,
+			"UPDATE job_workflows SET last_schedule = ?, data = ? WHERE name = ? AND last_schedule = ?",
+			timestamp(last), data, name, timestamp(previous))
+	})
+}
+
+func (s *workflowStorage) Delete(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, jobTypes.ErrWorkflowNotFound, "DELETE FROM job_workflows WHERE name = ?", name)
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+)
+
+type workflowRunStorage struct {
+	db *database
+}
+
+var _ jobTypes.WorkflowRunStorage = &workflowRunStorage{}
+
+func workflowRunFilterQuery(f jobTypes.WorkflowRunFilter) (string, []interface{}) {
+	conds := []string{"1 = 1"}
+	var args []interface{}
+	if f.WorkflowName != "" {
+		conds = append(conds, "workflow_name = ?")
+		args = append(args, f.WorkflowName)
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, string(f.Status))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (s *workflowRunStorage) Insert(ctx context.Context, r jobTypes.WorkflowRun) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO job_workflow_runs (id, workflow_name, status, start_time, version, data)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		r.ID, r.WorkflowName, string(r.Status), timestamp(r.StartTime), r.Version, data)
+	return errors.WithStack(err)
+}
+
+func (s *workflowRunStorage) Update(ctx context.Context, r jobTypes.WorkflowRun) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	version := r.Version
+	r.Version++
+	data, err := marshal(r)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, jobTypes.ErrWorkflowRunConflict, `UPDATE job_workflow_runs
+		SET status = ?, start_time = ?, version = ?, data = ? WHERE id = ? AND version = ?`,
+		string(r.Status), timestamp(r.StartTime), r.Version, data, r.ID, version)
+}
+
+func (s *workflowRunStorage) FindByID(ctx context.Context, workflowName, id string) (*jobTypes.WorkflowRun, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryDoc[jobTypes.WorkflowRun](ctx, h, jobTypes.ErrWorkflowRunNotFound,
+		"SELECT data FROM job_workflow_runs WHERE workflow_name = ? AND id = ?", workflowName, id)
+}
+
+func (s *workflowRunStorage) Find(ctx context.Context, f jobTypes.WorkflowRunFilter) ([]jobTypes.WorkflowRun, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cond, args := workflowRunFilterQuery(f)
+	query := "SELECT data FROM job_workflow_runs WHERE " + cond + " ORDER BY start_time DESC"
+	if f.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(f.Limit)
+	}
+	return queryDocs[jobTypes.WorkflowRun](ctx, h, query, args...)
+}
+
+func (s *workflowRunStorage) Prune(ctx context.Context, workflowName string, keep int) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		runs, err := queryDocs[jobTypes.WorkflowRun](ctx, h, `SELECT data FROM job_workflow_runs
+			WHERE workflow_name = ? AND status IN (?, ?) ORDER BY start_time DESC`,
+			workflowName, string(jobTypes.RunStatusSucceeded), string(jobTypes.RunStatusFailed))
+		if err != nil {
+			return err
+		}
+		if len(runs) <= keep {
+			return nil
+		}
+		ids := make([]string, 0, len(runs)-keep)
+		for _, r := range runs[keep:] {
+			ids = append(ids, r.ID)
+		}
+		cond, args := inClause("id", ids)
+		_, err = h.exec(ctx, "DELETE FROM job_workflow_runs WHERE "+cond, args...)
+		return errors.WithStack(err)
+	})
+}
+
+func (s *workflowRunStorage) DeleteByWorkflow(ctx context.Context, workflowName string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "DELETE FROM job_workflow_runs WHERE workflow_name = ?", workflowName)
+	return errors.WithStack(err)
+}
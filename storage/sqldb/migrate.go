@@ -0,0 +1,95 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationsLockID identifies the postgres advisory lock serializing
+// migrations run by concurrent API instances.
+const migrationsLockID = 7323836
+
+// migrate applies the embedded migrations missing from the
+// schema_migrations table, in the order of their file names. They run in a
+// single transaction, so a failing migration leaves the schema untouched.
+func migrate(ctx context.Context, db *sql.DB, postgres bool) error {
+	files, err := migrations.ReadDir("migrations")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name()
+	}
+	sort.Strings(names)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer tx.Rollback()
+	h := handle{q: tx, postgres: postgres}
+	if postgres {
+		_, err = h.exec(ctx, "SELECT pg_advisory_xact_lock(?)", migrationsLockID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	_, err = h.exec(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY)")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	applied, err := appliedMigrations(ctx, h)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		version := strings.TrimSuffix(name, ".sql")
+		if applied[version] {
+			continue
+		}
+		data, err := migrations.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		_, err = h.exec(ctx, string(data))
+		if err != nil {
+			return errors.Wrapf(err, "unable to apply migration %q", version)
+		}
+		_, err = h.exec(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", version)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return errors.WithStack(tx.Commit())
+}
+
+func appliedMigrations(ctx context.Context, h handle) (map[string]bool, error) {
+	rows, err := h.query(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	applied := map[string]bool{}
+	for rows.Next() {
+		var version string
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		applied[version] = true
+	}
+	return applied, errors.WithStack(rows.Err())
+}
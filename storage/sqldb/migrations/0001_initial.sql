@@ -0,0 +1,118 @@
+CREATE TABLE teams (
+    name TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE platforms (
+    name TEXT PRIMARY KEY,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE platform_images (
+    name TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE plans (
+    name TEXT PRIMARY KEY,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE cache_entries (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL,
+    expire_at BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX cache_entries_expire_at_idx ON cache_entries (expire_at);
+
+CREATE TABLE team_tokens (
+    token TEXT PRIMARY KEY,
+    token_id TEXT NOT NULL UNIQUE,
+    team TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX team_tokens_team_idx ON team_tokens (team);
+
+CREATE TABLE personal_tokens (
+    token TEXT PRIMARY KEY,
+    token_id TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    user_email TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    expires_at BIGINT NOT NULL,
+    expiry_notified BOOLEAN NOT NULL DEFAULT FALSE,
+    data TEXT NOT NULL,
+    UNIQUE (user_email, name)
+);
+
+CREATE INDEX personal_tokens_expires_at_idx ON personal_tokens (expires_at);
+
+CREATE TABLE webhooks (
+    name TEXT PRIMARY KEY,
+    team_owner TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id TEXT PRIMARY KEY,
+    webhook_name TEXT NOT NULL,
+    status TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    next_attempt_at BIGINT NOT NULL,
+    locked_until BIGINT NOT NULL,
+    expire_at BIGINT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_name, created_at);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (status, next_attempt_at);
+
+CREATE TABLE clusters (
+    name TEXT PRIMARY KEY,
+    provisioner TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX clusters_provisioner_idx ON clusters (provisioner);
+
+CREATE TABLE tracked_instances (
+    name TEXT PRIMARY KEY,
+    last_update BIGINT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE app_versions (
+    app_name TEXT PRIMARY KEY,
+    updated_hash TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE dynamic_routers (
+    name TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE auth_groups (
+    name TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE volumes (
+    name TEXT PRIMARY KEY,
+    pool TEXT NOT NULL,
+    team_owner TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE volume_binds (
+    app TEXT NOT NULL,
+    mount_point TEXT NOT NULL,
+    volume TEXT NOT NULL,
+    read_only BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (app, mount_point, volume)
+);
+
+CREATE INDEX volume_binds_volume_idx ON volume_binds (volume);
@@ -0,0 +1,26 @@
+CREATE TABLE roles (
+    name TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE services (
+    name TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE service_instances (
+    service_name TEXT NOT NULL,
+    name TEXT NOT NULL,
+    data TEXT NOT NULL,
+    PRIMARY KEY (service_name, name)
+);
+
+CREATE TABLE event_blocks (
+    id TEXT PRIMARY KEY,
+    active BOOLEAN NOT NULL,
+    start_time BIGINT NOT NULL,
+    end_time BIGINT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX event_blocks_active_idx ON event_blocks (active);
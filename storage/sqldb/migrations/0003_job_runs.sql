@@ -0,0 +1,9 @@
+CREATE TABLE job_runs (
+    id TEXT PRIMARY KEY,
+    job_name TEXT NOT NULL,
+    status TEXT NOT NULL,
+    start_time BIGINT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX job_runs_job_idx ON job_runs (job_name, start_time);
@@ -0,0 +1,18 @@
+CREATE TABLE job_workflows (
+    name TEXT PRIMARY KEY,
+    team_owner TEXT NOT NULL,
+    schedule TEXT NOT NULL,
+    last_schedule BIGINT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE job_workflow_runs (
+    id TEXT PRIMARY KEY,
+    workflow_name TEXT NOT NULL,
+    status TEXT NOT NULL,
+    start_time BIGINT NOT NULL,
+    version INTEGER NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX job_workflow_runs_workflow_idx ON job_workflow_runs (workflow_name, start_time);
@@ -0,0 +1,24 @@
+CREATE TABLE pools (
+    name TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE apps (
+    name TEXT PRIMARY KEY,
+    team_owner TEXT NOT NULL,
+    pool TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE app_teams (
+    app TEXT NOT NULL,
+    team TEXT NOT NULL,
+    PRIMARY KEY (app, team)
+);
+
+CREATE INDEX app_teams_team_idx ON app_teams (team);
+
+CREATE TABLE user_quotas (
+    email TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
@@ -0,0 +1,7 @@
+CREATE TABLE app_cnames (
+    app TEXT NOT NULL,
+    cname TEXT NOT NULL,
+    PRIMARY KEY (app, cname)
+);
+
+CREATE INDEX app_cnames_cname_idx ON app_cnames (cname);
@@ -0,0 +1,148 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/auth"
+)
+
+type personalTokenStorage struct {
+	db *database
+}
+
+var _ auth.PersonalTokenStorage = &personalTokenStorage{}
+
+type personalToken struct {
+	Token              string
+	TokenID            string
+	Name               string
+	Description        string
+	UserEmail          string
+	CreatedAt          time.Time
+	ExpiresAt          time.Time
+	LastAccess         time.Time
+	AllowedPermissions []string
+	ExpiryNotified     bool
+}
+
+func (s *personalTokenStorage) Insert(ctx context.Context, t auth.PersonalToken) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(personalToken(t))
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO personal_tokens
+		(token, token_id, name, user_email, created_at, expires_at, expiry_notified, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.Token, t.TokenID, t.Name, t.UserEmail, timestamp(t.CreatedAt), timestamp(t.ExpiresAt), t.ExpiryNotified, data)
+	if isDuplicateKeyError(err) {
+		return auth.ErrPersonalTokenAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+func (s *personalTokenStorage) findOne(ctx context.Context, h handle, query string, args ...interface{}) (*auth.PersonalToken, error) {
+	t, err := queryDoc[personalToken](ctx, h, auth.ErrPersonalTokenNotFound, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := auth.PersonalToken(*t)
+	return &result, nil
+}
+
+func (s *personalTokenStorage) FindByToken(ctx context.Context, token string) (*auth.PersonalToken, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.findOne(ctx, h, "SELECT data FROM personal_tokens WHERE token = ?", token)
+}
+
+func (s *personalTokenStorage) FindByTokenID(ctx context.Context, tokenID string) (*auth.PersonalToken, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.findOne(ctx, h, "SELECT data FROM personal_tokens WHERE token_id = ?", tokenID)
+}
+
+func (s *personalTokenStorage) FindByUser(ctx context.Context, email string) ([]auth.PersonalToken, error) {
+	return s.findByQuery(ctx, "SELECT data FROM personal_tokens WHERE user_email = ? ORDER BY created_at", email)
+}
+
+func (s *personalTokenStorage) FindExpiring(ctx context.Context, before time.Time) ([]auth.PersonalToken, error) {
+	return s.findByQuery(ctx, `SELECT data FROM personal_tokens
+		WHERE expires_at > 0 AND expires_at <= ? AND expiry_notified = ? ORDER BY created_at`,
+		timestamp(before), false)
+}
+
+func (s *personalTokenStorage) findByQuery(ctx context.Context, query string, args ...interface{}) ([]auth.PersonalToken, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := queryDocs[personalToken](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]auth.PersonalToken, len(tokens))
+	for i, t := range tokens {
+		result[i] = auth.PersonalToken(t)
+	}
+	return result, nil
+}
+
+// modify applies fn to the token found by query and stores it back.
+func (s *personalTokenStorage) modify(ctx context.Context, fn func(*auth.PersonalToken), query string, args ...interface{}) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		t, err := s.findOne(ctx, h, query, args...)
+		if err != nil {
+			return err
+		}
+		fn(t)
+		data, err := marshal(personalToken(*t))
+		if err != nil {
+			return err
+		}
+		return h.execOne(ctx, auth.ErrPersonalTokenNotFound,
+			"UPDATE personal_tokens SET expiry_notified = ?, data = ? WHERE token = ?", t.ExpiryNotified, data, t.Token)
+	})
+}
+
+func (s *personalTokenStorage) UpdateLastAccess(ctx context.Context, token string) error {
+	return s.modify(ctx, func(t *auth.PersonalToken) {
+		t.LastAccess = time.Now().UTC()
+	}, "SELECT data FROM personal_tokens WHERE token = ?", token)
+}
+
+func (s *personalTokenStorage) SetExpiryNotified(ctx context.Context, tokenID string) error {
+	return s.modify(ctx, func(t *auth.PersonalToken) {
+		t.ExpiryNotified = true
+	}, "SELECT data FROM personal_tokens WHERE token_id = ?", tokenID)
+}
+
+func (s *personalTokenStorage) Delete(ctx context.Context, tokenID string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, auth.ErrPersonalTokenNotFound, "DELETE FROM personal_tokens WHERE token_id = ?", tokenID)
+}
+
+func (s *personalTokenStorage) DeleteByUser(ctx context.Context, email string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "DELETE FROM personal_tokens WHERE user_email = ?", email)
+	return errors.WithStack(err)
+}
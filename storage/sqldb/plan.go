@@ -0,0 +1,120 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/app"
+)
+
+type planStorage struct {
+	db *database
+}
+
+var _ app.PlanStorage = &planStorage{}
+
+type plan struct {
+	Name             string
+	Memory           int64
+	CPUMilli         int
+	CPUBurst         *app.CPUBurst
+	RuntimeClassName string
+	Default          bool
+	Override         *app.PlanOverride `json:"-"`
+}
+
+func (s *planStorage) Insert(ctx context.Context, p app.Plan) error {
+	data, err := marshal(plan(p))
+	if err != nil {
+		return err
+	}
+	return s.db.transaction(ctx, func(h handle) error {
+		if p.Default {
+			err = s.unsetDefault(ctx, h)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = h.exec(ctx, "INSERT INTO plans (name, is_default, data) VALUES (?, ?, ?)", p.Name, p.Default, data)
+		if isDuplicateKeyError(err) {
+			return app.ErrPlanAlreadyExists
+		}
+		return errors.WithStack(err)
+	})
+}
+
+func (s *planStorage) unsetDefault(ctx context.Context, h handle) error {
+	plans, err := queryDocs[plan](ctx, h, "SELECT data FROM plans WHERE is_default = ?", true)
+	if err != nil {
+		return err
+	}
+	for _, p := range plans {
+		p.Default = false
+		data, err := marshal(p)
+		if err != nil {
+			return err
+		}
+		_, err = h.exec(ctx, "UPDATE plans SET is_default = ?, data = ? WHERE name = ?", false, data, p.Name)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (s *planStorage) FindAll(ctx context.Context) ([]app.Plan, error) {
+	return s.findByQuery(ctx, "SELECT data FROM plans ORDER BY name")
+}
+
+func (s *planStorage) FindDefault(ctx context.Context) (*app.Plan, error) {
+	plans, err := s.findByQuery(ctx, "SELECT data FROM plans WHERE is_default = ? ORDER BY name", true)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) > 1 {
+		return nil, app.ErrPlanDefaultAmbiguous
+	}
+	if len(plans) == 0 {
+		return nil, app.ErrPlanDefaultNotFound
+	}
+	return &plans[0], nil
+}
+
+func (s *planStorage) FindByName(ctx context.Context, name string) (*app.Plan, error) {
+	plans, err := s.findByQuery(ctx, "SELECT data FROM plans WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, app.ErrPlanNotFound
+	}
+	return &plans[0], nil
+}
+
+func (s *planStorage) findByQuery(ctx context.Context, query string, args ...interface{}) ([]app.Plan, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := queryDocs[plan](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]app.Plan, len(plans))
+	for i, p := range plans {
+		result[i] = app.Plan(p)
+	}
+	return result, nil
+}
+
+func (s *planStorage) Delete(ctx context.Context, p app.Plan) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, app.ErrPlanNotFound, "DELETE FROM plans WHERE name = ?", p.Name)
+}
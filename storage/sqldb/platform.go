@@ -0,0 +1,87 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/app"
+)
+
+type platformStorage struct {
+	db *database
+}
+
+var _ app.PlatformStorage = &platformStorage{}
+
+func (s *platformStorage) Insert(ctx context.Context, p app.Platform) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO platforms (name, disabled) VALUES (?, ?)", p.Name, p.Disabled)
+	if isDuplicateKeyError(err) {
+		return app.ErrDuplicatePlatform
+	}
+	return errors.WithStack(err)
+}
+
+func (s *platformStorage) FindByName(ctx context.Context, name string) (*app.Platform, error) {
+	platforms, err := s.findByQuery(ctx, "SELECT name, disabled FROM platforms WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	if len(platforms) == 0 {
+		return nil, app.ErrPlatformNotFound
+	}
+	return &platforms[0], nil
+}
+
+func (s *platformStorage) FindAll(ctx context.Context) ([]app.Platform, error) {
+	return s.findByQuery(ctx, "SELECT name, disabled FROM platforms ORDER BY name")
+}
+
+func (s *platformStorage) FindEnabled(ctx context.Context) ([]app.Platform, error) {
+	return s.findByQuery(ctx, "SELECT name, disabled FROM platforms WHERE disabled = ? ORDER BY name", false)
+}
+
+func (s *platformStorage) findByQuery(ctx context.Context, query string, args ...interface{}) ([]app.Platform, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	platforms := []app.Platform{}
+	for rows.Next() {
+		var p app.Platform
+		err = rows.Scan(&p.Name, &p.Disabled)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		platforms = append(platforms, p)
+	}
+	return platforms, errors.WithStack(rows.Err())
+}
+
+func (s *platformStorage) Update(ctx context.Context, p app.Platform) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, app.ErrPlatformNotFound, "UPDATE platforms SET disabled = ? WHERE name = ?", p.Disabled, p.Name)
+}
+
+func (s *platformStorage) Delete(ctx context.Context, p app.Platform) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, app.ErrPlatformNotFound, "DELETE FROM platforms WHERE name = ?", p.Name)
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/app/image"
+)
+
+type platformImageStorage struct {
+	db *database
+}
+
+var _ image.PlatformImageStorage = &platformImageStorage{}
+
+// Upsert increments the image count of the platform, creating its record
+// when missing.
+func (s *platformImageStorage) Upsert(ctx context.Context, name string) (*image.PlatformImage, error) {
+	var result *image.PlatformImage
+	err := s.db.transaction(ctx, func(h handle) error {
+		p, err := s.find(ctx, h, name)
+		if err == image.ErrPlatformImageNotFound {
+			p = &image.PlatformImage{Name: name}
+		} else if err != nil {
+			return err
+		}
+		p.Count++
+		err = s.save(ctx, h, p)
+		if err != nil {
+			return err
+		}
+		result = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *platformImageStorage) FindByName(ctx context.Context, name string) (*image.PlatformImage, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.find(ctx, h, name)
+}
+
+// Append adds images to the given version of the platform, doing nothing
+// when the platform has no record.
+func (s *platformImageStorage) Append(ctx context.Context, name string, version int, images []string) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		p, err := s.find(ctx, h, name)
+		if err == image.ErrPlatformImageNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		found := false
+		for i := range p.Versions {
+			if p.Versions[i].Version == version {
+				p.Versions[i].Images = append(p.Versions[i].Images, images...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			p.Versions = append(p.Versions, image.RegistryVersion{Version: version, Images: images})
+		}
+		return s.save(ctx, h, p)
+	})
+}
+
+func (s *platformImageStorage) Delete(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, image.ErrPlatformImageNotFound, "DELETE FROM platform_images WHERE name = ?", name)
+}
+
+func (s *platformImageStorage) find(ctx context.Context, h handle, name string) (*image.PlatformImage, error) {
+	p, err := queryDoc[image.PlatformImage](ctx, h, image.ErrPlatformImageNotFound, "SELECT data FROM platform_images WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(p.Versions, func(i, j int) bool {
+		return p.Versions[i].Version < p.Versions[j].Version
+	})
+	return p, nil
+}
+
+func (s *platformImageStorage) save(ctx context.Context, h handle, p *image.PlatformImage) error {
+	data, err := marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO platform_images (name, data) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET data = excluded.data`, p.Name, data)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/provision"
+)
+
+type poolStorage struct {
+	db *database
+}
+
+var _ provision.PoolStorage = &poolStorage{}
+
+func (s *poolStorage) Insert(ctx context.Context, p provision.Pool) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO pools (name, data) VALUES (?, ?)", p.Name, data)
+	if isDuplicateKeyError(err) {
+		return provision.ErrPoolAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+func (s *poolStorage) Update(ctx context.Context, p provision.Pool) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(p)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, provision.ErrPoolNotFound, "UPDATE pools SET data = ? WHERE name = ?", data, p.Name)
+}
+
+func (s *poolStorage) Delete(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, provision.ErrPoolNotFound, "DELETE FROM pools WHERE name = ?", name)
+}
+
+func (s *poolStorage) FindAll(ctx context.Context) ([]provision.Pool, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := queryDocs[provision.Pool](ctx, h, "SELECT data FROM pools ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	if pools == nil {
+		pools = []provision.Pool{}
+	}
+	return pools, nil
+}
+
+func (s *poolStorage) FindByName(ctx context.Context, name string) (*provision.Pool, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryDoc[provision.Pool](ctx, h, provision.ErrPoolNotFound, "SELECT data FROM pools WHERE name = ?", name)
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/storage"
+	"github.com/tsuru/tsuru/types/quota"
+)
+
+type teamQuotaStorage struct {
+	db *database
+}
+
+var _ quota.QuotaStorage = &teamQuotaStorage{}
+
+func (s *teamQuotaStorage) Get(ctx context.Context, name string) (*quota.Quota, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, err := queryDoc[team](ctx, h, quota.ErrQuotaNotFound, "SELECT data FROM teams WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	return &t.Quota, nil
+}
+
+func (s *teamQuotaStorage) SetLimit(ctx context.Context, name string, limit int) error {
+	return s.modify(ctx, name, func(q *quota.Quota) {
+		q.Limit = limit
+	})
+}
+
+func (s *teamQuotaStorage) Set(ctx context.Context, name string, inUse int) error {
+	return s.modify(ctx, name, func(q *quota.Quota) {
+		q.InUse = inUse
+	})
+}
+
+func (s *teamQuotaStorage) modify(ctx context.Context, name string, fn func(*quota.Quota)) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		t, err := queryDoc[team](ctx, h, quota.ErrQuotaNotFound, "SELECT data FROM teams WHERE name = ?", name)
+		if err != nil {
+			return err
+		}
+		fn(&t.Quota)
+		data, err := marshal(t)
+		if err != nil {
+			return err
+		}
+		return h.execOne(ctx, quota.ErrQuotaNotFound, "UPDATE teams SET data = ? WHERE name = ?", data, name)
+	})
+}
+
+type appQuotaStorage struct {
+	apps *appStorage
+}
+
+var _ quota.QuotaStorage = &appQuotaStorage{}
+
+func (s *appQuotaStorage) Get(ctx context.Context, name string) (*quota.Quota, error) {
+	h, err := s.apps.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a, err := queryDoc[appDoc](ctx, h, quota.ErrQuotaNotFound, "SELECT data FROM apps WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	return &a.Quota, nil
+}
+
+func (s *appQuotaStorage) SetLimit(ctx context.Context, name string, limit int) error {
+	return s.apps.modify(ctx, name, quota.ErrQuotaNotFound, func(a *appDoc) {
+		a.Quota.Limit = limit
+	})
+}
+
+func (s *appQuotaStorage) Set(ctx context.Context, name string, inUse int) error {
+	return s.apps.modify(ctx, name, quota.ErrQuotaNotFound, func(a *appDoc) {
+		a.Quota.InUse = inUse
+	})
+}
+
+// userQuotaStorage keeps the quotas of users in the user_quotas table.
+// Users themselves are still stored in MongoDB by the auth package, so the
+// quota of a user without a row yet is seeded from the user document by
+// userQuota, which returns quota.ErrQuotaNotFound for unknown users.
+type userQuotaStorage struct {
+	db        *database
+	userQuota func(ctx context.Context, email string) (*quota.Quota, error)
+}
+
+var _ quota.QuotaStorage = &userQuotaStorage{}
+
+func mongodbUserQuota(ctx context.Context, email string) (*quota.Quota, error) {
+	driver, err := storage.GetDbDriver("mongodb")
+	if err != nil {
+		return nil, err
+	}
+	return driver.UserQuotaStorage.Get(ctx, email)
+}
+
+func (s *userQuotaStorage) Get(ctx context.Context, email string) (*quota.Quota, error) {
+	var q *quota.Quota
+	err := s.db.transaction(ctx, func(h handle) error {
+		var err error
+		q, err = s.get(ctx, h, email)
+		return err
+	})
+	return q, err
+}
+
+func (s *userQuotaStorage) get(ctx context.Context, h handle, email string) (*quota.Quota, error) {
+	quotas, err := queryDocs[quota.Quota](ctx, h, "SELECT data FROM user_quotas WHERE email = ?", email)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotas) > 0 {
+		return &quotas[0], nil
+	}
+	q, err := s.userQuota(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	data, err := marshal(q)
+	if err != nil {
+		return nil, err
+	}
+	_, err = h.exec(ctx, "INSERT INTO user_quotas (email, data) VALUES (?, ?) ON CONFLICT (email) DO NOTHING", email, data)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return q, nil
+}
+
+func (s *userQuotaStorage) SetLimit(ctx context.Context, email string, limit int) error {
+	return s.modify(ctx, email, func(q *quota.Quota) {
+		q.Limit = limit
+	})
+}
+
+func (s *userQuotaStorage) Set(ctx context.Context, email string, inUse int) error {
+	return s.modify(ctx, email, func(q *quota.Quota) {
+		q.InUse = inUse
+	})
+}
+
+func (s *userQuotaStorage) modify(ctx context.Context, email string, fn func(*quota.Quota)) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		q, err := s.get(ctx, h, email)
+		if err != nil {
+			return err
+		}
+		fn(q)
+		data, err := marshal(q)
+		if err != nil {
+			return err
+		}
+		return h.execOne(ctx, quota.ErrQuotaNotFound, "UPDATE user_quotas SET data = ? WHERE email = ?", data, email)
+	})
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/permission"
+)
+
+type roleStorage struct {
+	db *database
+}
+
+var _ permission.RoleStorage = &roleStorage{}
+
+type role struct {
+	Name               string
+	ContextType        permission.ContextType
+	Description        string
+	SchemeNames        []string
+	DynamicSchemeNames []string
+	Events             []string
+}
+
+// toRole returns empty scheme and event lists, as stored by the other
+// drivers, while leaving out an empty dynamic scheme list.
+func (r role) toRole() permission.Role {
+	result := permission.Role(r)
+	if result.SchemeNames == nil {
+		result.SchemeNames = []string{}
+	}
+	if result.Events == nil {
+		result.Events = []string{}
+	}
+	if len(result.DynamicSchemeNames) == 0 {
+		result.DynamicSchemeNames = nil
+	}
+	return result
+}
+
+func (s *roleStorage) Insert(ctx context.Context, r permission.Role) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(role(r))
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO roles (name, data) VALUES (?, ?)", r.Name, data)
+	if isDuplicateKeyError(err) {
+		return permission.ErrRoleAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+// findFiltered returns the roles accepted by filter. Roles are filtered in
+// memory, as their lists are kept in the document.
+func (s *roleStorage) findFiltered(ctx context.Context, filter func(permission.Role) bool) ([]permission.Role, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := queryDocs[role](ctx, h, "SELECT data FROM roles ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	var result []permission.Role
+	for _, r := range roles {
+		dbRole := r.toRole()
+		if filter == nil || filter(dbRole) {
+			result = append(result, dbRole)
+		}
+	}
+	return result, nil
+}
+
+func (s *roleStorage) FindAll(ctx context.Context) ([]permission.Role, error) {
+	return s.findFiltered(ctx, nil)
+}
+
+func (s *roleStorage) FindByName(ctx context.Context, name string) (*permission.Role, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := queryDoc[role](ctx, h, permission.ErrRoleNotFound, "SELECT data FROM roles WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	result := r.toRole()
+	return &result, nil
+}
+
+func (s *roleStorage) FindWithEvents(ctx context.Context) ([]permission.Role, error) {
+	return s.findFiltered(ctx, func(r permission.Role) bool {
+		return len(r.Events) > 0
+	})
+}
+
+func (s *roleStorage) FindByEvent(ctx context.Context, eventName string) ([]permission.Role, error) {
+	return s.findFiltered(ctx, func(r permission.Role) bool {
+		return contains(r.Events, eventName)
+	})
+}
+
+func (s *roleStorage) FindByDynamicSchemePrefix(ctx context.Context, prefix string) ([]permission.Role, error) {
+	return s.findFiltered(ctx, func(r permission.Role) bool {
+		for _, name := range r.DynamicSchemeNames {
+			if name == prefix || strings.HasPrefix(name, prefix+".") {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (s *roleStorage) Update(ctx context.Context, r permission.Role) error {
+	return s.modify(ctx, r.Name, func(dbRole *role) {
+		dbRole.ContextType = r.ContextType
+		dbRole.Description = r.Description
+	})
+}
+
+func (s *roleStorage) AddSchemeNames(ctx context.Context, roleName string, schemeNames []string) error {
+	return s.modify(ctx, roleName, func(r *role) {
+		r.SchemeNames = addValues(r.SchemeNames, schemeNames)
+	})
+}
+
+func (s *roleStorage) RemoveSchemeNames(ctx context.Context, roleName string, schemeNames []string) error {
+	return s.modify(ctx, roleName, func(r *role) {
+		r.SchemeNames = removeValues(r.SchemeNames, schemeNames)
+	})
+}
+
+func (s *roleStorage) AddDynamicSchemeNames(ctx context.Context, roleName string, schemeNames []string) error {
+	return s.modify(ctx, roleName, func(r *role) {
+		r.DynamicSchemeNames = addValues(r.DynamicSchemeNames, schemeNames)
+	})
+}
+
+func (s *roleStorage) RemoveDynamicSchemeNames(ctx context.Context, roleName string, schemeNames []string) error {
+	return s.modify(ctx, roleName, func(r *role) {
+		r.DynamicSchemeNames = removeValues(r.DynamicSchemeNames, schemeNames)
+	})
+}
+
+func (s *roleStorage) AddEvent(ctx context.Context, roleName, eventName string) error {
+	return s.modify(ctx, roleName, func(r *role) {
+		r.Events = addValues(r.Events, []string{eventName})
+	})
+}
+
+func (s *roleStorage) RemoveEvent(ctx context.Context, roleName, eventName string) error {
+	return s.modify(ctx, roleName, func(r *role) {
+		r.Events = removeValues(r.Events, []string{eventName})
+	})
+}
+
+// modify applies fn to the named role and stores it back.
+func (s *roleStorage) modify(ctx context.Context, name string, fn func(*role)) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		r, err := queryDoc[role](ctx, h, permission.ErrRoleNotFound, "SELECT data FROM roles WHERE name = ?", name)
+		if err != nil {
+			return err
+		}
+		fn(r)
+		data, err := marshal(r)
+		if err != nil {
+			return err
+		}
+		return h.execOne(ctx, permission.ErrRoleNotFound, "UPDATE roles SET data = ? WHERE name = ?", data, name)
+	})
+}
+
+func (s *roleStorage) Delete(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, permission.ErrRoleNotFound, "DELETE FROM roles WHERE name = ?", name)
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/service"
+)
+
+type serviceStorage struct {
+	db *database
+}
+
+var _ service.ServiceStorage = &serviceStorage{}
+
+type svc service.Service
+
+// toService returns empty team lists and endpoints, as stored by the other
+// drivers.
+func (s svc) toService() service.Service {
+	result := service.Service(s)
+	if result.Endpoint == nil {
+		result.Endpoint = map[string]string{}
+	}
+	if result.OwnerTeams == nil {
+		result.OwnerTeams = []string{}
+	}
+	if result.Teams == nil {
+		result.Teams = []string{}
+	}
+	return result
+}
+
+func (s *serviceStorage) Insert(ctx context.Context, srv service.Service) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(svc(srv))
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO services (name, data) VALUES (?, ?)", srv.Name, data)
+	if isDuplicateKeyError(err) {
+		return service.ErrServiceAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+func (s *serviceStorage) Update(ctx context.Context, srv service.Service) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(svc(srv))
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, service.ErrServiceNotFound, "UPDATE services SET data = ? WHERE name = ?", data, srv.Name)
+}
+
+func (s *serviceStorage) FindByName(ctx context.Context, name string) (*service.Service, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	srv, err := queryDoc[svc](ctx, h, service.ErrServiceNotFound, "SELECT data FROM services WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	result := srv.toService()
+	return &result, nil
+}
+
+// FindAll filters the services in memory, as their teams are kept in the
+// document.
+func (s *serviceStorage) FindAll(ctx context.Context, f *service.ServiceFilter) ([]service.Service, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	services, err := queryDocs[svc](ctx, h, "SELECT data FROM services ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	var result []service.Service
+	for _, srv := range services {
+		if f == nil || matchesServiceFilter(f, srv) {
+			result = append(result, srv.toService())
+		}
+	}
+	return result, nil
+}
+
+// matchesServiceFilter reports whether srv matches any of the conditions in
+// f, where an empty list matches no service.
+func matchesServiceFilter(f *service.ServiceFilter, srv svc) bool {
+	if f.Unrestricted && !srv.IsRestricted {
+		return true
+	}
+	return (len(f.Teams) > 0 && matchAny(f.Teams, srv.Teams)) ||
+		(len(f.OwnerTeams) > 0 && matchAny(f.OwnerTeams, srv.OwnerTeams)) ||
+		contains(f.Names, srv.Name)
+}
+
+func (s *serviceStorage) SetManifest(ctx context.Context, name string, manifest *service.ServiceManifest) error {
+	return s.modify(ctx, name, func(srv *svc) {
+		srv.Manifest = manifest
+	})
+}
+
+func (s *serviceStorage) RenameTeam(ctx context.Context, oldName, newName string) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		services, err := queryDocs[svc](ctx, h, "SELECT data FROM services")
+		if err != nil {
+			return err
+		}
+		for _, srv := range services {
+			if !contains(srv.OwnerTeams, oldName) && !contains(srv.Teams, oldName) {
+				continue
+			}
+			srv.OwnerTeams = renameValue(srv.OwnerTeams, oldName, newName)
+			srv.Teams = renameValue(srv.Teams, oldName, newName)
+			data, err := marshal(srv)
+			if err != nil {
+				return err
+			}
+			_, err = h.exec(ctx, "UPDATE services SET data = ? WHERE name = ?", data, srv.Name)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
+
+// modify applies fn to the named service and stores it back.
+func (s *serviceStorage) modify(ctx context.Context, name string, fn func(*svc)) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		srv, err := queryDoc[svc](ctx, h, service.ErrServiceNotFound, "SELECT data FROM services WHERE name = ?", name)
+		if err != nil {
+			return err
+		}
+		fn(srv)
+		data, err := marshal(srv)
+		if err != nil {
+			return err
+		}
+		return h.execOne(ctx, service.ErrServiceNotFound, "UPDATE services SET data = ? WHERE name = ?", data, name)
+	})
+}
+
+func (s *serviceStorage) Delete(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, service.ErrServiceNotFound, "DELETE FROM services WHERE name = ?", name)
+}
+
+// renameValue replaces oldValue in values with newValue, unless values
+// already has it.
+func renameValue(values []string, oldValue, newValue string) []string {
+	if !contains(values, oldValue) {
+		return values
+	}
+	return addValues(removeValues(values, []string{oldValue}), []string{newValue})
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/service"
+)
+
+type serviceInstanceStorage struct {
+	db *database
+}
+
+var _ service.ServiceInstanceStorage = &serviceInstanceStorage{}
+
+type serviceInstance struct {
+	Name        string
+	ServiceName string
+	PlanName    string
+	Apps        []string
+	Jobs        []string
+	Teams       []string
+	TeamOwner   string
+	Description string
+	Tags        []string
+	Parameters  map[string]interface{}
+	Pool        string
+}
+
+func newServiceInstance(si service.ServiceInstance) serviceInstance {
+	return serviceInstance{
+		Name:        si.Name,
+		ServiceName: si.ServiceName,
+		PlanName:    si.PlanName,
+		Apps:        si.Apps,
+		Jobs:        si.Jobs,
+		Teams:       si.Teams,
+		TeamOwner:   si.TeamOwner,
+		Description: si.Description,
+		Tags:        si.Tags,
+		Parameters:  si.Parameters,
+		Pool:        si.Pool,
+	}
+}
+
+// toServiceInstance returns empty lists and parameters, as stored by the
+// other drivers.
+func (si serviceInstance) toServiceInstance() service.ServiceInstance {
+	result := service.ServiceInstance{
+		Name:        si.Name,
+		ServiceName: si.ServiceName,
+		PlanName:    si.PlanName,
+		Apps:        si.Apps,
+		Jobs:        si.Jobs,
+		Teams:       si.Teams,
+		TeamOwner:   si.TeamOwner,
+		Description: si.Description,
+		Tags:        si.Tags,
+		Parameters:  si.Parameters,
+		Pool:        si.Pool,
+	}
+	for _, values := range []*[]string{&result.Apps, &result.Jobs, &result.Teams, &result.Tags} {
+		if *values == nil {
+			*values = []string{}
+		}
+	}
+	if result.Parameters == nil {
+		result.Parameters = map[string]interface{}{}
+	}
+	return result
+}
+
+func (s *serviceInstanceStorage) Insert(ctx context.Context, si service.ServiceInstance) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(newServiceInstance(si))
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO service_instances (service_name, name, data) VALUES (?, ?, ?)",
+		si.ServiceName, si.Name, data)
+	if isDuplicateKeyError(err) {
+		return service.ErrInstanceNameAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+func (s *serviceInstanceStorage) FindByName(ctx context.Context, serviceName, instanceName string) (*service.ServiceInstance, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	si, err := queryDoc[serviceInstance](ctx, h, service.ErrServiceInstanceNotFound,
+		"SELECT data FROM service_instances WHERE service_name = ? AND name = ?", serviceName, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	result := si.toServiceInstance()
+	return &result, nil
+}
+
+// FindAll filters the instances in memory, as their teams, apps, jobs and
+// tags are kept in the document.
+func (s *serviceInstanceStorage) FindAll(ctx context.Context, f service.ServiceInstanceFilter) ([]service.ServiceInstance, error) {
+	query := "SELECT data FROM service_instances"
+	var args []interface{}
+	if f.ServiceNames != nil {
+		if len(f.ServiceNames) == 0 {
+			return nil, nil
+		}
+		var cond string
+		cond, args = inClause("service_name", f.ServiceNames)
+		query += " WHERE " + cond
+	}
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := queryDocs[serviceInstance](ctx, h, query+" ORDER BY service_name, name", args...)
+	if err != nil {
+		return nil, err
+	}
+	var result []service.ServiceInstance
+	for _, si := range instances {
+		if matchesServiceInstanceFilter(f, si) {
+			result = append(result, si.toServiceInstance())
+		}
+	}
+	return result, nil
+}
+
+func matchesServiceInstanceFilter(f service.ServiceInstanceFilter, si serviceInstance) bool {
+	if f.Teams != nil || f.Names != nil {
+		if !(len(f.Teams) > 0 && matchAny(f.Teams, si.Teams)) && !contains(f.Names, si.Name) {
+			return false
+		}
+	}
+	if f.AppName != "" && !contains(si.Apps, f.AppName) {
+		return false
+	}
+	if f.JobName != "" && !contains(si.Jobs, f.JobName) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !contains(si.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *serviceInstanceStorage) Update(ctx context.Context, si service.ServiceInstance) error {
+	return s.modify(ctx, si.ServiceName, si.Name, func(dbInstance *serviceInstance) error {
+		dbInstance.Description = si.Description
+		dbInstance.Tags = si.Tags
+		dbInstance.TeamOwner = si.TeamOwner
+		dbInstance.Teams = si.Teams
+		dbInstance.PlanName = si.PlanName
+		dbInstance.Parameters = si.Parameters
+		return nil
+	})
+}
+
+func (s *serviceInstanceStorage) AddApp(ctx context.Context, serviceName, instanceName, appName string) error {
+	return s.modify(ctx, serviceName, instanceName, func(si *serviceInstance) error {
+		if contains(si.Apps, appName) {
+			return service.ErrAppAlreadyBound
+		}
+		si.Apps = append(si.Apps, appName)
+		return nil
+	})
+}
+
+func (s *serviceInstanceStorage) RemoveApp(ctx context.Context, serviceName, instanceName, appName string) error {
+	return s.modify(ctx, serviceName, instanceName, func(si *serviceInstance) error {
+		si.Apps = removeValues(si.Apps, []string{appName})
+		return nil
+	})
+}
+
+func (s *serviceInstanceStorage) AddJob(ctx context.Context, serviceName, instanceName, jobName string) error {
+	return s.modify(ctx, serviceName, instanceName, func(si *serviceInstance) error {
+		if contains(si.Jobs, jobName) {
+			return service.ErrJobAlreadyBound
+		}
+		si.Jobs = append(si.Jobs, jobName)
+		return nil
+	})
+}
+
+func (s *serviceInstanceStorage) RemoveJob(ctx context.Context, serviceName, instanceName, jobName string) error {
+	return s.modify(ctx, serviceName, instanceName, func(si *serviceInstance) error {
+		si.Jobs = removeValues(si.Jobs, []string{jobName})
+		return nil
+	})
+}
+
+func (s *serviceInstanceStorage) AddTeam(ctx context.Context, serviceName, instanceName, teamName string) error {
+	return s.modify(ctx, serviceName, instanceName, func(si *serviceInstance) error {
+		si.Teams = addValues(si.Teams, []string{teamName})
+		return nil
+	})
+}
+
+func (s *serviceInstanceStorage) RemoveTeam(ctx context.Context, serviceName, instanceName, teamName string) error {
+	return s.modify(ctx, serviceName, instanceName, func(si *serviceInstance) error {
+		si.Teams = removeValues(si.Teams, []string{teamName})
+		return nil
+	})
+}
+
+// modify applies fn to the instance and stores it back, unless fn fails.
+func (s *serviceInstanceStorage) modify(ctx context.Context, serviceName, instanceName string, fn func(*serviceInstance) error) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		si, err := queryDoc[serviceInstance](ctx, h, service.ErrServiceInstanceNotFound,
+			"SELECT data FROM service_instances WHERE service_name = ? AND name = ?", serviceName, instanceName)
+		if err != nil {
+			return err
+		}
+		err = fn(si)
+		if err != nil {
+			return err
+		}
+		return s.save(ctx, h, *si)
+	})
+}
+
+func (s *serviceInstanceStorage) save(ctx context.Context, h handle, si serviceInstance) error {
+	data, err := marshal(si)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, service.ErrServiceInstanceNotFound,
+		"UPDATE service_instances SET data = ? WHERE service_name = ? AND name = ?", data, si.ServiceName, si.Name)
+}
+
+func (s *serviceInstanceStorage) RenameTeam(ctx context.Context, oldName, newName string) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		instances, err := queryDocs[serviceInstance](ctx, h, "SELECT data FROM service_instances")
+		if err != nil {
+			return err
+		}
+		for _, si := range instances {
+			if si.TeamOwner != oldName && !contains(si.Teams, oldName) {
+				continue
+			}
+			if si.TeamOwner == oldName {
+				si.TeamOwner = newName
+			}
+			si.Teams = renameValue(si.Teams, oldName, newName)
+			err = s.save(ctx, h, si)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *serviceInstanceStorage) Delete(ctx context.Context, serviceName, instanceName string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, service.ErrServiceInstanceNotFound,
+		"DELETE FROM service_instances WHERE service_name = ? AND name = ?", serviceName, instanceName)
+}
@@ -0,0 +1,279 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sqldb implements storage.DbDriver on top of a SQL database. It
+// registers the "postgres" and "sqlite" drivers, selected through the
+// database:driver config, and connects to the data source set by
+// database:sql:url. The schema is kept up to date by the migrations embedded
+// in the package, applied on the first connection.
+//
+// Records are stored as JSON documents alongside the columns used to look
+// them up, mirroring the documents kept by the mongodb driver. Events (except
+// for event blocks), users, jobs and pool constraints aren't part of
+// storage.DbDriver yet and are still kept in MongoDB, which must remain
+// configured.
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/storage"
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	storage.RegisterDbDriver("postgres", newDbDriver(&database{driverName: "postgres"}))
+	storage.RegisterDbDriver("sqlite", newDbDriver(&database{driverName: "sqlite"}))
+}
+
+func newDbDriver(db *database) storage.DbDriver {
+	apps := &appStorage{db: db}
+	return storage.DbDriver{
+		TeamStorage:            &teamStorage{db: db},
+		PlatformStorage:        &platformStorage{db: db},
+		PlatformImageStorage:   &platformImageStorage{db: db},
+		PlanStorage:            &planStorage{db: db},
+		AppCacheStorage:        &cacheStorage{db: db},
+		TeamTokenStorage:       &teamTokenStorage{db: db},
+		PersonalTokenStorage:   &personalTokenStorage{db: db},
+		UserQuotaStorage:       &userQuotaStorage{db: db, userQuota: mongodbUserQuota},
+		AppQuotaStorage:        &appQuotaStorage{apps: apps},
+		TeamQuotaStorage:       &teamQuotaStorage{db: db},
+		WebhookStorage:         &webhookStorage{db: db},
+		WebhookDeliveryStorage: &webhookDeliveryStorage{db: db},
+		ClusterStorage:         &clusterStorage{db: db},
+		InstanceTrackerStorage: &instanceTrackerStorage{db: db},
+		AppVersionStorage:      &appVersionStorage{db: db},
+		DynamicRouterStorage:   &dynamicRouterStorage{db: db},
+		AuthGroupStorage:       &authGroupStorage{db: db},
+		PoolStorage:            &poolStorage{db: db},
+		VolumeStorage:          &volumeStorage{db: db},
+		AppStorage:             apps,
+		EventStorage:           &eventStorage{db: db},
+		RoleStorage:            &roleStorage{db: db},
+		ServiceStorage:         &serviceStorage{db: db},
+		ServiceInstanceStorage: &serviceInstanceStorage{db: db},
+		JobRunStorage:          &jobRunStorage{db: db},
+		WorkflowStorage:        &workflowStorage{db: db},
+		WorkflowRunStorage:     &workflowRunStorage{db: db},
+	}
+}
+
+// database lazily opens the connection pool of a driver, running the
+// pending migrations before it's first used.
+type database struct {
+	driverName string
+
+	mu sync.Mutex
+	db *sql.DB
+}
+
+func (d *database) postgres() bool {
+	return d.driverName == "postgres"
+}
+
+func (d *database) conn(ctx context.Context) (*sql.DB, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db != nil {
+		return d.db, nil
+	}
+	url, err := config.GetString("database:sql:url")
+	if err != nil {
+		return nil, errors.Wrap(err, "database:sql:url is required by the sql drivers")
+	}
+	db, err := sql.Open(d.driverName, url)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !d.postgres() {
+		// SQLite only allows a single writer, sharing one connection avoids
+		// "database is locked" errors on concurrent writes.
+		db.SetMaxOpenConns(1)
+	}
+	err = migrate(ctx, db, d.postgres())
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	d.db = db
+	return d.db, nil
+}
+
+// close closes the connection pool, the next use of the database reopens it.
+func (d *database) close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db == nil {
+		return nil
+	}
+	err := d.db.Close()
+	d.db = nil
+	return err
+}
+
+func (d *database) handle(ctx context.Context) (handle, error) {
+	db, err := d.conn(ctx)
+	if err != nil {
+		return handle{}, err
+	}
+	return handle{q: db, postgres: d.postgres()}, nil
+}
+
+// transaction runs fn inside a transaction, committed if fn returns no
+// error.
+func (d *database) transaction(ctx context.Context, fn func(h handle) error) error {
+	db, err := d.conn(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = fn(handle{q: tx, postgres: d.postgres()})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return errors.WithStack(tx.Commit())
+}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// handle runs queries written with "?" placeholders against either a
+// connection pool or a transaction.
+type handle struct {
+	q        querier
+	postgres bool
+}
+
+func (h handle) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return h.q.ExecContext(ctx, h.rebind(query), args...)
+}
+
+func (h handle) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return h.q.QueryContext(ctx, h.rebind(query), args...)
+}
+
+// rebind replaces "?" placeholders with the numbered ones used by postgres.
+func (h handle) rebind(query string) string {
+	if !h.postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+// execOne runs a statement expected to affect a single row, returning
+// notFound when no row is affected.
+func (h handle) execOne(ctx context.Context, notFound error, query string, args ...interface{}) error {
+	result, err := h.exec(ctx, query, args...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// queryDocs decodes the JSON documents returned in the single column
+// selected by query.
+func queryDocs[T any](ctx context.Context, h handle, query string, args ...interface{}) ([]T, error) {
+	rows, err := h.query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var docs []T
+	for rows.Next() {
+		var data string
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var doc T
+		err = json.Unmarshal([]byte(data), &doc)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, errors.WithStack(rows.Err())
+}
+
+// queryDoc is like queryDocs for queries returning at most one document,
+// returning notFound when there's none.
+func queryDoc[T any](ctx context.Context, h handle, notFound error, query string, args ...interface{}) (*T, error) {
+	docs, err := queryDocs[T](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, notFound
+	}
+	return &docs[0], nil
+}
+
+func marshal(doc interface{}) (string, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(data), nil
+}
+
+// timestamp converts t to the integer stored in time columns, keeping the
+// zero time as 0.
+func timestamp(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// inClause returns a "column IN (?, ...)" condition with its arguments,
+// values must not be empty.
+func inClause(column string, values []string) (string, []interface{}) {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return column + " IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ") + ")", args
+}
+
+func isDuplicateKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "duplicate key value violates unique constraint")
+}
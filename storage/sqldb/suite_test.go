@@ -0,0 +1,53 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+var testDB = &database{driverName: "sqlite"}
+
+type sqliteBaseTest struct{}
+
+func (t *sqliteBaseTest) SetUpSuite(c *check.C) {
+	err := testDB.close()
+	c.Assert(err, check.IsNil)
+	config.Set("database:sql:url", filepath.Join(c.MkDir(), "tsuru.db"))
+}
+
+func (t *sqliteBaseTest) SetUpTest(c *check.C) {
+	ctx := context.TODO()
+	h, err := testDB.handle(ctx)
+	c.Assert(err, check.IsNil)
+	rows, err := h.query(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name <> 'schema_migrations'")
+	c.Assert(err, check.IsNil)
+	var tables []string
+	for rows.Next() {
+		var table string
+		c.Assert(rows.Scan(&table), check.IsNil)
+		tables = append(tables, table)
+	}
+	c.Assert(rows.Close(), check.IsNil)
+	for _, table := range tables {
+		_, err = h.exec(ctx, "DELETE FROM "+table)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (t *sqliteBaseTest) TearDownSuite(c *check.C) {
+	err := testDB.close()
+	c.Assert(err, check.IsNil)
+}
+
+func (t *sqliteBaseTest) TearDownTest(c *check.C) {
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/quota"
+)
+
+type teamStorage struct {
+	db *database
+}
+
+var _ auth.TeamStorage = &teamStorage{}
+
+type team struct {
+	Name         string
+	CreatingUser string
+	Tags         []string
+	Quota        quota.Quota
+}
+
+func (s *teamStorage) Insert(ctx context.Context, t auth.Team) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(team(t))
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO teams (name, data) VALUES (?, ?)", t.Name, data)
+	if isDuplicateKeyError(err) {
+		return auth.ErrTeamAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+func (s *teamStorage) Update(ctx context.Context, t auth.Team) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(team(t))
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, auth.ErrTeamNotFound, "UPDATE teams SET data = ? WHERE name = ?", data, t.Name)
+}
+
+func (s *teamStorage) FindAll(ctx context.Context) ([]auth.Team, error) {
+	return s.findByQuery(ctx, "SELECT data FROM teams ORDER BY name")
+}
+
+func (s *teamStorage) FindByName(ctx context.Context, name string) (*auth.Team, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, err := queryDoc[team](ctx, h, auth.ErrTeamNotFound, "SELECT data FROM teams WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	result := auth.Team(*t)
+	return &result, nil
+}
+
+func (s *teamStorage) FindByNames(ctx context.Context, names []string) ([]auth.Team, error) {
+	if len(names) == 0 {
+		return []auth.Team{}, nil
+	}
+	cond, args := inClause("name", names)
+	return s.findByQuery(ctx, "SELECT data FROM teams WHERE "+cond+" ORDER BY name", args...)
+}
+
+func (s *teamStorage) findByQuery(ctx context.Context, query string, args ...interface{}) ([]auth.Team, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := queryDocs[team](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]auth.Team, len(teams))
+	for i, t := range teams {
+		result[i] = auth.Team(t)
+	}
+	return result, nil
+}
+
+func (s *teamStorage) Delete(ctx context.Context, t auth.Team) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, auth.ErrTeamNotFound, "DELETE FROM teams WHERE name = ?", t.Name)
+}
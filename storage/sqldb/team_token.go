@@ -0,0 +1,135 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/auth"
+)
+
+type teamTokenStorage struct {
+	db *database
+}
+
+var _ auth.TeamTokenStorage = &teamTokenStorage{}
+
+type teamToken struct {
+	Token        string
+	TokenID      string
+	Description  string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	LastAccess   time.Time
+	CreatorEmail string
+	Team         string
+	Roles        []auth.RoleInstance
+
+	Constraints *auth.TeamTokenConstraints
+}
+
+func (s *teamTokenStorage) Insert(ctx context.Context, t auth.TeamToken) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(teamToken(t))
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO team_tokens (token, token_id, team, data) VALUES (?, ?, ?, ?)", t.Token, t.TokenID, t.Team, data)
+	if isDuplicateKeyError(err) {
+		return auth.ErrTeamTokenAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+func (s *teamTokenStorage) findOne(ctx context.Context, h handle, query string, args ...interface{}) (*auth.TeamToken, error) {
+	t, err := queryDoc[teamToken](ctx, h, auth.ErrTeamTokenNotFound, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := auth.TeamToken(*t)
+	return &result, nil
+}
+
+func (s *teamTokenStorage) FindByToken(ctx context.Context, token string) (*auth.TeamToken, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.findOne(ctx, h, "SELECT data FROM team_tokens WHERE token = ?", token)
+}
+
+func (s *teamTokenStorage) FindByTokenID(ctx context.Context, tokenID string) (*auth.TeamToken, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.findOne(ctx, h, "SELECT data FROM team_tokens WHERE token_id = ?", tokenID)
+}
+
+func (s *teamTokenStorage) FindByTeams(ctx context.Context, teamNames []string) ([]auth.TeamToken, error) {
+	query := "SELECT data FROM team_tokens"
+	var args []interface{}
+	if teamNames != nil {
+		if len(teamNames) == 0 {
+			return []auth.TeamToken{}, nil
+		}
+		var cond string
+		cond, args = inClause("team", teamNames)
+		query += " WHERE " + cond
+	}
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := queryDocs[teamToken](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]auth.TeamToken, len(tokens))
+	for i, t := range tokens {
+		result[i] = auth.TeamToken(t)
+	}
+	return result, nil
+}
+
+func (s *teamTokenStorage) UpdateLastAccess(ctx context.Context, token string) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		t, err := s.findOne(ctx, h, "SELECT data FROM team_tokens WHERE token = ?", token)
+		if err != nil {
+			return err
+		}
+		t.LastAccess = time.Now().UTC()
+		return s.update(ctx, h, *t)
+	})
+}
+
+func (s *teamTokenStorage) Update(ctx context.Context, token auth.TeamToken) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, h, token)
+}
+
+func (s *teamTokenStorage) update(ctx context.Context, h handle, t auth.TeamToken) error {
+	data, err := marshal(teamToken(t))
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, auth.ErrTeamTokenNotFound, "UPDATE team_tokens SET token = ?, team = ?, data = ? WHERE token_id = ?", t.Token, t.Team, data, t.TokenID)
+}
+
+func (s *teamTokenStorage) Delete(ctx context.Context, tokenID string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, auth.ErrTeamTokenNotFound, "DELETE FROM team_tokens WHERE token_id = ?", tokenID)
+}
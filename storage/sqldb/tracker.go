@@ -0,0 +1,44 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/tracker"
+)
+
+type instanceTrackerStorage struct {
+	db *database
+}
+
+var _ tracker.InstanceStorage = &instanceTrackerStorage{}
+
+func (s *instanceTrackerStorage) Notify(ctx context.Context, instance tracker.TrackedInstance) error {
+	instance.LastUpdate = time.Now().UTC()
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(instance)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO tracked_instances (name, last_update, data) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET last_update = excluded.last_update, data = excluded.data`,
+		instance.Name, timestamp(instance.LastUpdate), data)
+	return errors.WithStack(err)
+}
+
+func (s *instanceTrackerStorage) List(ctx context.Context, maxStale time.Duration) ([]tracker.TrackedInstance, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryDocs[tracker.TrackedInstance](ctx, h, "SELECT data FROM tracked_instances WHERE last_update > ? ORDER BY name",
+		time.Now().UTC().Add(-maxStale).UnixNano())
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/storage/storagetest"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+// userStorage stands for the users kept in MongoDB by the auth package.
+type userStorage map[string]auth.User
+
+func (s userStorage) Create(user *auth.User) error {
+	s[user.Email] = *user
+	return nil
+}
+
+func (s userStorage) Remove(user *auth.User) error {
+	delete(s, user.Email)
+	return nil
+}
+
+func (s userStorage) quota(ctx context.Context, email string) (*quota.Quota, error) {
+	user, ok := s[email]
+	if !ok {
+		return nil, quota.ErrQuotaNotFound
+	}
+	return &user.Quota, nil
+}
+
+var users = userStorage{}
+
+var _ = check.Suite(&storagetest.UserQuotaSuite{
+	UserStorage:      users,
+	UserQuotaStorage: &userQuotaStorage{db: testDB, userQuota: users.quota},
+	SuiteHooks:       &sqliteBaseTest{},
+})
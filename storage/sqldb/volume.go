@@ -0,0 +1,203 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/volume"
+)
+
+type volumeStorage struct {
+	db *database
+}
+
+var _ volume.VolumeStorage = &volumeStorage{}
+
+// vol leaves out the binds of the volume, which are kept in their own table.
+type vol struct {
+	Name      string
+	Pool      string
+	Plan      volume.VolumePlan
+	TeamOwner string
+	Status    string
+	Opts      map[string]string
+}
+
+func (s *volumeStorage) Save(ctx context.Context, v *volume.Volume) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return s.save(ctx, h, vol{
+		Name:      v.Name,
+		Pool:      v.Pool,
+		Plan:      v.Plan,
+		TeamOwner: v.TeamOwner,
+		Status:    v.Status,
+		Opts:      v.Opts,
+	})
+}
+
+func (s *volumeStorage) save(ctx context.Context, h handle, v vol) error {
+	data, err := marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO volumes (name, pool, team_owner, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET pool = excluded.pool, team_owner = excluded.team_owner, data = excluded.data`,
+		v.Name, v.Pool, v.TeamOwner, data)
+	return errors.WithStack(err)
+}
+
+func (s *volumeStorage) Delete(ctx context.Context, v *volume.Volume) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "DELETE FROM volumes WHERE name = ?", v.Name)
+	return errors.WithStack(err)
+}
+
+func (s *volumeStorage) Get(ctx context.Context, name string) (*volume.Volume, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, err := queryDoc[vol](ctx, h, volume.ErrVolumeNotFound, "SELECT data FROM volumes WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	result := v.toVolume()
+	return &result, nil
+}
+
+// ListByFilter returns the volumes matching any of the names, pools or
+// teams in the filter, or every volume when f is nil.
+func (s *volumeStorage) ListByFilter(ctx context.Context, f *volume.Filter) ([]volume.Volume, error) {
+	query := "SELECT data FROM volumes"
+	var args []interface{}
+	if f != nil {
+		var conds []string
+		for _, filter := range []struct {
+			column string
+			values []string
+		}{
+			{column: "name", values: f.Names},
+			{column: "pool", values: f.Pools},
+			{column: "team_owner", values: f.Teams},
+		} {
+			if len(filter.values) == 0 {
+				continue
+			}
+			cond, condArgs := inClause(filter.column, filter.values)
+			conds = append(conds, cond)
+			args = append(args, condArgs...)
+		}
+		if len(conds) == 0 {
+			return nil, nil
+		}
+		query += " WHERE " + strings.Join(conds, " OR ")
+	}
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vols, err := queryDocs[vol](ctx, h, query+" ORDER BY name", args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]volume.Volume, len(vols))
+	for i, v := range vols {
+		result[i] = v.toVolume()
+	}
+	return result, nil
+}
+
+func (v vol) toVolume() volume.Volume {
+	return volume.Volume{
+		Name:      v.Name,
+		Pool:      v.Pool,
+		Plan:      v.Plan,
+		TeamOwner: v.TeamOwner,
+		Status:    v.Status,
+		Opts:      v.Opts,
+	}
+}
+
+func (s *volumeStorage) InsertBind(ctx context.Context, b *volume.VolumeBind) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO volume_binds (app, mount_point, volume, read_only) VALUES (?, ?, ?, ?)",
+		b.ID.App, b.ID.MountPoint, b.ID.Volume, b.ReadOnly)
+	if isDuplicateKeyError(err) {
+		return volume.ErrVolumeAlreadyBound
+	}
+	return errors.WithStack(err)
+}
+
+func (s *volumeStorage) RemoveBind(ctx context.Context, id volume.VolumeBindID) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, volume.ErrVolumeBindNotFound,
+		"DELETE FROM volume_binds WHERE app = ? AND mount_point = ? AND volume = ?", id.App, id.MountPoint, id.Volume)
+}
+
+func (s *volumeStorage) Binds(ctx context.Context, volumeName string) ([]volume.VolumeBind, error) {
+	return s.findBinds(ctx, "volume = ?", volumeName)
+}
+
+func (s *volumeStorage) BindsForApp(ctx context.Context, volumeName, appName string) ([]volume.VolumeBind, error) {
+	if volumeName == "" {
+		return s.findBinds(ctx, "app = ?", appName)
+	}
+	return s.findBinds(ctx, "app = ? AND volume = ?", appName, volumeName)
+}
+
+func (s *volumeStorage) findBinds(ctx context.Context, cond string, args ...interface{}) ([]volume.VolumeBind, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.query(ctx, "SELECT app, mount_point, volume, read_only FROM volume_binds WHERE "+cond+
+		" ORDER BY app, volume, mount_point", args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var binds []volume.VolumeBind
+	for rows.Next() {
+		var b volume.VolumeBind
+		err = rows.Scan(&b.ID.App, &b.ID.MountPoint, &b.ID.Volume, &b.ReadOnly)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		binds = append(binds, b)
+	}
+	return binds, errors.WithStack(rows.Err())
+}
+
+func (s *volumeStorage) RenameTeam(ctx context.Context, oldName, newName string) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		vols, err := queryDocs[vol](ctx, h, "SELECT data FROM volumes WHERE team_owner = ?", oldName)
+		if err != nil {
+			return err
+		}
+		for _, v := range vols {
+			v.TeamOwner = newName
+			err = s.save(ctx, h, v)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
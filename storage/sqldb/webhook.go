@@ -0,0 +1,180 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/event"
+)
+
+type webhookStorage struct {
+	db *database
+}
+
+var _ event.WebhookStorage = &webhookStorage{}
+
+type webhook struct {
+	Name        string
+	Description string
+	TeamOwner   string
+	EventFilter event.WebhookEventFilter
+	URL         string
+	ProxyURL    string
+	Headers     http.Header
+	Method      string
+	Body        string
+	Insecure    bool
+	RetryPolicy event.WebhookRetryPolicy
+
+	Secret       string
+	RemoveSecret bool `json:"-"`
+	HasSecret    bool `json:"-"`
+
+	PreviousSecret          string
+	PreviousSecretExpiresAt time.Time
+}
+
+func (w webhook) toWebhook() event.Webhook {
+	result := event.Webhook(w)
+	if result.Headers == nil {
+		result.Headers = http.Header{}
+	}
+	f := &result.EventFilter
+	for _, values := range []*[]string{&f.TargetTypes, &f.TargetValues, &f.KindTypes, &f.KindNames} {
+		if *values == nil {
+			*values = []string{}
+		}
+	}
+	return result
+}
+
+func (s *webhookStorage) Insert(ctx context.Context, w event.Webhook) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(webhook(w))
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "INSERT INTO webhooks (name, team_owner, data) VALUES (?, ?, ?)", w.Name, w.TeamOwner, data)
+	if isDuplicateKeyError(err) {
+		return event.ErrWebhookAlreadyExists
+	}
+	return errors.WithStack(err)
+}
+
+func (s *webhookStorage) Update(ctx context.Context, w event.Webhook) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(webhook(w))
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, event.ErrWebhookNotFound, "UPDATE webhooks SET team_owner = ?, data = ? WHERE name = ?", w.TeamOwner, data, w.Name)
+}
+
+func (s *webhookStorage) findByQuery(ctx context.Context, query string, args ...interface{}) ([]event.Webhook, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := queryDocs[webhook](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]event.Webhook, len(webhooks))
+	for i, w := range webhooks {
+		result[i] = w.toWebhook()
+	}
+	return result, nil
+}
+
+func (s *webhookStorage) FindAllByTeams(ctx context.Context, teams []string) ([]event.Webhook, error) {
+	if teams == nil {
+		return s.findByQuery(ctx, "SELECT data FROM webhooks ORDER BY name")
+	}
+	if len(teams) == 0 {
+		return nil, nil
+	}
+	cond, args := inClause("team_owner", teams)
+	return s.findByQuery(ctx, "SELECT data FROM webhooks WHERE "+cond+" ORDER BY name", args...)
+}
+
+// FindByEvent returns the webhooks whose filters match the event. Filters
+// are matched in memory, as each of their lists either matches any value
+// when empty or must share a value with the event.
+func (s *webhookStorage) FindByEvent(ctx context.Context, f event.WebhookEventFilter, isSuccess bool) ([]event.Webhook, error) {
+	for _, name := range f.KindNames {
+		parts := strings.Split(name, ".")
+		parts = parts[:len(parts)-1]
+		for i := 1; i < len(parts); i++ {
+			parts[i] = parts[i-1] + "." + parts[i]
+		}
+		f.KindNames = append(f.KindNames, parts...)
+	}
+	webhooks, err := s.findByQuery(ctx, "SELECT data FROM webhooks ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	var result []event.Webhook
+	for _, w := range webhooks {
+		wf := w.EventFilter
+		if !matchAny(wf.TargetTypes, f.TargetTypes) ||
+			!matchAny(wf.TargetValues, f.TargetValues) ||
+			!matchAny(wf.KindTypes, f.KindTypes) ||
+			!matchAny(wf.KindNames, f.KindNames) {
+			continue
+		}
+		if (isSuccess && wf.ErrorOnly) || (!isSuccess && wf.SuccessOnly) {
+			continue
+		}
+		result = append(result, w)
+	}
+	return result, nil
+}
+
+// matchAny reports whether filter is empty or has any of values.
+func matchAny(filter, values []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		for _, v := range values {
+			if f == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *webhookStorage) FindByName(ctx context.Context, name string) (*event.Webhook, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w, err := queryDoc[webhook](ctx, h, event.ErrWebhookNotFound, "SELECT data FROM webhooks WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	result := w.toWebhook()
+	return &result, nil
+}
+
+func (s *webhookStorage) Delete(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return h.execOne(ctx, event.ErrWebhookNotFound, "DELETE FROM webhooks WHERE name = ?", name)
+}
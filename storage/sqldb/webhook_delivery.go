@@ -0,0 +1,233 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqldb
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/event"
+)
+
+type webhookDeliveryStorage struct {
+	db *database
+}
+
+var _ event.WebhookDeliveryStorage = &webhookDeliveryStorage{}
+
+type webhookDelivery struct {
+	ID            string
+	WebhookName   string
+	EventID       string
+	Status        event.WebhookDeliveryStatus
+	Attempts      int
+	MaxAttempts   int
+	LastError     string
+	History       []event.WebhookDeliveryAttempt
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	NextAttemptAt time.Time
+	LockedUntil   time.Time
+	ExpireAt      time.Time
+}
+
+// notExpiredDelivery filters out deliveries past their expiration, which
+// are only removed on the next Insert.
+const notExpiredDelivery = "(expire_at = 0 OR expire_at > ?)"
+
+func deliveryFilterQuery(f event.WebhookDeliveryFilter) (string, []interface{}) {
+	conds := []string{notExpiredDelivery}
+	args := []interface{}{time.Now().UnixNano()}
+	if f.WebhookName != "" {
+		conds = append(conds, "webhook_name = ?")
+		args = append(args, f.WebhookName)
+	}
+	if f.ID != "" {
+		conds = append(conds, "id = ?")
+		args = append(args, f.ID)
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, string(f.Status))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (s *webhookDeliveryStorage) Insert(ctx context.Context, d event.WebhookDelivery) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "DELETE FROM webhook_deliveries WHERE expire_at > 0 AND expire_at <= ?", time.Now().UnixNano())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	data, err := marshal(webhookDelivery(d))
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, `INSERT INTO webhook_deliveries
+		(id, webhook_name, status, created_at, next_attempt_at, locked_until, expire_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.WebhookName, string(d.Status), timestamp(d.CreatedAt), timestamp(d.NextAttemptAt),
+		timestamp(d.LockedUntil), timestamp(d.ExpireAt), data)
+	return errors.WithStack(err)
+}
+
+func (s *webhookDeliveryStorage) Update(ctx context.Context, d event.WebhookDelivery) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, h, d)
+}
+
+func (s *webhookDeliveryStorage) update(ctx context.Context, h handle, d event.WebhookDelivery) error {
+	return s.updateWhere(ctx, h, d, "id = ?", d.ID)
+}
+
+func (s *webhookDeliveryStorage) updateWhere(ctx context.Context, h handle, d event.WebhookDelivery, cond string, condArgs ...interface{}) error {
+	data, err := marshal(webhookDelivery(d))
+	if err != nil {
+		return err
+	}
+	args := []interface{}{
+		d.WebhookName, string(d.Status), timestamp(d.CreatedAt), timestamp(d.NextAttemptAt),
+		timestamp(d.LockedUntil), timestamp(d.ExpireAt), data,
+	}
+	return h.execOne(ctx, event.ErrWebhookDeliveryNotFound, `UPDATE webhook_deliveries SET webhook_name = ?,
+		status = ?, created_at = ?, next_attempt_at = ?, locked_until = ?, expire_at = ?, data = ?
+		WHERE `+cond, append(args, condArgs...)...)
+}
+
+func (s *webhookDeliveryStorage) FindByID(ctx context.Context, id string) (*event.WebhookDelivery, error) {
+	deliveries, err := s.Find(ctx, event.WebhookDeliveryFilter{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, event.ErrWebhookDeliveryNotFound
+	}
+	return &deliveries[0], nil
+}
+
+func (s *webhookDeliveryStorage) Find(ctx context.Context, f event.WebhookDeliveryFilter) ([]event.WebhookDelivery, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.find(ctx, h, f)
+}
+
+func (s *webhookDeliveryStorage) find(ctx context.Context, h handle, f event.WebhookDeliveryFilter) ([]event.WebhookDelivery, error) {
+	cond, args := deliveryFilterQuery(f)
+	query := "SELECT data FROM webhook_deliveries WHERE " + cond + " ORDER BY created_at DESC"
+	if f.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(f.Limit)
+	}
+	deliveries, err := queryDocs[webhookDelivery](ctx, h, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]event.WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = event.WebhookDelivery(d)
+	}
+	return result, nil
+}
+
+// AcquireNext locks the next due delivery with an update conditioned on its
+// previous lock, retrying with the following one when another process
+// acquires it first.
+func (s *webhookDeliveryStorage) AcquireNext(ctx context.Context, lockFor time.Duration) (*event.WebhookDelivery, error) {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		now := time.Now().UTC()
+		d, err := queryDoc[webhookDelivery](ctx, h, event.ErrWebhookDeliveryNotFound, `SELECT data FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ? AND locked_until <= ?
+			ORDER BY next_attempt_at LIMIT 1`,
+			string(event.WebhookDeliveryPending), now.UnixNano(), now.UnixNano())
+		if err != nil {
+			return nil, err
+		}
+		previousLock := timestamp(d.LockedUntil)
+		d.LockedUntil = now.Add(lockFor)
+		result := event.WebhookDelivery(*d)
+		err = s.updateWhere(ctx, h, result, "id = ? AND locked_until = ?", result.ID, previousLock)
+		if err == nil {
+			return &result, nil
+		}
+		if err != event.ErrWebhookDeliveryNotFound {
+			return nil, err
+		}
+	}
+}
+
+func (s *webhookDeliveryStorage) Redrive(ctx context.Context, f event.WebhookDeliveryFilter) (int, error) {
+	f.Status = event.WebhookDeliveryDead
+	count := 0
+	err := s.db.transaction(ctx, func(h handle) error {
+		deliveries, err := s.find(ctx, h, f)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		for _, d := range deliveries {
+			d.Status = event.WebhookDeliveryPending
+			d.Attempts = 0
+			d.NextAttemptAt = now
+			d.UpdatedAt = now
+			d.LockedUntil = time.Time{}
+			d.ExpireAt = time.Time{}
+			err = s.update(ctx, h, d)
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *webhookDeliveryStorage) Prune(ctx context.Context, webhookName string, keep int) error {
+	return s.db.transaction(ctx, func(h handle) error {
+		deliveries, err := s.find(ctx, h, event.WebhookDeliveryFilter{
+			WebhookName: webhookName,
+			Status:      event.WebhookDeliverySucceeded,
+		})
+		if err != nil {
+			return err
+		}
+		if len(deliveries) <= keep {
+			return nil
+		}
+		ids := make([]string, 0, len(deliveries)-keep)
+		for _, d := range deliveries[keep:] {
+			ids = append(ids, d.ID)
+		}
+		cond, args := inClause("id", ids)
+		_, err = h.exec(ctx, "DELETE FROM webhook_deliveries WHERE "+cond, args...)
+		return errors.WithStack(err)
+	})
+}
+
+func (s *webhookDeliveryStorage) DeleteByWebhook(ctx context.Context, name string) error {
+	h, err := s.db.handle(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.exec(ctx, "DELETE FROM webhook_deliveries WHERE webhook_name = ?", name)
+	return errors.WithStack(err)
+}
@@ -12,20 +12,15 @@ import (
 	check "gopkg.in/check.v1"
 )
 
-type appStorage interface {
-	Create(context.Context, *appTypes.App) error
-	Remove(context.Context, *appTypes.App) error
-}
-
 type AppQuotaSuite struct {
 	SuiteHooks
-	AppStorage      appStorage
+	AppStorage      appTypes.AppStorage
 	AppQuotaStorage quota.QuotaStorage
 }
 
 func (s *AppQuotaSuite) TestGet(c *check.C) {
 	app := &appTypes.App{Name: "myapp", Quota: quota.UnlimitedQuota}
-	s.AppStorage.Create(context.TODO(), app)
+	s.AppStorage.Insert(context.TODO(), app)
 	quota, err := s.AppQuotaStorage.Get(context.TODO(), "myapp")
 	c.Assert(err, check.IsNil)
 	c.Assert(quota.InUse, check.Equals, 0)
@@ -40,7 +35,7 @@ func (s *AppQuotaSuite) TestGetNotFound(c *check.C) {
 
 func (s *AppQuotaSuite) TestSetLimit(c *check.C) {
 	app := &appTypes.App{Name: "myapp", Quota: quota.Quota{Limit: 5, InUse: 0}}
-	s.AppStorage.Create(context.TODO(), app)
+	s.AppStorage.Insert(context.TODO(), app)
 	err := s.AppQuotaStorage.SetLimit(context.TODO(), "myapp", 1)
 	c.Assert(err, check.IsNil)
 	quota, err := s.AppQuotaStorage.Get(context.TODO(), "myapp")
@@ -57,7 +52,7 @@ func (s *AppQuotaSuite) TestSetLimitNotFound(c *check.C) {
 
 func (s *AppQuotaSuite) TestSet(c *check.C) {
 	app := &appTypes.App{Name: "myapp", Quota: quota.Quota{Limit: 5, InUse: 0}}
-	s.AppStorage.Create(context.TODO(), app)
+	s.AppStorage.Insert(context.TODO(), app)
 	err := s.AppQuotaStorage.Set(context.TODO(), "myapp", 3)
 	c.Assert(err, check.IsNil)
 	quota, err := s.AppQuotaStorage.Get(context.TODO(), "myapp")
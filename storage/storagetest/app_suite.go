@@ -0,0 +1,311 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/envs/encryption"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+type AppSuite struct {
+	SuiteHooks
+	AppStorage appTypes.AppStorage
+}
+
+func (s *AppSuite) TestInsertAndFindByName(c *check.C) {
+	app := &appTypes.App{Name: "myapp", TeamOwner: "dev", Teams: []string{"dev"}, Platform: "python"}
+	err := s.AppStorage.Insert(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Name, check.Equals, "myapp")
+	c.Assert(dbApp.TeamOwner, check.Equals, "dev")
+	c.Assert(dbApp.Teams, check.DeepEquals, []string{"dev"})
+	c.Assert(dbApp.Platform, check.Equals, "python")
+	_, err = s.AppStorage.FindByName(context.TODO(), "unknown")
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+}
+
+func (s *AppSuite) TestUpdate(c *check.C) {
+	app := &appTypes.App{Name: "myapp", Teams: []string{"dev"}}
+	err := s.AppStorage.Insert(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	app.Description = "my app"
+	app.Pool = "pool1"
+	err = s.AppStorage.Update(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "my app")
+	c.Assert(dbApp.Pool, check.Equals, "pool1")
+}
+
+func (s *AppSuite) TestFindNamesByTeam(c *check.C) {
+	apps := []*appTypes.App{
+		{Name: "app1", Teams: []string{"dev"}},
+		{Name: "app2", Teams: []string{"ops", "dev"}},
+		{Name: "app3", Teams: []string{"ops"}},
+	}
+	for _, app := range apps {
+		err := s.AppStorage.Insert(context.TODO(), app)
+		c.Assert(err, check.IsNil)
+	}
+	names, err := s.AppStorage.FindNamesByTeam(context.TODO(), "dev")
+	c.Assert(err, check.IsNil)
+	sort.Strings(names)
+	c.Assert(names, check.DeepEquals, []string{"app1", "app2"})
+	names, err = s.AppStorage.FindNamesByTeam(context.TODO(), "unknown")
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.HasLen, 0)
+}
+
+func (s *AppSuite) TestAddRemoveTeam(c *check.C) {
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "myapp", Teams: []string{"dev"}})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.AddTeam(context.TODO(), "myapp", "ops")
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.AddTeam(context.TODO(), "myapp", "ops")
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Teams, check.DeepEquals, []string{"dev", "ops"})
+	err = s.AppStorage.RemoveTeam(context.TODO(), "myapp", "dev")
+	c.Assert(err, check.IsNil)
+	dbApp, err = s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Teams, check.DeepEquals, []string{"ops"})
+}
+
+func (s *AppSuite) TestSetEnvs(c *check.C) {
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	envs := map[string]bindTypes.EnvVar{"DATABASE_HOST": {Name: "DATABASE_HOST", Value: "localhost", Public: true}}
+	err = s.AppStorage.SetEnvs(context.TODO(), "myapp", envs)
+	c.Assert(err, check.IsNil)
+	serviceEnvs := []bindTypes.ServiceEnvVar{
+		{EnvVar: bindTypes.EnvVar{Name: "MYSQL_HOST", Value: "mysql"}, ServiceName: "mysql", InstanceName: "db1"},
+	}
+	err = s.AppStorage.SetServiceEnvs(context.TODO(), "myapp", serviceEnvs)
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.DeepEquals, envs)
+	c.Assert(dbApp.ServiceEnvs, check.DeepEquals, serviceEnvs)
+}
+
+func (s *AppSuite) TestReencryptEnvs(c *check.C) {
+	envs := map[string]bindTypes.EnvVar{
+		"SECRET": {Name: "SECRET", Value: "s3cr3t"},
+		"PUBLIC": {Name: "PUBLIC", Value: "visible", Public: true},
+	}
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "myapp", Env: envs})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "publicapp", Env: map[string]bindTypes.EnvVar{
+		"PUBLIC": {Name: "PUBLIC", Value: "visible", Public: true},
+	}})
+	c.Assert(err, check.IsNil)
+	keyfile := filepath.Join(c.MkDir(), "keyfile")
+	config.Set("envs:encryption:provider", "local")
+	config.Set("envs:encryption:local:keyfile", keyfile)
+	defer func() {
+		config.Unset("envs:encryption")
+		encryption.Reset()
+	}()
+	_, err = encryption.AddLocalKey(keyfile)
+	c.Assert(err, check.IsNil)
+	encryption.Reset()
+	count, err := s.AppStorage.ReencryptEnvs(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, 1)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.DeepEquals, envs)
+}
+
+func (s *AppSuite) TestSetUpdatePlatformAndIncrementDeploys(c *check.C) {
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.SetUpdatePlatform(context.TODO(), "myapp", true)
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.IncrementDeploys(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.IncrementDeploys(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.UpdatePlatform, check.Equals, true)
+	c.Assert(dbApp.Deploys, check.Equals, uint(2))
+}
+
+func (s *AppSuite) TestDelete(c *check.C) {
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.Delete(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	_, err = s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+	err = s.AppStorage.Delete(context.TODO(), "myapp")
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+}
+
+func (s *AppSuite) appNames(apps []appTypes.App) []string {
+	names := []string{}
+	for _, a := range apps {
+		names = append(names, a.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *AppSuite) TestList(c *check.C) {
+	apps := []*appTypes.App{
+		{Name: "app1", TeamOwner: "dev", Teams: []string{"dev"}, Pool: "pool1", Owner: "me@example.com", Platform: "python", Tags: []string{"a", "b"}},
+		{Name: "app2", TeamOwner: "ops", Teams: []string{"ops", "dev"}, Pool: "pool2", Platform: "python", PlatformVersion: "v2", Tags: []string{"a"}},
+		{Name: "other", TeamOwner: "ops", Teams: []string{"ops"}, Pool: "pool2", Platform: "go"},
+	}
+	for _, app := range apps {
+		err := s.AppStorage.Insert(context.TODO(), app)
+		c.Assert(err, check.IsNil)
+	}
+	tests := []struct {
+		filter   *appTypes.Filter
+		expected []string
+	}{
+		{nil, []string{"app1", "app2", "other"}},
+		{&appTypes.Filter{Name: "app2"}, []string{"app2"}},
+		{&appTypes.Filter{NameMatches: "^app"}, []string{"app1", "app2"}},
+		{&appTypes.Filter{TeamOwner: "ops"}, []string{"app2", "other"}},
+		{&appTypes.Filter{UserOwner: "me@example.com"}, []string{"app1"}},
+		{&appTypes.Filter{Pool: "pool2"}, []string{"app2", "other"}},
+		{&appTypes.Filter{Pools: []string{"pool1", "pool2"}}, []string{"app1", "app2", "other"}},
+		{&appTypes.Filter{Platform: "python"}, []string{"app1", "app2"}},
+		{&appTypes.Filter{Platform: "python:latest"}, []string{"app1"}},
+		{&appTypes.Filter{Platform: "python:v2"}, []string{"app2"}},
+		{&appTypes.Filter{Tags: []string{"a", "b"}}, []string{"app1"}},
+		{&appTypes.Filter{Extra: map[string][]string{"teams": {"dev"}}}, []string{"app1", "app2"}},
+		{&appTypes.Filter{Extra: map[string][]string{"teams": {"dev"}, "name": {"other"}}}, []string{"app1", "app2", "other"}},
+		{&appTypes.Filter{Extra: map[string][]string{"teamowner": {"dev"}, "pool": {"pool2"}}, Platform: "python"}, []string{"app1", "app2"}},
+	}
+	for i, tt := range tests {
+		result, err := s.AppStorage.List(context.TODO(), tt.filter)
+		c.Assert(err, check.IsNil)
+		c.Check(s.appNames(result), check.DeepEquals, tt.expected, check.Commentf("(%d)", i))
+	}
+}
+
+func (s *AppSuite) TestCNames(c *check.C) {
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "app1", CName: []string{"a.example.com"}})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "app2"})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.AddCNames(context.TODO(), "app2", []string{"a.example.com", "b.example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.AddCNames(context.TODO(), "app2", []string{"b.example.com"})
+	c.Assert(err, check.IsNil)
+	apps, err := s.AppStorage.FindByCName(context.TODO(), "a.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(s.appNames(apps), check.DeepEquals, []string{"app1", "app2"})
+	err = s.AppStorage.RemoveCNames(context.TODO(), "app2", []string{"a.example.com"})
+	c.Assert(err, check.IsNil)
+	apps, err = s.AppStorage.FindByCName(context.TODO(), "a.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(s.appNames(apps), check.DeepEquals, []string{"app1"})
+	apps, err = s.AppStorage.FindByCName(context.TODO(), "b.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.HasLen, 1)
+	c.Assert(apps[0].CName, check.DeepEquals, []string{"b.example.com"})
+	apps, err = s.AppStorage.FindByCName(context.TODO(), "c.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.HasLen, 0)
+}
+
+func (s *AppSuite) TestCertIssuers(c *check.C) {
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.SetCertIssuer(context.TODO(), "myapp", "a.example.com", "letsencrypt")
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.SetCertIssuer(context.TODO(), "myapp", "b.example.com", "internal")
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.CertIssuers, check.DeepEquals, appTypes.CertIssuers{"a.example.com": "letsencrypt", "b.example.com": "internal"})
+	err = s.AppStorage.RemoveCertIssuer(context.TODO(), "myapp", "a.example.com")
+	c.Assert(err, check.IsNil)
+	dbApp, err = s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.CertIssuers, check.DeepEquals, appTypes.CertIssuers{"b.example.com": "internal"})
+}
+
+func (s *AppSuite) TestSetRouters(c *check.C) {
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "myapp", Router: "legacy", RouterOpts: map[string]string{"a": "b"}})
+	c.Assert(err, check.IsNil)
+	routers := []appTypes.AppRouter{{Name: "r1", Opts: map[string]string{"x": "y"}}}
+	err = s.AppStorage.SetRouters(context.TODO(), "myapp", routers)
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Routers, check.DeepEquals, routers)
+	c.Assert(dbApp.Router, check.Equals, "")
+	c.Assert(dbApp.RouterOpts, check.HasLen, 0)
+}
+
+func (s *AppSuite) TestSetRoutingWeightsAndAutoRollback(c *check.C) {
+	err := s.AppStorage.Insert(context.TODO(), &appTypes.App{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.SetRoutingWeights(context.TODO(), "myapp", map[int]int{1: 90, 2: 10})
+	c.Assert(err, check.IsNil)
+	cfg := &appTypes.AutoRollback{Enabled: true, Window: time.Minute, MaxRestarts: 2}
+	err = s.AppStorage.SetAutoRollback(context.TODO(), "myapp", cfg)
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.RoutingWeights, check.DeepEquals, map[int]int{1: 90, 2: 10})
+	c.Assert(dbApp.AutoRollback, check.DeepEquals, cfg)
+	err = s.AppStorage.SetRoutingWeights(context.TODO(), "myapp", nil)
+	c.Assert(err, check.IsNil)
+	err = s.AppStorage.SetAutoRollback(context.TODO(), "myapp", nil)
+	c.Assert(err, check.IsNil)
+	dbApp, err = s.AppStorage.FindByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.RoutingWeights, check.HasLen, 0)
+	c.Assert(dbApp.AutoRollback, check.IsNil)
+}
+
+func (s *AppSuite) TestRenameTeam(c *check.C) {
+	apps := []*appTypes.App{
+		{Name: "app1", TeamOwner: "dev", Teams: []string{"dev"}},
+		{Name: "app2", TeamOwner: "ops", Teams: []string{"ops", "dev"}},
+		{Name: "app3", TeamOwner: "ops", Teams: []string{"ops"}},
+	}
+	for _, app := range apps {
+		err := s.AppStorage.Insert(context.TODO(), app)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.AppStorage.RenameTeam(context.TODO(), "dev", "devs")
+	c.Assert(err, check.IsNil)
+	dbApp, err := s.AppStorage.FindByName(context.TODO(), "app1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TeamOwner, check.Equals, "devs")
+	c.Assert(dbApp.Teams, check.DeepEquals, []string{"devs"})
+	dbApp, err = s.AppStorage.FindByName(context.TODO(), "app2")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TeamOwner, check.Equals, "ops")
+	c.Assert(dbApp.Teams, check.DeepEquals, []string{"ops", "devs"})
+	names, err := s.AppStorage.FindNamesByTeam(context.TODO(), "devs")
+	c.Assert(err, check.IsNil)
+	sort.Strings(names)
+	c.Assert(names, check.DeepEquals, []string{"app1", "app2"})
+	names, err = s.AppStorage.FindNamesByTeam(context.TODO(), "dev")
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.HasLen, 0)
+}
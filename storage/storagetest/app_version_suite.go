@@ -59,6 +59,46 @@ func (s *AppVersionSuite) TestAppVersionStorage_UpdateVersionSuccess(c *check.C)
 	c.Assert(updatedVersions.UpdatedAt.Unix(), check.Equals, vi2.UpdatedAt.Unix())
 }
 
+func (s *AppVersionSuite) TestAppVersionStorage_UpdateBuildPhases(c *check.C) {
+	phases := []appTypes.BuildPhase{
+		{Name: appTypes.BuildPhaseBuild, Status: appTypes.BuildPhaseSucceeded, EventID: "evt1"},
+		{Name: appTypes.BuildPhaseRollout, Status: appTypes.BuildPhaseRunning, EventID: "evt1"},
+	}
+	err := s.AppVersionStorage.UpdateBuildPhases(context.TODO(), "myapp", 1, phases)
+	c.Assert(err, check.Equals, appTypes.ErrNoVersionsAvailable)
+
+	app := &appTypes.App{Name: "myapp"}
+	vi1, err := s.AppVersionStorage.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{App: app})
+	c.Assert(err, check.IsNil)
+	vi2, err := s.AppVersionStorage.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{App: app})
+	c.Assert(err, check.IsNil)
+	vi1.Disabled = true
+	err = s.AppVersionStorage.UpdateVersion(context.TODO(), "myapp", vi1)
+	c.Assert(err, check.IsNil)
+	versions, err := s.AppVersionStorage.AppVersions(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	err = s.AppVersionStorage.UpdateBuildPhases(context.TODO(), "myapp", 3, phases)
+	c.Assert(err, check.Equals, appTypes.ErrNoVersionsAvailable)
+	err = s.AppVersionStorage.UpdateBuildPhases(context.TODO(), "myapp", vi1.Version, phases)
+	c.Assert(err, check.IsNil)
+	updatedVersions, err := s.AppVersionStorage.AppVersions(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	c.Assert(updatedVersions.UpdatedHash, check.Not(check.Equals), versions.UpdatedHash)
+	c.Assert(updatedVersions.Versions, check.HasLen, 2)
+	updated := updatedVersions.Versions[vi1.Version]
+	c.Assert(updated.Disabled, check.Equals, true)
+	c.Assert(updated.BuildPhases, check.HasLen, 2)
+	c.Assert(updated.BuildPhases[0].Name, check.Equals, appTypes.BuildPhaseBuild)
+	c.Assert(updated.BuildPhases[1].Name, check.Equals, appTypes.BuildPhaseRollout)
+	c.Assert(updated.BuildPhases[1].Status, check.Equals, appTypes.BuildPhaseRunning)
+	c.Assert(updatedVersions.Versions[vi2.Version].BuildPhases, check.HasLen, 0)
+
+	err = s.AppVersionStorage.UpdateBuildPhases(context.TODO(), "myapp", vi1.Version, phases[:1], &appTypes.AppVersionWriteOptions{
+		PreviousUpdatedHash: versions.UpdatedHash,
+	})
+	c.Assert(err, check.Equals, appTypes.ErrTransactionCancelledByChange)
+}
+
 func (s *AppVersionSuite) TestAppVersionStorage_NewAppVersion(c *check.C) {
 	app := &appTypes.App{Name: "myapp"}
 	vi, err := s.AppVersionStorage.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
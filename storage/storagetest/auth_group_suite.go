@@ -97,3 +97,41 @@ func (s *AuthGroupSuite) TestList(c *check.C) {
 	c.Assert(groups, check.HasLen, 1)
 	c.Assert(groups[0].Name, check.Equals, "g2")
 }
+
+func (s *AuthGroupSuite) TestMembers(c *check.C) {
+	err := s.AuthGroupStorage.SetMembers(context.TODO(), "g1", []string{"a@example.com", "b@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.AuthGroupStorage.AddMembers(context.TODO(), "g1", []string{"b@example.com", "c@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.AuthGroupStorage.AddMembers(context.TODO(), "g2", []string{"a@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.AuthGroupStorage.RemoveMembers(context.TODO(), "g1", []string{"a@example.com"})
+	c.Assert(err, check.IsNil)
+	groups, err := s.AuthGroupStorage.List(context.TODO(), []string{"g1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.DeepEquals, []auth.Group{
+		{Name: "g1", Members: []string{"b@example.com", "c@example.com"}},
+	})
+	groups, err = s.AuthGroupStorage.ListByMember(context.TODO(), "a@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.DeepEquals, []auth.Group{
+		{Name: "g2", Members: []string{"a@example.com"}},
+	})
+	err = s.AuthGroupStorage.SetMembers(context.TODO(), "g1", nil)
+	c.Assert(err, check.IsNil)
+	groups, err = s.AuthGroupStorage.ListByMember(context.TODO(), "b@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 0)
+}
+
+func (s *AuthGroupSuite) TestDelete(c *check.C) {
+	err := s.AuthGroupStorage.AddRole(context.TODO(), "g1", "r1", "v1")
+	c.Assert(err, check.IsNil)
+	err = s.AuthGroupStorage.Delete(context.TODO(), "g1")
+	c.Assert(err, check.IsNil)
+	groups, err := s.AuthGroupStorage.List(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 0)
+	err = s.AuthGroupStorage.Delete(context.TODO(), "g1")
+	c.Assert(err, check.Equals, auth.ErrGroupNotFound)
+}
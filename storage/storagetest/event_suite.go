@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/types/event"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	check "gopkg.in/check.v1"
+)
+
+type EventSuite struct {
+	SuiteHooks
+	EventStorage event.EventStorage
+}
+
+func blockReasons(blocks []event.Block) []string {
+	reasons := make([]string, len(blocks))
+	for i, b := range blocks {
+		reasons[i] = b.Reason
+	}
+	return reasons
+}
+
+func (s *EventSuite) TestInsertAndFindBlocks(c *check.C) {
+	now := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	block := event.Block{
+		ID:           primitive.NewObjectID(),
+		StartTime:    now,
+		EndTime:      now.Add(time.Hour),
+		KindName:     "app.deploy",
+		OwnerName:    "me@example.com",
+		Target:       event.Target{Type: event.TargetTypeApp, Value: "myapp"},
+		Conditions:   map[string]string{"pool": "prod"},
+		Reason:       "maintenance",
+		Active:       true,
+		Window:       &event.BlockWindow{Start: "0 18 * * 5", End: "0 8 * * 1", Timezone: "America/Sao_Paulo"},
+		AllowedTeams: []string{"sre"},
+		AllowedRoles: []string{"release-manager"},
+	}
+	err := s.EventStorage.InsertBlock(context.TODO(), block)
+	c.Assert(err, check.IsNil)
+	blocks, err := s.EventStorage.FindBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 1)
+	c.Assert(blocks[0].StartTime.Equal(block.StartTime), check.Equals, true)
+	c.Assert(blocks[0].EndTime.Equal(block.EndTime), check.Equals, true)
+	blocks[0].StartTime, blocks[0].EndTime = block.StartTime, block.EndTime
+	c.Assert(blocks[0], check.DeepEquals, block)
+}
+
+func (s *EventSuite) TestFindBlocks(c *check.C) {
+	now := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	blocks := []event.Block{
+		{ID: primitive.NewObjectID(), StartTime: now.Add(-2 * time.Hour), Reason: "first", Active: true},
+		{ID: primitive.NewObjectID(), StartTime: now.Add(-time.Hour), Reason: "second"},
+		{ID: primitive.NewObjectID(), StartTime: now, Reason: "third", Active: true},
+	}
+	for _, b := range blocks {
+		err := s.EventStorage.InsertBlock(context.TODO(), b)
+		c.Assert(err, check.IsNil)
+	}
+	result, err := s.EventStorage.FindBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blockReasons(result), check.DeepEquals, []string{"third", "second", "first"})
+	active := true
+	result, err = s.EventStorage.FindBlocks(context.TODO(), &active)
+	c.Assert(err, check.IsNil)
+	c.Assert(blockReasons(result), check.DeepEquals, []string{"third", "first"})
+	active = false
+	result, err = s.EventStorage.FindBlocks(context.TODO(), &active)
+	c.Assert(err, check.IsNil)
+	c.Assert(blockReasons(result), check.DeepEquals, []string{"second"})
+}
+
+func (s *EventSuite) TestDeactivateBlock(c *check.C) {
+	block := event.Block{ID: primitive.NewObjectID(), StartTime: time.Now().UTC(), Reason: "maintenance", Active: true}
+	err := s.EventStorage.InsertBlock(context.TODO(), block)
+	c.Assert(err, check.IsNil)
+	endTime := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	err = s.EventStorage.DeactivateBlock(context.TODO(), block.ID, endTime)
+	c.Assert(err, check.IsNil)
+	blocks, err := s.EventStorage.FindBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 1)
+	c.Assert(blocks[0].Active, check.Equals, false)
+	c.Assert(blocks[0].EndTime.Equal(endTime), check.Equals, true)
+	err = s.EventStorage.DeactivateBlock(context.TODO(), block.ID, endTime)
+	c.Assert(err, check.Equals, event.ErrActiveBlockNotFound)
+	err = s.EventStorage.DeactivateBlock(context.TODO(), primitive.NewObjectID(), endTime)
+	c.Assert(err, check.Equals, event.ErrActiveBlockNotFound)
+}
+
+func (s *EventSuite) TestDeactivateExpiredBlocks(c *check.C) {
+	now := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	blocks := []event.Block{
+		{ID: primitive.NewObjectID(), StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour), Reason: "expired", Active: true},
+		{ID: primitive.NewObjectID(), StartTime: now.Add(-time.Hour), EndTime: now, Reason: "ending", Active: true},
+		{ID: primitive.NewObjectID(), StartTime: now.Add(-3 * time.Hour), EndTime: now.Add(time.Hour), Reason: "running", Active: true},
+		{ID: primitive.NewObjectID(), StartTime: now.Add(-4 * time.Hour), Reason: "forever", Active: true},
+	}
+	for _, b := range blocks {
+		err := s.EventStorage.InsertBlock(context.TODO(), b)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.EventStorage.DeactivateExpiredBlocks(context.TODO(), now)
+	c.Assert(err, check.IsNil)
+	active := true
+	result, err := s.EventStorage.FindBlocks(context.TODO(), &active)
+	c.Assert(err, check.IsNil)
+	c.Assert(blockReasons(result), check.DeepEquals, []string{"running", "forever"})
+}
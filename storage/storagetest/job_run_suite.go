@@ -0,0 +1,122 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	check "gopkg.in/check.v1"
+)
+
+type JobRunSuite struct {
+	SuiteHooks
+	JobRunStorage jobTypes.RunStorage
+}
+
+func runIDs(runs []jobTypes.Run) []string {
+	var ids []string
+	for _, r := range runs {
+		ids = append(ids, r.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *JobRunSuite) TestUpsertAndFindByID(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	r := jobTypes.Run{
+		ID:          "r1",
+		JobName:     "job1",
+		Cluster:     "c1",
+		Trigger:     jobTypes.RunTriggerManual,
+		TriggeredBy: "me@tsuru.io",
+		Status:      jobTypes.RunStatusRunning,
+		StartTime:   now,
+		Units:       []string{"job1-r1-abcde"},
+	}
+	err := s.JobRunStorage.Upsert(context.TODO(), r)
+	c.Assert(err, check.IsNil)
+	result, err := s.JobRunStorage.FindByID(context.TODO(), "job1", "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &r)
+	exitCode := int32(1)
+	r.Status = jobTypes.RunStatusFailed
+	r.Reason = "BackoffLimitExceeded"
+	r.ExitCode = &exitCode
+	r.Retries = 2
+	r.EndTime = now.Add(time.Minute)
+	r.Log = &jobTypes.RunLog{Entries: []jobTypes.RunLogEntry{{Date: now, Unit: "job1-r1-abcde", Message: "failed"}}}
+	err = s.JobRunStorage.Upsert(context.TODO(), r)
+	c.Assert(err, check.IsNil)
+	result, err = s.JobRunStorage.FindByID(context.TODO(), "job1", "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &r)
+	_, err = s.JobRunStorage.FindByID(context.TODO(), "job2", "r1")
+	c.Assert(err, check.Equals, jobTypes.ErrJobRunNotFound)
+	_, err = s.JobRunStorage.FindByID(context.TODO(), "job1", "not-found")
+	c.Assert(err, check.Equals, jobTypes.ErrJobRunNotFound)
+}
+
+func (s *JobRunSuite) TestFind(c *check.C) {
+	now := time.Now().UTC()
+	for _, r := range []jobTypes.Run{
+		{ID: "r1", JobName: "job1", Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-3 * time.Minute)},
+		{ID: "r2", JobName: "job1", Status: jobTypes.RunStatusFailed, StartTime: now.Add(-2 * time.Minute)},
+		{ID: "r3", JobName: "job1", Status: jobTypes.RunStatusFailed, StartTime: now.Add(-time.Minute)},
+		{ID: "r4", JobName: "job2", Status: jobTypes.RunStatusFailed, StartTime: now},
+	} {
+		err := s.JobRunStorage.Upsert(context.TODO(), r)
+		c.Assert(err, check.IsNil)
+	}
+	result, err := s.JobRunStorage.Find(context.TODO(), jobTypes.RunFilter{JobName: "job1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 3)
+	c.Assert(result[0].ID, check.Equals, "r3")
+	c.Assert(result[2].ID, check.Equals, "r1")
+	result, err = s.JobRunStorage.Find(context.TODO(), jobTypes.RunFilter{Status: jobTypes.RunStatusFailed})
+	c.Assert(err, check.IsNil)
+	c.Assert(runIDs(result), check.DeepEquals, []string{"r2", "r3", "r4"})
+	result, err = s.JobRunStorage.Find(context.TODO(), jobTypes.RunFilter{JobName: "job1", Status: jobTypes.RunStatusFailed, Limit: 1})
+	c.Assert(err, check.IsNil)
+	c.Assert(runIDs(result), check.DeepEquals, []string{"r3"})
+}
+
+func (s *JobRunSuite) TestPrune(c *check.C) {
+	now := time.Now().UTC()
+	for _, r := range []jobTypes.Run{
+		{ID: "r1", JobName: "job1", Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-4 * time.Minute)},
+		{ID: "r2", JobName: "job1", Status: jobTypes.RunStatusFailed, StartTime: now.Add(-3 * time.Minute)},
+		{ID: "r3", JobName: "job1", Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-2 * time.Minute)},
+		{ID: "r4", JobName: "job1", Status: jobTypes.RunStatusRunning, StartTime: now.Add(-time.Hour)},
+		{ID: "r5", JobName: "job2", Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-time.Hour)},
+	} {
+		err := s.JobRunStorage.Upsert(context.TODO(), r)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.JobRunStorage.Prune(context.TODO(), "job1", 2)
+	c.Assert(err, check.IsNil)
+	result, err := s.JobRunStorage.Find(context.TODO(), jobTypes.RunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(runIDs(result), check.DeepEquals, []string{"r2", "r3", "r4", "r5"})
+}
+
+func (s *JobRunSuite) TestDeleteByJob(c *check.C) {
+	for _, r := range []jobTypes.Run{
+		{ID: "r1", JobName: "job1"},
+		{ID: "r2", JobName: "job1"},
+		{ID: "r3", JobName: "job2"},
+	} {
+		err := s.JobRunStorage.Upsert(context.TODO(), r)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.JobRunStorage.DeleteByJob(context.TODO(), "job1")
+	c.Assert(err, check.IsNil)
+	result, err := s.JobRunStorage.Find(context.TODO(), jobTypes.RunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(runIDs(result), check.DeepEquals, []string{"r3"})
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	check "gopkg.in/check.v1"
+)
+
+type WorkflowRunSuite struct {
+	SuiteHooks
+	WorkflowRunStorage jobTypes.WorkflowRunStorage
+}
+
+func workflowRunIDs(runs []jobTypes.WorkflowRun) []string {
+	var ids []string
+	for _, r := range runs {
+		ids = append(ids, r.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *WorkflowRunSuite) TestInsertAndFindByID(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	r := jobTypes.WorkflowRun{
+		ID:           "r1",
+		WorkflowName: "wf1",
+		Trigger:      jobTypes.RunTriggerCron,
+		Status:       jobTypes.RunStatusRunning,
+		StartTime:    now,
+		Steps: []jobTypes.WorkflowStepRun{
+			{
+				WorkflowStep: jobTypes.WorkflowStep{Name: "extract", Job: "job1"},
+				Status:       jobTypes.WorkflowStepRunning,
+				StartTime:    now,
+			},
+			{
+				WorkflowStep: jobTypes.WorkflowStep{Name: "load", Job: "job2", DependsOn: []string{"extract"}},
+				Status:       jobTypes.WorkflowStepPending,
+			},
+		},
+	}
+	err := s.WorkflowRunStorage.Insert(context.TODO(), r)
+	c.Assert(err, check.IsNil)
+	result, err := s.WorkflowRunStorage.FindByID(context.TODO(), "wf1", "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &r)
+	_, err = s.WorkflowRunStorage.FindByID(context.TODO(), "wf2", "r1")
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowRunNotFound)
+}
+
+func (s *WorkflowRunSuite) TestUpdate(c *check.C) {
+	r := jobTypes.WorkflowRun{
+		ID:           "r1",
+		WorkflowName: "wf1",
+		Status:       jobTypes.RunStatusRunning,
+		Steps: []jobTypes.WorkflowStepRun{
+			{WorkflowStep: jobTypes.WorkflowStep{Name: "extract", Job: "job1"}, Status: jobTypes.WorkflowStepRunning},
+		},
+	}
+	err := s.WorkflowRunStorage.Insert(context.TODO(), r)
+	c.Assert(err, check.IsNil)
+	r.Status = jobTypes.RunStatusSucceeded
+	r.Steps[0].Status = jobTypes.WorkflowStepSucceeded
+	r.Steps[0].JobRun = "job-run-1"
+	err = s.WorkflowRunStorage.Update(context.TODO(), r)
+	c.Assert(err, check.IsNil)
+	result, err := s.WorkflowRunStorage.FindByID(context.TODO(), "wf1", "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Version, check.Equals, 1)
+	c.Assert(result.Status, check.Equals, jobTypes.RunStatusSucceeded)
+	c.Assert(result.Steps[0].JobRun, check.Equals, "job-run-1")
+	err = s.WorkflowRunStorage.Update(context.TODO(), r)
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowRunConflict)
+	err = s.WorkflowRunStorage.Update(context.TODO(), *result)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *WorkflowRunSuite) TestFind(c *check.C) {
+	now := time.Now().UTC()
+	for _, r := range []jobTypes.WorkflowRun{
+		{ID: "r1", WorkflowName: "wf1", Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-3 * time.Minute)},
+		{ID: "r2", WorkflowName: "wf1", Status: jobTypes.RunStatusFailed, StartTime: now.Add(-2 * time.Minute)},
+		{ID: "r3", WorkflowName: "wf1", Status: jobTypes.RunStatusFailed, StartTime: now.Add(-time.Minute)},
+		{ID: "r4", WorkflowName: "wf2", Status: jobTypes.RunStatusFailed, StartTime: now},
+	} {
+		err := s.WorkflowRunStorage.Insert(context.TODO(), r)
+		c.Assert(err, check.IsNil)
+	}
+	result, err := s.WorkflowRunStorage.Find(context.TODO(), jobTypes.WorkflowRunFilter{WorkflowName: "wf1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 3)
+	c.Assert(result[0].ID, check.Equals, "r3")
+	c.Assert(result[2].ID, check.Equals, "r1")
+	result, err = s.WorkflowRunStorage.Find(context.TODO(), jobTypes.WorkflowRunFilter{Status: jobTypes.RunStatusFailed})
+	c.Assert(err, check.IsNil)
+	c.Assert(workflowRunIDs(result), check.DeepEquals, []string{"r2", "r3", "r4"})
+	result, err = s.WorkflowRunStorage.Find(context.TODO(), jobTypes.WorkflowRunFilter{WorkflowName: "wf1", Status: jobTypes.RunStatusFailed, Limit: 1})
+	c.Assert(err, check.IsNil)
+	c.Assert(workflowRunIDs(result), check.DeepEquals, []string{"r3"})
+}
+
+func (s *WorkflowRunSuite) TestPrune(c *check.C) {
+	now := time.Now().UTC()
+	for _, r := range []jobTypes.WorkflowRun{
+		{ID: "r1", WorkflowName: "wf1", Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-4 * time.Minute)},
+		{ID: "r2", WorkflowName: "wf1", Status: jobTypes.RunStatusFailed, StartTime: now.Add(-3 * time.Minute)},
+		{ID: "r3", WorkflowName: "wf1", Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-2 * time.Minute)},
+		{ID: "r4", WorkflowName: "wf1", Status: jobTypes.RunStatusRunning, StartTime: now.Add(-time.Hour)},
+		{ID: "r5", WorkflowName: "wf2", Status: jobTypes.RunStatusSucceeded, StartTime: now.Add(-time.Hour)},
+	} {
+		err := s.WorkflowRunStorage.Insert(context.TODO(), r)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.WorkflowRunStorage.Prune(context.TODO(), "wf1", 2)
+	c.Assert(err, check.IsNil)
+	result, err := s.WorkflowRunStorage.Find(context.TODO(), jobTypes.WorkflowRunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(workflowRunIDs(result), check.DeepEquals, []string{"r2", "r3", "r4", "r5"})
+}
+
+func (s *WorkflowRunSuite) TestDeleteByWorkflow(c *check.C) {
+	for _, r := range []jobTypes.WorkflowRun{
+		{ID: "r1", WorkflowName: "wf1"},
+		{ID: "r2", WorkflowName: "wf1"},
+		{ID: "r3", WorkflowName: "wf2"},
+	} {
+		err := s.WorkflowRunStorage.Insert(context.TODO(), r)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.WorkflowRunStorage.DeleteByWorkflow(context.TODO(), "wf1")
+	c.Assert(err, check.IsNil)
+	result, err := s.WorkflowRunStorage.Find(context.TODO(), jobTypes.WorkflowRunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(workflowRunIDs(result), check.DeepEquals, []string{"r3"})
+}
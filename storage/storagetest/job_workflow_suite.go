@@ -0,0 +1,119 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"time"
+
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	check "gopkg.in/check.v1"
+)
+
+type WorkflowSuite struct {
+	SuiteHooks
+	WorkflowStorage jobTypes.WorkflowStorage
+}
+
+func workflowNames(workflows []jobTypes.Workflow) []string {
+	var names []string
+	for _, w := range workflows {
+		names = append(names, w.Name)
+	}
+	return names
+}
+
+func (s *WorkflowSuite) TestInsertAndFindByName(c *check.C) {
+	w := jobTypes.Workflow{
+		Name:      "wf1",
+		TeamOwner: "team1",
+		Schedule:  "0 * * * *",
+		Envs:      []bindTypes.EnvVar{{Name: "REGION", Value: "us-east-1", Public: true}},
+		Steps: []jobTypes.WorkflowStep{
+			{Name: "extract", Job: "job1"},
+			{Name: "load", Job: "job2", DependsOn: []string{"extract"}, Condition: jobTypes.WorkflowConditionSuccess},
+		},
+		LastScheduleTime: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	err := s.WorkflowStorage.Insert(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	result, err := s.WorkflowStorage.FindByName(context.TODO(), "wf1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &w)
+	err = s.WorkflowStorage.Insert(context.TODO(), w)
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowAlreadyExists)
+	_, err = s.WorkflowStorage.FindByName(context.TODO(), "not-found")
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowNotFound)
+}
+
+func (s *WorkflowSuite) TestUpdate(c *check.C) {
+	w := jobTypes.Workflow{
+		Name:      "wf1",
+		TeamOwner: "team1",
+		Steps:     []jobTypes.WorkflowStep{{Name: "extract", Job: "job1"}},
+	}
+	err := s.WorkflowStorage.Insert(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	w.Description = "nightly etl"
+	w.Steps = append(w.Steps, jobTypes.WorkflowStep{Name: "notify", Job: "job3", DependsOn: []string{"extract"}, Condition: jobTypes.WorkflowConditionFailure})
+	err = s.WorkflowStorage.Update(context.TODO(), w)
+	c.Assert(err, check.IsNil)
+	result, err := s.WorkflowStorage.FindByName(context.TODO(), "wf1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Description, check.Equals, "nightly etl")
+	c.Assert(result.Steps, check.DeepEquals, w.Steps)
+	w.Name = "not-found"
+	err = s.WorkflowStorage.Update(context.TODO(), w)
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowNotFound)
+}
+
+func (s *WorkflowSuite) TestFindAllByTeamsAndScheduled(c *check.C) {
+	for _, w := range []jobTypes.Workflow{
+		{Name: "wf1", TeamOwner: "team1", Schedule: "0 * * * *"},
+		{Name: "wf2", TeamOwner: "team2"},
+		{Name: "wf3", TeamOwner: "team3", Schedule: "*/5 * * * *"},
+	} {
+		err := s.WorkflowStorage.Insert(context.TODO(), w)
+		c.Assert(err, check.IsNil)
+	}
+	result, err := s.WorkflowStorage.FindAllByTeams(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(workflowNames(result), check.DeepEquals, []string{"wf1", "wf2", "wf3"})
+	result, err = s.WorkflowStorage.FindAllByTeams(context.TODO(), []string{"team2", "team3"})
+	c.Assert(err, check.IsNil)
+	c.Assert(workflowNames(result), check.DeepEquals, []string{"wf2", "wf3"})
+	result, err = s.WorkflowStorage.FindAllByTeams(context.TODO(), []string{})
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 0)
+	result, err = s.WorkflowStorage.FindScheduled(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(workflowNames(result), check.DeepEquals, []string{"wf1", "wf3"})
+}
+
+func (s *WorkflowSuite) TestUpdateLastSchedule(c *check.C) {
+	previous := time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+	err := s.WorkflowStorage.Insert(context.TODO(), jobTypes.Workflow{Name: "wf1", TeamOwner: "team1", Schedule: "0 * * * *", LastScheduleTime: previous})
+	c.Assert(err, check.IsNil)
+	last := previous.Add(time.Hour)
+	err = s.WorkflowStorage.UpdateLastSchedule(context.TODO(), "wf1", previous, last)
+	c.Assert(err, check.IsNil)
+	err = s.WorkflowStorage.UpdateLastSchedule(context.TODO(), "wf1", previous, last.Add(time.Minute))
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowScheduleConflict)
+	result, err := s.WorkflowStorage.FindByName(context.TODO(), "wf1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.LastScheduleTime.Equal(last), check.Equals, true)
+}
+
+func (s *WorkflowSuite) TestDelete(c *check.C) {
+	err := s.WorkflowStorage.Insert(context.TODO(), jobTypes.Workflow{Name: "wf1", TeamOwner: "team1"})
+	c.Assert(err, check.IsNil)
+	err = s.WorkflowStorage.Delete(context.TODO(), "wf1")
+	c.Assert(err, check.IsNil)
+	_, err = s.WorkflowStorage.FindByName(context.TODO(), "wf1")
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowNotFound)
+	err = s.WorkflowStorage.Delete(context.TODO(), "wf1")
+	c.Assert(err, check.Equals, jobTypes.ErrWorkflowNotFound)
+}
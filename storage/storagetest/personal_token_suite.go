@@ -0,0 +1,115 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+type PersonalTokenSuite struct {
+	SuiteHooks
+	PersonalTokenStorage auth.PersonalTokenStorage
+}
+
+func (s *PersonalTokenSuite) TestInsertPersonalToken(c *check.C) {
+	t := auth.PersonalToken{
+		Token:              "abc123",
+		TokenID:            "ci-abc12",
+		Name:               "ci",
+		UserEmail:          "me@example.com",
+		AllowedPermissions: []string{"app.deploy"},
+	}
+	err := s.PersonalTokenStorage.Insert(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	token, err := s.PersonalTokenStorage.FindByToken(context.TODO(), t.Token)
+	c.Assert(err, check.IsNil)
+	c.Assert(token.TokenID, check.Equals, t.TokenID)
+	c.Assert(token.UserEmail, check.Equals, t.UserEmail)
+	c.Assert(token.AllowedPermissions, check.DeepEquals, t.AllowedPermissions)
+	token, err = s.PersonalTokenStorage.FindByTokenID(context.TODO(), t.TokenID)
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Token, check.Equals, t.Token)
+}
+
+func (s *PersonalTokenSuite) TestInsertDuplicatePersonalTokenName(c *check.C) {
+	err := s.PersonalTokenStorage.Insert(context.TODO(), auth.PersonalToken{Token: "1", TokenID: "ci-1", Name: "ci", UserEmail: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.PersonalTokenStorage.Insert(context.TODO(), auth.PersonalToken{Token: "2", TokenID: "ci-2", Name: "ci", UserEmail: "me@example.com"})
+	c.Assert(err, check.Equals, auth.ErrPersonalTokenAlreadyExists)
+	err = s.PersonalTokenStorage.Insert(context.TODO(), auth.PersonalToken{Token: "3", TokenID: "ci-3", Name: "ci", UserEmail: "other@example.com"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *PersonalTokenSuite) TestFindPersonalTokenNotFound(c *check.C) {
+	_, err := s.PersonalTokenStorage.FindByToken(context.TODO(), "wat")
+	c.Assert(err, check.Equals, auth.ErrPersonalTokenNotFound)
+	_, err = s.PersonalTokenStorage.FindByTokenID(context.TODO(), "wat")
+	c.Assert(err, check.Equals, auth.ErrPersonalTokenNotFound)
+}
+
+func (s *PersonalTokenSuite) TestFindPersonalTokensByUser(c *check.C) {
+	for i, email := range []string{"me@example.com", "other@example.com", "me@example.com"} {
+		err := s.PersonalTokenStorage.Insert(context.TODO(), auth.PersonalToken{
+			Token:     string(rune('a' + i)),
+			TokenID:   string(rune('a' + i)),
+			Name:      string(rune('a' + i)),
+			UserEmail: email,
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Second),
+		})
+		c.Assert(err, check.IsNil)
+	}
+	tokens, err := s.PersonalTokenStorage.FindByUser(context.TODO(), "me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 2)
+	c.Assert(tokens[0].TokenID, check.Equals, "a")
+	c.Assert(tokens[1].TokenID, check.Equals, "c")
+	err = s.PersonalTokenStorage.DeleteByUser(context.TODO(), "me@example.com")
+	c.Assert(err, check.IsNil)
+	tokens, err = s.PersonalTokenStorage.FindByUser(context.TODO(), "me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+}
+
+func (s *PersonalTokenSuite) TestFindExpiringPersonalTokens(c *check.C) {
+	now := time.Now().UTC()
+	err := s.PersonalTokenStorage.Insert(context.TODO(), auth.PersonalToken{Token: "1", TokenID: "soon", Name: "soon", UserEmail: "me@example.com", ExpiresAt: now.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	err = s.PersonalTokenStorage.Insert(context.TODO(), auth.PersonalToken{Token: "2", TokenID: "later", Name: "later", UserEmail: "me@example.com", ExpiresAt: now.Add(48 * time.Hour)})
+	c.Assert(err, check.IsNil)
+	tokens, err := s.PersonalTokenStorage.FindExpiring(context.TODO(), now.Add(24*time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 1)
+	c.Assert(tokens[0].TokenID, check.Equals, "soon")
+	err = s.PersonalTokenStorage.SetExpiryNotified(context.TODO(), "soon")
+	c.Assert(err, check.IsNil)
+	tokens, err = s.PersonalTokenStorage.FindExpiring(context.TODO(), now.Add(24*time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+	err = s.PersonalTokenStorage.SetExpiryNotified(context.TODO(), "unknown")
+	c.Assert(err, check.Equals, auth.ErrPersonalTokenNotFound)
+}
+
+func (s *PersonalTokenSuite) TestUpdatePersonalTokenLastAccess(c *check.C) {
+	err := s.PersonalTokenStorage.Insert(context.TODO(), auth.PersonalToken{Token: "1", TokenID: "ci", Name: "ci", UserEmail: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.PersonalTokenStorage.UpdateLastAccess(context.TODO(), "1")
+	c.Assert(err, check.IsNil)
+	token, err := s.PersonalTokenStorage.FindByTokenID(context.TODO(), "ci")
+	c.Assert(err, check.IsNil)
+	c.Assert(time.Since(token.LastAccess) < time.Minute, check.Equals, true)
+}
+
+func (s *PersonalTokenSuite) TestDeletePersonalToken(c *check.C) {
+	err := s.PersonalTokenStorage.Insert(context.TODO(), auth.PersonalToken{Token: "1", TokenID: "ci", Name: "ci", UserEmail: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.PersonalTokenStorage.Delete(context.TODO(), "ci")
+	c.Assert(err, check.IsNil)
+	err = s.PersonalTokenStorage.Delete(context.TODO(), "ci")
+	c.Assert(err, check.Equals, auth.ErrPersonalTokenNotFound)
+}
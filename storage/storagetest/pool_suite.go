@@ -7,11 +7,8 @@ package storagetest
 import (
 	"context"
 
-	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/types/provision"
 	"gopkg.in/check.v1"
-
-	mongoBSON "go.mongodb.org/mongo-driver/bson"
 )
 
 type PoolSuite struct {
@@ -19,17 +16,18 @@ type PoolSuite struct {
 	PoolStorage provision.PoolStorage
 }
 
-func (s *PoolSuite) TestFindAll(c *check.C) {
-
-	collection, err := storagev2.PoolCollection()
-	c.Assert(err, check.IsNil)
+func (s *PoolSuite) insertPools(c *check.C) {
+	for _, p := range []provision.Pool{
+		{Name: "pool-A", Provisioner: "docker", Default: true},
+		{Name: "pool-B", Provisioner: "kubernetes"},
+	} {
+		err := s.PoolStorage.Insert(context.TODO(), p)
+		c.Assert(err, check.IsNil)
+	}
+}
 
-	_, err = collection.InsertMany(context.TODO(), []interface{}{
-		mongoBSON.M{"_id": "pool-A", "provisioner": "docker", "default": true},
-		mongoBSON.M{"_id": "pool-B", "provisioner": "kubernetes"},
-	},
-	)
-	c.Assert(err, check.IsNil)
+func (s *PoolSuite) TestFindAll(c *check.C) {
+	s.insertPools(c)
 	pools, err := s.PoolStorage.FindAll(context.TODO())
 	c.Assert(err, check.IsNil)
 	c.Assert(pools, check.DeepEquals, []provision.Pool{
@@ -39,17 +37,10 @@ func (s *PoolSuite) TestFindAll(c *check.C) {
 }
 
 func (s *PoolSuite) TestFindByName(c *check.C) {
-	collection, err := storagev2.PoolCollection()
-	c.Assert(err, check.IsNil)
-	_, err = collection.InsertMany(context.TODO(), []interface{}{
-		mongoBSON.M{"_id": "pool-A", "provisioner": "docker", "default": true},
-		mongoBSON.M{"_id": "pool-B", "provisioner": "kubernetes"},
-	})
-	c.Assert(err, check.IsNil)
+	s.insertPools(c)
 	pool, err := s.PoolStorage.FindByName(context.TODO(), "pool-B")
 	c.Assert(err, check.IsNil)
 	c.Assert(pool, check.DeepEquals, &provision.Pool{Name: "pool-B", Provisioner: "kubernetes"})
-
 }
 
 func (s *PoolSuite) TestFindByName_PoolNotFound(c *check.C) {
@@ -57,3 +48,31 @@ func (s *PoolSuite) TestFindByName_PoolNotFound(c *check.C) {
 	c.Assert(err, check.NotNil)
 	c.Assert(err, check.DeepEquals, provision.ErrPoolNotFound)
 }
+
+func (s *PoolSuite) TestInsertDuplicated(c *check.C) {
+	s.insertPools(c)
+	err := s.PoolStorage.Insert(context.TODO(), provision.Pool{Name: "pool-A"})
+	c.Assert(err, check.Equals, provision.ErrPoolAlreadyExists)
+}
+
+func (s *PoolSuite) TestUpdate(c *check.C) {
+	s.insertPools(c)
+	err := s.PoolStorage.Update(context.TODO(), provision.Pool{Name: "pool-B", Provisioner: "kubernetes", Labels: map[string]string{"zone": "a"}})
+	c.Assert(err, check.IsNil)
+	pool, err := s.PoolStorage.FindByName(context.TODO(), "pool-B")
+	c.Assert(err, check.IsNil)
+	c.Assert(pool, check.DeepEquals, &provision.Pool{Name: "pool-B", Provisioner: "kubernetes", Labels: map[string]string{"zone": "a"}})
+	err = s.PoolStorage.Update(context.TODO(), provision.Pool{Name: "pool-C"})
+	c.Assert(err, check.Equals, provision.ErrPoolNotFound)
+}
+
+func (s *PoolSuite) TestDelete(c *check.C) {
+	s.insertPools(c)
+	err := s.PoolStorage.Delete(context.TODO(), "pool-A")
+	c.Assert(err, check.IsNil)
+	pools, err := s.PoolStorage.FindAll(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(pools, check.DeepEquals, []provision.Pool{{Name: "pool-B", Provisioner: "kubernetes"}})
+	err = s.PoolStorage.Delete(context.TODO(), "pool-A")
+	c.Assert(err, check.Equals, provision.ErrPoolNotFound)
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+type RoleSuite struct {
+	SuiteHooks
+	RoleStorage permission.RoleStorage
+}
+
+func roleNames(roles []permission.Role) []string {
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = r.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *RoleSuite) TestInsertAndFindByName(c *check.C) {
+	role := permission.Role{Name: "app-deployer", ContextType: permission.CtxApp, Description: "deploys apps"}
+	err := s.RoleStorage.Insert(context.TODO(), role)
+	c.Assert(err, check.IsNil)
+	dbRole, err := s.RoleStorage.FindByName(context.TODO(), "app-deployer")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRole, check.DeepEquals, &permission.Role{
+		Name:        "app-deployer",
+		ContextType: permission.CtxApp,
+		Description: "deploys apps",
+		SchemeNames: []string{},
+		Events:      []string{},
+	})
+	_, err = s.RoleStorage.FindByName(context.TODO(), "unknown")
+	c.Assert(err, check.Equals, permission.ErrRoleNotFound)
+}
+
+func (s *RoleSuite) TestInsertDuplicated(c *check.C) {
+	role := permission.Role{Name: "app-deployer", ContextType: permission.CtxApp}
+	err := s.RoleStorage.Insert(context.TODO(), role)
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.Insert(context.TODO(), role)
+	c.Assert(err, check.Equals, permission.ErrRoleAlreadyExists)
+}
+
+func (s *RoleSuite) TestFindAll(c *check.C) {
+	roles, err := s.RoleStorage.FindAll(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(roles, check.HasLen, 0)
+	for _, name := range []string{"r1", "r2"} {
+		err = s.RoleStorage.Insert(context.TODO(), permission.Role{Name: name, ContextType: permission.CtxTeam})
+		c.Assert(err, check.IsNil)
+	}
+	roles, err = s.RoleStorage.FindAll(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(roleNames(roles), check.DeepEquals, []string{"r1", "r2"})
+}
+
+func (s *RoleSuite) TestFindWithEventsAndByEvent(c *check.C) {
+	err := s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r1", ContextType: permission.CtxTeam})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r2", ContextType: permission.CtxTeam, Events: []string{"team-create"}})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r3", ContextType: permission.CtxGlobal, Events: []string{"user-create"}})
+	c.Assert(err, check.IsNil)
+	roles, err := s.RoleStorage.FindWithEvents(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(roleNames(roles), check.DeepEquals, []string{"r2", "r3"})
+	roles, err = s.RoleStorage.FindByEvent(context.TODO(), "team-create")
+	c.Assert(err, check.IsNil)
+	c.Assert(roleNames(roles), check.DeepEquals, []string{"r2"})
+	roles, err = s.RoleStorage.FindByEvent(context.TODO(), "unknown")
+	c.Assert(err, check.IsNil)
+	c.Assert(roles, check.HasLen, 0)
+}
+
+func (s *RoleSuite) TestFindByDynamicSchemePrefix(c *check.C) {
+	err := s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r1", ContextType: permission.CtxServiceInstance, DynamicSchemeNames: []string{"service-instance.mysql.backup"}})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r2", ContextType: permission.CtxServiceInstance, DynamicSchemeNames: []string{"service-instance.mysql"}})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r3", ContextType: permission.CtxServiceInstance, DynamicSchemeNames: []string{"service-instance.mysql2.backup"}})
+	c.Assert(err, check.IsNil)
+	roles, err := s.RoleStorage.FindByDynamicSchemePrefix(context.TODO(), "service-instance.mysql")
+	c.Assert(err, check.IsNil)
+	c.Assert(roleNames(roles), check.DeepEquals, []string{"r1", "r2"})
+}
+
+func (s *RoleSuite) TestUpdate(c *check.C) {
+	err := s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r1", ContextType: permission.CtxTeam, SchemeNames: []string{"app"}})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.Update(context.TODO(), permission.Role{Name: "r1", ContextType: permission.CtxApp, Description: "new"})
+	c.Assert(err, check.IsNil)
+	role, err := s.RoleStorage.FindByName(context.TODO(), "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.ContextType, check.Equals, permission.CtxApp)
+	c.Assert(role.Description, check.Equals, "new")
+	c.Assert(role.SchemeNames, check.DeepEquals, []string{"app"})
+	err = s.RoleStorage.Update(context.TODO(), permission.Role{Name: "unknown", ContextType: permission.CtxApp})
+	c.Assert(err, check.Equals, permission.ErrRoleNotFound)
+}
+
+func (s *RoleSuite) TestAddRemoveSchemeNames(c *check.C) {
+	err := s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r1", ContextType: permission.CtxTeam})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.AddSchemeNames(context.TODO(), "r1", []string{"app.deploy", "app.update"})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.AddSchemeNames(context.TODO(), "r1", []string{"app.update", "team"})
+	c.Assert(err, check.IsNil)
+	role, err := s.RoleStorage.FindByName(context.TODO(), "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.SchemeNames, check.DeepEquals, []string{"app.deploy", "app.update", "team"})
+	err = s.RoleStorage.RemoveSchemeNames(context.TODO(), "r1", []string{"app.update", "team"})
+	c.Assert(err, check.IsNil)
+	role, err = s.RoleStorage.FindByName(context.TODO(), "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.SchemeNames, check.DeepEquals, []string{"app.deploy"})
+	err = s.RoleStorage.AddSchemeNames(context.TODO(), "unknown", []string{"app"})
+	c.Assert(err, check.Equals, permission.ErrRoleNotFound)
+}
+
+func (s *RoleSuite) TestAddRemoveDynamicSchemeNames(c *check.C) {
+	err := s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r1", ContextType: permission.CtxServiceInstance})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.AddDynamicSchemeNames(context.TODO(), "r1", []string{"service-instance.mysql.backup", "service-instance.mysql.restore"})
+	c.Assert(err, check.IsNil)
+	role, err := s.RoleStorage.FindByName(context.TODO(), "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.DynamicSchemeNames, check.DeepEquals, []string{"service-instance.mysql.backup", "service-instance.mysql.restore"})
+	err = s.RoleStorage.RemoveDynamicSchemeNames(context.TODO(), "r1", []string{"service-instance.mysql.backup"})
+	c.Assert(err, check.IsNil)
+	role, err = s.RoleStorage.FindByName(context.TODO(), "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.DynamicSchemeNames, check.DeepEquals, []string{"service-instance.mysql.restore"})
+}
+
+func (s *RoleSuite) TestAddRemoveEvent(c *check.C) {
+	err := s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r1", ContextType: permission.CtxTeam})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.AddEvent(context.TODO(), "r1", "team-create")
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.AddEvent(context.TODO(), "r1", "team-create")
+	c.Assert(err, check.IsNil)
+	role, err := s.RoleStorage.FindByName(context.TODO(), "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.Events, check.DeepEquals, []string{"team-create"})
+	err = s.RoleStorage.RemoveEvent(context.TODO(), "r1", "team-create")
+	c.Assert(err, check.IsNil)
+	role, err = s.RoleStorage.FindByName(context.TODO(), "r1")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.Events, check.DeepEquals, []string{})
+}
+
+func (s *RoleSuite) TestDelete(c *check.C) {
+	err := s.RoleStorage.Insert(context.TODO(), permission.Role{Name: "r1", ContextType: permission.CtxTeam})
+	c.Assert(err, check.IsNil)
+	err = s.RoleStorage.Delete(context.TODO(), "r1")
+	c.Assert(err, check.IsNil)
+	_, err = s.RoleStorage.FindByName(context.TODO(), "r1")
+	c.Assert(err, check.Equals, permission.ErrRoleNotFound)
+	err = s.RoleStorage.Delete(context.TODO(), "r1")
+	c.Assert(err, check.Equals, permission.ErrRoleNotFound)
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tsuru/tsuru/types/service"
+	check "gopkg.in/check.v1"
+)
+
+type ServiceInstanceSuite struct {
+	SuiteHooks
+	ServiceInstanceStorage service.ServiceInstanceStorage
+}
+
+func serviceInstanceNames(instances []service.ServiceInstance) []string {
+	names := make([]string, len(instances))
+	for i, si := range instances {
+		names[i] = si.ServiceName + "/" + si.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *ServiceInstanceSuite) TestInsertAndFindByName(c *check.C) {
+	si := service.ServiceInstance{
+		Name:        "db1",
+		ServiceName: "mysql",
+		PlanName:    "small",
+		Apps:        []string{"myapp"},
+		Jobs:        []string{},
+		Teams:       []string{"dev"},
+		TeamOwner:   "dev",
+		Description: "my database",
+		Tags:        []string{"tag1"},
+		Parameters:  map[string]interface{}{"storage": "ssd"},
+		Pool:        "pool1",
+	}
+	err := s.ServiceInstanceStorage.Insert(context.TODO(), si)
+	c.Assert(err, check.IsNil)
+	dbInstance, err := s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance, check.DeepEquals, &si)
+	_, err = s.ServiceInstanceStorage.FindByName(context.TODO(), "redis", "db1")
+	c.Assert(err, check.Equals, service.ErrServiceInstanceNotFound)
+}
+
+func (s *ServiceInstanceSuite) TestInsertDuplicated(c *check.C) {
+	si := service.ServiceInstance{Name: "db1", ServiceName: "mysql"}
+	err := s.ServiceInstanceStorage.Insert(context.TODO(), si)
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.Insert(context.TODO(), si)
+	c.Assert(err, check.Equals, service.ErrInstanceNameAlreadyExists)
+	err = s.ServiceInstanceStorage.Insert(context.TODO(), service.ServiceInstance{Name: "db1", ServiceName: "redis"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ServiceInstanceSuite) TestFindAll(c *check.C) {
+	instances := []service.ServiceInstance{
+		{Name: "db1", ServiceName: "mysql", Teams: []string{"dev"}, Apps: []string{"app1"}, Tags: []string{"a", "b"}},
+		{Name: "db2", ServiceName: "mysql", Teams: []string{"ops"}, Jobs: []string{"job1"}, Tags: []string{"a"}},
+		{Name: "cache", ServiceName: "redis", Teams: []string{"dev", "ops"}, Apps: []string{"app1", "app2"}},
+	}
+	for _, si := range instances {
+		err := s.ServiceInstanceStorage.Insert(context.TODO(), si)
+		c.Assert(err, check.IsNil)
+	}
+	tt := []struct {
+		filter   service.ServiceInstanceFilter
+		expected []string
+	}{
+		{service.ServiceInstanceFilter{}, []string{"mysql/db1", "mysql/db2", "redis/cache"}},
+		{service.ServiceInstanceFilter{Teams: []string{}}, []string{}},
+		{service.ServiceInstanceFilter{Teams: []string{"ops"}}, []string{"mysql/db2", "redis/cache"}},
+		{service.ServiceInstanceFilter{Teams: []string{"dev"}, Names: []string{"db2"}}, []string{"mysql/db1", "mysql/db2", "redis/cache"}},
+		{service.ServiceInstanceFilter{ServiceNames: []string{"mysql"}}, []string{"mysql/db1", "mysql/db2"}},
+		{service.ServiceInstanceFilter{ServiceNames: []string{}}, []string{}},
+		{service.ServiceInstanceFilter{Teams: []string{"dev"}, ServiceNames: []string{"redis"}}, []string{"redis/cache"}},
+		{service.ServiceInstanceFilter{AppName: "app1"}, []string{"mysql/db1", "redis/cache"}},
+		{service.ServiceInstanceFilter{JobName: "job1"}, []string{"mysql/db2"}},
+		{service.ServiceInstanceFilter{Tags: []string{"a"}}, []string{"mysql/db1", "mysql/db2"}},
+		{service.ServiceInstanceFilter{Tags: []string{"a", "b"}}, []string{"mysql/db1"}},
+	}
+	for i, t := range tt {
+		result, err := s.ServiceInstanceStorage.FindAll(context.TODO(), t.filter)
+		c.Assert(err, check.IsNil)
+		c.Check(serviceInstanceNames(result), check.DeepEquals, t.expected, check.Commentf("(%d)", i))
+	}
+}
+
+func (s *ServiceInstanceSuite) TestUpdate(c *check.C) {
+	si := service.ServiceInstance{
+		Name:        "db1",
+		ServiceName: "mysql",
+		Apps:        []string{"myapp"},
+		Jobs:        []string{},
+		Teams:       []string{"dev"},
+		TeamOwner:   "dev",
+		Tags:        []string{},
+		Parameters:  map[string]interface{}{},
+	}
+	err := s.ServiceInstanceStorage.Insert(context.TODO(), si)
+	c.Assert(err, check.IsNil)
+	updated := si
+	updated.Apps = []string{"other"}
+	updated.Description = "new description"
+	updated.Tags = []string{"tag1"}
+	updated.PlanName = "large"
+	updated.TeamOwner = "ops"
+	updated.Teams = []string{"dev", "ops"}
+	updated.Parameters = map[string]interface{}{"storage": "ssd"}
+	err = s.ServiceInstanceStorage.Update(context.TODO(), updated)
+	c.Assert(err, check.IsNil)
+	dbInstance, err := s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	updated.Apps = []string{"myapp"}
+	c.Assert(dbInstance, check.DeepEquals, &updated)
+	err = s.ServiceInstanceStorage.Update(context.TODO(), service.ServiceInstance{Name: "unknown", ServiceName: "mysql"})
+	c.Assert(err, check.Equals, service.ErrServiceInstanceNotFound)
+}
+
+func (s *ServiceInstanceSuite) TestAddRemoveApp(c *check.C) {
+	err := s.ServiceInstanceStorage.Insert(context.TODO(), service.ServiceInstance{Name: "db1", ServiceName: "mysql"})
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.AddApp(context.TODO(), "mysql", "db1", "app1")
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.AddApp(context.TODO(), "mysql", "db1", "app2")
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.AddApp(context.TODO(), "mysql", "db1", "app1")
+	c.Assert(err, check.Equals, service.ErrAppAlreadyBound)
+	dbInstance, err := s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.Apps, check.DeepEquals, []string{"app1", "app2"})
+	err = s.ServiceInstanceStorage.RemoveApp(context.TODO(), "mysql", "db1", "app1")
+	c.Assert(err, check.IsNil)
+	dbInstance, err = s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.Apps, check.DeepEquals, []string{"app2"})
+	err = s.ServiceInstanceStorage.RemoveApp(context.TODO(), "mysql", "unknown", "app2")
+	c.Assert(err, check.Equals, service.ErrServiceInstanceNotFound)
+}
+
+func (s *ServiceInstanceSuite) TestAddRemoveJob(c *check.C) {
+	err := s.ServiceInstanceStorage.Insert(context.TODO(), service.ServiceInstance{Name: "db1", ServiceName: "mysql"})
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.AddJob(context.TODO(), "mysql", "db1", "job1")
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.AddJob(context.TODO(), "mysql", "db1", "job1")
+	c.Assert(err, check.Equals, service.ErrJobAlreadyBound)
+	dbInstance, err := s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.Jobs, check.DeepEquals, []string{"job1"})
+	err = s.ServiceInstanceStorage.RemoveJob(context.TODO(), "mysql", "db1", "job1")
+	c.Assert(err, check.IsNil)
+	dbInstance, err = s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.Jobs, check.HasLen, 0)
+}
+
+func (s *ServiceInstanceSuite) TestAddRemoveTeam(c *check.C) {
+	err := s.ServiceInstanceStorage.Insert(context.TODO(), service.ServiceInstance{Name: "db1", ServiceName: "mysql", Teams: []string{"dev"}})
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.AddTeam(context.TODO(), "mysql", "db1", "ops")
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.AddTeam(context.TODO(), "mysql", "db1", "ops")
+	c.Assert(err, check.IsNil)
+	dbInstance, err := s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.Teams, check.DeepEquals, []string{"dev", "ops"})
+	err = s.ServiceInstanceStorage.RemoveTeam(context.TODO(), "mysql", "db1", "dev")
+	c.Assert(err, check.IsNil)
+	dbInstance, err = s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.Teams, check.DeepEquals, []string{"ops"})
+	err = s.ServiceInstanceStorage.AddTeam(context.TODO(), "mysql", "unknown", "ops")
+	c.Assert(err, check.Equals, service.ErrServiceInstanceNotFound)
+}
+
+func (s *ServiceInstanceSuite) TestRenameTeam(c *check.C) {
+	instances := []service.ServiceInstance{
+		{Name: "db1", ServiceName: "mysql", TeamOwner: "ops", Teams: []string{"ops", "dev"}},
+		{Name: "db2", ServiceName: "mysql", TeamOwner: "dev", Teams: []string{"dev"}},
+	}
+	for _, si := range instances {
+		err := s.ServiceInstanceStorage.Insert(context.TODO(), si)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.ServiceInstanceStorage.RenameTeam(context.TODO(), "ops", "sre")
+	c.Assert(err, check.IsNil)
+	db1, err := s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	c.Assert(db1.TeamOwner, check.Equals, "sre")
+	c.Assert(db1.Teams, check.DeepEquals, []string{"dev", "sre"})
+	db2, err := s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db2")
+	c.Assert(err, check.IsNil)
+	c.Assert(db2.TeamOwner, check.Equals, "dev")
+	c.Assert(db2.Teams, check.DeepEquals, []string{"dev"})
+}
+
+func (s *ServiceInstanceSuite) TestDelete(c *check.C) {
+	err := s.ServiceInstanceStorage.Insert(context.TODO(), service.ServiceInstance{Name: "db1", ServiceName: "mysql"})
+	c.Assert(err, check.IsNil)
+	err = s.ServiceInstanceStorage.Delete(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.IsNil)
+	_, err = s.ServiceInstanceStorage.FindByName(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.Equals, service.ErrServiceInstanceNotFound)
+	err = s.ServiceInstanceStorage.Delete(context.TODO(), "mysql", "db1")
+	c.Assert(err, check.Equals, service.ErrServiceInstanceNotFound)
+}
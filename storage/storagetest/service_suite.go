@@ -0,0 +1,154 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tsuru/tsuru/types/service"
+	check "gopkg.in/check.v1"
+)
+
+type ServiceSuite struct {
+	SuiteHooks
+	ServiceStorage service.ServiceStorage
+}
+
+func serviceNames(services []service.Service) []string {
+	names := make([]string, len(services))
+	for i, s := range services {
+		names[i] = s.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *ServiceSuite) TestInsertAndFindByName(c *check.C) {
+	svc := service.Service{
+		Name:       "mysql",
+		Username:   "mysql",
+		Password:   "secret",
+		Endpoint:   map[string]string{"production": "http://mysql.api"},
+		OwnerTeams: []string{"dba"},
+		Teams:      []string{"dba", "dev"},
+		Doc:        "some docs",
+		Encoding:   "json",
+	}
+	err := s.ServiceStorage.Insert(context.TODO(), svc)
+	c.Assert(err, check.IsNil)
+	dbService, err := s.ServiceStorage.FindByName(context.TODO(), "mysql")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbService, check.DeepEquals, &svc)
+	_, err = s.ServiceStorage.FindByName(context.TODO(), "unknown")
+	c.Assert(err, check.Equals, service.ErrServiceNotFound)
+}
+
+func (s *ServiceSuite) TestInsertDuplicated(c *check.C) {
+	svc := service.Service{Name: "mysql"}
+	err := s.ServiceStorage.Insert(context.TODO(), svc)
+	c.Assert(err, check.IsNil)
+	err = s.ServiceStorage.Insert(context.TODO(), svc)
+	c.Assert(err, check.Equals, service.ErrServiceAlreadyExists)
+}
+
+func (s *ServiceSuite) TestUpdate(c *check.C) {
+	svc := service.Service{Name: "mysql", OwnerTeams: []string{"dba"}, Teams: []string{}, Endpoint: map[string]string{}}
+	err := s.ServiceStorage.Insert(context.TODO(), svc)
+	c.Assert(err, check.IsNil)
+	svc.Doc = "new docs"
+	svc.IsRestricted = true
+	svc.Teams = []string{"dev"}
+	err = s.ServiceStorage.Update(context.TODO(), svc)
+	c.Assert(err, check.IsNil)
+	dbService, err := s.ServiceStorage.FindByName(context.TODO(), "mysql")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbService, check.DeepEquals, &svc)
+	err = s.ServiceStorage.Update(context.TODO(), service.Service{Name: "unknown"})
+	c.Assert(err, check.Equals, service.ErrServiceNotFound)
+}
+
+func (s *ServiceSuite) TestFindAll(c *check.C) {
+	services := []service.Service{
+		{Name: "mysql", OwnerTeams: []string{"dba"}, Teams: []string{"dev"}, IsRestricted: true},
+		{Name: "redis", OwnerTeams: []string{"cache"}, Teams: []string{"ops"}, IsRestricted: true},
+		{Name: "mongodb", OwnerTeams: []string{"dba"}},
+	}
+	for _, svc := range services {
+		err := s.ServiceStorage.Insert(context.TODO(), svc)
+		c.Assert(err, check.IsNil)
+	}
+	tt := []struct {
+		filter   *service.ServiceFilter
+		expected []string
+	}{
+		{nil, []string{"mongodb", "mysql", "redis"}},
+		{&service.ServiceFilter{}, []string{}},
+		{&service.ServiceFilter{Teams: []string{}}, []string{}},
+		{&service.ServiceFilter{Unrestricted: true}, []string{"mongodb"}},
+		{&service.ServiceFilter{Teams: []string{"ops"}}, []string{"redis"}},
+		{&service.ServiceFilter{OwnerTeams: []string{"dba"}}, []string{"mongodb", "mysql"}},
+		{&service.ServiceFilter{Names: []string{"redis"}}, []string{"redis"}},
+		{&service.ServiceFilter{Unrestricted: true, Teams: []string{"dev"}}, []string{"mongodb", "mysql"}},
+	}
+	for i, t := range tt {
+		result, err := s.ServiceStorage.FindAll(context.TODO(), t.filter)
+		c.Assert(err, check.IsNil)
+		c.Check(serviceNames(result), check.DeepEquals, t.expected, check.Commentf("(%d)", i))
+	}
+}
+
+func (s *ServiceSuite) TestSetManifest(c *check.C) {
+	err := s.ServiceStorage.Insert(context.TODO(), service.Service{Name: "mysql"})
+	c.Assert(err, check.IsNil)
+	manifest := &service.ServiceManifest{
+		Enabled:    true,
+		Operations: []service.ManifestOperation{{Method: "POST", Path: "/resources", Action: "service-instance.create"}},
+	}
+	err = s.ServiceStorage.SetManifest(context.TODO(), "mysql", manifest)
+	c.Assert(err, check.IsNil)
+	dbService, err := s.ServiceStorage.FindByName(context.TODO(), "mysql")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbService.Manifest, check.DeepEquals, manifest)
+	err = s.ServiceStorage.SetManifest(context.TODO(), "mysql", nil)
+	c.Assert(err, check.IsNil)
+	dbService, err = s.ServiceStorage.FindByName(context.TODO(), "mysql")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbService.Manifest, check.IsNil)
+	err = s.ServiceStorage.SetManifest(context.TODO(), "unknown", manifest)
+	c.Assert(err, check.Equals, service.ErrServiceNotFound)
+}
+
+func (s *ServiceSuite) TestRenameTeam(c *check.C) {
+	services := []service.Service{
+		{Name: "mysql", OwnerTeams: []string{"dba", "ops"}, Teams: []string{"dba"}},
+		{Name: "redis", OwnerTeams: []string{"cache"}, Teams: []string{"ops", "dev"}},
+	}
+	for _, svc := range services {
+		err := s.ServiceStorage.Insert(context.TODO(), svc)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.ServiceStorage.RenameTeam(context.TODO(), "ops", "sre")
+	c.Assert(err, check.IsNil)
+	mysql, err := s.ServiceStorage.FindByName(context.TODO(), "mysql")
+	c.Assert(err, check.IsNil)
+	c.Assert(mysql.OwnerTeams, check.DeepEquals, []string{"dba", "sre"})
+	c.Assert(mysql.Teams, check.DeepEquals, []string{"dba"})
+	redis, err := s.ServiceStorage.FindByName(context.TODO(), "redis")
+	c.Assert(err, check.IsNil)
+	c.Assert(redis.OwnerTeams, check.DeepEquals, []string{"cache"})
+	c.Assert(redis.Teams, check.DeepEquals, []string{"dev", "sre"})
+}
+
+func (s *ServiceSuite) TestDelete(c *check.C) {
+	err := s.ServiceStorage.Insert(context.TODO(), service.Service{Name: "mysql"})
+	c.Assert(err, check.IsNil)
+	err = s.ServiceStorage.Delete(context.TODO(), "mysql")
+	c.Assert(err, check.IsNil)
+	_, err = s.ServiceStorage.FindByName(context.TODO(), "mysql")
+	c.Assert(err, check.Equals, service.ErrServiceNotFound)
+	err = s.ServiceStorage.Delete(context.TODO(), "mysql")
+	c.Assert(err, check.Equals, service.ErrServiceNotFound)
+}
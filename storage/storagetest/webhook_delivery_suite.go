@@ -0,0 +1,176 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+type WebhookDeliverySuite struct {
+	SuiteHooks
+	WebhookDeliveryStorage eventTypes.WebhookDeliveryStorage
+}
+
+func deliveryIDs(deliveries []eventTypes.WebhookDelivery) []string {
+	var ids []string
+	for _, d := range deliveries {
+		ids = append(ids, d.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *WebhookDeliverySuite) TestInsertAndFindByID(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	d := eventTypes.WebhookDelivery{
+		ID:            "d1",
+		WebhookName:   "wh1",
+		EventID:       "evt1",
+		Status:        eventTypes.WebhookDeliveryPending,
+		MaxAttempts:   3,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		NextAttemptAt: now,
+	}
+	err := s.WebhookDeliveryStorage.Insert(context.TODO(), d)
+	c.Assert(err, check.IsNil)
+	result, err := s.WebhookDeliveryStorage.FindByID(context.TODO(), "d1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &d)
+	_, err = s.WebhookDeliveryStorage.FindByID(context.TODO(), "not-found")
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
+
+func (s *WebhookDeliverySuite) TestUpdate(c *check.C) {
+	d := eventTypes.WebhookDelivery{ID: "d1", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryPending}
+	err := s.WebhookDeliveryStorage.Insert(context.TODO(), d)
+	c.Assert(err, check.IsNil)
+	d.Status = eventTypes.WebhookDeliveryDead
+	d.Attempts = 2
+	d.LastError = "connection refused"
+	err = s.WebhookDeliveryStorage.Update(context.TODO(), d)
+	c.Assert(err, check.IsNil)
+	result, err := s.WebhookDeliveryStorage.FindByID(context.TODO(), "d1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Status, check.Equals, eventTypes.WebhookDeliveryDead)
+	c.Assert(result.Attempts, check.Equals, 2)
+	c.Assert(result.LastError, check.Equals, "connection refused")
+	err = s.WebhookDeliveryStorage.Update(context.TODO(), eventTypes.WebhookDelivery{ID: "other"})
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
+
+func (s *WebhookDeliverySuite) TestFind(c *check.C) {
+	now := time.Now().UTC()
+	deliveries := []eventTypes.WebhookDelivery{
+		{ID: "d1", WebhookName: "wh1", Status: eventTypes.WebhookDeliverySucceeded, CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: "d2", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "d3", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, CreatedAt: now.Add(-time.Minute)},
+		{ID: "d4", WebhookName: "wh2", Status: eventTypes.WebhookDeliveryDead, CreatedAt: now},
+	}
+	for _, d := range deliveries {
+		err := s.WebhookDeliveryStorage.Insert(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	result, err := s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "wh1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 3)
+	c.Assert(result[0].ID, check.Equals, "d3")
+	result, err = s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{Status: eventTypes.WebhookDeliveryDead})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveryIDs(result), check.DeepEquals, []string{"d2", "d3", "d4"})
+	result, err = s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, Limit: 1})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveryIDs(result), check.DeepEquals, []string{"d3"})
+}
+
+func (s *WebhookDeliverySuite) TestAcquireNext(c *check.C) {
+	now := time.Now().UTC()
+	deliveries := []eventTypes.WebhookDelivery{
+		{ID: "d1", Status: eventTypes.WebhookDeliveryPending, NextAttemptAt: now.Add(time.Hour)},
+		{ID: "d2", Status: eventTypes.WebhookDeliveryPending, NextAttemptAt: now.Add(-time.Minute)},
+		{ID: "d3", Status: eventTypes.WebhookDeliveryDead, NextAttemptAt: now.Add(-time.Hour)},
+		{ID: "d4", Status: eventTypes.WebhookDeliveryPending, NextAttemptAt: now.Add(-time.Minute), LockedUntil: now.Add(time.Minute)},
+		{ID: "d5", Status: eventTypes.WebhookDeliveryPending, NextAttemptAt: now.Add(-2 * time.Minute)},
+	}
+	for _, d := range deliveries {
+		err := s.WebhookDeliveryStorage.Insert(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	d, err := s.WebhookDeliveryStorage.AcquireNext(context.TODO(), time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(d.ID, check.Equals, "d5")
+	c.Assert(d.LockedUntil.After(now), check.Equals, true)
+	d, err = s.WebhookDeliveryStorage.AcquireNext(context.TODO(), time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(d.ID, check.Equals, "d2")
+	_, err = s.WebhookDeliveryStorage.AcquireNext(context.TODO(), time.Minute)
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
+
+func (s *WebhookDeliverySuite) TestRedrive(c *check.C) {
+	deliveries := []eventTypes.WebhookDelivery{
+		{ID: "d1", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, Attempts: 5},
+		{ID: "d2", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, Attempts: 5},
+		{ID: "d3", WebhookName: "wh1", Status: eventTypes.WebhookDeliverySucceeded, Attempts: 1},
+		{ID: "d4", WebhookName: "wh2", Status: eventTypes.WebhookDeliveryDead, Attempts: 5},
+	}
+	for _, d := range deliveries {
+		err := s.WebhookDeliveryStorage.Insert(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	n, err := s.WebhookDeliveryStorage.Redrive(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "wh1", ID: "d1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 1)
+	d, err := s.WebhookDeliveryStorage.FindByID(context.TODO(), "d1")
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Status, check.Equals, eventTypes.WebhookDeliveryPending)
+	c.Assert(d.Attempts, check.Equals, 0)
+	n, err = s.WebhookDeliveryStorage.Redrive(context.TODO(), eventTypes.WebhookDeliveryFilter{WebhookName: "wh1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 1)
+	result, err := s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{Status: eventTypes.WebhookDeliveryDead})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveryIDs(result), check.DeepEquals, []string{"d4"})
+}
+
+func (s *WebhookDeliverySuite) TestDeleteByWebhook(c *check.C) {
+	for _, d := range []eventTypes.WebhookDelivery{
+		{ID: "d1", WebhookName: "wh1"},
+		{ID: "d2", WebhookName: "wh1"},
+		{ID: "d3", WebhookName: "wh2"},
+	} {
+		err := s.WebhookDeliveryStorage.Insert(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.WebhookDeliveryStorage.DeleteByWebhook(context.TODO(), "wh1")
+	c.Assert(err, check.IsNil)
+	result, err := s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveryIDs(result), check.DeepEquals, []string{"d3"})
+}
+
+func (s *WebhookDeliverySuite) TestPrune(c *check.C) {
+	now := time.Now().UTC()
+	for _, d := range []eventTypes.WebhookDelivery{
+		{ID: "d1", WebhookName: "wh1", Status: eventTypes.WebhookDeliverySucceeded, CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: "d2", WebhookName: "wh1", Status: eventTypes.WebhookDeliverySucceeded, CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "d3", WebhookName: "wh1", Status: eventTypes.WebhookDeliverySucceeded, CreatedAt: now.Add(-time.Minute)},
+		{ID: "d4", WebhookName: "wh1", Status: eventTypes.WebhookDeliveryDead, CreatedAt: now.Add(-time.Hour)},
+		{ID: "d5", WebhookName: "wh2", Status: eventTypes.WebhookDeliverySucceeded, CreatedAt: now.Add(-time.Hour)},
+	} {
+		err := s.WebhookDeliveryStorage.Insert(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	err := s.WebhookDeliveryStorage.Prune(context.TODO(), "wh1", 2)
+	c.Assert(err, check.IsNil)
+	result, err := s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveryIDs(result), check.DeepEquals, []string{"d2", "d3", "d4", "d5"})
+}
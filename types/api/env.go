@@ -25,4 +25,5 @@ type Env struct {
 	Alias     string
 	Private   *bool  `json:"private,omitempty"`
 	ManagedBy string `json:"-" bson:"managedBy"`
+	SecretRef string `json:"secretRef,omitempty"`
 }
@@ -7,6 +7,7 @@ package app
 import (
 	"context"
 	"net/url"
+	"time"
 
 	"github.com/tsuru/tsuru/types/app/image"
 	"github.com/tsuru/tsuru/types/bind"
@@ -43,6 +44,12 @@ type App struct {
 	Metadata        Metadata
 	Processes       []Process
 
+	// RoutingWeights maps version numbers to the percentage of traffic they
+	// receive while a canary deploy is in progress.
+	RoutingWeights map[int]int `bson:",omitempty"`
+
+	AutoRollback *AutoRollback `bson:",omitempty"`
+
 	// UUID is a v4 UUID lazily generated on the first call to GetUUID()
 	UUID string
 
@@ -67,6 +74,17 @@ type AppRouter struct {
 	StatusDetail string            `json:"status-detail,omitempty" bson:"-"`
 }
 
+// AutoRollback configures the post-deploy watch window of an app. While the
+// window is open, the deployed version is rolled back to the previous
+// successful one if its units restart more than MaxRestarts times or if
+// MaxFailedChecks consecutive checks find units or routers not ready.
+type AutoRollback struct {
+	Enabled         bool          `json:"enabled"`
+	Window          time.Duration `json:"window"`
+	MaxRestarts     int           `json:"maxRestarts"`
+	MaxFailedChecks int           `json:"maxFailedChecks"`
+}
+
 type RoutableAddresses struct {
 	Prefix    string
 	Addresses []*url.URL
@@ -98,6 +116,49 @@ type AppService interface {
 	RemoveInstance(ctx context.Context, app *App, removeArgs bind.RemoveInstanceArgs) error
 }
 
+// AppStorage keeps the app documents. Its update methods ignore apps missing
+// from the storage.
+type AppStorage interface {
+	Insert(ctx context.Context, app *App) error
+	// Update replaces the stored app.
+	Update(ctx context.Context, app *App) error
+	FindByName(ctx context.Context, name string) (*App, error)
+	// List returns the apps matching the filter, or every app when filter is
+	// nil. Statuses are ignored, as they're known by the provisioners only,
+	// and Extra accepts the "name", "teams", "teamowner" and "pool" fields.
+	List(ctx context.Context, filter *Filter) ([]App, error)
+	// FindByCName returns the apps using the cname.
+	FindByCName(ctx context.Context, cname string) ([]App, error)
+	// FindNamesByTeam returns the names of the apps the team has access to.
+	FindNamesByTeam(ctx context.Context, team string) ([]string, error)
+	AddTeam(ctx context.Context, appName, team string) error
+	RemoveTeam(ctx context.Context, appName, team string) error
+	// RenameTeam replaces the team in the owner and teams of every app.
+	RenameTeam(ctx context.Context, oldName, newName string) error
+	AddCNames(ctx context.Context, appName string, cnames []string) error
+	RemoveCNames(ctx context.Context, appName string, cnames []string) error
+	SetCertIssuer(ctx context.Context, appName, cname, issuer string) error
+	RemoveCertIssuer(ctx context.Context, appName, cname string) error
+	// SetRouters replaces the routers of the app, dropping its legacy
+	// Router and RouterOpts fields.
+	SetRouters(ctx context.Context, appName string, routers []AppRouter) error
+	// SetRoutingWeights replaces the canary routing weights of the app,
+	// removing them when weights is empty.
+	SetRoutingWeights(ctx context.Context, appName string, weights map[int]int) error
+	// SetAutoRollback replaces the auto rollback config of the app, removing
+	// it when cfg is nil.
+	SetAutoRollback(ctx context.Context, appName string, cfg *AutoRollback) error
+	SetEnvs(ctx context.Context, appName string, envs map[string]bind.EnvVar) error
+	SetServiceEnvs(ctx context.Context, appName string, envs []bind.ServiceEnvVar) error
+	SetUpdatePlatform(ctx context.Context, appName string, updatePlatform bool) error
+	IncrementDeploys(ctx context.Context, appName string) error
+	// ReencryptEnvs writes the envs of every app with private values back,
+	// encrypting them with the active key, and returns the number of
+	// updated apps.
+	ReencryptEnvs(ctx context.Context) (int, error)
+	Delete(ctx context.Context, name string) error
+}
+
 type AppInfo struct {
 	Name        string   `json:"name"`
 	Platform    string   `json:"platform"`
@@ -112,6 +173,8 @@ type AppInfo struct {
 	Tags        []string `json:"tags"`
 	Metadata    Metadata `json:"metadata"`
 
+	AutoRollback *AutoRollback `json:"autoRollback,omitempty"`
+
 	Units                   []provision.Unit                 `json:"units"`
 	InternalAddresses       []AppInternalAddress             `json:"internalAddresses,omitempty"`
 	Autoscale               []provision.AutoScaleSpec        `json:"autoscale,omitempty"`
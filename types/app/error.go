@@ -13,6 +13,7 @@ import (
 
 var (
 	ErrAppNotFound            = errors.New("App not found")
+	ErrAppAlreadyExists       = errors.New("there is already an app with this name")
 	ErrPlanNotFound           = errors.New("plan not found")
 	ErrPlanAlreadyExists      = errors.New("plan already exists")
 	ErrPlanDefaultAmbiguous   = errors.New("more than one default plan found")
@@ -31,6 +31,22 @@ type ListLogArgs struct {
 	Units        []string
 	Limit        int
 	InvertSource bool
+	// Since and Until, when set, only match entries dated between them,
+	// inclusive.
+	Since time.Time
+	Until time.Time
+	// Filter matches the entries whose message contains it, or matches it
+	// as a regular expression when FilterRegex is set.
+	Filter      string
+	FilterRegex bool
+	// Levels matches the JSON or logfmt structured entries with any of the
+	// levels.
+	Levels []string
+}
+
+// Filtered reports whether args has any time, message or level filter.
+func (args ListLogArgs) Filtered() bool {
+	return !args.Since.IsZero() || !args.Until.IsZero() || args.Filter != "" || len(args.Levels) > 0
 }
 
 // Applog represents a log entry.
@@ -0,0 +1,126 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	logLevelKeys = []string{"level", "lvl", "severity", "loglevel"}
+
+	logLevelAliases = map[string]string{
+		"dbg":   "debug",
+		"inf":   "info",
+		"warn":  "warning",
+		"err":   "error",
+		"crit":  "critical",
+		"panic": "fatal",
+	}
+)
+
+// LogMatcher matches log entries against the time, message and level
+// filters of ListLogArgs. Source and unit filters are left to the log
+// services, which usually apply them before reading the entries.
+type LogMatcher struct {
+	args    ListLogArgs
+	pattern *regexp.Regexp
+	levels  map[string]struct{}
+}
+
+// NewLogMatcher validates the filters in args, returning a matcher for them.
+func NewLogMatcher(args ListLogArgs) (*LogMatcher, error) {
+	m := &LogMatcher{args: args}
+	if !args.Since.IsZero() && !args.Until.IsZero() && args.Until.Before(args.Since) {
+		return nil, errors.New("until must not be before since")
+	}
+	if args.Filter != "" && args.FilterRegex {
+		pattern, err := regexp.Compile(args.Filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid log filter")
+		}
+		m.pattern = pattern
+	}
+	if len(args.Levels) > 0 {
+		m.levels = make(map[string]struct{}, len(args.Levels))
+		for _, level := range args.Levels {
+			m.levels[normalizeLogLevel(level)] = struct{}{}
+		}
+	}
+	return m, nil
+}
+
+// Match reports whether the entry matches every filter in the matcher.
+func (m *LogMatcher) Match(entry *Applog) bool {
+	if !m.args.Since.IsZero() && entry.Date.Before(m.args.Since) {
+		return false
+	}
+	if !m.args.Until.IsZero() && entry.Date.After(m.args.Until) {
+		return false
+	}
+	if m.args.Filter != "" {
+		if m.pattern != nil {
+			if !m.pattern.MatchString(entry.Message) {
+				return false
+			}
+		} else if !strings.Contains(entry.Message, m.args.Filter) {
+			return false
+		}
+	}
+	if m.levels != nil {
+		if _, ok := m.levels[LogLevel(entry.Message)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// LogLevel returns the normalized level of a JSON or logfmt structured log
+// message, or an empty string when the message has no level field.
+func LogLevel(message string) string {
+	message = strings.TrimSpace(message)
+	if strings.HasPrefix(message, "{") {
+		var fields map[string]interface{}
+		if json.Unmarshal([]byte(message), &fields) != nil {
+			return ""
+		}
+		for key, value := range fields {
+			if isLogLevelKey(key) {
+				if level, ok := value.(string); ok {
+					return normalizeLogLevel(level)
+				}
+			}
+		}
+		return ""
+	}
+	for _, field := range strings.Fields(message) {
+		key, value, found := strings.Cut(field, "=")
+		if found && isLogLevelKey(key) {
+			return normalizeLogLevel(strings.Trim(value, `"'`))
+		}
+	}
+	return ""
+}
+
+func isLogLevelKey(key string) bool {
+	for _, k := range logLevelKeys {
+		if strings.EqualFold(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeLogLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if alias, ok := logLevelAliases[level]; ok {
+		return alias
+	}
+	return level
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s S) TestLogLevel(c *check.C) {
+	tt := []struct {
+		message  string
+		expected string
+	}{
+		{`{"level":"error","msg":"failed"}`, "error"},
+		{`  {"Severity":"WARN","msg":"slow"}`, "warning"},
+		{`{"lvl":10,"msg":"numeric"}`, ""},
+		{`{"msg":"no level"}`, ""},
+		{`{invalid json`, ""},
+		{`time=2026-01-01 level=info msg="started"`, "info"},
+		{`lvl="debug" msg=x`, "debug"},
+		{`level=err`, "error"},
+		{`plain message with level words`, ""},
+	}
+	for i, t := range tt {
+		c.Check(LogLevel(t.message), check.Equals, t.expected, check.Commentf("(%d)", i))
+	}
+}
+
+func (s S) TestNewLogMatcherInvalid(c *check.C) {
+	now := time.Now()
+	_, err := NewLogMatcher(ListLogArgs{Since: now, Until: now.Add(-time.Minute)})
+	c.Assert(err, check.ErrorMatches, "until must not be before since")
+	_, err = NewLogMatcher(ListLogArgs{Filter: "(", FilterRegex: true})
+	c.Assert(err, check.ErrorMatches, "invalid log filter: .*")
+	_, err = NewLogMatcher(ListLogArgs{Filter: "("})
+	c.Assert(err, check.IsNil)
+}
+
+func (s S) TestLogMatcherMatch(c *check.C) {
+	base := time.Date(2026, time.March, 2, 14, 0, 0, 0, time.UTC)
+	entry := func(minute int, message string) *Applog {
+		return &Applog{Date: base.Add(time.Duration(minute) * time.Minute), Message: message}
+	}
+	tt := []struct {
+		args     ListLogArgs
+		entry    *Applog
+		expected bool
+	}{
+		{ListLogArgs{}, entry(0, "anything"), true},
+		{ListLogArgs{Since: base.Add(2 * time.Minute)}, entry(1, "x"), false},
+		{ListLogArgs{Since: base.Add(2 * time.Minute)}, entry(2, "x"), true},
+		{ListLogArgs{Until: base.Add(10 * time.Minute)}, entry(10, "x"), true},
+		{ListLogArgs{Until: base.Add(10 * time.Minute)}, entry(11, "x"), false},
+		{ListLogArgs{Filter: "timeout"}, entry(0, "read timeout after 30s"), true},
+		{ListLogArgs{Filter: "timeout"}, entry(0, "read Timeout"), false},
+		{ListLogArgs{Filter: "(?i)time.?out", FilterRegex: true}, entry(0, "read TimeOut"), true},
+		{ListLogArgs{Filter: "^GET", FilterRegex: true}, entry(0, "POST /"), false},
+		{ListLogArgs{Levels: []string{"ERROR", "warn"}}, entry(0, `level=warning msg=slow`), true},
+		{ListLogArgs{Levels: []string{"error"}}, entry(0, `{"level":"info"}`), false},
+		{ListLogArgs{Levels: []string{"error"}}, entry(0, "unstructured"), false},
+		{ListLogArgs{Filter: "db", Levels: []string{"error"}}, entry(0, `level=error msg="db down"`), true},
+	}
+	for i, t := range tt {
+		m, err := NewLogMatcher(t.args)
+		c.Assert(err, check.IsNil)
+		c.Check(m.Match(t.entry), check.Equals, t.expected, check.Commentf("(%d)", i))
+	}
+}
@@ -51,6 +51,9 @@ type AppVersion interface {
 	String() string
 	ToggleEnabled(enabled bool, reason string) error
 	UpdatePastUnits(process string, replicas int) error
+	// SetBuildPhase stores the phase, replacing the last phase with the same
+	// name when it's still running.
+	SetBuildPhase(phase BuildPhase) error
 }
 
 type AddVersionDataArgs struct {
@@ -86,6 +89,59 @@ type AppVersionInfo struct {
 	DeploySuccessful bool                   `json:"deploySuccessful"`
 	MarkedToRemoval  bool                   `json:"markedToRemoval"`
 	PastUnits        map[string]int         `json:"pastUnits"`
+	BuildPhases      []BuildPhase           `json:"buildPhases"`
+}
+
+type BuildPhaseStatus string
+
+const (
+	BuildPhaseRunning   = BuildPhaseStatus("running")
+	BuildPhaseSucceeded = BuildPhaseStatus("succeeded")
+	BuildPhaseFailed    = BuildPhaseStatus("failed")
+)
+
+// Names of the phases of a version. The build protocol doesn't report the
+// steps taken by the build service, so BuildPhaseBuild covers the whole
+// build service call, including the build hooks and the image push.
+const (
+	BuildPhaseFetchSource = "fetch-source"
+	BuildPhaseBuild       = "build"
+	BuildPhaseRollout     = "rollout"
+)
+
+// BuildPhase is a step of the build or deploy of a version. Its output is
+// kept in the log of the event EventID, between StartTime and EndTime.
+type BuildPhase struct {
+	Name      string           `json:"name"`
+	Status    BuildPhaseStatus `json:"status"`
+	StartTime time.Time        `json:"startTime"`
+	EndTime   time.Time        `json:"endTime"`
+	EventID   string           `json:"eventID"`
+}
+
+// Duration returns how long the phase took, or has been running for.
+func (p BuildPhase) Duration() time.Duration {
+	if p.EndTime.IsZero() {
+		return time.Since(p.StartTime)
+	}
+	return p.EndTime.Sub(p.StartTime)
+}
+
+// AppVersionLog is the build and deploy output of a version, split by phase.
+type AppVersionLog struct {
+	Version int                  `json:"version"`
+	Phases  []AppVersionLogPhase `json:"phases"`
+}
+
+type AppVersionLogPhase struct {
+	BuildPhase
+	DurationSeconds float64              `json:"durationSeconds"`
+	Log             []AppVersionLogEntry `json:"log"`
+}
+
+type AppVersionLogEntry struct {
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
 }
 
 type NewVersionArgs struct {
@@ -113,6 +169,9 @@ type AppVersionStorage interface {
 	commonAppVersion
 	UpdateVersion(ctx context.Context, appName string, vi *AppVersionInfo, opts ...*AppVersionWriteOptions) error
 	UpdateVersionSuccess(ctx context.Context, appName string, vi *AppVersionInfo, opts ...*AppVersionWriteOptions) error
+	// UpdateBuildPhases replaces the build phases of a version, leaving the
+	// rest of it untouched.
+	UpdateBuildPhases(ctx context.Context, appName string, version int, phases []BuildPhase, opts ...*AppVersionWriteOptions) error
 	NewAppVersion(ctx context.Context, args NewVersionArgs) (*AppVersionInfo, error)
 }
 
@@ -4,11 +4,17 @@
 
 package auth
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var ErrGroupNotFound = errors.New("group not found")
 
 type Group struct {
-	Name  string         `json:"name"`
-	Roles []RoleInstance `json:"roles,omitempty"`
+	Name    string         `json:"name"`
+	Roles   []RoleInstance `json:"roles,omitempty"`
+	Members []string       `json:"members,omitempty" bson:",omitempty"`
 }
 
 type GroupStorage interface {
@@ -19,4 +25,9 @@ type GroupService interface {
 	List(ctx context.Context, filter []string) ([]Group, error)
 	AddRole(ctx context.Context, name, roleName, contextValue string) error
 	RemoveRole(ctx context.Context, name, roleName, contextValue string) error
+	ListByMember(ctx context.Context, email string) ([]Group, error)
+	SetMembers(ctx context.Context, name string, members []string) error
+	AddMembers(ctx context.Context, name string, members []string) error
+	RemoveMembers(ctx context.Context, name string, members []string) error
+	Delete(ctx context.Context, name string) error
 }
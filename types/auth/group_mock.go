@@ -11,9 +11,14 @@ var (
 )
 
 type MockGroupService struct {
-	OnAddRole    func(name, roleName, contextValue string) error
-	OnRemoveRole func(name, roleName, contextValue string) error
-	OnList       func(filter []string) ([]Group, error)
+	OnAddRole       func(name, roleName, contextValue string) error
+	OnRemoveRole    func(name, roleName, contextValue string) error
+	OnList          func(filter []string) ([]Group, error)
+	OnListByMember  func(email string) ([]Group, error)
+	OnSetMembers    func(name string, members []string) error
+	OnAddMembers    func(name string, members []string) error
+	OnRemoveMembers func(name string, members []string) error
+	OnDelete        func(name string) error
 }
 
 func (m *MockGroupService) AddRole(ctx context.Context, name string, roleName, contextValue string) error {
@@ -36,3 +41,38 @@ func (m *MockGroupService) List(ctx context.Context, filter []string) ([]Group,
 	}
 	return m.OnList(filter)
 }
+
+func (m *MockGroupService) ListByMember(ctx context.Context, email string) ([]Group, error) {
+	if m.OnListByMember == nil {
+		return nil, nil
+	}
+	return m.OnListByMember(email)
+}
+
+func (m *MockGroupService) SetMembers(ctx context.Context, name string, members []string) error {
+	if m.OnSetMembers == nil {
+		return nil
+	}
+	return m.OnSetMembers(name, members)
+}
+
+func (m *MockGroupService) AddMembers(ctx context.Context, name string, members []string) error {
+	if m.OnAddMembers == nil {
+		return nil
+	}
+	return m.OnAddMembers(name, members)
+}
+
+func (m *MockGroupService) RemoveMembers(ctx context.Context, name string, members []string) error {
+	if m.OnRemoveMembers == nil {
+		return nil
+	}
+	return m.OnRemoveMembers(name, members)
+}
+
+func (m *MockGroupService) Delete(ctx context.Context, name string) error {
+	if m.OnDelete == nil {
+		return nil
+	}
+	return m.OnDelete(name)
+}
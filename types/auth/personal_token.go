@@ -0,0 +1,67 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type PersonalTokenCreateArgs struct {
+	Name        string `json:"name" form:"name"`
+	Description string `json:"description" form:"description"`
+	ExpiresIn   int    `json:"expires_in" form:"expires_in"`
+
+	AllowedPermissions []string `json:"allowed_permissions" form:"allowed_permissions"`
+}
+
+// PersonalToken is an API token owned by a user, acting with the user
+// permissions narrowed to AllowedPermissions, when set. The token value is
+// only exposed when the token is created.
+type PersonalToken struct {
+	Token              string    `json:"token,omitempty"`
+	TokenID            string    `json:"token_id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	UserEmail          string    `json:"user_email"`
+	CreatedAt          time.Time `json:"created_at"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	LastAccess         time.Time `json:"last_access"`
+	AllowedPermissions []string  `json:"allowed_permissions,omitempty"`
+	ExpiryNotified     bool      `json:"-"`
+}
+
+type PersonalTokenStorage interface {
+	Insert(context.Context, PersonalToken) error
+	FindByToken(ctx context.Context, token string) (*PersonalToken, error)
+	FindByTokenID(ctx context.Context, tokenID string) (*PersonalToken, error)
+	FindByUser(ctx context.Context, email string) ([]PersonalToken, error)
+	// FindExpiring returns the tokens expiring before the given time whose
+	// owners weren't notified yet.
+	FindExpiring(ctx context.Context, before time.Time) ([]PersonalToken, error)
+	UpdateLastAccess(ctx context.Context, token string) error
+	SetExpiryNotified(ctx context.Context, tokenID string) error
+	Delete(ctx context.Context, tokenID string) error
+	DeleteByUser(ctx context.Context, email string) error
+}
+
+type PersonalTokenService interface {
+	Create(ctx context.Context, email string, args PersonalTokenCreateArgs) (PersonalToken, error)
+	List(ctx context.Context, email string) ([]PersonalToken, error)
+	FindByTokenID(ctx context.Context, tokenID string) (PersonalToken, error)
+	Delete(ctx context.Context, tokenID string) error
+	DeleteByUser(ctx context.Context, email string) error
+	Authenticate(ctx context.Context, header string) (Token, error)
+	// NotifyExpiring calls notify for each token expiring in the given
+	// window, flagging them so each owner is only notified once per token.
+	NotifyExpiring(ctx context.Context, window time.Duration, notify func(PersonalToken)) error
+}
+
+var (
+	ErrPersonalTokenAlreadyExists = errors.New("personal token already exists")
+	ErrPersonalTokenNotFound      = errors.New("personal token not found")
+	ErrPersonalTokenExpired       = errors.New("personal token expired")
+)
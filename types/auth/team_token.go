@@ -7,6 +7,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -15,6 +16,14 @@ type TeamTokenCreateArgs struct {
 	Description string `json:"description" form:"description"`
 	ExpiresIn   int    `json:"expires_in" form:"expires_in"`
 	Team        string `json:"team" form:"team"`
+
+	AllowedCIDRs       []string `json:"allowed_cidrs" form:"allowed_cidrs"`
+	AllowedPermissions []string `json:"allowed_permissions" form:"allowed_permissions"`
+	RateLimit          int      `json:"rate_limit" form:"rate_limit"`
+
+	// Roles are only set by internal callers, e.g. when exchanging workload
+	// identity tokens. API clients add roles after creating the token.
+	Roles []RoleInstance `json:"-" form:"-"`
 }
 
 type TeamTokenUpdateArgs struct {
@@ -22,6 +31,28 @@ type TeamTokenUpdateArgs struct {
 	Regenerate  bool   `json:"regenerate" form:"regenerate"`
 	Description string `json:"description" form:"description"`
 	ExpiresIn   int    `json:"expires_in" form:"expires_in"`
+
+	// ClearConstraints removes the current constraints before applying the
+	// ones set in the args. A negative RateLimit removes the rate limit.
+	ClearConstraints   bool     `json:"clear_constraints" form:"clear_constraints"`
+	AllowedCIDRs       []string `json:"allowed_cidrs" form:"allowed_cidrs"`
+	AllowedPermissions []string `json:"allowed_permissions" form:"allowed_permissions"`
+	RateLimit          int      `json:"rate_limit" form:"rate_limit"`
+}
+
+// TeamTokenConstraints restrict the use of a team token beyond its roles.
+// AllowedCIDRs limits the source addresses the token is accepted from,
+// AllowedPermissions narrows the permissions granted by the roles to the
+// listed schemes and their children, and RateLimit is the maximum number of
+// requests per minute accepted by each API instance.
+type TeamTokenConstraints struct {
+	AllowedCIDRs       []string `json:"allowed_cidrs,omitempty" bson:"allowed_cidrs,omitempty"`
+	AllowedPermissions []string `json:"allowed_permissions,omitempty" bson:"allowed_permissions,omitempty"`
+	RateLimit          int      `json:"rate_limit,omitempty" bson:"rate_limit,omitempty"`
+}
+
+func (c *TeamTokenConstraints) IsEmpty() bool {
+	return c == nil || (len(c.AllowedCIDRs) == 0 && len(c.AllowedPermissions) == 0 && c.RateLimit == 0)
 }
 
 type TeamToken struct {
@@ -34,6 +65,16 @@ type TeamToken struct {
 	CreatorEmail string         `json:"creator_email"`
 	Team         string         `json:"team"`
 	Roles        []RoleInstance `json:"roles,omitempty"`
+
+	Constraints *TeamTokenConstraints `json:"constraints,omitempty"`
+}
+
+// ConstrainedToken is implemented by team tokens, exposing the constraints
+// restricting their use.
+type ConstrainedToken interface {
+	NamedToken
+	TeamName() string
+	TokenConstraints() *TeamTokenConstraints
 }
 
 type TeamTokenStorage interface {
@@ -64,3 +105,30 @@ var (
 	ErrTeamTokenExpired                 = errors.New("team token expired")
 	ErrCannotRemoveTeamTokenWhoOwnsApps = errors.New("cannot remove team token who owns apps")
 )
+
+const (
+	TeamTokenRejectedSource     = "source"
+	TeamTokenRejectedPermission = "permission"
+	TeamTokenRejectedRateLimit  = "rate-limit"
+)
+
+// ErrTeamTokenRejected is returned when a valid team token is used in
+// violation of its constraints. Repeated is set for rejections after the
+// first one for the same token and reason in the same window, which are
+// recorded but not logged again.
+type ErrTeamTokenRejected struct {
+	TokenID  string
+	Team     string
+	Reason   string
+	Repeated bool
+}
+
+func (e *ErrTeamTokenRejected) Error() string {
+	switch e.Reason {
+	case TeamTokenRejectedSource:
+		return fmt.Sprintf("team token %q is not allowed from this address", e.TokenID)
+	case TeamTokenRejectedRateLimit:
+		return fmt.Sprintf("team token %q exceeded its rate limit", e.TokenID)
+	}
+	return fmt.Sprintf("team token %q is not allowed to perform this action", e.TokenID)
+}
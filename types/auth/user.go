@@ -32,6 +32,16 @@ type User struct {
 type RoleInstance struct {
 	Name         string
 	ContextValue string
+	// ExpiresAt is set on temporary assignments, which grant no permissions
+	// after it and are removed by a background worker.
+	ExpiresAt     *time.Time `json:",omitempty" bson:",omitempty"`
+	Justification string     `json:",omitempty" bson:",omitempty"`
+}
+
+// Expired returns whether the role instance is a temporary assignment that
+// expired before now.
+func (r RoleInstance) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !r.ExpiresAt.After(now)
 }
 
 type ErrTeamStillUsed struct {
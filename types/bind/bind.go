@@ -10,6 +10,9 @@ type EnvVar struct {
 	Alias     string `json:"alias"`
 	Public    bool   `json:"public"`
 	ManagedBy string `json:"managedBy,omitempty"`
+	// SecretRef references a secret kept out of tsuru, resolved when the
+	// app is deployed. Value is always empty when it's set.
+	SecretRef string `json:"secretRef,omitempty"`
 }
 
 type ServiceEnvVar struct {
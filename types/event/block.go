@@ -0,0 +1,53 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrActiveBlockNotFound = errors.New("active event block not found")
+
+type Block struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	StartTime    time.Time
+	EndTime      time.Time `bson:"endtime,omitempty"`
+	KindName     string
+	OwnerName    string
+	Target       Target            `bson:"target,omitempty"`
+	Conditions   map[string]string `bson:"conditions,omitempty"`
+	Reason       string
+	Active       bool
+	Window       *BlockWindow `bson:"window,omitempty"`
+	AllowedTeams []string     `bson:"allowedteams,omitempty"`
+	AllowedRoles []string     `bson:"allowedroles,omitempty"`
+}
+
+type BlockWindow struct {
+	Start    string
+	End      string
+	Timezone string `bson:"timezone,omitempty"`
+}
+
+// EventStorage keeps the event blocks. Events themselves aren't part of it:
+// their creation, locks, logs, approvals, listing and cleanup still use the
+// MongoDB events collection directly, so MongoDB remains required whichever
+// storage driver is configured.
+type EventStorage interface {
+	InsertBlock(ctx context.Context, b Block) error
+	// FindBlocks returns the blocks, most recently started first, filtered
+	// by their active flag when active isn't nil.
+	FindBlocks(ctx context.Context, active *bool) ([]Block, error)
+	// DeactivateBlock deactivates the active block, returning
+	// ErrActiveBlockNotFound when there's no such block.
+	DeactivateBlock(ctx context.Context, id primitive.ObjectID, endTime time.Time) error
+	// DeactivateExpiredBlocks deactivates the active blocks whose end time
+	// isn't after now.
+	DeactivateExpiredBlocks(ctx context.Context, now time.Time) error
+}
@@ -35,6 +35,7 @@ type EventData struct {
 	Log             string     `bson:",omitempty"`
 	StructuredLog   []LogEntry `bson:",omitempty"`
 	CancelInfo      CancelInfo
+	ApprovalInfo    ApprovalInfo
 	Cancelable      bool
 	Running         bool
 	Allowed         AllowedPermission
@@ -117,6 +118,22 @@ type CancelInfo struct {
 	Canceled  bool
 }
 
+// ApprovalInfo holds the approval state of events requiring a second
+// person's approval before running. Owner is the user who approved or
+// rejected the event.
+type ApprovalInfo struct {
+	Required     bool
+	Pending      bool
+	Deadline     time.Time
+	Owner        string
+	Reason       string
+	DecisionTime time.Time
+	Approved     bool
+	Rejected     bool
+	// Resumed is set once the approved operation is requested again.
+	Resumed bool
+}
+
 type AllowedPermission struct {
 	Scheme   string
 	Contexts []permission.PermissionContext `bson:",omitempty"`
@@ -159,6 +176,9 @@ var (
 	TargetTypeGC              = TargetType("gc")
 	TargetTypeRouter          = TargetType("router")
 
+	TargetTypeWorkloadIdentity = TargetType("workload-identity")
+	TargetTypeJobWorkflow      = TargetType("job-workflow")
+
 	ErrInvalidTargetType = errors.New("invalid event target type")
 )
 
@@ -202,6 +222,10 @@ func GetTargetType(t string) (TargetType, error) {
 		return TargetTypeWebhook, nil
 	case "router":
 		return TargetTypeRouter, nil
+	case "workload-identity":
+		return TargetTypeWorkloadIdentity, nil
+	case "job-workflow":
+		return TargetTypeJobWorkflow, nil
 	}
 	return TargetType(""), ErrInvalidTargetType
 }
@@ -8,11 +8,13 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 )
 
 var (
-	ErrWebhookAlreadyExists = errors.New("webhook already exists with the same name")
-	ErrWebhookNotFound      = errors.New("webhook not found")
+	ErrWebhookAlreadyExists    = errors.New("webhook already exists with the same name")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
 )
 
 type WebhookEventFilter struct {
@@ -24,6 +26,13 @@ type WebhookEventFilter struct {
 	SuccessOnly  bool     `json:"success_only" form:"success_only"`
 }
 
+// WebhookRetryPolicy controls how many times a failed delivery is retried
+// before being moved to the dead-letter state. A zero MaxAttempts means the
+// default value from the config file is used.
+type WebhookRetryPolicy struct {
+	MaxAttempts int `json:"max_attempts" form:"max_attempts"`
+}
+
 type Webhook struct {
 	Name        string             `json:"name" form:"name"`
 	Description string             `json:"description" form:"description"`
@@ -35,6 +44,64 @@ type Webhook struct {
 	Method      string             `json:"method" form:"method"`
 	Body        string             `json:"body" form:"body"`
 	Insecure    bool               `json:"insecure" form:"insecure"`
+	RetryPolicy WebhookRetryPolicy `json:"retry_policy" form:"retry_policy"`
+
+	// Secret is used to sign request bodies. It's write-only and is never
+	// returned by WebhookService.Find or WebhookService.List, use HasSecret
+	// to check whether the webhook is signed.
+	Secret       string `json:"secret,omitempty" form:"secret"`
+	RemoveSecret bool   `json:"remove_secret,omitempty" form:"remove_secret" bson:"-"`
+	HasSecret    bool   `json:"has_secret" form:"-" bson:"-"`
+
+	// PreviousSecret keeps the secret replaced by the last rotation, which
+	// is still used to sign requests until PreviousSecretExpiresAt.
+	PreviousSecret          string    `json:"-" form:"-"`
+	PreviousSecretExpiresAt time.Time `json:"-" form:"-"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   = WebhookDeliveryStatus("pending")
+	WebhookDeliverySucceeded = WebhookDeliveryStatus("succeeded")
+	WebhookDeliveryDead      = WebhookDeliveryStatus("dead")
+)
+
+// WebhookDelivery is the persisted state of a single event notification to
+// a single webhook.
+type WebhookDelivery struct {
+	ID            string                   `json:"id"`
+	WebhookName   string                   `json:"webhook_name"`
+	EventID       string                   `json:"event_id"`
+	Status        WebhookDeliveryStatus    `json:"status"`
+	Attempts      int                      `json:"attempts"`
+	MaxAttempts   int                      `json:"max_attempts"`
+	LastError     string                   `json:"last_error,omitempty"`
+	History       []WebhookDeliveryAttempt `json:"history,omitempty"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+	NextAttemptAt time.Time                `json:"next_attempt_at"`
+	LockedUntil   time.Time                `json:"-"`
+	ExpireAt      time.Time                `json:"-"`
+}
+
+// WebhookDeliveryAttempt records a single HTTP call made to a webhook.
+// Request and response bodies are truncated before being stored.
+type WebhookDeliveryAttempt struct {
+	EventID        string        `json:"event_id"`
+	Time           time.Time     `json:"time"`
+	Latency        time.Duration `json:"latency"`
+	RequestBody    string        `json:"request_body,omitempty"`
+	ResponseStatus int           `json:"response_status,omitempty"`
+	ResponseBody   string        `json:"response_body,omitempty"`
+	Error          string        `json:"error,omitempty"`
+}
+
+type WebhookDeliveryFilter struct {
+	WebhookName string
+	ID          string
+	Status      WebhookDeliveryStatus
+	Limit       int
 }
 
 type WebhookService interface {
@@ -44,6 +111,12 @@ type WebhookService interface {
 	Delete(context.Context, string) error
 	Find(context.Context, string) (Webhook, error)
 	List(context.Context, []string) ([]Webhook, error)
+	ListDeliveries(context.Context, WebhookDeliveryFilter) ([]WebhookDelivery, error)
+	RedriveDeliveries(context.Context, WebhookDeliveryFilter) (int, error)
+	// Test renders the webhook against the event with the given ID, or
+	// against a synthetic event if evtID is empty, and sends it once
+	// without persisting a delivery.
+	Test(ctx context.Context, name, evtID string) (*WebhookDeliveryAttempt, error)
 }
 
 type WebhookStorage interface {
@@ -54,3 +127,21 @@ type WebhookStorage interface {
 	FindByEvent(ctx context.Context, f WebhookEventFilter, isSuccess bool) ([]Webhook, error)
 	Delete(context.Context, string) error
 }
+
+type WebhookDeliveryStorage interface {
+	Insert(context.Context, WebhookDelivery) error
+	Update(context.Context, WebhookDelivery) error
+	FindByID(context.Context, string) (*WebhookDelivery, error)
+	Find(context.Context, WebhookDeliveryFilter) ([]WebhookDelivery, error)
+	// AcquireNext atomically locks and returns the next pending delivery
+	// whose next attempt is due, returning ErrWebhookDeliveryNotFound if
+	// there is none.
+	AcquireNext(ctx context.Context, lockFor time.Duration) (*WebhookDelivery, error)
+	// Redrive moves dead deliveries matching the filter back to the pending
+	// state, returning the number of deliveries affected.
+	Redrive(context.Context, WebhookDeliveryFilter) (int, error)
+	// Prune removes the finished deliveries of the webhook, except for the
+	// newest keep ones. Dead deliveries are never pruned.
+	Prune(ctx context.Context, webhookName string, keep int) error
+	DeleteByWebhook(context.Context, string) error
+}
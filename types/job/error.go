@@ -12,6 +12,16 @@ import (
 var (
 	ErrJobNotFound              = errors.New("Job not found")
 	ErrJobUnitNotFound          = errors.New("Job unit not found")
+	ErrJobRunNotFound           = errors.New("Job run not found")
+	ErrWorkflowNotFound         = errors.New("Workflow not found")
+	ErrWorkflowAlreadyExists    = errors.New("a workflow with the same name already exists")
+	ErrWorkflowRunNotFound      = errors.New("Workflow run not found")
+	ErrWorkflowRunConflict      = errors.New("workflow run was concurrently modified")
+	ErrWorkflowScheduleConflict = errors.New("workflow schedule was concurrently modified")
+	ErrWorkflowJobTeamChanged   = errors.New("job was moved to another team since the workflow was saved, the workflow must be updated to run it")
+	ErrInvalidWorkflowName      = errors.New("your workflow should have at most 40 " +
+		"characters, containing only lower case letters, numbers or dashes, " +
+		"starting with a letter.")
 	MaxAttempts                 = 5
 	ErrMaxAttemptsReached       = fmt.Errorf("Unable to generate unique job name: max attempts reached (%d)", MaxAttempts)
 	ErrJobAlreadyExists         = errors.New("a job with the same name already exists")
@@ -88,7 +88,7 @@ type JobService interface {
 	GetByName(ctx context.Context, name string) (*Job, error)
 	List(ctx context.Context, filter *Filter) ([]Job, error)
 	RemoveJob(ctx context.Context, job *Job) error
-	Trigger(ctx context.Context, job *Job) error
+	Trigger(ctx context.Context, job *Job, args TriggerArgs) error
 	UpdateJob(ctx context.Context, newJob, oldJob *Job, user *authTypes.User) error
 	AddServiceEnv(ctx context.Context, job *Job, addArgs AddInstanceArgs) error
 	RemoveServiceEnv(ctx context.Context, job *Job, removeArgs RemoveInstanceArgs) error
@@ -97,6 +97,9 @@ type JobService interface {
 	BaseImageName(ctx context.Context, job *Job) (string, error)
 	KillUnit(ctx context.Context, job *Job, unitName string, force bool) error
 	Deploy(ctx context.Context, opts DeployOptions, job *Job, output io.Writer) (string, error)
+	RecordRun(ctx context.Context, run Run) error
+	ListRuns(ctx context.Context, job *Job, filter RunFilter) ([]Run, error)
+	GetRun(ctx context.Context, job *Job, id string) (*Run, error)
 }
 
 type JobInfo struct {
@@ -20,7 +20,7 @@ type MockJobService struct {
 	OnList             func(*Filter) ([]Job, error)
 	OnRemoveJob        func(*Job) error
 	OnRemoveJobProv    func(*Job) error
-	OnTrigger          func(*Job) error
+	OnTrigger          func(*Job, TriggerArgs) error
 	OnAddServiceEnv    func(*Job, AddInstanceArgs) error
 	OnRemoveServiceEnv func(*Job, RemoveInstanceArgs) error
 	OnUpdateJob        func(*Job, *Job, *authTypes.User) error
@@ -29,6 +29,9 @@ type MockJobService struct {
 	OnBaseImageName    func(context.Context, *Job) (string, error)
 	OnKillUnit         func(*Job, string) error
 	OnDeploy           func(context.Context, DeployOptions, *Job, io.Writer) (string, error)
+	OnRecordRun        func(Run) error
+	OnListRuns         func(*Job, RunFilter) ([]Run, error)
+	OnGetRun           func(*Job, string) (*Run, error)
 }
 
 func (m *MockJobService) CreateJob(ctx context.Context, job *Job, user *authTypes.User) error {
@@ -66,11 +69,11 @@ func (m *MockJobService) RemoveJob(ctx context.Context, job *Job) error {
 	return m.OnRemoveJob(job)
 }
 
-func (m *MockJobService) Trigger(ctx context.Context, job *Job) error {
+func (m *MockJobService) Trigger(ctx context.Context, job *Job, args TriggerArgs) error {
 	if m.OnTrigger == nil {
 		return nil
 	}
-	return m.OnTrigger(job)
+	return m.OnTrigger(job, args)
 }
 
 func (m *MockJobService) UpdateJob(ctx context.Context, newJob, oldJob *Job, user *authTypes.User) error {
@@ -128,3 +131,24 @@ func (m *MockJobService) Deploy(ctx context.Context, opts DeployOptions, job *Jo
 	}
 	return m.OnDeploy(ctx, opts, job, output)
 }
+
+func (m *MockJobService) RecordRun(ctx context.Context, run Run) error {
+	if m.OnRecordRun == nil {
+		return nil
+	}
+	return m.OnRecordRun(run)
+}
+
+func (m *MockJobService) ListRuns(ctx context.Context, job *Job, filter RunFilter) ([]Run, error) {
+	if m.OnListRuns == nil {
+		return nil, nil
+	}
+	return m.OnListRuns(job, filter)
+}
+
+func (m *MockJobService) GetRun(ctx context.Context, job *Job, id string) (*Run, error) {
+	if m.OnGetRun == nil {
+		return nil, nil
+	}
+	return m.OnGetRun(job, id)
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+// RunTrigger tells what started a job run.
+type RunTrigger string
+
+const (
+	// RunTriggerCron is a run started by the job schedule.
+	RunTriggerCron = RunTrigger("cron")
+	// RunTriggerManual is a run started by a user, e.g. with tsuru job
+	// trigger.
+	RunTriggerManual = RunTrigger("manual")
+	// RunTriggerAPI is a run started by automation through the API, using
+	// team, personal or workload tokens.
+	RunTriggerAPI = RunTrigger("api")
+	// RunTriggerWorkflow is a run started as a step of a workflow run.
+	RunTriggerWorkflow = RunTrigger("workflow")
+)
+
+type RunStatus string
+
+const (
+	RunStatusRunning   = RunStatus("running")
+	RunStatusSucceeded = RunStatus("succeeded")
+	RunStatusFailed    = RunStatus("failed")
+)
+
+// TriggerArgs describes who is triggering a job, recorded in the run it
+// starts.
+type TriggerArgs struct {
+	Trigger     RunTrigger
+	TriggeredBy string
+	// Envs are set in the units of the run, overriding the ones of the job.
+	Envs     []bindTypes.EnvVar
+	Workflow *WorkflowRef
+}
+
+// WorkflowRef identifies the workflow run step started by a job run.
+type WorkflowRef struct {
+	Workflow string `json:"workflow"`
+	Run      string `json:"run"`
+	Step     string `json:"step"`
+}
+
+// Run is a single execution of a job, kept after its units are removed
+// from the cluster.
+type Run struct {
+	ID          string       `json:"id"`
+	JobName     string       `json:"jobName"`
+	Cluster     string       `json:"cluster,omitempty"`
+	Trigger     RunTrigger   `json:"trigger"`
+	TriggeredBy string       `json:"triggeredBy,omitempty"`
+	Status      RunStatus    `json:"status"`
+	Reason      string       `json:"reason,omitempty"`
+	ExitCode    *int32       `json:"exitCode,omitempty"`
+	Retries     int          `json:"retries"`
+	StartTime   time.Time    `json:"startTime"`
+	EndTime     time.Time    `json:"endTime"`
+	Units       []string     `json:"units,omitempty"`
+	Log         *RunLog      `json:"log,omitempty"`
+	Workflow    *WorkflowRef `json:"workflow,omitempty"`
+}
+
+// Finished returns whether the run is no longer running.
+func (r *Run) Finished() bool {
+	return r.Status == RunStatusSucceeded || r.Status == RunStatusFailed
+}
+
+// RunLog holds the output captured from the units of a run when it
+// finished, limited to the last lines of each unit.
+type RunLog struct {
+	Entries   []RunLogEntry `json:"entries,omitempty"`
+	Truncated bool          `json:"truncated"`
+}
+
+type RunLogEntry struct {
+	Date    time.Time `json:"date"`
+	Unit    string    `json:"unit"`
+	Message string    `json:"message"`
+}
+
+type RunFilter struct {
+	JobName string
+	Status  RunStatus
+	Limit   int
+}
+
+type RunStorage interface {
+	// Upsert stores the run, replacing the one with the same job name and ID.
+	Upsert(context.Context, Run) error
+	FindByID(ctx context.Context, jobName, id string) (*Run, error)
+	// Find returns the runs matching the filter, the most recent first.
+	Find(context.Context, RunFilter) ([]Run, error)
+	// Prune removes the finished runs of a job but the most recent keep ones.
+	Prune(ctx context.Context, jobName string, keep int) error
+	DeleteByJob(ctx context.Context, jobName string) error
+}
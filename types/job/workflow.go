@@ -0,0 +1,181 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+)
+
+// WorkflowCondition tells when a step runs, based on the status of the steps
+// it depends on. Steps without dependencies always run.
+type WorkflowCondition string
+
+const (
+	// WorkflowConditionSuccess runs the step when all its dependencies
+	// succeed, it's the default condition.
+	WorkflowConditionSuccess = WorkflowCondition("success")
+	// WorkflowConditionFailure runs the step when any of its dependencies
+	// fails.
+	WorkflowConditionFailure = WorkflowCondition("failure")
+	// WorkflowConditionAlways runs the step once all its dependencies
+	// finish, whatever their status.
+	WorkflowConditionAlways = WorkflowCondition("always")
+)
+
+type WorkflowStepStatus string
+
+const (
+	WorkflowStepPending   = WorkflowStepStatus("pending")
+	WorkflowStepRunning   = WorkflowStepStatus("running")
+	WorkflowStepSucceeded = WorkflowStepStatus("succeeded")
+	WorkflowStepFailed    = WorkflowStepStatus("failed")
+	// WorkflowStepSkipped is a step whose condition wasn't met.
+	WorkflowStepSkipped = WorkflowStepStatus("skipped")
+)
+
+// Workflow runs existing jobs in the order given by the dependencies between
+// its steps, either on a schedule or when triggered through the API.
+type Workflow struct {
+	Name        string             `json:"name"`
+	TeamOwner   string             `json:"teamOwner"`
+	Description string             `json:"description,omitempty"`
+	Schedule    string             `json:"schedule,omitempty"`
+	Envs        []bindTypes.EnvVar `json:"envs,omitempty"`
+	Steps       []WorkflowStep     `json:"steps"`
+
+	// LastScheduleTime is the last time the workflow was considered for a
+	// scheduled run, runs missed in between are not caught up.
+	LastScheduleTime time.Time `json:"lastScheduleTime"`
+}
+
+type WorkflowStep struct {
+	Name      string             `json:"name"`
+	Job       string             `json:"job"`
+	DependsOn []string           `json:"dependsOn,omitempty"`
+	Condition WorkflowCondition  `json:"condition,omitempty"`
+	Envs      []bindTypes.EnvVar `json:"envs,omitempty"`
+
+	// JobTeam is the team owning the job when the workflow was saved, the
+	// step isn't started if the job is moved to another team afterwards.
+	JobTeam string `json:"jobTeam,omitempty"`
+}
+
+// WorkflowRun is a single execution of a workflow. It keeps a copy of the
+// workflow steps, so changes to the workflow don't affect the runs in
+// progress.
+type WorkflowRun struct {
+	ID           string             `json:"id"`
+	WorkflowName string             `json:"workflowName"`
+	Trigger      RunTrigger         `json:"trigger"`
+	TriggeredBy  string             `json:"triggeredBy,omitempty"`
+	Status       RunStatus          `json:"status"`
+	Envs         []bindTypes.EnvVar `json:"envs,omitempty"`
+	StartTime    time.Time          `json:"startTime"`
+	EndTime      time.Time          `json:"endTime"`
+	Steps        []WorkflowStepRun  `json:"steps"`
+
+	// Version is increased on every update, guarding against concurrent
+	// updates of the run.
+	Version int `json:"version"`
+}
+
+// Finished returns whether the run is no longer running.
+func (r *WorkflowRun) Finished() bool {
+	return r.Status == RunStatusSucceeded || r.Status == RunStatusFailed
+}
+
+// Step returns the step of the run with the given name, or nil if there's
+// none.
+func (r *WorkflowRun) Step(name string) *WorkflowStepRun {
+	for i := range r.Steps {
+		if r.Steps[i].Name == name {
+			return &r.Steps[i]
+		}
+	}
+	return nil
+}
+
+type WorkflowStepRun struct {
+	WorkflowStep `bson:",inline"`
+	Status       WorkflowStepStatus `json:"status"`
+	Reason       string             `json:"reason,omitempty"`
+	// JobRun is the ID of the job run started by the step.
+	JobRun    string    `json:"jobRun,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// Finished returns whether the step is no longer pending or running.
+func (s *WorkflowStepRun) Finished() bool {
+	return s.Status == WorkflowStepSucceeded || s.Status == WorkflowStepFailed || s.Status == WorkflowStepSkipped
+}
+
+type WorkflowRunFilter struct {
+	WorkflowName string
+	Status       RunStatus
+	Limit        int
+}
+
+// WorkflowTriggerArgs describes who is triggering a workflow, envs are set
+// in the units of every step, overriding the ones of the workflow.
+type WorkflowTriggerArgs struct {
+	Trigger     RunTrigger
+	TriggeredBy string
+	Envs        []bindTypes.EnvVar
+}
+
+type WorkflowService interface {
+	Create(context.Context, Workflow) error
+	Update(context.Context, Workflow) error
+	// List returns the workflows owned by the teams, or all of them if
+	// teams is nil.
+	List(ctx context.Context, teams []string) ([]Workflow, error)
+	Find(ctx context.Context, name string) (*Workflow, error)
+	Delete(ctx context.Context, name string) error
+	Trigger(ctx context.Context, w *Workflow, args WorkflowTriggerArgs) (*WorkflowRun, error)
+	// TriggerScheduled triggers the scheduled workflows whose next run is
+	// due at now.
+	TriggerScheduled(ctx context.Context, now time.Time) error
+	// ReconcileRuns fails the running steps that didn't finish within the
+	// step timeout at now, so runs whose job updates are lost still finish.
+	ReconcileRuns(ctx context.Context, now time.Time) error
+	// UpdateStep updates the workflow run step started by the job run,
+	// starting the steps depending on it once it finishes.
+	UpdateStep(ctx context.Context, run Run) error
+	ListRuns(ctx context.Context, w *Workflow, filter WorkflowRunFilter) ([]WorkflowRun, error)
+	GetRun(ctx context.Context, w *Workflow, id string) (*WorkflowRun, error)
+}
+
+type WorkflowStorage interface {
+	Insert(context.Context, Workflow) error
+	Update(context.Context, Workflow) error
+	FindAllByTeams(context.Context, []string) ([]Workflow, error)
+	FindByName(context.Context, string) (*Workflow, error)
+	// FindScheduled returns the workflows with a schedule.
+	FindScheduled(context.Context) ([]Workflow, error)
+	// UpdateLastSchedule sets the last schedule time of the workflow to
+	// last, as long as it's still previous, returning
+	// ErrWorkflowScheduleConflict otherwise.
+	UpdateLastSchedule(ctx context.Context, name string, previous, last time.Time) error
+	Delete(context.Context, string) error
+}
+
+type WorkflowRunStorage interface {
+	Insert(context.Context, WorkflowRun) error
+	// Update stores the run as long as its version is still the stored one,
+	// returning ErrWorkflowRunConflict otherwise. The stored version is
+	// increased by one.
+	Update(context.Context, WorkflowRun) error
+	FindByID(ctx context.Context, workflowName, id string) (*WorkflowRun, error)
+	// Find returns the runs matching the filter, the most recent first.
+	Find(context.Context, WorkflowRunFilter) ([]WorkflowRun, error)
+	// Prune removes the finished runs of a workflow but the most recent keep
+	// ones.
+	Prune(ctx context.Context, workflowName string, keep int) error
+	DeleteByWorkflow(ctx context.Context, workflowName string) error
+}
@@ -5,6 +5,7 @@
 package permission
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -69,3 +70,34 @@ type ErrPermissionNotAllowed struct {
 func (e ErrPermissionNotAllowed) Error() string {
 	return fmt.Sprintf("permission %q not allowed with context of type %q", e.Permission, e.ContextType)
 }
+
+// Role is a named set of permissions granted within a context type.
+type Role struct {
+	Name               string      `bson:"_id" json:"name"`
+	ContextType        ContextType `json:"context"`
+	Description        string
+	SchemeNames        []string `json:"scheme_names,omitempty"`
+	DynamicSchemeNames []string `bson:"dynamic_scheme_names,omitempty" json:"dynamic_scheme_names,omitempty"`
+	Events             []string `json:"events,omitempty"`
+}
+
+type RoleStorage interface {
+	Insert(ctx context.Context, role Role) error
+	FindAll(ctx context.Context) ([]Role, error)
+	FindByName(ctx context.Context, name string) (*Role, error)
+	// FindWithEvents returns the roles with at least one event.
+	FindWithEvents(ctx context.Context) ([]Role, error)
+	FindByEvent(ctx context.Context, eventName string) ([]Role, error)
+	// FindByDynamicSchemePrefix returns the roles with a dynamic scheme
+	// equal to prefix or nested under it.
+	FindByDynamicSchemePrefix(ctx context.Context, prefix string) ([]Role, error)
+	// Update stores the context type and description of the role.
+	Update(ctx context.Context, role Role) error
+	AddSchemeNames(ctx context.Context, roleName string, schemeNames []string) error
+	RemoveSchemeNames(ctx context.Context, roleName string, schemeNames []string) error
+	AddDynamicSchemeNames(ctx context.Context, roleName string, schemeNames []string) error
+	RemoveDynamicSchemeNames(ctx context.Context, roleName string, schemeNames []string) error
+	AddEvent(ctx context.Context, roleName, eventName string) error
+	RemoveEvent(ctx context.Context, roleName, eventName string) error
+	Delete(ctx context.Context, name string) error
+}
@@ -11,6 +11,7 @@ import (
 
 var (
 	ErrPoolNotFound      = errors.New("pool does not exist")
+	ErrPoolAlreadyExists = errors.New("pool already exists")
 	ErrTooManyPoolsFound = errors.New("too many pools found")
 )
 
@@ -18,9 +19,13 @@ type Pool struct {
 	Name        string `bson:"_id"`
 	Provisioner string
 	Default     bool
+	Labels      map[string]string `bson:",omitempty"`
 }
 
 type PoolStorage interface {
+	Insert(ctx context.Context, p Pool) error
+	Update(ctx context.Context, p Pool) error
+	Delete(ctx context.Context, name string) error
 	FindAll(ctx context.Context) ([]Pool, error)
 	FindByName(ctx context.Context, name string) (*Pool, error)
 }
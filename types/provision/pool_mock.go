@@ -12,10 +12,34 @@ var _ PoolStorage = &MockPoolStorage{}
 var _ PoolService = &MockPoolService{}
 
 type MockPoolStorage struct {
+	OnInsert     func(Pool) error
+	OnUpdate     func(Pool) error
+	OnDelete     func(string) error
 	OnFindAll    func() ([]Pool, error)
 	OnFindByName func(string) (*Pool, error)
 }
 
+func (m *MockPoolStorage) Insert(ctx context.Context, p Pool) error {
+	if m.OnInsert != nil {
+		return m.OnInsert(p)
+	}
+	return nil
+}
+
+func (m *MockPoolStorage) Update(ctx context.Context, p Pool) error {
+	if m.OnUpdate != nil {
+		return m.OnUpdate(p)
+	}
+	return nil
+}
+
+func (m *MockPoolStorage) Delete(ctx context.Context, name string) error {
+	if m.OnDelete != nil {
+		return m.OnDelete(name)
+	}
+	return nil
+}
+
 func (m *MockPoolStorage) FindAll(ctx context.Context) ([]Pool, error) {
 	if m.OnFindAll != nil {
 		return m.OnFindAll()
@@ -4,8 +4,30 @@
 
 package service
 
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrServiceAlreadyExists = errors.New("Service already exists.")
+	ErrServiceNotFound      = errors.New("Service not found.")
+)
+
+type ServiceEncoding string
+
 type Service struct {
-	Manifest *ServiceManifest `bson:"manifest,omitempty" json:"manifest,omitempty"`
+	Name           string `bson:"_id"`
+	Username       string
+	Password       string
+	Endpoint       map[string]string
+	OwnerTeams     []string `bson:"owner_teams"`
+	Teams          []string
+	Doc            string
+	IsRestricted   bool             `bson:"is_restricted"`
+	IsMultiCluster bool             `bson:"is_multi_cluster"`
+	Encoding       ServiceEncoding  `bson:"encoding"`
+	Manifest       *ServiceManifest `bson:"manifest,omitempty" json:"manifest,omitempty"`
 }
 
 type ServiceManifest struct {
@@ -20,3 +42,27 @@ type ManifestOperation struct {
 	Path   string `bson:"path" json:"path"`
 	Action string `bson:"action" json:"action"`
 }
+
+// ServiceFilter selects the services matching any of its conditions. A nil
+// list adds no condition, while an empty one matches no service.
+type ServiceFilter struct {
+	// Unrestricted matches the services available to every team.
+	Unrestricted bool
+	Teams        []string
+	OwnerTeams   []string
+	Names        []string
+}
+
+type ServiceStorage interface {
+	Insert(ctx context.Context, s Service) error
+	// Update replaces the stored service.
+	Update(ctx context.Context, s Service) error
+	FindByName(ctx context.Context, name string) (*Service, error)
+	// FindAll returns the services matching the filter, or every service
+	// when it's nil.
+	FindAll(ctx context.Context, f *ServiceFilter) ([]Service, error)
+	// SetManifest stores the manifest of the service, removing it when nil.
+	SetManifest(ctx context.Context, name string, manifest *ServiceManifest) error
+	RenameTeam(ctx context.Context, oldName, newName string) error
+	Delete(ctx context.Context, name string) error
+}
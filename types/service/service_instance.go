@@ -0,0 +1,76 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrServiceInstanceNotFound   = errors.New("service instance not found")
+	ErrInstanceNameAlreadyExists = errors.New("instance name already exists.")
+	ErrAppAlreadyBound           = errors.New("app is already bound to this service instance")
+	ErrJobAlreadyBound           = errors.New("job is already bound to this service instance")
+)
+
+type ServiceInstance struct {
+	Name        string                 `json:"name"`
+	ServiceName string                 `bson:"service_name" json:"service_name"`
+	PlanName    string                 `bson:"plan_name" json:"plan_name"`
+	Apps        []string               `json:"apps"`
+	Jobs        []string               `json:"jobs"`
+	Teams       []string               `json:"teams"`
+	TeamOwner   string                 `json:"team_owner"`
+	Description string                 `json:"description"`
+	Tags        []string               `json:"tags"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	// Pool is the pool name which the Service Instance should run into.
+	// This field is mandatory iff the parent Service is running in
+	// multi-cluster mode (see Service.IsMultiCluster field)
+	//
+	// NOTE: after the service instance is created, this field turns immutable.
+	Pool string `json:"pool,omitempty"`
+
+	// ForceRemove indicates whether service instance should be removed even the
+	// related call to service API fails.
+	ForceRemove bool `bson:"-" json:"-"`
+}
+
+// ServiceInstanceFilter selects the instances matching all of its
+// conditions. Teams and Names, when any of them is not nil, match the
+// instances either granted to one of the teams or named after one of the
+// names.
+type ServiceInstanceFilter struct {
+	Teams []string
+	Names []string
+	// ServiceNames, when not nil, matches the instances of the services.
+	ServiceNames []string
+	AppName      string
+	JobName      string
+	// Tags matches the instances with every one of the tags.
+	Tags []string
+}
+
+type ServiceInstanceStorage interface {
+	Insert(ctx context.Context, si ServiceInstance) error
+	FindByName(ctx context.Context, serviceName, instanceName string) (*ServiceInstance, error)
+	FindAll(ctx context.Context, f ServiceInstanceFilter) ([]ServiceInstance, error)
+	// Update stores the description, tags, plan, parameters, team owner and
+	// teams of the instance.
+	Update(ctx context.Context, si ServiceInstance) error
+	// AddApp binds the app to the instance, returning ErrAppAlreadyBound
+	// when it's already bound.
+	AddApp(ctx context.Context, serviceName, instanceName, appName string) error
+	RemoveApp(ctx context.Context, serviceName, instanceName, appName string) error
+	// AddJob binds the job to the instance, returning ErrJobAlreadyBound
+	// when it's already bound.
+	AddJob(ctx context.Context, serviceName, instanceName, jobName string) error
+	RemoveJob(ctx context.Context, serviceName, instanceName, jobName string) error
+	AddTeam(ctx context.Context, serviceName, instanceName, teamName string) error
+	RemoveTeam(ctx context.Context, serviceName, instanceName, teamName string) error
+	RenameTeam(ctx context.Context, oldName, newName string) error
+	Delete(ctx context.Context, serviceName, instanceName string) error
+}